@@ -53,6 +53,7 @@ const (
 	EnvHTTPProxy             = "VAULT_HTTP_PROXY"
 	EnvVaultProxyAddr        = "VAULT_PROXY_ADDR"
 	EnvVaultDisableRedirects = "VAULT_DISABLE_REDIRECTS"
+	EnvVaultFailoverAddrs    = "VAULT_FAILOVER_ADDRS"
 	HeaderIndex              = "X-Vault-Index"
 	HeaderForward            = "X-Vault-Forward"
 	HeaderInconsistent       = "X-Vault-Inconsistent"
@@ -207,6 +208,24 @@ type Config struct {
 	// primary node.
 	DisableRedirects bool
 	clientTLSConfig  *tls.Config
+
+	// FailoverAddresses are additional Vault addresses, such as the other
+	// nodes of an HA cluster, used when the current address cannot be
+	// reached or reports that it is unavailable. The first address, starting
+	// with Address, that passes a health check becomes the client's address
+	// and the request is retried against it. Unix domain sockets are not
+	// supported.
+	FailoverAddresses []string
+
+	// FailoverHealthCheckTimeout is the time allowed for the health check of
+	// a single failover address. Defaults to 5 seconds.
+	FailoverHealthCheckTimeout time.Duration
+
+	// ConsistencyPreference controls whether requests are served by the node
+	// the client is connected to, or forwarded to the active node. Forwarding
+	// requires the X-Vault-Forward header to be allowed in the server's
+	// configuration.
+	ConsistencyPreference ConsistencyPreference
 }
 
 // TLSConfig contains the parameters needed to configure TLS on the HTTP client
@@ -386,6 +405,7 @@ func (c *Config) ReadEnvironment() error {
 	var limit *rate.Limiter
 	var envVaultProxy string
 	var envVaultDisableRedirects bool
+	var envFailoverAddrs []string
 
 	// Parse the environment variables
 	if v := os.Getenv(EnvVaultAddress); v != "" {
@@ -394,6 +414,9 @@ func (c *Config) ReadEnvironment() error {
 	if v := os.Getenv(EnvVaultAgentAddr); v != "" {
 		envAgentAddress = v
 	}
+	if v := os.Getenv(EnvVaultFailoverAddrs); v != "" {
+		envFailoverAddrs = strutil.RemoveDuplicatesStable(strutil.ParseStringSlice(v, ","), false)
+	}
 	if v := os.Getenv(EnvVaultMaxRetries); v != "" {
 		maxRetries, err := strconv.ParseUint(v, 10, 32)
 		if err != nil {
@@ -497,6 +520,10 @@ func (c *Config) ReadEnvironment() error {
 		c.AgentAddress = envAgentAddress
 	}
 
+	if len(envFailoverAddrs) > 0 {
+		c.FailoverAddresses = envFailoverAddrs
+	}
+
 	if envMaxRetries != nil {
 		c.MaxRetries = int(*envMaxRetries)
 	}
@@ -589,6 +616,10 @@ type Client struct {
 	requestCallbacks      []RequestCallback
 	responseCallbacks     []ResponseCallback
 	replicationStateStore *replicationStateStore
+
+	// outputPolicyPrereqs are requests performed before the request which
+	// produces an OutputPolicyError; see AddOutputPolicyPrerequisite.
+	outputPolicyPrereqs []*OutputPolicyError
 }
 
 // NewClient returns a new client for the given configuration.
@@ -640,6 +671,10 @@ func NewClient(c *Config) (*Client, error) {
 		return nil, err
 	}
 
+	if _, err := parseFailoverAddresses(c.FailoverAddresses); err != nil {
+		return nil, err
+	}
+
 	client := &Client{
 		addr:    u,
 		config:  c,
@@ -684,6 +719,9 @@ func (c *Client) CloneConfig() *Config {
 	newConfig.CloneToken = c.config.CloneToken
 	newConfig.ReadYourWrites = c.config.ReadYourWrites
 	newConfig.clientTLSConfig = c.config.clientTLSConfig
+	newConfig.FailoverAddresses = c.config.FailoverAddresses
+	newConfig.FailoverHealthCheckTimeout = c.config.FailoverHealthCheckTimeout
+	newConfig.ConsistencyPreference = c.config.ConsistencyPreference
 
 	// we specifically want a _copy_ of the client here, not a pointer to the original one
 	newClient := *c.config.HttpClient
@@ -932,6 +970,22 @@ func (c *Client) SetOutputPolicy(isSet bool) {
 	c.config.OutputPolicy = isSet
 }
 
+// AddOutputPolicyPrerequisite records a request that a command performs, with
+// OutputPolicy temporarily disabled, before the request that will produce the
+// OutputPolicyError, such as the pre-read done by "vault kv patch". The
+// capabilities it requires are included in the generated policy. The path is
+// relative to /v1, e.g. "secret/data/foo".
+func (c *Client) AddOutputPolicyPrerequisite(method, path string, params url.Values) {
+	c.modifyLock.Lock()
+	defer c.modifyLock.Unlock()
+
+	c.outputPolicyPrereqs = append(c.outputPolicyPrereqs, &OutputPolicyError{
+		method: method,
+		path:   "/" + strings.TrimPrefix(path, "/"),
+		params: params,
+	})
+}
+
 // CurrentWrappingLookupFunc sets a lookup function that returns desired wrap TTLs
 // for a given operation and path.
 func (c *Client) CurrentWrappingLookupFunc() WrappingLookupFunc {
@@ -1367,8 +1421,13 @@ func (c *Client) rawRequestWithContext(ctx context.Context, r *Request) (*Respon
 	outputPolicy := c.config.OutputPolicy
 	logger := c.config.Logger
 	disableRedirects := c.config.DisableRedirects
+	failoverAddrs := c.config.FailoverAddresses
+	consistencyPreference := c.config.ConsistencyPreference
 	c.config.modifyLock.RUnlock()
 
+	outputPolicyPrereqs := c.outputPolicyPrereqs
+	addr := c.addr
+
 	c.modifyLock.RUnlock()
 
 	// ensure that the most current namespace setting is used at the time of the call
@@ -1388,6 +1447,10 @@ func (c *Client) rawRequestWithContext(ctx context.Context, r *Request) (*Respon
 		c.replicationStateStore.requireState(r)
 	}
 
+	if consistencyPreference == ConsistencyForward {
+		r.Headers.Set(HeaderForward, "active-node")
+	}
+
 	if limiter != nil {
 		limiter.Wait(ctx)
 	}
@@ -1397,7 +1460,13 @@ func (c *Client) rawRequestWithContext(ctx context.Context, r *Request) (*Respon
 		return nil, err
 	}
 
+	// Only fail over requests sent to the client's own address, not those
+	// targeting an arbitrary server.
+	canFailover := len(failoverAddrs) > 0 && addr != nil && r.URL != nil &&
+		r.URL.Scheme == addr.Scheme && r.URL.Host == addr.Host
+
 	redirectCount := 0
+	failoverCount := 0
 START:
 	req, err := r.toRetryableHTTP()
 	if err != nil {
@@ -1421,9 +1490,10 @@ START:
 
 	if outputPolicy {
 		LastOutputPolicyError = &OutputPolicyError{
-			method: req.Method,
-			path:   strings.TrimPrefix(req.URL.Path, "/v1"),
-			params: req.URL.Query(),
+			method:        req.Method,
+			path:          strings.TrimPrefix(req.URL.Path, "/v1"),
+			params:        req.URL.Query(),
+			prerequisites: outputPolicyPrereqs,
 		}
 		return nil, LastOutputPolicyError
 	}
@@ -1451,6 +1521,24 @@ START:
 
 	var result *Response
 	resp, err := client.Do(req)
+	if canFailover && failoverCount < len(failoverAddrs) && ctx.Err() == nil && (err != nil || isFailoverStatus(resp)) {
+		if newAddr, foErr := c.failover(ctx, req.URL); foErr == nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			r.URL.Scheme = newAddr.Scheme
+			r.URL.Host = newAddr.Host
+
+			// Reset the request body if any
+			if err := r.ResetJSONBody(); err != nil {
+				return nil, err
+			}
+
+			failoverCount++
+			goto START
+		}
+	}
 	if resp != nil {
 		result = &Response{Response: resp}
 	}
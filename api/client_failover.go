@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultFailoverHealthCheckTimeout is the default time allowed for the health
+// check of a single failover address.
+const DefaultFailoverHealthCheckTimeout = 5 * time.Second
+
+// ErrNoHealthyFailoverAddress is returned when a request failed and none of
+// the configured failover addresses passed a health check.
+var ErrNoHealthyFailoverAddress = errors.New("no healthy Vault address to fail over to")
+
+// ConsistencyPreference controls where requests sent to a node the client
+// reached through failover are served.
+type ConsistencyPreference string
+
+const (
+	// ConsistencyLocal lets whichever node the request reaches serve it,
+	// which for performance standbys means reads are served locally. This is
+	// the default.
+	ConsistencyLocal ConsistencyPreference = "local"
+
+	// ConsistencyForward asks performance standbys to forward every request
+	// to the active node, trading latency for consistency.
+	ConsistencyForward ConsistencyPreference = "forward"
+)
+
+// parseFailoverAddresses validates the failover addresses. Unix domain
+// sockets are not supported as failover targets.
+func parseFailoverAddresses(addrs []string) ([]*url.URL, error) {
+	parsed := make([]*url.URL, 0, len(addrs))
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid failover address %q: %w", addr, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid failover address %q: scheme must be http or https", addr)
+		}
+		parsed = append(parsed, u)
+	}
+
+	return parsed, nil
+}
+
+// SetFailoverAddresses sets the additional addresses the client fails over to
+// when the current address cannot be reached. See Config.FailoverAddresses.
+func (c *Client) SetFailoverAddresses(addrs []string) error {
+	c.modifyLock.Lock()
+	defer c.modifyLock.Unlock()
+	c.config.modifyLock.Lock()
+	defer c.config.modifyLock.Unlock()
+
+	if _, err := parseFailoverAddresses(addrs); err != nil {
+		return err
+	}
+
+	c.config.FailoverAddresses = addrs
+	return nil
+}
+
+// FailoverAddresses returns the configured failover addresses.
+func (c *Client) FailoverAddresses() []string {
+	c.modifyLock.RLock()
+	defer c.modifyLock.RUnlock()
+	c.config.modifyLock.RLock()
+	defer c.config.modifyLock.RUnlock()
+
+	return c.config.FailoverAddresses
+}
+
+// SetConsistencyPreference sets where requests are served once the client
+// has failed over. See Config.ConsistencyPreference.
+func (c *Client) SetConsistencyPreference(pref ConsistencyPreference) {
+	c.modifyLock.RLock()
+	defer c.modifyLock.RUnlock()
+	c.config.modifyLock.Lock()
+	defer c.config.modifyLock.Unlock()
+
+	c.config.ConsistencyPreference = pref
+}
+
+// ConsistencyPreference returns the configured consistency preference.
+func (c *Client) ConsistencyPreference() ConsistencyPreference {
+	c.modifyLock.RLock()
+	defer c.modifyLock.RUnlock()
+	c.config.modifyLock.RLock()
+	defer c.config.modifyLock.RUnlock()
+
+	return c.config.ConsistencyPreference
+}
+
+// isFailoverStatus returns true if the response indicates that the node which
+// returned it cannot serve requests, so another address should be tried.
+func isFailoverStatus(resp *http.Response) bool {
+	return resp != nil && resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// failover health checks the client's primary and failover addresses, other
+// than the one which just failed, and switches the client to the first one
+// that reports itself as unsealed and able to serve requests.
+func (c *Client) failover(ctx context.Context, failed *url.URL) (*url.URL, error) {
+	c.modifyLock.RLock()
+	c.config.modifyLock.RLock()
+	httpClient := c.config.HttpClient
+	primary := c.config.Address
+	addrs := c.config.FailoverAddresses
+	timeout := c.config.FailoverHealthCheckTimeout
+	c.config.modifyLock.RUnlock()
+	c.modifyLock.RUnlock()
+
+	candidates, err := parseFailoverAddresses(addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	// The primary address is tried first so that clients move back to it once
+	// it recovers. It is skipped if it is a Unix domain socket.
+	if p, err := parseFailoverAddresses([]string{primary}); err == nil {
+		candidates = append(p, candidates...)
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultFailoverHealthCheckTimeout
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Scheme == failed.Scheme && candidate.Host == failed.Host {
+			continue
+		}
+
+		if !checkFailoverHealth(ctx, httpClient, candidate, timeout) {
+			continue
+		}
+
+		c.modifyLock.Lock()
+		c.addr = candidate
+		c.modifyLock.Unlock()
+
+		return candidate, nil
+	}
+
+	return nil, ErrNoHealthyFailoverAddress
+}
+
+// checkFailoverHealth returns true if the Vault node at addr is initialized,
+// unsealed and either active or a standby able to forward requests.
+func checkFailoverHealth(ctx context.Context, httpClient *http.Client, addr *url.URL, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	u := *addr
+	u.Path = "/v1/sys/health"
+	u.RawQuery = url.Values{
+		"standbyok":     []string{"true"},
+		"perfstandbyok": []string{"true"},
+	}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set(RequestHeaderName, "true")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient_Failover(t *testing.T) {
+	t.Parallel()
+
+	// The primary is unreachable, the first failover address is sealed and
+	// the second one is healthy.
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	primary.Close()
+
+	sealed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer sealed.Close()
+
+	var forwardHeader string
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/secret/foo" {
+			forwardHeader = r.Header.Get(HeaderForward)
+			w.Write([]byte(`{"data":{"foo":"bar"}}`))
+		}
+	}))
+	defer healthy.Close()
+
+	config := DefaultConfig()
+	config.Address = primary.URL
+	config.MaxRetries = 0
+	config.FailoverAddresses = []string{sealed.URL, healthy.URL}
+	config.ConsistencyPreference = ConsistencyForward
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("foo")
+
+	secret, err := client.Logical().ReadWithContext(context.Background(), "secret/foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret == nil || secret.Data["foo"] != "bar" {
+		t.Fatalf("unexpected secret: %#v", secret)
+	}
+	if client.Address() != healthy.URL {
+		t.Fatalf("expected client to fail over to %q, got %q", healthy.URL, client.Address())
+	}
+	if forwardHeader != "active-node" {
+		t.Fatalf("expected forward header to be set, got %q", forwardHeader)
+	}
+}
+
+func TestClient_FailoverNoHealthyAddress(t *testing.T) {
+	t.Parallel()
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	primary.Close()
+
+	sealed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer sealed.Close()
+
+	config := DefaultConfig()
+	config.Address = primary.URL
+	config.MaxRetries = 0
+	config.FailoverAddresses = []string{sealed.URL}
+
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken("foo")
+
+	if _, err := client.Logical().ReadWithContext(context.Background(), "secret/foo"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if client.Address() != primary.URL {
+		t.Fatalf("expected client to keep address %q, got %q", primary.URL, client.Address())
+	}
+}
+
+func TestClient_FailoverInvalidAddress(t *testing.T) {
+	t.Parallel()
+
+	config := DefaultConfig()
+	config.FailoverAddresses = []string{"unix:///var/run/vault.sock"}
+
+	if _, err := NewClient(config); err == nil {
+		t.Fatal("expected an error for a unix socket failover address")
+	}
+}
+
+func TestClient_FailoverAddressesFromEnv(t *testing.T) {
+	t.Setenv(EnvVaultFailoverAddrs, " https://vault-c:8200, https://vault-a:8200,,https://vault-c:8200,https://vault-b:8200")
+
+	config := DefaultConfig()
+	if config.Error != nil {
+		t.Fatal(config.Error)
+	}
+
+	// The addresses are tried in the given order, so it must be kept.
+	expected := []string{"https://vault-c:8200", "https://vault-a:8200", "https://vault-b:8200"}
+	if !reflect.DeepEqual(config.FailoverAddresses, expected) {
+		t.Fatalf("expected %v, got %v", expected, config.FailoverAddresses)
+	}
+}
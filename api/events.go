@@ -0,0 +1,262 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cenkalti/backoff/v3"
+	"nhooyr.io/websocket"
+)
+
+// DefaultEventSubscriptionBuffer is the default size of the buffer for
+// received events. Once it is full, the subscription stops reading from the
+// server until the consumer catches up.
+const DefaultEventSubscriptionBuffer = 64
+
+// ErrEventsNotFound is returned when the server does not expose the events
+// endpoint, for example because the events experiment is disabled.
+var ErrEventsNotFound = errors.New("events endpoint not found; check `vault read sys/experiments` to see if an events experiment is available but disabled")
+
+// Events is used to subscribe to Vault's event notifications.
+type Events struct {
+	c *Client
+}
+
+// Events is used to return the client for event-related API calls.
+func (c *Client) Events() *Events {
+	return &Events{c: c}
+}
+
+// EventSubscribeOptions customizes an event subscription.
+type EventSubscribeOptions struct {
+	// Namespaces are additional child namespace patterns to subscribe to,
+	// relative to the client's namespace. Patterns may contain "*".
+	Namespaces []string
+
+	// BufferSize is the size of the buffer for received events. Defaults to
+	// DefaultEventSubscriptionBuffer.
+	BufferSize int
+
+	// MaxReconnectInterval caps the backoff between reconnection attempts
+	// after the connection is lost. Defaults to one minute.
+	MaxReconnectInterval time.Duration
+}
+
+// Event is a Vault event notification, decoded from its CloudEvents JSON
+// representation.
+type Event struct {
+	ID              string        `json:"id"`
+	Source          string        `json:"source"`
+	SpecVersion     string        `json:"specversion"`
+	Type            string        `json:"type"`
+	DataContentType string        `json:"datacontentype"`
+	Time            time.Time     `json:"time"`
+	Data            EventReceived `json:"data"`
+}
+
+// EventReceived is the payload of an Event.
+type EventReceived struct {
+	Event      EventData        `json:"event"`
+	Namespace  string           `json:"namespace"`
+	EventType  string           `json:"event_type"`
+	PluginInfo *EventPluginInfo `json:"plugin_info,omitempty"`
+}
+
+// EventData holds the details of an event as sent by the plugin that emitted
+// it.
+type EventData struct {
+	ID        string          `json:"id"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	EntityIDs []string        `json:"entity_ids,omitempty"`
+	Note      string          `json:"note,omitempty"`
+}
+
+// EventPluginInfo describes the plugin and mount that emitted an event.
+type EventPluginInfo struct {
+	MountClass    string `json:"mount_class"`
+	MountAccessor string `json:"mount_accessor"`
+	MountPath     string `json:"mount_path"`
+	Plugin        string `json:"plugin"`
+	PluginVersion string `json:"plugin_version"`
+	Version       string `json:"version"`
+}
+
+// EventSubscription is a live subscription returned by Events.Subscribe.
+type EventSubscription struct {
+	eventCh chan *Event
+	errCh   chan error
+}
+
+// Events returns the channel on which received events are published. It is
+// closed once the subscription's context is done.
+func (s *EventSubscription) Events() <-chan *Event {
+	return s.eventCh
+}
+
+// Errors returns a channel reporting connection and decoding errors. The
+// subscription reconnects on its own after a connection error; errors are
+// dropped if nobody is reading them.
+func (s *EventSubscription) Errors() <-chan error {
+	return s.errCh
+}
+
+func (s *EventSubscription) reportError(err error) {
+	select {
+	case s.errCh <- err:
+	default:
+	}
+}
+
+// Subscribe subscribes to events of the given type (topic), which may be a
+// glob pattern using "*". The initial connection is made before returning so
+// that authorization and configuration errors are reported immediately. If
+// the connection is later lost, it is re-established with an exponential
+// backoff until the context is cancelled.
+func (e *Events) Subscribe(ctx context.Context, eventType string, opts *EventSubscribeOptions) (*EventSubscription, error) {
+	if opts == nil {
+		opts = &EventSubscribeOptions{}
+	}
+
+	bufferSize := opts.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventSubscriptionBuffer
+	}
+
+	maxInterval := opts.MaxReconnectInterval
+	if maxInterval <= 0 {
+		maxInterval = time.Minute
+	}
+
+	conn, err := e.dial(ctx, eventType, opts.Namespaces)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &EventSubscription{
+		eventCh: make(chan *Event, bufferSize),
+		errCh:   make(chan error, 1),
+	}
+
+	go func() {
+		defer close(sub.eventCh)
+
+		reconnectBackoff := &backoff.ExponentialBackOff{
+			RandomizationFactor: backoff.DefaultRandomizationFactor,
+			InitialInterval:     time.Second,
+			MaxInterval:         maxInterval,
+			Multiplier:          2,
+			Clock:               backoff.SystemClock,
+		}
+
+		for {
+			if conn != nil {
+				reconnectBackoff.Reset()
+				err := e.read(ctx, conn, sub)
+				conn.Close(websocket.StatusNormalClosure, "")
+				conn = nil
+				if ctx.Err() != nil {
+					return
+				}
+				sub.reportError(err)
+			}
+
+			timer := time.NewTimer(reconnectBackoff.NextBackOff())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			conn, err = e.dial(ctx, eventType, opts.Namespaces)
+			if err != nil {
+				sub.reportError(err)
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// read decodes events from the connection until it fails. Sending blocks
+// while the event buffer is full, which stops reading from the server.
+func (e *Events) read(ctx context.Context, conn *websocket.Conn, sub *EventSubscription) error {
+	for {
+		_, message, err := conn.Read(ctx)
+		if err != nil {
+			return err
+		}
+
+		event := &Event{}
+		if err := json.Unmarshal(message, event); err != nil {
+			sub.reportError(fmt.Errorf("error decoding event: %w", err))
+			continue
+		}
+
+		select {
+		case sub.eventCh <- event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// dial opens the WebSocket connection for the subscription, following
+// redirects to the active node.
+func (e *Events) dial(ctx context.Context, eventType string, namespaces []string) (*websocket.Conn, error) {
+	r := e.c.NewRequest(http.MethodGet, "/v1/sys/events/subscribe/"+eventType)
+	u := r.URL
+	if u.Scheme == "http" {
+		u.Scheme = "ws"
+	} else {
+		u.Scheme = "wss"
+	}
+	q := u.Query()
+	q.Set("json", "true")
+	for _, ns := range namespaces {
+		q.Add("namespaces", strings.Trim(strings.TrimSpace(ns), "/"))
+	}
+	u.RawQuery = q.Encode()
+
+	headers := e.c.Headers()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	headers.Set(AuthHeaderName, e.c.Token())
+	if ns := e.c.Namespace(); ns != "" {
+		headers.Set(NamespaceHeaderName, ns)
+	}
+
+	addr := u.String()
+	for attempt := 0; attempt < 10; attempt++ {
+		conn, resp, err := websocket.Dial(ctx, addr, &websocket.DialOptions{
+			HTTPClient: e.c.CloneConfig().HttpClient,
+			HTTPHeader: headers,
+		})
+		if err == nil {
+			return conn, nil
+		}
+
+		switch {
+		case resp == nil:
+			return nil, err
+		case resp.StatusCode == http.StatusTemporaryRedirect:
+			addr = resp.Header.Get("Location")
+			continue
+		case resp.StatusCode == http.StatusNotFound:
+			return nil, ErrEventsNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("too many redirects")
+}
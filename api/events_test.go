@@ -0,0 +1,93 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+func TestEvents_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	var connections int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/sys/events/subscribe/kv*" || r.URL.Query().Get("json") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get(AuthHeaderName) != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		n := atomic.AddInt32(&connections, 1)
+
+		// Each connection sends a single event and is then dropped, forcing
+		// the subscription to reconnect.
+		msg := fmt.Sprintf(`{"id":"event-%d","source":"https://vaultproject.io/","specversion":"1.0","type":"*","datacontentype":"application/cloudevents","time":"2023-09-12T15:19:49.394915-07:00","data":{"event":{"id":"event-%d","metadata":{"path":"secret/foo"}},"event_type":"kv-v2/data-write","plugin_info":{"mount_class":"secret","mount_path":"secret/","plugin":"kv"}}}`, n, n)
+		conn.Write(r.Context(), websocket.MessageText, []byte(msg))
+		conn.Close(websocket.StatusNormalClosure, "")
+	}))
+	defer server.Close()
+
+	config := DefaultConfig()
+	config.Address = server.URL
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client.SetToken("bad")
+	if _, err := client.Events().Subscribe(ctx, "kv*", nil); err == nil {
+		t.Fatal("expected an error subscribing with a bad token")
+	}
+
+	client.SetToken("root")
+	if _, err := client.Events().Subscribe(ctx, "missing", nil); err != ErrEventsNotFound {
+		t.Fatalf("expected %v, got %v", ErrEventsNotFound, err)
+	}
+
+	sub, err := client.Events().Subscribe(ctx, "kv*", &EventSubscribeOptions{
+		MaxReconnectInterval: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case event := <-sub.Events():
+			if event.ID != fmt.Sprintf("event-%d", i) {
+				t.Fatalf("unexpected event ID %q", event.ID)
+			}
+			if event.Data.EventType != "kv-v2/data-write" {
+				t.Fatalf("unexpected event type %q", event.Data.EventType)
+			}
+			if event.Data.PluginInfo == nil || event.Data.PluginInfo.MountPath != "secret/" {
+				t.Fatalf("unexpected plugin info %#v", event.Data.PluginInfo)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+
+	cancel()
+	for range sub.Events() {
+	}
+}
@@ -23,12 +23,14 @@ require (
 	github.com/mitchellh/mapstructure v1.5.0
 	golang.org/x/net v0.7.0
 	golang.org/x/time v0.0.0-20200416051211-89c76fbcd5d1
+	nhooyr.io/websocket v1.8.7
 )
 
 require (
 	github.com/fatih/color v1.7.0 // indirect
 	github.com/google/go-cmp v0.5.7 // indirect
 	github.com/hashicorp/go-sockaddr v1.0.2 // indirect
+	github.com/klauspost/compress v1.10.3 // indirect
 	github.com/mattn/go-colorable v0.1.6 // indirect
 	github.com/mattn/go-isatty v0.0.12 // indirect
 	github.com/mitchellh/go-homedir v1.1.0 // indirect
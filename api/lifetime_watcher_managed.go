@@ -0,0 +1,319 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v3"
+)
+
+var ErrManagedSecretMissingFetch = errors.New("missing fetch function")
+
+// DefaultManagedSecretEventBuffer is the default size of the buffer for
+// events on a ManagedSecret's channel.
+const DefaultManagedSecretEventBuffer = 5
+
+// SecretFetcher fetches a fresh copy of a secret, for example by re-reading a
+// dynamic secrets path or by logging in again.
+type SecretFetcher func(ctx context.Context) (*Secret, error)
+
+// LifetimeEventType identifies what happened to a ManagedSecret.
+type LifetimeEventType uint
+
+const (
+	// LifetimeEventFetched is sent when a new secret has been fetched, either
+	// initially or because the previous one could no longer be renewed.
+	LifetimeEventFetched LifetimeEventType = iota
+
+	// LifetimeEventRenewed is sent when the lease or token of the current
+	// secret has been renewed.
+	LifetimeEventRenewed
+
+	// LifetimeEventExpiring is sent when the current secret can no longer be
+	// renewed and is about to be replaced by a fresh one.
+	LifetimeEventExpiring
+
+	// LifetimeEventFetchError is sent when fetching a new secret failed. The
+	// fetch is retried with an exponential backoff.
+	LifetimeEventFetchError
+)
+
+func (t LifetimeEventType) String() string {
+	switch t {
+	case LifetimeEventFetched:
+		return "fetched"
+	case LifetimeEventRenewed:
+		return "renewed"
+	case LifetimeEventExpiring:
+		return "expiring"
+	case LifetimeEventFetchError:
+		return "fetch-error"
+	}
+	return "unknown"
+}
+
+// LifetimeEvent is sent on a ManagedSecret's event channel.
+type LifetimeEvent struct {
+	// Type is the kind of event.
+	Type LifetimeEventType
+
+	// Time is when the event took place (UTC).
+	Time time.Time
+
+	// Secret is the newly fetched secret for LifetimeEventFetched, the
+	// renewal data for LifetimeEventRenewed and the expiring secret for
+	// LifetimeEventExpiring.
+	Secret *Secret
+
+	// Err is set for LifetimeEventFetchError, and for LifetimeEventExpiring
+	// when the renewal stopped because of an error.
+	Err error
+}
+
+// ManagedSecretInput is used as input to NewManagedSecret.
+type ManagedSecretInput struct {
+	// Fetch is used to obtain the secret initially, when Secret is nil, and
+	// every time the current secret can no longer be renewed.
+	Fetch SecretFetcher
+
+	// Secret is an optional, already fetched, initial secret.
+	Secret *Secret
+
+	// Rand is the randomizer to use for renewal jitter. If not provided, one
+	// will be generated and seeded automatically.
+	Rand *rand.Rand
+
+	// Increment is the new TTL, in seconds, requested on each renewal. See
+	// LifetimeWatcherInput.
+	Increment int
+
+	// RenewBehavior controls what happens when a renewal errors or the
+	// secret is not renewable. See LifetimeWatcherInput.
+	RenewBehavior RenewBehavior
+
+	// EventBuffer is the size of the buffered channel where events are
+	// dispatched.
+	EventBuffer int
+}
+
+// ManagedSecret keeps a secret alive: it renews the lease or token of the
+// current secret with a LifetimeWatcher and fetches a new secret once renewal
+// is no longer possible, reporting each step as a LifetimeEvent.
+//
+//	managed, err := client.NewManagedSecret(&ManagedSecretInput{
+//		Fetch: func(ctx context.Context) (*Secret, error) {
+//			return client.Logical().ReadWithContext(ctx, "database/creds/readonly")
+//		},
+//	})
+//	go managed.Start(ctx)
+//	defer managed.Stop()
+//
+//	for event := range managed.EventCh() {
+//		switch event.Type {
+//		case LifetimeEventFetched:
+//			// Reconfigure the consumer with event.Secret
+//		case LifetimeEventFetchError:
+//			log.Printf("failed to fetch secret: %v", event.Err)
+//		}
+//	}
+//
+// The event channel is closed once Start returns.
+type ManagedSecret struct {
+	l sync.Mutex
+
+	client        *Client
+	fetch         SecretFetcher
+	secret        *Secret
+	random        *rand.Rand
+	increment     int
+	renewBehavior RenewBehavior
+	eventCh       chan *LifetimeEvent
+
+	stopped bool
+	stopCh  chan struct{}
+}
+
+// NewManagedSecret creates a new ManagedSecret from the given input.
+func (c *Client) NewManagedSecret(i *ManagedSecretInput) (*ManagedSecret, error) {
+	if i == nil {
+		return nil, ErrLifetimeWatcherMissingInput
+	}
+
+	if i.Fetch == nil {
+		return nil, ErrManagedSecretMissingFetch
+	}
+
+	random := i.Rand
+	if random == nil {
+		// NOTE:
+		// Rather than a cryptographically secure random number generator (RNG),
+		// the default behavior uses the math/rand package. The random number is
+		// used to introduce a slight jitter when calculating the grace period
+		// for a monitored secret monitoring. This is intended to stagger renewal
+		// requests to the Vault server, but in a semi-predictable way, so there
+		// is no need to use a cryptographically secure RNG.
+		random = rand.New(rand.NewSource(int64(time.Now().Nanosecond())))
+	}
+
+	eventBuffer := i.EventBuffer
+	if eventBuffer == 0 {
+		eventBuffer = DefaultManagedSecretEventBuffer
+	}
+
+	return &ManagedSecret{
+		client:        c,
+		fetch:         i.Fetch,
+		secret:        i.Secret,
+		random:        random,
+		increment:     i.Increment,
+		renewBehavior: i.RenewBehavior,
+		eventCh:       make(chan *LifetimeEvent, eventBuffer),
+		stopCh:        make(chan struct{}),
+	}, nil
+}
+
+// EventCh returns the channel where lifetime events are published. It is
+// closed when Start returns.
+func (m *ManagedSecret) EventCh() <-chan *LifetimeEvent {
+	return m.eventCh
+}
+
+// Secret returns the most recently fetched secret, or nil if none has been
+// fetched yet.
+func (m *ManagedSecret) Secret() *Secret {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	return m.secret
+}
+
+// Stop stops the managed secret; Start returns shortly afterwards.
+func (m *ManagedSecret) Stop() {
+	m.l.Lock()
+	defer m.l.Unlock()
+
+	if !m.stopped {
+		close(m.stopCh)
+		m.stopped = true
+	}
+}
+
+// Start fetches the secret if needed and keeps it alive until the context is
+// cancelled or Stop is called. It blocks, so it is typically run in its own
+// goroutine.
+func (m *ManagedSecret) Start(ctx context.Context) {
+	defer close(m.eventCh)
+
+	for {
+		secret := m.Secret()
+		if secret == nil {
+			var ok bool
+			if secret, ok = m.fetchWithBackoff(ctx); !ok {
+				return
+			}
+		}
+
+		if !m.watch(ctx, secret) {
+			return
+		}
+
+		m.l.Lock()
+		m.secret = nil
+		m.l.Unlock()
+	}
+}
+
+// watch renews the given secret until it can no longer be renewed. It returns
+// false if the manager was stopped in the meantime.
+func (m *ManagedSecret) watch(ctx context.Context, secret *Secret) bool {
+	watcher, err := m.client.NewLifetimeWatcher(&LifetimeWatcherInput{
+		Secret:        secret,
+		Rand:          m.random,
+		Increment:     m.increment,
+		RenewBehavior: m.renewBehavior,
+	})
+	if err != nil {
+		return m.send(ctx, &LifetimeEvent{Type: LifetimeEventExpiring, Secret: secret, Err: err})
+	}
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-m.stopCh:
+			return false
+		case renewal := <-watcher.RenewCh():
+			if !m.send(ctx, &LifetimeEvent{Type: LifetimeEventRenewed, Secret: renewal.Secret}) {
+				return false
+			}
+		case err := <-watcher.DoneCh():
+			return m.send(ctx, &LifetimeEvent{Type: LifetimeEventExpiring, Secret: secret, Err: err})
+		}
+	}
+}
+
+// fetchWithBackoff fetches a new secret, retrying with an exponential backoff
+// until it succeeds. It returns false if the manager was stopped first.
+func (m *ManagedSecret) fetchWithBackoff(ctx context.Context) (*Secret, bool) {
+	retryBackoff := &backoff.ExponentialBackOff{
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		InitialInterval:     time.Second,
+		MaxInterval:         5 * time.Minute,
+		Multiplier:          2,
+		Clock:               backoff.SystemClock,
+	}
+	retryBackoff.Reset()
+
+	for {
+		secret, err := m.fetch(ctx)
+		if err == nil && secret == nil {
+			err = ErrLifetimeWatcherNoSecretData
+		}
+		if err == nil {
+			m.l.Lock()
+			m.secret = secret
+			m.l.Unlock()
+
+			return secret, m.send(ctx, &LifetimeEvent{Type: LifetimeEventFetched, Secret: secret})
+		}
+
+		if !m.send(ctx, &LifetimeEvent{Type: LifetimeEventFetchError, Err: err}) {
+			return nil, false
+		}
+
+		timer := time.NewTimer(retryBackoff.NextBackOff())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, false
+		case <-m.stopCh:
+			timer.Stop()
+			return nil, false
+		case <-timer.C:
+		}
+	}
+}
+
+// send publishes an event, returning false if the manager was stopped before
+// it could be delivered.
+func (m *ManagedSecret) send(ctx context.Context, event *LifetimeEvent) bool {
+	event.Time = time.Now().UTC()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-m.stopCh:
+		return false
+	case m.eventCh <- event:
+		return true
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManagedSecret_NewManagedSecret(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.NewManagedSecret(nil); err != ErrLifetimeWatcherMissingInput {
+		t.Fatalf("expected %v, got %v", ErrLifetimeWatcherMissingInput, err)
+	}
+
+	if _, err := client.NewManagedSecret(&ManagedSecretInput{}); err != ErrManagedSecretMissingFetch {
+		t.Fatalf("expected %v, got %v", ErrManagedSecretMissingFetch, err)
+	}
+}
+
+func TestManagedSecret_FetchesOnExpiry(t *testing.T) {
+	t.Parallel()
+
+	client, err := NewClient(DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetches := 0
+	managed, err := client.NewManagedSecret(&ManagedSecretInput{
+		Fetch: func(context.Context) (*Secret, error) {
+			fetches++
+			if fetches == 1 {
+				return nil, errors.New("transient failure")
+			}
+			return &Secret{
+				LeaseID:       "database/creds/readonly/abcd",
+				LeaseDuration: 1,
+			}, nil
+		},
+		RenewBehavior: RenewBehaviorRenewDisabled,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	go managed.Start(ctx)
+
+	expected := []LifetimeEventType{
+		LifetimeEventFetchError,
+		LifetimeEventFetched,
+		LifetimeEventExpiring,
+		LifetimeEventFetched,
+	}
+	for _, want := range expected {
+		select {
+		case event := <-managed.EventCh():
+			if event.Type != want {
+				t.Fatalf("expected %q event, got %q (err: %v)", want, event.Type, event.Err)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q event", want)
+		}
+	}
+
+	if managed.Secret() == nil {
+		t.Fatal("expected a current secret")
+	}
+
+	managed.Stop()
+	for range managed.EventCh() {
+	}
+}
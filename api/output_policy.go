@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
 )
 
 const (
@@ -22,6 +24,10 @@ type OutputPolicyError struct {
 	path           string
 	params         url.Values
 	finalHCLString string
+
+	// prerequisites are requests performed before this one whose
+	// capabilities must also be granted by the generated policy.
+	prerequisites []*OutputPolicyError
 }
 
 func (d *OutputPolicyError) Error() string {
@@ -47,15 +53,44 @@ func (d *OutputPolicyError) HCLString() (string, error) {
 	return d.finalHCLString, nil
 }
 
-// Builds a sample policy document from the request
+// Builds a sample policy document from the request and its prerequisites.
+// Capabilities for the same path are merged into a single stanza.
 func (d *OutputPolicyError) buildSamplePolicy() (string, error) {
+	var paths []string
+	pathCapabilities := make(map[string][]string)
+	for _, r := range append(append([]*OutputPolicyError{}, d.prerequisites...), d) {
+		capabilities, err := r.capabilities()
+		if err != nil {
+			return "", err
+		}
+
+		if _, ok := pathCapabilities[r.path]; !ok {
+			paths = append(paths, r.path)
+		}
+		for _, c := range capabilities {
+			if !strutil.StrListContains(pathCapabilities[r.path], c) {
+				pathCapabilities[r.path] = append(pathCapabilities[r.path], c)
+			}
+		}
+	}
+
+	stanzas := make([]string, 0, len(paths))
+	for _, path := range paths {
+		stanzas = append(stanzas, formatOutputPolicy(path, pathCapabilities[path]))
+	}
+
+	return strings.Join(stanzas, "\n\n"), nil
+}
+
+// capabilities returns the capabilities required to perform the request.
+func (d *OutputPolicyError) capabilities() ([]string, error) {
 	operation := d.method
 	// List is often defined as a URL param instead of as an http.Method
 	// this will check for the header and properly switch off of the intended functionality
 	if d.params.Has("list") {
 		isList, err := strconv.ParseBool(d.params.Get("list"))
 		if err != nil {
-			return "", fmt.Errorf("the value of the list url param is not a bool: %v", err)
+			return nil, fmt.Errorf("the value of the list url param is not a bool: %v", err)
 		}
 
 		if isList {
@@ -83,7 +118,7 @@ func (d *OutputPolicyError) buildSamplePolicy() (string, error) {
 		capabilities = append(capabilities, "sudo")
 	}
 
-	return formatOutputPolicy(d.path, capabilities), nil
+	return capabilities, nil
 }
 
 func formatOutputPolicy(path string, capabilities []string) string {
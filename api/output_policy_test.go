@@ -66,6 +66,37 @@ func TestBuildSamplePolicy(t *testing.T) {
 			formatOutputPolicy("/sys/config/ui/headers", []string{"read", "sudo"}),
 			nil,
 		},
+		{ // prerequisites are included ahead of the request itself
+			"prerequisites on another path",
+			&OutputPolicyError{
+				method: http.MethodPatch,
+				path:   "/secret/data/foo",
+				prerequisites: []*OutputPolicyError{
+					{
+						method: http.MethodGet,
+						path:   "/secret/metadata/foo",
+					},
+				},
+			},
+			formatOutputPolicy("/secret/metadata/foo", []string{"read"}) + "\n\n" +
+				formatOutputPolicy("/secret/data/foo", []string{"patch"}),
+			nil,
+		},
+		{ // capabilities for the same path are merged into a single stanza
+			"prerequisites on the same path",
+			&OutputPolicyError{
+				method: http.MethodPut,
+				path:   "/secret/data/foo",
+				prerequisites: []*OutputPolicyError{
+					{
+						method: http.MethodGet,
+						path:   "/secret/data/foo",
+					},
+				},
+			},
+			formatOutputPolicy("/secret/data/foo", []string{"read", "create", "update"}),
+			nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// AuditTailOptions narrows down the audit entries streamed by AuditTail.
+type AuditTailOptions struct {
+	// Filters are expressions of the form "<field> <operator> <value>", all of
+	// which must match for an entry to be streamed.
+	Filters []string
+
+	// Device is the path of the audit device whose salt is used to hash the
+	// streamed entries. The first enabled device is used if empty.
+	Device string
+}
+
+// AuditTail returns a channel that outputs JSON-encoded audit entries, one per
+// string, as they are logged by the server the client is connected to.
+func (c *Sys) AuditTail(ctx context.Context, opts *AuditTailOptions) (chan string, error) {
+	r := c.c.NewRequest(http.MethodGet, "/v1/sys/audit-tail")
+
+	if opts != nil {
+		for _, filter := range opts.Filters {
+			r.Params.Add("filter", filter)
+		}
+		if opts.Device != "" {
+			r.Params.Add("device", opts.Device)
+		}
+	}
+
+	resp, err := c.c.RawRequestWithContext(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	entryCh := make(chan string, 64)
+
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		droppedCount := 0
+
+		defer close(entryCh)
+		defer resp.Body.Close()
+
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+
+			if !scanner.Scan() {
+				return
+			}
+
+			entry := scanner.Text()
+
+			if droppedCount > 0 {
+				select {
+				case entryCh <- fmt.Sprintf("Audit tail dropped %d entries during request\n", droppedCount):
+					droppedCount = 0
+				default:
+					droppedCount++
+					continue
+				}
+			}
+
+			select {
+			case entryCh <- entry:
+			default:
+				droppedCount++
+			}
+		}
+	}()
+
+	return entryCh, nil
+}
@@ -62,6 +62,7 @@ type InitRequest struct {
 	RecoveryThreshold int      `json:"recovery_threshold"`
 	RecoveryPGPKeys   []string `json:"recovery_pgp_keys"`
 	RootTokenPGPKey   string   `json:"root_token_pgp_key"`
+	BarrierAlgorithm  string   `json:"barrier_algorithm,omitempty"`
 }
 
 type InitStatusResponse struct {
@@ -0,0 +1,94 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// MountExportInput is used as input to the ExportMount function.
+type MountExportInput struct {
+	Mount      string `json:"mount"`
+	Passphrase string `json:"passphrase"`
+}
+
+// MountExportOutput is the response of the ExportMount function.
+type MountExportOutput struct {
+	Archive string `json:"archive" mapstructure:"archive"`
+	Type    string `json:"type" mapstructure:"type"`
+	Entries int    `json:"entries" mapstructure:"entries"`
+}
+
+// MountImportInput is used as input to the ImportMount function.
+type MountImportInput struct {
+	Mount      string `json:"mount"`
+	Passphrase string `json:"passphrase"`
+	Archive    string `json:"archive"`
+}
+
+// MountImportOutput is the response of the ImportMount function.
+type MountImportOutput struct {
+	Type    string `json:"type" mapstructure:"type"`
+	Entries int    `json:"entries" mapstructure:"entries"`
+}
+
+// ExportMount exports the data of a mount to an encrypted archive.
+func (c *Sys) ExportMount(input *MountExportInput) (*MountExportOutput, error) {
+	return c.ExportMountWithContext(context.Background(), input)
+}
+
+// ExportMountWithContext exports the data of a mount to an encrypted archive.
+func (c *Sys) ExportMountWithContext(ctx context.Context, input *MountExportInput) (*MountExportOutput, error) {
+	var result MountExportOutput
+	if err := c.migrationRequest(ctx, "/v1/sys/migration/export", input, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ImportMount replaces the data of a mount with the content of an archive
+// returned by ExportMount.
+func (c *Sys) ImportMount(input *MountImportInput) (*MountImportOutput, error) {
+	return c.ImportMountWithContext(context.Background(), input)
+}
+
+// ImportMountWithContext replaces the data of a mount with the content of an
+// archive returned by ExportMount.
+func (c *Sys) ImportMountWithContext(ctx context.Context, input *MountImportInput) (*MountImportOutput, error) {
+	var result MountImportOutput
+	if err := c.migrationRequest(ctx, "/v1/sys/migration/import", input, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Sys) migrationRequest(ctx context.Context, path string, input, result interface{}) error {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	r := c.c.NewRequest(http.MethodPost, path)
+	if err := r.SetJSONBody(input); err != nil {
+		return err
+	}
+
+	resp, err := c.c.rawRequestWithContext(ctx, r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	secret, err := ParseSecret(resp.Body)
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Data == nil {
+		return errors.New("data from server response is empty")
+	}
+
+	return mapstructure.WeakDecode(secret.Data, result)
+}
@@ -145,6 +145,7 @@ type GetPluginResponse struct {
 	Builtin           bool     `json:"builtin"`
 	Command           string   `json:"command"`
 	OCIImage          string   `json:"oci_image"`
+	Runtime           string   `json:"runtime"`
 	Name              string   `json:"name"`
 	SHA256            string   `json:"sha256"`
 	DeprecationStatus string   `json:"deprecation_status,omitempty"`
@@ -206,6 +207,9 @@ type RegisterPluginInput struct {
 	// OCIImage specifies the container image to run as a plugin.
 	OCIImage string `json:"oci_image,omitempty"`
 
+	// Runtime is the name of the plugin runtime to run the OCI image with.
+	Runtime string `json:"runtime,omitempty"`
+
 	// Env specifies a list of key=value pairs to add to the plugin's environment
 	// variables.
 	Env []string `json:"env,omitempty"`
@@ -235,6 +239,81 @@ func (c *Sys) RegisterPluginWithContext(ctx context.Context, i *RegisterPluginIn
 	return err
 }
 
+// InstallPluginInput is used as input to the InstallPlugin function.
+type InstallPluginInput struct {
+	// Name is the name of the plugin. Required.
+	Name string `json:"name"`
+
+	// Type of the plugin. Required.
+	Type PluginType `json:"-"`
+
+	// Version is the version of the plugin to install. Required.
+	Version string `json:"version"`
+
+	// URL is the location of the plugin binary. Defaults to the plugin's
+	// location in the server's configured plugin registry.
+	URL string `json:"url,omitempty"`
+
+	// SignatureURL is the location of the plugin's detached signature.
+	// Defaults to URL with a .sig suffix.
+	SignatureURL string `json:"signature_url,omitempty"`
+
+	// SHA256 is the optional expected shasum of the plugin.
+	SHA256 string `json:"sha256,omitempty"`
+
+	// Args is the list of args to spawn the process with.
+	Args []string `json:"args,omitempty"`
+
+	// Env specifies a list of key=value pairs to add to the plugin's environment
+	// variables.
+	Env []string `json:"env,omitempty"`
+}
+
+// InstallPluginResponse is the response from the InstallPlugin call.
+type InstallPluginResponse struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+	Command string `json:"command"`
+	SHA256  string `json:"sha256"`
+}
+
+// InstallPlugin wraps InstallPluginWithContext using context.Background.
+func (c *Sys) InstallPlugin(i *InstallPluginInput) (*InstallPluginResponse, error) {
+	return c.InstallPluginWithContext(context.Background(), i)
+}
+
+// InstallPluginWithContext downloads the plugin with the given information,
+// verifies its signature and registers it in the catalog.
+func (c *Sys) InstallPluginWithContext(ctx context.Context, i *InstallPluginInput) (*InstallPluginResponse, error) {
+	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
+	defer cancelFunc()
+
+	req := c.c.NewRequest(http.MethodPut, "/v1/sys/plugins/install")
+	body := struct {
+		*InstallPluginInput
+		Type string `json:"type"`
+	}{i, i.Type.String()}
+	if err := req.SetJSONBody(body); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.c.rawRequestWithContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data *InstallPluginResponse
+	}
+	err = resp.DecodeJSON(&result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Data, err
+}
+
 // DeregisterPluginInput is used as input to the DeregisterPlugin function.
 type DeregisterPluginInput struct {
 	// Name is the name of the plugin. Required.
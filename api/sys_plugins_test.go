@@ -5,6 +5,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -32,6 +33,38 @@ func TestRegisterPlugin(t *testing.T) {
 	}
 }
 
+func TestInstallPlugin(t *testing.T) {
+	mockVaultServer := httptest.NewServer(http.HandlerFunc(mockVaultHandlerInstall(t)))
+	defer mockVaultServer.Close()
+
+	cfg := DefaultConfig()
+	cfg.Address = mockVaultServer.URL
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := client.Sys().InstallPluginWithContext(context.Background(), &InstallPluginInput{
+		Name:    "my-plugin",
+		Type:    PluginTypeSecrets,
+		Version: "v1.0.0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &InstallPluginResponse{
+		Name:    "my-plugin",
+		Type:    "secret",
+		Version: "v1.0.0",
+		Command: "secret_my-plugin_v1.0.0",
+		SHA256:  "8ba442dba2f6c8bd0e2c4a0c2dc24c2b8bd1d1c9d3d1c9d3c8ba442dba2f6c8b",
+	}
+	if !reflect.DeepEqual(resp, expected) {
+		t.Fatalf("expected %#v, got %#v", expected, resp)
+	}
+}
+
 func TestListPlugins(t *testing.T) {
 	mockVaultServer := httptest.NewServer(http.HandlerFunc(mockVaultHandlerList))
 	defer mockVaultServer.Close()
@@ -322,3 +355,26 @@ func mockVaultHandlerRegister(w http.ResponseWriter, _ *http.Request) {
 }
 
 const registerResponse = `{}`
+
+func mockVaultHandlerInstall(t *testing.T) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		if r.URL.Path != "/v1/sys/plugins/install" || body["name"] != "my-plugin" || body["type"] != "secret" || body["version"] != "v1.0.0" {
+			t.Errorf("unexpected request to %s: %#v", r.URL.Path, body)
+		}
+		_, _ = w.Write([]byte(installResponse))
+	}
+}
+
+const installResponse = `{
+  "data": {
+    "name": "my-plugin",
+    "type": "secret",
+    "version": "v1.0.0",
+    "command": "secret_my-plugin_v1.0.0",
+    "sha256": "8ba442dba2f6c8bd0e2c4a0c2dc24c2b8bd1d1c9d3d1c9d3c8ba442dba2f6c8b"
+  }
+}`
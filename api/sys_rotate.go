@@ -16,10 +16,26 @@ func (c *Sys) Rotate() error {
 }
 
 func (c *Sys) RotateWithContext(ctx context.Context) error {
+	return c.RotateWithAlgorithmWithContext(ctx, "")
+}
+
+// RotateWithAlgorithm rotates the barrier encryption key, switching to the
+// given algorithm, such as "chacha20-poly1305". An empty algorithm keeps the
+// algorithm of the active key.
+func (c *Sys) RotateWithAlgorithm(algorithm string) error {
+	return c.RotateWithAlgorithmWithContext(context.Background(), algorithm)
+}
+
+func (c *Sys) RotateWithAlgorithmWithContext(ctx context.Context, algorithm string) error {
 	ctx, cancelFunc := c.c.withConfiguredTimeout(ctx)
 	defer cancelFunc()
 
 	r := c.c.NewRequest(http.MethodPost, "/v1/sys/rotate")
+	if algorithm != "" {
+		if err := r.SetJSONBody(map[string]interface{}{"algorithm": algorithm}); err != nil {
+			return err
+		}
+	}
 
 	resp, err := c.c.rawRequestWithContext(ctx, r)
 	if err == nil {
@@ -95,6 +111,10 @@ func (c *Sys) KeyStatusWithContext(ctx context.Context) (*KeyStatus, error) {
 		result.Encryptions = int(encryptions64)
 	}
 
+	if algorithm, ok := secret.Data["algorithm"].(string); ok {
+		result.Algorithm = algorithm
+	}
+
 	return &result, err
 }
 
@@ -102,4 +122,5 @@ type KeyStatus struct {
 	Term        int       `json:"term"`
 	InstallTime time.Time `json:"install_time"`
 	Encryptions int       `json:"encryptions"`
+	Algorithm   string    `json:"algorithm"`
 }
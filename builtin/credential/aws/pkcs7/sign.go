@@ -281,6 +281,13 @@ func (si *signerInfo) SetUnauthenticatedAttributes(extraUnsignedAttrs []Attribut
 	return nil
 }
 
+// SetContentType sets the content type of the SignedData. For example to specify the
+// content type of a time-stamp token according to RFC 3161 section 2.4.2.
+// This must be called before adding signers.
+func (sd *SignedData) SetContentType(contentType asn1.ObjectIdentifier) {
+	sd.sd.ContentInfo.ContentType = contentType
+}
+
 // AddCertificate adds the certificate to the payload. Useful for parent certificates
 func (sd *SignedData) AddCertificate(cert *x509.Certificate) {
 	sd.certs = append(sd.certs, cert)
@@ -297,9 +304,20 @@ func (sd *SignedData) GetSignedData() *signedData {
 	return &sd.sd
 }
 
+// RemoveCertificates removes the certificates of the signers from the payload,
+// such as when the recipient did not request them.
+// This must be called right before Finish()
+func (sd *SignedData) RemoveCertificates() {
+	sd.certs = nil
+}
+
 // Finish marshals the content and its signers
 func (sd *SignedData) Finish() ([]byte, error) {
-	sd.sd.Certificates = marshalCertificates(sd.certs)
+	if len(sd.certs) > 0 {
+		sd.sd.Certificates = marshalCertificates(sd.certs)
+	} else {
+		sd.sd.Certificates = rawCertificates{}
+	}
 	inner, err := asn1.Marshal(sd.sd)
 	if err != nil {
 		return nil, err
@@ -457,7 +457,7 @@ func getAcmeRoleAndIssuer(sc *storageContext, data *framework.FieldData, config
 
 func getAndValidateAcmeRole(sc *storageContext, requestedRole string) (*roleEntry, error) {
 	var err error
-	role, err := sc.Backend.getRole(sc.Context, sc.Storage, requestedRole)
+	role, err := sc.Backend.getRoleWithProfile(sc.Context, sc.Storage, requestedRole)
 	if err != nil {
 		return nil, fmt.Errorf("%w: err loading role", ErrServerInternal)
 	}
@@ -114,8 +114,9 @@ func Backend(conf *logical.BackendConfig) *backend {
 				"unified-crl",
 				"unified-ocsp",   // Unified OCSP POST
 				"unified-ocsp/*", // Unified OCSP GET
+				"timestamp",      // RFC 3161 time-stamp requests
 
-				// ACME paths are added below
+				// ACME, EST, SCEP and CMP paths are added below
 			},
 
 			LocalStorage: []string{
@@ -125,7 +126,11 @@ func Backend(conf *logical.BackendConfig) *backend {
 				clusterConfigPath,
 				"crls/",
 				"certs/",
+				certMetadataPrefix,
+				escrowedKeyPrefix,
 				acmePathPrefix,
+				ocspResponderPrefix,
+				"expiry-notifications/",
 			},
 
 			Root: []string{
@@ -137,6 +142,10 @@ func Backend(conf *logical.BackendConfig) *backend {
 				legacyCertBundlePath,
 				legacyCertBundleBackupPath,
 				keyPrefix,
+				ocspResponderPrefix,
+				storageScepConfig,
+				storageCmpConfig,
+				escrowedKeyPrefix,
 			},
 
 			WriteForwardedStorage: []string{
@@ -149,6 +158,10 @@ func Backend(conf *logical.BackendConfig) *backend {
 		Paths: []*framework.Path{
 			pathListRoles(&b),
 			pathRoles(&b),
+			pathListProfiles(&b),
+			pathProfiles(&b),
+			pathListEscrowedKeys(&b),
+			pathEscrowedKeys(&b),
 			pathGenerateRoot(&b),
 			pathSignIntermediate(&b),
 			pathSignSelfIssued(&b),
@@ -159,16 +172,27 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathConfigCRL(&b),
 			pathConfigURLs(&b),
 			pathConfigCluster(&b),
+			pathConfigPolicyWebhook(&b),
+			pathConfigExpiryNotifications(&b),
+			pathConfigIssuanceLimits(&b),
+			pathCertCounts(&b),
 			pathSignVerbatim(&b),
 			pathSign(&b),
+			pathSignWithSerial(&b),
+			pathValidateCSR(&b),
 			pathIssue(&b),
+			pathIssueWithSerial(&b),
+			pathIssueFromCert(&b),
 			pathRotateCRL(&b),
 			pathRotateDeltaCRL(&b),
 			pathRevoke(&b),
 			pathRevokeWithKey(&b),
+			pathRevokeBatch(&b),
 			pathListCertsRevoked(&b),
 			pathTidy(&b),
 			pathTidyCancel(&b),
+			pathTidyPause(&b),
+			pathTidyResume(&b),
 			pathTidyStatus(&b),
 			pathConfigAutoTidy(&b),
 
@@ -187,15 +211,25 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathRotateRoot(&b),
 			pathIssuerGenerateIntermediate(&b),
 			pathCrossSignIntermediate(&b),
+			pathCrossSignIssuer(&b),
 			pathConfigIssuers(&b),
 			pathReplaceRoot(&b),
+			pathRootRotation(&b),
+			pathRootRotationPrepare(&b),
+			pathRootRotationFlip(&b),
+			pathRootRotationRollback(&b),
 			pathRevokeIssuer(&b),
+			pathImportIssuerCRL(&b),
+			pathIssuerCRLSigner(&b),
 
 			// Key APIs
 			pathListKeys(&b),
 			pathKey(&b),
 			pathGenerateKey(&b),
 			pathImportKey(&b),
+			pathImportWrappingKey(&b),
+			pathImportWrappedKey(&b),
+			pathExportWrappedKey(&b),
 			pathConfigKeys(&b),
 
 			// Fetch APIs have been lowered to favor the newer issuer API endpoints
@@ -206,6 +240,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathFetchValidRaw(&b),
 			pathFetchValid(&b),
 			pathFetchListCerts(&b),
+			pathImportCerts(&b),
 
 			// OCSP APIs
 			buildPathOcspGet(&b),
@@ -219,6 +254,19 @@ func Backend(conf *logical.BackendConfig) *backend {
 			pathAcmeConfig(&b),
 			pathAcmeEabList(&b),
 			pathAcmeEabDelete(&b),
+
+			// EST
+			pathEstConfig(&b),
+
+			// SCEP
+			pathScepConfig(&b),
+
+			// CMP
+			pathCmpConfig(&b),
+
+			// Timestamping
+			pathTimestampingConfig(&b),
+			pathTimestamp(&b),
 		},
 
 		Secrets: []*framework.Secret{
@@ -262,8 +310,29 @@ func Backend(conf *logical.BackendConfig) *backend {
 		setupAcmeDirectory(&b, prefix.acmePrefix, prefix.unauthPrefix, prefix.opts)
 	}
 
+	// Add EST paths to backend
+	for _, prefix := range []struct {
+		estPrefix    string
+		unauthPrefix string
+	}{
+		{"est", "est"},
+		{"est/" + framework.GenericNameRegex("label"), "est/+"},
+	} {
+		setupEstPaths(&b, prefix.estPrefix, prefix.unauthPrefix)
+	}
+
+	// Add SCEP paths to backend
+	setupScepPaths(&b, "scep", "scep")
+	setupScepPaths(&b, "scep/"+framework.GenericNameRegex("label"), "scep/+")
+
+	// Add CMP paths to backend
+	setupCmpPaths(&b, "cmp", "cmp")
+	setupCmpPaths(&b, "cmp/"+framework.GenericNameRegex("label"), "cmp/+")
+
 	b.tidyCASGuard = new(uint32)
 	b.tidyCancelCAS = new(uint32)
+	b.tidyPauseCAS = new(uint32)
+	b.tidyStopCh = make(chan struct{})
 	b.tidyStatus = &tidyStatus{state: tidyStatusInactive}
 	b.storage = conf.StorageView
 	b.backendUUID = conf.BackendUUID
@@ -276,6 +345,9 @@ func Backend(conf *logical.BackendConfig) *backend {
 	cannotRebuildCRLs := conf.System.ReplicationState().HasState(consts.ReplicationPerformanceStandby) ||
 		conf.System.ReplicationState().HasState(consts.ReplicationDRSecondary)
 	b.crlBuilder = newCRLBuilder(!cannotRebuildCRLs)
+	b.ocspCache = newOcspResponseCache(b.backendUUID)
+	b.issuanceLimiter = newIssuanceLimiter()
+	b.issuanceCounts = newIssuanceCounts()
 
 	// Delay the first tidy until after we've started up.
 	b.lastTidy = time.Now()
@@ -284,6 +356,7 @@ func Backend(conf *logical.BackendConfig) *backend {
 	b.certCountEnabled = atomic2.NewBool(false)
 	b.publishCertCountMetrics = atomic2.NewBool(false)
 	b.certsCounted = atomic2.NewBool(false)
+	b.expiryScanRunning = atomic2.NewBool(false)
 	b.certCountError = "Initialize Not Yet Run, Cert Counts Unavailable"
 	b.certCount = &atomic.Uint32{}
 	b.revokedCertCount = &atomic.Uint32{}
@@ -307,6 +380,9 @@ type backend struct {
 	revokeStorageLock sync.RWMutex
 	tidyCASGuard      *uint32
 	tidyCancelCAS     *uint32
+	tidyPauseCAS      *uint32
+	// Closed when the backend is cleaned up, to stop a running tidy.
+	tidyStopCh chan struct{}
 
 	tidyStatusLock sync.RWMutex
 	tidyStatus     *tidyStatus
@@ -323,8 +399,17 @@ type backend struct {
 	possibleDoubleCountedSerials        []string
 	possibleDoubleCountedRevokedSerials []string
 
+	expiryScanRunning *atomic2.Bool
+
 	pkiStorageVersion atomic.Value
 	crlBuilder        *crlBuilder
+	ocspCache         *ocspResponseCache
+	issuanceLimiter   *issuanceLimiter
+	issuanceCounts    *issuanceCounts
+
+	// Held across the duplicate check and the store of a certificate
+	// with a caller requested serial number.
+	requestedSerialLock sync.Mutex
 
 	// Write lock around issuers and keys.
 	issuersLock sync.RWMutex
@@ -372,7 +457,7 @@ func (b *backend) metricsWrap(callType string, roleMode int, ofunc roleOperation
 		}
 		if roleMode > noRole {
 			// Get the role
-			role, err = b.getRole(ctx, req.Storage, roleName)
+			role, err = b.getRoleWithProfile(ctx, req.Storage, roleName)
 			if err != nil {
 				return nil, err
 			}
@@ -431,6 +516,7 @@ func (b *backend) initialize(ctx context.Context, ir *logical.InitializationRequ
 func (b *backend) cleanup(ctx context.Context) {
 	sc := b.makeStorageContext(ctx, b.storage)
 	b.acmeState.validator.Closing <- struct{}{}
+	close(b.tidyStopCh)
 
 	b.cleanupEnt(sc)
 }
@@ -525,16 +611,26 @@ func (b *backend) invalidate(ctx context.Context, key string) {
 			// the primary cluster would have done it already, but the CRL is cluster specific so
 			// force a rebuild of ours.
 			b.crlBuilder.requestRebuildIfActiveNode(b)
+			b.ocspCache.purge()
 		} else {
 			b.Logger().Debug("Ignoring invalidation updates for issuer as the PKI migration has yet to complete.")
 		}
 	case key == "config/crl":
 		// We may need to reload our OCSP status flag
 		b.crlBuilder.markConfigDirty()
+		b.ocspCache.purge()
 	case key == storageAcmeConfig:
 		b.acmeState.markConfigDirty()
 	case key == storageIssuerConfig:
 		b.crlBuilder.invalidateCRLBuildTime()
+		b.ocspCache.purge()
+	case strings.HasPrefix(key, "role/"):
+		// Roles changed or deleted by other nodes.
+		b.issuanceLimiter.forgetRole(strings.TrimPrefix(key, "role/"))
+	case strings.HasPrefix(key, revokedPath):
+		// Revocations written by other nodes; cached OCSP responses for
+		// this serial would otherwise still report it as good.
+		b.ocspCache.purge()
 	case strings.HasPrefix(key, crossRevocationPrefix):
 		split := strings.Split(key, "/")
 
@@ -562,6 +658,7 @@ func (b *backend) invalidate(ctx context.Context, key string) {
 		cluster := split[len(split)-2]
 		serial := split[len(split)-1]
 		b.crlBuilder.addCertFromCrossRevocation(cluster, serial)
+		b.ocspCache.purge()
 	}
 
 	b.invalidateEnt(ctx, key)
@@ -680,6 +777,22 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 		return nil
 	}
 
+	doOcspResponders := func() error {
+		// As we're (below) modifying the backing storage, we need to ensure
+		// we're not on a standby/secondary node.
+		if b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) ||
+			b.System().ReplicationState().HasState(consts.ReplicationDRSecondary) {
+			return nil
+		}
+
+		cfg, err := b.crlBuilder.getConfigWithUpdate(sc)
+		if err != nil {
+			return err
+		}
+
+		return rotateOcspResponders(sc, cfg, false)
+	}
+
 	// First tidy any ACME nonces to free memory.
 	b.acmeState.DoTidyNonces()
 
@@ -690,6 +803,8 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 	// Then run the CRL rebuild and tidy operation.
 	crlErr := doCRL()
 	tidyErr := doAutoTidy()
+	ocspErr := doOcspResponders()
+	expiryErr := b.maybeScanExpiringCerts(sc)
 
 	// Periodically re-emit gauges so that they don't disappear/go stale
 	tidyConfig, err := sc.getAutoTidyConfig()
@@ -707,6 +822,14 @@ func (b *backend) periodicFunc(ctx context.Context, request *logical.Request) er
 		errors = multierror.Append(errors, fmt.Errorf("Error running auto-tidy:\n - %w\n", tidyErr))
 	}
 
+	if ocspErr != nil {
+		errors = multierror.Append(errors, fmt.Errorf("Error rotating OCSP responders:\n - %w\n", ocspErr))
+	}
+
+	if expiryErr != nil {
+		errors = multierror.Append(errors, fmt.Errorf("Error scanning for expiring certificates:\n - %w\n", expiryErr))
+	}
+
 	if errors != nil {
 		return errors
 	}
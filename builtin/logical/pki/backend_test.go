@@ -48,6 +48,7 @@ import (
 	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/hashicorp/vault/api"
 	auth "github.com/hashicorp/vault/api/auth/userpass"
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
 	"github.com/hashicorp/vault/builtin/credential/userpass"
 	logicaltest "github.com/hashicorp/vault/helper/testhelpers/logical"
 	vaulthttp "github.com/hashicorp/vault/http"
@@ -502,6 +503,7 @@ func generateURLSteps(t *testing.T, caCert, caKey string, intdata, reqdata map[s
 			"http://example.com/ocsp1",
 			"http://example.com/ocsp2",
 		},
+		DeltaCRLDistributionPoints: []string{},
 	}
 	csrTemplate := x509.CertificateRequest{
 		Subject: pkix.Name{
@@ -3136,6 +3138,340 @@ func TestBackend_OID_SANs(t *testing.T) {
 	}
 }
 
+func TestBackend_WildcardPolicy(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"ttl":         "40h",
+		"common_name": "myvault.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allowed_domains":          "example.com",
+		"allow_subdomains":         true,
+		"wildcard_full_label_only": true,
+		"max_wildcard_depth":       4,
+		"denied_wildcard_zones":    "example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, allowed := range map[string]bool{
+		"*.prod.example.com":    true,
+		"a.b.prod.example.com":  true,
+		"*.example.com":         false,
+		"*.EXAMPLE.com":         false,
+		"f*.prod.example.com":   false,
+		"*.eu.prod.example.com": false,
+	} {
+		_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+			"common_name": name,
+		})
+		if allowed && err != nil {
+			t.Fatalf("expected %v to be allowed: %v", name, err)
+		}
+		if !allowed && err == nil {
+			t.Fatalf("expected %v to be denied", name)
+		}
+	}
+
+	// The wildcard policy also applies when any name is allowed.
+	_, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name":        true,
+		"denied_wildcard_zones": "example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "*.example.com",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "f*.example.org",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name":     true,
+		"max_wildcard_depth": -1,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestBackend_AllowedIPSANs(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"ttl":         "40h",
+		"common_name": "myvault.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Not a valid CIDR
+	_, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name":  true,
+		"allowed_ip_sans": "10.1.2.3",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	_, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name":  true,
+		"allowed_ip_sans": "10.1.0.0/16,2001:db8::/32",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "foobar.com",
+		"ip_sans":     "10.1.2.3,2001:db8::1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode([]byte(resp.Data["certificate"].(string)))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cert.IPAddresses) != 2 || cert.IPAddresses[0].String() != "10.1.2.3" || cert.IPAddresses[1].String() != "2001:db8::1" {
+		t.Fatalf("unexpected IP SANs %v", cert.IPAddresses)
+	}
+
+	// Outside of the allowed CIDRs
+	for _, ip := range []string{"10.2.0.1", "2001:db9::1", "10.1.2.3,192.168.0.1"} {
+		_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+			"common_name": "foobar.com",
+			"ip_sans":     ip,
+		})
+		if err == nil {
+			t.Fatalf("expected error for %v", ip)
+		}
+	}
+
+	// The CIDRs also apply to IP SANs taken from the CSR
+	_, err = CBPatch(b, s, "roles/test", map[string]interface{}{
+		"use_csr_sans": true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "foobar.com"},
+		IPAddresses: []net.IP{net.ParseIP("192.168.0.1")},
+	}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CBWrite(b, s, "sign/test", map[string]interface{}{
+		"csr": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestBackend_SMIME(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"ttl":         "40h",
+		"common_name": "myvault.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = CBWrite(b, s, "roles/smime", map[string]interface{}{
+		"allowed_domains":       "example.com",
+		"allow_bare_domains":    true,
+		"server_flag":           false,
+		"client_flag":           false,
+		"email_protection_flag": true,
+		"allowed_email_sans":    "*@Example.com",
+		"escrow_private_keys":   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Allowed by allowed_domains, but not allowed_email_sans
+	_, err = CBWrite(b, s, "issue/smime", map[string]interface{}{
+		"common_name": "alice@example.com",
+		"alt_names":   "alice@sub.example.com",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	resp, err := CBWrite(b, s, "issue/smime", map[string]interface{}{
+		"common_name": "Alice@example.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial := resp.Data["serial_number"].(string)
+	privateKey := resp.Data["private_key"].(string)
+
+	resp, err = CBList(b, s, "escrowed-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(resp.Data["keys"], []string{normalizeSerial(serial)}); diff != nil {
+		t.Fatal(diff)
+	}
+
+	resp, err = CBRead(b, s, "escrowed-keys/"+serial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["private_key"] != privateKey || resp.Data["role"] != "smime" {
+		t.Fatalf("unexpected escrowed key: %v", resp.Data)
+	}
+
+	// Keys not generated by Vault are not escrowed
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = CBWrite(b, s, "sign/smime", map[string]interface{}{
+		"common_name": "bob@example.com",
+		"csr":         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = CBList(b, s, "escrowed-keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data["keys"].([]string)) != 1 {
+		t.Fatalf("unexpected escrowed keys: %v", resp.Data["keys"])
+	}
+
+	_, err = CBDelete(b, s, "escrowed-keys/"+serial)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err = CBRead(b, s, "escrowed-keys/"+serial)
+	if err != nil || resp != nil {
+		t.Fatalf("expected no escrowed key: %v, %v", resp, err)
+	}
+}
+
+func TestBackend_UPNSANs(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"ttl":         "40h",
+		"common_name": "myvault.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name":     true,
+		"enforce_hostnames":  false,
+		"allowed_upn_sans":   "*@corp.example.com",
+		"allowed_other_sans": "1.3.6.1.4.1.311.20.2.4;UTF8:*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := CBRead(b, s, "roles/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(resp.Data["allowed_upn_sans"], []string{"*@corp.example.com"}); diff != nil {
+		t.Fatal(diff)
+	}
+
+	// Not an allowed UPN
+	_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "alice",
+		"upn_sans":    "alice@example.com",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	// Not a valid UPN
+	_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "alice",
+		"upn_sans":    "alice@bob@corp.example.com",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	// Allowed UPNs, both as upn_sans and other_sans
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "alice",
+		"upn_sans":    "alice@corp.example.com",
+		"other_sans":  "1.3.6.1.4.1.311.20.2.3;UTF8:Bob@CORP.example.com,1.3.6.1.4.1.311.20.2.4;UTF8:carol",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode([]byte(resp.Data["certificate"].(string)))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	others, err := getOtherSANsFromX509Extensions(cert.Extensions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var upns []string
+	for _, other := range others {
+		if other.oid == upnOID {
+			upns = append(upns, other.value)
+		}
+	}
+	sort.Strings(upns)
+	if diff := deep.Equal(upns, []string{"Bob@CORP.example.com", "alice@corp.example.com"}); diff != nil {
+		t.Fatal(diff)
+	}
+
+	// Other OIDs are still subject to allowed_other_sans
+	_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "alice",
+		"other_sans":  "1.3.6.1.4.1.311.20.2.5;UTF8:alice@corp.example.com",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
 func TestBackend_AllowedSerialNumbers(t *testing.T) {
 	t.Parallel()
 	b, s := CreateBackendWithStorage(t)
@@ -3447,7 +3783,41 @@ func TestBackend_AllowedURISANsTemplate(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// Templates never match literally, such as for tokens without an entity.
+	client.SetToken(cluster.RootToken)
+	_, err = client.Logical().Write("pki/issue/test", map[string]interface{}{"uri_sans": "spiffe://domain/{{identity.entity.aliases." + userpassAccessor + ".name}}"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	// Identity values containing globs only match exactly.
+	_, err = client.Logical().Write("identity/entity/id/"+secret.Auth.EntityID, map[string]interface{}{
+		"metadata": map[string]string{"workload": "web*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("pki/roles/test", map[string]interface{}{
+		"allowed_uri_sans":          "spiffe://domain/workload/{{identity.entity.metadata.workload}}",
+		"allowed_uri_sans_template": true,
+		"require_cn":                false,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.SetToken(userpassToken)
+	_, err = client.Logical().Write("pki/issue/test", map[string]interface{}{"uri_sans": "spiffe://domain/workload/web-admin"})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	_, err = client.Logical().Write("pki/issue/test", map[string]interface{}{"uri_sans": "spiffe://domain/workload/web*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	// Set allowed_uri_sans_template to false.
+	client.SetToken(cluster.RootToken)
 	_, err = client.Logical().Write("pki/roles/test", map[string]interface{}{
 		"allowed_uri_sans_template": false,
 	})
@@ -3456,6 +3826,7 @@ func TestBackend_AllowedURISANsTemplate(t *testing.T) {
 	}
 
 	// Issue certificate with userpassToken.
+	client.SetToken(userpassToken)
 	_, err = client.Logical().Write("pki/issue/test", map[string]interface{}{"uri_sans": "spiffe://domain/users/userpassname"})
 	if err == nil {
 		t.Fatal("expected error")
@@ -3655,6 +4026,7 @@ func TestReadWriteDeleteRoles(t *testing.T) {
 		"use_csr_sans":                       true,
 		"client_flag":                        true,
 		"allowed_serial_numbers":             []interface{}{},
+		"allowed_acme_challenges":            []interface{}{},
 		"generate_lease":                     false,
 		"signature_bits":                     json.Number("256"),
 		"use_pss":                            false,
@@ -3662,11 +4034,26 @@ func TestReadWriteDeleteRoles(t *testing.T) {
 		"allowed_uri_sans_template":          false,
 		"enforce_hostnames":                  true,
 		"policy_identifiers":                 []interface{}{},
+		"custom_extensions":                  []interface{}{},
+		"ms_certificate_template":            "",
+		"ms_template_major_version":          json.Number("100"),
+		"ms_template_minor_version":          json.Number("0"),
+		"ms_application_policies":            []interface{}{},
+		"ct_log_urls":                        []interface{}{},
+		"caa_identities":                     []interface{}{},
+		"caa_resolvers":                      []interface{}{},
+		"caa_failure_mode":                   "hard",
+		"lint_mode":                          "off",
+		"lint_ignore":                        []interface{}{},
+		"profile":                            "",
+		"certs_per_minute":                   json.Number("0"),
+		"max_concurrent_signs":               json.Number("0"),
 		"require_cn":                         true,
 		"allowed_domains_template":           false,
 		"allow_token_displayname":            false,
 		"country":                            []interface{}{},
 		"not_after":                          "",
+		"leaf_not_after_behavior":            "",
 		"postal_code":                        []interface{}{},
 		"use_csr_common_name":                true,
 		"allow_localhost":                    true,
@@ -3677,6 +4064,7 @@ func TestReadWriteDeleteRoles(t *testing.T) {
 		"basic_constraints_valid_for_non_ca": false,
 		"key_usage":                          []interface{}{"DigitalSignature", "KeyAgreement", "KeyEncipherment"},
 		"not_before_duration":                json.Number("30"),
+		"max_not_before_duration":            json.Number("0"),
 		"allow_glob_domains":                 false,
 		"ttl":                                json.Number("0"),
 		"ou":                                 []interface{}{},
@@ -3698,6 +4086,13 @@ func TestReadWriteDeleteRoles(t *testing.T) {
 		"issuer_ref":                         "default",
 		"cn_validations":                     []interface{}{"email", "hostname"},
 		"allowed_user_ids":                   []interface{}{},
+		"allowed_upn_sans":                   []interface{}{},
+		"allowed_ip_sans":                    []interface{}{},
+		"wildcard_full_label_only":           false,
+		"max_wildcard_depth":                 json.Number("0"),
+		"denied_wildcard_zones":              []interface{}{},
+		"allowed_email_sans":                 []interface{}{},
+		"escrow_private_keys":                false,
 	}
 
 	if diff := deep.Equal(expectedData, resp.Data); len(diff) > 0 {
@@ -4029,37 +4424,48 @@ func TestBackend_RevokePlusTidy_Intermediate(t *testing.T) {
 			t.Fatal(err)
 		}
 		expectedData := map[string]interface{}{
-			"safety_buffer":                         json.Number("1"),
-			"issuer_safety_buffer":                  json.Number("31536000"),
-			"revocation_queue_safety_buffer":        json.Number("172800"),
-			"tidy_cert_store":                       true,
-			"tidy_revoked_certs":                    true,
-			"tidy_revoked_cert_issuer_associations": false,
-			"tidy_expired_issuers":                  false,
-			"tidy_move_legacy_ca_bundle":            false,
-			"tidy_revocation_queue":                 false,
-			"tidy_cross_cluster_revoked_certs":      false,
-			"pause_duration":                        "0s",
-			"state":                                 "Finished",
-			"error":                                 nil,
-			"time_started":                          nil,
-			"time_finished":                         nil,
-			"last_auto_tidy_finished":               nil,
-			"message":                               nil,
-			"cert_store_deleted_count":              json.Number("1"),
-			"revoked_cert_deleted_count":            json.Number("1"),
-			"missing_issuer_cert_count":             json.Number("0"),
-			"current_cert_store_count":              json.Number("0"),
-			"current_revoked_cert_count":            json.Number("0"),
-			"revocation_queue_deleted_count":        json.Number("0"),
-			"cross_revoked_cert_deleted_count":      json.Number("0"),
-			"internal_backend_uuid":                 backendUUID,
-			"tidy_acme":                             false,
-			"acme_account_safety_buffer":            json.Number("2592000"),
-			"acme_orders_deleted_count":             json.Number("0"),
-			"acme_account_revoked_count":            json.Number("0"),
-			"acme_account_deleted_count":            json.Number("0"),
-			"total_acme_account_count":              json.Number("0"),
+			"safety_buffer":                             json.Number("1"),
+			"issuer_safety_buffer":                      json.Number("31536000"),
+			"revocation_queue_safety_buffer":            json.Number("172800"),
+			"tidy_cert_store":                           true,
+			"tidy_revoked_certs":                        true,
+			"tidy_revoked_cert_issuer_associations":     false,
+			"tidy_expired_issuers":                      false,
+			"tidy_move_legacy_ca_bundle":                false,
+			"tidy_revocation_queue":                     false,
+			"tidy_cross_cluster_revoked_certs":          false,
+			"pause_duration":                            "0s",
+			"state":                                     "Finished",
+			"error":                                     nil,
+			"time_started":                              nil,
+			"time_finished":                             nil,
+			"last_auto_tidy_finished":                   nil,
+			"message":                                   nil,
+			"cert_store_deleted_count":                  json.Number("1"),
+			"revoked_cert_deleted_count":                json.Number("1"),
+			"missing_issuer_cert_count":                 json.Number("0"),
+			"current_cert_store_count":                  json.Number("0"),
+			"current_revoked_cert_count":                json.Number("0"),
+			"revocation_queue_deleted_count":            json.Number("0"),
+			"cross_revoked_cert_deleted_count":          json.Number("0"),
+			"internal_backend_uuid":                     backendUUID,
+			"tidy_acme":                                 false,
+			"acme_account_safety_buffer":                json.Number("2592000"),
+			"acme_orders_deleted_count":                 json.Number("0"),
+			"acme_account_revoked_count":                json.Number("0"),
+			"acme_account_deleted_count":                json.Number("0"),
+			"total_acme_account_count":                  json.Number("0"),
+			"current_phase":                             nil,
+			"current_phase_entries_total":               nil,
+			"current_phase_entries_scanned":             nil,
+			"current_phase_time_remaining":              nil,
+			"entries_scanned_count":                     json.Number("2"),
+			"phase_durations":                           nil,
+			"time_paused":                               nil,
+			"tidy_cert_metadata":                        false,
+			"cert_metadata_deleted_count":               json.Number("0"),
+			"tidy_orphaned_issuer_associations":         false,
+			"orphaned_issuer_association_deleted_count": json.Number("0"),
 		}
 		// Let's copy the times from the response so that we can use deep.Equal()
 		timeStarted, ok := tidyStatus.Data["time_started"]
@@ -4073,6 +4479,11 @@ func TestBackend_RevokePlusTidy_Intermediate(t *testing.T) {
 		}
 		expectedData["time_finished"] = timeFinished
 		expectedData["last_auto_tidy_finished"] = tidyStatus.Data["last_auto_tidy_finished"]
+		phaseDurations, ok := tidyStatus.Data["phase_durations"].(map[string]interface{})
+		if !ok || len(phaseDurations) != 2 || phaseDurations["cert_store"] == nil || phaseDurations["revoked_certs"] == nil {
+			t.Fatalf("Expected tidy status response to include the durations of both phases, got %v", tidyStatus.Data["phase_durations"])
+		}
+		expectedData["phase_durations"] = phaseDurations
 
 		if diff := deep.Equal(expectedData, tidyStatus.Data); diff != nil {
 			t.Fatal(diff)
@@ -5302,6 +5713,68 @@ TgM7RZnmEjNdeaa4M52o7VY=
 	require.NotContains(t, resp.Data["usage"], "crl-signing")
 }
 
+func TestIssuerUsageModes(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	// Modes can't be combined with other usages.
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"usage": "crl-signing,sign-intermediate-only",
+	})
+	require.ErrorContains(t, err, "can't be combined with other usages")
+
+	// Issuing certificates still implies signing intermediates.
+	resp, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"usage": "issuing-certificates",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/default")
+	require.Equal(t, "intermediate-signing,issuing-certificates,read-only", resp.Data["usage"])
+
+	// An issuer only signing intermediates refuses leaves.
+	resp, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"usage": "sign-intermediate-only",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/default")
+	require.Equal(t, "intermediate-signing,read-only", resp.Data["usage"])
+
+	_, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "www.example.com",
+	})
+	require.ErrorContains(t, err, "requested usage issuing-certificates")
+
+	intKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "intermediate example.com"},
+	}, intKey)
+	require.NoError(t, err)
+	resp, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/sign-intermediate")
+
+	// Revoked issuers can't regain intermediate signing.
+	resp, err = CBWrite(b, s, "issuer/default/revoke", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/default/revoke")
+	require.Equal(t, "read-only", resp.Data["usage"])
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"usage": "intermediate-signing",
+	})
+	require.ErrorContains(t, err, "This issuer was revoked")
+}
+
 func TestBackend_IfModifiedSinceHeaders(t *testing.T) {
 	t.Parallel()
 	coreConfig := &vault.CoreConfig{
@@ -6681,18 +7154,45 @@ func pathShouldBeUnauthedWriteOnly(t *testing.T, client *api.Client, path string
 	}
 }
 
+func pathShouldBeUnauthedReadWrite(t *testing.T, client *api.Client, path string, token string) {
+	client.SetToken("")
+	resp, err := client.Logical().ReadWithContext(ctx, path)
+	if err != nil && isPermDenied(err) {
+		t.Fatalf("unexpected failure to read %v while unauthed: %v / %v", path, err, resp)
+	}
+	resp, err = client.Logical().WriteWithContext(ctx, path, map[string]interface{}{})
+	if err != nil && isPermDenied(err) {
+		t.Fatalf("unexpected failure to write %v while unauthed: %v / %v", path, err, resp)
+	}
+
+	// These should all be denied, with or without a token.
+	for _, tok := range []string{"", token} {
+		client.SetToken(tok)
+		resp, err = client.Logical().DeleteWithContext(ctx, path)
+		if (err == nil && resp != nil) || (err != nil && !isDeniedOp(err)) {
+			t.Fatalf("unexpected failure during delete on read-write path %v: %v / %v", path, err, resp)
+		}
+		resp, err = client.Logical().JSONMergePatch(ctx, path, map[string]interface{}{})
+		if (err == nil && resp != nil) || (err != nil && !isDeniedOp(err)) {
+			t.Fatalf("unexpected failure during patch on read-write path %v: %v / %v", path, err, resp)
+		}
+	}
+}
+
 type pathAuthChecker int
 
 const (
 	shouldBeAuthed pathAuthChecker = iota
 	shouldBeUnauthedReadList
 	shouldBeUnauthedWriteOnly
+	shouldBeUnauthedReadWrite
 )
 
 var pathAuthChckerMap = map[pathAuthChecker]pathAuthCheckerFunc{
 	shouldBeAuthed:            pathShouldBeAuthed,
 	shouldBeUnauthedReadList:  pathShouldBeUnauthedReadList,
 	shouldBeUnauthedWriteOnly: pathShouldBeUnauthedWriteOnly,
+	shouldBeUnauthedReadWrite: pathShouldBeUnauthedReadWrite,
 }
 
 func TestProperAuthing(t *testing.T) {
@@ -6768,6 +7268,7 @@ func TestProperAuthing(t *testing.T) {
 		"cert/unified-delta-crl/raw":             shouldBeUnauthedReadList,
 		"cert/unified-delta-crl/raw/pem":         shouldBeUnauthedReadList,
 		"certs/":                                 shouldBeAuthed,
+		"certs/import":                           shouldBeAuthed,
 		"certs/revoked/":                         shouldBeAuthed,
 		"certs/revocation-queue/":                shouldBeAuthed,
 		"certs/unified-revoked/":                 shouldBeAuthed,
@@ -6776,10 +7277,20 @@ func TestProperAuthing(t *testing.T) {
 		"config/ca":                              shouldBeAuthed,
 		"config/cluster":                         shouldBeAuthed,
 		"config/crl":                             shouldBeAuthed,
+		"config/est":                             shouldBeAuthed,
+		"config/scep":                            shouldBeAuthed,
+		"config/cmp":                             shouldBeAuthed,
 		"config/issuers":                         shouldBeAuthed,
 		"config/keys":                            shouldBeAuthed,
+		"config/policy-webhook":                  shouldBeAuthed,
+		"config/expiry-notifications":            shouldBeAuthed,
+		"config/issuance-limits":                 shouldBeAuthed,
+		"cert-counts":                            shouldBeAuthed,
 		"config/urls":                            shouldBeAuthed,
+		"config/timestamping":                    shouldBeAuthed,
 		"crl":                                    shouldBeUnauthedReadList,
+		"escrowed-keys/":                         shouldBeAuthed,
+		"escrowed-keys/" + serial:                shouldBeAuthed,
 		"crl/pem":                                shouldBeUnauthedReadList,
 		"crl/delta":                              shouldBeUnauthedReadList,
 		"crl/delta/pem":                          shouldBeUnauthedReadList,
@@ -6792,6 +7303,8 @@ func TestProperAuthing(t *testing.T) {
 		"intermediate/generate/kms":              shouldBeAuthed,
 		"intermediate/set-signed":                shouldBeAuthed,
 		"issue/test":                             shouldBeAuthed,
+		"issue-with-serial/test":                 shouldBeAuthed,
+		"issue-from-cert/test":                   shouldBeAuthed,
 		"issuer/default":                         shouldBeAuthed,
 		"issuer/default/der":                     shouldBeUnauthedReadList,
 		"issuer/default/json":                    shouldBeUnauthedReadList,
@@ -6809,6 +7322,8 @@ func TestProperAuthing(t *testing.T) {
 		"issuer/default/unified-crl/delta/der":   shouldBeUnauthedReadList,
 		"issuer/default/unified-crl/delta/pem":   shouldBeUnauthedReadList,
 		"issuer/default/issue/test":              shouldBeAuthed,
+		"issuer/default/crl-signer":              shouldBeAuthed,
+		"issuer/default/import-crl":              shouldBeAuthed,
 		"issuer/default/resign-crls":             shouldBeAuthed,
 		"issuer/default/revoke":                  shouldBeAuthed,
 		"issuer/default/sign-intermediate":       shouldBeAuthed,
@@ -6818,6 +7333,7 @@ func TestProperAuthing(t *testing.T) {
 		"issuer/default/sign-verbatim/test":      shouldBeAuthed,
 		"issuer/default/sign/test":               shouldBeAuthed,
 		"issuers/":                               shouldBeUnauthedReadList,
+		"issuers/cross-sign":                     shouldBeAuthed,
 		"issuers/generate/intermediate/exported": shouldBeAuthed,
 		"issuers/generate/intermediate/internal": shouldBeAuthed,
 		"issuers/generate/intermediate/existing": shouldBeAuthed,
@@ -6834,12 +7350,18 @@ func TestProperAuthing(t *testing.T) {
 		"keys/generate/exported":                 shouldBeAuthed,
 		"keys/generate/kms":                      shouldBeAuthed,
 		"keys/import":                            shouldBeAuthed,
+		"keys/import-wrapped":                    shouldBeAuthed,
+		"keys/import-wrapping-key":               shouldBeAuthed,
+		"key/default/export-wrapped":             shouldBeAuthed,
 		"ocsp":                                   shouldBeUnauthedWriteOnly,
 		"ocsp/dGVzdAo=":                          shouldBeUnauthedReadList,
 		"revoke":                                 shouldBeAuthed,
 		"revoke-with-key":                        shouldBeAuthed,
+		"revoke-batch":                           shouldBeAuthed,
 		"roles/test":                             shouldBeAuthed,
 		"roles/":                                 shouldBeAuthed,
+		"profiles/test":                          shouldBeAuthed,
+		"profiles/":                              shouldBeAuthed,
 		"root":                                   shouldBeAuthed,
 		"root/generate/exported":                 shouldBeAuthed,
 		"root/generate/internal":                 shouldBeAuthed,
@@ -6850,14 +7372,23 @@ func TestProperAuthing(t *testing.T) {
 		"root/rotate/exported":                   shouldBeAuthed,
 		"root/rotate/existing":                   shouldBeAuthed,
 		"root/rotate/kms":                        shouldBeAuthed,
+		"root/rotation":                          shouldBeAuthed,
+		"root/rotation/prepare/internal":         shouldBeAuthed,
+		"root/rotation/flip":                     shouldBeAuthed,
+		"root/rotation/rollback":                 shouldBeAuthed,
 		"root/sign-intermediate":                 shouldBeAuthed,
 		"root/sign-self-issued":                  shouldBeAuthed,
 		"sign-verbatim":                          shouldBeAuthed,
 		"sign-verbatim/test":                     shouldBeAuthed,
 		"sign/test":                              shouldBeAuthed,
+		"sign-with-serial/test":                  shouldBeAuthed,
+		"timestamp":                              shouldBeUnauthedWriteOnly,
 		"tidy":                                   shouldBeAuthed,
 		"tidy-cancel":                            shouldBeAuthed,
+		"tidy-pause":                             shouldBeAuthed,
+		"tidy-resume":                            shouldBeAuthed,
 		"tidy-status":                            shouldBeAuthed,
+		"validate-csr/test":                      shouldBeAuthed,
 		"unified-crl":                            shouldBeUnauthedReadList,
 		"unified-crl/pem":                        shouldBeUnauthedReadList,
 		"unified-crl/delta":                      shouldBeUnauthedReadList,
@@ -6892,6 +7423,21 @@ func TestProperAuthing(t *testing.T) {
 		paths[acmePrefix+"new-eab"] = shouldBeAuthed
 	}
 
+	// Add EST based paths to the test suite
+	for _, estPrefix := range []string{"est/", "est/devices/"} {
+		paths[estPrefix+"cacerts"] = shouldBeUnauthedReadList
+		paths[estPrefix+"simpleenroll"] = shouldBeAuthed
+		paths[estPrefix+"simplereenroll"] = shouldBeUnauthedWriteOnly
+	}
+
+	// Add SCEP based paths to the test suite
+	paths["scep"] = shouldBeUnauthedReadWrite
+	paths["scep/devices"] = shouldBeUnauthedReadWrite
+
+	// Add CMP based paths to the test suite
+	paths["cmp"] = shouldBeUnauthedWriteOnly
+	paths["cmp/devices"] = shouldBeUnauthedWriteOnly
+
 	for path, checkerType := range paths {
 		checker := pathAuthChckerMap[checkerType]
 		checker(t, client, "pki/"+path, token)
@@ -6917,6 +7463,9 @@ func TestProperAuthing(t *testing.T) {
 		if strings.Contains(raw_path, "roles/") && strings.Contains(raw_path, "{name}") {
 			raw_path = strings.ReplaceAll(raw_path, "{name}", "test")
 		}
+		if strings.Contains(raw_path, "profiles/") && strings.Contains(raw_path, "{name}") {
+			raw_path = strings.ReplaceAll(raw_path, "{name}", "test")
+		}
 		if strings.Contains(raw_path, "{role}") {
 			raw_path = strings.ReplaceAll(raw_path, "{role}", "test")
 		}
@@ -6950,6 +7499,9 @@ func TestProperAuthing(t *testing.T) {
 		if strings.Contains(raw_path, "eab") && strings.Contains(raw_path, "{key_id}") {
 			raw_path = strings.ReplaceAll(raw_path, "{key_id}", eabKid)
 		}
+		if (strings.Contains(raw_path, "est/") || strings.Contains(raw_path, "scep/") || strings.Contains(raw_path, "cmp/")) && strings.Contains(raw_path, "{label}") {
+			raw_path = strings.ReplaceAll(raw_path, "{label}", "devices")
+		}
 		if strings.Contains(raw_path, "external-policy/") && strings.Contains(raw_path, "{policy}") {
 			raw_path = strings.ReplaceAll(raw_path, "{policy}", "a-policy")
 		}
@@ -7015,8 +7567,8 @@ func TestPatchIssuer(t *testing.T) {
 		},
 		{
 			Field:   "usage",
-			Before:  "crl-signing,issuing-certificates,ocsp-signing,read-only",
-			Patched: "issuing-certificates,read-only",
+			Before:  "crl-signing,intermediate-signing,issuing-certificates,ocsp-signing,read-only",
+			Patched: "intermediate-signing,read-only",
 		},
 		{
 			Field:   "revocation_signature_algorithm",
@@ -7138,6 +7690,348 @@ func TestGenerateRootCAWithAIA(t *testing.T) {
 	requireSuccessNonNilResponse(t, resp, err, "expected root generation to succeed")
 }
 
+func TestCertMetadata(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/stored", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "roles/not-stored", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"no_store":       true,
+	})
+	require.NoError(t, err)
+
+	metadata := map[string]string{
+		"owner":  "team-a",
+		"ticket": "OPS-1234",
+	}
+
+	// Metadata can't be kept for certificates which aren't.
+	resp, err = CBWrite(b, s, "issue/not-stored", map[string]interface{}{
+		"common_name":   "example.com",
+		"cert_metadata": metadata,
+	})
+	require.Error(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = CBWrite(b, s, "issue/stored", map[string]interface{}{
+		"common_name":   "example.com",
+		"cert_metadata": metadata,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/stored")
+	serialWithMetadata := resp.Data["serial_number"].(string)
+
+	resp, err = CBWrite(b, s, "issue/stored", map[string]interface{}{
+		"common_name": "example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/stored")
+	serialWithoutMetadata := resp.Data["serial_number"].(string)
+
+	resp, err = CBRead(b, s, "cert/"+serialWithMetadata)
+	requireSuccessNonNilResponse(t, resp, err, "cert/"+serialWithMetadata)
+	require.Equal(t, metadata, resp.Data["cert_metadata"])
+
+	resp, err = CBRead(b, s, "cert/"+serialWithoutMetadata)
+	requireSuccessNonNilResponse(t, resp, err, "cert/"+serialWithoutMetadata)
+	require.NotContains(t, resp.Data, "cert_metadata")
+
+	// Raw reads return only the certificate.
+	resp, err = CBRead(b, s, "cert/"+serialWithMetadata+"/raw/pem")
+	requireSuccessNonNilResponse(t, resp, err, "cert/"+serialWithMetadata+"/raw/pem")
+	require.NotContains(t, resp.Data, "cert_metadata")
+
+	resp, err = CBList(b, s, "certs")
+	requireSuccessNonNilResponse(t, resp, err, "certs")
+	require.NotContains(t, resp.Data, "key_info")
+
+	resp, err = CBReq(b, s, logical.ListOperation, "certs", map[string]interface{}{
+		"include_metadata": true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "certs")
+	require.Contains(t, resp.Data["keys"], serialWithMetadata)
+	require.Contains(t, resp.Data["keys"], serialWithoutMetadata)
+	keyInfo := resp.Data["key_info"].(map[string]interface{})
+	require.Len(t, keyInfo, 2)
+	require.Equal(t, metadata, keyInfo[serialWithMetadata].(map[string]interface{})["cert_metadata"])
+	require.Equal(t, "stored", keyInfo[serialWithoutMetadata].(map[string]interface{})["role"])
+	require.NotContains(t, keyInfo[serialWithoutMetadata], "cert_metadata")
+}
+
+func TestCertSearch(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	var issuerIds, rootSerials []string
+	for _, name := range []string{"root-a", "root-b"} {
+		resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+			"common_name": name,
+			"issuer_name": name,
+			"key_type":    "ec",
+			"ttl":         "40h",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+		issuerIds = append(issuerIds, string(resp.Data["issuer_id"].(issuerID)))
+		rootSerials = append(rootSerials, resp.Data["serial_number"].(string))
+
+		_, err = CBWrite(b, s, "roles/"+name, map[string]interface{}{
+			"allow_any_name": true,
+			"issuer_ref":     name,
+			"key_type":       "ec",
+		})
+		require.NoError(t, err)
+	}
+
+	issue := func(role string, commonName string, altNames string) string {
+		t.Helper()
+
+		resp, err := CBWrite(b, s, "issue/"+role, map[string]interface{}{
+			"common_name": commonName,
+			"alt_names":   altNames,
+			"ip_sans":     "10.0.0.1",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/"+role)
+		return resp.Data["serial_number"].(string)
+	}
+
+	web := issue("root-a", "web.example.com", "www.example.com")
+	api := issue("root-a", "api.example.com", "")
+	db := issue("root-b", "db.internal", "replica.db.internal")
+
+	search := func(params map[string]interface{}) []string {
+		t.Helper()
+
+		resp, err := CBReq(b, s, logical.ListOperation, "certs", params)
+		requireSuccessNonNilResponse(t, resp, err, "certs")
+		if resp.Data["keys"] == nil {
+			return nil
+		}
+		return resp.Data["keys"].([]string)
+	}
+
+	require.ElementsMatch(t, []string{web, api}, search(map[string]interface{}{"common_name": "*.example.com"}))
+	require.ElementsMatch(t, []string{web}, search(map[string]interface{}{"common_name": "WEB.example.com"}))
+	require.ElementsMatch(t, []string{web}, search(map[string]interface{}{"san": "www.*"}))
+	require.ElementsMatch(t, []string{db}, search(map[string]interface{}{"san": "*.db.internal"}))
+	require.ElementsMatch(t, []string{web, api, db}, search(map[string]interface{}{"san": "10.0.0.1"}))
+	require.ElementsMatch(t, []string{web, api}, search(map[string]interface{}{"role": "root-a"}))
+	require.ElementsMatch(t, []string{db}, search(map[string]interface{}{"issuer_ref": "root-b", "common_name": "db.*"}))
+	require.ElementsMatch(t, []string{db}, search(map[string]interface{}{"issuer_ref": issuerIds[1], "role": "root-b"}))
+	require.ElementsMatch(t, []string{api}, search(map[string]interface{}{"role": "root-a", "common_name": "api.*"}))
+	require.ElementsMatch(t, []string{db}, search(map[string]interface{}{"serial_prefix": strings.ToUpper(strings.ReplaceAll(db, ":", "-"))}))
+	require.Empty(t, search(map[string]interface{}{"role": "root-b", "common_name": "web.example.com"}))
+
+	// The roots themselves aren't issued through a role, but are still
+	// matched on their (own) issuer.
+	require.ElementsMatch(t, []string{rootSerials[0], web, api}, search(map[string]interface{}{"issuer_ref": "root-a"}))
+	require.ElementsMatch(t, []string{rootSerials[1], db}, search(map[string]interface{}{"issuer_ref": "root-b"}))
+
+	resp, err := CBReq(b, s, logical.ListOperation, "certs", map[string]interface{}{
+		"issuer_ref": "missing",
+	})
+	require.Error(t, err)
+	require.True(t, resp.IsError())
+}
+
+func TestCertListPagination(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootSerial := resp.Data["serial_number"].(string)
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	require.NoError(t, err)
+
+	var shortLived, longLived []string
+	for i := 0; i < 3; i++ {
+		resp, err = CBWrite(b, s, "issue/example", map[string]interface{}{
+			"common_name": "short.example.com",
+			"ttl":         "1h",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/example")
+		shortLived = append(shortLived, resp.Data["serial_number"].(string))
+
+		resp, err = CBWrite(b, s, "issue/example", map[string]interface{}{
+			"common_name": "long.example.com",
+			"ttl":         "30h",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/example")
+		longLived = append(longLived, resp.Data["serial_number"].(string))
+	}
+
+	list := func(params map[string]interface{}) []string {
+		t.Helper()
+
+		resp, err := CBReq(b, s, logical.ListOperation, "certs", params)
+		requireSuccessNonNilResponse(t, resp, err, "certs")
+		if resp.Data["keys"] == nil {
+			return nil
+		}
+		return resp.Data["keys"].([]string)
+	}
+
+	all := list(nil)
+	require.Len(t, all, 7)
+
+	// Walking the listing page by page yields every certificate once, in
+	// order, with or without filters.
+	for _, params := range []map[string]interface{}{
+		{},
+		{"common_name": "*.example.com"},
+	} {
+		var walked []string
+		after := ""
+		for {
+			params["after"] = after
+			params["limit"] = 2
+			page := list(params)
+			require.LessOrEqual(t, len(page), 2)
+			if len(page) == 0 {
+				break
+			}
+
+			walked = append(walked, page...)
+			after = page[len(page)-1]
+		}
+
+		expected := all
+		if params["common_name"] != nil {
+			expected = append(append([]string{}, shortLived...), longLived...)
+		}
+		require.ElementsMatch(t, expected, walked)
+		require.IsIncreasing(t, walked)
+	}
+
+	inTwoHours := time.Now().Add(2 * time.Hour).Format(time.RFC3339)
+	require.ElementsMatch(t, shortLived, list(map[string]interface{}{"expires_before": inTwoHours}))
+	require.ElementsMatch(t, append([]string{rootSerial}, longLived...), list(map[string]interface{}{"expires_after": inTwoHours}))
+	require.ElementsMatch(t, longLived, list(map[string]interface{}{
+		"expires_after":  inTwoHours,
+		"expires_before": time.Now().Add(35 * time.Hour).Format(time.RFC3339),
+	}))
+
+	require.Empty(t, list(map[string]interface{}{"revoked_only": true}))
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": longLived[1],
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{longLived[1]}, list(map[string]interface{}{"revoked_only": true}))
+	require.Empty(t, list(map[string]interface{}{"revoked_only": true, "expires_before": inTwoHours}))
+
+	resp, err = CBReq(b, s, logical.ListOperation, "certs", map[string]interface{}{
+		"expires_before": "tomorrow",
+	})
+	require.Error(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = CBReq(b, s, logical.ListOperation, "certs", map[string]interface{}{
+		"limit": -1,
+	})
+	require.Error(t, err)
+	require.True(t, resp.IsError())
+}
+
+func TestIssueSignPKCS7(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+	})
+	require.NoError(t, err)
+
+	requirePKCS7Chain := func(resp *logical.Response) {
+		t.Helper()
+
+		certDer, err := base64.StdEncoding.DecodeString(resp.Data["certificate"].(string))
+		require.NoError(t, err)
+
+		p7Der, err := base64.StdEncoding.DecodeString(resp.Data["pkcs7"].(string))
+		require.NoError(t, err)
+		p7, err := pkcs7.Parse(p7Der)
+		require.NoError(t, err)
+
+		require.Len(t, p7.Certificates, 2)
+		require.Equal(t, certDer, p7.Certificates[0].Raw)
+		require.Equal(t, rootCert.Raw, p7.Certificates[1].Raw)
+	}
+
+	resp, err = CBWrite(b, s, "issue/example", map[string]interface{}{
+		"common_name": "issued.example.com",
+		"ttl":         "1h",
+		"format":      "pkcs7",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/example")
+	requirePKCS7Chain(resp)
+
+	// As with der, the private key is base64 encoded DER.
+	keyDer, err := base64.StdEncoding.DecodeString(resp.Data["private_key"].(string))
+	require.NoError(t, err)
+	_, err = x509.ParseECPrivateKey(keyDer)
+	require.NoError(t, err)
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "signed.example.com"},
+	}, "ec", 256)
+	resp, err = CBWrite(b, s, "sign/example", map[string]interface{}{
+		"csr":    csrPem,
+		"ttl":    "1h",
+		"format": "pkcs7",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign/example")
+	requirePKCS7Chain(resp)
+	require.NotContains(t, resp.Data, "private_key")
+
+	// Without roots in the chain, only the certificate itself remains.
+	resp, err = CBWrite(b, s, "sign/example", map[string]interface{}{
+		"csr":                     csrPem,
+		"ttl":                     "1h",
+		"format":                  "pkcs7",
+		"remove_roots_from_chain": true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign/example")
+	p7Der, err := base64.StdEncoding.DecodeString(resp.Data["pkcs7"].(string))
+	require.NoError(t, err)
+	p7, err := pkcs7.Parse(p7Der)
+	require.NoError(t, err)
+	require.Len(t, p7.Certificates, 1)
+}
+
 var (
 	initTest  sync.Once
 	rsaCAKey  string
@@ -7147,3 +8041,240 @@ var (
 	edCAKey   string
 	edCACert  string
 )
+
+func TestPKI_IssuerPolicyIdentifiers(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	rootPolicy := "1.3.6.1.4.1.99999.10"
+	cpsPolicy := `[{"oid":"1.3.6.1.4.1.99999.11","cps":"https://example.com/cps","notice":"Issued under the example CP"}]`
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name":        "root example.com",
+		"policy_identifiers": "not-an-oid",
+	})
+	require.Error(t, err, "expected invalid policy identifiers to be rejected")
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name":        "root example.com",
+		"ttl":                "40h",
+		"issuer_name":        "root",
+		"policy_identifiers": cpsPolicy,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, "1.3.6.1.4.1.99999.11", rootCert.PolicyIdentifiers[0].String())
+	requireCertificatePoliciesExtension(t, rootCert, "https://example.com/cps")
+
+	// Intermediate CSRs request their policies.
+	intB, intS := CreateBackendWithStorage(t)
+	resp, err = CBWrite(intB, intS, "intermediate/generate/internal", map[string]interface{}{
+		"common_name":        "int example.com",
+		"policy_identifiers": "1.3.6.1.4.1.99999.20",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "intermediate/generate/internal")
+	csrPem := resp.Data["csr"].(string)
+	csrBlock, _ := pem.Decode([]byte(csrPem))
+	csr, err := x509.ParseCertificateRequest(csrBlock.Bytes)
+	require.NoError(t, err)
+	var csrHasPolicies bool
+	for _, ext := range csr.Extensions {
+		if ext.Id.String() == "2.5.29.32" {
+			csrHasPolicies = true
+		}
+	}
+	require.True(t, csrHasPolicies, "expected CSR to request certificate policies")
+
+	// Policies on the issuer apply to certificates which don't set their own.
+	resp, err = CBPatch(b, s, "issuer/root", map[string]interface{}{
+		"policy_identifiers": rootPolicy,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/root")
+	require.Equal(t, []string{rootPolicy}, resp.Data["policy_identifiers"])
+
+	resp, err = CBRead(b, s, "issuer/root")
+	requireSuccessNonNilResponse(t, resp, err, "issuer/root")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issuer/root"), logical.ReadOperation), resp, true)
+	require.Equal(t, []string{rootPolicy}, resp.Data["policy_identifiers"])
+
+	resp, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr": csrPem,
+		"ttl": "20h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/sign-intermediate")
+	intCert := parseCert(t, resp.Data["certificate"].(string))
+	require.Len(t, intCert.PolicyIdentifiers, 1)
+	require.Equal(t, rootPolicy, intCert.PolicyIdentifiers[0].String())
+
+	resp, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr":                csrPem,
+		"ttl":                "20h",
+		"policy_identifiers": cpsPolicy,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/sign-intermediate")
+	intCert = parseCert(t, resp.Data["certificate"].(string))
+	require.Len(t, intCert.PolicyIdentifiers, 1)
+	require.Equal(t, "1.3.6.1.4.1.99999.11", intCert.PolicyIdentifiers[0].String())
+	requireCertificatePoliciesExtension(t, intCert, "https://example.com/cps")
+
+	// Roles with policies of their own take precedence over the issuer's.
+	_, err = CBWrite(b, s, "roles/inherit", map[string]interface{}{
+		"allow_any_name": true,
+	})
+	require.NoError(t, err)
+	_, err = CBWrite(b, s, "roles/explicit", map[string]interface{}{
+		"allow_any_name":     true,
+		"policy_identifiers": "1.3.6.1.4.1.99999.30",
+	})
+	require.NoError(t, err)
+
+	for role, expected := range map[string]string{"inherit": rootPolicy, "explicit": "1.3.6.1.4.1.99999.30"} {
+		resp, err = CBWrite(b, s, "issue/"+role, map[string]interface{}{
+			"common_name": "leaf.example.com",
+			"ttl":         "1h",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/"+role)
+		leafCert := parseCert(t, resp.Data["certificate"].(string))
+		require.Len(t, leafCert.PolicyIdentifiers, 1, "role %v", role)
+		require.Equal(t, expected, leafCert.PolicyIdentifiers[0].String(), "role %v", role)
+	}
+
+	// Clearing the issuer's policies stops them from being inherited.
+	resp, err = CBPatch(b, s, "issuer/root", map[string]interface{}{
+		"policy_identifiers": []string{},
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/root")
+
+	resp, err = CBWrite(b, s, "issue/inherit", map[string]interface{}{
+		"common_name": "leaf.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/inherit")
+	require.Empty(t, parseCert(t, resp.Data["certificate"].(string)).PolicyIdentifiers)
+}
+
+func requireCertificatePoliciesExtension(t *testing.T, cert *x509.Certificate, contains string) {
+	t.Helper()
+	for _, ext := range cert.Extensions {
+		if ext.Id.String() == "2.5.29.32" {
+			require.Contains(t, string(ext.Value), contains)
+			return
+		}
+	}
+	require.Fail(t, "certificate lacks the certificatePolicies extension")
+}
+
+func TestPKI_NameConstraints(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name":          "root example.com",
+		"ttl":                  "40h",
+		"excluded_dns_domains": "forbidden.example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, []string{"forbidden.example.com"}, rootCert.ExcludedDNSDomains)
+	require.True(t, rootCert.PermittedDNSDomainsCritical)
+
+	constraints := map[string]interface{}{
+		"permitted_dns_domains":     "team.example.com",
+		"excluded_dns_domains":      "secret.team.example.com",
+		"permitted_ip_ranges":       "10.1.0.0/16,2001:db8::/32",
+		"excluded_ip_ranges":        "10.1.1.0/24",
+		"permitted_email_addresses": "team.example.com",
+		"excluded_email_addresses":  "admin@team.example.com",
+		"permitted_uri_domains":     ".team.example.com",
+		"excluded_uri_domains":      "legacy.team.example.com",
+	}
+	requireConstraints := func(t *testing.T, cert *x509.Certificate) {
+		t.Helper()
+		require.True(t, cert.PermittedDNSDomainsCritical)
+		require.Equal(t, []string{"team.example.com"}, cert.PermittedDNSDomains)
+		require.Equal(t, []string{"secret.team.example.com"}, cert.ExcludedDNSDomains)
+		require.Len(t, cert.PermittedIPRanges, 2)
+		require.Equal(t, "10.1.0.0/16", cert.PermittedIPRanges[0].String())
+		require.Equal(t, "2001:db8::/32", cert.PermittedIPRanges[1].String())
+		require.Len(t, cert.ExcludedIPRanges, 1)
+		require.Equal(t, "10.1.1.0/24", cert.ExcludedIPRanges[0].String())
+		require.Equal(t, []string{"team.example.com"}, cert.PermittedEmailAddresses)
+		require.Equal(t, []string{"admin@team.example.com"}, cert.ExcludedEmailAddresses)
+		require.Equal(t, []string{".team.example.com"}, cert.PermittedURIDomains)
+		require.Equal(t, []string{"legacy.team.example.com"}, cert.ExcludedURIDomains)
+	}
+
+	// The delegated CA requests its constraints in its CSR.
+	intB, intS := CreateBackendWithStorage(t)
+	csrData := map[string]interface{}{
+		"common_name": "team.example.com",
+	}
+	for key, value := range constraints {
+		csrData[key] = value
+	}
+	resp, err = CBWrite(intB, intS, "intermediate/generate/internal", csrData)
+	requireSuccessNonNilResponse(t, resp, err, "intermediate/generate/internal")
+	csrPem := resp.Data["csr"].(string)
+	csrBlock, _ := pem.Decode([]byte(csrPem))
+	csr, err := x509.ParseCertificateRequest(csrBlock.Bytes)
+	require.NoError(t, err)
+	var csrHasConstraints bool
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(certutil.ExtensionNameConstraintsOID) {
+			csrHasConstraints = true
+			require.True(t, ext.Critical)
+		}
+	}
+	require.True(t, csrHasConstraints, "expected CSR to request name constraints")
+
+	// Requested constraints are only honored with use_csr_values.
+	resp, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr":            csrPem,
+		"ttl":            "20h",
+		"use_csr_values": true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/sign-intermediate")
+	requireConstraints(t, parseCert(t, resp.Data["certificate"].(string)))
+
+	resp, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr": csrPem,
+		"ttl": "20h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/sign-intermediate")
+	unconstrained := parseCert(t, resp.Data["certificate"].(string))
+	require.Empty(t, unconstrained.PermittedDNSDomains)
+	require.Empty(t, unconstrained.PermittedIPRanges)
+
+	// The signer's constraints take precedence over the requested ones.
+	resp, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr":                   csrPem,
+		"ttl":                   "20h",
+		"use_csr_values":        true,
+		"permitted_dns_domains": "narrow.team.example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/sign-intermediate")
+	intCert := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, []string{"narrow.team.example.com"}, intCert.PermittedDNSDomains)
+	require.Empty(t, intCert.ExcludedDNSDomains)
+	require.Empty(t, intCert.PermittedIPRanges)
+
+	var constraintExtensions int
+	for _, ext := range intCert.Extensions {
+		if ext.Id.Equal(certutil.ExtensionNameConstraintsOID) {
+			constraintExtensions++
+		}
+	}
+	require.Equal(t, 1, constraintExtensions)
+
+	_, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr":                 csrPem,
+		"permitted_ip_ranges": "10.1.0.0",
+	})
+	require.ErrorContains(t, err, "invalid permitted_ip_ranges")
+
+	_, err = CBWrite(intB, intS, "intermediate/generate/internal", map[string]interface{}{
+		"common_name":        "team.example.com",
+		"excluded_ip_ranges": "not-a-range",
+	})
+	require.ErrorContains(t, err, "invalid excluded_ip_ranges")
+}
@@ -71,9 +71,15 @@ func getGenerationParams(sc *storageContext, data *framework.FieldData) (exporte
 		PostalCode:                data.Get("postal_code").([]string),
 		NotBeforeDuration:         time.Duration(data.Get("not_before_duration").(int)) * time.Second,
 		CNValidations:             []string{"disabled"},
+		PolicyIdentifiers:         getPolicyIdentifier(data, nil),
 	}
 	*role.AllowWildcardCertificates = true
 
+	if err = validatePolicyIdentifiers(role.PolicyIdentifiers); err != nil {
+		errorResp = logical.ErrorResponse(err.Error())
+		return
+	}
+
 	if role.KeyBits, role.SignatureBits, err = certutil.ValidateDefaultOrValueKeyTypeSignatureLength(role.KeyType, role.KeyBits, role.SignatureBits); err != nil {
 		errorResp = logical.ErrorResponse(err.Error())
 	}
@@ -312,6 +318,7 @@ func buildSignVerbatimRole(data *framework.FieldData, role *roleEntry) *roleEntr
 		if role.NotBeforeDuration > 0 {
 			entry.NotBeforeDuration = role.NotBeforeDuration
 		}
+		entry.MaxNotBeforeDuration = role.MaxNotBeforeDuration
 		entry.NoStore = role.NoStore
 		entry.Issuer = role.Issuer
 	}
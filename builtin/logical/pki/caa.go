@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/miekg/dns"
+)
+
+// CAA checking (RFC 8659): roles with CAA identities configured look up the
+// CAA records relevant to each requested DNS name and refuse to issue when
+// they don't authorize any of the role's identities.
+const (
+	caaFailureModeHard = "hard"
+	caaFailureModeSoft = "soft"
+
+	caaLookupTimeout = 10 * time.Second
+	caaResolvConf    = "/etc/resolv.conf"
+)
+
+func validateCAAFailureMode(mode string) error {
+	switch mode {
+	case caaFailureModeHard, caaFailureModeSoft:
+		return nil
+	default:
+		return fmt.Errorf("invalid caa_failure_mode %q: must be %q or %q", mode, caaFailureModeHard, caaFailureModeSoft)
+	}
+}
+
+// normalizeCAAResolvers validates the given resolvers, defaulting their
+// port to 53.
+func normalizeCAAResolvers(resolvers []string) ([]string, error) {
+	normalized := make([]string, 0, len(resolvers))
+	for _, resolver := range resolvers {
+		address := strings.TrimSpace(resolver)
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(strings.Trim(address, "[]"), "53")
+		}
+		if host, _, err := net.SplitHostPort(address); err != nil || host == "" {
+			return nil, fmt.Errorf("invalid CAA resolver %q", resolver)
+		}
+		normalized = append(normalized, address)
+	}
+
+	return normalized, nil
+}
+
+// checkCAA checks the CAA records of the DNS names of the certificate about
+// to be issued against the role's CAA identities. With the soft failure
+// mode, names whose records cannot be looked up are only warned about.
+func checkCAA(ctx context.Context, role *roleEntry, creation *certutil.CreationBundle) ([]string, error) {
+	if len(role.CAAIdentities) == 0 {
+		return nil, nil
+	}
+
+	dnsNames := creation.Params.DNSNames
+	if creation.Params.UseCSRValues && creation.CSR != nil {
+		dnsNames = creation.CSR.DNSNames
+	}
+
+	resolvers := role.CAAResolvers
+	if len(resolvers) == 0 {
+		config, err := dns.ClientConfigFromFile(caaResolvConf)
+		if err != nil {
+			return caaLookupFailure(role, nil, fmt.Errorf("CAA lookup failed: no resolvers configured: %w", err))
+		}
+		for _, server := range config.Servers {
+			resolvers = append(resolvers, net.JoinHostPort(server, config.Port))
+		}
+	}
+
+	var warnings []string
+	for _, name := range dnsNames {
+		wildcard := strings.HasPrefix(name, "*.")
+		domain := strings.TrimPrefix(name, "*.")
+
+		records, err := lookupRelevantCAA(ctx, resolvers, domain)
+		if err != nil {
+			warnings, err = caaLookupFailure(role, warnings, fmt.Errorf("CAA lookup failed for %v: %w", name, err))
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !caaAuthorizes(records, role.CAAIdentities, wildcard) {
+			return nil, errutil.UserError{Err: fmt.Sprintf("CAA records for %v do not authorize issuance by %v", name, strings.Join(role.CAAIdentities, ", "))}
+		}
+	}
+
+	return warnings, nil
+}
+
+func caaLookupFailure(role *roleEntry, warnings []string, err error) ([]string, error) {
+	if role.CAAFailureMode == caaFailureModeSoft {
+		return append(warnings, fmt.Sprintf("%v; issuing regardless", err)), nil
+	}
+
+	return nil, errutil.UserError{Err: err.Error()}
+}
+
+// lookupRelevantCAA finds the relevant CAA record set for domain, climbing
+// the DNS tree towards the root until a non-empty set is found.
+func lookupRelevantCAA(ctx context.Context, resolvers []string, domain string) ([]*dns.CAA, error) {
+	labels := dns.SplitDomainName(domain)
+	for i := range labels {
+		records, err := lookupCAA(ctx, resolvers, strings.Join(labels[i:], "."))
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			return records, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func lookupCAA(ctx context.Context, resolvers []string, name string) ([]*dns.CAA, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), dns.TypeCAA)
+	msg.RecursionDesired = true
+
+	ctx, cancel := context.WithTimeout(ctx, caaLookupTimeout)
+	defer cancel()
+
+	client := new(dns.Client)
+	var lastErr error
+	for _, resolver := range resolvers {
+		resp, _, err := client.ExchangeContext(ctx, msg, resolver)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		// A missing name has no CAA records, and so doesn't restrict
+		// issuance; any other error leaves the records unknown.
+		if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+			lastErr = fmt.Errorf("resolver %v returned %v for %v", resolver, dns.RcodeToString[resp.Rcode], name)
+			continue
+		}
+
+		var records []*dns.CAA
+		for _, answer := range resp.Answer {
+			if caa, ok := answer.(*dns.CAA); ok {
+				records = append(records, caa)
+			}
+		}
+		return records, nil
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no resolvers available")
+	}
+	return nil, lastErr
+}
+
+// caaAuthorizes evaluates a relevant CAA record set per RFC 8659 Section 4.
+func caaAuthorizes(records []*dns.CAA, identities []string, wildcard bool) bool {
+	hasIssueWild := false
+	for _, record := range records {
+		tag := strings.ToLower(record.Tag)
+		switch tag {
+		case "issue", "iodef":
+		case "issuewild":
+			hasIssueWild = true
+		default:
+			// Unknown properties marked critical forbid issuance.
+			if record.Flag&128 != 0 {
+				return false
+			}
+		}
+	}
+
+	tag := "issue"
+	if wildcard && hasIssueWild {
+		tag = "issuewild"
+	}
+
+	var properties []string
+	for _, record := range records {
+		if strings.EqualFold(record.Tag, tag) {
+			properties = append(properties, record.Value)
+		}
+	}
+
+	// A record set without issue properties doesn't restrict issuance.
+	if len(properties) == 0 {
+		return true
+	}
+
+	for _, property := range properties {
+		issuer := strings.TrimSpace(strings.SplitN(property, ";", 2)[0])
+		for _, identity := range identities {
+			if issuer != "" && strings.EqualFold(issuer, identity) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,220 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/require"
+)
+
+// startCAATestServer serves the given CAA records, answering SERVFAIL for
+// names under servfail.example.com.
+func startCAATestServer(t *testing.T, records map[string][]*dns.CAA) string {
+	t.Helper()
+
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		resp := new(dns.Msg)
+		resp.SetReply(r)
+
+		name := r.Question[0].Name
+		if dns.IsSubDomain("servfail.example.com.", name) {
+			resp.Rcode = dns.RcodeServerFailure
+		} else {
+			for _, record := range records[name] {
+				record.Hdr = dns.RR_Header{Name: name, Rrtype: dns.TypeCAA, Class: dns.ClassINET, Ttl: 60}
+				resp.Answer = append(resp.Answer, record)
+			}
+		}
+
+		require.NoError(t, w.WriteMsg(resp))
+	})
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: conn, Handler: handler, NotifyStartedFunc: func() { close(started) }}
+	go server.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { server.Shutdown() })
+
+	return conn.LocalAddr().String()
+}
+
+func TestCAAAuthorizes(t *testing.T) {
+	t.Parallel()
+
+	issue := func(value string) *dns.CAA { return &dns.CAA{Tag: "issue", Value: value} }
+	issueWild := func(value string) *dns.CAA { return &dns.CAA{Tag: "issuewild", Value: value} }
+	identities := []string{"vault.example.com"}
+
+	require.True(t, caaAuthorizes(nil, identities, false))
+	require.True(t, caaAuthorizes([]*dns.CAA{{Tag: "iodef", Value: "mailto:security@example.com"}}, identities, false))
+	require.True(t, caaAuthorizes([]*dns.CAA{issue("other-ca.example"), issue("Vault.Example.com; account=1234")}, identities, false))
+	require.False(t, caaAuthorizes([]*dns.CAA{issue("other-ca.example")}, identities, false))
+	require.False(t, caaAuthorizes([]*dns.CAA{issue(";")}, identities, false))
+	require.False(t, caaAuthorizes([]*dns.CAA{issue("vault.example.com"), {Flag: 128, Tag: "tbs", Value: "unknown"}}, identities, false))
+	require.True(t, caaAuthorizes([]*dns.CAA{issue("vault.example.com"), {Tag: "tbs", Value: "unknown"}}, identities, false))
+
+	// Wildcards use issuewild properties when present, and issue otherwise.
+	require.True(t, caaAuthorizes([]*dns.CAA{issue("vault.example.com")}, identities, true))
+	require.False(t, caaAuthorizes([]*dns.CAA{issue("vault.example.com"), issueWild(";")}, identities, true))
+	require.True(t, caaAuthorizes([]*dns.CAA{issue("other-ca.example"), issueWild("vault.example.com")}, identities, true))
+
+	resolvers, err := normalizeCAAResolvers([]string{"127.0.0.1", "10.0.0.1:5353", "::1", "[2001:db8::1]:53"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:53", "10.0.0.1:5353", "[::1]:53", "[2001:db8::1]:53"}, resolvers)
+	_, err = normalizeCAAResolvers([]string{":53"})
+	require.Error(t, err)
+}
+
+func TestPki_RoleCAAChecking(t *testing.T) {
+	t.Parallel()
+
+	resolver := startCAATestServer(t, map[string][]*dns.CAA{
+		"allowed.example.com.":  {{Tag: "issue", Value: "vault.example.com"}},
+		"denied.example.com.":   {{Tag: "issue", Value: "other-ca.example"}},
+		"wild.example.com.":     {{Tag: "issue", Value: "other-ca.example"}, {Tag: "issuewild", Value: "vault.example.com"}},
+		"critical.example.com.": {{Tag: "issue", Value: "vault.example.com"}, {Flag: 128, Tag: "tbs", Value: "unknown"}},
+	})
+
+	b, s := CreateBackendWithStorage(t)
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/caa", map[string]interface{}{
+		"allow_any_name":   true,
+		"caa_identities":   "vault.example.com",
+		"caa_failure_mode": "lenient",
+	})
+	require.ErrorContains(t, err, "invalid caa_failure_mode")
+
+	resp, err = CBWrite(b, s, "roles/caa", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"caa_identities": "vault.example.com",
+		"caa_resolvers":  resolver,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/caa")
+	require.Equal(t, "hard", resp.Data["caa_failure_mode"])
+	require.Equal(t, []string{resolver}, resp.Data["caa_resolvers"])
+
+	issue := func(names ...string) error {
+		_, err := CBWrite(b, s, "issue/caa", map[string]interface{}{
+			"common_name": names[0],
+			"alt_names":   strings.Join(names[1:], ","),
+			"ttl":         "1h",
+		})
+		return err
+	}
+
+	err = issue("allowed.example.com")
+	require.NoError(t, err)
+	err = issue("www.sub.allowed.example.com")
+	require.NoError(t, err, "expected CAA records of the parent domain to apply")
+	err = issue("unrestricted.example.org")
+	require.NoError(t, err, "expected names without CAA records to be permitted")
+	err = issue("*.wild.example.com")
+	require.NoError(t, err)
+
+	err = issue("www.allowed.example.com", "denied.example.com")
+	require.ErrorContains(t, err, "CAA records for denied.example.com do not authorize issuance by vault.example.com")
+	err = issue("wild.example.com")
+	require.ErrorContains(t, err, "CAA records for wild.example.com do not authorize issuance")
+	err = issue("critical.example.com")
+	require.ErrorContains(t, err, "CAA records for critical.example.com do not authorize issuance")
+	err = issue("www.servfail.example.com")
+	require.ErrorContains(t, err, "CAA lookup failed for www.servfail.example.com")
+
+	// In the soft failure mode, lookup failures only warn, while records
+	// not authorizing issuance still refuse it.
+	_, err = CBPatch(b, s, "roles/caa", map[string]interface{}{
+		"caa_failure_mode": "soft",
+	})
+	require.NoError(t, err)
+
+	resp, err = CBWrite(b, s, "issue/caa", map[string]interface{}{
+		"common_name": "www.servfail.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/caa")
+	require.NotEmpty(t, resp.Warnings)
+	require.Contains(t, resp.Warnings[0], "CAA lookup failed for www.servfail.example.com")
+
+	err = issue("denied.example.com")
+	require.ErrorContains(t, err, "do not authorize issuance")
+
+	// Names from CSRs are checked on /sign too.
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "denied.example.com"},
+		DNSNames: []string{"denied.example.com"},
+	}, "ec", 256)
+	_, err = CBWrite(b, s, "sign/caa", map[string]interface{}{
+		"csr": csrPem,
+		"ttl": "1h",
+	})
+	require.ErrorContains(t, err, "do not authorize issuance")
+}
+
+// TestPki_RoleCAARequestContext ensures that cancelling a sign request stops
+// its CAA lookups, rather than waiting for them to time out.
+func TestPki_RoleCAARequestContext(t *testing.T) {
+	t.Parallel()
+
+	// Resolvers which never answer.
+	var resolvers []string
+	for i := 0; i < 5; i++ {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		resolvers = append(resolvers, conn.LocalAddr().String())
+	}
+
+	b, s := CreateBackendWithStorage(t)
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	resp, err = CBWrite(b, s, "roles/caa", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"caa_identities": "vault.example.com",
+		"caa_resolvers":  strings.Join(resolvers, ","),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/caa")
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "www.example.com"},
+		DNSNames: []string{"www.example.com"},
+	}, "ec", 256)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation:  logical.UpdateOperation,
+		Path:       "sign/caa",
+		Data:       map[string]interface{}{"csr": csrPem},
+		Storage:    s,
+		MountPoint: "pki/",
+	})
+	require.True(t, err != nil || resp.IsError(), "expected the sign request to fail")
+	require.Less(t, time.Since(start), 5*time.Second)
+}
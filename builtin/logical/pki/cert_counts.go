@@ -0,0 +1,198 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// issuanceCounts tracks the number of leaf certificates issued and revoked
+// by each role since this node started. Unlike the stored certificate
+// counts, they are not rebuilt from storage.
+type issuanceCounts struct {
+	lock    sync.Mutex
+	issued  uint64
+	revoked uint64
+	roles   map[string]*roleIssuanceCounts
+}
+
+type roleIssuanceCounts struct {
+	Issued  uint64
+	Revoked uint64
+}
+
+func newIssuanceCounts() *issuanceCounts {
+	return &issuanceCounts{
+		roles: make(map[string]*roleIssuanceCounts),
+	}
+}
+
+func (c *issuanceCounts) role(name string) *roleIssuanceCounts {
+	counts, ok := c.roles[name]
+	if !ok {
+		counts = &roleIssuanceCounts{}
+		c.roles[name] = counts
+	}
+	return counts
+}
+
+// countIssuedCertificate records the issuance of a leaf certificate by the
+// named role, which is empty for certificates not issued through a stored
+// role, such as those of sign-verbatim.
+func (b *backend) countIssuedCertificate(role string) {
+	b.issuanceCounts.lock.Lock()
+	b.issuanceCounts.issued++
+	if role != "" {
+		b.issuanceCounts.role(role).Issued++
+	}
+	b.issuanceCounts.lock.Unlock()
+
+	metrics.IncrCounterWithLabels([]string{"secrets", "pki", b.backendUUID, "issued_certificates"}, 1, []metrics.Label{{"role", role}})
+}
+
+// countRevokedCertificate records the revocation of a certificate issued by
+// the named role, which is empty when it is unknown.
+func (b *backend) countRevokedCertificate(role string) {
+	b.issuanceCounts.lock.Lock()
+	b.issuanceCounts.revoked++
+	if role != "" {
+		b.issuanceCounts.role(role).Revoked++
+	}
+	b.issuanceCounts.lock.Unlock()
+
+	metrics.IncrCounterWithLabels([]string{"secrets", "pki", b.backendUUID, "revoked_certificates"}, 1, []metrics.Label{{"role", role}})
+}
+
+// checkStoredCertificateCap refuses issuance of certificates to be stored
+// once the mount stores max_stored_certificates of them. The cap is only
+// enforced while stored certificates are counted.
+func (b *backend) checkStoredCertificateCap(config *issuanceLimitsConfigEntry, role *roleEntry) error {
+	if config.MaxStoredCertificates <= 0 || role.NoStore || !b.certCountEnabled.Load() || !b.certsCounted.Load() {
+		return nil
+	}
+
+	if stored := b.certCount.Load(); int64(stored) >= int64(config.MaxStoredCertificates) {
+		return errutil.UserError{Err: fmt.Sprintf("this mount stores %d certificates, reaching its max_stored_certificates limit of %d; tidy expired certificates or issue with a no_store role", stored, config.MaxStoredCertificates)}
+	}
+
+	return nil
+}
+
+func pathCertCounts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "cert-counts",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "certificate-counts",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathCertCountsRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"stored_certificates": {
+								Type:        framework.TypeInt64,
+								Description: `Number of certificates stored by this mount, when counted`,
+								Required:    false,
+							},
+							"stored_revoked_certificates": {
+								Type:        framework.TypeInt64,
+								Description: `Number of revoked certificates stored by this mount, when counted`,
+								Required:    false,
+							},
+							"stored_count_error": {
+								Type:        framework.TypeString,
+								Description: `Why stored certificates are not counted, if they are not`,
+								Required:    false,
+							},
+							"max_stored_certificates": {
+								Type:        framework.TypeInt,
+								Description: `Number of stored certificates beyond which issuance is refused`,
+								Required:    true,
+							},
+							"issued_certificates": {
+								Type:        framework.TypeInt64,
+								Description: `Number of leaf certificates issued by this node since it started`,
+								Required:    true,
+							},
+							"revoked_certificates": {
+								Type:        framework.TypeInt64,
+								Description: `Number of certificates revoked by this node since it started`,
+								Required:    true,
+							},
+							"roles": {
+								Type:        framework.TypeMap,
+								Description: `Number of certificates issued and revoked by this node since it started, by role`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathCertCountsHelpSyn,
+		HelpDescription: pathCertCountsHelpDesc,
+	}
+}
+
+func (b *backend) pathCertCountsRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getIssuanceLimitsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"max_stored_certificates": config.MaxStoredCertificates,
+		},
+	}
+
+	if b.certCountEnabled.Load() {
+		resp.Data["stored_certificates"] = int64(b.certCount.Load())
+		resp.Data["stored_revoked_certificates"] = int64(b.revokedCertCount.Load())
+	}
+	if b.certCountError != "" {
+		resp.Data["stored_count_error"] = b.certCountError
+	}
+
+	b.issuanceCounts.lock.Lock()
+	defer b.issuanceCounts.lock.Unlock()
+
+	roles := make(map[string]interface{}, len(b.issuanceCounts.roles))
+	for name, counts := range b.issuanceCounts.roles {
+		roles[name] = map[string]interface{}{
+			"issued_certificates":  int64(counts.Issued),
+			"revoked_certificates": int64(counts.Revoked),
+		}
+	}
+
+	resp.Data["issued_certificates"] = int64(b.issuanceCounts.issued)
+	resp.Data["revoked_certificates"] = int64(b.issuanceCounts.revoked)
+	resp.Data["roles"] = roles
+
+	return resp, nil
+}
+
+const pathCertCountsHelpSyn = `Read the number of certificates stored, issued and revoked by this mount.`
+
+const pathCertCountsHelpDesc = `
+This path returns the number of certificates stored by this mount, when
+maintain_stored_certificate_counts is enabled in config/auto-tidy, and the
+number of leaf certificates issued and revoked by this node since it
+started, in total and by role.
+`
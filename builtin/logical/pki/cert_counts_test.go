@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_CertCounts(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	readCounts := func() map[string]interface{} {
+		resp, err := CBRead(b, s, "cert-counts")
+		requireSuccessNonNilResponse(t, resp, err, "cert-counts")
+		schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("cert-counts"), logical.ReadOperation), resp, true)
+		return resp.Data
+	}
+
+	counts := readCounts()
+	require.Equal(t, int64(0), counts["issued_certificates"])
+	require.NotContains(t, counts, "stored_certificates")
+	require.NotEmpty(t, counts["stored_count_error"])
+
+	_, err := CBWrite(b, s, "config/issuance-limits", map[string]interface{}{
+		"max_stored_certificates": 3,
+	})
+	require.ErrorContains(t, err, "requires maintain_stored_certificate_counts")
+
+	resp, err := CBWrite(b, s, "config/auto-tidy", map[string]interface{}{
+		"maintain_stored_certificate_counts": true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/auto-tidy")
+	require.NoError(t, b.initializeStoredCertificateCounts(context.Background()))
+
+	resp, err = CBWrite(b, s, "config/issuance-limits", map[string]interface{}{
+		"max_stored_certificates": 3,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/issuance-limits")
+	require.Equal(t, 3, resp.Data["max_stored_certificates"])
+
+	// The root is stored too, counting towards the cap.
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	for _, role := range []string{"test", "nostore"} {
+		resp, err = CBWrite(b, s, "roles/"+role, map[string]interface{}{
+			"allow_any_name": true,
+			"key_type":       "ec",
+			"no_store":       role == "nostore",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "roles/"+role)
+	}
+
+	issue := func(role string) (*logical.Response, error) {
+		return CBWrite(b, s, "issue/"+role, map[string]interface{}{
+			"common_name": "www.example.com",
+		})
+	}
+
+	resp, err = issue("test")
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	serial := resp.Data["serial_number"].(string)
+	resp, err = issue("test")
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	_, err = issue("test")
+	require.ErrorContains(t, err, "this mount stores 3 certificates, reaching its max_stored_certificates limit of 3")
+
+	// Certificates which are not stored are not capped.
+	resp, err = issue("nostore")
+	requireSuccessNonNilResponse(t, resp, err, "issue/nostore")
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serial,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+
+	counts = readCounts()
+	require.Equal(t, int64(3), counts["stored_certificates"])
+	require.Equal(t, int64(1), counts["stored_revoked_certificates"])
+	require.Equal(t, int64(3), counts["issued_certificates"])
+	require.Equal(t, int64(1), counts["revoked_certificates"])
+	require.Equal(t, map[string]interface{}{
+		"test": map[string]interface{}{
+			"issued_certificates":  int64(2),
+			"revoked_certificates": int64(1),
+		},
+		"nostore": map[string]interface{}{
+			"issued_certificates":  int64(1),
+			"revoked_certificates": int64(0),
+		},
+	}, counts["roles"])
+}
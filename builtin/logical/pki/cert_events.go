@@ -0,0 +1,41 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Event types sent through Vault's event system when certificates are issued
+// (with a key generated by Vault), signed from a CSR, or revoked.
+const (
+	eventTypeCertIssue  = "pki/issue"
+	eventTypeCertSign   = "pki/sign"
+	eventTypeCertRevoke = "pki/revoke"
+)
+
+// sendCertEvent notifies event subscribers, such as inventory systems, of a
+// change to a certificate, so they don't have to poll certs/ for it. Events
+// are best effort: failing to send one does not fail the operation.
+func (b *backend) sendCertEvent(ctx context.Context, eventType string, cert *x509.Certificate, role string, issuerId issuerID, entityId string) {
+	serial := serialFromCert(cert)
+	err := logical.SendEvent(ctx, b, eventType,
+		logical.EventMetadataDataPath, "cert/"+serial,
+		logical.EventMetadataOperation, "write",
+		logical.EventMetadataModified, "true",
+		"serial_number", serial,
+		"common_name", cert.Subject.CommonName,
+		"role", role,
+		"issuer_id", issuerId.String(),
+		"entity_id", entityId,
+	)
+	if err != nil && !errors.Is(err, framework.ErrNoEvents) {
+		b.Logger().Warn("failed to send certificate event", "event_type", eventType, "serial_number", serial, "error", err)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEventSender struct {
+	lock   sync.Mutex
+	types  []logical.EventType
+	events []*logical.EventData
+}
+
+func (m *mockEventSender) SendEvent(_ context.Context, eventType logical.EventType, event *logical.EventData) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.types = append(m.types, eventType)
+	m.events = append(m.events, event)
+	return nil
+}
+
+// last returns the type and metadata of the most recent event.
+func (m *mockEventSender) last(t *testing.T) (logical.EventType, map[string]interface{}) {
+	t.Helper()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	require.NotEmpty(t, m.events, "expected an event to have been sent")
+	return m.types[len(m.types)-1], m.events[len(m.events)-1].Metadata.AsMap()
+}
+
+func (m *mockEventSender) count() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return len(m.events)
+}
+
+func TestCertEvents(t *testing.T) {
+	t.Parallel()
+
+	events := &mockEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+
+	b := Backend(config)
+	require.NoError(t, b.Setup(context.Background(), config))
+	b.pkiStorageVersion.Store(1)
+	s := config.StorageView
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	issuerId := resp.Data["issuer_id"].(issuerID).String()
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+	})
+	require.NoError(t, err)
+
+	requireEvent := func(expectedType string, serial string, commonName string, role string, issuer string) {
+		t.Helper()
+
+		eventType, metadata := events.last(t)
+		require.Equal(t, logical.EventType(expectedType), eventType)
+		require.Equal(t, serial, metadata["serial_number"])
+		require.Equal(t, commonName, metadata["common_name"])
+		require.Equal(t, role, metadata["role"])
+		require.Equal(t, issuer, metadata["issuer_id"])
+		require.Equal(t, "cert/"+serial, metadata[logical.EventMetadataDataPath])
+		require.Contains(t, metadata, "entity_id")
+	}
+
+	resp, err = CBWrite(b, s, "issue/example", map[string]interface{}{
+		"common_name": "issued.example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/example")
+	issuedSerial := resp.Data["serial_number"].(string)
+	requireEvent(eventTypeCertIssue, issuedSerial, "issued.example.com", "example", issuerId)
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "signed.example.com"},
+	}, "ec", 256)
+	resp, err = CBWrite(b, s, "sign/example", map[string]interface{}{
+		"csr": csrPem,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign/example")
+	requireEvent(eventTypeCertSign, resp.Data["serial_number"].(string), "signed.example.com", "example", issuerId)
+
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": issuedSerial,
+	})
+	require.NoError(t, err)
+	requireEvent(eventTypeCertRevoke, issuedSerial, "issued.example.com", "", issuerId)
+
+	// Revoking an already revoked certificate isn't a change.
+	sent := events.count()
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": issuedSerial,
+	})
+	require.NoError(t, err)
+	require.Equal(t, sent, events.count())
+}
@@ -0,0 +1,189 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/ryanuber/go-glob"
+)
+
+// certListFilter narrows LIST certs down to the certificates matching all
+// of the given criteria, so operators can locate a certificate without
+// reading every serial themselves.
+type certListFilter struct {
+	commonName   string
+	san          string
+	serialPrefix string
+	role         string
+	issuerId     issuerID
+	issuerCert   *x509.Certificate
+
+	expiresBefore time.Time
+	expiresAfter  time.Time
+
+	// When only listing revoked certificates, the (normalized) serials of
+	// all of them, as listed once up front.
+	revokedOnly bool
+	revoked     map[string]struct{}
+}
+
+func newCertListFilter(sc *storageContext, data *framework.FieldData) (*certListFilter, error) {
+	filter := &certListFilter{
+		commonName:   strings.ToLower(data.Get("common_name").(string)),
+		san:          strings.ToLower(data.Get("san").(string)),
+		serialPrefix: normalizeSerial(data.Get("serial_prefix").(string)),
+		role:         data.Get("role").(string),
+		revokedOnly:  data.Get("revoked_only").(bool),
+	}
+
+	var err error
+	filter.expiresBefore, err = parseCertListTime(data, "expires_before")
+	if err != nil {
+		return nil, err
+	}
+	filter.expiresAfter, err = parseCertListTime(data, "expires_after")
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.revokedOnly {
+		revoked, err := sc.listRevokedCerts()
+		if err != nil {
+			return nil, err
+		}
+
+		filter.revoked = make(map[string]struct{}, len(revoked))
+		for _, serial := range revoked {
+			filter.revoked[normalizeSerial(serial)] = struct{}{}
+		}
+	}
+
+	if issuerRef := data.Get(issuerRefParam).(string); issuerRef != "" {
+		issuerId, err := sc.resolveIssuerReference(issuerRef)
+		if err != nil {
+			return nil, errutil.UserError{Err: err.Error()}
+		}
+
+		issuer, err := sc.fetchIssuerById(issuerId)
+		if err != nil {
+			return nil, err
+		}
+
+		filter.issuerId = issuerId
+		filter.issuerCert, err = issuer.GetCertificate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+func parseCertListTime(data *framework.FieldData, field string) (time.Time, error) {
+	raw := data.Get(field).(string)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errutil.UserError{Err: fmt.Sprintf("invalid %v: must be an RFC 3339 timestamp: %v", field, err)}
+	}
+
+	return parsed, nil
+}
+
+func (f *certListFilter) isEmpty() bool {
+	return f.commonName == "" && f.san == "" && f.serialPrefix == "" && f.role == "" && f.issuerId == "" &&
+		f.expiresBefore.IsZero() && f.expiresAfter.IsZero() && !f.revokedOnly
+}
+
+// needsCert reports whether matching requires the stored certificate, rather
+// than just its serial.
+func (f *certListFilter) needsCert() bool {
+	return f.commonName != "" || f.san != "" || f.issuerId != "" || !f.expiresBefore.IsZero() || !f.expiresAfter.IsZero()
+}
+
+func (f *certListFilter) needsMetadata() bool {
+	return f.role != "" || f.issuerId != ""
+}
+
+// matchesSerial filters on the (normalized) serial alone, ahead of fetching
+// the certificate or its metadata.
+func (f *certListFilter) matchesSerial(serial string) bool {
+	normalized := normalizeSerial(serial)
+	if f.revokedOnly {
+		if _, ok := f.revoked[normalized]; !ok {
+			return false
+		}
+	}
+
+	return strings.HasPrefix(normalized, f.serialPrefix)
+}
+
+func (f *certListFilter) matches(cert *x509.Certificate, metadata *certMetadataEntry) bool {
+	if f.role != "" && (metadata == nil || metadata.Role != f.role) {
+		return false
+	}
+
+	if f.commonName != "" && !glob.Glob(f.commonName, strings.ToLower(cert.Subject.CommonName)) {
+		return false
+	}
+
+	if f.san != "" && !f.matchesSAN(cert) {
+		return false
+	}
+
+	if !f.expiresBefore.IsZero() && !cert.NotAfter.Before(f.expiresBefore) {
+		return false
+	}
+
+	if !f.expiresAfter.IsZero() && !cert.NotAfter.After(f.expiresAfter) {
+		return false
+	}
+
+	if f.issuerId != "" {
+		if metadata != nil && metadata.IssuerId != "" {
+			return metadata.IssuerId == f.issuerId
+		}
+
+		// Certificates stored without metadata are matched on the issuer's
+		// subject and key instead, as in chain building.
+		if !bytes.Equal(cert.RawIssuer, f.issuerCert.RawSubject) {
+			return false
+		}
+		if len(cert.AuthorityKeyId) > 0 && !bytes.Equal(cert.AuthorityKeyId, f.issuerCert.SubjectKeyId) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *certListFilter) matchesSAN(cert *x509.Certificate) bool {
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+
+	for _, san := range sans {
+		if glob.Glob(f.san, strings.ToLower(san)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -4,6 +4,7 @@
 package pki
 
 import (
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/ed25519"
@@ -40,6 +41,17 @@ type inputBundle struct {
 	role    *roleEntry
 	req     *logical.Request
 	apiData *framework.FieldData
+
+	// externalProofOfPossession is set when possession of the CSR's key was
+	// verified outside of the CSR, whose signature is then not checked.
+	externalProofOfPossession bool
+
+	// policyWebhook, when set, is consulted before issuing leaf certificates.
+	policyWebhook *policyWebhookConfigEntry
+
+	// serialNumber, when set, is the caller supplied serial number of the
+	// certificate, in place of one generated for its issuer.
+	serialNumber *big.Int
 }
 
 var (
@@ -137,6 +149,20 @@ func getFormat(data *framework.FieldData) string {
 	return format
 }
 
+// getIssueSignFormat extends getFormat with the formats available when
+// issuing leaf certificates. As it bundles the private key, "pkcs12" is only
+// available when the key is generated by Vault.
+func getIssueSignFormat(data *framework.FieldData, generateKey bool) string {
+	format := data.Get("format").(string)
+	switch {
+	case format == "pkcs7":
+		return format
+	case format == "pkcs12" && generateKey:
+		return format
+	}
+	return getFormat(data)
+}
+
 // fetchCAInfo will fetch the CA info, will return an error if no ca info exists, this does NOT support
 // loading using the legacyBundleShimID and should be used with care. This should be called only once
 // within the request path otherwise you run the risk of a race condition with the issuer migration on perf-secondaries.
@@ -205,6 +231,13 @@ func (sc *storageContext) fetchCAInfoByIssuerId(issuerId issuerID, usage issuerU
 		URLs:                 nil,
 		LeafNotAfterBehavior: entry.LeafNotAfterBehavior,
 		RevocationSigAlg:     entry.RevocationSigAlg,
+		PolicyIdentifiers:    entry.PolicyIdentifiers,
+		SerialNumberBits:     entry.SerialNumberBits,
+	}
+
+	caInfo.SerialNumberPrefix, err = parseSerialNumberPrefix(entry.SerialNumberPrefix)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse serial number prefix of issuer %v: %v", issuerId, err)}
 	}
 
 	entries, err := entry.GetAIAURLs(sc)
@@ -320,25 +353,110 @@ func fetchCertBySerial(sc *storageContext, prefix, serial string) (*logical.Stor
 
 // Given a URI SAN, verify that it is allowed.
 func validateURISAN(b *backend, data *inputBundle, uri string) bool {
-	valid := false
 	for _, allowed := range data.role.AllowedURISANs {
+		exact := false
 		if data.role.AllowedURISANsTemplate {
-			isTemplate, _ := framework.ValidateIdentityTemplate(allowed)
-			if isTemplate && data.req.EntityID != "" {
-				tmpAllowed, err := framework.PopulateIdentityTemplate(allowed, data.req.EntityID, b.System())
-				if err != nil {
-					continue
-				}
-				allowed = tmpAllowed
+			var ok bool
+			allowed, exact, ok = resolveAllowedTemplate(b, data, allowed)
+			if !ok {
+				continue
 			}
 		}
-		validURI := glob.Glob(allowed, uri)
-		if validURI {
-			valid = true
-			break
+		if exact && allowed == uri || !exact && glob.Glob(allowed, uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// Given an email address (RFC 822 name), verify that it is allowed by the
+// role's allowed_email_sans. Like allowed_uri_sans, these may be identity
+// templates, such as {{identity.entity.metadata.email}}.
+func validateEmailSAN(b *backend, data *inputBundle, email string) bool {
+	email = strings.ToLower(email)
+	for _, allowed := range data.role.AllowedEmailSANs {
+		allowed, exact, ok := resolveAllowedTemplate(b, data, allowed)
+		if !ok {
+			continue
+		}
+		allowed = strings.ToLower(allowed)
+		if exact && allowed == email || !exact && glob.Glob(allowed, email) {
+			return true
 		}
 	}
-	return valid
+	return false
+}
+
+// resolveAllowedTemplate populates an allowed value which may be an identity
+// template against the requester's identity. It returns the resulting
+// pattern, whether values must match it exactly rather than as a glob, and
+// whether it can match at all.
+func resolveAllowedTemplate(b *backend, data *inputBundle, allowed string) (string, bool, bool) {
+	isTemplate, _ := framework.ValidateIdentityTemplate(allowed)
+	if !isTemplate {
+		return allowed, false, true
+	}
+
+	// Templates only match once resolved against the requester's identity,
+	// never literally.
+	if data.req.EntityID == "" {
+		return "", false, false
+	}
+	tmpAllowed, err := framework.PopulateIdentityTemplate(allowed, data.req.EntityID, b.System())
+	if err != nil {
+		return "", false, false
+	}
+
+	// Identity values containing globs must not widen the pattern, so values
+	// have to match them exactly.
+	exact := strings.Count(tmpAllowed, "*") != strings.Count(allowed, "*")
+	return tmpAllowed, exact, true
+}
+
+// validateWildcardPolicy checks a wildcard name, split into its wildcard
+// label and the remaining zone, against the role's wildcard_full_label_only,
+// max_wildcard_depth and denied_wildcard_zones restrictions.
+func validateWildcardPolicy(role *roleEntry, wildcardLabel string, zone string) bool {
+	if role.WildcardFullLabelOnly && wildcardLabel != "*" {
+		return false
+	}
+
+	if role.MaxWildcardDepth > 0 {
+		depth := 1
+		if zone != "" {
+			depth += strings.Count(strings.TrimSuffix(zone, "."), ".") + 1
+		}
+		if depth > role.MaxWildcardDepth {
+			return false
+		}
+	}
+
+	for _, denied := range role.DeniedWildcardZones {
+		if strings.EqualFold(strings.TrimSuffix(zone, "."), strings.TrimSuffix(denied, ".")) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Given an IP SAN, verify that it is within one of the role's allowed_ip_sans
+// CIDRs, if any are set.
+func validateIPSAN(role *roleEntry, ip net.IP) bool {
+	if len(role.AllowedIPSANs) == 0 {
+		return true
+	}
+
+	ipNets, err := parseIPRanges(role.AllowedIPSANs)
+	if err != nil {
+		return false
+	}
+	for _, ipNet := range ipNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
 
 // Validates a given common name, ensuring it's either an email or a hostname
@@ -509,6 +627,12 @@ func validateNames(b *backend, data *inputBundle, names []string) string {
 			if err != nil {
 				return name
 			}
+
+			// The role's wildcard policy likewise takes precedence over
+			// AllowAnyName.
+			if !validateWildcardPolicy(data.role, wildcardLabel, reducedName) {
+				return name
+			}
 		}
 
 		// Email addresses using wildcard domain names do not make sense
@@ -676,6 +800,32 @@ func validateNames(b *backend, data *inputBundle, names []string) string {
 	return ""
 }
 
+// upnOID is the otherName type of Microsoft User Principal Names, as used for
+// smartcard logon and 802.1X.
+const upnOID = "1.3.6.1.4.1.311.20.2.3"
+
+// validateUPN checks that a User Principal Name is of the form user@suffix.
+func validateUPN(upn string) error {
+	user, suffix, found := strings.Cut(upn, "@")
+	if !found || user == "" || suffix == "" || strings.Contains(suffix, "@") {
+		return fmt.Errorf("UPN %q is not of the form user@suffix", upn)
+	}
+	return nil
+}
+
+// Given a User Principal Name, verify that it is allowed by allowed_upn_sans.
+func validateUPNSAN(data *inputBundle, upn string) bool {
+	if validateUPN(upn) != nil {
+		return false
+	}
+	for _, allowed := range data.role.AllowedUPNSANs {
+		if glob.Glob(strings.ToLower(allowed), strings.ToLower(upn)) {
+			return true
+		}
+	}
+	return false
+}
+
 // validateOtherSANs checks if the values requested are allowed. If an OID
 // isn't allowed, it will be returned as the first string. If a value isn't
 // allowed, it will be returned as the second string. Empty strings + error
@@ -692,6 +842,12 @@ func validateOtherSANs(data *inputBundle, requested map[string][]string) (string
 	}
 	for oid, names := range requested {
 		for _, name := range names {
+			// UPNs may be allowed by either allowed_upn_sans or
+			// allowed_other_sans.
+			if oid == upnOID && validateUPNSAN(data, name) {
+				continue
+			}
+
 			allowedNames, ok := allowed[oid]
 			if !ok {
 				return oid, "", nil
@@ -814,7 +970,9 @@ func generateCert(sc *storageContext,
 
 	if isCA {
 		data.Params.IsCA = isCA
-		data.Params.PermittedDNSDomains = input.apiData.Get("permitted_dns_domains").([]string)
+		if err := getNameConstraints(input.apiData, data.Params); err != nil {
+			return nil, nil, err
+		}
 
 		if data.SigningBundle == nil {
 			// Generating a self-signed root certificate. Since we have no
@@ -856,6 +1014,22 @@ func generateCert(sc *storageContext,
 				data.Params.MaxPathLength = *input.role.MaxPathLength
 			}
 		}
+	} else if caSign != nil {
+		webhookWarnings, err := applyPolicyWebhook(ctx, input.policyWebhook, input, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, webhookWarnings...)
+
+		if err := checkIssuerNameConstraints(caSign, data); err != nil {
+			return nil, nil, err
+		}
+
+		caaWarnings, err := checkCAA(ctx, input.role, data)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, caaWarnings...)
 	}
 
 	parsedBundle, err := generateCABundle(sc, input, data, randomSource)
@@ -868,6 +1042,39 @@ func generateCert(sc *storageContext,
 
 // N.B.: This is only meant to be used for generating intermediate CAs.
 // It skips some sanity checks.
+// getNameConstraints reads the name constraints requested for a CA
+// certificate into the creation parameters.
+func getNameConstraints(data *framework.FieldData, params *certutil.CreationParameters) error {
+	params.PermittedDNSDomains = data.Get("permitted_dns_domains").([]string)
+	params.ExcludedDNSDomains = data.Get("excluded_dns_domains").([]string)
+	params.PermittedEmailAddresses = data.Get("permitted_email_addresses").([]string)
+	params.ExcludedEmailAddresses = data.Get("excluded_email_addresses").([]string)
+	params.PermittedURIDomains = data.Get("permitted_uri_domains").([]string)
+	params.ExcludedURIDomains = data.Get("excluded_uri_domains").([]string)
+
+	var err error
+	if params.PermittedIPRanges, err = parseIPRanges(data.Get("permitted_ip_ranges").([]string)); err != nil {
+		return errutil.UserError{Err: fmt.Sprintf("invalid permitted_ip_ranges: %v", err)}
+	}
+	if params.ExcludedIPRanges, err = parseIPRanges(data.Get("excluded_ip_ranges").([]string)); err != nil {
+		return errutil.UserError{Err: fmt.Sprintf("invalid excluded_ip_ranges: %v", err)}
+	}
+
+	return nil
+}
+
+func parseIPRanges(ranges []string) ([]*net.IPNet, error) {
+	var ipNets []*net.IPNet
+	for _, ipRange := range ranges {
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(ipRange))
+		if err != nil {
+			return nil, err
+		}
+		ipNets = append(ipNets, ipNet)
+	}
+	return ipNets, nil
+}
+
 func generateIntermediateCSR(sc *storageContext, input *inputBundle, randomSource io.Reader) (*certutil.ParsedCSRBundle, []string, error) {
 	b := sc.Backend
 
@@ -879,6 +1086,12 @@ func generateIntermediateCSR(sc *storageContext, input *inputBundle, randomSourc
 		return nil, nil, errutil.InternalError{Err: "nil parameters received from parameter bundle generation"}
 	}
 
+	if input.apiData != nil {
+		if err := getNameConstraints(input.apiData, creation.Params); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	addBasicConstraints := input.apiData != nil && input.apiData.Get("add_basic_constraints").(bool)
 	parsedBundle, err := generateCSRBundle(sc, input, creation, addBasicConstraints, randomSource)
 	if err != nil {
@@ -888,11 +1101,34 @@ func generateIntermediateCSR(sc *storageContext, input *inputBundle, randomSourc
 	return parsedBundle, warnings, nil
 }
 
-func signCert(b *backend,
+func signCert(ctx context.Context,
+	b *backend,
 	data *inputBundle,
 	caSign *certutil.CAInfoBundle,
 	isCA bool,
 	useCSRValues bool) (*certutil.ParsedCertBundle, []string, error,
+) {
+	creation, warnings, err := prepareSignCert(ctx, b, data, caSign, isCA, useCSRValues)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsedBundle, err := certutil.SignCertificate(creation)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return parsedBundle, warnings, nil
+}
+
+// prepareSignCert validates the CSR of the request against the role,
+// returning the creation bundle of the certificate to sign it with.
+func prepareSignCert(ctx context.Context,
+	b *backend,
+	data *inputBundle,
+	caSign *certutil.CAInfoBundle,
+	isCA bool,
+	useCSRValues bool) (*certutil.CreationBundle, []string, error,
 ) {
 	if data.role == nil {
 		return nil, nil, errutil.InternalError{Err: "no role found in data bundle"}
@@ -1061,6 +1297,10 @@ func signCert(b *backend,
 		}
 	}
 
+	if err := validateKeyAttestation(data, csr.PublicKey); err != nil {
+		return nil, nil, err
+	}
+
 	creation, warnings, err := generateCreationBundle(b, data, caSign, csr)
 	if err != nil {
 		return nil, nil, err
@@ -1071,23 +1311,37 @@ func signCert(b *backend,
 
 	creation.Params.IsCA = isCA
 	creation.Params.UseCSRValues = useCSRValues
+	creation.SkipCSRSignatureCheck = data.externalProofOfPossession
 
 	if isCA {
-		creation.Params.PermittedDNSDomains = data.apiData.Get("permitted_dns_domains").([]string)
+		if err := getNameConstraints(data.apiData, creation.Params); err != nil {
+			return nil, nil, err
+		}
 	} else {
 		for _, ext := range csr.Extensions {
 			if ext.Id.Equal(certutil.ExtensionBasicConstraintsOID) {
 				warnings = append(warnings, "specified CSR contained a Basic Constraints extension that was ignored during issuance")
 			}
 		}
-	}
 
-	parsedBundle, err := certutil.SignCertificate(creation)
-	if err != nil {
-		return nil, nil, err
+		webhookWarnings, err := applyPolicyWebhook(ctx, data.policyWebhook, data, creation)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, webhookWarnings...)
+
+		if err := checkIssuerNameConstraints(caSign, creation); err != nil {
+			return nil, nil, err
+		}
+
+		caaWarnings, err := checkCAA(ctx, data.role, creation)
+		if err != nil {
+			return nil, nil, err
+		}
+		warnings = append(warnings, caaWarnings...)
 	}
 
-	return parsedBundle, warnings, nil
+	return creation, warnings, nil
 }
 
 // otherNameRaw describes a name related to a certificate which is not in one
@@ -1313,6 +1567,21 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 			return nil, nil, errutil.UserError{Err: fmt.Sprintf(
 				"email address %s not allowed by this role", badName)}
 		}
+
+		// Email addresses, including one in the CN, must additionally
+		// match the role's allowed_email_sans, if any are set.
+		if len(data.role.AllowedEmailSANs) > 0 {
+			requestedEmails := emailAddresses
+			if strings.Contains(cn, "@") {
+				requestedEmails = append(append([]string{}, emailAddresses...), cn)
+			}
+			for _, email := range requestedEmails {
+				if !validateEmailSAN(b, data, email) {
+					return nil, nil, errutil.UserError{Err: fmt.Sprintf(
+						"email address %s not allowed by this role's allowed_email_sans", email)}
+				}
+			}
+		}
 	}
 
 	// otherSANsInput has the same format as the other_sans HTTP param in the
@@ -1325,6 +1594,14 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 	if sans := data.apiData.Get("other_sans").([]string); len(sans) > 0 {
 		otherSANsInput = sans
 	}
+	if upns, ok := data.apiData.GetOk("upn_sans"); ok {
+		for _, upn := range upns.([]string) {
+			if err := validateUPN(upn); err != nil {
+				return nil, nil, errutil.UserError{Err: fmt.Sprintf("invalid upn_sans: %v", err)}
+			}
+			otherSANsInput = append(otherSANsInput, upnOID+";UTF8:"+upn)
+		}
+	}
 	if data.role.UseCSRSANs && csr != nil && len(csr.Extensions) > 0 {
 		others, err := getOtherSANsFromX509Extensions(csr.Extensions)
 		if err != nil {
@@ -1362,6 +1639,12 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 				if !data.role.AllowIPSANs {
 					return nil, nil, errutil.UserError{Err: "IP Subject Alternative Names are not allowed in this role, but was provided some via CSR"}
 				}
+				for _, ip := range csr.IPAddresses {
+					if !validateIPSAN(data.role, ip) {
+						return nil, nil, errutil.UserError{Err: fmt.Sprintf(
+							"IP Subject Alternative Name %s not allowed by this role", ip)}
+					}
+				}
 				ipAddresses = csr.IPAddresses
 			}
 		} else {
@@ -1377,6 +1660,10 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 						return nil, nil, errutil.UserError{Err: fmt.Sprintf(
 							"the value %q is not a valid IP address", v)}
 					}
+					if !validateIPSAN(data.role, parsedIP) {
+						return nil, nil, errutil.UserError{Err: fmt.Sprintf(
+							"IP Subject Alternative Name %s not allowed by this role", v)}
+					}
 					ipAddresses = append(ipAddresses, parsedIP)
 				}
 			}
@@ -1457,6 +1744,11 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 	}
 	warnings = append(warnings, ttlWarnings...)
 
+	notBeforeDuration, err := getCertificateNotBeforeDuration(data)
+	if err != nil {
+		return nil, warnings, err
+	}
+
 	// Parse SKID from the request for cross-signing.
 	var skid []byte
 	{
@@ -1513,6 +1805,32 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 		}
 	}
 
+	extraExtensions, err := buildCustomExtensions(b, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msExtensions, err := buildMicrosoftExtensions(data.role)
+	if err != nil {
+		return nil, nil, err
+	}
+	extraExtensions = append(extraExtensions, msExtensions...)
+
+	// Certificates without policies of their own inherit those of their
+	// issuer.
+	policyIdentifiers := data.role.PolicyIdentifiers
+	if len(policyIdentifiers) == 0 && caSign != nil {
+		policyIdentifiers = caSign.PolicyIdentifiers
+	}
+
+	serialNumber := data.serialNumber
+	if serialNumber == nil {
+		serialNumber, err = generateSerialNumber(caSign)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	creation := &certutil.CreationBundle{
 		Params: &certutil.CreationParameters{
 			Subject:                       subject,
@@ -1529,11 +1847,13 @@ func generateCreationBundle(b *backend, data *inputBundle, caSign *certutil.CAIn
 			KeyUsage:                      x509.KeyUsage(parseKeyUsages(data.role.KeyUsage)),
 			ExtKeyUsage:                   parseExtKeyUsages(data.role),
 			ExtKeyUsageOIDs:               data.role.ExtKeyUsageOIDs,
-			PolicyIdentifiers:             data.role.PolicyIdentifiers,
+			PolicyIdentifiers:             policyIdentifiers,
 			BasicConstraintsValidForNonCA: data.role.BasicConstraintsValidForNonCA,
-			NotBeforeDuration:             data.role.NotBeforeDuration,
+			NotBeforeDuration:             notBeforeDuration,
 			ForceAppendCaChain:            caSign != nil,
 			SKID:                          skid,
+			ExtraExtensions:               extraExtensions,
+			SerialNumber:                  serialNumber,
 		},
 		SigningBundle: caSign,
 		CSR:           csr,
@@ -1615,9 +1935,25 @@ func getCertificateNotAfter(b *backend, data *inputBundle, caSign *certutil.CAIn
 		if err != nil {
 			return notAfter, warnings, errutil.UserError{Err: err.Error()}
 		}
+		if data.role.EnforceMaxTTL && notAfter.After(time.Now().Add(maxTTL)) {
+			return time.Time{}, warnings, errutil.UserError{Err: fmt.Sprintf("not_after %v is beyond the role's maximum TTL of %v", notAfterAlt, maxTTL)}
+		}
 	} else {
 		notAfter = time.Now().Add(ttl)
 	}
+
+	// The role may override the issuer's behavior.
+	if caSign != nil && data.role.LeafNotAfterBehavior != "" {
+		behavior, err := parseLeafNotAfterBehavior(data.role.LeafNotAfterBehavior)
+		if err != nil {
+			return time.Time{}, warnings, errutil.UserError{Err: err.Error()}
+		}
+
+		roleSign := *caSign
+		roleSign.LeafNotAfterBehavior = behavior
+		caSign = &roleSign
+	}
+
 	notAfter, err = applyIssuerLeafNotAfterBehavior(caSign, notAfter)
 	if err != nil {
 		return time.Time{}, warnings, err
@@ -1625,6 +1961,50 @@ func getCertificateNotAfter(b *backend, data *inputBundle, caSign *certutil.CAIn
 	return notAfter, warnings, nil
 }
 
+// getCertificateNotBeforeDuration returns how far before now to backdate a
+// certificate: by the role's not_before_duration, unless the request asks
+// for a not_before date, which may be at most the role's
+// max_not_before_duration (or not_before_duration, if larger) ago.
+func getCertificateNotBeforeDuration(data *inputBundle) (time.Duration, error) {
+	if _, present := data.apiData.Schema["not_before"]; !present {
+		return data.role.NotBeforeDuration, nil
+	}
+
+	rawNotBefore, ok := data.apiData.GetOk("not_before")
+	if !ok || rawNotBefore.(string) == "" {
+		return data.role.NotBeforeDuration, nil
+	}
+
+	notBefore, err := time.Parse(time.RFC3339, rawNotBefore.(string))
+	if err != nil {
+		return 0, errutil.UserError{Err: fmt.Sprintf("invalid not_before: %v", err)}
+	}
+
+	backdate := time.Since(notBefore)
+	if backdate < 0 {
+		return 0, errutil.UserError{Err: fmt.Sprintf("not_before of %s is in the future", notBefore.UTC().Format(time.RFC3339))}
+	}
+
+	maxBackdate := data.role.MaxNotBeforeDuration
+	if data.role.NotBeforeDuration > maxBackdate {
+		maxBackdate = data.role.NotBeforeDuration
+	}
+	if data.role.NotBeforeDuration == 0 && maxBackdate < 30*time.Second {
+		// Matches the default backdating of certutil.
+		maxBackdate = 30 * time.Second
+	}
+	if backdate > maxBackdate {
+		return 0, errutil.UserError{Err: fmt.Sprintf("not_before of %s is further in the past than the role allows (max_not_before_duration of %s)", notBefore.UTC().Format(time.RFC3339), maxBackdate)}
+	}
+
+	// A zero duration would select the default backdating instead.
+	if backdate == 0 {
+		backdate = time.Nanosecond
+	}
+
+	return backdate, nil
+}
+
 // applyIssuerLeafNotAfterBehavior resets a certificate's notAfter time or errors out based on the
 // issuer's notAfter date along with the LeafNotAfterBehavior configuration
 func applyIssuerLeafNotAfterBehavior(caSign *certutil.CAInfoBundle, notAfter time.Time) (time.Time, error) {
@@ -1648,6 +2028,19 @@ func applyIssuerLeafNotAfterBehavior(caSign *certutil.CAInfoBundle, notAfter tim
 	return notAfter, nil
 }
 
+func parseLeafNotAfterBehavior(raw string) (certutil.NotAfterBehavior, error) {
+	switch raw {
+	case "err":
+		return certutil.ErrNotAfterBehavior, nil
+	case "truncate":
+		return certutil.TruncateNotAfterBehavior, nil
+	case "permit":
+		return certutil.PermitNotAfterBehavior, nil
+	default:
+		return certutil.ErrNotAfterBehavior, fmt.Errorf("unknown value for field `leaf_not_after_behavior`: %q. Possible values are `err`, `truncate`, and `permit`", raw)
+	}
+}
+
 func convertRespToPKCS8(resp *logical.Response) error {
 	privRaw, ok := resp.Data["private_key"]
 	if !ok {
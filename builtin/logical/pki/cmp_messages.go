@@ -0,0 +1,423 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"time"
+)
+
+// The structures below implement the subset of CMP (RFC 4210) and CRMF
+// (RFC 4211) messages needed for initialization, certification and key
+// update requests. Note that the CMP module uses explicit tagging while the
+// CRMF module uses implicit tagging; encoding/asn1 ignores tagging parameters
+// when marshalling a RawValue, so tagged raw values are built by hand.
+
+// PKIBody choices.
+const (
+	cmpBodyIR       = 0
+	cmpBodyIP       = 1
+	cmpBodyCR       = 2
+	cmpBodyCP       = 3
+	cmpBodyKUR      = 7
+	cmpBodyKUP      = 8
+	cmpBodyPKIConf  = 19
+	cmpBodyError    = 23
+	cmpBodyCertConf = 24
+)
+
+// PKIStatus values.
+const (
+	cmpStatusAccepted  = 0
+	cmpStatusRejection = 2
+)
+
+// PKIFailureInfo bits.
+const (
+	cmpFailBadMessageCheck = 1
+	cmpFailBadRequest      = 2
+	cmpFailBadDataFormat   = 5
+	cmpFailBadPOP          = 9
+)
+
+// The upper bound on the iteration count of password based MACs, so that
+// unauthenticated requests cannot make us hash indefinitely.
+const cmpMaxPBMIterations = 100000
+
+var (
+	oidCmpPasswordBasedMac = asn1.ObjectIdentifier{1, 2, 840, 113533, 7, 66, 13}
+	oidCmpImplicitConfirm  = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 4, 13}
+	oidExtensionRequest    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 14}
+
+	cmpOwfAlgorithms = map[string]crypto.Hash{
+		asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}.String():             crypto.SHA1,
+		asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}.String(): crypto.SHA256,
+		asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}.String(): crypto.SHA384,
+		asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}.String(): crypto.SHA512,
+	}
+
+	cmpMacAlgorithms = map[string]crypto.Hash{
+		asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 8, 1, 2}.String(): crypto.SHA1,
+		asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}.String():   crypto.SHA256,
+		asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}.String():  crypto.SHA384,
+		asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}.String():  crypto.SHA512,
+	}
+)
+
+type cmpMessage struct {
+	Header     asn1.RawValue
+	Body       asn1.RawValue
+	Protection asn1.BitString  `asn1:"optional,explicit,tag:0"`
+	ExtraCerts []asn1.RawValue `asn1:"optional,explicit,tag:1"`
+}
+
+type cmpHeader struct {
+	Pvno          int
+	Sender        asn1.RawValue
+	Recipient     asn1.RawValue
+	MessageTime   time.Time                `asn1:"optional,explicit,tag:0,generalized"`
+	ProtectionAlg pkix.AlgorithmIdentifier `asn1:"optional,explicit,tag:1"`
+	SenderKID     []byte                   `asn1:"optional,explicit,tag:2"`
+	RecipKID      []byte                   `asn1:"optional,explicit,tag:3"`
+	TransactionID []byte                   `asn1:"optional,explicit,tag:4"`
+	SenderNonce   []byte                   `asn1:"optional,explicit,tag:5"`
+	RecipNonce    []byte                   `asn1:"optional,explicit,tag:6"`
+	FreeText      asn1.RawValue            `asn1:"optional,explicit,tag:7"`
+	GeneralInfo   []cmpInfoTypeAndValue    `asn1:"optional,explicit,tag:8"`
+}
+
+type cmpInfoTypeAndValue struct {
+	InfoType  asn1.ObjectIdentifier
+	InfoValue asn1.RawValue `asn1:"optional"`
+}
+
+type cmpPBMParameter struct {
+	Salt           []byte
+	Owf            pkix.AlgorithmIdentifier
+	IterationCount int
+	Mac            pkix.AlgorithmIdentifier
+}
+
+type cmpStatusInfo struct {
+	Status       int
+	StatusString []asn1.RawValue `asn1:"optional"`
+	FailInfo     asn1.BitString  `asn1:"optional"`
+}
+
+type cmpErrorMsgContent struct {
+	Status cmpStatusInfo
+}
+
+type cmpCertRequest struct {
+	CertReqID    int
+	CertTemplate asn1.RawValue
+	Controls     asn1.RawValue `asn1:"optional"`
+}
+
+type cmpPOPOSigningKey struct {
+	Input     asn1.RawValue `asn1:"optional,tag:0"`
+	Algorithm pkix.AlgorithmIdentifier
+	Signature asn1.BitString
+}
+
+type cmpCertRepMessage struct {
+	CAPubs   []asn1.RawValue `asn1:"optional,explicit,tag:1"`
+	Response []cmpCertResponse
+}
+
+type cmpCertResponse struct {
+	CertReqID        int
+	Status           cmpStatusInfo
+	CertifiedKeyPair cmpCertifiedKeyPair `asn1:"optional"`
+}
+
+type cmpCertifiedKeyPair struct {
+	CertOrEncCert asn1.RawValue
+}
+
+type cmpCertStatus struct {
+	CertHash   []byte
+	CertReqID  int
+	StatusInfo cmpStatusInfo `asn1:"optional"`
+	HashAlg    asn1.RawValue `asn1:"optional,explicit,tag:0"`
+}
+
+// cmpCertTemplate holds the fields of a CRMF CertTemplate we honor; the
+// remaining fields are controlled by the role.
+type cmpCertTemplate struct {
+	subject    []byte
+	publicKey  []byte
+	extensions []pkix.Extension
+}
+
+func cmpBody(bodyType int, content []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: bodyType, IsCompound: true, Bytes: content}
+}
+
+func cmpSequence(content []byte) ([]byte, error) {
+	return asn1.Marshal(asn1.RawValue{Tag: asn1.TagSequence, IsCompound: true, Bytes: content})
+}
+
+func cmpFreeText(text string) []asn1.RawValue {
+	return []asn1.RawValue{{Tag: asn1.TagUTF8String, Bytes: []byte(text)}}
+}
+
+func cmpFailInfo(bit int) asn1.BitString {
+	bits := make([]byte, bit/8+1)
+	bits[bit/8] = 0x80 >> (bit % 8)
+	return asn1.BitString{Bytes: bits, BitLength: bit + 1}
+}
+
+func cmpProtectedPart(header asn1.RawValue, body asn1.RawValue) ([]byte, error) {
+	return asn1.Marshal(struct {
+		Header asn1.RawValue
+		Body   asn1.RawValue
+	}{header, body})
+}
+
+func (h *cmpHeader) requestsImplicitConfirm() bool {
+	for _, info := range h.GeneralInfo {
+		if info.InfoType.Equal(oidCmpImplicitConfirm) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmpProtection protects outgoing messages, either with a password based MAC
+// or with a signature.
+type cmpProtection struct {
+	alg        pkix.AlgorithmIdentifier
+	senderKID  []byte
+	extraCerts [][]byte
+	protect    func(protectedPart []byte) ([]byte, error)
+}
+
+func newCmpPBMProtection(secret []byte, alg pkix.AlgorithmIdentifier) *cmpProtection {
+	return &cmpProtection{
+		alg: alg,
+		protect: func(protectedPart []byte) ([]byte, error) {
+			return cmpPasswordBasedMac(secret, alg, protectedPart)
+		},
+	}
+}
+
+func newCmpSignatureProtection(signer crypto.Signer, cert *x509.Certificate, extraCerts [][]byte) (*cmpProtection, error) {
+	_, sigAlgo, err := publicKeyType(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, detail := range signatureAlgorithmDetails {
+		if detail.algo != sigAlgo {
+			continue
+		}
+
+		alg := pkix.AlgorithmIdentifier{Algorithm: detail.oid}
+		if detail.pubKeyAlgo == x509.RSA {
+			alg.Parameters = asn1.NullRawValue
+		}
+
+		return &cmpProtection{
+			alg:        alg,
+			senderKID:  cert.SubjectKeyId,
+			extraCerts: extraCerts,
+			protect: func(protectedPart []byte) ([]byte, error) {
+				digest := protectedPart
+				if detail.hash != crypto.Hash(0) {
+					h := detail.hash.New()
+					h.Write(protectedPart)
+					digest = h.Sum(nil)
+				}
+				return signer.Sign(rand.Reader, digest, detail.hash)
+			},
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported signature algorithm %v", sigAlgo)
+}
+
+// marshal completes the header with the protection algorithm and returns the
+// protected DER encoded PKIMessage.
+func (p *cmpProtection) marshal(header *cmpHeader, body asn1.RawValue) ([]byte, error) {
+	header.ProtectionAlg = p.alg
+	if len(p.senderKID) > 0 {
+		header.SenderKID = p.senderKID
+	}
+
+	headerDer, err := asn1.Marshal(*header)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding CMP header: %w", err)
+	}
+	bodyDer, err := asn1.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding CMP body: %w", err)
+	}
+
+	msg := cmpMessage{
+		Header: asn1.RawValue{FullBytes: headerDer},
+		Body:   asn1.RawValue{FullBytes: bodyDer},
+	}
+
+	protectedPart, err := cmpProtectedPart(msg.Header, msg.Body)
+	if err != nil {
+		return nil, err
+	}
+	protection, err := p.protect(protectedPart)
+	if err != nil {
+		return nil, fmt.Errorf("failed protecting CMP message: %w", err)
+	}
+	msg.Protection = asn1.BitString{Bytes: protection, BitLength: len(protection) * 8}
+
+	for _, cert := range p.extraCerts {
+		msg.ExtraCerts = append(msg.ExtraCerts, asn1.RawValue{FullBytes: cert})
+	}
+
+	return asn1.Marshal(msg)
+}
+
+// cmpPasswordBasedMac computes the PasswordBasedMac of RFC 4211 Section 4.4.
+func cmpPasswordBasedMac(secret []byte, alg pkix.AlgorithmIdentifier, data []byte) ([]byte, error) {
+	if !alg.Algorithm.Equal(oidCmpPasswordBasedMac) {
+		return nil, fmt.Errorf("unsupported MAC algorithm %v", alg.Algorithm)
+	}
+
+	var params cmpPBMParameter
+	if rest, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil || len(rest) > 0 {
+		return nil, errors.New("invalid PasswordBasedMac parameters")
+	}
+
+	if params.IterationCount < 1 || params.IterationCount > cmpMaxPBMIterations {
+		return nil, fmt.Errorf("PasswordBasedMac iteration count must be between 1 and %d", cmpMaxPBMIterations)
+	}
+
+	owf, ok := cmpOwfAlgorithms[params.Owf.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PasswordBasedMac one-way function %v", params.Owf.Algorithm)
+	}
+	mac, ok := cmpMacAlgorithms[params.Mac.Algorithm.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported PasswordBasedMac MAC algorithm %v", params.Mac.Algorithm)
+	}
+
+	h := owf.New()
+	h.Write(secret)
+	h.Write(params.Salt)
+	key := h.Sum(nil)
+	for i := 1; i < params.IterationCount; i++ {
+		h.Reset()
+		h.Write(key)
+		key = h.Sum(key[:0])
+	}
+
+	m := hmac.New(func() hash.Hash { return mac.New() }, key)
+	m.Write(data)
+	return m.Sum(nil), nil
+}
+
+// cmpCheckSignature verifies a signature made with the given algorithm.
+// RSASSA-PSS is not supported as its parameters are not parsed.
+func cmpCheckSignature(pub interface{}, alg pkix.AlgorithmIdentifier, signed []byte, signature []byte) error {
+	for _, detail := range signatureAlgorithmDetails {
+		if !detail.oid.Equal(alg.Algorithm) || detail.oid.Equal(oidSignatureRSAPSS) {
+			continue
+		}
+
+		return (&x509.Certificate{PublicKey: pub}).CheckSignature(detail.algo, signed, signature)
+	}
+
+	return fmt.Errorf("unsupported signature algorithm %v", alg.Algorithm)
+}
+
+func parseCmpCertTemplate(raw []byte) (*cmpCertTemplate, error) {
+	var fields []asn1.RawValue
+	if rest, err := asn1.Unmarshal(raw, &fields); err != nil || len(rest) > 0 {
+		return nil, errors.New("malformed certificate template")
+	}
+
+	// An empty RDNSequence, for templates without a subject.
+	template := &cmpCertTemplate{subject: []byte{0x30, 0x00}}
+	for _, field := range fields {
+		if field.Class != asn1.ClassContextSpecific {
+			return nil, errors.New("malformed certificate template")
+		}
+
+		switch field.Tag {
+		case 5:
+			// Name is a CHOICE, so the implicit tag is in effect explicit.
+			template.subject = field.Bytes
+		case 6:
+			spki, err := cmpSequence(field.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			template.publicKey = spki
+		case 9:
+			extensions, err := cmpSequence(field.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			if rest, err := asn1.Unmarshal(extensions, &template.extensions); err != nil || len(rest) > 0 {
+				return nil, errors.New("malformed certificate template extensions")
+			}
+		}
+	}
+
+	if template.publicKey == nil {
+		return nil, errors.New("certificate template has no public key")
+	}
+
+	return template, nil
+}
+
+// certificateRequest converts the template into an unsigned PKCS#10 request,
+// so that it can go through the same issuance path as CSRs. The proof of
+// possession is verified separately, so the signature is left empty.
+func (t *cmpCertTemplate) certificateRequest(alg pkix.AlgorithmIdentifier) (*x509.CertificateRequest, error) {
+	var attributes []asn1.RawValue
+	if len(t.extensions) > 0 {
+		extensions, err := asn1.Marshal(t.extensions)
+		if err != nil {
+			return nil, err
+		}
+
+		attribute, err := asn1.Marshal(struct {
+			Type   asn1.ObjectIdentifier
+			Values []asn1.RawValue `asn1:"set"`
+		}{oidExtensionRequest, []asn1.RawValue{{FullBytes: extensions}}})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, asn1.RawValue{FullBytes: attribute})
+	}
+
+	tbs, err := asn1.Marshal(struct {
+		Version    int
+		Subject    asn1.RawValue
+		PublicKey  asn1.RawValue
+		Attributes []asn1.RawValue `asn1:"tag:0"`
+	}{0, asn1.RawValue{FullBytes: t.subject}, asn1.RawValue{FullBytes: t.publicKey}, attributes})
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := asn1.Marshal(struct {
+		TBS                asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}{TBS: asn1.RawValue{FullBytes: tbs}, SignatureAlgorithm: alg})
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificateRequest(csr)
+}
@@ -34,9 +34,8 @@ func (sc *storageContext) updateDefaultKeyId(id keyID) error {
 	}
 
 	if config.DefaultKeyId != id {
-		return sc.setKeysConfig(&keyConfigEntry{
-			DefaultKeyId: id,
-		})
+		config.DefaultKeyId = id
+		return sc.setKeysConfig(config)
 	}
 
 	return nil
@@ -5,8 +5,15 @@ package pki
 
 import (
 	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"strings"
 	"testing"
@@ -15,10 +22,12 @@ import (
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
 	"github.com/hashicorp/vault/api"
 	vaulthttp "github.com/hashicorp/vault/http"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/vault"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
 )
 
 func TestBackend_CRL_EnableDisableRoot(t *testing.T) {
@@ -732,6 +741,181 @@ func TestPoP(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestPoPSignature(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	for _, keyType := range []string{"rsa", "ec", "ed25519"} {
+		resp, err = CBWrite(b, s, "roles/"+keyType, map[string]interface{}{
+			"allow_any_name": true,
+			"key_type":       keyType,
+		})
+		requireSuccessNonNilResponse(t, resp, err, "roles/"+keyType)
+
+		issue := func() (*x509.Certificate, crypto.Signer) {
+			resp, err := CBWrite(b, s, "issue/"+keyType, map[string]interface{}{
+				"common_name": "testing",
+			})
+			requireSuccessNonNilResponse(t, resp, err, "issue/"+keyType)
+			cert := parseCert(t, resp.Data["certificate"].(string))
+			block, _ := pem.Decode([]byte(resp.Data["private_key"].(string)))
+			key, _, err := certutil.ParseDERKey(block.Bytes)
+			require.NoError(t, err)
+			return cert, key
+		}
+
+		sign := func(cert *x509.Certificate, key crypto.Signer) string {
+			challenge := []byte("revoke-with-key:" + serialFromCert(cert))
+			var signature []byte
+			var err error
+			if keyType == "ed25519" {
+				signature, err = key.Sign(rand.Reader, challenge, crypto.Hash(0))
+			} else {
+				digest := sha256.Sum256(challenge)
+				signature, err = key.Sign(rand.Reader, digest[:], crypto.SHA256)
+			}
+			require.NoError(t, err)
+			return base64.StdEncoding.EncodeToString(signature)
+		}
+
+		cert, key := issue()
+		otherCert, otherKey := issue()
+
+		// Signatures for another certificate, or by another key, are refused.
+		_, err = CBWrite(b, s, "revoke-with-key", map[string]interface{}{
+			"serial_number": serialFromCert(cert),
+			"signature":     sign(otherCert, key),
+		})
+		require.ErrorContains(t, err, "provided signature does not verify")
+		_, err = CBWrite(b, s, "revoke-with-key", map[string]interface{}{
+			"serial_number": serialFromCert(cert),
+			"signature":     sign(cert, otherKey),
+		})
+		require.ErrorContains(t, err, "provided signature does not verify")
+
+		_, err = CBWrite(b, s, "revoke-with-key", map[string]interface{}{
+			"serial_number": serialFromCert(cert),
+			"signature":     sign(cert, key),
+			"private_key":   "irrelevant",
+		})
+		require.ErrorContains(t, err, "not both")
+
+		resp, err = CBWrite(b, s, "revoke-with-key", map[string]interface{}{
+			"serial_number": serialFromCert(cert),
+			"signature":     sign(cert, key),
+		})
+		requireSuccessNonNilResponse(t, resp, err, "revoke-with-key")
+		require.NotEmpty(t, resp.Data["revocation_time"])
+
+		resp, err = CBWrite(b, s, "revoke-with-key", map[string]interface{}{
+			"certificate": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: otherCert.Raw})),
+			"signature":   sign(otherCert, otherKey),
+		})
+		requireSuccessNonNilResponse(t, resp, err, "revoke-with-key")
+		require.NotEmpty(t, resp.Data["revocation_time"])
+	}
+}
+
+func TestRevocationReason(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	issuer := parseCert(t, resp.Data["certificate"].(string))
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	issue := func() *x509.Certificate {
+		resp, err := CBWrite(b, s, "issue/test", map[string]interface{}{
+			"common_name": "testing",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/test")
+		return parseCert(t, resp.Data["certificate"].(string))
+	}
+
+	compromised := issue()
+	superseded := issue()
+	unspecified := issue()
+
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number":     serialFromCert(compromised),
+		"revocation_reason": "certificateHold",
+	})
+	require.ErrorContains(t, err, "unsupported revocation_reason")
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number":     serialFromCert(compromised),
+		"revocation_reason": "keycompromise",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("revoke"), logical.UpdateOperation), resp, true)
+	require.Equal(t, "keyCompromise", resp.Data["revocation_reason"])
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number":     serialFromCert(superseded),
+		"revocation_reason": "4",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+	require.Equal(t, "superseded", resp.Data["revocation_reason"])
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(unspecified),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+	require.Equal(t, "unspecified", resp.Data["revocation_reason"])
+
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(compromised))
+	requireSuccessNonNilResponse(t, resp, err, "cert")
+	require.Equal(t, "keyCompromise", resp.Data["revocation_reason"])
+
+	// Unspecified revocations carry no reasonCode extension.
+	expected := map[string][]byte{
+		serialFromCert(compromised): {0x0a, 0x01, 0x01},
+		serialFromCert(superseded):  {0x0a, 0x01, 0x04},
+		serialFromCert(unspecified): nil,
+	}
+	crl := getParsedCrlFromBackend(t, b, s, "crl")
+	require.Len(t, crl.TBSCertList.RevokedCertificates, len(expected))
+	for _, revoked := range crl.TBSCertList.RevokedCertificates {
+		var reasonCode []byte
+		for _, ext := range revoked.Extensions {
+			if ext.Id.Equal(oidExtensionReasonCode) {
+				reasonCode = ext.Value
+			}
+		}
+		require.Equal(t, expected[serialFromBigInt(revoked.SerialNumber)], reasonCode)
+	}
+
+	for cert, reason := range map[*x509.Certificate]int{
+		compromised: ocsp.KeyCompromise,
+		superseded:  ocsp.Superseded,
+		unspecified: ocsp.Unspecified,
+	} {
+		resp, err = SendOcspRequest(t, b, s, "get", cert, issuer, crypto.SHA256)
+		requireSuccessNonNilResponse(t, resp, err, "ocsp")
+		ocspResp, err := ocsp.ParseResponse(resp.Data["http_raw_body"].([]byte), issuer)
+		require.NoError(t, err)
+		require.Equal(t, ocsp.Revoked, ocspResp.Status)
+		require.Equal(t, reason, ocspResp.RevocationReason)
+	}
+}
+
 func TestIssuerRevocation(t *testing.T) {
 	t.Parallel()
 
@@ -901,6 +1085,89 @@ func TestIssuerRevocation(t *testing.T) {
 	require.NotEmpty(t, resp.Data["revocation_time"])
 }
 
+func TestFreshestCRLExtension(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	_, err := CBWrite(b, s, "config/crl", map[string]interface{}{
+		"auto_rebuild": true,
+		"enable_delta": true,
+	})
+	require.NoError(t, err)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"issuer_name": "root",
+		"key_type":    "ec",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	findFreshestCRLExt := func(path string) *pkix.Extension {
+		crl := getParsedCrlFromBackend(t, b, s, path)
+		for _, ext := range crl.TBSCertList.Extensions {
+			if ext.Id.Equal(certutil.FreshestCRLOID) {
+				return &ext
+			}
+		}
+		return nil
+	}
+	requireFreshestCRL := func(url string) {
+		_, err := CBRead(b, s, "crl/rotate")
+		require.NoError(t, err)
+		_, err = CBRead(b, s, "crl/rotate-delta")
+		require.NoError(t, err)
+
+		ext := findFreshestCRLExt("issuer/root/crl/der")
+		if url == "" {
+			require.Nil(t, ext, "expected no freshest CRL extension on the complete CRL")
+			return
+		}
+
+		require.NotNil(t, ext, "expected a freshest CRL extension on the complete CRL")
+		require.False(t, ext.Critical)
+		expected, err := certutil.CreateFreshestCRLExt([]string{url})
+		require.NoError(t, err)
+		require.Equal(t, expected.Value, ext.Value)
+
+		require.Nil(t, findFreshestCRLExt("issuer/root/crl/delta/der"), "expected no freshest CRL extension on the delta CRL")
+	}
+
+	// Without delta CRL distribution points, nothing is advertised.
+	requireFreshestCRL("")
+
+	// The global AIA configuration applies to all issuers.
+	resp, err = CBWrite(b, s, "config/urls", map[string]interface{}{
+		"delta_crl_distribution_points": "http://localhost/v1/pki/crl/delta",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://localhost/v1/pki/crl/delta"}, resp.Data["delta_crl_distribution_points"])
+	requireFreshestCRL("http://localhost/v1/pki/crl/delta")
+
+	_, err = CBWrite(b, s, "config/urls", map[string]interface{}{
+		"delta_crl_distribution_points": "not a url",
+	})
+	require.Error(t, err)
+
+	// Per-issuer AIA configuration takes precedence.
+	resp, err = CBPatch(b, s, "issuer/root", map[string]interface{}{
+		"delta_crl_distribution_points": "http://localhost/v1/pki/issuer/root/crl/delta/der",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://localhost/v1/pki/issuer/root/crl/delta/der"}, resp.Data["delta_crl_distribution_points"])
+	requireFreshestCRL("http://localhost/v1/pki/issuer/root/crl/delta/der")
+
+	// Disabling delta CRLs removes the extension again.
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"enable_delta": false,
+	})
+	require.NoError(t, err)
+	_, err = CBRead(b, s, "crl/rotate")
+	require.NoError(t, err)
+	require.Nil(t, findFreshestCRLExt("issuer/root/crl/der"))
+}
+
 func TestAutoRebuild(t *testing.T) {
 	t.Parallel()
 
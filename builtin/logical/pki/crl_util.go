@@ -42,10 +42,25 @@ type revocationInfo struct {
 	RevocationTime    int64     `json:"revocation_time"`
 	RevocationTimeUTC time.Time `json:"revocation_time_utc"`
 	CertificateIssuer issuerID  `json:"issuer_id"`
+	RevocationReason  int       `json:"revocation_reason,omitempty"`
+
+	// SerialNumber and CertExpiration stand in for CertificateBytes on
+	// revocations imported from an external CRL, of certificates this mount
+	// doesn't store. As CRLs don't carry the expiration of the certificates
+	// they list, that of their issuer is used instead.
+	SerialNumber   string    `json:"serial_number,omitempty"`
+	CertExpiration time.Time `json:"certificate_expiration_utc"`
+}
+
+// isImported reports whether this revocation was imported from an external
+// CRL, without the revoked certificate itself.
+func (r *revocationInfo) isImported() bool {
+	return len(r.CertificateBytes) == 0 && r.SerialNumber != ""
 }
 
 type revocationRequest struct {
-	RequestedAt time.Time `json:"requested_at"`
+	RequestedAt      time.Time `json:"requested_at"`
+	RevocationReason int       `json:"revocation_reason,omitempty"`
 }
 
 type revocationConfirmed struct {
@@ -774,7 +789,12 @@ func (cb *crlBuilder) processRevocationQueue(sc *storageContext) error {
 			continue
 		}
 
-		resp, err := tryRevokeCertBySerial(sc, crlConfig, req.Serial)
+		var revRequest revocationRequest
+		if err := entry.DecodeJSON(&revRequest); err != nil {
+			return fmt.Errorf("failed to decode cross-cluster revocation queue entry: %w", err)
+		}
+
+		resp, err := tryRevokeCertBySerial(sc, crlConfig, req.Serial, revRequest.RevocationReason)
 		if err == nil && resp != nil && !resp.IsError() && resp.Data != nil && resp.Data["state"].(string) == "revoked" {
 			if isNotPerfPrimary {
 				// Write a revocation queue removal entry.
@@ -888,7 +908,12 @@ func (cb *crlBuilder) processCrossClusterRevocations(sc *storageContext) error {
 			continue
 		}
 
-		resp, err := tryRevokeCertBySerial(sc, crlConfig, req.Serial)
+		var revEntry unifiedRevocationEntry
+		if err := entry.DecodeJSON(&revEntry); err != nil {
+			return fmt.Errorf("failed to decode unified revocation entry: %w", err)
+		}
+
+		resp, err := tryRevokeCertBySerial(sc, crlConfig, req.Serial, revEntry.RevocationReason)
 		if err == nil && resp != nil && !resp.IsError() && resp.Data != nil && resp.Data["state"].(string) == "revoked" {
 			// We could theoretically save ourselves from writing a global
 			// revocation entry during the above certificate revocation, as
@@ -952,7 +977,7 @@ func fetchIssuerMapForRevocationChecking(sc *storageContext) (map[issuerID]*x509
 
 // Revoke a certificate from a given serial number if it is present in local
 // storage.
-func tryRevokeCertBySerial(sc *storageContext, config *crlConfig, serial string) (*logical.Response, error) {
+func tryRevokeCertBySerial(sc *storageContext, config *crlConfig, serial string, reason int) (*logical.Response, error) {
 	// revokeCert requires us to hold these locks before calling it.
 	sc.Backend.revokeStorageLock.Lock()
 	defer sc.Backend.revokeStorageLock.Unlock()
@@ -976,18 +1001,32 @@ func tryRevokeCertBySerial(sc *storageContext, config *crlConfig, serial string)
 		return nil, fmt.Errorf("error parsing certificate: %w", err)
 	}
 
-	return revokeCert(sc, config, cert)
+	// Requests from the cross-cluster revocation queue aren't attributed to
+	// an entity on this cluster.
+	return revokeCert(sc, config, cert, "", reason)
 }
 
 // Revokes a cert, and tries to be smart about error recovery
-func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (*logical.Response, error) {
+func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate, entityId string, reason int) (*logical.Response, error) {
+	resp, revoked, err := revokeCertWithoutRebuild(sc, config, cert, entityId, reason)
+	if err != nil || !revoked {
+		return resp, err
+	}
+
+	return rebuildCRLAfterRevocation(sc, config, resp)
+}
+
+// revokeCertWithoutRebuild revokes a cert like revokeCert, but leaves
+// rebuilding the CRLs, when auto-rebuild is disabled, to the caller: this
+// is needed when it returns true, for a newly revoked certificate.
+func revokeCertWithoutRebuild(sc *storageContext, config *crlConfig, cert *x509.Certificate, entityId string, reason int) (*logical.Response, bool, error) {
 	// As this backend is self-contained and this function does not hook into
 	// third parties to manage users or resources, if the mount is tainted,
 	// revocation doesn't matter anyways -- the CRL that would be written will
 	// be immediately blown away by the view being cleared. So we can simply
 	// fast path a successful exit.
 	if sc.Backend.System().Tainted() {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	colonSerial := serialFromCert(cert)
@@ -999,33 +1038,34 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 	// handle revoking certs.
 	issuerIDCertMap, err := fetchIssuerMapForRevocationChecking(sc)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// Ensure we don't revoke an issuer via this API; use /issuer/:issuer_ref/revoke
 	// instead.
 	for issuer, certificate := range issuerIDCertMap {
 		if colonSerial == serialFromCert(certificate) {
-			return logical.ErrorResponse(fmt.Sprintf("adding issuer (id: %v) to its own CRL is not allowed", issuer)), nil
+			return logical.ErrorResponse(fmt.Sprintf("adding issuer (id: %v) to its own CRL is not allowed", issuer)), false, nil
 		}
 	}
 
 	curRevInfo, err := sc.fetchRevocationInfo(colonSerial)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 	if curRevInfo != nil {
 		resp := &logical.Response{
 			Data: map[string]interface{}{
-				"revocation_time": curRevInfo.RevocationTime,
-				"state":           "revoked",
+				"revocation_time":   curRevInfo.RevocationTime,
+				"revocation_reason": revocationReasonName(curRevInfo.RevocationReason),
+				"state":             "revoked",
 			},
 		}
 		if !curRevInfo.RevocationTimeUTC.IsZero() {
 			resp.Data["revocation_time_rfc3339"] = curRevInfo.RevocationTimeUTC.Format(time.RFC3339Nano)
 		}
 
-		return resp, nil
+		return resp, false, nil
 	}
 
 	// Add a little wiggle room because leases are stored with a second
@@ -1033,7 +1073,7 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 	if cert.NotAfter.Before(time.Now().Add(2 * time.Second)) {
 		response := &logical.Response{}
 		response.AddWarning(fmt.Sprintf("certificate with serial %s already expired; refusing to add to CRL", colonSerial))
-		return response, nil
+		return response, false, nil
 	}
 
 	currTime := time.Now()
@@ -1041,6 +1081,7 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 		CertificateBytes:  cert.Raw,
 		RevocationTime:    currTime.Unix(),
 		RevocationTimeUTC: currTime.UTC(),
+		RevocationReason:  reason,
 	}
 
 	// We may not find an issuer with this certificate; that's fine so
@@ -1049,15 +1090,25 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 
 	revEntry, err := logical.StorageEntryJSON(revokedPath+hyphenSerial, revInfo)
 	if err != nil {
-		return nil, fmt.Errorf("error creating revocation entry: %w", err)
+		return nil, false, fmt.Errorf("error creating revocation entry: %w", err)
 	}
 
 	certsCounted := sc.Backend.certsCounted.Load()
 	err = sc.Storage.Put(sc.Context, revEntry)
 	if err != nil {
-		return nil, fmt.Errorf("error saving revoked certificate to new location: %w", err)
+		return nil, false, fmt.Errorf("error saving revoked certificate to new location: %w", err)
 	}
 	sc.Backend.ifCountEnabledIncrementTotalRevokedCertificatesCount(certsCounted, revEntry.Key)
+	sc.Backend.ocspCache.purge()
+	sc.Backend.sendCertEvent(sc.Context, eventTypeCertRevoke, cert, "", revInfo.CertificateIssuer, entityId)
+
+	// The certificate is revoked regardless of whether its role, recorded
+	// in its metadata, can be looked up for counting.
+	var revokedRole string
+	if metadata, err := sc.fetchCertMetadata(colonSerial); err == nil && metadata != nil {
+		revokedRole = metadata.Role
+	}
+	sc.Backend.countRevokedCertificate(revokedRole)
 
 	// From here on out, the certificate has been revoked locally. Any other
 	// persistence issues might still err, but any other failure messages
@@ -1066,6 +1117,7 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 		Data: map[string]interface{}{
 			"revocation_time":         revInfo.RevocationTime,
 			"revocation_time_rfc3339": revInfo.RevocationTimeUTC.Format(time.RFC3339Nano),
+			"revocation_reason":       revocationReasonName(revInfo.RevocationReason),
 			"state":                   "revoked",
 		},
 	}
@@ -1079,6 +1131,7 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 			CertExpiration:    cert.NotAfter,
 			RevocationTimeUTC: revInfo.RevocationTimeUTC,
 			CertificateIssuer: revInfo.CertificateIssuer,
+			RevocationReason:  revInfo.RevocationReason,
 		}
 
 		ignoreErr := writeUnifiedRevocationEntry(sc, entry)
@@ -1094,28 +1147,37 @@ func revokeCert(sc *storageContext, config *crlConfig, cert *x509.Certificate) (
 		}
 	}
 
-	if !config.AutoRebuild {
-		// Note that writing the Delta WAL here isn't necessary; we've
-		// already rebuilt the full CRL so the Delta WAL will be cleared
-		// afterwards. Writing an entry only to immediately remove it
-		// isn't necessary.
-		warnings, crlErr := sc.Backend.crlBuilder.rebuild(sc, false)
-		if crlErr != nil {
-			switch crlErr.(type) {
-			case errutil.UserError:
-				return logical.ErrorResponse(fmt.Sprintf("Error during CRL building: %s", crlErr)), nil
-			default:
-				return nil, fmt.Errorf("error encountered during CRL building: %w", crlErr)
-			}
-		}
-		for index, warning := range warnings {
-			resp.AddWarning(fmt.Sprintf("Warning %d during CRL rebuild: %v", index+1, warning))
-		}
-	} else if config.EnableDelta {
+	if config.AutoRebuild && config.EnableDelta {
+		// Without auto-rebuild, the caller rebuilds the full CRL, clearing
+		// the Delta WAL afterwards; writing an entry only to immediately
+		// remove it isn't necessary.
 		if err := writeRevocationDeltaWALs(sc, config, resp, failedWritingUnifiedCRL, hyphenSerial, colonSerial); err != nil {
-			return nil, fmt.Errorf("failed to write WAL entries for Delta CRLs: %w", err)
+			return nil, false, fmt.Errorf("failed to write WAL entries for Delta CRLs: %w", err)
+		}
+	}
+
+	return resp, true, nil
+}
+
+// rebuildCRLAfterRevocation rebuilds the CRLs after revocations when
+// auto-rebuild is disabled, adding any warnings to the given response.
+func rebuildCRLAfterRevocation(sc *storageContext, config *crlConfig, resp *logical.Response) (*logical.Response, error) {
+	if config.AutoRebuild {
+		return resp, nil
+	}
+
+	warnings, crlErr := sc.Backend.crlBuilder.rebuild(sc, false)
+	if crlErr != nil {
+		switch crlErr.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(fmt.Sprintf("Error during CRL building: %s", crlErr)), nil
+		default:
+			return nil, fmt.Errorf("error encountered during CRL building: %w", crlErr)
 		}
 	}
+	for index, warning := range warnings {
+		resp.AddWarning(fmt.Sprintf("Warning %d during CRL rebuild: %v", index+1, warning))
+	}
 
 	return resp, nil
 }
@@ -1937,6 +1999,26 @@ func getLocalRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID
 			return nil, nil, errutil.InternalError{Err: fmt.Sprintf("error decoding revocation entry for serial %s: %s", serial, err)}
 		}
 
+		if revInfo.isImported() {
+			// Imported revocations were assigned their issuer on import
+			// and, lacking the certificate, can't be reassigned.
+			serialNumber, ok := serialToBigInt(revInfo.SerialNumber)
+			if !ok {
+				return nil, nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse imported revoked serial %s", serial)}
+			}
+			newRevCert, err := newRevokedCertificate(serialNumber, &revInfo)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if isRevInfoIssuerValid(&revInfo, issuerIDCertMap) {
+				revokedCertsMap[revInfo.CertificateIssuer] = append(revokedCertsMap[revInfo.CertificateIssuer], newRevCert)
+			} else {
+				unassignedCerts = append(unassignedCerts, newRevCert)
+			}
+			continue
+		}
+
 		revokedCert, err := x509.ParseCertificate(revInfo.CertificateBytes)
 		if err != nil {
 			return nil, nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse stored revoked certificate with serial %s: %s", serial, err)}
@@ -1970,15 +2052,9 @@ func getLocalRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID
 			}
 		}
 
-		// NOTE: We have to change this to UTC time because the CRL standard
-		// mandates it but Go will happily encode the CRL without this.
-		newRevCert := pkix.RevokedCertificate{
-			SerialNumber: revokedCert.SerialNumber,
-		}
-		if !revInfo.RevocationTimeUTC.IsZero() {
-			newRevCert.RevocationTime = revInfo.RevocationTimeUTC
-		} else {
-			newRevCert.RevocationTime = time.Unix(revInfo.RevocationTime, 0).UTC()
+		newRevCert, err := newRevokedCertificate(revokedCert.SerialNumber, &revInfo)
+		if err != nil {
+			return nil, nil, err
 		}
 
 		// If we have a CertificateIssuer field on the revocation entry,
@@ -2018,6 +2094,24 @@ func getLocalRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID
 	return unassignedCerts, revokedCertsMap, nil
 }
 
+// newRevokedCertificate builds the CRL entry of a revoked certificate.
+func newRevokedCertificate(serialNumber *big.Int, revInfo *revocationInfo) (pkix.RevokedCertificate, error) {
+	// NOTE: We have to change this to UTC time because the CRL standard
+	// mandates it but Go will happily encode the CRL without this.
+	newRevCert := pkix.RevokedCertificate{
+		SerialNumber: serialNumber,
+	}
+	if !revInfo.RevocationTimeUTC.IsZero() {
+		newRevCert.RevocationTime = revInfo.RevocationTimeUTC
+	} else {
+		newRevCert.RevocationTime = time.Unix(revInfo.RevocationTime, 0).UTC()
+	}
+
+	var err error
+	newRevCert.Extensions, err = revocationReasonExtensions(revInfo.RevocationReason)
+	return newRevCert, err
+}
+
 func getUnifiedRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuerID]*x509.Certificate, isDelta bool) ([]pkix.RevokedCertificate, map[issuerID][]pkix.RevokedCertificate, error) {
 	// Getting unified revocation entries is a bit different than getting
 	// the local ones. In particular, the full copy of the certificate is
@@ -2094,6 +2188,10 @@ func getUnifiedRevokedCertEntries(sc *storageContext, issuerIDCertMap map[issuer
 			}
 
 			revEntry.RevocationTime = xRevEntry.RevocationTimeUTC
+			revEntry.Extensions, err = revocationReasonExtensions(xRevEntry.RevocationReason)
+			if err != nil {
+				return nil, nil, err
+			}
 
 			if found, inFoundMap := foundSerials[normalizeSerial(serial)]; found && inFoundMap {
 				// Serial has already been added to the CRL.
@@ -2148,6 +2246,32 @@ func augmentWithRevokedIssuers(issuerIDEntryMap map[issuerID]*issuerEntry, issue
 	return nil
 }
 
+// buildFreshestCRLExt returns the Freshest CRL extension pointing complete
+// CRLs of this issuer at its delta CRL, or nil if no delta CRL distribution
+// points are configured.
+func buildFreshestCRLExt(sc *storageContext, thisIssuerId issuerID) (*pkix.Extension, error) {
+	issuer, err := sc.fetchIssuerById(thisIssuerId)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error fetching issuer %v: %v", thisIssuerId, err)}
+	}
+
+	urls, err := issuer.GetAIAURLs(sc)
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error fetching AIA URLs of issuer %v: %v", thisIssuerId, err)}
+	}
+
+	if len(urls.DeltaCRLDistributionPoints) == 0 {
+		return nil, nil
+	}
+
+	ext, err := certutil.CreateFreshestCRLExt(urls.DeltaCRLDistributionPoints)
+	if err != nil {
+		return nil, fmt.Errorf("could not create freshest crl extension: %w", err)
+	}
+
+	return &ext, nil
+}
+
 // Builds a CRL by going through the list of revoked certificates and building
 // a new CRL with the stored revocation times and serial numbers.
 func buildCRL(sc *storageContext, crlInfo *crlConfig, forceNew bool, thisIssuerId issuerID, revoked []pkix.RevokedCertificate, identifier crlID, crlNumber int64, isUnified bool, isDelta bool, lastCompleteNumber int64) (*time.Time, error) {
@@ -2177,7 +2301,7 @@ func buildCRL(sc *storageContext, crlInfo *crlConfig, forceNew bool, thisIssuerI
 	revokedCerts = revoked
 
 WRITE:
-	signingBundle, caErr := sc.fetchCAInfoByIssuerId(thisIssuerId, CRLSigningUsage)
+	signingBundle, caErr := sc.fetchCRLSigningBundle(thisIssuerId)
 	if caErr != nil {
 		switch caErr.(type) {
 		case errutil.UserError:
@@ -2197,6 +2321,14 @@ WRITE:
 			return nil, fmt.Errorf("could not create crl delta indicator extension: %w", err)
 		}
 		extensions = []pkix.Extension{ext}
+	} else if crlInfo.EnableDelta && !isUnified && thisIssuerId != legacyBundleShimID {
+		ext, err := buildFreshestCRLExt(sc, thisIssuerId)
+		if err != nil {
+			return nil, err
+		}
+		if ext != nil {
+			extensions = []pkix.Extension{*ext}
+		}
 	}
 
 	revocationListTemplate := &x509.RevocationList{
@@ -0,0 +1,233 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// Certificate Transparency (RFC 6962) submission: roles with CT logs
+// configured issue a precertificate carrying the critical poison extension,
+// submit it to each log and embed the returned SCTs in the final
+// certificate, which is otherwise identical to the precertificate.
+var (
+	ctPoisonOID  = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 3}
+	ctSCTListOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+)
+
+const (
+	ctSubmissionTimeout = 30 * time.Second
+	ctMaxResponseSize   = 64 * 1024
+)
+
+// ctAddChainResponse is a log's response to add-pre-chain, per RFC 6962
+// Section 4.1.
+type ctAddChainResponse struct {
+	SCTVersion uint8  `json:"sct_version"`
+	ID         string `json:"id"`
+	Timestamp  uint64 `json:"timestamp"`
+	Extensions string `json:"extensions"`
+	Signature  string `json:"signature"`
+}
+
+func validateCTLogURLs(logURLs []string) error {
+	for _, logURL := range logURLs {
+		parsed, err := url.Parse(logURL)
+		if err != nil {
+			return fmt.Errorf("invalid CT log url %q: %w", logURL, err)
+		}
+		if (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return fmt.Errorf("invalid CT log url %q: must be an absolute http or https url", logURL)
+		}
+	}
+
+	return nil
+}
+
+// embedSCTs submits a precertificate of the issued certificate to each of
+// the given CT logs, replacing the certificate in parsedBundle with one
+// embedding the logs' SCTs. The certificate issued without SCTs is
+// discarded.
+func embedSCTs(ctx context.Context, caSign *certutil.CAInfoBundle, parsedBundle *certutil.ParsedCertBundle, logURLs []string, randReader io.Reader) error {
+	cert := parsedBundle.Certificate
+
+	precert, err := reissueWithExtension(cert, caSign, pkix.Extension{
+		Id:       ctPoisonOID,
+		Critical: true,
+		Value:    asn1.NullBytes,
+	}, randReader)
+	if err != nil {
+		return fmt.Errorf("error creating precertificate: %w", err)
+	}
+
+	chain := []string{
+		base64.StdEncoding.EncodeToString(precert),
+		base64.StdEncoding.EncodeToString(caSign.Certificate.Raw),
+	}
+	for _, block := range caSign.CAChain {
+		if block.Certificate != nil && !block.Certificate.Equal(caSign.Certificate) {
+			chain = append(chain, base64.StdEncoding.EncodeToString(block.Certificate.Raw))
+		}
+	}
+
+	var scts [][]byte
+	for _, logURL := range logURLs {
+		sct, err := submitPrecertificate(ctx, logURL, chain)
+		if err != nil {
+			return fmt.Errorf("error submitting precertificate to CT log %v: %w", logURL, err)
+		}
+		scts = append(scts, sct)
+	}
+
+	sctList, err := marshalSCTList(scts)
+	if err != nil {
+		return fmt.Errorf("error encoding SCTs: %w", err)
+	}
+
+	final, err := reissueWithExtension(cert, caSign, pkix.Extension{
+		Id:    ctSCTListOID,
+		Value: sctList,
+	}, randReader)
+	if err != nil {
+		return fmt.Errorf("error creating certificate with SCTs: %w", err)
+	}
+
+	finalCert, err := x509.ParseCertificate(final)
+	if err != nil {
+		return fmt.Errorf("error parsing certificate with SCTs: %w", err)
+	}
+
+	parsedBundle.CertificateBytes = final
+	parsedBundle.Certificate = finalCert
+	return nil
+}
+
+// reissueWithExtension signs a copy of cert with the given extension
+// appended. All of cert's extensions are passed through verbatim and in
+// order, so that the TBSCertificate of the copy only differs from cert by
+// the added extension, as RFC 6962 requires of precertificates and the
+// final certificate.
+func reissueWithExtension(cert *x509.Certificate, caSign *certutil.CAInfoBundle, ext pkix.Extension, randReader io.Reader) ([]byte, error) {
+	template := *cert
+	template.ExtraExtensions = append(append([]pkix.Extension{}, cert.Extensions...), ext)
+
+	return x509.CreateCertificate(randReader, &template, caSign.Certificate, cert.PublicKey, caSign.PrivateKey)
+}
+
+func submitPrecertificate(ctx context.Context, logURL string, chain []string) ([]byte, error) {
+	body, err := json.Marshal(map[string][]string{"chain": chain})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ctSubmissionTimeout)
+	defer cancel()
+
+	endpoint := strings.TrimSuffix(logURL, "/") + "/ct/v1/add-pre-chain"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := cleanhttp.DefaultClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, ctMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status %v: %v", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	var sct ctAddChainResponse
+	if err := json.Unmarshal(respBody, &sct); err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return marshalSCT(sct)
+}
+
+// marshalSCT encodes a log's response as a SignedCertificateTimestamp, per
+// RFC 6962 Section 3.2.
+func marshalSCT(sct ctAddChainResponse) ([]byte, error) {
+	if sct.SCTVersion != 0 {
+		return nil, fmt.Errorf("unsupported SCT version %v", sct.SCTVersion)
+	}
+
+	logID, err := base64.StdEncoding.DecodeString(sct.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding log id: %w", err)
+	}
+	if len(logID) != 32 {
+		return nil, fmt.Errorf("log id must be 32 bytes, got %v", len(logID))
+	}
+
+	extensions, err := base64.StdEncoding.DecodeString(sct.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding extensions: %w", err)
+	}
+
+	// The signature is already a TLS encoded digitally-signed struct.
+	signature, err := base64.StdEncoding.DecodeString(sct.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding signature: %w", err)
+	}
+	if len(signature) < 4 {
+		return nil, errors.New("signature is too short")
+	}
+
+	var b cryptobyte.Builder
+	b.AddUint8(sct.SCTVersion)
+	b.AddBytes(logID)
+	b.AddUint64(sct.Timestamp)
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddBytes(extensions)
+	})
+	b.AddBytes(signature)
+
+	return b.Bytes()
+}
+
+// marshalSCTList encodes SCTs as the value of the embedded SCT list
+// extension: a SignedCertificateTimestampList in an OCTET STRING.
+func marshalSCTList(scts [][]byte) ([]byte, error) {
+	var b cryptobyte.Builder
+	b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+		for _, sct := range scts {
+			b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+				b.AddBytes(sct)
+			})
+		}
+	})
+
+	list, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return asn1.Marshal(list)
+}
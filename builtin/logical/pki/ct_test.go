@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// fakeCTLog is a minimal RFC 6962 log accepting precertificate chains.
+type fakeCTLog struct {
+	t      *testing.T
+	logID  []byte
+	failed bool
+
+	lock   sync.Mutex
+	chains [][]*x509.Certificate
+}
+
+func (l *fakeCTLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	require.Equal(l.t, "/ct/v1/add-pre-chain", r.URL.Path)
+	require.Equal(l.t, http.MethodPost, r.Method)
+
+	if l.failed {
+		http.Error(w, "log is read only", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		Chain []string `json:"chain"`
+	}
+	require.NoError(l.t, json.NewDecoder(r.Body).Decode(&req))
+
+	var chain []*x509.Certificate
+	for _, encoded := range req.Chain {
+		der, err := base64.StdEncoding.DecodeString(encoded)
+		require.NoError(l.t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(l.t, err)
+		chain = append(chain, cert)
+	}
+
+	l.lock.Lock()
+	l.chains = append(l.chains, chain)
+	l.lock.Unlock()
+
+	require.NoError(l.t, json.NewEncoder(w).Encode(ctAddChainResponse{
+		ID:        base64.StdEncoding.EncodeToString(l.logID),
+		Timestamp: 1700000000000,
+		// SHA-256 with ECDSA, followed by a (fake) 4 byte signature.
+		Signature: base64.StdEncoding.EncodeToString([]byte{4, 3, 0, 4, 1, 2, 3, 4}),
+	}))
+}
+
+func TestPki_RoleCTLogSubmission(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	firstLog := &fakeCTLog{t: t, logID: bytes.Repeat([]byte{1}, 32)}
+	firstServer := httptest.NewServer(firstLog)
+	defer firstServer.Close()
+	secondLog := &fakeCTLog{t: t, logID: bytes.Repeat([]byte{2}, 32)}
+	secondServer := httptest.NewServer(secondLog)
+	defer secondServer.Close()
+
+	_, err = CBWrite(b, s, "roles/ct", map[string]interface{}{
+		"allow_any_name": true,
+		"ct_log_urls":    "ftp://ct.example.com",
+	})
+	require.ErrorContains(t, err, "must be an absolute http or https url")
+
+	resp, err = CBWrite(b, s, "roles/ct", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"ct_log_urls":    []string{firstServer.URL, secondServer.URL + "/"},
+		"custom_extensions": []map[string]interface{}{
+			{"oid": "1.3.6.1.4.1.99999.1", "template": "custom"},
+		},
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/ct")
+	require.Equal(t, []string{firstServer.URL, secondServer.URL + "/"}, resp.Data["ct_log_urls"])
+
+	resp, err = CBWrite(b, s, "issue/ct", map[string]interface{}{
+		"common_name": "www.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/ct")
+	cert := parseCert(t, resp.Data["certificate"].(string))
+
+	// Both logs saw the same precertificate, chained to the issuer.
+	require.Len(t, firstLog.chains, 1)
+	require.Len(t, secondLog.chains, 1)
+	precert := firstLog.chains[0][0]
+	require.Equal(t, precert.Raw, secondLog.chains[0][0].Raw)
+	require.Len(t, firstLog.chains[0], 2)
+	require.Equal(t, rootCert.Raw, firstLog.chains[0][1].Raw)
+	require.NoError(t, precert.CheckSignatureFrom(rootCert))
+
+	// The final certificate is the precertificate with the poison
+	// extension replaced by the SCT list.
+	require.Equal(t, precert.SerialNumber, cert.SerialNumber)
+	require.NoError(t, cert.CheckSignatureFrom(rootCert))
+	require.Equal(t, resp.Data["serial_number"], serialFromCert(cert))
+
+	precertExts := precert.Extensions
+	require.True(t, precertExts[len(precertExts)-1].Id.Equal(ctPoisonOID))
+	require.True(t, precertExts[len(precertExts)-1].Critical)
+	certExts := cert.Extensions
+	require.True(t, certExts[len(certExts)-1].Id.Equal(ctSCTListOID))
+	require.False(t, certExts[len(certExts)-1].Critical)
+	require.Equal(t, precertExts[:len(precertExts)-1], certExts[:len(certExts)-1])
+
+	var sctList []byte
+	rest, err := asn1.Unmarshal(certExts[len(certExts)-1].Value, &sctList)
+	require.NoError(t, err)
+	require.Empty(t, rest)
+
+	list := cryptobyte.String(sctList)
+	var scts cryptobyte.String
+	require.True(t, list.ReadUint16LengthPrefixed(&scts))
+	require.True(t, list.Empty())
+	for _, expectedLogID := range [][]byte{firstLog.logID, secondLog.logID} {
+		var sct cryptobyte.String
+		require.True(t, scts.ReadUint16LengthPrefixed(&sct))
+
+		var version uint8
+		var logID []byte
+		var timestamp uint64
+		var extensions cryptobyte.String
+		require.True(t, sct.ReadUint8(&version))
+		require.True(t, sct.ReadBytes(&logID, 32))
+		require.True(t, sct.ReadUint64(&timestamp))
+		require.True(t, sct.ReadUint16LengthPrefixed(&extensions))
+		require.Equal(t, uint8(0), version)
+		require.Equal(t, expectedLogID, logID)
+		require.Equal(t, uint64(1700000000000), timestamp)
+		require.Empty(t, extensions)
+		require.Equal(t, []byte{4, 3, 0, 4, 1, 2, 3, 4}, []byte(sct))
+	}
+	require.True(t, scts.Empty())
+
+	// The stored certificate is the one with SCTs.
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(cert))
+	requireSuccessNonNilResponse(t, resp, err, "cert")
+	require.Equal(t, cert.Raw, parseCert(t, resp.Data["certificate"].(string)).Raw)
+
+	// Issuance fails when a log doesn't return an SCT.
+	secondLog.failed = true
+	_, err = CBWrite(b, s, "issue/ct", map[string]interface{}{
+		"common_name": "www.example.com",
+		"ttl":         "1h",
+	})
+	require.ErrorContains(t, err, "log is read only")
+}
@@ -0,0 +1,300 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+const customExtensionsParam = "custom_extensions"
+
+// Extensions Vault computes itself from the role and the request, which
+// custom extensions may not replace: everything under id-ce (2.5.29), such
+// as the SANs, key usages and basic constraints, and the AIA extension.
+var (
+	oidExtensionArc               = asn1.ObjectIdentifier{2, 5, 29}
+	oidAuthorityInformationAccess = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 1}
+)
+
+// Microsoft extensions emitted from the role's ms_* fields, as defined in
+// [MS-WCCE] Section 2.2.2.7.
+var (
+	oidMicrosoftCertificateTemplate = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 7}
+	oidMicrosoftApplicationPolicies = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 10}
+)
+
+type msCertificateTemplate struct {
+	TemplateID   asn1.ObjectIdentifier
+	MajorVersion int
+	MinorVersion int
+}
+
+type msApplicationPolicy struct {
+	PolicyIdentifier asn1.ObjectIdentifier
+}
+
+// customExtension is an additional X.509 extension added by a role to the
+// certificates it issues. Its value is either fixed, as the base64 encoded
+// DER of the extension's value, or a template, which may contain identity
+// templates and is encoded as a UTF8String.
+type customExtension struct {
+	OID      string `json:"oid"`
+	Critical bool   `json:"critical"`
+	Value    string `json:"value,omitempty"`
+	Template string `json:"template,omitempty"`
+}
+
+func (e customExtension) validate() error {
+	oid, err := certutil.StringToOid(e.OID)
+	if err != nil {
+		return fmt.Errorf("%q could not be parsed as a valid oid for a custom extension", e.OID)
+	}
+
+	if len(oid) > len(oidExtensionArc) && oid[:len(oidExtensionArc)].Equal(oidExtensionArc) || oid.Equal(oidAuthorityInformationAccess) {
+		return fmt.Errorf("custom extension %v may not replace an extension managed by Vault", e.OID)
+	}
+
+	if (len(e.Value) == 0) == (len(e.Template) == 0) {
+		return fmt.Errorf("custom extension %v: exactly one of value or template must be set", e.OID)
+	}
+
+	if len(e.Value) > 0 {
+		if _, err := decodeCustomExtensionValue(e.Value); err != nil {
+			return fmt.Errorf("custom extension %v: %w", e.OID, err)
+		}
+		return nil
+	}
+
+	if _, err := framework.ValidateIdentityTemplate(e.Template); err != nil {
+		return fmt.Errorf("custom extension %v: %w", e.OID, err)
+	}
+
+	return nil
+}
+
+func decodeCustomExtensionValue(value string) ([]byte, error) {
+	der, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding value: %w", err)
+	}
+
+	var raw asn1.RawValue
+	rest, err := asn1.Unmarshal(der, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("value is not valid DER: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("value has trailing data after its DER encoding")
+	}
+
+	return der, nil
+}
+
+// getCustomExtensions parses the custom_extensions parameter, which is a
+// list of extension objects, or a JSON encoding of one, falling back to the
+// given extensions when it wasn't provided.
+func getCustomExtensions(data *framework.FieldData, defaultExtensions []customExtension) ([]customExtension, error) {
+	rawExtensions, ok := data.GetOk(customExtensionsParam)
+	if !ok {
+		return defaultExtensions, nil
+	}
+
+	extensions := []customExtension{}
+	for _, rawExtension := range rawExtensions.([]interface{}) {
+		var encoded []byte
+		switch value := rawExtension.(type) {
+		case string:
+			encoded = []byte(strings.TrimSpace(value))
+		default:
+			var err error
+			encoded, err = json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing %v: %w", customExtensionsParam, err)
+			}
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(encoded))
+		decoder.DisallowUnknownFields()
+		if bytes.HasPrefix(encoded, []byte("[")) {
+			var list []customExtension
+			if err := decoder.Decode(&list); err != nil {
+				return nil, fmt.Errorf("error parsing %v: %w", customExtensionsParam, err)
+			}
+			extensions = append(extensions, list...)
+			continue
+		}
+
+		var extension customExtension
+		if err := decoder.Decode(&extension); err != nil {
+			return nil, fmt.Errorf("error parsing %v: %w", customExtensionsParam, err)
+		}
+		extensions = append(extensions, extension)
+	}
+
+	seen := make(map[string]bool, len(extensions))
+	for _, extension := range extensions {
+		if err := extension.validate(); err != nil {
+			return nil, err
+		}
+
+		oid, _ := certutil.StringToOid(extension.OID)
+		if seen[oid.String()] {
+			return nil, fmt.Errorf("custom extension %v specified multiple times", extension.OID)
+		}
+		seen[oid.String()] = true
+	}
+
+	return extensions, nil
+}
+
+// buildCustomExtensions renders the role's custom extensions for the
+// request, populating any identity templates from the requesting entity.
+func buildCustomExtensions(b *backend, data *inputBundle) ([]pkix.Extension, error) {
+	var extensions []pkix.Extension
+	for _, extension := range data.role.CustomExtensions {
+		oid, err := certutil.StringToOid(extension.OID)
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error parsing custom extension oid %q: %v", extension.OID, err)}
+		}
+
+		var value []byte
+		if len(extension.Value) > 0 {
+			value, err = decodeCustomExtensionValue(extension.Value)
+			if err != nil {
+				return nil, errutil.InternalError{Err: fmt.Sprintf("error decoding custom extension %v: %v", extension.OID, err)}
+			}
+		} else {
+			rendered := extension.Template
+			isTemplate, _ := framework.ValidateIdentityTemplate(extension.Template)
+			if isTemplate {
+				if data.req == nil || data.req.EntityID == "" {
+					return nil, errutil.UserError{Err: fmt.Sprintf("custom extension %v is templated, but the request has no associated entity", extension.OID)}
+				}
+
+				rendered, err = framework.PopulateIdentityTemplate(extension.Template, data.req.EntityID, b.System())
+				if err != nil {
+					return nil, errutil.UserError{Err: fmt.Sprintf("unable to populate template for custom extension %v: %v", extension.OID, err)}
+				}
+			}
+
+			value, err = asn1.MarshalWithParams(rendered, "utf8")
+			if err != nil {
+				return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding custom extension %v: %v", extension.OID, err)}
+			}
+		}
+
+		extensions = append(extensions, pkix.Extension{
+			Id:       oid,
+			Critical: extension.Critical,
+			Value:    value,
+		})
+	}
+
+	return extensions, nil
+}
+
+// validateMicrosoftExtensions ensures the role's Microsoft template and
+// application policies are valid OIDs, not also set as custom extensions.
+func validateMicrosoftExtensions(role *roleEntry) error {
+	if role.MSCertificateTemplate != "" {
+		if _, err := certutil.StringToOid(role.MSCertificateTemplate); err != nil {
+			return fmt.Errorf("%q could not be parsed as a valid oid for ms_certificate_template", role.MSCertificateTemplate)
+		}
+	}
+	if role.MSTemplateMajorVersion < 0 || role.MSTemplateMinorVersion < 0 {
+		return errors.New("ms_template_major_version and ms_template_minor_version must not be negative")
+	}
+
+	for _, oidstr := range role.MSApplicationPolicies {
+		if _, err := certutil.StringToOid(oidstr); err != nil {
+			return fmt.Errorf("%q could not be parsed as a valid oid for ms_application_policies", oidstr)
+		}
+	}
+
+	return checkMicrosoftExtensionConflicts(role)
+}
+
+func checkMicrosoftExtensionConflicts(role *roleEntry) error {
+	for _, extension := range role.CustomExtensions {
+		oid, err := certutil.StringToOid(extension.OID)
+		if err != nil {
+			continue
+		}
+
+		if role.MSCertificateTemplate != "" && oid.Equal(oidMicrosoftCertificateTemplate) {
+			return fmt.Errorf("custom extension %v conflicts with ms_certificate_template", extension.OID)
+		}
+		if len(role.MSApplicationPolicies) > 0 && oid.Equal(oidMicrosoftApplicationPolicies) {
+			return fmt.Errorf("custom extension %v conflicts with ms_application_policies", extension.OID)
+		}
+	}
+
+	return nil
+}
+
+// buildMicrosoftExtensions renders the Microsoft certificate template and
+// application policies extensions of the role, if set.
+func buildMicrosoftExtensions(role *roleEntry) ([]pkix.Extension, error) {
+	// Profiles may bring in custom extensions the role was never checked
+	// against.
+	if err := checkMicrosoftExtensionConflicts(role); err != nil {
+		return nil, errutil.UserError{Err: err.Error()}
+	}
+
+	var extensions []pkix.Extension
+	if role.MSCertificateTemplate != "" {
+		templateID, err := certutil.StringToOid(role.MSCertificateTemplate)
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error parsing ms_certificate_template %q: %v", role.MSCertificateTemplate, err)}
+		}
+
+		value, err := asn1.Marshal(msCertificateTemplate{
+			TemplateID:   templateID,
+			MajorVersion: role.MSTemplateMajorVersion,
+			MinorVersion: role.MSTemplateMinorVersion,
+		})
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding certificate template extension: %v", err)}
+		}
+
+		extensions = append(extensions, pkix.Extension{
+			Id:    oidMicrosoftCertificateTemplate,
+			Value: value,
+		})
+	}
+
+	if len(role.MSApplicationPolicies) > 0 {
+		policies := make([]msApplicationPolicy, 0, len(role.MSApplicationPolicies))
+		for _, oidstr := range role.MSApplicationPolicies {
+			oid, err := certutil.StringToOid(oidstr)
+			if err != nil {
+				return nil, errutil.InternalError{Err: fmt.Sprintf("error parsing ms_application_policies oid %q: %v", oidstr, err)}
+			}
+			policies = append(policies, msApplicationPolicy{PolicyIdentifier: oid})
+		}
+
+		value, err := asn1.Marshal(policies)
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error encoding application policies extension: %v", err)}
+		}
+
+		extensions = append(extensions, pkix.Extension{
+			Id:    oidMicrosoftApplicationPolicies,
+			Value: value,
+		})
+	}
+
+	return extensions, nil
+}
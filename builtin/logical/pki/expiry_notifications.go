@@ -0,0 +1,284 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Expiry notifications: when enabled, stored certificates are periodically
+// scanned and each is notified about once for every threshold before its
+// expiry it crosses. Only the time of the last successful scan is kept, so
+// a failed scan is retried as a whole and may notify some certificates
+// again.
+const (
+	eventTypeCertExpiring = "pki/expiring"
+
+	// Cluster-local, like the certificates being scanned.
+	expiryNotificationStatePath = "expiry-notifications/state"
+
+	expiryNotificationWebhookBatchSize = 100
+)
+
+type expiryNotificationState struct {
+	LastScan time.Time `json:"last_scan"`
+}
+
+type expiringCertificate struct {
+	SerialNumber string    `json:"serial_number"`
+	CommonName   string    `json:"common_name"`
+	NotAfter     time.Time `json:"not_after"`
+	Threshold    string    `json:"threshold"`
+	IsCA         bool      `json:"is_ca"`
+	Role         string    `json:"role"`
+	IssuerID     string    `json:"issuer_id"`
+}
+
+type expiryNotificationWebhookRequest struct {
+	Certificates []expiringCertificate `json:"certificates"`
+}
+
+func (sc *storageContext) getExpiryNotificationState() (*expiryNotificationState, error) {
+	entry, err := sc.Storage.Get(sc.Context, expiryNotificationStatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &expiryNotificationState{}
+	if entry != nil {
+		if err := entry.DecodeJSON(state); err != nil {
+			return nil, fmt.Errorf("unable to decode expiry notification state: %w", err)
+		}
+	}
+
+	return state, nil
+}
+
+func (sc *storageContext) setExpiryNotificationState(state *expiryNotificationState) error {
+	entry, err := logical.StorageEntryJSON(expiryNotificationStatePath, state)
+	if err != nil {
+		return err
+	}
+
+	return sc.Storage.Put(sc.Context, entry)
+}
+
+// crossedExpiryThreshold returns the shortest of the thresholds (sorted
+// longest first) the certificate is within at now, unless the certificate
+// was issued within it already: short-lived certificates aren't notified
+// about thresholds longer than their lifetime.
+func crossedExpiryThreshold(thresholds []time.Duration, cert *x509.Certificate, now time.Time) (time.Duration, bool) {
+	for index := len(thresholds) - 1; index >= 0; index-- {
+		threshold := thresholds[index]
+		crossedAt := cert.NotAfter.Add(-threshold)
+		if now.Before(crossedAt) {
+			continue
+		}
+
+		return threshold, crossedAt.After(cert.NotBefore)
+	}
+
+	return 0, false
+}
+
+// maybeScanExpiringCerts starts a scan for certificates nearing expiry in
+// the background, if enabled and one is due.
+func (b *backend) maybeScanExpiringCerts(sc *storageContext) error {
+	// As we're (below) modifying the backing storage, we need to ensure
+	// we're not on a standby/secondary node.
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) ||
+		b.System().ReplicationState().HasState(consts.ReplicationDRSecondary) {
+		return nil
+	}
+
+	config, err := sc.getExpiryNotificationConfig()
+	if err != nil {
+		return err
+	}
+	if !config.Enabled {
+		return nil
+	}
+
+	state, err := sc.getExpiryNotificationState()
+	if err != nil {
+		return err
+	}
+	if time.Now().Before(state.LastScan.Add(config.ScanInterval)) {
+		return nil
+	}
+
+	if !b.expiryScanRunning.CAS(false, true) {
+		return nil
+	}
+
+	go func() {
+		defer b.expiryScanRunning.Store(false)
+
+		backgroundSc := b.makeStorageContext(context.Background(), b.storage)
+		if err := b.scanExpiringCerts(backgroundSc, config, state, time.Now()); err != nil {
+			b.Logger().Error("failed to notify about certificates nearing expiry", "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// scanExpiringCerts notifies about the stored, unrevoked certificates which
+// crossed one of the configured thresholds since the last scan, recording
+// the scan once all notifications were sent.
+func (b *backend) scanExpiringCerts(sc *storageContext, config *expiryNotificationConfigEntry, state *expiryNotificationState, now time.Time) error {
+	serials, err := sc.Storage.List(sc.Context, "certs/")
+	if err != nil {
+		return fmt.Errorf("error fetching list of certs: %w", err)
+	}
+
+	revokedSerials, err := sc.Storage.List(sc.Context, revokedPath)
+	if err != nil {
+		return fmt.Errorf("error fetching list of revoked certs: %w", err)
+	}
+	revoked := make(map[string]bool, len(revokedSerials))
+	for _, serial := range revokedSerials {
+		revoked[serial] = true
+	}
+
+	var expiring []expiringCertificate
+	for _, serial := range serials {
+		if revoked[serial] {
+			continue
+		}
+
+		certEntry, err := sc.Storage.Get(sc.Context, "certs/"+serial)
+		if err != nil {
+			return fmt.Errorf("error fetching certificate %q: %w", serial, err)
+		}
+		if certEntry == nil || len(certEntry.Value) == 0 {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(certEntry.Value)
+		if err != nil {
+			b.Logger().Warn("unable to parse stored certificate; skipping it for expiry notifications", "serial", serial, "error", err)
+			continue
+		}
+
+		if !now.Before(cert.NotAfter) {
+			continue
+		}
+
+		threshold, ok := crossedExpiryThreshold(config.Thresholds, cert, now)
+		if !ok || !cert.NotAfter.Add(-threshold).After(state.LastScan) {
+			continue
+		}
+
+		notification := expiringCertificate{
+			SerialNumber: serialFromCert(cert),
+			CommonName:   cert.Subject.CommonName,
+			NotAfter:     cert.NotAfter.UTC(),
+			Threshold:    threshold.String(),
+			IsCA:         cert.IsCA,
+		}
+		metadata, err := sc.fetchCertMetadata(serial)
+		if err != nil {
+			return err
+		}
+		if metadata != nil {
+			notification.Role = metadata.Role
+			notification.IssuerID = metadata.IssuerId.String()
+		}
+		expiring = append(expiring, notification)
+	}
+
+	switch config.Sink {
+	case expiryNotificationSinkWebhook:
+		for start := 0; start < len(expiring); start += expiryNotificationWebhookBatchSize {
+			end := start + expiryNotificationWebhookBatchSize
+			if end > len(expiring) {
+				end = len(expiring)
+			}
+
+			if err := callExpiryNotificationWebhook(sc.Context, config, expiring[start:end]); err != nil {
+				return fmt.Errorf("error sending expiry notifications to webhook: %w", err)
+			}
+		}
+	default:
+		for _, notification := range expiring {
+			b.sendExpiringCertEvent(sc.Context, notification)
+		}
+	}
+
+	if len(expiring) > 0 {
+		b.Logger().Info("notified about certificates nearing expiry", "count", len(expiring), "sink", config.Sink)
+	}
+
+	return sc.setExpiryNotificationState(&expiryNotificationState{LastScan: now})
+}
+
+// sendExpiringCertEvent is best effort, like the other certificate events.
+func (b *backend) sendExpiringCertEvent(ctx context.Context, notification expiringCertificate) {
+	err := logical.SendEvent(ctx, b, eventTypeCertExpiring,
+		logical.EventMetadataDataPath, "cert/"+notification.SerialNumber,
+		"serial_number", notification.SerialNumber,
+		"common_name", notification.CommonName,
+		"not_after", notification.NotAfter.Format(time.RFC3339),
+		"threshold", notification.Threshold,
+		"role", notification.Role,
+		"issuer_id", notification.IssuerID,
+	)
+	if err != nil && !errors.Is(err, framework.ErrNoEvents) {
+		b.Logger().Warn("failed to send certificate event", "event_type", eventTypeCertExpiring, "serial_number", notification.SerialNumber, "error", err)
+	}
+}
+
+func callExpiryNotificationWebhook(ctx context.Context, config *expiryNotificationConfigEntry, expiring []expiringCertificate) error {
+	body, err := json.Marshal(expiryNotificationWebhookRequest{Certificates: expiring})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	transport := cleanhttp.DefaultTransport()
+	if config.CACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config.CACertificate)) {
+			return fmt.Errorf("ca_certificate contains no PEM encoded certificates")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, policyWebhookMaxResponseSize))
+		return fmt.Errorf("unexpected response status %v: %v", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiryNotifications(t *testing.T) {
+	t.Parallel()
+
+	events := &mockEventSender{}
+	config := logical.TestBackendConfig()
+	config.StorageView = &logical.InmemStorage{}
+	config.EventsSender = events
+
+	b := Backend(config)
+	require.NoError(t, b.Setup(context.Background(), config))
+	b.pkiStorageVersion.Store(1)
+	s := config.StorageView
+	sc := b.makeStorageContext(context.Background(), s)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	issuerId := resp.Data["issuer_id"].(issuerID).String()
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+	})
+	require.NoError(t, err)
+
+	issue := func(commonName string, ttl string) string {
+		resp, err := CBWrite(b, s, "issue/example", map[string]interface{}{
+			"common_name": commonName,
+			"ttl":         ttl,
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/example")
+		return resp.Data["serial_number"].(string)
+	}
+
+	longSerial := issue("long.example.com", "20h")
+	mediumSerial := issue("medium.example.com", "10h")
+	issue("short.example.com", "2h")
+	revokedSerial := issue("revoked.example.com", "10h")
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": revokedSerial,
+	})
+	require.NoError(t, err)
+
+	// Defaults, and validation of the configuration.
+	resp, err = CBRead(b, s, "config/expiry-notifications")
+	requireSuccessNonNilResponse(t, resp, err, "config/expiry-notifications")
+	require.Equal(t, false, resp.Data["enabled"])
+	require.Equal(t, []string{"720h0m0s", "168h0m0s", "24h0m0s"}, resp.Data["thresholds"])
+	require.Equal(t, expiryNotificationSinkEvent, resp.Data["sink"])
+
+	_, err = CBWrite(b, s, "config/expiry-notifications", map[string]interface{}{
+		"enabled": true,
+		"sink":    "webhook",
+	})
+	require.ErrorContains(t, err, "url is required")
+
+	_, err = CBWrite(b, s, "config/expiry-notifications", map[string]interface{}{
+		"thresholds": "12h,-1h",
+	})
+	require.ErrorContains(t, err, "must be positive")
+
+	resp, err = CBWrite(b, s, "config/expiry-notifications", map[string]interface{}{
+		"enabled":    true,
+		"thresholds": "3h,12h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/expiry-notifications")
+	require.Equal(t, []string{"12h0m0s", "3h0m0s"}, resp.Data["thresholds"])
+
+	scan := func(now time.Time) error {
+		t.Helper()
+
+		cfg, err := sc.getExpiryNotificationConfig()
+		require.NoError(t, err)
+		state, err := sc.getExpiryNotificationState()
+		require.NoError(t, err)
+		return b.scanExpiringCerts(sc, cfg, state, now)
+	}
+
+	requireExpiringEvents := func(since int, expected map[string]string) {
+		t.Helper()
+
+		events.lock.Lock()
+		defer events.lock.Unlock()
+
+		thresholds := map[string]string{}
+		for index := since; index < len(events.events); index++ {
+			require.Equal(t, logical.EventType(eventTypeCertExpiring), events.types[index])
+			metadata := events.events[index].Metadata.AsMap()
+			require.Equal(t, "example", metadata["role"])
+			require.Equal(t, issuerId, metadata["issuer_id"])
+			thresholds[metadata["serial_number"].(string)] = metadata["threshold"].(string)
+		}
+		require.Equal(t, expected, thresholds)
+	}
+
+	// Freshly issued certificates haven't crossed any threshold yet: the
+	// short-lived one was issued within both of them.
+	start := time.Now()
+	eventCount := events.count()
+	require.NoError(t, scan(start))
+	requireExpiringEvents(eventCount, map[string]string{})
+
+	// Nine hours on, the long-lived certificate crossed the longest
+	// threshold while the medium-lived one crossed both; it is only
+	// notified about the shortest. The revoked and expired certificates
+	// are ignored.
+	require.NoError(t, scan(start.Add(9*time.Hour)))
+	requireExpiringEvents(eventCount, map[string]string{
+		longSerial:   "12h0m0s",
+		mediumSerial: "3h0m0s",
+	})
+
+	// A certificate is notified about once per threshold.
+	eventCount = events.count()
+	require.NoError(t, scan(start.Add(10*time.Hour)))
+	requireExpiringEvents(eventCount, map[string]string{})
+
+	require.NoError(t, scan(start.Add(18*time.Hour)))
+	requireExpiringEvents(eventCount, map[string]string{
+		longSerial: "3h0m0s",
+	})
+
+	resp, err = CBRead(b, s, "config/expiry-notifications")
+	requireSuccessNonNilResponse(t, resp, err, "config/expiry-notifications")
+	require.Equal(t, start.Add(18*time.Hour).Format(time.RFC3339), resp.Data["last_scan"])
+
+	// Notifications can be POSTed to a webhook instead.
+	var lock sync.Mutex
+	var received []expiryNotificationWebhookRequest
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if fail {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		var request expiryNotificationWebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		received = append(received, request)
+	}))
+	defer server.Close()
+
+	_, err = CBWrite(b, s, "config/expiry-notifications", map[string]interface{}{
+		"sink": "webhook",
+		"url":  server.URL,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sc.setExpiryNotificationState(&expiryNotificationState{}))
+	eventCount = events.count()
+	require.NoError(t, scan(start.Add(9*time.Hour)))
+	require.Equal(t, eventCount, events.count())
+	require.Len(t, received, 1)
+	require.Len(t, received[0].Certificates, 2)
+	for _, cert := range received[0].Certificates {
+		require.Contains(t, []string{longSerial, mediumSerial}, cert.SerialNumber)
+		require.Equal(t, "example", cert.Role)
+		require.Equal(t, issuerId, cert.IssuerID)
+	}
+
+	// A failed delivery is retried on the next scan.
+	lock.Lock()
+	fail = true
+	lock.Unlock()
+	err = scan(start.Add(18 * time.Hour))
+	require.ErrorContains(t, err, "503")
+
+	state, err := sc.getExpiryNotificationState()
+	require.NoError(t, err)
+	require.True(t, state.LastScan.Equal(start.Add(9*time.Hour)))
+
+	lock.Lock()
+	fail = false
+	lock.Unlock()
+	require.NoError(t, scan(start.Add(18*time.Hour)))
+	require.Len(t, received, 2)
+	require.Len(t, received[1].Certificates, 1)
+	require.Equal(t, longSerial, received[1].Certificates[0].SerialNumber)
+}
@@ -89,6 +89,16 @@ comma-delimited list.`,
 		},
 	}
 
+	fields["upn_sans"] = &framework.FieldSchema{
+		Type: framework.TypeCommaStringSlice,
+		Description: `The requested Microsoft User Principal Names (UPNs) to
+place in otherName SANs, if any, in a comma-delimited list of user@suffix
+values.`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "UPN Subject Alternative Names (SANs)",
+		},
+	}
+
 	return fields
 }
 
@@ -97,6 +107,24 @@ comma-delimited list.`,
 func addNonCACommonFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
 	fields = addIssueAndSignCommonFields(fields)
 
+	fields["format"] = &framework.FieldSchema{
+		Type:    framework.TypeString,
+		Default: "pem",
+		Description: `Format for returned data. Can be "pem", "der",
+"pem_bundle", or "pkcs7". If "pem_bundle", any
+private key and issuing cert will be appended to
+the certificate pem. If "der", the value will be
+base64 encoded. If "pkcs7", the values will be
+base64 encoded as with "der" and the certificate
+and its chain will also be returned as a base64
+encoded, degenerate PKCS#7 bundle. Defaults to
+"pem".`,
+		AllowedValues: []interface{}{"pem", "der", "pem_bundle", "pkcs7"},
+		DisplayAttrs: &framework.DisplayAttributes{
+			Value: "pem",
+		},
+	}
+
 	fields["role"] = &framework.FieldSchema{
 		Type: framework.TypeString,
 		Description: `The desired role with configuration for this
@@ -149,6 +177,14 @@ be larger than the role max TTL.`,
 The value format should be given in UTC format YYYY-MM-ddTHH:MM:SSZ`,
 	}
 
+	fields["not_before"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `Set the not before field of the certificate with specified date value,
+in place of backdating it by the role's not_before_duration. The value format
+should be given in UTC format YYYY-MM-ddTHH:MM:SSZ. It must not be in the future,
+nor further in the past than the role's max_not_before_duration.`,
+	}
+
 	fields["remove_roots_from_chain"] = &framework.FieldSchema{
 		Type:    framework.TypeBool,
 		Default: false,
@@ -166,6 +202,17 @@ Any values are added with OID 0.9.2342.19200300.100.1.1.`,
 		},
 	}
 
+	fields["cert_metadata"] = &framework.FieldSchema{
+		Type: framework.TypeKVPairs,
+		Description: `Arbitrary key/value metadata to store alongside
+the issued certificate, such as its owner or the ticket it was requested
+under. Returned when reading the certificate; requires the role to store
+certificates.`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Certificate Metadata",
+		},
+	}
+
 	fields = addIssuerRefField(fields)
 
 	return fields
@@ -291,6 +338,15 @@ The value format should be given in UTC format YYYY-MM-ddTHH:MM:SSZ`,
 		},
 	}
 
+	fields[policyIdentifiersParam] = &framework.FieldSchema{
+		Type: framework.TypeCommaStringSlice,
+		Description: `A comma-separated string or list of policy OIDs, or a JSON list of qualified policy
+information, which must include an oid, and may include a notice and/or cps url, using the form
+[{"oid"="1.3.6.1.4.1.7.8","notice"="I am a user Notice"}, {"oid"="1.3.6.1.4.1.44947.1.2.4 ","cps"="https://example.com"}].
+When generating a CSR, these are requested in it. When signing an intermediate,
+defaults to the signing issuer's policy identifiers.`,
+	}
+
 	return fields
 }
 
@@ -376,6 +432,15 @@ func addCAIssueFields(fields map[string]*framework.FieldSchema) map[string]*fram
 		Description: "The maximum allowable path length",
 	}
 
+	fields = addCANameConstraintsFields(fields)
+	fields = addIssuerNameField(fields)
+
+	return fields
+}
+
+// addCANameConstraintsFields adds the name constraints fields, used when
+// generating or signing CA certificates and when requesting them in a CSR
+func addCANameConstraintsFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
 	fields["permitted_dns_domains"] = &framework.FieldSchema{
 		Type:        framework.TypeCommaStringSlice,
 		Description: `Domains for which this certificate is allowed to sign or issue child certificates. If set, all DNS names (subject and alt) on child certs must be exact matches or subsets of the given domains (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
@@ -384,7 +449,61 @@ func addCAIssueFields(fields map[string]*framework.FieldSchema) map[string]*fram
 		},
 	}
 
-	fields = addIssuerNameField(fields)
+	fields["excluded_dns_domains"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `Domains for which this certificate is not allowed to sign or issue child certificates (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Excluded DNS Domains",
+		},
+	}
+
+	fields["permitted_ip_ranges"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `IP ranges for which this certificate is allowed to sign or issue child certificates, in CIDR notation (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Permitted IP Ranges",
+		},
+	}
+
+	fields["excluded_ip_ranges"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `IP ranges for which this certificate is not allowed to sign or issue child certificates, in CIDR notation (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Excluded IP Ranges",
+		},
+	}
+
+	fields["permitted_email_addresses"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `Email addresses or domains for which this certificate is allowed to sign or issue child certificates (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Permitted Email Addresses",
+		},
+	}
+
+	fields["excluded_email_addresses"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `Email addresses or domains for which this certificate is not allowed to sign or issue child certificates (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Excluded Email Addresses",
+		},
+	}
+
+	fields["permitted_uri_domains"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `URI domains for which this certificate is allowed to sign or issue child certificates (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Permitted URI Domains",
+		},
+	}
+
+	fields["excluded_uri_domains"] = &framework.FieldSchema{
+		Type:        framework.TypeCommaStringSlice,
+		Description: `URI domains for which this certificate is not allowed to sign or issue child certificates (see https://tools.ietf.org/html/rfc5280#section-4.2.1.10).`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Excluded URI Domains",
+		},
+	}
 
 	return fields
 }
@@ -415,6 +534,17 @@ to the issuer.`,
 	return fields
 }
 
+func addKeyAttestationField(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
+	fields["key_attestation"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `PEM bundle of the attestation certificate of the CSR's
+key, as issued by the hardware holding it, followed by any intermediate
+certificates chaining it to the key_attestation_roots of the role's profile.
+Required when the profile sets require_key_attestation.`,
+	}
+	return fields
+}
+
 func addKeyRefNameFields(fields map[string]*framework.FieldSchema) map[string]*framework.FieldSchema {
 	fields = addKeyNameField(fields)
 	fields = addKeyRefField(fields)
@@ -572,6 +702,25 @@ the cross-cluster revoked certificate store. Only runs on the active
 primary node.`,
 	}
 
+	fields["tidy_cert_metadata"] = &framework.FieldSchema{
+		Type: framework.TypeBool,
+		Description: `Set to true to remove certificate metadata entries
+whose certificate is no longer stored, such as ones left behind by
+earlier versions of tidy. Metadata is otherwise removed alongside its
+certificate by tidy_cert_store and tidy_revoked_certs.`,
+		Default: defaultTidyConfig.CertMetadata,
+	}
+
+	fields["tidy_orphaned_issuer_associations"] = &framework.FieldSchema{
+		Type: framework.TypeBool,
+		Description: `Set to true to remove references left behind
+by deleted issuers and keys: default issuer and key settings pointing
+to them, and the CRL numbering and stored CRLs of issuers which no
+longer exist. No issuers or keys will be removed as part of this
+operation.`,
+		Default: defaultTidyConfig.OrphanedAssocs,
+	}
+
 	return fields
 }
 
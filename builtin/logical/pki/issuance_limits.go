@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const issuanceRateWindow = time.Minute
+
+// issuanceLimiter enforces the certs_per_minute and max_concurrent_signs
+// limits of the mount and its roles. Its state is kept in memory, so each
+// node enforces the limits independently.
+type issuanceLimiter struct {
+	lock  sync.Mutex
+	mount issuanceLimiterState
+	roles map[string]*issuanceLimiterState
+}
+
+type issuanceLimiterState struct {
+	// issued holds the times certificates were issued within the last
+	// issuanceRateWindow, oldest first. It is only tracked while a
+	// certs_per_minute limit applies.
+	issued []time.Time
+	active int
+}
+
+func newIssuanceLimiter() *issuanceLimiter {
+	return &issuanceLimiter{
+		roles: make(map[string]*issuanceLimiterState),
+	}
+}
+
+// check returns a description of the limit an additional issuance would
+// exceed, if any.
+func (s *issuanceLimiterState) check(now time.Time, certsPerMinute int, maxConcurrentSigns int) string {
+	cutoff := now.Add(-issuanceRateWindow)
+	expired := 0
+	for expired < len(s.issued) && !s.issued[expired].After(cutoff) {
+		expired++
+	}
+	s.issued = s.issued[expired:]
+
+	if maxConcurrentSigns > 0 && s.active >= maxConcurrentSigns {
+		return fmt.Sprintf("signs at most %d certificates concurrently", maxConcurrentSigns)
+	}
+	if certsPerMinute > 0 && len(s.issued) >= certsPerMinute {
+		return fmt.Sprintf("issues at most %d certificates per minute", certsPerMinute)
+	}
+
+	return ""
+}
+
+func (s *issuanceLimiterState) acquire(now time.Time, certsPerMinute int) {
+	if certsPerMinute > 0 {
+		s.issued = append(s.issued, now)
+	}
+	s.active++
+}
+
+// acquire reserves the issuance of a certificate by the role against the
+// limits of the mount and the role, returning a function to release the
+// reservation once the certificate is signed and stored. Requests exceeding
+// a limit are refused with logical.ErrRateLimitQuotaExceeded, which is
+// answered with a 429 status code.
+func (l *issuanceLimiter) acquire(config *issuanceLimitsConfigEntry, role *roleEntry) (func(), error) {
+	now := time.Now()
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if exceeded := l.mount.check(now, config.CertsPerMinute, config.MaxConcurrentSigns); exceeded != "" {
+		return nil, fmt.Errorf("%w: this mount %v; retry later", logical.ErrRateLimitQuotaExceeded, exceeded)
+	}
+
+	// On the fly roles, such as those of sign-verbatim, have no name and
+	// are only subject to the limits of the mount. Roles whose limits were
+	// removed drop their state.
+	var roleState *issuanceLimiterState
+	if role.Name != "" && role.CertsPerMinute == 0 && role.MaxConcurrentSigns == 0 {
+		delete(l.roles, role.Name)
+	}
+	if role.Name != "" && (role.CertsPerMinute > 0 || role.MaxConcurrentSigns > 0) {
+		roleState = l.roles[role.Name]
+		if roleState == nil {
+			roleState = &issuanceLimiterState{}
+			l.roles[role.Name] = roleState
+		}
+
+		if exceeded := roleState.check(now, role.CertsPerMinute, role.MaxConcurrentSigns); exceeded != "" {
+			return nil, fmt.Errorf("%w: role %v %v; retry later", logical.ErrRateLimitQuotaExceeded, role.Name, exceeded)
+		}
+	}
+
+	l.mount.acquire(now, config.CertsPerMinute)
+	if roleState != nil {
+		roleState.acquire(now, role.CertsPerMinute)
+	}
+
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		l.mount.active--
+		if roleState != nil {
+			roleState.active--
+		}
+	}, nil
+}
+
+// forgetRole drops the state of the role, once it is deleted or changed by
+// another node. Reservations still held are released against the dropped
+// state, so they no longer count against the limits of the role.
+func (l *issuanceLimiter) forgetRole(name string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.roles, name)
+}
+
+// acquireIssuanceLimits reserves the issuance of a certificate by the role
+// against the configured issuance limits; see issuanceLimiter.acquire and
+// checkStoredCertificateCap.
+func (b *backend) acquireIssuanceLimits(sc *storageContext, role *roleEntry) (func(), error) {
+	config, err := sc.getIssuanceLimitsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load issuance limits configuration: %w", err)
+	}
+
+	if err := b.checkStoredCertificateCap(config, role); err != nil {
+		return nil, err
+	}
+
+	return b.issuanceLimiter.acquire(config, role)
+}
@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssuanceLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := newIssuanceLimiter()
+	config := &issuanceLimitsConfigEntry{MaxConcurrentSigns: 2}
+	role := &roleEntry{Name: "test", MaxConcurrentSigns: 1}
+
+	release, err := limiter.acquire(config, role)
+	require.NoError(t, err)
+	_, err = limiter.acquire(config, role)
+	require.ErrorIs(t, err, logical.ErrRateLimitQuotaExceeded)
+	require.ErrorContains(t, err, "role test signs at most 1 certificates concurrently")
+
+	// On the fly roles are only subject to the limits of the mount.
+	releaseOther, err := limiter.acquire(config, &roleEntry{})
+	require.NoError(t, err)
+	_, err = limiter.acquire(config, &roleEntry{})
+	require.ErrorContains(t, err, "this mount signs at most 2 certificates concurrently")
+
+	release()
+	releaseOther()
+	release, err = limiter.acquire(config, role)
+	require.NoError(t, err)
+	release()
+
+	// Roles without limits don't keep any state.
+	require.Contains(t, limiter.roles, "test")
+	release, err = limiter.acquire(config, &roleEntry{Name: "test"})
+	require.NoError(t, err)
+	release()
+	require.NotContains(t, limiter.roles, "test")
+
+	// Issuance older than a minute no longer counts.
+	now := time.Now()
+	state := &issuanceLimiterState{}
+	state.acquire(now.Add(-2*time.Minute), 1)
+	state.active--
+	require.Empty(t, state.check(now, 1, 0))
+	require.Empty(t, state.issued)
+	state.acquire(now, 1)
+	state.active--
+	require.Equal(t, "issues at most 1 certificates per minute", state.check(now.Add(30*time.Second), 1, 0))
+}
+
+func TestPki_IssuanceLimits(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+	ctx := context.Background()
+
+	resp, err := CBRead(b, s, "config/issuance-limits")
+	requireSuccessNonNilResponse(t, resp, err, "config/issuance-limits")
+	require.Equal(t, 0, resp.Data["certs_per_minute"])
+	require.Equal(t, 0, resp.Data["max_concurrent_signs"])
+
+	_, err = CBWrite(b, s, "config/issuance-limits", map[string]interface{}{
+		"certs_per_minute": -1,
+	})
+	require.ErrorContains(t, err, "certs_per_minute must not be negative")
+
+	resp, err = CBWrite(b, s, "config/issuance-limits", map[string]interface{}{
+		"certs_per_minute": 3,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/issuance-limits")
+
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/limited", map[string]interface{}{
+		"allow_any_name":   true,
+		"certs_per_minute": -1,
+	})
+	require.ErrorContains(t, err, "must not be negative")
+
+	for _, role := range []string{"limited", "other"} {
+		resp, err = CBWrite(b, s, "roles/"+role, map[string]interface{}{
+			"allow_any_name": true,
+			"key_type":       "ec",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "roles/"+role)
+	}
+	resp, err = CBPatch(b, s, "roles/limited", map[string]interface{}{
+		"certs_per_minute": 2,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/limited")
+	require.Equal(t, 2, resp.Data["certs_per_minute"])
+
+	issue := func(role string) error {
+		_, err := CBWrite(b, s, "issue/"+role, map[string]interface{}{
+			"common_name": "www.example.com",
+		})
+		return err
+	}
+
+	require.NoError(t, issue("limited"))
+	require.NoError(t, issue("limited"))
+	err = issue("limited")
+	require.ErrorContains(t, err, "role limited issues at most 2 certificates per minute")
+	code, _ := logical.RespondErrorCommon(&logical.Request{}, nil, err)
+	require.Equal(t, http.StatusTooManyRequests, code)
+
+	// Refused requests do not count against the limit of the mount.
+	require.NoError(t, issue("other"))
+	require.ErrorContains(t, issue("other"), "this mount issues at most 3 certificates per minute")
+
+	resp, err = CBWrite(b, s, "config/issuance-limits", map[string]interface{}{
+		"certs_per_minute": 0,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/issuance-limits")
+	require.NoError(t, issue("other"))
+	require.ErrorContains(t, issue("limited"), "role limited issues at most 2 certificates per minute")
+
+	// The state of deleted roles is dropped, so a role recreated with the
+	// same name starts afresh.
+	resp, err = CBDelete(b, s, "roles/limited")
+	require.NoError(t, err)
+	require.NotContains(t, b.issuanceLimiter.roles, "limited")
+	resp, err = CBWrite(b, s, "roles/limited", map[string]interface{}{
+		"allow_any_name":   true,
+		"key_type":         "ec",
+		"certs_per_minute": 2,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/limited")
+	require.NoError(t, issue("limited"))
+
+	// Roles changed on other nodes drop their state too.
+	b.invalidate(ctx, "role/limited")
+	require.NotContains(t, b.issuanceLimiter.roles, "limited")
+}
@@ -0,0 +1,120 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// parseKeyAttestationRoots parses the PEM bundle of the roots key
+// attestations must chain to, returning a nil pool when none are set.
+func parseKeyAttestationRoots(bundle string) (*x509.CertPool, error) {
+	certs, err := parseKeyAttestationCertificates(bundle)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+
+	return pool, nil
+}
+
+func parseKeyAttestationCertificates(bundle string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type %v", block.Type)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 && strings.TrimSpace(bundle) != "" {
+		return nil, fmt.Errorf("no PEM encoded certificates found")
+	}
+
+	return certs, nil
+}
+
+// validateKeyAttestation ensures the request carries an attestation of the
+// CSR's key when the role requires one. An attestation is the certificate a
+// hardware device issues for a key it generated, such as a YubiKey PIV
+// attestation certificate, followed by the certificates chaining it to one of
+// the role's key_attestation_roots.
+func validateKeyAttestation(data *inputBundle, publicKey crypto.PublicKey) error {
+	if !data.role.RequireKeyAttestation {
+		return nil
+	}
+
+	var attestation string
+	if _, present := data.apiData.Schema["key_attestation"]; present {
+		attestation = data.apiData.Get("key_attestation").(string)
+	}
+	if attestation == "" {
+		return errutil.UserError{Err: "role requires a key_attestation of the CSR's key"}
+	}
+
+	certs, err := parseKeyAttestationCertificates(attestation)
+	if err != nil {
+		return errutil.UserError{Err: fmt.Sprintf("invalid key_attestation: %v", err)}
+	}
+
+	attested, err := x509.MarshalPKIXPublicKey(certs[0].PublicKey)
+	if err != nil {
+		return errutil.UserError{Err: fmt.Sprintf("invalid key_attestation: %v", err)}
+	}
+	requested, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return errutil.UserError{Err: fmt.Sprintf("failed marshaling CSR's public key: %v", err)}
+	}
+	if !bytes.Equal(attested, requested) {
+		return errutil.UserError{Err: "key_attestation does not attest the CSR's key"}
+	}
+
+	roots, err := parseKeyAttestationRoots(data.role.KeyAttestationRoots)
+	if err != nil || roots == nil {
+		return errutil.InternalError{Err: fmt.Sprintf("role has invalid key_attestation_roots: %v", err)}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	// Attestation certificates carry no extended key usages of note, so
+	// only their chain is verified.
+	if _, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return errutil.UserError{Err: fmt.Sprintf("key_attestation is not trusted: %v", err)}
+	}
+
+	return nil
+}
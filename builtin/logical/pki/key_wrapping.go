@@ -0,0 +1,207 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/google/tink/go/kwp/subtle"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Keys imported through keys/import-wrapped are wrapped with the same scheme
+// as Transit's BYOK import: an ephemeral AES-256 key, encrypted with RSA-OAEP
+// to the mount's import wrapping key, followed by the PKCS#8 private key
+// wrapped with that AES key (AES-KWP, RFC 5649).
+const (
+	importWrappingKeyPath = "config/import-wrapping-key"
+	importWrappingKeyBits = 4096
+
+	// The length of the RSA-OAEP encrypted ephemeral key at the start of
+	// the ciphertext, matching the size of the wrapping key.
+	importWrappingEncryptedKeyBytes = importWrappingKeyBits / 8
+)
+
+type importWrappingKeyEntry struct {
+	PrivateKey string `json:"private_key"`
+}
+
+// getImportWrappingKey returns the mount's RSA key used to wrap keys for
+// import, generating it on first use. Callers must hold the issuersLock.
+func (sc *storageContext) getImportWrappingKey() (*rsa.PrivateKey, error) {
+	entry, err := sc.Storage.Get(sc.Context, importWrappingKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching import wrapping key: %w", err)
+	}
+
+	if entry != nil {
+		var wrappingKey importWrappingKeyEntry
+		if err := entry.DecodeJSON(&wrappingKey); err != nil {
+			return nil, fmt.Errorf("error decoding import wrapping key: %w", err)
+		}
+
+		block, _ := pem.Decode([]byte(wrappingKey.PrivateKey))
+		if block == nil {
+			return nil, errors.New("error decoding import wrapping key: no PEM data")
+		}
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, importWrappingKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("error generating import wrapping key: %w", err)
+	}
+
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	entry, err = logical.StorageEntryJSON(importWrappingKeyPath, &importWrappingKeyEntry{PrivateKey: string(keyPem)})
+	if err != nil {
+		return nil, err
+	}
+	if err := sc.Storage.Put(sc.Context, entry); err != nil {
+		return nil, fmt.Errorf("error storing import wrapping key: %w", err)
+	}
+
+	return key, nil
+}
+
+// unwrapImportedKey reverses the BYOK wrapping of ciphertext, returning the
+// DER encoded PKCS#8 private key within it.
+func unwrapImportedKey(wrappingKey *rsa.PrivateKey, ciphertext []byte, hashFn hash.Hash) ([]byte, error) {
+	// Bounds check the ciphertext to avoid panics
+	if len(ciphertext) <= importWrappingEncryptedKeyBytes {
+		return nil, errors.New("provided ciphertext is too short")
+	}
+
+	wrappedEphKey := ciphertext[:importWrappingEncryptedKeyBytes]
+	wrappedImportKey := ciphertext[importWrappingEncryptedKeyBytes:]
+
+	ephKey, err := rsa.DecryptOAEP(hashFn, rand.Reader, wrappingKey, wrappedEphKey, []byte{})
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting ephemeral key: %w", err)
+	}
+
+	// Zero out the ephemeral AES key once done with it; while not a
+	// guarantee against memory analysis, it limits its lifetime.
+	defer func() {
+		for i := range ephKey {
+			ephKey[i] = 0
+		}
+	}()
+
+	if len(ephKey) != 32 {
+		return nil, errors.New("expected ephemeral AES key to be 256-bit")
+	}
+
+	kwp, err := subtle.NewKWP(ephKey)
+	if err != nil {
+		return nil, err
+	}
+
+	importKey, err := kwp.Unwrap(wrappedImportKey)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping key: %w", err)
+	}
+
+	return importKey, nil
+}
+
+// wrapExportedKey wraps the DER encoded PKCS#8 private key to the given RSA
+// public key with the same scheme as unwrapImportedKey, so that the result
+// can be imported into another mount, into Transit or into a KMS supporting
+// RSA-OAEP with AES key wrap.
+func wrapExportedKey(wrappingKey *rsa.PublicKey, keyDer []byte, hashFn hash.Hash) ([]byte, error) {
+	ephKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, ephKey); err != nil {
+		return nil, fmt.Errorf("error generating ephemeral key: %w", err)
+	}
+
+	defer func() {
+		for i := range ephKey {
+			ephKey[i] = 0
+		}
+	}()
+
+	kwp, err := subtle.NewKWP(ephKey)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := kwp.Wrap(keyDer)
+	if err != nil {
+		return nil, fmt.Errorf("error wrapping key: %w", err)
+	}
+
+	wrappedEphKey, err := rsa.EncryptOAEP(hashFn, rand.Reader, wrappingKey, ephKey, []byte{})
+	if err != nil {
+		return nil, fmt.Errorf("error encrypting ephemeral key: %w", err)
+	}
+
+	return append(wrappedEphKey, wrappedKey...), nil
+}
+
+// parseExportWrappingKey parses a PEM encoded RSA public key, either in
+// PKIX or PKCS#1 form, to wrap exported keys to.
+func parseExportWrappingKey(publicKey string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicKey))
+	if block == nil {
+		return nil, errors.New("error decoding public key: no PEM data")
+	}
+
+	var rsaKey *rsa.PublicKey
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key: %w", err)
+		}
+		rsaKey = key
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key: %w", err)
+		}
+
+		var ok bool
+		rsaKey, ok = key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key must be an RSA key, got %T", key)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported public key PEM type: %q", block.Type)
+	}
+
+	if rsaKey.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("public key must be at least 2048 bits, got %d", rsaKey.N.BitLen())
+	}
+
+	return rsaKey, nil
+}
+
+func parseWrappingHashFn(hashFn string) (hash.Hash, error) {
+	switch strings.ToUpper(hashFn) {
+	case "SHA1":
+		return sha1.New(), nil
+	case "SHA224":
+		return sha256.New224(), nil
+	case "SHA256":
+		return sha256.New(), nil
+	case "SHA384":
+		return sha512.New384(), nil
+	case "SHA512":
+		return sha512.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown hash function: %s", hashFn)
+	}
+}
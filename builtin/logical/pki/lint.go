@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Certificate linting: roles with a lint mode run the certificates they
+// issue through zlint-style checks before the certificate is released,
+// stored or submitted to CT logs. In the warn mode, every finding is
+// returned as a warning; in the block mode, findings of error severity
+// refuse issuance and the certificate is discarded.
+const (
+	lintModeOff   = "off"
+	lintModeWarn  = "warn"
+	lintModeBlock = "block"
+)
+
+type lintSeverity int
+
+const (
+	lintWarning lintSeverity = iota
+	lintError
+)
+
+type certLint struct {
+	severity lintSeverity
+	check    func(cert *x509.Certificate) []string
+}
+
+// certLints are keyed by the name of the equivalent zlint lint, where one
+// exists; the e_ and w_ prefixes denote their severity.
+var certLints = map[string]certLint{
+	"e_rsa_mod_less_than_2048_bits": {lintError, func(cert *x509.Certificate) []string {
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && key.N.BitLen() < 2048 {
+			return []string{fmt.Sprintf("RSA modulus is %d bits", key.N.BitLen())}
+		}
+		return nil
+	}},
+	"e_rsa_public_exponent_not_odd": {lintError, func(cert *x509.Certificate) []string {
+		if key, ok := cert.PublicKey.(*rsa.PublicKey); ok && (key.E < 3 || key.E%2 == 0) {
+			return []string{fmt.Sprintf("RSA public exponent is %d", key.E)}
+		}
+		return nil
+	}},
+	"e_ec_improper_curves": {lintError, func(cert *x509.Certificate) []string {
+		if key, ok := cert.PublicKey.(*ecdsa.PublicKey); ok {
+			switch key.Curve {
+			case elliptic.P256(), elliptic.P384(), elliptic.P521():
+			default:
+				return []string{fmt.Sprintf("EC key uses curve %v", key.Curve.Params().Name)}
+			}
+		}
+		return nil
+	}},
+	"e_serial_number_not_positive": {lintError, func(cert *x509.Certificate) []string {
+		if cert.SerialNumber.Sign() <= 0 {
+			return []string{"serial number is not positive"}
+		}
+		return nil
+	}},
+	"e_serial_number_longer_than_20_octets": {lintError, func(cert *x509.Certificate) []string {
+		if len(cert.SerialNumber.Bytes()) > 20 {
+			return []string{fmt.Sprintf("serial number is %d octets", len(cert.SerialNumber.Bytes()))}
+		}
+		return nil
+	}},
+	"e_validity_time_not_positive": {lintError, func(cert *x509.Certificate) []string {
+		if !cert.NotAfter.After(cert.NotBefore) {
+			return []string{"NotAfter is not after NotBefore"}
+		}
+		return nil
+	}},
+	"w_validity_period_over_398_days": {lintWarning, func(cert *x509.Certificate) []string {
+		if !cert.IsCA && cert.NotAfter.Sub(cert.NotBefore) > 398*24*time.Hour {
+			return []string{"validity period exceeds the 398 days browsers accept for TLS certificates"}
+		}
+		return nil
+	}},
+	"e_sub_cert_key_usage_cert_sign_bit_set": {lintError, func(cert *x509.Certificate) []string {
+		if !cert.IsCA && cert.KeyUsage&x509.KeyUsageCertSign != 0 {
+			return []string{"non-CA certificate has the keyCertSign key usage"}
+		}
+		return nil
+	}},
+	"e_ext_san_missing": {lintError, func(cert *x509.Certificate) []string {
+		if len(cert.RawSubject) <= 2 && !hasSANs(cert) {
+			return []string{"certificate has neither a subject nor subject alternative names"}
+		}
+		return nil
+	}},
+	"e_dnsname_not_valid": {lintError, func(cert *x509.Certificate) []string {
+		var problems []string
+		for _, name := range cert.DNSNames {
+			switch {
+			case len(strings.TrimSuffix(name, ".")) > 253:
+				problems = append(problems, fmt.Sprintf("DNS name %q is longer than 253 characters", name))
+			case !hostnameRegex.MatchString(name):
+				problems = append(problems, fmt.Sprintf("DNS name %q is not a valid hostname", name))
+			default:
+				for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+					if len(label) > 63 {
+						problems = append(problems, fmt.Sprintf("DNS name %q has a label longer than 63 characters", name))
+						break
+					}
+				}
+			}
+		}
+		return problems
+	}},
+	"e_dnsname_wildcard_only_in_left_label": {lintError, func(cert *x509.Certificate) []string {
+		var problems []string
+		for _, name := range cert.DNSNames {
+			if strings.Contains(strings.TrimPrefix(name, "*."), "*") {
+				problems = append(problems, fmt.Sprintf("DNS name %q has a wildcard outside of its leftmost label", name))
+			}
+		}
+		return problems
+	}},
+	"e_dnsname_is_ip_address": {lintError, func(cert *x509.Certificate) []string {
+		var problems []string
+		for _, name := range cert.DNSNames {
+			if net.ParseIP(name) != nil {
+				problems = append(problems, fmt.Sprintf("DNS name %q is an IP address", name))
+			}
+		}
+		return problems
+	}},
+	"e_ext_san_rfc822_name_invalid": {lintError, func(cert *x509.Certificate) []string {
+		var problems []string
+		for _, email := range cert.EmailAddresses {
+			at := strings.LastIndex(email, "@")
+			if at <= 0 || !hostnameRegex.MatchString(email[at+1:]) {
+				problems = append(problems, fmt.Sprintf("email address %q is not valid", email))
+			}
+		}
+		return problems
+	}},
+	"e_ext_san_uri_relative": {lintError, func(cert *x509.Certificate) []string {
+		var problems []string
+		for _, uri := range cert.URIs {
+			if !uri.IsAbs() {
+				problems = append(problems, fmt.Sprintf("URI %q is not absolute", uri.String()))
+			}
+		}
+		return problems
+	}},
+	"w_subject_common_name_not_from_san": {lintWarning, func(cert *x509.Certificate) []string {
+		cn := cert.Subject.CommonName
+		if cn == "" || !hasSANs(cert) {
+			return nil
+		}
+		for _, name := range append(append([]string{}, cert.DNSNames...), cert.EmailAddresses...) {
+			if strings.EqualFold(name, cn) {
+				return nil
+			}
+		}
+		for _, ip := range cert.IPAddresses {
+			if ip.String() == cn {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("common name %q is not one of the subject alternative names", cn)}
+	}},
+}
+
+func hasSANs(cert *x509.Certificate) bool {
+	return len(cert.DNSNames) > 0 || len(cert.EmailAddresses) > 0 || len(cert.IPAddresses) > 0 || len(cert.URIs) > 0
+}
+
+func validateLintMode(mode string) error {
+	switch mode {
+	case lintModeOff, lintModeWarn, lintModeBlock:
+		return nil
+	default:
+		return fmt.Errorf("invalid lint_mode %q: must be %q, %q or %q", mode, lintModeOff, lintModeWarn, lintModeBlock)
+	}
+}
+
+func validateLintNames(names []string) error {
+	for _, name := range names {
+		if _, ok := certLints[name]; !ok {
+			return fmt.Errorf("unknown lint %q in lint_ignore", name)
+		}
+	}
+	return nil
+}
+
+// lintCertificate runs the role's lints on the certificate, returning the
+// findings to warn about, and an error describing the findings blocking
+// issuance, if any.
+func lintCertificate(role *roleEntry, cert *x509.Certificate) ([]string, error) {
+	if role.LintMode == "" || role.LintMode == lintModeOff {
+		return nil, nil
+	}
+
+	ignored := make(map[string]bool, len(role.LintIgnore))
+	for _, name := range role.LintIgnore {
+		ignored[name] = true
+	}
+
+	names := make([]string, 0, len(certLints))
+	for name := range certLints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var warnings, failures []string
+	for _, name := range names {
+		if ignored[name] {
+			continue
+		}
+
+		lint := certLints[name]
+		for _, problem := range lint.check(cert) {
+			finding := fmt.Sprintf("%v: %v", name, problem)
+			if role.LintMode == lintModeBlock && lint.severity == lintError {
+				failures = append(failures, finding)
+			} else {
+				warnings = append(warnings, "certificate lint "+finding)
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("certificate failed linting: %v", strings.Join(failures, "; "))
+	}
+
+	return warnings, nil
+}
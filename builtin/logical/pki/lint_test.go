@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/x509"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCertificate(t *testing.T) {
+	t.Parallel()
+
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(400 * 24 * time.Hour),
+		DNSNames:     []string{"www.example.com", "foo.*.example.com", "127.0.0.1"},
+		URIs:         []*url.URL{{Path: "relative"}},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	warnings, err := lintCertificate(&roleEntry{LintMode: lintModeOff}, cert)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+
+	warnings, err = lintCertificate(&roleEntry{LintMode: lintModeWarn}, cert)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`certificate lint e_dnsname_is_ip_address: DNS name "127.0.0.1" is an IP address`,
+		`certificate lint e_dnsname_not_valid: DNS name "foo.*.example.com" is not a valid hostname`,
+		`certificate lint e_dnsname_wildcard_only_in_left_label: DNS name "foo.*.example.com" has a wildcard outside of its leftmost label`,
+		`certificate lint e_ext_san_uri_relative: URI "relative" is not absolute`,
+		`certificate lint w_validity_period_over_398_days: validity period exceeds the 398 days browsers accept for TLS certificates`,
+	}, warnings)
+
+	// In the block mode, only errors block issuance.
+	_, err = lintCertificate(&roleEntry{LintMode: lintModeBlock}, cert)
+	require.ErrorContains(t, err, "e_ext_san_uri_relative")
+
+	warnings, err = lintCertificate(&roleEntry{
+		LintMode:   lintModeBlock,
+		LintIgnore: []string{"e_dnsname_is_ip_address", "e_dnsname_not_valid", "e_dnsname_wildcard_only_in_left_label", "e_ext_san_uri_relative"},
+	}, cert)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Contains(t, warnings[0], "w_validity_period_over_398_days")
+}
+
+func TestPki_RoleLinting(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/lint", map[string]interface{}{
+		"allow_any_name": true,
+		"lint_mode":      "strict",
+	})
+	require.ErrorContains(t, err, "invalid lint_mode")
+
+	_, err = CBWrite(b, s, "roles/lint", map[string]interface{}{
+		"allow_any_name": true,
+		"lint_ignore":    "e_no_such_lint",
+	})
+	require.ErrorContains(t, err, `unknown lint "e_no_such_lint"`)
+
+	resp, err = CBWrite(b, s, "roles/lint", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"key_usage":      "DigitalSignature,CertSign",
+		"lint_mode":      "warn",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/lint")
+	require.Equal(t, "warn", resp.Data["lint_mode"])
+
+	issue := map[string]interface{}{
+		"common_name":          "www.example.com",
+		"alt_names":            "other.example.com",
+		"exclude_cn_from_sans": true,
+		"ttl":                  "1h",
+	}
+	resp, err = CBWrite(b, s, "issue/lint", issue)
+	requireSuccessNonNilResponse(t, resp, err, "issue/lint")
+	require.Contains(t, resp.Warnings, "certificate lint e_sub_cert_key_usage_cert_sign_bit_set: non-CA certificate has the keyCertSign key usage")
+	require.Contains(t, resp.Warnings, `certificate lint w_subject_common_name_not_from_san: common name "www.example.com" is not one of the subject alternative names`)
+
+	// Blocked certificates are neither returned nor stored.
+	resp, err = CBList(b, s, "certs")
+	requireSuccessNonNilResponse(t, resp, err, "certs")
+	storedCerts := len(resp.Data["keys"].([]string))
+
+	_, err = CBPatch(b, s, "roles/lint", map[string]interface{}{
+		"lint_mode": "block",
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "issue/lint", issue)
+	require.ErrorContains(t, err, "certificate failed linting: e_sub_cert_key_usage_cert_sign_bit_set")
+
+	resp, err = CBList(b, s, "certs")
+	requireSuccessNonNilResponse(t, resp, err, "certs")
+	require.Len(t, resp.Data["keys"], storedCerts)
+
+	// Ignored lints neither block nor warn; warning severity lints still warn.
+	resp, err = CBPatch(b, s, "roles/lint", map[string]interface{}{
+		"lint_ignore": "e_sub_cert_key_usage_cert_sign_bit_set",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/lint")
+	require.Equal(t, "block", resp.Data["lint_mode"])
+
+	resp, err = CBWrite(b, s, "issue/lint", issue)
+	requireSuccessNonNilResponse(t, resp, err, "issue/lint")
+	require.NotContains(t, resp.Warnings, "certificate lint e_sub_cert_key_usage_cert_sign_bit_set: non-CA certificate has the keyCertSign key usage")
+	require.Contains(t, resp.Warnings, `certificate lint w_subject_common_name_not_from_san: common name "www.example.com" is not one of the subject alternative names`)
+}
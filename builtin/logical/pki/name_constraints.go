@@ -0,0 +1,161 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// checkIssuerNameConstraints validates the SANs of a leaf certificate about
+// to be issued against the name constraints of its issuer and of the rest of
+// the issuer's chain, the same way clients will when verifying it. Without
+// this, Vault would happily issue certificates no client accepts.
+func checkIssuerNameConstraints(caSign *certutil.CAInfoBundle, creation *certutil.CreationBundle) error {
+	dnsNames := creation.Params.DNSNames
+	emailAddresses := creation.Params.EmailAddresses
+	ipAddresses := creation.Params.IPAddresses
+	uris := creation.Params.URIs
+	if creation.Params.UseCSRValues && creation.CSR != nil {
+		dnsNames = creation.CSR.DNSNames
+		emailAddresses = creation.CSR.EmailAddresses
+		ipAddresses = creation.CSR.IPAddresses
+		uris = creation.CSR.URIs
+	}
+
+	constrainingCerts := []*x509.Certificate{caSign.Certificate}
+	for _, block := range caSign.CAChain {
+		if block.Certificate != nil && !block.Certificate.Equal(caSign.Certificate) {
+			constrainingCerts = append(constrainingCerts, block.Certificate)
+		}
+	}
+
+	for _, caCert := range constrainingCerts {
+		violation := func(kind string, name string, reason string) error {
+			return errutil.UserError{Err: fmt.Sprintf("%v %q %v by the name constraints of CA certificate %q", kind, name, reason, caCert.Subject.CommonName)}
+		}
+
+		for _, name := range dnsNames {
+			if !matchesAnyConstraint(name, caCert.PermittedDNSDomains, matchDomainConstraint, true) {
+				return violation("DNS name", name, "is not permitted")
+			}
+			if matchesAnyConstraint(name, caCert.ExcludedDNSDomains, matchDomainConstraint, false) {
+				return violation("DNS name", name, "is excluded")
+			}
+		}
+
+		for _, email := range emailAddresses {
+			if !matchesAnyConstraint(email, caCert.PermittedEmailAddresses, matchEmailConstraint, true) {
+				return violation("email address", email, "is not permitted")
+			}
+			if matchesAnyConstraint(email, caCert.ExcludedEmailAddresses, matchEmailConstraint, false) {
+				return violation("email address", email, "is excluded")
+			}
+		}
+
+		for _, ip := range ipAddresses {
+			if !matchesAnyIPRange(ip, caCert.PermittedIPRanges, true) {
+				return violation("IP address", ip.String(), "is not permitted")
+			}
+			if matchesAnyIPRange(ip, caCert.ExcludedIPRanges, false) {
+				return violation("IP address", ip.String(), "is excluded")
+			}
+		}
+
+		for _, uri := range uris {
+			if len(caCert.PermittedURIDomains) == 0 && len(caCert.ExcludedURIDomains) == 0 {
+				continue
+			}
+
+			// URI constraints only apply to domain names; clients reject
+			// URIs with IP address hosts outright under them.
+			if net.ParseIP(uri.Hostname()) != nil || uri.Hostname() == "" {
+				return violation("URI", uri.String(), "cannot be checked")
+			}
+			if !matchesAnyConstraint(uri.Hostname(), caCert.PermittedURIDomains, matchDomainConstraint, true) {
+				return violation("URI", uri.String(), "is not permitted")
+			}
+			if matchesAnyConstraint(uri.Hostname(), caCert.ExcludedURIDomains, matchDomainConstraint, false) {
+				return violation("URI", uri.String(), "is excluded")
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchesAnyConstraint returns whether name matches any of the given
+// constraints, or emptyMatches when there are none: an empty list of
+// permitted names permits everything, while one of excluded names excludes
+// nothing.
+func matchesAnyConstraint(name string, constraints []string, match func(string, string) bool, emptyMatches bool) bool {
+	if len(constraints) == 0 {
+		return emptyMatches
+	}
+
+	for _, constraint := range constraints {
+		if match(name, constraint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAnyIPRange(ip net.IP, ranges []*net.IPNet, emptyMatches bool) bool {
+	if len(ranges) == 0 {
+		return emptyMatches
+	}
+
+	for _, ipRange := range ranges {
+		candidate := ip
+		if len(ipRange.IP) == net.IPv4len {
+			candidate = ip.To4()
+		}
+		if candidate != nil && len(candidate) == len(ipRange.IP) && ipRange.Contains(candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchDomainConstraint follows RFC 5280 Section 4.2.1.10: a constraint
+// matches the domain itself and its subdomains, while a constraint with a
+// leading period only matches subdomains.
+func matchDomainConstraint(domain string, constraint string) bool {
+	if constraint == "" {
+		return true
+	}
+
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	constraint = strings.ToLower(constraint)
+
+	if strings.HasPrefix(constraint, ".") {
+		return strings.HasSuffix(domain, constraint)
+	}
+
+	return domain == constraint || strings.HasSuffix(domain, "."+constraint)
+}
+
+// matchEmailConstraint matches a full mailbox when the constraint contains
+// an @, and otherwise the domain part of the address.
+func matchEmailConstraint(email string, constraint string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+
+	if strings.Contains(constraint, "@") {
+		constraintAt := strings.LastIndex(constraint, "@")
+		return email[:at] == constraint[:constraintAt] && strings.EqualFold(email[at+1:], constraint[constraintAt+1:])
+	}
+
+	return matchDomainConstraint(email[at+1:], constraint)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchNameConstraints(t *testing.T) {
+	t.Parallel()
+
+	domainCases := []struct {
+		domain     string
+		constraint string
+		expected   bool
+	}{
+		{"example.com", "example.com", true},
+		{"www.example.com", "example.com", true},
+		{"WWW.Example.COM", "example.com", true},
+		{"*.example.com", "example.com", true},
+		{"badexample.com", "example.com", false},
+		{"example.com", ".example.com", false},
+		{"www.example.com", ".example.com", true},
+		{"example.org", "example.com", false},
+		{"anything.org", "", true},
+	}
+	for _, tc := range domainCases {
+		require.Equal(t, tc.expected, matchDomainConstraint(tc.domain, tc.constraint), "domain %q constraint %q", tc.domain, tc.constraint)
+	}
+
+	emailCases := []struct {
+		email      string
+		constraint string
+		expected   bool
+	}{
+		{"user@example.com", "example.com", true},
+		{"user@mail.example.com", "example.com", true},
+		{"user@mail.example.com", ".example.com", true},
+		{"user@example.com", ".example.com", false},
+		{"user@example.com", "user@EXAMPLE.com", true},
+		{"other@example.com", "user@example.com", false},
+		{"user@example.org", "example.com", false},
+		{"not-an-email", "example.com", false},
+	}
+	for _, tc := range emailCases {
+		require.Equal(t, tc.expected, matchEmailConstraint(tc.email, tc.constraint), "email %q constraint %q", tc.email, tc.constraint)
+	}
+
+	_, v4Range, _ := net.ParseCIDR("10.0.0.0/8")
+	_, v6Range, _ := net.ParseCIDR("2001:db8::/32")
+	ranges := []*net.IPNet{v4Range, v6Range}
+	require.True(t, matchesAnyIPRange(net.ParseIP("10.1.2.3"), ranges, false))
+	require.True(t, matchesAnyIPRange(net.ParseIP("2001:db8::1"), ranges, false))
+	require.False(t, matchesAnyIPRange(net.ParseIP("192.168.0.1"), ranges, false))
+	require.False(t, matchesAnyIPRange(net.ParseIP("::ffff:192.168.0.1"), []*net.IPNet{v6Range}, false))
+	require.True(t, matchesAnyIPRange(net.ParseIP("192.168.0.1"), nil, true))
+	require.False(t, matchesAnyIPRange(net.ParseIP("192.168.0.1"), nil, false))
+}
+
+func TestPKI_IssuerNameConstraintsEnforced(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name":               "Constrained Root",
+		"key_type":                  "ec",
+		"ttl":                       "40h",
+		"permitted_dns_domains":     "example.com",
+		"excluded_dns_domains":      "secret.example.com",
+		"permitted_ip_ranges":       "10.0.0.0/8",
+		"permitted_email_addresses": "example.com",
+		"permitted_uri_domains":     ".example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/any", map[string]interface{}{
+		"allow_any_name":    true,
+		"enforce_hostnames": false,
+		"allowed_uri_sans":  "*",
+		"key_type":          "ec",
+		"no_store":          true,
+	})
+	require.NoError(t, err)
+
+	issue := func(data map[string]interface{}) error {
+		data["ttl"] = "1h"
+		_, err := CBWrite(b, s, "issue/any", data)
+		return err
+	}
+
+	require.NoError(t, issue(map[string]interface{}{
+		"common_name": "www.example.com",
+		"alt_names":   "example.com,*.api.example.com",
+		"ip_sans":     "10.1.2.3",
+		"uri_sans":    "spiffe://svc.example.com/workload",
+	}))
+	require.NoError(t, issue(map[string]interface{}{
+		"common_name": "user@mail.example.com",
+	}))
+
+	require.ErrorContains(t, issue(map[string]interface{}{
+		"common_name": "www.example.org",
+	}), `DNS name "www.example.org" is not permitted by the name constraints of CA certificate "Constrained Root"`)
+	require.ErrorContains(t, issue(map[string]interface{}{
+		"common_name": "db.secret.example.com",
+	}), `DNS name "db.secret.example.com" is excluded`)
+	require.ErrorContains(t, issue(map[string]interface{}{
+		"common_name": "www.example.com",
+		"ip_sans":     "192.168.1.1",
+	}), `IP address "192.168.1.1" is not permitted`)
+	require.ErrorContains(t, issue(map[string]interface{}{
+		"common_name": "www.example.com",
+		"uri_sans":    "spiffe://example.org/workload",
+	}), `URI "spiffe://example.org/workload" is not permitted`)
+	require.ErrorContains(t, issue(map[string]interface{}{
+		"common_name": "user@example.org",
+	}), `email address "user@example.org" is not permitted`)
+
+	// Names excluded from the SANs aren't checked.
+	require.NoError(t, issue(map[string]interface{}{
+		"common_name":          "Some Service",
+		"exclude_cn_from_sans": true,
+	}))
+
+	// Names taken from the CSR are checked the same way on /sign.
+	_, err = CBWrite(b, s, "roles/csr-sans", map[string]interface{}{
+		"allow_any_name":    true,
+		"enforce_hostnames": false,
+		"use_csr_sans":      true,
+		"key_type":          "any",
+		"no_store":          true,
+	})
+	require.NoError(t, err)
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "www.example.com"},
+		DNSNames: []string{"www.example.com", "www.example.net"},
+	}, "ec", 256)
+	_, err = CBWrite(b, s, "sign/csr-sans", map[string]interface{}{
+		"csr": csrPem,
+		"ttl": "1h",
+	})
+	require.ErrorContains(t, err, `DNS name "www.example.net" is not permitted`)
+
+	_, _, csrPem = generateCSR(t, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "www.example.com"},
+		DNSNames: []string{"www.example.com", "api.example.com"},
+	}, "ec", 256)
+	resp, err = CBWrite(b, s, "sign/csr-sans", map[string]interface{}{
+		"csr": csrPem,
+		"ttl": "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign/csr-sans")
+	require.ElementsMatch(t, []string{"www.example.com", "api.example.com"}, parseCert(t, resp.Data["certificate"].(string)).DNSNames)
+
+	_, err = CBWrite(b, s, "sign-verbatim", map[string]interface{}{
+		"csr": csrPem,
+		"ttl": "1h",
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ocsp"
+)
+
+// The number of signed OCSP responses kept in memory per mount, on each
+// node; at a few hundred bytes per response, this bounds the cache to a
+// handful of megabytes.
+const ocspCacheSize = 16384
+
+type ocspCacheEntry struct {
+	response []byte
+	expires  time.Time
+}
+
+// ocspResponseCache holds signed OCSP responses to avoid looking up the
+// certificate's status and signing a new response on every request. It is
+// purged whenever a certificate is revoked, or the issuers or the revocation
+// configuration change, so cached responses never lag behind storage.
+type ocspResponseCache struct {
+	backendUUID string
+	cache       *lru.Cache
+}
+
+func newOcspResponseCache(backendUUID string) *ocspResponseCache {
+	// lru.New only fails on non-positive sizes.
+	cache, _ := lru.New(ocspCacheSize)
+	return &ocspResponseCache{
+		backendUUID: backendUUID,
+		cache:       cache,
+	}
+}
+
+func ocspCacheKey(req *ocsp.Request, useUnifiedStorage bool) string {
+	return fmt.Sprintf("%x/%x/%d/%x/%t", req.IssuerNameHash, req.IssuerKeyHash, req.HashAlgorithm, req.SerialNumber.Bytes(), useUnifiedStorage)
+}
+
+func (c *ocspResponseCache) get(key string) []byte {
+	if raw, ok := c.cache.Get(key); ok {
+		entry := raw.(*ocspCacheEntry)
+		if time.Now().Before(entry.expires) {
+			metrics.IncrCounter([]string{"secrets", "pki", c.backendUUID, "ocsp_cache_hit"}, 1)
+			return entry.response
+		}
+
+		c.cache.Remove(key)
+	}
+
+	metrics.IncrCounter([]string{"secrets", "pki", c.backendUUID, "ocsp_cache_miss"}, 1)
+	return nil
+}
+
+func (c *ocspResponseCache) put(key string, response []byte, ttl time.Duration) {
+	c.cache.Add(key, &ocspCacheEntry{
+		response: response,
+		expires:  time.Now().Add(ttl),
+	})
+}
+
+func (c *ocspResponseCache) purge() {
+	c.cache.Purge()
+}
+
+// ocspCacheTTL returns how long signed OCSP responses may be cached for, or
+// zero if caching is disabled. Responses are never cached past their own
+// NextUpdate.
+func (cfg *crlConfig) ocspCacheTTL() time.Duration {
+	if cfg.OcspDisable {
+		return 0
+	}
+
+	ttl, err := parseutil.ParseDurationSecond(cfg.OcspCacheTTL)
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+
+	expiry, err := parseutil.ParseDurationSecond(cfg.OcspExpiry)
+	if err != nil {
+		return 0
+	}
+
+	if expiry < ttl {
+		return expiry
+	}
+	return ttl
+}
+
+// pregenerateOcspResponse signs and caches the OCSP response for a newly
+// issued certificate, as requested by RFC 5019 clients (with SHA-1 hashed
+// issuer identifiers), ahead of the first request for it. Failures are not
+// fatal to issuance; the response is then generated on request instead.
+func pregenerateOcspResponse(sc *storageContext, cert *x509.Certificate, issuerCert *x509.Certificate) {
+	b := sc.Backend
+
+	cfg, err := b.crlBuilder.getConfigWithUpdate(sc)
+	if err != nil || !cfg.OcspPregenerate {
+		return
+	}
+
+	cacheTTL := cfg.ocspCacheTTL()
+	if cacheTTL <= 0 || cert == nil || issuerCert == nil {
+		return
+	}
+
+	derReq, err := ocsp.CreateRequest(cert, issuerCert, &ocsp.RequestOptions{Hash: crypto.SHA1})
+	if err != nil {
+		b.Logger().Debug("failed to pre-generate OCSP response", "error", err)
+		return
+	}
+
+	ocspReq, err := ocsp.ParseRequest(derReq)
+	if err != nil {
+		b.Logger().Debug("failed to pre-generate OCSP response", "error", err)
+		return
+	}
+
+	// Pre-generate for the existing /ocsp path.
+	useUnifiedStorage := shouldLocalPathsUseUnified(cfg)
+	resp := respondToOcspRequest(sc, cfg, ocspReq, useUnifiedStorage)
+	if resp.Data[logical.HTTPStatusCode] != http.StatusOK {
+		return
+	}
+
+	b.ocspCache.put(ocspCacheKey(ocspReq, useUnifiedStorage), resp.Data[logical.HTTPRawBody].([]byte), cacheTTL)
+}
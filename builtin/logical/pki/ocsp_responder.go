@@ -0,0 +1,291 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Delegated OCSP responders are stored in cluster-local storage, as each
+// cluster signs its own OCSP responses, similar to how each cluster builds
+// its own CRLs.
+const ocspResponderPrefix = "ocsp-responders/"
+
+// RFC 6960 Section 4.2.2.2.1:
+//
+// > id-pkix-ocsp-nocheck OBJECT IDENTIFIER ::= { id-pkix-ocsp 5 }
+var ocspNoCheckOID = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 48, 1, 5}
+
+type ocspResponderEntry struct {
+	Certificate  string    `json:"certificate"`
+	PrivateKey   string    `json:"private_key"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+}
+
+// needsRotation reports whether half of the responder's lifetime has
+// elapsed, leaving at least the other half to sign responses until the
+// periodic function gets to replace it.
+func (e *ocspResponderEntry) needsRotation(now time.Time) bool {
+	lifetime := e.NotAfter.Sub(e.NotBefore)
+	return now.After(e.NotBefore.Add(lifetime / 2))
+}
+
+func (e *ocspResponderEntry) toParsedBundle() (*certutil.ParsedCertBundle, error) {
+	bundle := &certutil.CertBundle{
+		Certificate: e.Certificate,
+		PrivateKey:  e.PrivateKey,
+	}
+
+	return bundle.ToParsedCertBundle()
+}
+
+func (sc *storageContext) fetchOcspResponder(issuerId issuerID) (*ocspResponderEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, ocspResponderPrefix+issuerId.String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch OCSP responder for issuer %v: %w", issuerId, err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var responder ocspResponderEntry
+	if err := entry.DecodeJSON(&responder); err != nil {
+		return nil, fmt.Errorf("unable to decode OCSP responder for issuer %v: %w", issuerId, err)
+	}
+
+	return &responder, nil
+}
+
+func (sc *storageContext) writeOcspResponder(issuerId issuerID, responder *ocspResponderEntry) error {
+	entry, err := logical.StorageEntryJSON(ocspResponderPrefix+issuerId.String(), responder)
+	if err != nil {
+		return err
+	}
+
+	if err := sc.Storage.Put(sc.Context, entry); err != nil {
+		return err
+	}
+
+	sc.Backend.ocspCache.purge()
+	return nil
+}
+
+// getOcspResponseSigner returns the bundle OCSP responses for the given
+// issuer should be signed with: its CRL signer if allowed to sign OCSP
+// responses, its delegated responder if enabled and valid for the whole
+// lifetime of the response, or otherwise the issuer itself. The issuer's key
+// is only loaded when it signs the response; lacking a valid delegated
+// responder, it only does so when the fallback is enabled.
+func getOcspResponseSigner(sc *storageContext, cfg *crlConfig, issuer *issuerEntry) (*certutil.ParsedCertBundle, error) {
+	if issuer.CRLSigner != nil {
+		signer, err := sc.fetchCRLSigner(issuer)
+		if err != nil {
+			return nil, err
+		}
+		for _, usage := range signer.Certificate.ExtKeyUsage {
+			if usage == x509.ExtKeyUsageOCSPSigning {
+				return signer, nil
+			}
+		}
+	}
+
+	if !cfg.OcspDelegatedResponder {
+		return fetchOcspIssuerSigner(sc, issuer.ID)
+	}
+
+	responder, err := sc.fetchOcspResponder(issuer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responseExpiry, err := parseutil.ParseDurationSecond(cfg.OcspExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	if responder == nil || time.Now().Add(responseExpiry).After(responder.NotAfter) {
+		if !cfg.OcspResponderFallback {
+			return nil, fmt.Errorf("no valid delegated OCSP responder for issuer %v; responders are issued by the periodic function or when updating the CRL configuration", issuer.ID)
+		}
+
+		sc.Backend.Logger().Warn("no valid delegated OCSP responder; signing with issuer", "issuer", issuer.ID)
+		return fetchOcspIssuerSigner(sc, issuer.ID)
+	}
+
+	return responder.toParsedBundle()
+}
+
+// fetchOcspIssuerSigner loads the issuer, with its key, for signing OCSP
+// responses itself.
+func fetchOcspIssuerSigner(sc *storageContext, issuerId issuerID) (*certutil.ParsedCertBundle, error) {
+	_, bundle, err := sc.fetchCertBundleByIssuerId(issuerId, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCABundle(sc.Context, sc.Backend, bundle)
+}
+
+// rotateOcspResponders issues delegated OCSP responders for all issuers
+// which are allowed to sign OCSP responses and lack a responder or whose
+// responder needs rotation, or all of them when force is set.
+func rotateOcspResponders(sc *storageContext, cfg *crlConfig, force bool) error {
+	if !cfg.OcspDelegatedResponder || cfg.OcspDisable || sc.Backend.useLegacyBundleCaStorage() {
+		return nil
+	}
+
+	ttl, err := parseutil.ParseDurationSecond(cfg.OcspResponderTTL)
+	if err != nil {
+		return fmt.Errorf("unable to parse OCSP responder lifetime: %w", err)
+	}
+
+	issuers, err := sc.listIssuers()
+	if err != nil {
+		return fmt.Errorf("unable to list issuers: %w", err)
+	}
+
+	now := time.Now()
+	for _, issuerId := range issuers {
+		issuer, err := sc.fetchIssuerById(issuerId)
+		if err != nil {
+			return err
+		}
+
+		// Issuers with a CRL signer shouldn't have their key used to issue
+		// responders; they answer with the signer or, failing that, sign
+		// responses themselves.
+		if issuer.KeyID == "" || issuer.Revoked || issuer.CRLSigner != nil || !issuer.Usage.HasUsage(OCSPSigningUsage) {
+			continue
+		}
+
+		if !force {
+			responder, err := sc.fetchOcspResponder(issuerId)
+			if err != nil {
+				return err
+			}
+
+			if responder != nil && !responder.needsRotation(now) {
+				// Replace responders revoked by an operator right away.
+				revoked, err := fetchCertBySerial(sc, revokedPath, responder.SerialNumber)
+				if err != nil {
+					return err
+				}
+				if revoked == nil {
+					continue
+				}
+			}
+		}
+
+		responder, err := issueOcspResponder(sc, issuerId, ttl)
+		if err != nil {
+			return fmt.Errorf("unable to issue OCSP responder for issuer %v: %w", issuerId, err)
+		}
+
+		if err := sc.writeOcspResponder(issuerId, responder); err != nil {
+			return fmt.Errorf("unable to store OCSP responder for issuer %v: %w", issuerId, err)
+		}
+	}
+
+	return nil
+}
+
+func issueOcspResponder(sc *storageContext, issuerId issuerID, ttl time.Duration) (*ocspResponderEntry, error) {
+	signingBundle, err := sc.fetchCAInfoByIssuerId(issuerId, OCSPSigningUsage)
+	if err != nil {
+		return nil, err
+	}
+	caCert := signingBundle.Certificate
+
+	// Use a key of the same type and size as the issuer's, so the issuer's
+	// revocation signature algorithm applies to the responder as well.
+	var key crypto.Signer
+	switch pub := caCert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		key, err = rsa.GenerateKey(rand.Reader, pub.N.BitLen())
+	case *ecdsa.PublicKey:
+		key, err = ecdsa.GenerateKey(pub.Curve, rand.Reader)
+	case ed25519.PublicKey:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unsupported issuer key type %T", pub)}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate responder key: %w", err)
+	}
+
+	serialNumber, err := certutil.GenerateSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(ttl)
+	if caCert.NotAfter.Before(notAfter) {
+		notAfter = caCert.NotAfter
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName: strings.TrimSpace(caCert.Subject.CommonName + " OCSP Responder"),
+		},
+		NotBefore:             now.Add(-30 * time.Second),
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+		BasicConstraintsValid: true,
+		// Responders are short lived and can't be checked through the OCSP
+		// responses they sign themselves.
+		ExtraExtensions: []pkix.Extension{
+			{Id: ocspNoCheckOID, Value: asn1.NullBytes},
+		},
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), signingBundle.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign responder certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(certBytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse responder certificate: %w", err)
+	}
+
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal responder key: %w", err)
+	}
+
+	// Track the responder like any other issued certificate, so it shows up
+	// in certs/ listings and can be revoked.
+	if err := storeCertificate(sc, &certutil.ParsedCertBundle{Certificate: cert, CertificateBytes: certBytes}); err != nil {
+		return nil, err
+	}
+
+	return &ocspResponderEntry{
+		Certificate:  strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}))),
+		PrivateKey:   strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes}))),
+		SerialNumber: serialFromCert(cert),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+	}, nil
+}
@@ -259,6 +259,12 @@ func (b *backend) acmeFinalizeOrderHandler(ac *acmeContext, r *logical.Request,
 		return nil, err
 	}
 
+	release, err := b.acquireIssuanceLimits(ac.sc, ac.role)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRateLimited, err)
+	}
+	defer release()
+
 	var signedCertBundle *certutil.ParsedCertBundle
 	var issuerId issuerID
 	if ac.runtimeOpts.isCiepsEnabled {
@@ -278,8 +284,22 @@ func (b *backend) acmeFinalizeOrderHandler(ac *acmeContext, r *logical.Request,
 		if err != nil {
 			return nil, err
 		}
+
+		metadata := &certMetadataEntry{
+			Role:     ac.role.Name,
+			IssuerId: issuerId,
+		}
+		if err := ac.sc.writeCertMetadata(serialFromCert(signedCertBundle.Certificate), metadata); err != nil {
+			return nil, fmt.Errorf("unable to store certificate metadata: %w", err)
+		}
+
+		if len(signedCertBundle.CAChain) > 0 {
+			pregenerateOcspResponse(ac.sc, signedCertBundle.Certificate, signedCertBundle.CAChain[0].Certificate)
+		}
 	}
 	hyphenSerialNumber := normalizeSerialFromBigInt(signedCertBundle.Certificate.SerialNumber)
+	b.sendCertEvent(ac.sc.Context, eventTypeCertSign, signedCertBundle.Certificate, ac.role.Name, issuerId, r.EntityID)
+	b.countIssuedCertificate(ac.role.Name)
 
 	if err := b.acmeState.TrackIssuedCert(ac, order.AccountId, hyphenSerialNumber, order.OrderId); err != nil {
 		b.Logger().Warn("orphaned generated ACME certificate due to error saving account->cert->order reference", "serial_number", hyphenSerialNumber, "error", err)
@@ -432,6 +452,10 @@ func (b *backend) validateIdentifiersAgainstRole(role *roleEntry, identifiers []
 				return fmt.Errorf("%w: role (%s) does not allow IP sans, so cannot issue certificate for %v",
 					ErrRejectedIdentifier, role.Name, identifier.OriginalValue)
 			}
+			if ip := net.ParseIP(identifier.Value); ip == nil || !validateIPSAN(role, ip) {
+				return fmt.Errorf("%w: role (%s) will not issue certificate for IP %v",
+					ErrRejectedIdentifier, role.Name, identifier.OriginalValue)
+			}
 		default:
 			return fmt.Errorf("unknown type of identifier: %v for %v", identifier.Type, identifier.OriginalValue)
 		}
@@ -550,11 +574,20 @@ func issueCertFromCsr(ac *acmeContext, csr *x509.CertificateRequest) (*certutil.
 	if signingBundle.LeafNotAfterBehavior == certutil.ErrNotAfterBehavior {
 		signingBundle.LeafNotAfterBehavior = certutil.TruncateNotAfterBehavior
 	}
+	role := ac.role
+	if role.LeafNotAfterBehavior == certutil.ErrNotAfterBehavior.String() {
+		acmeRole := *ac.role
+		acmeRole.LeafNotAfterBehavior = certutil.TruncateNotAfterBehavior.String()
+		role = &acmeRole
+	}
 
 	input := &inputBundle{
 		req:     &logical.Request{},
 		apiData: data,
-		role:    ac.role,
+		role:    role,
+	}
+	if err := loadPolicyWebhook(ac.sc, input); err != nil {
+		return nil, "", err
 	}
 
 	normalNotAfter, _, err := getCertificateNotAfter(ac.sc.Backend, input, signingBundle)
@@ -584,7 +617,7 @@ func issueCertFromCsr(ac *acmeContext, csr *x509.CertificateRequest) (*certutil.
 	// unit, we have no way of validating this (via ACME here, without perhaps
 	// an external policy engine), and thus should not be setting it on our
 	// final issued certificate.
-	parsedBundle, _, err := signCert(ac.sc.Backend, input, signingBundle, false /* is_ca=false */, false /* use_csr_values */)
+	parsedBundle, _, err := signCert(ac.sc.Context, ac.sc.Backend, input, signingBundle, false /* is_ca=false */, false /* use_csr_values */)
 	if err != nil {
 		return nil, "", fmt.Errorf("%w: refusing to sign CSR: %s", ErrBadCSR, err.Error())
 	}
@@ -763,14 +796,18 @@ func (b *backend) acmeNewOrderHandler(ac *acmeContext, _ *logical.Request, _ *fr
 	// IF/WHEN we support pre-authz workflows and associate existing authorizations to this
 	// order they will need filtering.
 	var authorizations []*ACMEAuthorization
-	var authorizationIds []string
 	for _, identifier := range identifiers {
-		authz, err := generateAuthorization(account, identifier)
+		authz, err := generateAuthorization(account, identifier, ac.role)
 		if err != nil {
 			return nil, fmt.Errorf("error generating authorizations: %w", err)
 		}
 		authorizations = append(authorizations, authz)
+	}
 
+	// Only store the authorizations once all of them were generated, so that
+	// an identifier rejected by the role doesn't leave orphaned ones behind.
+	var authorizationIds []string
+	for _, authz := range authorizations {
 		err = b.acmeState.SaveAuthorization(ac, authz)
 		if err != nil {
 			return nil, fmt.Errorf("failed storing authorization: %w", err)
@@ -865,7 +902,7 @@ func buildOrderUrl(acmeCtx *acmeContext, orderId string) string {
 	return acmeCtx.baseUrl.JoinPath("order", orderId).String()
 }
 
-func generateAuthorization(acct *acmeAccount, identifier *ACMEIdentifier) (*ACMEAuthorization, error) {
+func generateAuthorization(acct *acmeAccount, identifier *ACMEIdentifier, role *roleEntry) (*ACMEAuthorization, error) {
 	authId := genUuid()
 
 	// Certain challenges have certain restrictions: DNS challenges cannot
@@ -878,6 +915,23 @@ func generateAuthorization(acct *acmeAccount, identifier *ACMEIdentifier) (*ACME
 		allowedChallenges = []ACMEChallengeType{ACMEDNSChallenge}
 	}
 
+	// The role may further restrict the challenges usable to validate
+	// identifiers.
+	if role != nil && len(role.AllowedACMEChallenges) > 0 {
+		var roleChallenges []ACMEChallengeType
+		for _, challengeType := range allowedChallenges {
+			if strutil.StrListContains(role.AllowedACMEChallenges, string(challengeType)) {
+				roleChallenges = append(roleChallenges, challengeType)
+			}
+		}
+
+		if len(roleChallenges) == 0 {
+			return nil, fmt.Errorf("%w: role (%s) allows no challenge type able to validate identifier %v",
+				ErrRejectedIdentifier, role.Name, identifier.OriginalValue)
+		}
+		allowedChallenges = roleChallenges
+	}
+
 	var challenges []*ACMEChallenge
 	for _, challengeType := range allowedChallenges {
 		token, err := getACMEToken()
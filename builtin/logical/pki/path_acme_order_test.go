@@ -95,6 +95,69 @@ func TestACME_ValidateIdentifiersAgainstRole(t *testing.T) {
 	}
 }
 
+// TestACME_GenerateAuthorizationRoleChallenges verifies the challenges offered
+// in generated authorizations are restricted to the ones allowed by the role.
+func TestACME_GenerateAuthorizationRoleChallenges(t *testing.T) {
+	acct := &acmeAccount{KeyId: genUuid()}
+
+	tests := []struct {
+		name       string
+		role       *roleEntry
+		identifier *ACMEIdentifier
+		expected   []ACMEChallengeType
+		expectErr  bool
+	}{
+		{
+			name:       "default-role-allows-all",
+			role:       buildTestRole(t, nil),
+			identifier: _buildACMEIdentifier("www.test.com"),
+			expected:   []ACMEChallengeType{ACMEHTTPChallenge, ACMEDNSChallenge, ACMEALPNChallenge},
+		},
+		{
+			name:       "role-restricts-dns-identifier",
+			role:       buildTestRole(t, map[string]interface{}{"allowed_acme_challenges": []string{"dns-01", "tls-alpn-01"}}),
+			identifier: _buildACMEIdentifier("www.test.com"),
+			expected:   []ACMEChallengeType{ACMEDNSChallenge, ACMEALPNChallenge},
+		},
+		{
+			name:       "role-allows-wildcard",
+			role:       buildTestRole(t, map[string]interface{}{"allowed_acme_challenges": []string{"dns-01"}}),
+			identifier: _buildACMEIdentifier("*.test.com"),
+			expected:   []ACMEChallengeType{ACMEDNSChallenge},
+		},
+		{
+			name:       "role-forbids-wildcard",
+			role:       buildTestRole(t, map[string]interface{}{"allowed_acme_challenges": []string{"http-01"}}),
+			identifier: _buildACMEIdentifier("*.test.com"),
+			expectErr:  true,
+		},
+		{
+			name:       "role-forbids-ip",
+			role:       buildTestRole(t, map[string]interface{}{"allowed_acme_challenges": []string{"dns-01"}}),
+			identifier: _buildACMEIdentifier("192.168.0.1"),
+			expectErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authz, err := generateAuthorization(acct, tt.identifier, tt.role)
+
+			if tt.expectErr {
+				require.Error(t, err)
+				require.ErrorIs(t, err, ErrRejectedIdentifier)
+				return
+			}
+
+			require.NoError(t, err)
+			var challengeTypes []ACMEChallengeType
+			for _, challenge := range authz.Challenges {
+				challengeTypes = append(challengeTypes, challenge.Type)
+			}
+			require.Equal(t, tt.expected, challengeTypes)
+		})
+	}
+}
+
 func _buildACMEIdentifiers(values ...string) []*ACMEIdentifier {
 	var identifiers []*ACMEIdentifier
 
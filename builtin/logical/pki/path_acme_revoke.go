@@ -13,6 +13,7 @@ import (
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ocsp"
 )
 
 func pathAcmeRevoke(b *backend, baseUrl string, opts acmeWrapperOpts) *framework.Path {
@@ -63,15 +64,17 @@ func (b *backend) acmeRevocationHandler(acmeCtx *acmeContext, _ *logical.Request
 		return nil, fmt.Errorf("bad request was lacking required field 'certificate': %w", ErrMalformed)
 	}
 
+	reason := ocsp.Unspecified
 	rawReason, present := data["reason"]
 	if present {
-		reason, ok := rawReason.(float64)
+		rawCode, ok := rawReason.(float64)
 		if !ok {
 			return nil, fmt.Errorf("invalid type (%T; expected float64) for field 'reason': %w", rawReason, ErrMalformed)
 		}
 
-		if int(reason) != 0 {
-			return nil, fmt.Errorf("Vault does not support revocation reasons (got %v; expected omitted or 0/unspecified): %w", int(reason), ErrBadRevocationReason)
+		reason = int(rawCode)
+		if float64(reason) != rawCode || revocationReasonName(reason) == "" {
+			return nil, fmt.Errorf("Vault does not support revocation reason %v (certificateHold and removeFromCRL are not supported): %w", rawCode, ErrBadRevocationReason)
 		}
 	}
 
@@ -127,13 +130,13 @@ func (b *backend) acmeRevocationHandler(acmeCtx *acmeContext, _ *logical.Request
 	// Finally, do the relevant permissions/authorization check as
 	// appropriate based on the type of revocation happening.
 	if !userCtx.Existing {
-		return b.acmeRevocationByPoP(acmeCtx, userCtx, cert, config)
+		return b.acmeRevocationByPoP(acmeCtx, userCtx, cert, config, reason)
 	}
 
-	return b.acmeRevocationByAccount(acmeCtx, userCtx, cert, config)
+	return b.acmeRevocationByAccount(acmeCtx, userCtx, cert, config, reason)
 }
 
-func (b *backend) acmeRevocationByPoP(acmeCtx *acmeContext, userCtx *jwsCtx, cert *x509.Certificate, config *crlConfig) (*logical.Response, error) {
+func (b *backend) acmeRevocationByPoP(acmeCtx *acmeContext, userCtx *jwsCtx, cert *x509.Certificate, config *crlConfig, reason int) (*logical.Response, error) {
 	// Since this account does not exist, ensure we've gotten a private key
 	// matching the certificate's public key. This private key isn't
 	// explicitly provided, but instead provided by proxy (public key,
@@ -156,10 +159,10 @@ func (b *backend) acmeRevocationByPoP(acmeCtx *acmeContext, userCtx *jwsCtx, cer
 	b.revokeStorageLock.Lock()
 	defer b.revokeStorageLock.Unlock()
 
-	return revokeCert(acmeCtx.sc, config, cert)
+	return revokeCert(acmeCtx.sc, config, cert, "", reason)
 }
 
-func (b *backend) acmeRevocationByAccount(acmeCtx *acmeContext, userCtx *jwsCtx, cert *x509.Certificate, config *crlConfig) (*logical.Response, error) {
+func (b *backend) acmeRevocationByAccount(acmeCtx *acmeContext, userCtx *jwsCtx, cert *x509.Certificate, config *crlConfig, reason int) (*logical.Response, error) {
 	// Fetch the account; disallow revocations from non-valid-status accounts.
 	_, err := requireValidAcmeAccount(acmeCtx, userCtx)
 	if err != nil {
@@ -178,5 +181,5 @@ func (b *backend) acmeRevocationByAccount(acmeCtx *acmeContext, userCtx *jwsCtx,
 	b.revokeStorageLock.Lock()
 	defer b.revokeStorageLock.Unlock()
 
-	return revokeCert(acmeCtx.sc, config, cert)
+	return revokeCert(acmeCtx.sc, config, cert, "", reason)
 }
@@ -0,0 +1,500 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	cmpContentType        = "application/pkixcmp"
+	cmpMaximumRequestSize = 64 * 1024
+
+	pathCmpHelpSyn  = `An endpoint implementing the CMP enrollment protocol`
+	pathCmpHelpDesc = `This API endpoint implements initialization, certification and key
+update requests of the Certificate Management Protocol defined in RFC 4210,
+over the HTTP transfer defined in RFC 6712. Requests and responses are DER
+encoded PKIMessages rather than conventional Vault JSON, so a CMP client
+should be used to interact with this endpoint.
+
+The unlabeled cmp endpoint follows the default_path_policy of config/cmp,
+while cmp/<label> endpoints follow the policy configured for that label in
+label_to_path_policy. Initialization requests are authenticated by a MAC
+keyed with the configured shared secret or by a signature of a trusted vendor
+certificate; key update requests by a signature of the current certificate,
+issued by this mount.`
+)
+
+func setupCmpPaths(b *backend, cmpPattern string, unauthPattern string) {
+	b.Backend.Paths = append(b.Backend.Paths, pathCmp(b, cmpPattern))
+
+	// CMP requests are authenticated by their message protection rather
+	// than a Vault token.
+	b.PathsSpecial.Unauthenticated = append(b.PathsSpecial.Unauthenticated, unauthPattern)
+}
+
+func pathCmp(b *backend, pattern string) *framework.Path {
+	fields := map[string]*framework.FieldSchema{}
+	if strings.Contains(pattern, framework.GenericNameRegex("label")) {
+		fields["label"] = &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: `The CMP label, mapped to a path policy by label_to_path_policy in config/cmp`,
+			Required:    true,
+		}
+	}
+
+	return &framework.Path{
+		Pattern: pattern,
+		Fields:  fields,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathCmpOperation,
+			},
+		},
+
+		HelpSynopsis:    pathCmpHelpSyn,
+		HelpDescription: pathCmpHelpDesc,
+	}
+}
+
+func (b *backend) pathCmpOperation(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, role, issuer, err := getCmpRoleAndIssuer(sc, data)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	raw, err := readCmpRequest(req)
+	if err != nil {
+		return logical.ErrorResponse("failed reading CMP message: %v", err), nil
+	}
+
+	var msg cmpMessage
+	if rest, err := asn1.Unmarshal(raw, &msg); err != nil || len(rest) > 0 {
+		return logical.ErrorResponse("failed parsing CMP message"), nil
+	}
+
+	var header cmpHeader
+	if rest, err := asn1.Unmarshal(msg.Header.FullBytes, &header); err != nil || len(rest) > 0 {
+		return logical.ErrorResponse("failed parsing CMP message header"), nil
+	}
+
+	responder, err := newCmpResponder(sc, issuer, &header)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	return b.cmpHandleMessage(sc, req, config, role, issuer, &msg, &header, responder)
+}
+
+func getCmpRoleAndIssuer(sc *storageContext, data *framework.FieldData) (*cmpConfigEntry, *roleEntry, *issuerEntry, error) {
+	config, err := sc.getCmpConfig()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if !config.Enabled {
+		return nil, nil, nil, errutil.UserError{Err: "CMP is disabled on this mount"}
+	}
+
+	policy := config.DefaultPathPolicy
+	if labelRaw, ok := data.GetOk("label"); ok {
+		label := labelRaw.(string)
+		policy, ok = config.LabelToPathPolicy[label]
+		if !ok {
+			return nil, nil, nil, errutil.UserError{Err: fmt.Sprintf("unknown CMP label %q", label)}
+		}
+	}
+
+	role, issuer, err := getEnrollmentRoleAndIssuer(sc, "CMP", policy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return config, role, issuer, nil
+}
+
+func readCmpRequest(req *logical.Request) ([]byte, error) {
+	// NOTE: Writing an empty update request to Vault causes a nil request.HTTPRequest, and that object
+	//       says that it is possible for its Body element to be nil as well, so check both just in case.
+	if req.HTTPRequest == nil || req.HTTPRequest.Body == nil {
+		return nil, fmt.Errorf("no data in request body; the Content-Type must be %s", cmpContentType)
+	}
+	defer req.HTTPRequest.Body.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(req.HTTPRequest.Body, cmpMaximumRequestSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) >= cmpMaximumRequestSize {
+		return nil, errors.New("request is too large")
+	}
+
+	return raw, nil
+}
+
+func (b *backend) cmpHandleMessage(sc *storageContext, req *logical.Request, config *cmpConfigEntry, role *roleEntry, issuer *issuerEntry, msg *cmpMessage, header *cmpHeader, responder *cmpResponder) (*logical.Response, error) {
+	if header.Pvno != 2 && header.Pvno != 3 {
+		return responder.failure(cmpFailBadRequest, fmt.Sprintf("unsupported CMP protocol version %d", header.Pvno))
+	}
+
+	if len(header.TransactionID) == 0 || len(header.SenderNonce) == 0 {
+		return responder.failure(cmpFailBadDataFormat, "the transactionID and senderNonce header fields are required")
+	}
+
+	body := msg.Body
+	if body.Class != asn1.ClassContextSpecific || !body.IsCompound {
+		return responder.failure(cmpFailBadDataFormat, "malformed CMP message body")
+	}
+
+	sender, err := verifyCmpProtection(sc, config, issuer, msg, header)
+	if err != nil {
+		var userErr errutil.UserError
+		if errors.As(err, &userErr) {
+			return responder.failure(cmpFailBadMessageCheck, userErr.Err)
+		}
+		return nil, err
+	}
+
+	// RFC 4210 Section 5.1.3.1: responses to MAC protected requests are
+	// protected with the same shared secret.
+	if sender.sharedSecret {
+		responder.protection = newCmpPBMProtection([]byte(config.SharedSecret), header.ProtectionAlg)
+		responder.protection.extraCerts = responder.chain
+	}
+
+	switch body.Tag {
+	case cmpBodyIR:
+		if sender.issued {
+			return responder.failure(cmpFailBadRequest, "initialization requests must be protected with the shared secret or a vendor certificate; use a key update request instead")
+		}
+		return b.cmpIssue(sc, req, role, issuer, header, responder, body.Bytes, cmpBodyIP, nil)
+	case cmpBodyCR:
+		// Certification requests signed with a certificate of this issuer
+		// are restricted to its names, as key updates are; otherwise any
+		// holder of a leaf could request certificates for other identities.
+		var current *x509.Certificate
+		if sender.issued {
+			current = sender.cert
+		}
+		return b.cmpIssue(sc, req, role, issuer, header, responder, body.Bytes, cmpBodyCP, current)
+	case cmpBodyKUR:
+		if !sender.issued {
+			return responder.failure(cmpFailBadRequest, "key update requests must be signed with the current certificate")
+		}
+		return b.cmpIssue(sc, req, role, issuer, header, responder, body.Bytes, cmpBodyKUP, sender.cert)
+	case cmpBodyCertConf:
+		// Certificates are stored when issued, so confirmations are simply
+		// acknowledged.
+		var statuses []cmpCertStatus
+		if rest, err := asn1.Unmarshal(body.Bytes, &statuses); err != nil || len(rest) > 0 {
+			return responder.failure(cmpFailBadDataFormat, "malformed certificate confirmation")
+		}
+		return responder.respond(cmpBodyPKIConf, asn1.NullRawValue)
+	default:
+		return responder.failure(cmpFailBadRequest, fmt.Sprintf("unsupported CMP message body type %d", body.Tag))
+	}
+}
+
+// cmpSender describes how a request was authenticated.
+type cmpSender struct {
+	// sharedSecret is set when the message was MAC protected with the
+	// configured shared secret.
+	sharedSecret bool
+
+	// cert is the signer of signature protected messages; issued is set
+	// when it was issued by the requested issuer, and otherwise it chains
+	// to a trusted vendor certificate.
+	cert   *x509.Certificate
+	issued bool
+}
+
+func verifyCmpProtection(sc *storageContext, config *cmpConfigEntry, issuer *issuerEntry, msg *cmpMessage, header *cmpHeader) (*cmpSender, error) {
+	if len(msg.Protection.Bytes) == 0 {
+		return nil, errutil.UserError{Err: "CMP messages must be protected"}
+	}
+
+	protectedPart, err := cmpProtectedPart(msg.Header, msg.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.ProtectionAlg.Algorithm.Equal(oidCmpPasswordBasedMac) {
+		if config.SharedSecret == "" {
+			return nil, errutil.UserError{Err: "MAC based protection requires a shared secret to be configured"}
+		}
+
+		mac, err := cmpPasswordBasedMac([]byte(config.SharedSecret), header.ProtectionAlg, protectedPart)
+		if err != nil {
+			return nil, errutil.UserError{Err: err.Error()}
+		}
+		if !hmac.Equal(mac, msg.Protection.RightAlign()) {
+			return nil, errutil.UserError{Err: "MAC verification failed"}
+		}
+
+		return &cmpSender{sharedSecret: true}, nil
+	}
+
+	// RFC 4210 Section 5.1.1: the first certificate of extraCerts must be
+	// the one the message is protected with.
+	if len(msg.ExtraCerts) == 0 {
+		return nil, errutil.UserError{Err: "signature protected messages must carry the signer certificate in extraCerts"}
+	}
+
+	var certs []*x509.Certificate
+	for _, raw := range msg.ExtraCerts {
+		cert, err := x509.ParseCertificate(raw.FullBytes)
+		if err != nil {
+			return nil, errutil.UserError{Err: fmt.Sprintf("failed parsing extraCerts: %v", err)}
+		}
+		certs = append(certs, cert)
+	}
+
+	signer := certs[0]
+	if err := cmpCheckSignature(signer.PublicKey, header.ProtectionAlg, protectedPart, msg.Protection.RightAlign()); err != nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("signature verification failed: %v", err)}
+	}
+
+	err = verifyEnrollmentCertificate(sc, signer, issuer)
+	if err == nil {
+		return &cmpSender{cert: signer, issued: true}, nil
+	}
+	if !errors.Is(err, logical.ErrPermissionDenied) {
+		return nil, err
+	}
+
+	vendorRoots, err := parseCmpTrustedVendorCertificates(config.TrustedVendorCertificates)
+	if err != nil {
+		return nil, err
+	}
+	if vendorRoots != nil {
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		if _, err := signer.Verify(x509.VerifyOptions{
+			Roots:         vendorRoots,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err == nil {
+			return &cmpSender{cert: signer}, nil
+		}
+	}
+
+	return nil, errutil.UserError{Err: "the message signer is not trusted"}
+}
+
+func (b *backend) cmpIssue(sc *storageContext, req *logical.Request, role *roleEntry, issuer *issuerEntry, header *cmpHeader, responder *cmpResponder, content []byte, repType int, current *x509.Certificate) (*logical.Response, error) {
+	var reqMsgs []asn1.RawValue
+	if rest, err := asn1.Unmarshal(content, &reqMsgs); err != nil || len(rest) > 0 || len(reqMsgs) != 1 {
+		return responder.failure(cmpFailBadDataFormat, "exactly one certificate request message is supported")
+	}
+
+	certReqID, csr, failInfo, err := parseCmpCertReqMsg(reqMsgs[0].FullBytes)
+	if err != nil {
+		return responder.failure(failInfo, err.Error())
+	}
+
+	// Key updates, and certification requests signed with an issued
+	// certificate, must keep its subject, as with EST re-enrollment.
+	if current != nil {
+		if err := validateReenrollCsr(current, csr); err != nil {
+			return responder.failure(cmpFailBadRequest, err.Error())
+		}
+	}
+
+	parsedBundle, err := b.signEnrollmentCsr(sc, req, role, issuer, csr, true)
+	if err != nil {
+		var userErr errutil.UserError
+		if errors.As(err, &userErr) {
+			return responder.failure(cmpFailBadRequest, userErr.Err)
+		}
+		return nil, err
+	}
+
+	rep := cmpCertRepMessage{
+		Response: []cmpCertResponse{{
+			CertReqID: certReqID,
+			Status:    cmpStatusInfo{Status: cmpStatusAccepted},
+			CertifiedKeyPair: cmpCertifiedKeyPair{
+				CertOrEncCert: asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: parsedBundle.CertificateBytes},
+			},
+		}},
+	}
+
+	// Initialization responses deliver the root of the chain as the
+	// trust anchor of the newly enrolled entity.
+	if repType == cmpBodyIP && len(responder.chain) > 0 {
+		rep.CAPubs = []asn1.RawValue{{FullBytes: responder.chain[len(responder.chain)-1]}}
+	}
+
+	responder.implicitConfirm = header.requestsImplicitConfirm()
+	return responder.respond(repType, rep)
+}
+
+func parseCmpCertReqMsg(raw []byte) (int, *x509.CertificateRequest, int, error) {
+	var parts []asn1.RawValue
+	if rest, err := asn1.Unmarshal(raw, &parts); err != nil || len(rest) > 0 || len(parts) == 0 {
+		return 0, nil, cmpFailBadDataFormat, errors.New("malformed certificate request message")
+	}
+
+	var certReq cmpCertRequest
+	if rest, err := asn1.Unmarshal(parts[0].FullBytes, &certReq); err != nil || len(rest) > 0 {
+		return 0, nil, cmpFailBadDataFormat, errors.New("malformed certificate request")
+	}
+
+	template, err := parseCmpCertTemplate(certReq.CertTemplate.FullBytes)
+	if err != nil {
+		return 0, nil, cmpFailBadDataFormat, err
+	}
+
+	// The proof of possession is the only context tagged element of a
+	// CertReqMsg; the signature choice is tagged [1].
+	var popo *asn1.RawValue
+	for i := range parts[1:] {
+		if parts[i+1].Class == asn1.ClassContextSpecific {
+			popo = &parts[i+1]
+			break
+		}
+	}
+	if popo == nil || popo.Tag != 1 {
+		return 0, nil, cmpFailBadPOP, errors.New("a signature based proof of possession is required")
+	}
+
+	popoDer, err := cmpSequence(popo.Bytes)
+	if err != nil {
+		return 0, nil, cmpFailBadDataFormat, err
+	}
+	var pop cmpPOPOSigningKey
+	if rest, err := asn1.Unmarshal(popoDer, &pop); err != nil || len(rest) > 0 {
+		return 0, nil, cmpFailBadDataFormat, errors.New("malformed proof of possession")
+	}
+	if len(pop.Input.FullBytes) > 0 {
+		return 0, nil, cmpFailBadPOP, errors.New("proofs of possession over poposkInput are not supported; include the subject in the certificate template")
+	}
+
+	csr, err := template.certificateRequest(pop.Algorithm)
+	if err != nil {
+		return 0, nil, cmpFailBadDataFormat, fmt.Errorf("failed parsing certificate template: %w", err)
+	}
+
+	if err := cmpCheckSignature(csr.PublicKey, pop.Algorithm, parts[0].FullBytes, pop.Signature.RightAlign()); err != nil {
+		return 0, nil, cmpFailBadPOP, fmt.Errorf("proof of possession verification failed: %w", err)
+	}
+
+	return certReq.CertReqID, csr, 0, nil
+}
+
+// cmpResponder builds responses to a single CMP request.
+type cmpResponder struct {
+	request         *cmpHeader
+	caCert          *x509.Certificate
+	chain           [][]byte
+	protection      *cmpProtection
+	implicitConfirm bool
+}
+
+func newCmpResponder(sc *storageContext, issuer *issuerEntry, header *cmpHeader) (*cmpResponder, error) {
+	signingBundle, err := sc.fetchCAInfoByIssuerId(issuer.ID, IssuanceUsage)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading CA %s: %w", issuer.ID.String(), err)
+	}
+
+	var chain [][]byte
+	for _, certPem := range issuer.CAChain {
+		block, _ := pem.Decode([]byte(certPem))
+		if block == nil {
+			return nil, fmt.Errorf("failed decoding CA chain of issuer %v", issuer.ID)
+		}
+		chain = append(chain, block.Bytes)
+	}
+
+	// Responses are signed by the issuer unless the request was protected
+	// with the shared secret.
+	protection, err := newCmpSignatureProtection(signingBundle.PrivateKey, signingBundle.Certificate, chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed preparing CMP response protection: %w", err)
+	}
+
+	return &cmpResponder{
+		request:    header,
+		caCert:     signingBundle.Certificate,
+		chain:      chain,
+		protection: protection,
+	}, nil
+}
+
+func (r *cmpResponder) failure(failInfo int, text string) (*logical.Response, error) {
+	return r.respond(cmpBodyError, cmpErrorMsgContent{
+		Status: cmpStatusInfo{
+			Status:       cmpStatusRejection,
+			StatusString: cmpFreeText(text),
+			FailInfo:     cmpFailInfo(failInfo),
+		},
+	})
+}
+
+func (r *cmpResponder) respond(bodyType int, content interface{}) (*logical.Response, error) {
+	contentDer, err := asn1.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding CMP response: %w", err)
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	pvno := r.request.Pvno
+	if pvno != 2 && pvno != 3 {
+		pvno = 2
+	}
+
+	header := &cmpHeader{
+		Pvno:          pvno,
+		Sender:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: r.caCert.RawSubject},
+		Recipient:     asn1.RawValue{FullBytes: r.request.Sender.FullBytes},
+		MessageTime:   time.Now().UTC().Truncate(time.Second),
+		RecipKID:      r.request.SenderKID,
+		TransactionID: r.request.TransactionID,
+		SenderNonce:   nonce,
+		RecipNonce:    r.request.SenderNonce,
+	}
+	if len(header.Recipient.FullBytes) == 0 {
+		// An empty directoryName, for requests without a sender.
+		header.Recipient = asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: []byte{0x30, 0x00}}
+	}
+	if r.implicitConfirm {
+		header.GeneralInfo = []cmpInfoTypeAndValue{{InfoType: oidCmpImplicitConfirm, InfoValue: asn1.NullRawValue}}
+	}
+
+	der, err := r.protection.marshal(header, cmpBody(bodyType, contentDer))
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: cmpContentType,
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     der,
+		},
+	}, nil
+}
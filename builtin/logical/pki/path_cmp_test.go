@@ -0,0 +1,467 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCmpConfig(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBRead(b, s, "config/cmp")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, false, resp.Data["enabled"])
+	require.Equal(t, "sign-verbatim", resp.Data["default_path_policy"])
+	require.Equal(t, false, resp.Data["shared_secret_set"])
+
+	resp, err = CBWrite(b, s, "config/cmp", map[string]interface{}{
+		"enabled":       true,
+		"shared_secret": "secret",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, true, resp.Data["shared_secret_set"])
+	require.NotContains(t, resp.Data, "shared_secret")
+
+	_, err = CBWrite(b, s, "config/cmp", map[string]interface{}{
+		"trusted_vendor_certificates": "not a certificate",
+	})
+	require.Error(t, err, "expected invalid vendor certificates to be rejected")
+
+	vendorCert, _ := cmpTestCA(t, "vendor root")
+	vendorPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: vendorCert.Raw}))
+	_, err = CBWrite(b, s, "config/cmp", map[string]interface{}{
+		"trusted_vendor_certificates": vendorPem,
+	})
+	require.NoError(t, err)
+
+	resp, err = CBRead(b, s, "config/cmp")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, vendorPem, resp.Data["trusted_vendor_certificates"])
+	require.Equal(t, true, resp.Data["shared_secret_set"])
+
+	_, err = CBWrite(b, s, "config/cmp", map[string]interface{}{
+		"label_to_path_policy": map[string]string{"devices": "role:missing"},
+	})
+	require.Error(t, err, "expected missing role to be rejected")
+}
+
+func TestCmpEnrollment(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	_, err = CBWrite(b, s, "roles/nf", map[string]interface{}{
+		"allowed_domains":  "nf.example.com",
+		"allow_subdomains": true,
+		"key_type":         "any",
+		"ttl":              "1h",
+	})
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	subject := cmpTestSubject(t, "amf.nf.example.com")
+	ir := cmpTestCertReqMessages(t, key, subject, "amf.nf.example.com")
+
+	// Disabled by default.
+	pbm := newCmpPBMProtection([]byte("secret"), cmpTestPBMAlgorithm(t))
+	_, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyIR, ir, pbm, false)
+	require.Error(t, err)
+
+	vendorCert, vendorKey := cmpTestCA(t, "vendor root")
+	_, err = CBWrite(b, s, "config/cmp", map[string]interface{}{
+		"enabled":                     true,
+		"default_path_policy":         "forbid",
+		"label_to_path_policy":        map[string]string{"nf": "role:nf"},
+		"shared_secret":               "secret",
+		"trusted_vendor_certificates": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: vendorCert.Raw})),
+	})
+	require.NoError(t, err)
+
+	_, err = cmpTestRequest(b, s, "cmp", cmpBodyIR, ir, pbm, false)
+	require.Error(t, err)
+
+	// A wrong shared secret is refused with a signed error message.
+	wrongPbm := newCmpPBMProtection([]byte("wrong"), cmpTestPBMAlgorithm(t))
+	reply, err := cmpTestRequest(b, s, "cmp/nf", cmpBodyIR, ir, wrongPbm, false)
+	require.NoError(t, err)
+	reply.requireSignedBy(t, rootCert)
+	reply.requireError(t, cmpFailBadMessageCheck)
+
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyIR, ir, pbm, true)
+	require.NoError(t, err)
+	require.True(t, reply.header.requestsImplicitConfirm())
+
+	// Responses to MAC protected requests are MAC protected.
+	protectedPart, err := cmpProtectedPart(reply.msg.Header, reply.msg.Body)
+	require.NoError(t, err)
+	mac, err := cmpPasswordBasedMac([]byte("secret"), reply.header.ProtectionAlg, protectedPart)
+	require.NoError(t, err)
+	require.Equal(t, mac, reply.msg.Protection.RightAlign())
+
+	rep := reply.requireCertRep(t, cmpBodyIP)
+	require.Len(t, rep.CAPubs, 1)
+	require.Equal(t, rootCert.Raw, rep.CAPubs[0].FullBytes)
+	leaf := reply.requireCert(t, rep)
+	require.Equal(t, "amf.nf.example.com", leaf.Subject.CommonName)
+	requireSignedBy(t, leaf, rootCert)
+
+	// The issued certificate must be stored.
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(leaf))
+	requireSuccessNonNilResponse(t, resp, err)
+
+	// Names outside of the role are rejected.
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyIR, cmpTestCertReqMessages(t, key, cmpTestSubject(t, "example.org")), pbm, false)
+	require.NoError(t, err)
+	reply.requireError(t, cmpFailBadRequest)
+
+	// Initial enrollment with a vendor certificate.
+	deviceKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	deviceCert := cmpTestIssue(t, vendorCert, vendorKey, deviceKey, "device serial 1")
+	vendorSig, err := newCmpSignatureProtection(deviceKey, deviceCert, [][]byte{deviceCert.Raw})
+	require.NoError(t, err)
+
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyIR, ir, vendorSig, false)
+	require.NoError(t, err)
+	reply.requireSignedBy(t, rootCert)
+	reply.requireCert(t, reply.requireCertRep(t, cmpBodyIP))
+
+	// Certificates of unknown vendors are refused.
+	otherVendorCert, otherVendorKey := cmpTestCA(t, "other vendor root")
+	otherDeviceCert := cmpTestIssue(t, otherVendorCert, otherVendorKey, deviceKey, "device serial 2")
+	otherSig, err := newCmpSignatureProtection(deviceKey, otherDeviceCert, [][]byte{otherDeviceCert.Raw})
+	require.NoError(t, err)
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyIR, ir, otherSig, false)
+	require.NoError(t, err)
+	reply.requireError(t, cmpFailBadMessageCheck)
+
+	// Confirmations are acknowledged.
+	certConf, err := asn1.Marshal([]cmpCertStatus{{CertHash: []byte("hash"), CertReqID: 0}})
+	require.NoError(t, err)
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyCertConf, certConf, pbm, false)
+	require.NoError(t, err)
+	require.Equal(t, cmpBodyPKIConf, reply.msg.Body.Tag)
+
+	// Key updates are signed with the current certificate.
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	kur := cmpTestCertReqMessages(t, newKey, leaf.RawSubject, leaf.DNSNames...)
+
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyKUR, kur, pbm, false)
+	require.NoError(t, err)
+	reply.requireError(t, cmpFailBadRequest)
+
+	leafSig, err := newCmpSignatureProtection(key, leaf, [][]byte{leaf.Raw})
+	require.NoError(t, err)
+
+	// Key updates must keep the subject.
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyKUR, cmpTestCertReqMessages(t, newKey, cmpTestSubject(t, "smf.nf.example.com"), "smf.nf.example.com"), leafSig, false)
+	require.NoError(t, err)
+	reply.requireError(t, cmpFailBadRequest)
+
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyKUR, kur, leafSig, false)
+	require.NoError(t, err)
+	reply.requireSignedBy(t, rootCert)
+	updated := reply.requireCert(t, reply.requireCertRep(t, cmpBodyKUP))
+	require.Equal(t, leaf.Subject.CommonName, updated.Subject.CommonName)
+	require.NotEqual(t, leaf.SerialNumber, updated.SerialNumber)
+	require.True(t, newKey.PublicKey.Equal(updated.PublicKey))
+
+	// Certification requests signed with an issued certificate are
+	// restricted to its subject and names.
+	crKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyCR, cmpTestCertReqMessages(t, crKey, cmpTestSubject(t, "smf.nf.example.com"), "smf.nf.example.com"), leafSig, false)
+	require.NoError(t, err)
+	reply.requireError(t, cmpFailBadRequest)
+
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyCR, cmpTestCertReqMessages(t, crKey, leaf.RawSubject, "amf.nf.example.com", "smf.nf.example.com"), leafSig, false)
+	require.NoError(t, err)
+	reply.requireError(t, cmpFailBadRequest)
+
+	cr := cmpTestCertReqMessages(t, crKey, leaf.RawSubject, leaf.DNSNames...)
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyCR, cr, leafSig, false)
+	require.NoError(t, err)
+	reply.requireSignedBy(t, rootCert)
+	crCert := reply.requireCert(t, reply.requireCertRep(t, cmpBodyCP))
+	require.Equal(t, leaf.Subject.CommonName, crCert.Subject.CommonName)
+	require.True(t, crKey.PublicKey.Equal(crCert.PublicKey))
+
+	// Certification requests under the shared secret or a vendor
+	// certificate may ask for any name the role allows.
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyCR, cmpTestCertReqMessages(t, crKey, cmpTestSubject(t, "smf.nf.example.com"), "smf.nf.example.com"), pbm, false)
+	require.NoError(t, err)
+	crCert = reply.requireCert(t, reply.requireCertRep(t, cmpBodyCP))
+	require.Equal(t, "smf.nf.example.com", crCert.Subject.CommonName)
+
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyCR, cmpTestCertReqMessages(t, crKey, cmpTestSubject(t, "upf.nf.example.com"), "upf.nf.example.com"), vendorSig, false)
+	require.NoError(t, err)
+	reply.requireSignedBy(t, rootCert)
+	crCert = reply.requireCert(t, reply.requireCertRep(t, cmpBodyCP))
+	require.Equal(t, "upf.nf.example.com", crCert.Subject.CommonName)
+
+	// Revoked certificates can no longer update their keys.
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(leaf),
+	})
+	require.NoError(t, err)
+
+	reply, err = cmpTestRequest(b, s, "cmp/nf", cmpBodyKUR, kur, leafSig, false)
+	require.NoError(t, err)
+	reply.requireError(t, cmpFailBadMessageCheck)
+}
+
+type cmpTestReply struct {
+	msg    cmpMessage
+	header cmpHeader
+}
+
+func (r *cmpTestReply) requireSignedBy(t *testing.T, signer *x509.Certificate) {
+	t.Helper()
+
+	protectedPart, err := cmpProtectedPart(r.msg.Header, r.msg.Body)
+	require.NoError(t, err)
+	require.NoError(t, cmpCheckSignature(signer.PublicKey, r.header.ProtectionAlg, protectedPart, r.msg.Protection.RightAlign()))
+}
+
+func (r *cmpTestReply) requireError(t *testing.T, failInfo int) {
+	t.Helper()
+
+	require.Equal(t, cmpBodyError, r.msg.Body.Tag)
+	var content cmpErrorMsgContent
+	_, err := asn1.Unmarshal(r.msg.Body.Bytes, &content)
+	require.NoError(t, err)
+	require.Equal(t, cmpStatusRejection, content.Status.Status)
+	require.Equal(t, 1, content.Status.FailInfo.At(failInfo))
+}
+
+func (r *cmpTestReply) requireCertRep(t *testing.T, bodyType int) *cmpCertRepMessage {
+	t.Helper()
+
+	require.Equal(t, bodyType, r.msg.Body.Tag)
+	var rep cmpCertRepMessage
+	_, err := asn1.Unmarshal(r.msg.Body.Bytes, &rep)
+	require.NoError(t, err)
+	require.Len(t, rep.Response, 1)
+	require.Equal(t, cmpStatusAccepted, rep.Response[0].Status.Status)
+	return &rep
+}
+
+func (r *cmpTestReply) requireCert(t *testing.T, rep *cmpCertRepMessage) *x509.Certificate {
+	t.Helper()
+
+	cert, err := x509.ParseCertificate(rep.Response[0].CertifiedKeyPair.CertOrEncCert.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func cmpTestRequest(b *backend, s logical.Storage, path string, bodyType int, content []byte, protection *cmpProtection, implicitConfirm bool) (*cmpTestReply, error) {
+	transactionID := make([]byte, 16)
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(transactionID); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := &cmpHeader{
+		Pvno:          2,
+		Sender:        asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: []byte{0x30, 0x00}},
+		Recipient:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: []byte{0x30, 0x00}},
+		MessageTime:   time.Now().UTC().Truncate(time.Second),
+		SenderKID:     []byte("client"),
+		TransactionID: transactionID,
+		SenderNonce:   nonce,
+	}
+	if implicitConfirm {
+		header.GeneralInfo = []cmpInfoTypeAndValue{{InfoType: oidCmpImplicitConfirm, InfoValue: asn1.NullRawValue}}
+	}
+
+	der, err := protection.marshal(header, cmpBody(bodyType, content))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/v1/pki/"+path, bytes.NewReader(der))
+	httpReq.Header.Set("Content-Type", cmpContentType)
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation:   logical.UpdateOperation,
+		Path:        path,
+		Storage:     s,
+		MountPoint:  "pki/",
+		HTTPRequest: httpReq,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, resp.Error()
+	}
+
+	var reply cmpTestReply
+	if _, err := asn1.Unmarshal(resp.Data[logical.HTTPRawBody].([]byte), &reply.msg); err != nil {
+		return nil, err
+	}
+	if _, err := asn1.Unmarshal(reply.msg.Header.FullBytes, &reply.header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(transactionID, reply.header.TransactionID) || !bytes.Equal(nonce, reply.header.RecipNonce) {
+		return nil, fmt.Errorf("response does not match the request transaction")
+	}
+
+	return &reply, nil
+}
+
+func cmpTestPBMAlgorithm(t *testing.T) pkix.AlgorithmIdentifier {
+	t.Helper()
+
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	require.NoError(t, err)
+
+	params, err := asn1.Marshal(cmpPBMParameter{
+		Salt:           salt,
+		Owf:            pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}},
+		IterationCount: 1000,
+		Mac:            pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}},
+	})
+	require.NoError(t, err)
+
+	return pkix.AlgorithmIdentifier{Algorithm: oidCmpPasswordBasedMac, Parameters: asn1.RawValue{FullBytes: params}}
+}
+
+func cmpTestSubject(t *testing.T, commonName string) []byte {
+	t.Helper()
+
+	subject, err := asn1.Marshal(pkix.Name{CommonName: commonName}.ToRDNSequence())
+	require.NoError(t, err)
+	return subject
+}
+
+// cmpTestCertReqMessages builds CertReqMessages requesting a certificate for
+// the key, subject and DNS names, with a signature based proof of possession.
+func cmpTestCertReqMessages(t *testing.T, key *ecdsa.PrivateKey, subject []byte, dnsNames ...string) []byte {
+	t.Helper()
+
+	spki, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	var spkiSeq asn1.RawValue
+	_, err = asn1.Unmarshal(spki, &spkiSeq)
+	require.NoError(t, err)
+
+	fields := []asn1.RawValue{
+		{Class: asn1.ClassContextSpecific, Tag: 5, IsCompound: true, Bytes: subject},
+		{Class: asn1.ClassContextSpecific, Tag: 6, IsCompound: true, Bytes: spkiSeq.Bytes},
+	}
+	if len(dnsNames) > 0 {
+		// Let the standard library encode the subjectAltName extension.
+		der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: dnsNames}, key)
+		require.NoError(t, err)
+		csr, err := x509.ParseCertificateRequest(der)
+		require.NoError(t, err)
+
+		extensions, err := asn1.Marshal(csr.Extensions)
+		require.NoError(t, err)
+		var extensionsSeq asn1.RawValue
+		_, err = asn1.Unmarshal(extensions, &extensionsSeq)
+		require.NoError(t, err)
+		fields = append(fields, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 9, IsCompound: true, Bytes: extensionsSeq.Bytes})
+	}
+
+	template, err := asn1.Marshal(fields)
+	require.NoError(t, err)
+
+	certReq, err := asn1.Marshal(cmpCertRequest{CertTemplate: asn1.RawValue{FullBytes: template}})
+	require.NoError(t, err)
+
+	protection, err := newCmpSignatureProtection(key, &x509.Certificate{}, nil)
+	require.NoError(t, err)
+	signature, err := protection.protect(certReq)
+	require.NoError(t, err)
+
+	algorithm, err := asn1.Marshal(protection.alg)
+	require.NoError(t, err)
+	bitString, err := asn1.Marshal(asn1.BitString{Bytes: signature, BitLength: len(signature) * 8})
+	require.NoError(t, err)
+
+	certReqMsg, err := asn1.Marshal([]asn1.RawValue{
+		{FullBytes: certReq},
+		{Class: asn1.ClassContextSpecific, Tag: 1, IsCompound: true, Bytes: append(algorithm, bitString...)},
+	})
+	require.NoError(t, err)
+
+	certReqMessages, err := asn1.Marshal([]asn1.RawValue{{FullBytes: certReqMsg}})
+	require.NoError(t, err)
+	return certReqMessages
+}
+
+func cmpTestCA(t *testing.T, commonName string) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func cmpTestIssue(t *testing.T, ca *x509.Certificate, caKey crypto.Signer, key crypto.Signer, commonName string) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, key.Public(), caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
@@ -5,6 +5,7 @@ package pki
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/hashicorp/vault/sdk/framework"
@@ -27,6 +28,21 @@ func pathConfigCA(b *backend) *framework.Path {
 				Description: `PEM-format, concatenated unencrypted
 secret key and certificate.`,
 			},
+			"pkcs12_bundle": {
+				Type: framework.TypeString,
+				Description: `Base64-encoded PKCS#12 bundle of the secret
+key and
+certificates, decrypted with "passphrase".
+Mutually exclusive with "pem_bundle".`,
+			},
+			"passphrase": {
+				Type: framework.TypeString,
+				Description: `Passphrase decrypting the "pkcs12_bundle" or
+any encrypted private keys within the "pem_bundle".`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -222,7 +238,12 @@ func (b *backend) pathCAIssuersRead(ctx context.Context, req *logical.Request, _
 		return logical.ErrorResponse("Error loading issuers configuration: " + err.Error()), nil
 	}
 
-	return b.formatCAIssuerConfigRead(config), nil
+	response := b.formatCAIssuerConfigRead(config)
+	if len(config.DefaultIssuerId) > 0 {
+		addWarningOnRevokedIssuer(sc, config.DefaultIssuerId.String(), response)
+	}
+
+	return response, nil
 }
 
 func (b *backend) formatCAIssuerConfigRead(config *issuerConfigEntry) *logical.Response {
@@ -285,6 +306,9 @@ func (b *backend) pathCAIssuersWrite(ctx context.Context, req *logical.Request,
 		response.AddWarning(msg)
 		b.Logger().Error(msg)
 	}
+	if entry.Revoked {
+		response.AddWarning(fmt.Sprintf("Issuer %v was revoked; certificates can no longer be issued through the default issuer until it is changed", parsedIssuer))
+	}
 
 	if err := sc.setIssuersConfig(config); err != nil {
 		return logical.ErrorResponse("Error updating issuer configuration: " + err.Error()), nil
@@ -319,6 +343,12 @@ func pathConfigKeys(b *backend) *framework.Path {
 				Type:        framework.TypeString,
 				Description: `Reference (name or identifier) of the default key.`,
 			},
+			"allow_wrapped_export": {
+				Type: framework.TypeBool,
+				Description: `Whether keys may be exported wrapped to a
+caller-supplied RSA public key, through key/:key_ref/export-wrapped.
+Defaults to false.`,
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -337,6 +367,10 @@ func pathConfigKeys(b *backend) *framework.Path {
 								Description: `Reference (name or identifier) to the default issuer.`,
 								Required:    true,
 							},
+							"allow_wrapped_export": {
+								Type:        framework.TypeBool,
+								Description: `Whether keys may be exported wrapped to a caller-supplied public key.`,
+							},
 						},
 					}},
 				},
@@ -356,6 +390,10 @@ func pathConfigKeys(b *backend) *framework.Path {
 								Type:        framework.TypeString,
 								Description: `Reference (name or identifier) to the default issuer.`,
 							},
+							"allow_wrapped_export": {
+								Type:        framework.TypeBool,
+								Description: `Whether keys may be exported wrapped to a caller-supplied public key.`,
+							},
 						},
 					}},
 				},
@@ -382,7 +420,8 @@ func (b *backend) pathKeyDefaultRead(ctx context.Context, req *logical.Request,
 
 	return &logical.Response{
 		Data: map[string]interface{}{
-			defaultRef: config.DefaultKeyId,
+			defaultRef:             config.DefaultKeyId,
+			"allow_wrapped_export": config.AllowWrappedExport,
 		},
 	}, nil
 }
@@ -397,25 +436,43 @@ func (b *backend) pathKeyDefaultWrite(ctx context.Context, req *logical.Request,
 		return logical.ErrorResponse("Cannot update key defaults until migration has completed"), nil
 	}
 
-	newDefault := data.Get(defaultRef).(string)
-	if len(newDefault) == 0 || newDefault == defaultRef {
+	rawDefault, defaultSet := data.GetOk(defaultRef)
+	rawAllowExport, allowExportSet := data.GetOk("allow_wrapped_export")
+	if !defaultSet && !allowExportSet {
 		return logical.ErrorResponse("Invalid key specification; must be non-empty and can't be 'default'."), nil
 	}
 
 	sc := b.makeStorageContext(ctx, req.Storage)
-	parsedKey, err := sc.resolveKeyReference(newDefault)
+	config, err := sc.getKeysConfig()
 	if err != nil {
-		return logical.ErrorResponse("Error resolving issuer reference: " + err.Error()), nil
+		return logical.ErrorResponse("Error loading keys configuration: " + err.Error()), nil
 	}
 
-	err = sc.updateDefaultKeyId(parsedKey)
-	if err != nil {
+	if defaultSet {
+		newDefault := rawDefault.(string)
+		if len(newDefault) == 0 || newDefault == defaultRef {
+			return logical.ErrorResponse("Invalid key specification; must be non-empty and can't be 'default'."), nil
+		}
+
+		parsedKey, err := sc.resolveKeyReference(newDefault)
+		if err != nil {
+			return logical.ErrorResponse("Error resolving issuer reference: " + err.Error()), nil
+		}
+		config.DefaultKeyId = parsedKey
+	}
+
+	if allowExportSet {
+		config.AllowWrappedExport = rawAllowExport.(bool)
+	}
+
+	if err := sc.setKeysConfig(config); err != nil {
 		return logical.ErrorResponse("Error updating issuer configuration: " + err.Error()), nil
 	}
 
 	return &logical.Response{
 		Data: map[string]interface{}{
-			defaultRef: parsedKey,
+			defaultRef:             config.DefaultKeyId,
+			"allow_wrapped_export": config.AllowWrappedExport,
 		},
 	}, nil
 }
@@ -426,4 +483,7 @@ const pathConfigKeysHelpDesc = `
 This path allows configuration of key parameters.
 
 The "default" parameter controls which key is the default used by signing paths.
+
+The "allow_wrapped_export" parameter controls whether keys may be exported,
+wrapped to a caller-supplied RSA public key, through key/:key_ref/export-wrapped.
 `
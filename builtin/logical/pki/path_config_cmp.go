@@ -0,0 +1,249 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageCmpConfig      = "config/cmp"
+	pathConfigCmpHelpSyn  = "Configuration of CMP Endpoints"
+	pathConfigCmpHelpDesc = "Here we configure:\n\nenabled=false, whether CMP is enabled, defaults to false meaning that clusters will by default not get CMP support,\ndefault_path_policy=\"sign-verbatim\", either \"forbid\", preventing the unlabeled cmp endpoint from being used at all, \"role:<role_name>\" which is the role to be used for unlabeled CMP requests; or \"sign-verbatim\", meaning CMP issuance will be equivalent to sign-verbatim,\nlabel_to_path_policy={}, a map of CMP labels to either \"sign-verbatim\" or \"role:<role_name>\", served under cmp/<label>,\nshared_secret=\"\", the secret initialization requests may be MAC protected with,\ntrusted_vendor_certificates=\"\", a PEM bundle of vendor CA certificates; initialization requests signed by a certificate chaining to one of them are accepted."
+)
+
+type cmpConfigEntry struct {
+	Enabled                   bool              `json:"enabled"`
+	DefaultPathPolicy         string            `json:"default_path_policy"`
+	LabelToPathPolicy         map[string]string `json:"label_to_path_policy"`
+	SharedSecret              string            `json:"shared_secret"`
+	TrustedVendorCertificates string            `json:"trusted_vendor_certificates"`
+}
+
+var defaultCmpConfig = cmpConfigEntry{
+	Enabled:           false,
+	DefaultPathPolicy: "sign-verbatim",
+	LabelToPathPolicy: map[string]string{},
+}
+
+func (sc *storageContext) getCmpConfig() (*cmpConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageCmpConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping cmpConfigEntry
+	if entry == nil {
+		mapping = defaultCmpConfig
+		mapping.LabelToPathPolicy = map[string]string{}
+		return &mapping, nil
+	}
+
+	if err := entry.DecodeJSON(&mapping); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode CMP configuration: %v", err)}
+	}
+
+	if mapping.LabelToPathPolicy == nil {
+		mapping.LabelToPathPolicy = map[string]string{}
+	}
+
+	return &mapping, nil
+}
+
+func (sc *storageContext) setCmpConfig(entry *cmpConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageCmpConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathCmpConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/cmp",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether CMP is enabled, defaults to false meaning that clusters will by default not get CMP support`,
+				Default:     false,
+			},
+			"default_path_policy": {
+				Type:        framework.TypeString,
+				Description: `the policy to be used for unlabeled CMP requests under cmp; either "forbid", "sign-verbatim" (the default) or a role to use as this policy, as "role:<role_name>"`,
+				Default:     "sign-verbatim",
+			},
+			"label_to_path_policy": {
+				Type:        framework.TypeKVPairs,
+				Description: `a map of CMP labels, served under cmp/<label>, to the policy used for requests on that label; each policy is either "sign-verbatim" or "role:<role_name>"`,
+			},
+			"shared_secret": {
+				Type:        framework.TypeString,
+				Description: `the secret initialization requests may be protected with using a password based MAC. This value is never returned`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+			"trusted_vendor_certificates": {
+				Type:        framework.TypeString,
+				Description: `a PEM bundle of vendor CA certificates; initialization requests signed by a certificate chaining to one of them are accepted`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "cmp-configuration",
+				},
+				Callback: b.pathCmpRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathCmpWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "cmp",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigCmpHelpSyn,
+		HelpDescription: pathConfigCmpHelpDesc,
+	}
+}
+
+func (b *backend) pathCmpRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getCmpConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromCmpConfig(config), nil
+}
+
+func genResponseFromCmpConfig(config *cmpConfigEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":                     config.Enabled,
+			"default_path_policy":         config.DefaultPathPolicy,
+			"label_to_path_policy":        config.LabelToPathPolicy,
+			"shared_secret_set":           len(config.SharedSecret) > 0,
+			"trusted_vendor_certificates": config.TrustedVendorCertificates,
+		},
+	}
+}
+
+func (b *backend) pathCmpWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getCmpConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if defaultPathPolicyRaw, ok := d.GetOk("default_path_policy"); ok {
+		config.DefaultPathPolicy = defaultPathPolicyRaw.(string)
+	}
+
+	if labelsRaw, ok := d.GetOk("label_to_path_policy"); ok {
+		config.LabelToPathPolicy = labelsRaw.(map[string]string)
+	}
+
+	if secretRaw, ok := d.GetOk("shared_secret"); ok {
+		config.SharedSecret = secretRaw.(string)
+	}
+
+	if vendorRaw, ok := d.GetOk("trusted_vendor_certificates"); ok {
+		config.TrustedVendorCertificates = vendorRaw.(string)
+	}
+
+	if _, err := parseCmpTrustedVendorCertificates(config.TrustedVendorCertificates); err != nil {
+		return logical.ErrorResponse("invalid trusted_vendor_certificates: %v", err), nil
+	}
+
+	if err := validateEnrollmentPathPolicy(sc, "CMP", config.DefaultPathPolicy, true); err != nil {
+		return logical.ErrorResponse("invalid default_path_policy: %v", err), nil
+	}
+
+	labels := make([]string, 0, len(config.LabelToPathPolicy))
+	for label := range config.LabelToPathPolicy {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		if !enrollmentLabelRegex.MatchString(label) {
+			return logical.ErrorResponse("invalid CMP label %q: labels may only contain alphanumeric characters, dashes, underscores and periods", label), nil
+		}
+
+		if err := validateEnrollmentPathPolicy(sc, "CMP", config.LabelToPathPolicy[label], false); err != nil {
+			return logical.ErrorResponse("invalid path policy for CMP label %q: %v", label, err), nil
+		}
+	}
+
+	if err := sc.setCmpConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromCmpConfig(config), nil
+}
+
+// parseCmpTrustedVendorCertificates parses the PEM bundle of vendor CA
+// certificates, returning a nil pool when none are configured.
+func parseCmpTrustedVendorCertificates(bundle string) (*x509.CertPool, error) {
+	var pool *x509.CertPool
+
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block of type %v", block.Type)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing certificate: %w", err)
+		}
+
+		if pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AddCert(cert)
+	}
+
+	if pool == nil && strings.TrimSpace(bundle) != "" {
+		return nil, fmt.Errorf("no PEM encoded certificates found")
+	}
+
+	return pool, nil
+}
@@ -26,6 +26,11 @@ type crlConfig struct {
 	AutoRebuild               bool   `json:"auto_rebuild"`
 	AutoRebuildGracePeriod    string `json:"auto_rebuild_grace_period"`
 	OcspExpiry                string `json:"ocsp_expiry"`
+	OcspDelegatedResponder    bool   `json:"ocsp_delegated_responder"`
+	OcspResponderTTL          string `json:"ocsp_responder_ttl"`
+	OcspResponderFallback     bool   `json:"ocsp_responder_fallback"`
+	OcspCacheTTL              string `json:"ocsp_cache_ttl"`
+	OcspPregenerate           bool   `json:"ocsp_pregenerate"`
 	EnableDelta               bool   `json:"enable_delta"`
 	DeltaRebuildInterval      string `json:"delta_rebuild_interval"`
 	UseGlobalQueue            bool   `json:"cross_cluster_revocation"`
@@ -40,6 +45,11 @@ var defaultCrlConfig = crlConfig{
 	Disable:                   false,
 	OcspDisable:               false,
 	OcspExpiry:                "12h",
+	OcspDelegatedResponder:    false,
+	OcspResponderTTL:          "72h",
+	OcspResponderFallback:     false,
+	OcspCacheTTL:              "0s",
+	OcspPregenerate:           false,
 	AutoRebuild:               false,
 	AutoRebuildGracePeriod:    "12h",
 	EnableDelta:               false,
@@ -78,6 +88,34 @@ valid; defaults to 72 hours`,
 the NextUpdate field); defaults to 12 hours`,
 				Default: "1h",
 			},
+			"ocsp_delegated_responder": {
+				Type: framework.TypeBool,
+				Description: `If set to true, OCSP responses are signed by a delegated
+responder certificate issued by each issuer instead of the issuer's key.`,
+			},
+			"ocsp_responder_ttl": {
+				Type: framework.TypeString,
+				Description: `The lifetime of delegated OCSP responder certificates; they
+are rotated once half of it has elapsed. Defaults to 72h.`,
+				Default: "72h",
+			},
+			"ocsp_responder_fallback": {
+				Type: framework.TypeBool,
+				Description: `If set to true, OCSP responses of issuers without a valid
+delegated responder are signed by the issuer's key; otherwise such requests
+fail. Defaults to false.`,
+			},
+			"ocsp_cache_ttl": {
+				Type: framework.TypeString,
+				Description: `The amount of time signed OCSP responses are cached in memory
+on each node, capped at ocsp_expiry; defaults to 0s, disabling the cache.`,
+				Default: "0s",
+			},
+			"ocsp_pregenerate": {
+				Type: framework.TypeBool,
+				Description: `If set to true, OCSP responses are generated and cached when
+certificates are issued. Requires ocsp_cache_ttl to be set.`,
+			},
 			"auto_rebuild": {
 				Type:        framework.TypeBool,
 				Description: `If set to true, enables automatic rebuilding of the CRL`,
@@ -148,6 +186,31 @@ valid; defaults to 72 hours`,
 the NextUpdate field); defaults to 12 hours`,
 								Required: true,
 							},
+							"ocsp_delegated_responder": {
+								Type:        framework.TypeBool,
+								Description: `Whether OCSP responses are signed by a delegated responder certificate`,
+								Required:    false,
+							},
+							"ocsp_responder_ttl": {
+								Type:        framework.TypeString,
+								Description: `The lifetime of delegated OCSP responder certificates`,
+								Required:    false,
+							},
+							"ocsp_responder_fallback": {
+								Type:        framework.TypeBool,
+								Description: `Whether issuers without a valid delegated OCSP responder sign responses themselves`,
+								Required:    false,
+							},
+							"ocsp_cache_ttl": {
+								Type:        framework.TypeString,
+								Description: `The amount of time signed OCSP responses are cached in memory`,
+								Required:    false,
+							},
+							"ocsp_pregenerate": {
+								Type:        framework.TypeBool,
+								Description: `Whether OCSP responses are generated when certificates are issued`,
+								Required:    false,
+							},
 							"auto_rebuild": {
 								Type:        framework.TypeBool,
 								Description: `If set to true, enables automatic rebuilding of the CRL`,
@@ -221,6 +284,31 @@ valid; defaults to 72 hours`,
 the NextUpdate field); defaults to 12 hours`,
 								Default: "1h",
 							},
+							"ocsp_delegated_responder": {
+								Type:        framework.TypeBool,
+								Description: `Whether OCSP responses are signed by a delegated responder certificate`,
+								Required:    false,
+							},
+							"ocsp_responder_ttl": {
+								Type:        framework.TypeString,
+								Description: `The lifetime of delegated OCSP responder certificates`,
+								Required:    false,
+							},
+							"ocsp_responder_fallback": {
+								Type:        framework.TypeBool,
+								Description: `Whether issuers without a valid delegated OCSP responder sign responses themselves`,
+								Required:    false,
+							},
+							"ocsp_cache_ttl": {
+								Type:        framework.TypeString,
+								Description: `The amount of time signed OCSP responses are cached in memory`,
+								Required:    false,
+							},
+							"ocsp_pregenerate": {
+								Type:        framework.TypeBool,
+								Description: `Whether OCSP responses are generated when certificates are issued`,
+								Required:    false,
+							},
 							"auto_rebuild": {
 								Type:        framework.TypeBool,
 								Description: `If set to true, enables automatic rebuilding of the CRL`,
@@ -320,6 +408,48 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 		config.OcspExpiry = expiry
 	}
 
+	oldOcspDelegatedResponder := config.OcspDelegatedResponder
+	if ocspDelegatedResponderRaw, ok := d.GetOk("ocsp_delegated_responder"); ok {
+		config.OcspDelegatedResponder = ocspDelegatedResponderRaw.(bool)
+	}
+
+	if ocspResponderFallbackRaw, ok := d.GetOk("ocsp_responder_fallback"); ok {
+		config.OcspResponderFallback = ocspResponderFallbackRaw.(bool)
+	}
+
+	oldOcspResponderTTL := config.OcspResponderTTL
+	if ocspResponderTTLRaw, ok := d.GetOk("ocsp_responder_ttl"); ok {
+		ocspResponderTTL := ocspResponderTTLRaw.(string)
+		duration, err := parseutil.ParseDurationSecond(ocspResponderTTL)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("given ocsp_responder_ttl could not be decoded: %s", err)), nil
+		}
+		if duration <= 0 {
+			return logical.ErrorResponse(fmt.Sprintf("ocsp_responder_ttl must be greater than 0 got: %s", duration)), nil
+		}
+		config.OcspResponderTTL = ocspResponderTTL
+	}
+
+	if ocspCacheTTLRaw, ok := d.GetOk("ocsp_cache_ttl"); ok {
+		ocspCacheTTL := ocspCacheTTLRaw.(string)
+		duration, err := parseutil.ParseDurationSecond(ocspCacheTTL)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("given ocsp_cache_ttl could not be decoded: %s", err)), nil
+		}
+		if duration < 0 {
+			return logical.ErrorResponse(fmt.Sprintf("ocsp_cache_ttl must be greater than or equal to 0 got: %s", duration)), nil
+		}
+		config.OcspCacheTTL = ocspCacheTTL
+	}
+
+	if ocspPregenerateRaw, ok := d.GetOk("ocsp_pregenerate"); ok {
+		config.OcspPregenerate = ocspPregenerateRaw.(bool)
+	}
+
+	if config.OcspPregenerate && config.ocspCacheTTL() <= 0 {
+		return logical.ErrorResponse("ocsp_pregenerate requires OCSP to be enabled with a non-zero ocsp_cache_ttl and ocsp_expiry"), nil
+	}
+
 	oldAutoRebuild := config.AutoRebuild
 	if autoRebuildRaw, ok := d.GetOk("auto_rebuild"); ok {
 		config.AutoRebuild = autoRebuildRaw.(bool)
@@ -371,6 +501,14 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 		}
 	}
 
+	if config.OcspDelegatedResponder {
+		ocspExpiry, _ := parseutil.ParseDurationSecond(config.OcspExpiry)
+		ocspResponderTTL, _ := parseutil.ParseDurationSecond(config.OcspResponderTTL)
+		if ocspExpiry > ocspResponderTTL/2 {
+			return logical.ErrorResponse(fmt.Sprintf("OCSP responder lifetime (%v) must be at least twice the OCSP response expiry (%v) when delegated OCSP responders are enabled, so responses never outlive their responder", config.OcspResponderTTL, config.OcspExpiry)), nil
+		}
+	}
+
 	if config.EnableDelta {
 		deltaRebuildInterval, _ := parseutil.ParseDurationSecond(config.DeltaRebuildInterval)
 		if deltaRebuildInterval >= expiry {
@@ -414,6 +552,9 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 		return nil, fmt.Errorf("failed persisting CRL config: %w", err)
 	}
 
+	// Cached OCSP responses may no longer reflect the new configuration.
+	b.ocspCache.purge()
+
 	resp := genResponseFromCrlConfig(config)
 
 	// Note this only affects/happens on the main cluster node, if you need to
@@ -438,6 +579,14 @@ func (b *backend) pathCRLWrite(ctx context.Context, req *logical.Request, d *fra
 		}
 	}
 
+	if config.OcspDelegatedResponder && (!oldOcspDelegatedResponder || oldOcspResponderTTL != config.OcspResponderTTL) {
+		// Responders are otherwise only rotated by the periodic function;
+		// issue fresh ones now so the new settings apply immediately.
+		if err := rotateOcspResponders(sc, config, true); err != nil {
+			return nil, fmt.Errorf("error issuing delegated OCSP responders: %w", err)
+		}
+	}
+
 	return resp, nil
 }
 
@@ -448,6 +597,11 @@ func genResponseFromCrlConfig(config *crlConfig) *logical.Response {
 			"disable":                       config.Disable,
 			"ocsp_disable":                  config.OcspDisable,
 			"ocsp_expiry":                   config.OcspExpiry,
+			"ocsp_delegated_responder":      config.OcspDelegatedResponder,
+			"ocsp_responder_ttl":            config.OcspResponderTTL,
+			"ocsp_responder_fallback":       config.OcspResponderFallback,
+			"ocsp_cache_ttl":                config.OcspCacheTTL,
+			"ocsp_pregenerate":              config.OcspPregenerate,
 			"auto_rebuild":                  config.AutoRebuild,
 			"auto_rebuild_grace_period":     config.AutoRebuildGracePeriod,
 			"enable_delta":                  config.EnableDelta,
@@ -0,0 +1,218 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageEstConfig      = "config/est"
+	pathConfigEstHelpSyn  = "Configuration of EST Endpoints"
+	pathConfigEstHelpDesc = "Here we configure:\n\nenabled=false, whether EST is enabled, defaults to false meaning that clusters will by default not get EST support,\ndefault_path_policy=\"sign-verbatim\", either \"forbid\", preventing the unlabeled est/ endpoints from being used at all, \"role:<role_name>\" which is the role to be used for unlabeled EST requests; or \"sign-verbatim\", meaning EST issuance will be equivalent to sign-verbatim,\nlabel_to_path_policy={}, a map of EST labels to either \"sign-verbatim\" or \"role:<role_name>\", served under est/<label>/."
+)
+
+type estConfigEntry struct {
+	Enabled           bool              `json:"enabled"`
+	DefaultPathPolicy string            `json:"default_path_policy"`
+	LabelToPathPolicy map[string]string `json:"label_to_path_policy"`
+}
+
+var defaultEstConfig = estConfigEntry{
+	Enabled:           false,
+	DefaultPathPolicy: "sign-verbatim",
+	LabelToPathPolicy: map[string]string{},
+}
+
+var enrollmentLabelRegex = regexp.MustCompile("^" + framework.GenericNameRegex("label") + "$")
+
+func (sc *storageContext) getEstConfig() (*estConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageEstConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping estConfigEntry
+	if entry == nil {
+		mapping = defaultEstConfig
+		mapping.LabelToPathPolicy = map[string]string{}
+		return &mapping, nil
+	}
+
+	if err := entry.DecodeJSON(&mapping); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode EST configuration: %v", err)}
+	}
+
+	if mapping.LabelToPathPolicy == nil {
+		mapping.LabelToPathPolicy = map[string]string{}
+	}
+
+	return &mapping, nil
+}
+
+func (sc *storageContext) setEstConfig(entry *estConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageEstConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathEstConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/est",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether EST is enabled, defaults to false meaning that clusters will by default not get EST support`,
+				Default:     false,
+			},
+			"default_path_policy": {
+				Type:        framework.TypeString,
+				Description: `the policy to be used for unlabeled EST requests under est/; either "forbid", "sign-verbatim" (the default) or a role to use as this policy, as "role:<role_name>"`,
+				Default:     "sign-verbatim",
+			},
+			"label_to_path_policy": {
+				Type:        framework.TypeKVPairs,
+				Description: `a map of EST labels, served under est/<label>/, to the policy used for requests on that label; each policy is either "sign-verbatim" or "role:<role_name>"`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "est-configuration",
+				},
+				Callback: b.pathEstRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathEstWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "est",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigEstHelpSyn,
+		HelpDescription: pathConfigEstHelpDesc,
+	}
+}
+
+func (b *backend) pathEstRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getEstConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromEstConfig(config), nil
+}
+
+func genResponseFromEstConfig(config *estConfigEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":              config.Enabled,
+			"default_path_policy":  config.DefaultPathPolicy,
+			"label_to_path_policy": config.LabelToPathPolicy,
+		},
+	}
+}
+
+func (b *backend) pathEstWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getEstConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if defaultPathPolicyRaw, ok := d.GetOk("default_path_policy"); ok {
+		config.DefaultPathPolicy = defaultPathPolicyRaw.(string)
+	}
+
+	if labelsRaw, ok := d.GetOk("label_to_path_policy"); ok {
+		config.LabelToPathPolicy = labelsRaw.(map[string]string)
+	}
+
+	if err := validateEnrollmentPathPolicy(sc, "EST", config.DefaultPathPolicy, true); err != nil {
+		return logical.ErrorResponse("invalid default_path_policy: %v", err), nil
+	}
+
+	labels := make([]string, 0, len(config.LabelToPathPolicy))
+	for label := range config.LabelToPathPolicy {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		if !enrollmentLabelRegex.MatchString(label) {
+			return logical.ErrorResponse("invalid EST label %q: labels may only contain alphanumeric characters, dashes, underscores and periods", label), nil
+		}
+
+		if err := validateEnrollmentPathPolicy(sc, "EST", config.LabelToPathPolicy[label], false); err != nil {
+			return logical.ErrorResponse("invalid path policy for EST label %q: %v", label, err), nil
+		}
+	}
+
+	if err := sc.setEstConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromEstConfig(config), nil
+}
+
+// validateEnrollmentPathPolicy validates a path policy of one of the
+// enrollment protocols (EST, SCEP): either "forbid" (only when allowForbid is
+// set), "sign-verbatim" or "role:<role_name>".
+func validateEnrollmentPathPolicy(sc *storageContext, protocol string, policy string, allowForbid bool) error {
+	policyType, extraInfo, err := getDefaultDirectoryPolicyType(policy)
+	if err != nil {
+		return err
+	}
+
+	switch policyType {
+	case Forbid:
+		if !allowForbid {
+			return fmt.Errorf("forbid is only valid as the default_path_policy; remove the label instead")
+		}
+	case SignVerbatim:
+	case Role:
+		role, err := sc.Backend.getRole(sc.Context, sc.Storage, extraInfo)
+		if err != nil {
+			return fmt.Errorf("failed loading role %v: %w", extraInfo, err)
+		}
+		if role == nil {
+			return fmt.Errorf("role %v does not exist", extraInfo)
+		}
+	default:
+		return fmt.Errorf("policy %v is not supported by %s", policy, protocol)
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageExpiryNotificationConfig       = "config/expiry-notifications"
+	pathConfigExpiryNotificationsHelpSyn  = "Configuration of notifications about certificates nearing expiry"
+	pathConfigExpiryNotificationsHelpDesc = "Here we configure:\n\nenabled=false, whether stored certificates are periodically scanned for ones nearing expiry,\nthresholds=720h,168h,24h, how long before expiry certificates are notified about,\nscan_interval=1h, how often stored certificates are scanned,\nsink=event, where notifications are sent: event for Vault's event system, or webhook,\nurl=\"\", the http or https url notifications are POSTed to by the webhook sink,\ntimeout=10s, how long to wait for the webhook to accept notifications,\nca_certificate=\"\", an optional PEM encoded CA bundle used to verify the webhook's TLS certificate."
+
+	expiryNotificationSinkEvent   = "event"
+	expiryNotificationSinkWebhook = "webhook"
+)
+
+type expiryNotificationConfigEntry struct {
+	Enabled       bool            `json:"enabled"`
+	Thresholds    []time.Duration `json:"thresholds"`
+	ScanInterval  time.Duration   `json:"scan_interval"`
+	Sink          string          `json:"sink"`
+	URL           string          `json:"url"`
+	Timeout       time.Duration   `json:"timeout"`
+	CACertificate string          `json:"ca_certificate"`
+}
+
+var defaultExpiryNotificationConfig = expiryNotificationConfigEntry{
+	Enabled:      false,
+	Thresholds:   []time.Duration{30 * 24 * time.Hour, 7 * 24 * time.Hour, 24 * time.Hour},
+	ScanInterval: time.Hour,
+	Sink:         expiryNotificationSinkEvent,
+	Timeout:      10 * time.Second,
+}
+
+func (sc *storageContext) getExpiryNotificationConfig() (*expiryNotificationConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageExpiryNotificationConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping expiryNotificationConfigEntry
+	if entry == nil {
+		mapping = defaultExpiryNotificationConfig
+		mapping.Thresholds = append([]time.Duration{}, defaultExpiryNotificationConfig.Thresholds...)
+		return &mapping, nil
+	}
+
+	if err := entry.DecodeJSON(&mapping); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode expiry notification configuration: %v", err)}
+	}
+
+	return &mapping, nil
+}
+
+func (sc *storageContext) setExpiryNotificationConfig(entry *expiryNotificationConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageExpiryNotificationConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathConfigExpiryNotifications(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/expiry-notifications",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether stored certificates are periodically scanned for ones nearing expiry, defaults to false`,
+				Default:     false,
+			},
+			"thresholds": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: `how long before expiry certificates are notified about, as a list of durations; each certificate is notified about once per threshold it crosses, defaults to 720h,168h,24h`,
+				Default:     []string{"720h", "168h", "24h"},
+			},
+			"scan_interval": {
+				Type:        framework.TypeDurationSecond,
+				Description: `how often stored certificates are scanned, defaults to 1h`,
+				Default:     "1h",
+			},
+			"sink": {
+				Type:          framework.TypeString,
+				Description:   `where notifications are sent: event, to send pki/expiring events through Vault's event system, or webhook, to POST them to url; defaults to event`,
+				Default:       expiryNotificationSinkEvent,
+				AllowedValues: []interface{}{expiryNotificationSinkEvent, expiryNotificationSinkWebhook},
+			},
+			"url": {
+				Type:        framework.TypeString,
+				Description: `the http or https url notifications are POSTed to; required by the webhook sink`,
+			},
+			"timeout": {
+				Type:        framework.TypeDurationSecond,
+				Description: `how long to wait for the webhook to accept notifications, defaults to 10s`,
+				Default:     "10s",
+			},
+			"ca_certificate": {
+				Type:        framework.TypeString,
+				Description: `an optional PEM encoded CA bundle used to verify the webhook's TLS certificate in place of the system roots`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "expiry-notifications-configuration",
+				},
+				Callback: b.pathExpiryNotificationsRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathExpiryNotificationsWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "expiry-notifications",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigExpiryNotificationsHelpSyn,
+		HelpDescription: pathConfigExpiryNotificationsHelpDesc,
+	}
+}
+
+func (b *backend) pathExpiryNotificationsRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getExpiryNotificationConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := genResponseFromExpiryNotificationConfig(config)
+
+	// Each cluster scans the certificates it stores itself.
+	state, err := sc.getExpiryNotificationState()
+	if err != nil {
+		return nil, err
+	}
+	if !state.LastScan.IsZero() {
+		resp.Data["last_scan"] = state.LastScan.Format(time.RFC3339)
+	}
+
+	return resp, nil
+}
+
+func genResponseFromExpiryNotificationConfig(config *expiryNotificationConfigEntry) *logical.Response {
+	thresholds := make([]string, 0, len(config.Thresholds))
+	for _, threshold := range config.Thresholds {
+		thresholds = append(thresholds, threshold.String())
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":        config.Enabled,
+			"thresholds":     thresholds,
+			"scan_interval":  int64(config.ScanInterval.Seconds()),
+			"sink":           config.Sink,
+			"url":            config.URL,
+			"timeout":        int64(config.Timeout.Seconds()),
+			"ca_certificate": config.CACertificate,
+			"last_scan":      "",
+		},
+	}
+}
+
+func (b *backend) pathExpiryNotificationsWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getExpiryNotificationConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if thresholdsRaw, ok := d.GetOk("thresholds"); ok {
+		config.Thresholds = nil
+		for _, thresholdStr := range thresholdsRaw.([]string) {
+			threshold, err := parseutil.ParseDurationSecond(thresholdStr)
+			if err != nil {
+				return logical.ErrorResponse("invalid threshold %q: %v", thresholdStr, err), nil
+			}
+			if threshold <= 0 {
+				return logical.ErrorResponse("invalid threshold %q: must be positive", thresholdStr), nil
+			}
+			config.Thresholds = append(config.Thresholds, threshold)
+		}
+
+		// Keep the longest threshold first, as they are crossed.
+		sort.Slice(config.Thresholds, func(i, j int) bool {
+			return config.Thresholds[i] > config.Thresholds[j]
+		})
+	}
+
+	if scanIntervalRaw, ok := d.GetOk("scan_interval"); ok {
+		config.ScanInterval = time.Duration(scanIntervalRaw.(int)) * time.Second
+	}
+
+	if sinkRaw, ok := d.GetOk("sink"); ok {
+		config.Sink = sinkRaw.(string)
+	}
+
+	if urlRaw, ok := d.GetOk("url"); ok {
+		config.URL = urlRaw.(string)
+	}
+
+	if timeoutRaw, ok := d.GetOk("timeout"); ok {
+		config.Timeout = time.Duration(timeoutRaw.(int)) * time.Second
+	}
+
+	if caRaw, ok := d.GetOk("ca_certificate"); ok {
+		config.CACertificate = caRaw.(string)
+	}
+
+	if config.Enabled && len(config.Thresholds) == 0 {
+		return logical.ErrorResponse("at least one threshold is required when expiry notifications are enabled"), nil
+	}
+
+	if config.ScanInterval <= 0 {
+		return logical.ErrorResponse("scan_interval must be positive"), nil
+	}
+
+	switch config.Sink {
+	case expiryNotificationSinkEvent, expiryNotificationSinkWebhook:
+	default:
+		return logical.ErrorResponse("invalid sink %q: must be %v or %v", config.Sink, expiryNotificationSinkEvent, expiryNotificationSinkWebhook), nil
+	}
+
+	if config.URL != "" {
+		parsed, err := url.Parse(config.URL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return logical.ErrorResponse("invalid url %q: must be an absolute http or https url", config.URL), nil
+		}
+	} else if config.Enabled && config.Sink == expiryNotificationSinkWebhook {
+		return logical.ErrorResponse("url is required by the webhook sink"), nil
+	}
+
+	if config.Timeout <= 0 {
+		return logical.ErrorResponse("timeout must be positive"), nil
+	}
+
+	if config.CACertificate != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(config.CACertificate)) {
+			return logical.ErrorResponse("ca_certificate contains no PEM encoded certificates"), nil
+		}
+	}
+
+	if err := sc.setExpiryNotificationConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromExpiryNotificationConfig(config), nil
+}
@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageIssuanceLimitsConfig      = "config/issuance-limits"
+	pathConfigIssuanceLimitsHelpSyn  = "Configuration of the issuance rate limits of this mount"
+	pathConfigIssuanceLimitsHelpDesc = "Here we configure:\n\ncerts_per_minute=0, the number of leaf certificates this mount issues per minute, across all roles,\nmax_concurrent_signs=0, the number of leaf certificates this mount signs concurrently,\nmax_stored_certificates=0, the number of stored certificates beyond which issuance of certificates to be stored is refused.\n\nA limit of zero disables it. Rate limits are enforced by each node independently; roles can set stricter rate limits of their own."
+)
+
+type issuanceLimitsConfigEntry struct {
+	CertsPerMinute     int `json:"certs_per_minute"`
+	MaxConcurrentSigns int `json:"max_concurrent_signs"`
+	// MaxStoredCertificates caps the stored certificate count maintained
+	// when maintain_stored_certificate_counts is enabled.
+	MaxStoredCertificates int `json:"max_stored_certificates"`
+}
+
+var defaultIssuanceLimitsConfig = issuanceLimitsConfigEntry{}
+
+func (sc *storageContext) getIssuanceLimitsConfig() (*issuanceLimitsConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageIssuanceLimitsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping issuanceLimitsConfigEntry
+	if entry == nil {
+		mapping = defaultIssuanceLimitsConfig
+		return &mapping, nil
+	}
+
+	if err := entry.DecodeJSON(&mapping); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode issuance limits configuration: %v", err)}
+	}
+
+	return &mapping, nil
+}
+
+func (sc *storageContext) setIssuanceLimitsConfig(entry *issuanceLimitsConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageIssuanceLimitsConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathConfigIssuanceLimits(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/issuance-limits",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"certs_per_minute": {
+				Type:        framework.TypeInt,
+				Description: `the number of leaf certificates this mount issues per minute, across all roles; 0, the default, disables the limit`,
+				Default:     0,
+			},
+			"max_concurrent_signs": {
+				Type:        framework.TypeInt,
+				Description: `the number of leaf certificates this mount signs concurrently; 0, the default, disables the limit`,
+				Default:     0,
+			},
+			"max_stored_certificates": {
+				Type:        framework.TypeInt,
+				Description: `the number of stored certificates beyond which issuance of certificates to be stored is refused; requires maintain_stored_certificate_counts in config/auto-tidy. 0, the default, disables the limit`,
+				Default:     0,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "issuance-limits-configuration",
+				},
+				Callback: b.pathIssuanceLimitsRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathIssuanceLimitsWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "issuance-limits",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigIssuanceLimitsHelpSyn,
+		HelpDescription: pathConfigIssuanceLimitsHelpDesc,
+	}
+}
+
+func (b *backend) pathIssuanceLimitsRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getIssuanceLimitsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromIssuanceLimitsConfig(config), nil
+}
+
+func genResponseFromIssuanceLimitsConfig(config *issuanceLimitsConfigEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"certs_per_minute":        config.CertsPerMinute,
+			"max_concurrent_signs":    config.MaxConcurrentSigns,
+			"max_stored_certificates": config.MaxStoredCertificates,
+		},
+	}
+}
+
+func (b *backend) pathIssuanceLimitsWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getIssuanceLimitsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if certsPerMinuteRaw, ok := d.GetOk("certs_per_minute"); ok {
+		config.CertsPerMinute = certsPerMinuteRaw.(int)
+	}
+
+	if maxConcurrentSignsRaw, ok := d.GetOk("max_concurrent_signs"); ok {
+		config.MaxConcurrentSigns = maxConcurrentSignsRaw.(int)
+	}
+
+	if config.CertsPerMinute < 0 {
+		return logical.ErrorResponse("certs_per_minute must not be negative"), nil
+	}
+
+	if config.MaxConcurrentSigns < 0 {
+		return logical.ErrorResponse("max_concurrent_signs must not be negative"), nil
+	}
+
+	if maxStoredRaw, ok := d.GetOk("max_stored_certificates"); ok {
+		config.MaxStoredCertificates = maxStoredRaw.(int)
+		if config.MaxStoredCertificates < 0 {
+			return logical.ErrorResponse("max_stored_certificates must not be negative"), nil
+		}
+
+		if config.MaxStoredCertificates > 0 {
+			tidyConfig, err := sc.getAutoTidyConfig()
+			if err != nil {
+				return nil, err
+			}
+			if !tidyConfig.MaintainCount {
+				return logical.ErrorResponse("max_stored_certificates requires maintain_stored_certificate_counts to be enabled in config/auto-tidy"), nil
+			}
+		}
+	}
+
+	if err := sc.setIssuanceLimitsConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromIssuanceLimitsConfig(config), nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storagePolicyWebhookConfig      = "config/policy-webhook"
+	pathConfigPolicyWebhookHelpSyn  = "Configuration of the external issuance policy webhook"
+	pathConfigPolicyWebhookHelpDesc = "Here we configure:\n\nenabled=false, whether the webhook is consulted before issuing leaf certificates,\nurl=\"\", the http or https url the parsed request is POSTed to,\ntimeout=10s, how long to wait for the webhook's decision,\nfail_open=false, whether to issue certificates, with a warning, when the webhook cannot be reached,\nca_certificate=\"\", an optional PEM encoded CA bundle used to verify the webhook's TLS certificate."
+)
+
+type policyWebhookConfigEntry struct {
+	Enabled       bool          `json:"enabled"`
+	URL           string        `json:"url"`
+	Timeout       time.Duration `json:"timeout"`
+	FailOpen      bool          `json:"fail_open"`
+	CACertificate string        `json:"ca_certificate"`
+}
+
+var defaultPolicyWebhookConfig = policyWebhookConfigEntry{
+	Enabled: false,
+	Timeout: 10 * time.Second,
+}
+
+func (sc *storageContext) getPolicyWebhookConfig() (*policyWebhookConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storagePolicyWebhookConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping policyWebhookConfigEntry
+	if entry == nil {
+		mapping = defaultPolicyWebhookConfig
+		return &mapping, nil
+	}
+
+	if err := entry.DecodeJSON(&mapping); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode policy webhook configuration: %v", err)}
+	}
+
+	return &mapping, nil
+}
+
+func (sc *storageContext) setPolicyWebhookConfig(entry *policyWebhookConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storagePolicyWebhookConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathConfigPolicyWebhook(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/policy-webhook",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether the webhook is consulted before issuing leaf certificates, defaults to false`,
+				Default:     false,
+			},
+			"url": {
+				Type:        framework.TypeString,
+				Description: `the http or https url the parsed request and requester identity are POSTed to; required when enabled`,
+			},
+			"timeout": {
+				Type:        framework.TypeDurationSecond,
+				Description: `how long to wait for the webhook's decision, defaults to 10s`,
+				Default:     "10s",
+			},
+			"fail_open": {
+				Type:        framework.TypeBool,
+				Description: `whether to issue certificates, with a warning, when the webhook cannot be reached or returns an invalid response; by default issuance is refused`,
+				Default:     false,
+			},
+			"ca_certificate": {
+				Type:        framework.TypeString,
+				Description: `an optional PEM encoded CA bundle used to verify the webhook's TLS certificate in place of the system roots`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "policy-webhook-configuration",
+				},
+				Callback: b.pathPolicyWebhookRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathPolicyWebhookWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "policy-webhook",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigPolicyWebhookHelpSyn,
+		HelpDescription: pathConfigPolicyWebhookHelpDesc,
+	}
+}
+
+func (b *backend) pathPolicyWebhookRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getPolicyWebhookConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromPolicyWebhookConfig(config), nil
+}
+
+func genResponseFromPolicyWebhookConfig(config *policyWebhookConfigEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":        config.Enabled,
+			"url":            config.URL,
+			"timeout":        int64(config.Timeout.Seconds()),
+			"fail_open":      config.FailOpen,
+			"ca_certificate": config.CACertificate,
+		},
+	}
+}
+
+func (b *backend) pathPolicyWebhookWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getPolicyWebhookConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if urlRaw, ok := d.GetOk("url"); ok {
+		config.URL = urlRaw.(string)
+	}
+
+	if timeoutRaw, ok := d.GetOk("timeout"); ok {
+		config.Timeout = time.Duration(timeoutRaw.(int)) * time.Second
+	}
+
+	if failOpenRaw, ok := d.GetOk("fail_open"); ok {
+		config.FailOpen = failOpenRaw.(bool)
+	}
+
+	if caRaw, ok := d.GetOk("ca_certificate"); ok {
+		config.CACertificate = caRaw.(string)
+	}
+
+	if config.URL != "" {
+		parsed, err := url.Parse(config.URL)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return logical.ErrorResponse("invalid url %q: must be an absolute http or https url", config.URL), nil
+		}
+	} else if config.Enabled {
+		return logical.ErrorResponse("url is required when the policy webhook is enabled"), nil
+	}
+
+	if config.Timeout <= 0 {
+		return logical.ErrorResponse("timeout must be positive"), nil
+	}
+
+	if config.CACertificate != "" {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(config.CACertificate)) {
+			return logical.ErrorResponse("ca_certificate contains no PEM encoded certificates"), nil
+		}
+	}
+
+	if err := sc.setPolicyWebhookConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromPolicyWebhookConfig(config), nil
+}
@@ -0,0 +1,279 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageScepConfig      = "config/scep"
+	pathConfigScepHelpSyn  = "Configuration of SCEP Endpoints"
+	pathConfigScepHelpDesc = "Here we configure:\n\nenabled=false, whether SCEP is enabled, defaults to false meaning that clusters will by default not get SCEP support,\ndefault_path_policy=\"sign-verbatim\", either \"forbid\", preventing the unlabeled scep endpoint from being used at all, \"role:<role_name>\" which is the role to be used for unlabeled SCEP requests; or \"sign-verbatim\", meaning SCEP issuance will be equivalent to sign-verbatim,\nlabel_to_path_policy={}, a map of SCEP labels to either \"sign-verbatim\" or \"role:<role_name>\", served under scep/<label>,\nchallenge_password=\"\", the challenge password initial enrollment requests must carry,\nra_certificate=\"\" and ra_key_ref=\"\", an optional RSA registration authority certificate and the key within this mount used to decrypt requests and sign responses in place of the issuer."
+)
+
+type scepConfigEntry struct {
+	Enabled           bool              `json:"enabled"`
+	DefaultPathPolicy string            `json:"default_path_policy"`
+	LabelToPathPolicy map[string]string `json:"label_to_path_policy"`
+	ChallengePassword string            `json:"challenge_password"`
+	RACertificate     string            `json:"ra_certificate"`
+	RAKeyID           keyID             `json:"ra_key_id"`
+}
+
+var defaultScepConfig = scepConfigEntry{
+	Enabled:           false,
+	DefaultPathPolicy: "sign-verbatim",
+	LabelToPathPolicy: map[string]string{},
+}
+
+func (sc *storageContext) getScepConfig() (*scepConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageScepConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapping scepConfigEntry
+	if entry == nil {
+		mapping = defaultScepConfig
+		mapping.LabelToPathPolicy = map[string]string{}
+		return &mapping, nil
+	}
+
+	if err := entry.DecodeJSON(&mapping); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode SCEP configuration: %v", err)}
+	}
+
+	if mapping.LabelToPathPolicy == nil {
+		mapping.LabelToPathPolicy = map[string]string{}
+	}
+
+	return &mapping, nil
+}
+
+func (sc *storageContext) setScepConfig(entry *scepConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageScepConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathScepConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/scep",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether SCEP is enabled, defaults to false meaning that clusters will by default not get SCEP support`,
+				Default:     false,
+			},
+			"default_path_policy": {
+				Type:        framework.TypeString,
+				Description: `the policy to be used for unlabeled SCEP requests under scep; either "forbid", "sign-verbatim" (the default) or a role to use as this policy, as "role:<role_name>"`,
+				Default:     "sign-verbatim",
+			},
+			"label_to_path_policy": {
+				Type:        framework.TypeKVPairs,
+				Description: `a map of SCEP labels, served under scep/<label>, to the policy used for requests on that label; each policy is either "sign-verbatim" or "role:<role_name>"`,
+			},
+			"challenge_password": {
+				Type:        framework.TypeString,
+				Description: `the challenge password initial enrollment requests must carry; initial enrollment is refused while unset. This value is never returned`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
+			"ra_certificate": {
+				Type:        framework.TypeString,
+				Description: `an optional PEM encoded RSA registration authority certificate, advertised by GetCACert and used to decrypt requests and sign responses in place of the issuer; must be set alongside ra_key_ref`,
+			},
+			"ra_key_ref": {
+				Type:        framework.TypeString,
+				Description: `a reference to the key within this mount matching ra_certificate`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "scep-configuration",
+				},
+				Callback: b.pathScepRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathScepWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "scep",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigScepHelpSyn,
+		HelpDescription: pathConfigScepHelpDesc,
+	}
+}
+
+func (b *backend) pathScepRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getScepConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromScepConfig(config), nil
+}
+
+func genResponseFromScepConfig(config *scepConfigEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":                config.Enabled,
+			"default_path_policy":    config.DefaultPathPolicy,
+			"label_to_path_policy":   config.LabelToPathPolicy,
+			"challenge_password_set": len(config.ChallengePassword) > 0,
+			"ra_certificate":         config.RACertificate,
+			"ra_key_ref":             config.RAKeyID.String(),
+		},
+	}
+}
+
+func (b *backend) pathScepWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getScepConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if defaultPathPolicyRaw, ok := d.GetOk("default_path_policy"); ok {
+		config.DefaultPathPolicy = defaultPathPolicyRaw.(string)
+	}
+
+	if labelsRaw, ok := d.GetOk("label_to_path_policy"); ok {
+		config.LabelToPathPolicy = labelsRaw.(map[string]string)
+	}
+
+	if challengeRaw, ok := d.GetOk("challenge_password"); ok {
+		config.ChallengePassword = challengeRaw.(string)
+	}
+
+	raCertRaw, raCertSet := d.GetOk("ra_certificate")
+	raKeyRaw, raKeySet := d.GetOk("ra_key_ref")
+	if raCertSet != raKeySet {
+		return logical.ErrorResponse("ra_certificate and ra_key_ref must be updated together"), nil
+	}
+	if raCertSet {
+		config.RACertificate = raCertRaw.(string)
+		config.RAKeyID = ""
+		if raKeyRaw.(string) != "" {
+			config.RAKeyID, err = sc.resolveKeyReference(raKeyRaw.(string))
+			if err != nil {
+				return logical.ErrorResponse("failed resolving ra_key_ref: %v", err), nil
+			}
+		}
+	}
+
+	if (config.RACertificate == "") != (config.RAKeyID == "") {
+		return logical.ErrorResponse("ra_certificate and ra_key_ref must either both be set or both be empty"), nil
+	}
+	if config.RAKeyID != "" {
+		if _, _, err := loadScepRA(sc, config); err != nil {
+			return logical.ErrorResponse("invalid registration authority: %v", err), nil
+		}
+	}
+
+	if err := validateEnrollmentPathPolicy(sc, "SCEP", config.DefaultPathPolicy, true); err != nil {
+		return logical.ErrorResponse("invalid default_path_policy: %v", err), nil
+	}
+
+	labels := make([]string, 0, len(config.LabelToPathPolicy))
+	for label := range config.LabelToPathPolicy {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		if !enrollmentLabelRegex.MatchString(label) {
+			return logical.ErrorResponse("invalid SCEP label %q: labels may only contain alphanumeric characters, dashes, underscores and periods", label), nil
+		}
+
+		if err := validateEnrollmentPathPolicy(sc, "SCEP", config.LabelToPathPolicy[label], false); err != nil {
+			return logical.ErrorResponse("invalid path policy for SCEP label %q: %v", label, err), nil
+		}
+	}
+
+	if err := sc.setScepConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromScepConfig(config), nil
+}
+
+// loadScepRA loads the configured registration authority certificate and
+// its RSA private key.
+func loadScepRA(sc *storageContext, config *scepConfigEntry) (*x509.Certificate, *rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(config.RACertificate))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, fmt.Errorf("ra_certificate is not a PEM encoded certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed parsing ra_certificate: %w", err)
+	}
+
+	key, err := sc.fetchKeyById(config.RAKeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if key.isManagedPrivateKey() {
+		return nil, nil, fmt.Errorf("managed keys cannot be used as SCEP registration authority keys")
+	}
+
+	signer, _, err := certutil.ParsePEMKey(key.PrivateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed parsing RA key: %w", err)
+	}
+
+	rsaKey, ok := signer.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("SCEP registration authority keys must be RSA keys")
+	}
+
+	certKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok || !rsaKey.PublicKey.Equal(certKey) {
+		return nil, nil, fmt.Errorf("ra_certificate does not match the key referenced by ra_key_ref")
+	}
+
+	return cert, rsaKey, nil
+}
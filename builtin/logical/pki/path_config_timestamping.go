@@ -0,0 +1,375 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	storageTimestampingConfig      = "config/timestamping"
+	pathConfigTimestampingHelpSyn  = "Configuration of the RFC 3161 timestamp authority"
+	pathConfigTimestampingHelpDesc = `
+This endpoint configures the timestamp authority served under timestamp,
+which countersigns the digests of artifacts, such as code signatures, so they
+remain verifiable once the signing certificate expires.
+
+Time-stamp tokens are signed with a key of this mount, key_ref, certified by a
+dedicated issuer, issuer_ref. When no certificate is given, the issuer signs
+one with the timeStamping extended key usage marked critical, as RFC 3161
+requires. The issuer's own key cannot be used, so that it is never used to
+sign anything but certificates.
+`
+)
+
+var (
+	oidExtensionExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+	oidExtKeyUsageTimeStamping   = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 8}
+)
+
+type timestampingConfigEntry struct {
+	Enabled     bool     `json:"enabled"`
+	IssuerID    issuerID `json:"issuer_id"`
+	KeyID       keyID    `json:"key_id"`
+	Certificate string   `json:"certificate"`
+	PolicyOID   string   `json:"policy_oid"`
+}
+
+func (sc *storageContext) getTimestampingConfig() (*timestampingConfigEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageTimestampingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var config timestampingConfigEntry
+	if entry == nil {
+		return &config, nil
+	}
+
+	if err := entry.DecodeJSON(&config); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode timestamping configuration: %v", err)}
+	}
+
+	return &config, nil
+}
+
+func (sc *storageContext) setTimestampingConfig(entry *timestampingConfigEntry) error {
+	json, err := logical.StorageEntryJSON(storageTimestampingConfig, entry)
+	if err != nil {
+		return fmt.Errorf("failed creating storage entry: %w", err)
+	}
+
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return fmt.Errorf("failed writing storage entry: %w", err)
+	}
+
+	return nil
+}
+
+func pathTimestampingConfig(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "config/timestamping",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"enabled": {
+				Type:        framework.TypeBool,
+				Description: `whether the timestamp authority is enabled, defaults to false`,
+				Default:     false,
+			},
+			issuerRefParam: {
+				Type:        framework.TypeString,
+				Description: `a reference to the dedicated issuer certifying the timestamp authority`,
+			},
+			keyRefParam: {
+				Type:        framework.TypeString,
+				Description: `a reference to the key within this mount signing time-stamp tokens; must not be the issuer's own key`,
+			},
+			"certificate": {
+				Type:        framework.TypeString,
+				Description: `an optional PEM encoded certificate of the key, signed by the issuer, with the timeStamping extended key usage as its only, critical, extended key usage; when not given, the issuer signs one`,
+			},
+			"policy_oid": {
+				Type:        framework.TypeString,
+				Description: `the TSA policy OID stamped into time-stamp tokens; requests for another policy are rejected`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationSuffix: "timestamping-configuration",
+				},
+				Callback: b.pathTimestampingRead,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathTimestampingWrite,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb:   "configure",
+					OperationSuffix: "timestamping",
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathConfigTimestampingHelpSyn,
+		HelpDescription: pathConfigTimestampingHelpDesc,
+	}
+}
+
+func (b *backend) pathTimestampingRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getTimestampingConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return genResponseFromTimestampingConfig(config), nil
+}
+
+func genResponseFromTimestampingConfig(config *timestampingConfigEntry) *logical.Response {
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"enabled":     config.Enabled,
+			"issuer_id":   config.IssuerID.String(),
+			"key_id":      config.KeyID.String(),
+			"certificate": config.Certificate,
+			"policy_oid":  config.PolicyOID,
+		},
+	}
+}
+
+func (b *backend) pathTimestampingWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot configure timestamping until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	config, err := sc.getTimestampingConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if enabledRaw, ok := d.GetOk("enabled"); ok {
+		config.Enabled = enabledRaw.(bool)
+	}
+
+	if policyRaw, ok := d.GetOk("policy_oid"); ok {
+		config.PolicyOID = policyRaw.(string)
+	}
+	if config.PolicyOID != "" {
+		if _, err := certutil.StringToOid(config.PolicyOID); err != nil {
+			return logical.ErrorResponse("%q could not be parsed as a valid policy_oid", config.PolicyOID), nil
+		}
+	}
+
+	issuerRefRaw, issuerSet := d.GetOk(issuerRefParam)
+	keyRefRaw, keySet := d.GetOk(keyRefParam)
+	certRaw, certSet := d.GetOk("certificate")
+	if issuerSet || keySet || certSet {
+		if !issuerSet || !keySet {
+			return logical.ErrorResponse("%v and %v must be updated together", issuerRefParam, keyRefParam), nil
+		}
+
+		issuerId, err := sc.resolveIssuerReference(issuerRefRaw.(string))
+		if err != nil {
+			if issuerId == IssuerRefNotFound {
+				return logical.ErrorResponse("unable to resolve issuer id for reference: %v", issuerRefRaw), nil
+			}
+			return nil, err
+		}
+		issuer, err := sc.fetchIssuerById(issuerId)
+		if err != nil {
+			return nil, err
+		}
+
+		keyId, err := sc.resolveKeyReference(keyRefRaw.(string))
+		if err != nil {
+			if keyId == KeyRefNotFound {
+				return logical.ErrorResponse("unable to resolve key id for reference: %v", keyRefRaw), nil
+			}
+			return nil, err
+		}
+		if keyId == issuer.KeyID {
+			return logical.ErrorResponse("key %v is the issuer's own key", keyId), nil
+		}
+
+		key, err := sc.fetchKeyById(keyId)
+		if err != nil {
+			return nil, err
+		}
+		if key.isManagedPrivateKey() {
+			return logical.ErrorResponse("managed keys cannot be used to sign time-stamp tokens"), nil
+		}
+
+		var signerCert *x509.Certificate
+		if certSet && certRaw.(string) != "" {
+			signerCert, err = validateTimestampingCertificate(sc, issuer, key, certRaw.(string))
+			if err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+		} else {
+			publicKey, err := getPublicKey(sc.Context, b, key)
+			if err != nil {
+				return nil, err
+			}
+			signerCert, err = issueTimestampingCertificate(sc, issuer, publicKey)
+			if err != nil {
+				return nil, err
+			}
+
+			// Track the certificate like any other issued one, so it shows
+			// up in certs/ listings and can be revoked.
+			if err := storeCertificate(sc, &certutil.ParsedCertBundle{Certificate: signerCert, CertificateBytes: signerCert.Raw}); err != nil {
+				return nil, err
+			}
+		}
+
+		config.IssuerID = issuerId
+		config.KeyID = keyId
+		config.Certificate = strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signerCert.Raw})))
+	}
+
+	if config.Enabled {
+		if config.Certificate == "" {
+			return logical.ErrorResponse("%v and %v are required to enable timestamping", issuerRefParam, keyRefParam), nil
+		}
+		if config.PolicyOID == "" {
+			return logical.ErrorResponse("policy_oid is required to enable timestamping"), nil
+		}
+	}
+
+	if err := sc.setTimestampingConfig(config); err != nil {
+		return nil, fmt.Errorf("failed persisting: %w", err)
+	}
+
+	return genResponseFromTimestampingConfig(config), nil
+}
+
+// validateTimestampingCertificate checks that a provided certificate may
+// sign time-stamp tokens: it must be signed by the issuer, certify the key
+// and carry the timeStamping extended key usage as its only, critical,
+// extended key usage, per RFC 3161 Section 2.3.
+func validateTimestampingCertificate(sc *storageContext, issuer *issuerEntry, key *keyEntry, certPem string) (*x509.Certificate, error) {
+	block, rest := pem.Decode([]byte(certPem))
+	if block == nil || len(strings.TrimSpace(string(rest))) != 0 {
+		return nil, fmt.Errorf("invalid certificate; should be one PEM block only")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	issuerCert, err := issuer.GetCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing issuer certificate value: %w", err)
+	}
+
+	if err := cert.CheckSignatureFrom(issuerCert); err != nil {
+		return nil, fmt.Errorf("certificate was not signed by issuer %v: %w", issuer.ID, err)
+	}
+	if len(cert.ExtKeyUsage) != 1 || cert.ExtKeyUsage[0] != x509.ExtKeyUsageTimeStamping || len(cert.UnknownExtKeyUsage) != 0 {
+		return nil, fmt.Errorf("certificate must have timeStamping as its only extended key usage")
+	}
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidExtensionExtendedKeyUsage) && !ext.Critical {
+			return nil, fmt.Errorf("certificate must mark its extended key usage extension critical")
+		}
+	}
+
+	equal, err := comparePublicKey(sc, key, cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !equal {
+		return nil, fmt.Errorf("certificate doesn't match key %v", key.ID)
+	}
+
+	return cert, nil
+}
+
+// issueTimestampingCertificate has the issuer certify the timestamp
+// authority's key, naming it after the issuer.
+func issueTimestampingCertificate(sc *storageContext, issuer *issuerEntry, publicKey crypto.PublicKey) (*x509.Certificate, error) {
+	signingBundle, err := sc.fetchCAInfoByIssuerId(issuer.ID, IssuanceUsage)
+	if err != nil {
+		return nil, err
+	}
+	caCert := signingBundle.Certificate
+
+	serialNumber, err := certutil.GenerateSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	// Go never marks the extended key usage extension critical, so it is
+	// given explicitly.
+	extKeyUsage, err := asn1.Marshal([]asn1.ObjectIdentifier{oidExtKeyUsageTimeStamping})
+	if err != nil {
+		return nil, err
+	}
+
+	subject := caCert.Subject
+	subject.CommonName = strings.TrimSpace(subject.CommonName + " Timestamp Authority")
+	subject.ExtraNames = nil
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-30 * time.Second),
+		NotAfter:     caCert.NotAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidExtensionExtendedKeyUsage, Critical: true, Value: extKeyUsage},
+		},
+		BasicConstraintsValid: true,
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, caCert, publicKey, signingBundle.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign timestamp authority certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(certBytes)
+}
+
+// fetchTimestampingSigner loads the certificate and key signing time-stamp
+// tokens.
+func (sc *storageContext) fetchTimestampingSigner(config *timestampingConfigEntry) (*certutil.ParsedCertBundle, error) {
+	key, err := sc.fetchKeyById(config.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := parseCABundle(sc.Context, sc.Backend, &certutil.CertBundle{
+		Certificate:    config.Certificate,
+		PrivateKeyType: key.PrivateKeyType,
+		PrivateKey:     key.PrivateKey,
+	})
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse timestamp authority signer: %v", err)}
+	}
+
+	return signer, nil
+}
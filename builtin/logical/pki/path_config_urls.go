@@ -41,6 +41,13 @@ for the CRL distribution points attribute. See also RFC 5280 Section 4.2.1.13.`,
 for the OCSP servers attribute. See also RFC 5280 Section 4.2.2.1.`,
 			},
 
+			"delta_crl_distribution_points": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `Comma-separated list of URLs to be used
+for the Freshest CRL extension on complete CRLs, pointing at the delta CRL.
+Only used when delta CRLs are enabled. See also RFC 5280 Section 5.2.6.`,
+			},
+
 			"enable_templating": {
 				Type: framework.TypeBool,
 				Description: `Whether or not to enabling templating of the
@@ -79,6 +86,12 @@ for the CRL distribution points attribute. See also RFC 5280 Section 4.2.1.13.`,
 								Type: framework.TypeCommaStringSlice,
 								Description: `Comma-separated list of URLs to be used
 for the OCSP servers attribute. See also RFC 5280 Section 4.2.2.1.`,
+							},
+							"delta_crl_distribution_points": {
+								Type: framework.TypeCommaStringSlice,
+								Description: `Comma-separated list of URLs to be used
+for the Freshest CRL extension on complete CRLs, pointing at the delta CRL.
+See also RFC 5280 Section 5.2.6.`,
 							},
 							"enable_templating": {
 								Type: framework.TypeBool,
@@ -120,6 +133,13 @@ for the CRL distribution points attribute. See also RFC 5280 Section 4.2.1.13.`,
 for the OCSP servers attribute. See also RFC 5280 Section 4.2.2.1.`,
 								Required: true,
 							},
+							"delta_crl_distribution_points": {
+								Type: framework.TypeCommaStringSlice,
+								Description: `Comma-separated list of URLs to be used
+for the Freshest CRL extension on complete CRLs, pointing at the delta CRL.
+See also RFC 5280 Section 5.2.6.`,
+								Required: true,
+							},
 							"enable_templating": {
 								Type: framework.TypeBool,
 								Description: `Whether or not to enable templating of the
@@ -157,10 +177,11 @@ func getGlobalAIAURLs(ctx context.Context, storage logical.Storage) (*aiaConfigE
 	}
 
 	entries := &aiaConfigEntry{
-		IssuingCertificates:   []string{},
-		CRLDistributionPoints: []string{},
-		OCSPServers:           []string{},
-		EnableTemplating:      false,
+		IssuingCertificates:        []string{},
+		CRLDistributionPoints:      []string{},
+		OCSPServers:                []string{},
+		EnableTemplating:           false,
+		DeltaCRLDistributionPoints: []string{},
 	}
 
 	if entry == nil {
@@ -199,10 +220,11 @@ func (b *backend) pathReadURL(ctx context.Context, req *logical.Request, _ *fram
 
 	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"issuing_certificates":    entries.IssuingCertificates,
-			"crl_distribution_points": entries.CRLDistributionPoints,
-			"ocsp_servers":            entries.OCSPServers,
-			"enable_templating":       entries.EnableTemplating,
+			"issuing_certificates":          entries.IssuingCertificates,
+			"crl_distribution_points":       entries.CRLDistributionPoints,
+			"ocsp_servers":                  entries.OCSPServers,
+			"delta_crl_distribution_points": entries.DeltaCRLDistributionPoints,
+			"enable_templating":             entries.EnableTemplating,
 		},
 	}
 
@@ -227,13 +249,17 @@ func (b *backend) pathWriteURL(ctx context.Context, req *logical.Request, data *
 	if urlsInt, ok := data.GetOk("ocsp_servers"); ok {
 		entries.OCSPServers = urlsInt.([]string)
 	}
+	if urlsInt, ok := data.GetOk("delta_crl_distribution_points"); ok {
+		entries.DeltaCRLDistributionPoints = urlsInt.([]string)
+	}
 
 	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"issuing_certificates":    entries.IssuingCertificates,
-			"crl_distribution_points": entries.CRLDistributionPoints,
-			"ocsp_servers":            entries.OCSPServers,
-			"enable_templating":       entries.EnableTemplating,
+			"issuing_certificates":          entries.IssuingCertificates,
+			"crl_distribution_points":       entries.CRLDistributionPoints,
+			"ocsp_servers":                  entries.OCSPServers,
+			"delta_crl_distribution_points": entries.DeltaCRLDistributionPoints,
+			"enable_templating":             entries.EnableTemplating,
 		},
 	}
 
@@ -270,6 +296,11 @@ func (b *backend) pathWriteURL(ctx context.Context, req *logical.Request, data *
 			return logical.ErrorResponse(fmt.Sprintf(
 				"invalid URL found in Authority Information Access (AIA) parameter ocsp_servers: %s", badURL)), nil
 		}
+
+		if badURL := validateURLs(entries.DeltaCRLDistributionPoints); badURL != "" {
+			return logical.ErrorResponse(fmt.Sprintf(
+				"invalid URL found in Authority Information Access (AIA) parameter delta_crl_distribution_points: %s", badURL)), nil
+		}
 	}
 
 	if err := writeURLs(ctx, req.Storage, entries); err != nil {
@@ -290,5 +321,8 @@ values are not set, no such information will be encoded in the issued
 certificates. To delete URLs, simply re-set the appropriate value with an
 empty string.
 
+The delta CRL distribution points are instead encoded into the Freshest CRL
+extension of complete CRLs when delta CRLs are enabled.
+
 Multiple URLs can be specified for each type; use commas to separate them.
 `
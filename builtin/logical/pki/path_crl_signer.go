@@ -0,0 +1,481 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// crlSignerEntry associates a separate key with an issuer, used in place of
+// the issuer's own key to sign its CRLs and OCSP responses. The certificate
+// carries the issuer's subject, so CRLs signed by it name the same issuer,
+// and is signed by the issuer, so clients can validate it.
+type crlSignerEntry struct {
+	KeyID       keyID  `json:"key_id"`
+	Certificate string `json:"certificate"`
+}
+
+func pathIssuerCRLSigner(b *backend) *framework.Path {
+	fields := addIssuerRefField(map[string]*framework.FieldSchema{})
+	fields[keyRefParam] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `Reference to the existing key to sign CRLs and
+OCSP responses with; either an identifier or the name assigned to the key.
+Must not be the issuer's own key.`,
+	}
+	fields["certificate"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `PEM encoded certificate of the key, signed by the
+issuer and with the issuer's subject. When not given, the issuer signs one
+with the cRLSign key usage and the OCSPSigning extended key usage.`,
+	}
+
+	responseFields := map[string]*framework.FieldSchema{
+		"issuer_id": {
+			Type:        framework.TypeString,
+			Description: `Issuer Id`,
+			Required:    true,
+		},
+		"key_id": {
+			Type:        framework.TypeString,
+			Description: `Id of the key signing the issuer's CRLs and OCSP responses`,
+			Required:    true,
+		},
+		"certificate": {
+			Type:        framework.TypeString,
+			Description: `Certificate of the key signing the issuer's CRLs and OCSP responses`,
+			Required:    true,
+		},
+		"serial_number": {
+			Type:        framework.TypeString,
+			Description: `Serial number of the certificate`,
+			Required:    true,
+		},
+	}
+
+	return &framework.Path{
+		Pattern: "issuer/" + framework.GenericNameRegex(issuerRefParam) + "/crl-signer",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKIIssuer,
+			OperationSuffix: "crl-signer",
+		},
+
+		Fields: fields,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathReadIssuerCRLSigner,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "read",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      responseFields,
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathWriteIssuerCRLSigner,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "write",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      responseFields,
+					}},
+				},
+				// Read more about why these flags are set in backend.go
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathDeleteIssuerCRLSigner,
+				DisplayAttrs: &framework.DisplayAttributes{
+					OperationVerb: "delete",
+				},
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+				// Read more about why these flags are set in backend.go
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathIssuerCRLSignerHelpSyn,
+		HelpDescription: pathIssuerCRLSignerHelpDesc,
+	}
+}
+
+func (b *backend) pathReadIssuerCRLSigner(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot read CRL signer until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	issuer, resp, err := resolveCRLSignerIssuer(sc, data)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if issuer.CRLSigner == nil {
+		return nil, nil
+	}
+
+	return respondReadCRLSigner(issuer)
+}
+
+func (b *backend) pathWriteIssuerCRLSigner(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Since we're modifying the issuer, grab a lock.
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot set CRL signer until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	issuer, resp, err := resolveCRLSignerIssuer(sc, data)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	keyRef := data.Get(keyRefParam).(string)
+	if len(keyRef) == 0 {
+		return logical.ErrorResponse("missing key reference"), nil
+	}
+	keyId, err := sc.resolveKeyReference(keyRef)
+	if err != nil {
+		if keyId == KeyRefNotFound {
+			return logical.ErrorResponse("unable to resolve key id for reference: " + keyRef), nil
+		}
+		return nil, err
+	}
+	if keyId == issuer.KeyID {
+		return logical.ErrorResponse(fmt.Sprintf("key %v is the issuer's own key", keyId)), nil
+	}
+
+	key, err := sc.fetchKeyById(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	var signerCert *x509.Certificate
+	if certPem := data.Get("certificate").(string); len(certPem) > 0 {
+		signerCert, err = validateCRLSignerCertificate(sc, issuer, key, certPem)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	} else {
+		publicKey, err := getPublicKey(sc.Context, b, key)
+		if err != nil {
+			return nil, err
+		}
+		signerCert, err = issueCRLSignerCertificate(sc, issuer, publicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Track the certificate like any other issued one, so it shows up in
+	// certs/ listings and can be revoked.
+	if err := storeCertificate(sc, &certutil.ParsedCertBundle{Certificate: signerCert, CertificateBytes: signerCert.Raw}); err != nil {
+		return nil, err
+	}
+
+	issuer.CRLSigner = &crlSignerEntry{
+		KeyID:       keyId,
+		Certificate: strings.TrimSpace(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signerCert.Raw}))),
+	}
+	if err := sc.writeIssuer(issuer); err != nil {
+		return nil, err
+	}
+
+	response, err := respondReadCRLSigner(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.rebuildForCRLSigner(sc, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (b *backend) pathDeleteIssuerCRLSigner(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Since we're modifying the issuer, grab a lock.
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot delete CRL signer until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	issuer, resp, err := resolveCRLSignerIssuer(sc, data)
+	if resp != nil || err != nil {
+		return resp, err
+	}
+
+	if issuer.CRLSigner == nil {
+		return nil, nil
+	}
+
+	issuer.CRLSigner = nil
+	if err := sc.writeIssuer(issuer); err != nil {
+		return nil, err
+	}
+
+	response := &logical.Response{}
+	if err := b.rebuildForCRLSigner(sc, response); err != nil {
+		return nil, err
+	}
+	if len(response.Warnings) == 0 {
+		return nil, nil
+	}
+
+	return response, nil
+}
+
+func resolveCRLSignerIssuer(sc *storageContext, data *framework.FieldData) (*issuerEntry, *logical.Response, error) {
+	issuerRef := getIssuerRef(data)
+	if len(issuerRef) == 0 {
+		return nil, logical.ErrorResponse("missing issuer reference"), nil
+	}
+
+	issuerId, err := sc.resolveIssuerReference(issuerRef)
+	if err != nil {
+		if issuerId == IssuerRefNotFound {
+			return nil, logical.ErrorResponse("unable to resolve issuer id for reference: " + issuerRef), nil
+		}
+		return nil, nil, err
+	}
+
+	issuer, err := sc.fetchIssuerById(issuerId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return issuer, nil, nil
+}
+
+func respondReadCRLSigner(issuer *issuerEntry) (*logical.Response, error) {
+	cert, err := issuer.CRLSigner.GetCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"issuer_id":     issuer.ID,
+			"key_id":        issuer.CRLSigner.KeyID,
+			"certificate":   issuer.CRLSigner.Certificate,
+			"serial_number": serialFromCert(cert),
+		},
+	}, nil
+}
+
+// rebuildForCRLSigner re-signs the CRLs and drops cached OCSP responses
+// after the signer of an issuer changed.
+func (b *backend) rebuildForCRLSigner(sc *storageContext, response *logical.Response) error {
+	b.ocspCache.purge()
+
+	warnings, err := b.crlBuilder.rebuild(sc, true)
+	if err != nil {
+		return err
+	}
+	for index, warning := range warnings {
+		response.AddWarning(fmt.Sprintf("Warning %d during CRL rebuild: %v", index+1, warning))
+	}
+
+	return nil
+}
+
+// validateCRLSignerCertificate checks that a provided certificate can stand
+// in for the issuer when signing CRLs: it must name the issuer as subject,
+// be signed by it, allow CRL signing, carry a subject key identifier and
+// certify the signer's key.
+func validateCRLSignerCertificate(sc *storageContext, issuer *issuerEntry, key *keyEntry, certPem string) (*x509.Certificate, error) {
+	block, rest := pem.Decode([]byte(certPem))
+	if block == nil || len(strings.TrimSpace(string(rest))) != 0 {
+		return nil, fmt.Errorf("invalid certificate; should be one PEM block only")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	issuerCert, err := issuer.GetCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing issuer certificate value: %w", err)
+	}
+
+	if string(cert.RawSubject) != string(issuerCert.RawSubject) {
+		return nil, fmt.Errorf("certificate subject %q doesn't match the issuer's subject %q", cert.Subject, issuerCert.Subject)
+	}
+	if err := cert.CheckSignatureFrom(issuerCert); err != nil {
+		return nil, fmt.Errorf("certificate was not signed by issuer %v: %w", issuer.ID, err)
+	}
+	if cert.KeyUsage&x509.KeyUsageCRLSign == 0 {
+		return nil, fmt.Errorf("certificate lacks the cRLSign key usage")
+	}
+	if len(cert.SubjectKeyId) == 0 {
+		return nil, fmt.Errorf("certificate lacks a subject key identifier, which CRLs refer to")
+	}
+
+	equal, err := comparePublicKey(sc, key, cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if !equal {
+		return nil, fmt.Errorf("certificate doesn't match key %v", key.ID)
+	}
+
+	return cert, nil
+}
+
+// issueCRLSignerCertificate has the issuer certify the signer's key under
+// its own subject. This is the only time the issuer's key gets used.
+func issueCRLSignerCertificate(sc *storageContext, issuer *issuerEntry, publicKey crypto.PublicKey) (*x509.Certificate, error) {
+	signingBundle, err := sc.fetchCAInfoByIssuerId(issuer.ID, CRLSigningUsage)
+	if err != nil {
+		return nil, err
+	}
+	caCert := signingBundle.Certificate
+
+	serialNumber, err := certutil.GenerateSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	// Go refuses to sign CRLs with a certificate lacking a subject key
+	// identifier, which it only generates by itself for CAs.
+	subjectKeyId, err := certutil.GetSubjectKeyID(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		RawSubject:            caCert.RawSubject,
+		SubjectKeyId:          subjectKeyId,
+		NotBefore:             time.Now().Add(-30 * time.Second),
+		NotAfter:              caCert.NotAfter,
+		KeyUsage:              x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning},
+		BasicConstraintsValid: true,
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, caCert, publicKey, signingBundle.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to sign CRL signer certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(certBytes)
+}
+
+// fetchCRLSigningBundle returns the bundle CRLs of the given issuer are
+// signed with: its CRL signer when one is set, and otherwise the issuer
+// itself.
+func (sc *storageContext) fetchCRLSigningBundle(issuerId issuerID) (*certutil.CAInfoBundle, error) {
+	if issuerId == legacyBundleShimID {
+		return sc.fetchCAInfoByIssuerId(issuerId, CRLSigningUsage)
+	}
+
+	issuer, err := sc.fetchIssuerById(issuerId)
+	if err != nil {
+		return nil, err
+	}
+	if issuer.CRLSigner == nil {
+		return sc.fetchCAInfoByIssuerId(issuerId, CRLSigningUsage)
+	}
+
+	if err := issuer.EnsureUsage(CRLSigningUsage); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error while attempting to use issuer %v: %v", issuerId, err)}
+	}
+
+	signer, err := sc.fetchCRLSigner(issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	// The issuer's revocation signature algorithm is only known to suit
+	// the signer if both use the same kind of key.
+	revocationSigAlg := x509.UnknownSignatureAlgorithm
+	if issuerCert, err := issuer.GetCertificate(); err == nil && issuerCert.PublicKeyAlgorithm == signer.Certificate.PublicKeyAlgorithm {
+		revocationSigAlg = issuer.RevocationSigAlg
+	}
+
+	return &certutil.CAInfoBundle{
+		ParsedCertBundle: *signer,
+		RevocationSigAlg: revocationSigAlg,
+	}, nil
+}
+
+// fetchCRLSigner loads the certificate and key of the issuer's CRL signer.
+func (sc *storageContext) fetchCRLSigner(issuer *issuerEntry) (*certutil.ParsedCertBundle, error) {
+	key, err := sc.fetchKeyById(issuer.CRLSigner.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := parseCABundle(sc.Context, sc.Backend, &certutil.CertBundle{
+		Certificate:    issuer.CRLSigner.Certificate,
+		PrivateKeyType: key.PrivateKeyType,
+		PrivateKey:     key.PrivateKey,
+	})
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to parse CRL signer of issuer %v: %v", issuer.ID, err)}
+	}
+
+	return signer, nil
+}
+
+func (e *crlSignerEntry) GetCertificate() (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(e.Certificate))
+	if block == nil {
+		return nil, errutil.InternalError{Err: "unable to parse CRL signer certificate: invalid PEM"}
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+const (
+	pathIssuerCRLSignerHelpSyn  = `Manage the key signing an issuer's CRLs and OCSP responses.`
+	pathIssuerCRLSignerHelpDesc = `
+This endpoint associates a separate key of this mount with an issuer, used
+in place of the issuer's own key to sign its CRLs and, when its certificate
+allows OCSP signing, its OCSP responses. The issuer's key then only gets
+used for issuance, which helps when it lives in an HSM with strict usage
+limits.
+
+The key is certified by a certificate with the issuer's subject, signed by
+the issuer: either provided through the "certificate" parameter, or issued
+by Vault when writing to this endpoint. Deleting the association returns
+to signing with the issuer's own key.
+
+Issuers sharing a key and subject share their CRL, which is signed with the
+CRL signer of whichever of them it is built for; set the same signer on all
+of them.
+`
+)
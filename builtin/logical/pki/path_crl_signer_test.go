@@ -0,0 +1,107 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestPki_IssuerCRLSigner(t *testing.T) {
+	t.Parallel()
+	b, s, testEnv := setupOcspEnv(t, "ec")
+	signerPath := "issuer/" + testEnv.issuerId1.String() + "/crl-signer"
+
+	resp, err := CBRead(b, s, signerPath)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	resp, err = CBWrite(b, s, "keys/generate/internal", map[string]interface{}{
+		"key_name": "crl-signer",
+		"key_type": "rsa",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "keys/generate/internal")
+	signerKeyId := resp.Data["key_id"].(keyID)
+
+	_, err = CBWrite(b, s, signerPath, map[string]interface{}{
+		"key_ref": testEnv.keyId1.String(),
+	})
+	require.ErrorContains(t, err, "is the issuer's own key")
+
+	// A provided certificate has to name the issuer as its subject.
+	_, err = CBWrite(b, s, signerPath, map[string]interface{}{
+		"key_ref":     "crl-signer",
+		"certificate": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: testEnv.leafCertIssuer1.Raw})),
+	})
+	require.ErrorContains(t, err, "doesn't match the issuer's subject")
+
+	resp, err = CBWrite(b, s, signerPath, map[string]interface{}{
+		"key_ref": "crl-signer",
+	})
+	requireSuccessNonNilResponse(t, resp, err, signerPath)
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route(signerPath), logical.UpdateOperation), resp, true)
+	require.Equal(t, signerKeyId, resp.Data["key_id"])
+	signerPem := resp.Data["certificate"].(string)
+	signerCert := parseCert(t, signerPem)
+	require.Equal(t, testEnv.issuer1.RawSubject, signerCert.RawSubject)
+	require.Equal(t, x509.RSA, signerCert.PublicKeyAlgorithm)
+	require.NoError(t, signerCert.CheckSignatureFrom(testEnv.issuer1))
+
+	resp, err = CBRead(b, s, signerPath)
+	requireSuccessNonNilResponse(t, resp, err, signerPath)
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route(signerPath), logical.ReadOperation), resp, true)
+	require.Equal(t, signerKeyId, resp.Data["key_id"])
+
+	_, err = CBDelete(b, s, "key/crl-signer")
+	require.ErrorContains(t, err, "Key in Use by Issuer")
+
+	// CRLs are signed by the signer rather than the issuer.
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(testEnv.leafCertIssuer1),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+
+	crl := getParsedCrlFromBackend(t, b, s, "issuer/"+testEnv.issuerId1.String()+"/crl/der")
+	require.NoError(t, signerCert.CheckCRLSignature(crl))
+	require.Error(t, testEnv.issuer1.CheckCRLSignature(crl))
+	require.Len(t, crl.TBSCertList.RevokedCertificates, 1)
+
+	// So are OCSP responses, which include the signer's certificate.
+	resp, err = SendOcspRequest(t, b, s, "get", testEnv.leafCertIssuer1, testEnv.issuer1, crypto.SHA1)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp get request")
+	require.Equal(t, 200, resp.Data["http_status_code"])
+	ocspResp, err := ocsp.ParseResponse(resp.Data["http_raw_body"].([]byte), testEnv.issuer1)
+	require.NoError(t, err, "parsing ocsp get response")
+	require.Equal(t, ocsp.Revoked, ocspResp.Status)
+	require.Equal(t, signerCert.Raw, ocspResp.Certificate.Raw)
+
+	// Other issuers are unaffected.
+	crl = getParsedCrlFromBackend(t, b, s, "issuer/"+testEnv.issuerId2.String()+"/crl/der")
+	require.NoError(t, testEnv.issuer2.CheckCRLSignature(crl))
+
+	// Certificates can be provided rather than issued.
+	resp, err = CBWrite(b, s, signerPath, map[string]interface{}{
+		"key_ref":     "crl-signer",
+		"certificate": signerPem,
+	})
+	requireSuccessNonNilResponse(t, resp, err, signerPath)
+	require.Equal(t, signerPem, resp.Data["certificate"])
+
+	resp, err = CBDelete(b, s, signerPath)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	resp, err = CBRead(b, s, signerPath)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	crl = getParsedCrlFromBackend(t, b, s, "issuer/"+testEnv.issuerId1.String()+"/crl/der")
+	require.NoError(t, testEnv.issuer1.CheckCRLSignature(crl))
+}
@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathListEscrowedKeys(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "escrowed-keys/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "escrowed-keys",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathEscrowedKeyList,
+			},
+		},
+
+		HelpSynopsis:    pathListEscrowedKeysHelpSyn,
+		HelpDescription: pathListEscrowedKeysHelpDesc,
+	}
+}
+
+func pathEscrowedKeys(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `escrowed-keys/(?P<serial>[0-9A-Fa-f-:]+)`,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "escrowed-key",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"serial": {
+				Type: framework.TypeString,
+				Description: `Certificate serial number, in colon- or
+hyphen-separated octal`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathEscrowedKeyRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"private_key": {
+								Type:        framework.TypeString,
+								Description: `The escrowed private key, in PEM format`,
+								Required:    true,
+							},
+							"private_key_type": {
+								Type:        framework.TypeString,
+								Description: `The type of the escrowed private key`,
+								Required:    true,
+							},
+							"role": {
+								Type:        framework.TypeString,
+								Description: `The role the certificate was issued through`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathEscrowedKeyDelete,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+				// Read more about why this flag is set in backend.go.
+				ForwardPerformanceStandby: true,
+			},
+		},
+
+		HelpSynopsis:    pathEscrowedKeysHelpSyn,
+		HelpDescription: pathEscrowedKeysHelpDesc,
+	}
+}
+
+func (b *backend) pathEscrowedKeyList(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, escrowedKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathEscrowedKeyRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	escrowed, err := sc.fetchEscrowedKey(data.Get("serial").(string))
+	if err != nil {
+		return nil, err
+	}
+	if escrowed == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"private_key":      escrowed.PrivateKey,
+			"private_key_type": escrowed.PrivateKeyType,
+			"role":             escrowed.Role,
+		},
+	}, nil
+}
+
+func (b *backend) pathEscrowedKeyDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if err := req.Storage.Delete(ctx, escrowedKeyPrefix+normalizeSerial(data.Get("serial").(string))); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+const pathListEscrowedKeysHelpSyn = `List the serial numbers of certificates with escrowed private keys`
+
+const pathListEscrowedKeysHelpDesc = `Escrowed keys will be listed by the serial number of their certificate.`
+
+const pathEscrowedKeysHelpSyn = `Read or delete the escrowed private key of a certificate.`
+
+const pathEscrowedKeysHelpDesc = `
+This path lets you recover the private keys Vault generated for certificates
+issued through roles with escrow_private_keys set, such as to decrypt email
+encrypted to S/MIME certificates. Escrowed keys are not removed by tidy, as
+they remain needed once their certificate expires, so delete them here when
+they are no longer needed.
+`
@@ -0,0 +1,496 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	estCertsOnlyContentType = "application/pkcs7-mime; smime-type=certs-only"
+	estMaximumRequestSize   = 64 * 1024
+
+	pathEstHelpSyn  = `An endpoint implementing the EST enrollment protocol`
+	pathEstHelpDesc = `This API endpoint implements the cacerts, simpleenroll and
+simplereenroll operations of the EST protocol defined in RFC 7030. Requests
+and responses use the base64 encoded PKCS#10 and PKCS#7 formats mandated by
+the RFC rather than conventional Vault JSON, so an EST client should be used
+to interact with these endpoints.
+
+The unlabeled est/ endpoints follow the default_path_policy of config/est,
+while est/<label>/ endpoints follow the policy configured for that label in
+label_to_path_policy. The simplereenroll endpoint is authenticated by the TLS
+client certificate being renewed, which must have been issued by this mount.`
+)
+
+func setupEstPaths(b *backend, estPrefix string, unauthPrefix string) {
+	b.Backend.Paths = append(b.Backend.Paths, pathEstCaCerts(b, estPrefix))
+	b.Backend.Paths = append(b.Backend.Paths, pathEstSimpleEnroll(b, estPrefix))
+	b.Backend.Paths = append(b.Backend.Paths, pathEstSimpleReenroll(b, estPrefix))
+
+	// The CA certificates are public, and re-enrollment is authenticated by
+	// the TLS client certificate being renewed rather than a Vault token.
+	b.PathsSpecial.Unauthenticated = append(b.PathsSpecial.Unauthenticated, unauthPrefix+"/cacerts")
+	b.PathsSpecial.Unauthenticated = append(b.PathsSpecial.Unauthenticated, unauthPrefix+"/simplereenroll")
+	// We specifically do NOT add simpleenroll to this as it should be auth'd
+}
+
+func pathEstCaCerts(b *backend, baseUrl string) *framework.Path {
+	return &framework.Path{
+		Pattern: baseUrl + "/cacerts",
+		Fields:  estFields(baseUrl),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathEstCaCerts,
+			},
+		},
+
+		HelpSynopsis:    pathEstHelpSyn,
+		HelpDescription: pathEstHelpDesc,
+	}
+}
+
+func pathEstSimpleEnroll(b *backend, baseUrl string) *framework.Path {
+	return &framework.Path{
+		Pattern: baseUrl + "/simpleenroll",
+		Fields:  estFields(baseUrl),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathEstSimpleEnroll,
+			},
+		},
+
+		HelpSynopsis:    pathEstHelpSyn,
+		HelpDescription: pathEstHelpDesc,
+	}
+}
+
+func pathEstSimpleReenroll(b *backend, baseUrl string) *framework.Path {
+	return &framework.Path{
+		Pattern: baseUrl + "/simplereenroll",
+		Fields:  estFields(baseUrl),
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathEstSimpleReenroll,
+			},
+		},
+
+		HelpSynopsis:    pathEstHelpSyn,
+		HelpDescription: pathEstHelpDesc,
+	}
+}
+
+func estFields(baseUrl string) map[string]*framework.FieldSchema {
+	fields := map[string]*framework.FieldSchema{}
+	if strings.Contains(baseUrl, framework.GenericNameRegex("label")) {
+		fields["label"] = &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: `The EST label, mapped to a path policy by label_to_path_policy in config/est`,
+			Required:    true,
+		}
+	}
+	return fields
+}
+
+func (b *backend) pathEstCaCerts(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	_, issuer, err := getEstRoleAndIssuer(sc, data)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	var chain []byte
+	for _, certPem := range issuer.CAChain {
+		block, _ := pem.Decode([]byte(certPem))
+		if block == nil {
+			return nil, fmt.Errorf("failed decoding CA chain of issuer %v", issuer.ID)
+		}
+		chain = append(chain, block.Bytes...)
+	}
+
+	return estCertsOnlyResponse(chain)
+}
+
+func (b *backend) pathEstSimpleEnroll(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	role, issuer, err := getEstRoleAndIssuer(sc, data)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	csr, err := parseEstCsr(req)
+	if err != nil {
+		return logical.ErrorResponse("failed to parse PKCS#10 request: %v", err), nil
+	}
+
+	parsedBundle, err := b.signEnrollmentCsr(sc, req, role, issuer, csr, false)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	return estCertsOnlyResponse(parsedBundle.CertificateBytes)
+}
+
+func (b *backend) pathEstSimpleReenroll(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	role, issuer, err := getEstRoleAndIssuer(sc, data)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	current, err := getEstReenrollCertificate(sc, req, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := parseEstCsr(req)
+	if err != nil {
+		return logical.ErrorResponse("failed to parse PKCS#10 request: %v", err), nil
+	}
+
+	// RFC 7030 Section 4.2.2: the re-enrollment request must carry the same
+	// Subject and SubjectAltName as the certificate being renewed.
+	if err := validateReenrollCsr(current, csr); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	parsedBundle, err := b.signEnrollmentCsr(sc, req, role, issuer, csr, false)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	return estCertsOnlyResponse(parsedBundle.CertificateBytes)
+}
+
+func getEstRoleAndIssuer(sc *storageContext, data *framework.FieldData) (*roleEntry, *issuerEntry, error) {
+	config, err := sc.getEstConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !config.Enabled {
+		return nil, nil, errutil.UserError{Err: "EST is disabled on this mount"}
+	}
+
+	policy := config.DefaultPathPolicy
+	if labelRaw, ok := data.GetOk("label"); ok {
+		label := labelRaw.(string)
+		policy, ok = config.LabelToPathPolicy[label]
+		if !ok {
+			return nil, nil, errutil.UserError{Err: fmt.Sprintf("unknown EST label %q", label)}
+		}
+	}
+
+	return getEnrollmentRoleAndIssuer(sc, "EST", policy)
+}
+
+// getEnrollmentRoleAndIssuer resolves the role and issuer used by the
+// enrollment protocols (EST, SCEP) from a path policy as validated by
+// validateEnrollmentPathPolicy.
+func getEnrollmentRoleAndIssuer(sc *storageContext, protocol string, policy string) (*roleEntry, *issuerEntry, error) {
+	policyType, extraInfo, err := getDefaultDirectoryPolicyType(policy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var role *roleEntry
+	switch policyType {
+	case Forbid:
+		return nil, nil, errutil.UserError{Err: fmt.Sprintf("unlabeled %s requests are forbidden by default_path_policy", protocol)}
+	case SignVerbatim:
+		role = buildSignVerbatimRoleWithNoData(nil)
+	case Role:
+		role, err = sc.Backend.getRoleWithProfile(sc.Context, sc.Storage, extraInfo)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed loading role %v: %w", extraInfo, err)
+		}
+		if role == nil {
+			return nil, nil, errutil.UserError{Err: fmt.Sprintf("%s role %v does not exist", protocol, extraInfo)}
+		}
+	default:
+		return nil, nil, fmt.Errorf("policy %v is not supported by %s", policy, protocol)
+	}
+
+	issuerRef := role.Issuer
+	if len(issuerRef) == 0 {
+		issuerRef = defaultRef
+	}
+
+	issuerId, err := sc.resolveIssuerReference(issuerRef)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	issuer, err := sc.fetchIssuerById(issuerId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return role, issuer, nil
+}
+
+func parseEstCsr(req *logical.Request) (*x509.CertificateRequest, error) {
+	// NOTE: Writing an empty update request to Vault causes a nil request.HTTPRequest, and that object
+	//       says that it is possible for its Body element to be nil as well, so check both just in case.
+	if req.HTTPRequest == nil || req.HTTPRequest.Body == nil {
+		return nil, errors.New("no data in request body; the Content-Type must be application/pkcs10")
+	}
+	defer req.HTTPRequest.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(req.HTTPRequest.Body, estMaximumRequestSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) >= estMaximumRequestSize {
+		return nil, errors.New("request is too large")
+	}
+
+	// RFC 7030 mandates a base64 Content-Transfer-Encoding, which is
+	// commonly wrapped over multiple lines.
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(body)), ""))
+	if err != nil {
+		return nil, fmt.Errorf("request body is not base64 encoded: %w", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid signature on request: %w", err)
+	}
+
+	return csr, nil
+}
+
+func getEstReenrollCertificate(sc *storageContext, req *logical.Request, issuer *issuerEntry) (*x509.Certificate, error) {
+	if req.Connection == nil || req.Connection.ConnState == nil || len(req.Connection.ConnState.PeerCertificates) == 0 {
+		return nil, logical.ErrPermissionDenied
+	}
+	current := req.Connection.ConnState.PeerCertificates[0]
+
+	if err := verifyEnrollmentCertificate(sc, current, issuer); err != nil {
+		return nil, err
+	}
+
+	return current, nil
+}
+
+// verifyEnrollmentCertificate ensures a certificate presented to authenticate
+// a re-enrollment was issued by the given issuer, is currently valid and has
+// not been revoked.
+func verifyEnrollmentCertificate(sc *storageContext, current *x509.Certificate, issuer *issuerEntry) error {
+	issuerCert, err := issuer.GetCertificate()
+	if err != nil {
+		return err
+	}
+
+	if err := current.CheckSignatureFrom(issuerCert); err != nil {
+		return logical.ErrPermissionDenied
+	}
+
+	now := time.Now()
+	if now.Before(current.NotBefore) || now.After(current.NotAfter) {
+		return logical.ErrPermissionDenied
+	}
+
+	revEntry, err := fetchCertBySerial(sc, revokedPath, serialFromCert(current))
+	if err != nil {
+		return err
+	}
+	if revEntry != nil {
+		return logical.ErrPermissionDenied
+	}
+
+	return nil
+}
+
+func validateReenrollCsr(current *x509.Certificate, csr *x509.CertificateRequest) error {
+	if !bytes.Equal(current.RawSubject, csr.RawSubject) {
+		return errors.New("re-enrollment request subject does not match the current certificate")
+	}
+
+	sameStrings := func(a, b []string) bool {
+		a = append([]string(nil), a...)
+		b = append([]string(nil), b...)
+		sort.Strings(a)
+		sort.Strings(b)
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	currentIPs := make([]string, 0, len(current.IPAddresses))
+	for _, ip := range current.IPAddresses {
+		currentIPs = append(currentIPs, ip.String())
+	}
+	csrIPs := make([]string, 0, len(csr.IPAddresses))
+	for _, ip := range csr.IPAddresses {
+		csrIPs = append(csrIPs, ip.String())
+	}
+
+	currentURIs := make([]string, 0, len(current.URIs))
+	for _, uri := range current.URIs {
+		currentURIs = append(currentURIs, uri.String())
+	}
+	csrURIs := make([]string, 0, len(csr.URIs))
+	for _, uri := range csr.URIs {
+		csrURIs = append(csrURIs, uri.String())
+	}
+
+	if !sameStrings(current.DNSNames, csr.DNSNames) ||
+		!sameStrings(current.EmailAddresses, csr.EmailAddresses) ||
+		!sameStrings(currentIPs, csrIPs) ||
+		!sameStrings(currentURIs, csrURIs) {
+		return errors.New("re-enrollment request subject alternative names do not match the current certificate")
+	}
+
+	return nil
+}
+
+// signEnrollmentCsr signs a CSR received over one of the enrollment protocols
+// (EST, SCEP, CMP) under the given role and issuer, storing the result unless
+// the role disables it. externalProofOfPossession must only be set when the
+// caller has verified possession of the CSR's key itself.
+func (b *backend) signEnrollmentCsr(sc *storageContext, req *logical.Request, role *roleEntry, issuer *issuerEntry, csr *x509.CertificateRequest, externalProofOfPossession bool) (*certutil.ParsedCertBundle, error) {
+	// If storing the certificate and on a performance standby, forward this request on to the primary
+	// Allow performance secondaries to generate and store certificates locally to them.
+	if !role.NoStore && b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) {
+		return nil, logical.ErrReadOnly
+	}
+
+	if csr.PublicKeyAlgorithm == x509.UnknownPublicKeyAlgorithm || csr.PublicKey == nil {
+		return nil, errutil.UserError{Err: "refusing to sign CSR with empty PublicKey"}
+	}
+
+	pemCsr := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE REQUEST",
+		Bytes: csr.Raw,
+	}))
+
+	data := &framework.FieldData{
+		Raw: map[string]interface{}{
+			"csr": pemCsr,
+		},
+		Schema: getCsrSignVerbatimSchemaFields(),
+	}
+
+	// Enrollment clients cannot supply Vault request parameters, so take the common
+	// name from the CSR when the role would otherwise require one.
+	if role.RequireCN && csr.Subject.CommonName != "" {
+		data.Raw["common_name"] = csr.Subject.CommonName
+	}
+
+	signingBundle, err := sc.fetchCAInfoByIssuerId(issuer.ID, IssuanceUsage)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading CA %s: %w", issuer.ID.String(), err)
+	}
+
+	input := &inputBundle{
+		req:                       req,
+		apiData:                   data,
+		role:                      role,
+		externalProofOfPossession: externalProofOfPossession,
+	}
+	if err := loadPolicyWebhook(sc, input); err != nil {
+		return nil, err
+	}
+
+	release, err := b.acquireIssuanceLimits(sc, role)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	parsedBundle, _, err := signCert(sc.Context, b, input, signingBundle, false /* is_ca=false */, false /* use_csr_values */)
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return nil, err
+		default:
+			return nil, fmt.Errorf("error signing certificate: %w", err)
+		}
+	}
+
+	if err := parsedBundle.Verify(); err != nil {
+		return nil, fmt.Errorf("verification of parsed bundle failed: %w", err)
+	}
+
+	if !role.NoStore {
+		if err := storeCertificate(sc, parsedBundle); err != nil {
+			return nil, err
+		}
+
+		metadata := &certMetadataEntry{
+			Role:     role.Name,
+			IssuerId: issuer.ID,
+		}
+		if err := sc.writeCertMetadata(serialFromCert(parsedBundle.Certificate), metadata); err != nil {
+			return nil, fmt.Errorf("unable to store certificate metadata: %w", err)
+		}
+
+		if len(parsedBundle.CAChain) > 0 {
+			pregenerateOcspResponse(sc, parsedBundle.Certificate, parsedBundle.CAChain[0].Certificate)
+		}
+	}
+
+	b.sendCertEvent(sc.Context, eventTypeCertSign, parsedBundle.Certificate, role.Name, issuer.ID, req.EntityID)
+	b.countIssuedCertificate(role.Name)
+
+	return parsedBundle, nil
+}
+
+func estCertsOnlyResponse(certs []byte) (*logical.Response, error) {
+	p7, err := pkcs7.DegenerateCertificate(certs)
+	if err != nil {
+		return nil, fmt.Errorf("failed building PKCS#7 response: %w", err)
+	}
+
+	// The body is passed as bytes as string bodies would be base64 decoded
+	// by the HTTP layer, while EST requires the base64 form on the wire.
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: estCertsOnlyContentType,
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     []byte(base64.StdEncoding.EncodeToString(p7)),
+		},
+		Headers: map[string][]string{
+			"Content-Transfer-Encoding": {"base64"},
+		},
+	}, nil
+}
+
+func enrollmentErrorResponse(err error) (*logical.Response, error) {
+	var userErr errutil.UserError
+	if errors.As(err, &userErr) {
+		return logical.ErrorResponse(userErr.Error()), nil
+	}
+	return nil, err
+}
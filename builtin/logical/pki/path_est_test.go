@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstConfig(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBRead(b, s, "config/est")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, false, resp.Data["enabled"])
+	require.Equal(t, "sign-verbatim", resp.Data["default_path_policy"])
+
+	_, err = CBWrite(b, s, "roles/device", map[string]interface{}{
+		"allow_any_name": true,
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "config/est", map[string]interface{}{
+		"enabled":              true,
+		"default_path_policy":  "forbid",
+		"label_to_path_policy": map[string]string{"devices": "role:device"},
+	})
+	require.NoError(t, err)
+
+	resp, err = CBRead(b, s, "config/est")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, true, resp.Data["enabled"])
+	require.Equal(t, "forbid", resp.Data["default_path_policy"])
+	require.Equal(t, map[string]string{"devices": "role:device"}, resp.Data["label_to_path_policy"])
+
+	_, err = CBWrite(b, s, "config/est", map[string]interface{}{
+		"label_to_path_policy": map[string]string{"devices": "role:missing"},
+	})
+	require.Error(t, err, "expected missing role to be rejected")
+
+	_, err = CBWrite(b, s, "config/est", map[string]interface{}{
+		"label_to_path_policy": map[string]string{"devices": "forbid"},
+	})
+	require.Error(t, err, "expected forbid to be rejected as a label policy")
+
+	_, err = CBWrite(b, s, "config/est", map[string]interface{}{
+		"default_path_policy": "external-policy",
+	})
+	require.Error(t, err, "expected external-policy to be rejected")
+}
+
+func TestEstEnrollment(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	// Disabled by default.
+	resp, err = CBRead(b, s, "est/cacerts")
+	require.Error(t, err)
+
+	_, err = CBWrite(b, s, "roles/device", map[string]interface{}{
+		"allowed_domains":  "devices.example.com",
+		"allow_subdomains": true,
+		"key_type":         "any",
+		"ttl":              "1h",
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "config/est", map[string]interface{}{
+		"enabled":              true,
+		"default_path_policy":  "forbid",
+		"label_to_path_policy": map[string]string{"devices": "role:device"},
+	})
+	require.NoError(t, err)
+
+	// The default path is forbidden, and unknown labels are rejected.
+	_, err = CBRead(b, s, "est/cacerts")
+	require.Error(t, err)
+	_, err = CBRead(b, s, "est/unknown/cacerts")
+	require.Error(t, err)
+
+	resp, err = CBRead(b, s, "est/devices/cacerts")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, estCertsOnlyContentType, resp.Data[logical.HTTPContentType])
+	certs := parseEstCertsOnly(t, resp)
+	require.Len(t, certs, 1)
+	require.True(t, rootCert.Equal(certs[0]))
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	csr := estCsr(t, key, "one.devices.example.com")
+	resp, err = estRequest(b, s, "est/devices/simpleenroll", csr, nil)
+	requireSuccessNonNilResponse(t, resp, err)
+	certs = parseEstCertsOnly(t, resp)
+	require.Len(t, certs, 1)
+	leaf := certs[0]
+	require.Equal(t, "one.devices.example.com", leaf.Subject.CommonName)
+	requireSignedBy(t, leaf, rootCert)
+
+	// The issued certificate must be stored.
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(leaf))
+	requireSuccessNonNilResponse(t, resp, err)
+
+	// Names outside of the role are rejected.
+	resp, err = estRequest(b, s, "est/devices/simpleenroll", estCsr(t, key, "example.org"), nil)
+	require.Error(t, err)
+
+	// Re-enrollment requires the current certificate to be presented.
+	_, err = estRequest(b, s, "est/devices/simplereenroll", csr, nil)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+
+	// Re-enrollment must keep the same subject.
+	_, err = estRequest(b, s, "est/devices/simplereenroll", estCsr(t, key, "two.devices.example.com"), leaf)
+	require.Error(t, err)
+
+	resp, err = estRequest(b, s, "est/devices/simplereenroll", csr, leaf)
+	requireSuccessNonNilResponse(t, resp, err)
+	certs = parseEstCertsOnly(t, resp)
+	require.Len(t, certs, 1)
+	require.Equal(t, leaf.Subject.CommonName, certs[0].Subject.CommonName)
+	require.NotEqual(t, leaf.SerialNumber, certs[0].SerialNumber)
+
+	// Revoked certificates can no longer re-enroll.
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(leaf),
+	})
+	require.NoError(t, err)
+
+	_, err = estRequest(b, s, "est/devices/simplereenroll", csr, leaf)
+	require.ErrorIs(t, err, logical.ErrPermissionDenied)
+}
+
+func estCsr(t *testing.T, key *ecdsa.PrivateKey, commonName string) []byte {
+	t.Helper()
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: commonName},
+		DNSNames: []string{commonName},
+	}, key)
+	require.NoError(t, err)
+	return csr
+}
+
+func estRequest(b *backend, s logical.Storage, path string, csr []byte, clientCert *x509.Certificate) (*logical.Response, error) {
+	httpReq := httptest.NewRequest(http.MethodPost, "/v1/pki/"+path, strings.NewReader(base64.StdEncoding.EncodeToString(csr)))
+	httpReq.Header.Set("Content-Type", "application/pkcs10")
+
+	req := &logical.Request{
+		Operation:   logical.UpdateOperation,
+		Path:        path,
+		Storage:     s,
+		MountPoint:  "pki/",
+		HTTPRequest: httpReq,
+		Connection:  &logical.Connection{},
+	}
+	if clientCert != nil {
+		req.Connection.ConnState = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{clientCert},
+		}
+	}
+
+	resp, err := b.HandleRequest(context.Background(), req)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if resp.IsError() {
+		return resp, resp.Error()
+	}
+	return resp, nil
+}
+
+func parseEstCertsOnly(t *testing.T, resp *logical.Response) []*x509.Certificate {
+	t.Helper()
+
+	der, err := base64.StdEncoding.DecodeString(string(resp.Data[logical.HTTPRawBody].([]byte)))
+	require.NoError(t, err)
+
+	p7, err := pkcs7.Parse(der)
+	require.NoError(t, err)
+	return p7.Certificates
+}
@@ -5,9 +5,11 @@ package pki
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,6 +39,11 @@ var pathFetchReadSchema = map[int][]framework.Response{
 				Description: `Revocation time RFC 3339 formatted`,
 				Required:    false,
 			},
+			"revocation_reason": {
+				Type:        framework.TypeString,
+				Description: `RFC 5280 reason of the revocation`,
+				Required:    false,
+			},
 			"issuer_id": {
 				Type:        framework.TypeString,
 				Description: `ID of the issuer`,
@@ -47,6 +54,11 @@ var pathFetchReadSchema = map[int][]framework.Response{
 				Description: `Issuing CA Chain`,
 				Required:    false,
 			},
+			"cert_metadata": {
+				Type:        framework.TypeKVPairs,
+				Description: `Metadata provided when the certificate was issued`,
+				Required:    false,
+			},
 		},
 	}},
 }
@@ -236,6 +248,63 @@ func pathFetchListCerts(b *backend) *framework.Path {
 			OperationSuffix: "certs",
 		},
 
+		Fields: map[string]*framework.FieldSchema{
+			"include_metadata": {
+				Type:    framework.TypeBool,
+				Default: false,
+				Description: `Whether to return the role, issuer and metadata
+certificates were issued with, as key_info.`,
+			},
+			"common_name": {
+				Type: framework.TypeString,
+				Description: `Only list certificates with a matching Common Name;
+may contain glob patterns.`,
+			},
+			"san": {
+				Type: framework.TypeString,
+				Description: `Only list certificates with a matching DNS, email,
+IP or URI Subject Alternative Name; may contain glob patterns.`,
+			},
+			"serial_prefix": {
+				Type:        framework.TypeString,
+				Description: `Only list certificates whose serial number starts with this prefix.`,
+			},
+			"role": {
+				Type: framework.TypeString,
+				Description: `Only list certificates issued through this role.
+Certificates issued before roles were recorded never match.`,
+			},
+			issuerRefParam: {
+				Type:        framework.TypeString,
+				Description: `Only list certificates issued by this issuer.`,
+			},
+			"expires_before": {
+				Type:        framework.TypeString,
+				Description: `Only list certificates expiring before this RFC 3339 timestamp.`,
+			},
+			"expires_after": {
+				Type:        framework.TypeString,
+				Description: `Only list certificates expiring after this RFC 3339 timestamp.`,
+			},
+			"revoked_only": {
+				Type:        framework.TypeBool,
+				Default:     false,
+				Description: `Only list revoked certificates.`,
+			},
+			"after": {
+				Type: framework.TypeString,
+				Description: `Only list certificates with serial numbers after this
+one; used with limit to page through the certificates, passing the last serial
+number of the previous page.`,
+			},
+			"limit": {
+				Type:    framework.TypeInt,
+				Default: 0,
+				Description: `The maximum number of certificates to list; 0 for
+no limit.`,
+			},
+		},
+
 		Operations: map[logical.Operation]framework.OperationHandler{
 			logical.ListOperation: &framework.PathOperation{
 				Callback: b.pathFetchCertList,
@@ -247,15 +316,110 @@ func pathFetchListCerts(b *backend) *framework.Path {
 	}
 }
 
-func (b *backend) pathFetchCertList(ctx context.Context, req *logical.Request, _ *framework.FieldData) (response *logical.Response, retErr error) {
+func (b *backend) pathFetchCertList(ctx context.Context, req *logical.Request, data *framework.FieldData) (response *logical.Response, retErr error) {
+	limit := data.Get("limit").(int)
+	if limit < 0 {
+		return logical.ErrorResponse("limit must not be negative"), nil
+	}
+
 	entries, err := req.Storage.List(ctx, "certs/")
 	if err != nil {
 		return nil, err
 	}
-	for i := range entries {
-		entries[i] = denormalizeSerial(entries[i])
+
+	// Storage doesn't guarantee any order, which paging relies on.
+	sort.Strings(entries)
+	if after := normalizeSerial(data.Get("after").(string)); after != "" {
+		index := sort.SearchStrings(entries, after)
+		if index < len(entries) && entries[index] == after {
+			index += 1
+		}
+		entries = entries[index:]
 	}
-	return logical.ListResponse(entries), nil
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	filter, err := newCertListFilter(sc, data)
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(err.Error()), nil
+		default:
+			return nil, err
+		}
+	}
+
+	includeMetadata := data.Get("include_metadata").(bool)
+	if filter.isEmpty() && !includeMetadata {
+		if limit > 0 && len(entries) > limit {
+			entries = entries[:limit]
+		}
+		for i := range entries {
+			entries[i] = denormalizeSerial(entries[i])
+		}
+		return logical.ListResponse(entries), nil
+	}
+
+	keys := []string{}
+	keyInfo := map[string]interface{}{}
+	for _, serial := range entries {
+		if limit > 0 && len(keys) >= limit {
+			break
+		}
+
+		if !filter.matchesSerial(serial) {
+			continue
+		}
+
+		var cert *x509.Certificate
+		if filter.needsCert() {
+			certEntry, err := req.Storage.Get(ctx, "certs/"+serial)
+			if err != nil {
+				return nil, fmt.Errorf("error fetching certificate %q: %w", serial, err)
+			}
+			if certEntry == nil {
+				continue
+			}
+
+			cert, err = x509.ParseCertificate(certEntry.Value)
+			if err != nil {
+				// Tidy will report on (and not remove) these; don't let
+				// them break searching.
+				b.Logger().Warn("skipping unparseable certificate in listing", "serial", serial, "error", err)
+				continue
+			}
+		}
+
+		var metadata *certMetadataEntry
+		if includeMetadata || filter.needsMetadata() {
+			metadata, err = sc.fetchCertMetadata(serial)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if !filter.matches(cert, metadata) {
+			continue
+		}
+
+		key := denormalizeSerial(serial)
+		keys = append(keys, key)
+		if includeMetadata && metadata != nil {
+			info := map[string]interface{}{
+				"role":      metadata.Role,
+				"issuer_id": metadata.IssuerId,
+			}
+			if len(metadata.CertMetadata) > 0 {
+				info["cert_metadata"] = metadata.CertMetadata
+			}
+			keyInfo[key] = info
+		}
+	}
+
+	if !includeMetadata {
+		return logical.ListResponse(keys), nil
+	}
+
+	return logical.ListResponseWithInfo(keys, keyInfo), nil
 }
 
 func (b *backend) pathFetchRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (response *logical.Response, retErr error) {
@@ -267,6 +431,8 @@ func (b *backend) pathFetchRead(ctx context.Context, req *logical.Request, data
 	var revocationTime int64
 	var revocationIssuerId string
 	var revocationTimeRfc3339 string
+	var revocationReason string
+	var certMetadata *certMetadataEntry
 
 	response = &logical.Response{
 		Data: map[string]interface{}{},
@@ -459,12 +625,22 @@ func (b *backend) pathFetchRead(ctx context.Context, req *logical.Request, data
 		}
 		revocationTime = revInfo.RevocationTime
 		revocationIssuerId = revInfo.CertificateIssuer.String()
+		revocationReason = revocationReasonName(revInfo.RevocationReason)
 
 		if !revInfo.RevocationTimeUTC.IsZero() {
 			revocationTimeRfc3339 = revInfo.RevocationTimeUTC.Format(time.RFC3339Nano)
 		}
 	}
 
+	// Metadata is only returned in JSON responses, not raw certificates.
+	if len(contentType) == 0 && pemType == "CERTIFICATE" {
+		certMetadata, funcErr = sc.fetchCertMetadata(serial)
+		if funcErr != nil {
+			retErr = funcErr
+			goto reply
+		}
+	}
+
 reply:
 	switch {
 	case len(contentType) != 0:
@@ -501,10 +677,17 @@ reply:
 		if revocationIssuerId != "" {
 			response.Data["issuer_id"] = revocationIssuerId
 		}
+		if revocationReason != "" {
+			response.Data["revocation_reason"] = revocationReason
+		}
 
 		if len(fullChain) > 0 {
 			response.Data["ca_chain"] = string(fullChain)
 		}
+
+		if certMetadata != nil && len(certMetadata.CertMetadata) > 0 {
+			response.Data["cert_metadata"] = certMetadata.CertMetadata
+		}
 	}
 
 	return
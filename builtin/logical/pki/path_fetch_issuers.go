@@ -163,13 +163,36 @@ the entire validity period. It is suggested to use "truncate" for
 intermediate CAs and "permit" only for root CAs.`,
 		Default: "err",
 	}
+	fields[policyIdentifiersParam] = &framework.FieldSchema{
+		Type: framework.TypeCommaStringSlice,
+		Description: `A comma-separated string or list of policy OIDs, or a JSON list of qualified policy
+information, which must include an oid, and may include a notice and/or cps url. These
+are added to certificates issued by this issuer, unless their role or request specifies
+its own.`,
+	}
+	fields["serial_number_bits"] = &framework.FieldSchema{
+		Type: framework.TypeInt,
+		Description: `Number of random bits in the serial numbers of
+certificates signed by this issuer, between 64 and 159. Defaults to 0,
+for 159 random bits.`,
+	}
+	fields["serial_number_prefix"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `Hex encoded prefix of the serial numbers of
+certificates signed by this issuer, placed above serial_number_bits random
+bits; both must fit into 159 bits. Use a multiple of 8 bits for the prefix
+to appear as-is in hex encoded serial numbers.`,
+	}
 	fields["usage"] = &framework.FieldSchema{
 		Type: framework.TypeCommaStringSlice,
 		Description: `Comma-separated list (or string slice) of usages for
 this issuer; valid values are "read-only", "issuing-certificates",
-"crl-signing", and "ocsp-signing". Multiple values may be specified. Read-only
-is implicit and always set.`,
-		Default: []string{"read-only", "issuing-certificates", "crl-signing", "ocsp-signing"},
+"crl-signing", "ocsp-signing", and "intermediate-signing". Multiple values may
+be specified. Read-only is implicit and always set; issuing-certificates
+implies intermediate-signing. Alternatively, the single value
+"ocsp-signing-only" or "sign-intermediate-only" restricts the issuer to that
+duty.`,
+		Default: []string{"read-only", "issuing-certificates", "crl-signing", "ocsp-signing", "intermediate-signing"},
 	}
 	fields["revocation_signature_algorithm"] = &framework.FieldSchema{
 		Type: framework.TypeString,
@@ -196,6 +219,12 @@ for the CRL distribution points attribute. See also RFC 5280 Section 4.2.1.13.`,
 		Type: framework.TypeCommaStringSlice,
 		Description: `Comma-separated list of URLs to be used
 for the OCSP servers attribute. See also RFC 5280 Section 4.2.2.1.`,
+	}
+	fields["delta_crl_distribution_points"] = &framework.FieldSchema{
+		Type: framework.TypeCommaStringSlice,
+		Description: `Comma-separated list of URLs to be used
+for the Freshest CRL extension on complete CRLs, pointing at this issuer's
+delta CRL. See also RFC 5280 Section 5.2.6.`,
 	}
 	fields["enable_aia_url_templating"] = &framework.FieldSchema{
 		Type: framework.TypeBool,
@@ -248,6 +277,21 @@ to be set on all PR secondary clusters.`,
 					Description: `Leaf Not After Behavior`,
 					Required:    false,
 				},
+				"policy_identifiers": {
+					Type:        framework.TypeStringSlice,
+					Description: `Policy Identifiers`,
+					Required:    false,
+				},
+				"serial_number_bits": {
+					Type:        framework.TypeInt,
+					Description: `Serial Number Bits`,
+					Required:    false,
+				},
+				"serial_number_prefix": {
+					Type:        framework.TypeString,
+					Description: `Serial Number Prefix`,
+					Required:    false,
+				},
 				"usage": {
 					Type:        framework.TypeString,
 					Description: `Usage`,
@@ -286,6 +330,11 @@ to be set on all PR secondary clusters.`,
 					Description: `OCSP Servers`,
 					Required:    false,
 				},
+				"delta_crl_distribution_points": {
+					Type:        framework.TypeStringSlice,
+					Description: `Delta CRL Distribution Points`,
+					Required:    false,
+				},
 				"enable_aia_url_templating": {
 					Type:        framework.TypeBool,
 					Description: `Whether or not templating is enabled for AIA fields`,
@@ -446,12 +495,16 @@ func respondReadIssuer(issuer *issuerEntry) (*logical.Response, error) {
 		"manual_chain":                   respManualChain,
 		"ca_chain":                       issuer.CAChain,
 		"leaf_not_after_behavior":        issuer.LeafNotAfterBehavior.String(),
+		"policy_identifiers":             issuer.PolicyIdentifiers,
+		"serial_number_bits":             issuer.SerialNumberBits,
+		"serial_number_prefix":           issuer.SerialNumberPrefix,
 		"usage":                          issuer.Usage.Names(),
 		"revocation_signature_algorithm": revSigAlgStr,
 		"revoked":                        issuer.Revoked,
 		"issuing_certificates":           []string{},
 		"crl_distribution_points":        []string{},
 		"ocsp_servers":                   []string{},
+		"delta_crl_distribution_points":  []string{},
 	}
 
 	if issuer.Revoked {
@@ -463,6 +516,9 @@ func respondReadIssuer(issuer *issuerEntry) (*logical.Response, error) {
 		data["issuing_certificates"] = issuer.AIAURIs.IssuingCertificates
 		data["crl_distribution_points"] = issuer.AIAURIs.CRLDistributionPoints
 		data["ocsp_servers"] = issuer.AIAURIs.OCSPServers
+		if len(issuer.AIAURIs.DeltaCRLDistributionPoints) > 0 {
+			data["delta_crl_distribution_points"] = issuer.AIAURIs.DeltaCRLDistributionPoints
+		}
 		data["enable_aia_url_templating"] = issuer.AIAURIs.EnableTemplating
 	}
 
@@ -536,10 +592,21 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 		return logical.ErrorResponse("Unknown value for field `leaf_not_after_behavior`. Possible values are `err`, `truncate`, and `permit`."), nil
 	}
 
+	newPolicyIdentifiers := getPolicyIdentifier(data, nil)
+	if err := validatePolicyIdentifiers(newPolicyIdentifiers); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("Unable to parse specified policy identifiers: %v", err)), nil
+	}
+
+	newSerialNumberBits := data.Get("serial_number_bits").(int)
+	newSerialNumberPrefix, err := normalizeSerialNumberFormat(newSerialNumberBits, data.Get("serial_number_prefix").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	rawUsage := data.Get("usage").([]string)
 	newUsage, err := NewIssuerUsageFromNames(rawUsage)
 	if err != nil {
-		return logical.ErrorResponse(fmt.Sprintf("Unable to parse specified usages: %v - valid values are %v", rawUsage, AllIssuerUsages.Names())), nil
+		return logical.ErrorResponse(fmt.Sprintf("Unable to parse specified usages: %v (%v) - valid values are %v, or one of ocsp-signing-only and sign-intermediate-only alone", rawUsage, err, AllIssuerUsages.Names())), nil
 	}
 
 	// Revocation signature algorithm changes
@@ -573,6 +640,10 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 	if badURL := validateURLs(ocspServers); !enableTemplating && badURL != "" {
 		return logical.ErrorResponse(fmt.Sprintf("invalid URL found in Authority Information Access (AIA) parameter ocsp_servers: %s", badURL)), nil
 	}
+	deltaCrlDistributionPoints := data.Get("delta_crl_distribution_points").([]string)
+	if badURL := validateURLs(deltaCrlDistributionPoints); !enableTemplating && badURL != "" {
+		return logical.ErrorResponse(fmt.Sprintf("invalid URL found in Authority Information Access (AIA) parameter delta_crl_distribution_points: %s", badURL)), nil
+	}
 
 	modified := false
 
@@ -592,8 +663,19 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 		modified = true
 	}
 
+	if isStringArrayDifferent(newPolicyIdentifiers, issuer.PolicyIdentifiers) {
+		issuer.PolicyIdentifiers = newPolicyIdentifiers
+		modified = true
+	}
+
+	if newSerialNumberBits != issuer.SerialNumberBits || newSerialNumberPrefix != issuer.SerialNumberPrefix {
+		issuer.SerialNumberBits = newSerialNumberBits
+		issuer.SerialNumberPrefix = newSerialNumberPrefix
+		modified = true
+	}
+
 	if newUsage != issuer.Usage {
-		if issuer.Revoked && newUsage.HasUsage(IssuanceUsage) {
+		if issuer.Revoked && (newUsage.HasUsage(IssuanceUsage) || newUsage.HasUsage(IntermediateSigningUsage)) {
 			// Forbid allowing cert signing on its usage.
 			return logical.ErrorResponse("This issuer was revoked; unable to modify its usage to include certificate signing again. Reissue this certificate (preferably with a new key) and modify that entry instead."), nil
 		}
@@ -617,7 +699,7 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 		modified = true
 	}
 
-	if issuer.AIAURIs == nil && (len(issuerCertificates) > 0 || len(crlDistributionPoints) > 0 || len(ocspServers) > 0) {
+	if issuer.AIAURIs == nil && (len(issuerCertificates) > 0 || len(crlDistributionPoints) > 0 || len(ocspServers) > 0 || len(deltaCrlDistributionPoints) > 0) {
 		issuer.AIAURIs = &aiaConfigEntry{}
 	}
 	if issuer.AIAURIs != nil {
@@ -640,6 +722,10 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 				Source: &ocspServers,
 				Dest:   &issuer.AIAURIs.OCSPServers,
 			},
+			{
+				Source: &deltaCrlDistributionPoints,
+				Dest:   &issuer.AIAURIs.DeltaCRLDistributionPoints,
+			},
 		}
 
 		// For each pair, if it is different on the object, update it.
@@ -656,7 +742,7 @@ func (b *backend) pathUpdateIssuer(ctx context.Context, req *logical.Request, da
 
 		// If no AIA URLs exist on the issuer, set the AIA URLs entry to nil
 		// to ease usage later.
-		if len(issuer.AIAURIs.IssuingCertificates) == 0 && len(issuer.AIAURIs.CRLDistributionPoints) == 0 && len(issuer.AIAURIs.OCSPServers) == 0 {
+		if issuer.AIAURIs.isEmpty() {
 			issuer.AIAURIs = nil
 		}
 	}
@@ -809,16 +895,52 @@ func (b *backend) pathPatchIssuer(ctx context.Context, req *logical.Request, dat
 		}
 	}
 
+	// Policy Identifier Changes
+	if _, ok := data.GetOk(policyIdentifiersParam); ok {
+		newPolicyIdentifiers := getPolicyIdentifier(data, nil)
+		if err := validatePolicyIdentifiers(newPolicyIdentifiers); err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("Unable to parse specified policy identifiers: %v", err)), nil
+		}
+		if isStringArrayDifferent(newPolicyIdentifiers, issuer.PolicyIdentifiers) {
+			issuer.PolicyIdentifiers = newPolicyIdentifiers
+			modified = true
+		}
+	}
+
+	// Serial Number Format Changes
+	rawSerialNumberBits, bitsOk := data.GetOk("serial_number_bits")
+	rawSerialNumberPrefix, prefixOk := data.GetOk("serial_number_prefix")
+	if bitsOk || prefixOk {
+		newSerialNumberBits := issuer.SerialNumberBits
+		if bitsOk {
+			newSerialNumberBits = rawSerialNumberBits.(int)
+		}
+		newSerialNumberPrefix := issuer.SerialNumberPrefix
+		if prefixOk {
+			newSerialNumberPrefix = rawSerialNumberPrefix.(string)
+		}
+
+		newSerialNumberPrefix, err := normalizeSerialNumberFormat(newSerialNumberBits, newSerialNumberPrefix)
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		if newSerialNumberBits != issuer.SerialNumberBits || newSerialNumberPrefix != issuer.SerialNumberPrefix {
+			issuer.SerialNumberBits = newSerialNumberBits
+			issuer.SerialNumberPrefix = newSerialNumberPrefix
+			modified = true
+		}
+	}
+
 	// Usage Changes
 	rawUsageData, ok := data.GetOk("usage")
 	if ok {
 		rawUsage := rawUsageData.([]string)
 		newUsage, err := NewIssuerUsageFromNames(rawUsage)
 		if err != nil {
-			return logical.ErrorResponse(fmt.Sprintf("Unable to parse specified usages: %v - valid values are %v", rawUsage, AllIssuerUsages.Names())), nil
+			return logical.ErrorResponse(fmt.Sprintf("Unable to parse specified usages: %v (%v) - valid values are %v, or one of ocsp-signing-only and sign-intermediate-only alone", rawUsage, err, AllIssuerUsages.Names())), nil
 		}
 		if newUsage != issuer.Usage {
-			if issuer.Revoked && newUsage.HasUsage(IssuanceUsage) {
+			if issuer.Revoked && (newUsage.HasUsage(IssuanceUsage) || newUsage.HasUsage(IntermediateSigningUsage)) {
 				// Forbid allowing cert signing on its usage.
 				return logical.ErrorResponse("This issuer was revoked; unable to modify its usage to include certificate signing again. Reissue this certificate (preferably with a new key) and modify that entry instead."), nil
 			}
@@ -888,6 +1010,10 @@ func (b *backend) pathPatchIssuer(ctx context.Context, req *logical.Request, dat
 			Source: "ocsp_servers",
 			Dest:   &issuer.AIAURIs.OCSPServers,
 		},
+		{
+			Source: "delta_crl_distribution_points",
+			Dest:   &issuer.AIAURIs.DeltaCRLDistributionPoints,
+		},
 	}
 
 	if enableTemplatingRaw, ok := data.GetOk("enable_aia_url_templating"); ok {
@@ -916,7 +1042,7 @@ func (b *backend) pathPatchIssuer(ctx context.Context, req *logical.Request, dat
 
 	// If no AIA URLs exist on the issuer, set the AIA URLs entry to nil to
 	// ease usage later.
-	if len(issuer.AIAURIs.IssuingCertificates) == 0 && len(issuer.AIAURIs.CRLDistributionPoints) == 0 && len(issuer.AIAURIs.OCSPServers) == 0 {
+	if issuer.AIAURIs.isEmpty() {
 		issuer.AIAURIs = nil
 	}
 
@@ -1120,6 +1246,22 @@ func (b *backend) pathDeleteIssuer(ctx context.Context, req *logical.Request, da
 	return response, nil
 }
 
+// addWarningOnRevokedIssuer warns when the given reference, held by a role
+// or the issuers configuration, resolves to a revoked issuer.
+func addWarningOnRevokedIssuer(sc *storageContext, issuerRef string, resp *logical.Response) {
+	issuerId, err := sc.resolveIssuerReference(issuerRef)
+	if err != nil {
+		return
+	}
+
+	issuer, err := sc.fetchIssuerById(issuerId)
+	if err != nil || !issuer.Revoked {
+		return
+	}
+
+	resp.AddWarning(fmt.Sprintf("Issuer %v (%v) was revoked; certificates can no longer be issued through it", issuerRef, issuerId))
+}
+
 func addWarningOnDereferencing(sc *storageContext, name string, resp *logical.Response) {
 	timeout, inUseBy, err := sc.checkForRolesReferencing(name)
 	if err != nil || timeout {
@@ -0,0 +1,222 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathImportCerts(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "certs/import",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "import",
+			OperationSuffix: "certs",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"certificates": {
+				Type: framework.TypeString,
+				Description: `PEM encoded leaf certificates to store, issued by
+issuers of this mount.`,
+			},
+			"role": {
+				Type: framework.TypeString,
+				Description: `Role to record the certificates as issued through,
+for listing and revoking them by role.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathImportCertsWrite,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"imported": {
+								Type:        framework.TypeStringSlice,
+								Description: `Serial numbers of the certificates stored by this request`,
+								Required:    true,
+							},
+							"skipped": {
+								Type:        framework.TypeMap,
+								Description: `Serial numbers of the certificates which were not stored, with the reason`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+				// Read more about why these flags are set in backend.go
+				ForwardPerformanceStandby: true,
+			},
+		},
+
+		HelpSynopsis:    pathImportCertsHelpSyn,
+		HelpDescription: pathImportCertsHelpDesc,
+	}
+}
+
+func (b *backend) pathImportCertsWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot import certificates until migration has completed"), nil
+	}
+
+	var certs []*x509.Certificate
+	pemBytes := []byte(data.Get("certificates").(string))
+	for len(bytes.TrimSpace(pemBytes)) > 0 {
+		var pemBlock *pem.Block
+		pemBlock, pemBytes = pem.Decode(pemBytes)
+		if pemBlock == nil {
+			return logical.ErrorResponse("provided PEM block contained no data"), nil
+		}
+		if pemBlock.Type != "CERTIFICATE" {
+			return logical.ErrorResponse(fmt.Sprintf("unexpected PEM block of type %q; only certificates can be imported", pemBlock.Type)), nil
+		}
+
+		cert, err := x509.ParseCertificate(pemBlock.Bytes)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("failed to parse certificate %d: %v", len(certs), err)), nil
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return logical.ErrorResponse("no certificates were provided"), nil
+	}
+
+	roleName := data.Get("role").(string)
+	if roleName != "" {
+		role, err := b.getRole(ctx, req.Storage, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role == nil {
+			return logical.ErrorResponse(fmt.Sprintf("unknown role: %s", roleName)), nil
+		}
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	issuerIDCertMap, err := fetchIssuerMapForRevocationChecking(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the revocation lock, as stored certificates may already have a
+	// revocation imported from a CRL.
+	b.revokeStorageLock.Lock()
+	defer b.revokeStorageLock.Unlock()
+
+	imported := []string{}
+	skipped := map[string]interface{}{}
+	for _, cert := range certs {
+		serial := serialFromCert(cert)
+
+		skipReason, err := importCertificate(sc, cert, roleName, issuerIDCertMap)
+		if err != nil {
+			return nil, fmt.Errorf("error importing certificate %v: %w", serial, err)
+		}
+		if skipReason != "" {
+			skipped[serial] = skipReason
+			continue
+		}
+
+		imported = append(imported, serial)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"imported": imported,
+			"skipped":  skipped,
+		},
+	}, nil
+}
+
+// importCertificate stores an externally held leaf certificate, issued by
+// one of the mount's issuers, as if it had been issued by this mount. It
+// returns why the certificate wasn't stored, if it wasn't.
+func importCertificate(sc *storageContext, cert *x509.Certificate, roleName string, issuerIDCertMap map[issuerID]*x509.Certificate) (string, error) {
+	if cert.IsCA {
+		return "certificate is a CA; import it as an issuer instead", nil
+	}
+	if cert.NotAfter.Before(time.Now()) {
+		return "certificate already expired", nil
+	}
+
+	var issuerId issuerID
+	for id, issuerCert := range issuerIDCertMap {
+		if bytes.Equal(cert.RawIssuer, issuerCert.RawSubject) && cert.CheckSignatureFrom(issuerCert) == nil {
+			issuerId = id
+			break
+		}
+	}
+	if issuerId == "" {
+		return "certificate was not issued by an issuer of this mount", nil
+	}
+
+	serial := serialFromCert(cert)
+	certEntry, err := fetchCertBySerial(sc, "certs/", serial)
+	if err != nil {
+		return "", err
+	}
+	if certEntry != nil {
+		return "certificate already stored", nil
+	}
+
+	key := "certs/" + normalizeSerial(serial)
+	certsCounted := sc.Backend.certsCounted.Load()
+	if err := sc.Storage.Put(sc.Context, &logical.StorageEntry{Key: key, Value: cert.Raw}); err != nil {
+		return "", fmt.Errorf("unable to store certificate locally: %w", err)
+	}
+	sc.Backend.ifCountEnabledIncrementTotalCertificatesCount(certsCounted, key)
+
+	metadata := &certMetadataEntry{
+		Role:     roleName,
+		IssuerId: issuerId,
+	}
+	if err := sc.writeCertMetadata(serial, metadata); err != nil {
+		return "", fmt.Errorf("unable to store certificate metadata: %w", err)
+	}
+
+	// Complete a revocation imported from a CRL with the certificate, so
+	// that it expires along with it.
+	revInfo, err := sc.fetchRevocationInfo(serial)
+	if err != nil {
+		return "", err
+	}
+	if revInfo != nil && revInfo.isImported() {
+		revInfo.CertificateBytes = cert.Raw
+		revInfo.SerialNumber = ""
+		revInfo.CertExpiration = time.Time{}
+
+		revEntry, err := logical.StorageEntryJSON(revokedPath+normalizeSerial(serial), revInfo)
+		if err != nil {
+			return "", fmt.Errorf("error creating revocation entry: %w", err)
+		}
+		if err := sc.Storage.Put(sc.Context, revEntry); err != nil {
+			return "", fmt.Errorf("error updating revoked certificate: %w", err)
+		}
+	}
+
+	return "", nil
+}
+
+const pathImportCertsHelpSyn = `Store leaf certificates issued outside of this mount's certificate store.`
+
+const pathImportCertsHelpDesc = `
+This endpoint stores leaf certificates issued by issuers of this mount which
+aren't in its certificate store, such as ones issued through no_store roles
+or by the CA an issuer was imported from. Once stored, they can be revoked,
+listed and tidied like any other certificate, without being re-issued.
+`
@@ -0,0 +1,150 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestPki_ImportCerts(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootPem := resp.Data["certificate"].(string)
+
+	resp, err = CBWrite(b, s, "roles/nostore", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+		"no_store":       true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/nostore")
+
+	resp, err = CBWrite(b, s, "issue/nostore", map[string]interface{}{
+		"common_name": "www.example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/nostore")
+	leafPem := resp.Data["certificate"].(string)
+	leafSerial := resp.Data["serial_number"].(string)
+
+	resp, err = CBRead(b, s, "cert/"+leafSerial)
+	require.NoError(t, err)
+	require.Nil(t, resp)
+
+	_, err = CBWrite(b, s, "certs/import", map[string]interface{}{
+		"certificates": leafPem,
+		"role":         "missing",
+	})
+	require.ErrorContains(t, err, "unknown role")
+	_, err = CBWrite(b, s, "certs/import", map[string]interface{}{
+		"certificates": "-----BEGIN CERTIFICATE REQUEST-----\nMIIB\n-----END CERTIFICATE REQUEST-----\n",
+	})
+	require.ErrorContains(t, err, "only certificates can be imported")
+
+	externalKey, externalCert := createExternalCA(t, "external root")
+	externalLeaf := createExternalLeaf(t, externalKey, externalCert, big.NewInt(0x0abc))
+
+	resp, err = CBWrite(b, s, "certs/import", map[string]interface{}{
+		"certificates": leafPem + "\n" + rootPem + "\n" + externalLeaf,
+		"role":         "nostore",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "certs/import")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("certs/import"), logical.UpdateOperation), resp, true)
+	require.Equal(t, []string{leafSerial}, resp.Data["imported"])
+	skipped := resp.Data["skipped"].(map[string]interface{})
+	require.Len(t, skipped, 2)
+	require.Contains(t, skipped[serialFromCert(parseCert(t, rootPem))], "is a CA")
+	require.Equal(t, "certificate was not issued by an issuer of this mount", skipped["0a:bc"])
+
+	resp, err = CBWrite(b, s, "certs/import", map[string]interface{}{
+		"certificates": leafPem,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "certs/import")
+	require.Equal(t, "certificate already stored", resp.Data["skipped"].(map[string]interface{})[leafSerial])
+
+	// Imported certificates are listed with their role, and can be revoked.
+	resp, err = CBReq(b, s, logical.ListOperation, "certs", map[string]interface{}{
+		"role": "nostore",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "certs")
+	require.Equal(t, []string{leafSerial}, resp.Data["keys"])
+
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": leafSerial,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+
+	// Revocations imported from a CRL, of certificates not stored yet, are
+	// completed with the certificate once it is imported.
+	keyDer, err := x509.MarshalPKCS8PrivateKey(externalKey)
+	require.NoError(t, err)
+	resp, err = CBWrite(b, s, "issuers/import/bundle", map[string]interface{}{
+		"pem_bundle": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: externalCert.Raw})) +
+			string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuers/import/bundle")
+	externalIssuerId := resp.Data["imported_issuers"].([]string)[0]
+
+	revokedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	resp, err = CBWrite(b, s, "issuer/"+externalIssuerId+"/import-crl", map[string]interface{}{
+		"crl": createExternalCRL(t, externalKey, externalCert, []pkix.RevokedCertificate{
+			revokedCertificate(t, big.NewInt(0x0abc), revokedAt, ocsp.Superseded),
+		}),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "import-crl")
+	require.Equal(t, []string{"0a:bc"}, resp.Data["imported"])
+
+	resp, err = CBWrite(b, s, "certs/import", map[string]interface{}{
+		"certificates": externalLeaf,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "certs/import")
+	require.Equal(t, []string{"0a:bc"}, resp.Data["imported"])
+
+	resp, err = CBRead(b, s, "cert/0a:bc")
+	requireSuccessNonNilResponse(t, resp, err, "cert")
+	require.Equal(t, revokedAt.Unix(), resp.Data["revocation_time"])
+	require.Equal(t, "superseded", resp.Data["revocation_reason"])
+
+	revInfo, err := b.makeStorageContext(context.Background(), s).fetchRevocationInfo("0a:bc")
+	require.NoError(t, err)
+	require.False(t, revInfo.isImported())
+	require.Equal(t, parseCert(t, externalLeaf).Raw, revInfo.CertificateBytes)
+}
+
+func createExternalLeaf(t *testing.T, caKey *ecdsa.PrivateKey, caCert *x509.Certificate, serial *big.Int) string {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: "leaf.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, key.Public(), caKey)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
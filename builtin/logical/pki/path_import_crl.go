@@ -0,0 +1,280 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathImportIssuerCRL(b *backend) *framework.Path {
+	fields := addIssuerRefField(map[string]*framework.FieldSchema{})
+	fields["crl"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `PEM encoded CRL, signed by the issuer, whose revoked
+certificates to mark as revoked.`,
+	}
+
+	return &framework.Path{
+		Pattern: "issuer/" + framework.GenericNameRegex(issuerRefParam) + "/import-crl",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKIIssuer,
+			OperationVerb:   "import",
+			OperationSuffix: "crl",
+		},
+
+		Fields: fields,
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathImportIssuerCRLWrite,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"imported": {
+								Type:        framework.TypeStringSlice,
+								Description: `Serial numbers of the certificates marked revoked by this request`,
+								Required:    true,
+							},
+							"skipped": {
+								Type:        framework.TypeMap,
+								Description: `Serial numbers of the revoked certificates which were not imported, with the reason`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+				// Read more about why these flags are set in backend.go
+				ForwardPerformanceStandby: true,
+			},
+		},
+
+		HelpSynopsis:    pathImportIssuerCRLHelpSyn,
+		HelpDescription: pathImportIssuerCRLHelpDesc,
+	}
+}
+
+func (b *backend) pathImportIssuerCRLWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("cannot import CRL until migration has completed"), nil
+	}
+
+	issuerRef := getIssuerRef(data)
+	if len(issuerRef) == 0 {
+		return logical.ErrorResponse("missing issuer reference"), nil
+	}
+
+	block, rest := pem.Decode([]byte(data.Get("crl").(string)))
+	if block == nil || len(rest) != 0 {
+		return logical.ErrorResponse("invalid crl; should be one PEM block only"), nil
+	}
+	crl, err := x509.ParseRevocationList(block.Bytes)
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("failed to parse crl: %v", err)), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	issuerId, err := sc.resolveIssuerReference(issuerRef)
+	if err != nil {
+		if issuerId == IssuerRefNotFound {
+			return logical.ErrorResponse("unable to resolve issuer id for reference: " + issuerRef), nil
+		}
+		return nil, err
+	}
+
+	issuer, err := sc.fetchIssuerById(issuerId)
+	if err != nil {
+		return nil, err
+	}
+	issuerCert, err := issuer.GetCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing issuer certificate value: %w", err)
+	}
+
+	if err := crl.CheckSignatureFrom(issuerCert); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("crl was not signed by issuer %v: %v", issuerId, err)), nil
+	}
+
+	config, err := b.crlBuilder.getConfigWithUpdate(sc)
+	if err != nil {
+		return nil, fmt.Errorf("error importing crl: failed reading config: %w", err)
+	}
+
+	issuerIDCertMap, err := fetchIssuerMapForRevocationChecking(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	b.revokeStorageLock.Lock()
+	defer b.revokeStorageLock.Unlock()
+
+	resp := &logical.Response{}
+	imported := []string{}
+	skipped := map[string]interface{}{}
+	for _, entry := range crl.RevokedCertificates {
+		serial := serialFromBigInt(entry.SerialNumber)
+
+		reason, err := revocationReasonFromExtensions(entry.Extensions)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("revoked certificate %v: %v", serial, err)), nil
+		}
+		if revocationReasonName(reason) == "" {
+			skipped[serial] = fmt.Sprintf("unsupported revocation reason %d", reason)
+			continue
+		}
+
+		skipReason, err := importRevocation(sc, config, issuerId, issuerCert, issuerIDCertMap, serial, entry.RevocationTime, reason, resp)
+		if err != nil {
+			return nil, fmt.Errorf("error importing revocation of certificate %v: %w", serial, err)
+		}
+		if skipReason != "" {
+			skipped[serial] = skipReason
+			continue
+		}
+
+		imported = append(imported, serial)
+	}
+
+	resp.Data = map[string]interface{}{
+		"imported": imported,
+		"skipped":  skipped,
+	}
+	if len(imported) == 0 {
+		return resp, nil
+	}
+
+	// Rebuild the CRLs, regardless of auto-rebuild, to publish the imported
+	// revocations right away.
+	warnings, crlErr := b.crlBuilder.rebuild(sc, false)
+	if crlErr != nil {
+		switch crlErr.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(fmt.Sprintf("Error during CRL building: %s", crlErr)), nil
+		default:
+			return nil, fmt.Errorf("error encountered during CRL building: %w", crlErr)
+		}
+	}
+	for index, warning := range warnings {
+		resp.AddWarning(fmt.Sprintf("Warning %d during CRL rebuild: %v", index+1, warning))
+	}
+
+	return resp, nil
+}
+
+// importRevocation marks the certificate with the given serial number,
+// listed on an external CRL of the issuer, as revoked. When the certificate
+// isn't stored by this mount, only its serial number is recorded. It returns
+// why the revocation wasn't imported, if it wasn't.
+func importRevocation(sc *storageContext, config *crlConfig, issuerId issuerID, issuerCert *x509.Certificate, issuerIDCertMap map[issuerID]*x509.Certificate, serial string, revocationTime time.Time, reason int, resp *logical.Response) (string, error) {
+	for id, certificate := range issuerIDCertMap {
+		if serial == serialFromCert(certificate) {
+			return fmt.Sprintf("certificate is issuer %v of this mount; revoke it through its revoke endpoint instead", id), nil
+		}
+	}
+
+	curRevInfo, err := sc.fetchRevocationInfo(serial)
+	if err != nil {
+		return "", err
+	}
+	if curRevInfo != nil {
+		return "certificate already revoked", nil
+	}
+
+	revInfo := revocationInfo{
+		RevocationTime:    revocationTime.Unix(),
+		RevocationTimeUTC: revocationTime.UTC(),
+		CertificateIssuer: issuerId,
+		RevocationReason:  reason,
+	}
+
+	var cert *x509.Certificate
+	var expiration time.Time
+	certEntry, err := fetchCertBySerial(sc, "certs/", serial)
+	if err != nil {
+		return "", err
+	}
+	if certEntry != nil {
+		cert, err = x509.ParseCertificate(certEntry.Value)
+		if err != nil {
+			return "", fmt.Errorf("error parsing stored certificate: %w", err)
+		}
+		if err := cert.CheckSignatureFrom(issuerCert); err != nil {
+			return "stored certificate with this serial number was not issued by this issuer", nil
+		}
+
+		revInfo.CertificateBytes = cert.Raw
+		expiration = cert.NotAfter
+	} else {
+		revInfo.SerialNumber = serial
+		revInfo.CertExpiration = issuerCert.NotAfter
+		expiration = issuerCert.NotAfter
+	}
+
+	if expiration.Before(time.Now()) {
+		return "certificate already expired", nil
+	}
+
+	revEntry, err := logical.StorageEntryJSON(revokedPath+normalizeSerial(serial), revInfo)
+	if err != nil {
+		return "", fmt.Errorf("error creating revocation entry: %w", err)
+	}
+
+	certsCounted := sc.Backend.certsCounted.Load()
+	if err := sc.Storage.Put(sc.Context, revEntry); err != nil {
+		return "", fmt.Errorf("error saving revoked certificate: %w", err)
+	}
+	sc.Backend.ifCountEnabledIncrementTotalRevokedCertificatesCount(certsCounted, revEntry.Key)
+	sc.Backend.ocspCache.purge()
+
+	var revokedRole string
+	if cert != nil {
+		if metadata, err := sc.fetchCertMetadata(serial); err == nil && metadata != nil {
+			revokedRole = metadata.Role
+		}
+		sc.Backend.sendCertEvent(sc.Context, eventTypeCertRevoke, cert, "", issuerId, "")
+	}
+	sc.Backend.countRevokedCertificate(revokedRole)
+
+	if config.UnifiedCRL {
+		entry := &unifiedRevocationEntry{
+			SerialNumber:      serial,
+			CertExpiration:    expiration,
+			RevocationTimeUTC: revInfo.RevocationTimeUTC,
+			CertificateIssuer: issuerId,
+			RevocationReason:  reason,
+		}
+
+		if ignoreErr := writeUnifiedRevocationEntry(sc, entry); ignoreErr != nil {
+			// As with regular revocations, a separate background thread
+			// will reattempt this later on.
+			sc.Backend.Logger().Error("Failed to write unified revocation entry, will re-attempt later",
+				"serial_number", serial, "error", ignoreErr)
+			sc.Backend.unifiedTransferStatus.forceRun()
+
+			resp.AddWarning(fmt.Sprintf("Failed to write unified revocation entry for %v, will re-attempt later: %v", serial, ignoreErr))
+		}
+	}
+
+	return "", nil
+}
+
+const pathImportIssuerCRLHelpSyn = `Import the revocations of a CRL signed by this issuer.`
+
+const pathImportIssuerCRLHelpDesc = `
+This endpoint imports a CRL produced by this issuer outside of Vault, such as
+by the CA it was imported from. The certificates it lists are marked as
+revoked, with their original revocation time and reason, and included in the
+CRLs Vault publishes from then on. Certificates which Vault doesn't store are
+recorded by their serial number only, until the issuer expires.
+`
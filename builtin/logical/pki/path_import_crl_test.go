@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestPki_ImportIssuerCRL(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	// An external CA, whose issuer gets imported along with its key.
+	externalKey, externalCert := createExternalCA(t, "external root")
+	keyDer, err := x509.MarshalPKCS8PrivateKey(externalKey)
+	require.NoError(t, err)
+	bundle := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: externalCert.Raw})) +
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+	resp, err := CBWrite(b, s, "issuers/import/bundle", map[string]interface{}{
+		"pem_bundle": bundle,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuers/import/bundle")
+	issuerId := string(resp.Data["imported_issuers"].([]string)[0])
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "www.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	storedSerial := resp.Data["serial_number"].(string)
+	storedCert := parseCert(t, resp.Data["certificate"].(string))
+
+	revokedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	crlPem := createExternalCRL(t, externalKey, externalCert, []pkix.RevokedCertificate{
+		revokedCertificate(t, storedCert.SerialNumber, revokedAt, ocsp.KeyCompromise),
+		revokedCertificate(t, big.NewInt(0x0abc), revokedAt, ocsp.Unspecified),
+		revokedCertificate(t, big.NewInt(0x0def), revokedAt, ocsp.CertificateHold),
+		revokedCertificate(t, externalCert.SerialNumber, revokedAt, ocsp.Unspecified),
+	})
+
+	// CRLs must be signed by the issuer they're imported for.
+	otherKey, otherCert := createExternalCA(t, "other root")
+	_, err = CBWrite(b, s, "issuer/default/import-crl", map[string]interface{}{
+		"crl": createExternalCRL(t, otherKey, otherCert, nil),
+	})
+	require.ErrorContains(t, err, "crl was not signed by issuer")
+
+	resp, err = CBWrite(b, s, "issuer/default/import-crl", map[string]interface{}{
+		"crl": crlPem,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/default/import-crl")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issuer/default/import-crl"), logical.UpdateOperation), resp, true)
+	require.ElementsMatch(t, []string{storedSerial, "0a:bc"}, resp.Data["imported"])
+	skipped := resp.Data["skipped"].(map[string]interface{})
+	require.Len(t, skipped, 2)
+	require.Contains(t, skipped["0d:ef"], "unsupported revocation reason")
+	require.Contains(t, skipped[serialFromCert(externalCert)], "issuer "+issuerId)
+
+	resp, err = CBRead(b, s, "cert/"+storedSerial)
+	requireSuccessNonNilResponse(t, resp, err, "cert")
+	require.Equal(t, revokedAt.Unix(), resp.Data["revocation_time"])
+	require.Equal(t, "keyCompromise", resp.Data["revocation_reason"])
+
+	resp, err = CBList(b, s, "certs/revoked")
+	requireSuccessNonNilResponse(t, resp, err, "certs/revoked")
+	require.Contains(t, resp.Data["keys"], "0a:bc")
+
+	// Both revocations are published on the issuer's CRL, as it was.
+	crl := getParsedCrlFromBackend(t, b, s, "issuer/default/crl/der")
+	require.Len(t, crl.TBSCertList.RevokedCertificates, 2)
+	for _, entry := range crl.TBSCertList.RevokedCertificates {
+		require.True(t, entry.RevocationTime.Equal(revokedAt))
+		reason, err := revocationReasonFromExtensions(entry.Extensions)
+		require.NoError(t, err)
+		if entry.SerialNumber.Cmp(storedCert.SerialNumber) == 0 {
+			require.Equal(t, ocsp.KeyCompromise, reason)
+		} else {
+			require.Equal(t, int64(0x0abc), entry.SerialNumber.Int64())
+			require.Equal(t, ocsp.Unspecified, reason)
+		}
+	}
+
+	// Importing the same CRL again doesn't change anything.
+	resp, err = CBWrite(b, s, "issuer/default/import-crl", map[string]interface{}{
+		"crl": crlPem,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/default/import-crl")
+	require.Empty(t, resp.Data["imported"])
+	require.Equal(t, "certificate already revoked", resp.Data["skipped"].(map[string]interface{})["0a:bc"])
+
+	// Tidy copes with revocations lacking the certificate, keeping them
+	// until their issuer expires.
+	resp, err = CBWrite(b, s, "tidy", map[string]interface{}{
+		"tidy_revoked_certs":                    true,
+		"tidy_revoked_cert_issuer_associations": true,
+		"safety_buffer":                         "1s",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "tidy")
+	require.Eventually(t, func() bool {
+		resp, err := CBRead(b, s, "tidy-status")
+		require.NoError(t, err)
+		require.NotEqual(t, "Error", resp.Data["state"], "tidy failed: %v", resp.Data["error"])
+		return resp.Data["state"] == "Finished"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	resp, err = CBList(b, s, "certs/revoked")
+	requireSuccessNonNilResponse(t, resp, err, "certs/revoked")
+	require.Contains(t, resp.Data["keys"], "0a:bc")
+}
+
+func createExternalCA(t *testing.T, commonName string) (*ecdsa.PrivateKey, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(0x1234),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-2 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return key, cert
+}
+
+func createExternalCRL(t *testing.T, key *ecdsa.PrivateKey, cert *x509.Certificate, revoked []pkix.RevokedCertificate) string {
+	t.Helper()
+
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificates: revoked,
+		Number:              big.NewInt(7),
+		ThisUpdate:          time.Now(),
+		NextUpdate:          time.Now().Add(time.Hour),
+	}, cert, key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}))
+}
+
+func revokedCertificate(t *testing.T, serial *big.Int, revokedAt time.Time, reason int) pkix.RevokedCertificate {
+	t.Helper()
+
+	extensions, err := revocationReasonExtensions(reason)
+	require.NoError(t, err)
+
+	return pkix.RevokedCertificate{SerialNumber: serial, RevocationTime: revokedAt, Extensions: extensions}
+}
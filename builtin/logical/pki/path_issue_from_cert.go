@@ -0,0 +1,208 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Parameters of issue-from-cert passed through to issuance unchanged; the
+// subject and SANs instead come from the existing certificate.
+var issueFromCertPassthroughFields = []string{
+	"role",
+	"ttl",
+	"not_after",
+	"not_before",
+	"format",
+	"private_key_format",
+	"pkcs12_password",
+	"remove_roots_from_chain",
+	"csr",
+}
+
+func pathIssueFromCert(b *backend) *framework.Path {
+	pattern := "issue-from-cert/" + framework.GenericNameRegex("role")
+
+	displayAttrs := &framework.DisplayAttributes{
+		OperationPrefix: operationPrefixPKI,
+		OperationVerb:   "issue",
+		OperationSuffix: "from-certificate",
+	}
+
+	ret := buildPathIssue(b, pattern, displayAttrs)
+	ret.Operations[logical.UpdateOperation].(*framework.PathOperation).Callback = b.metricsWrap("issue-from-cert", roleRequired, b.pathIssueFromCert)
+
+	issueFields := ret.Fields
+	ret.Fields = map[string]*framework.FieldSchema{
+		"serial": {
+			Type: framework.TypeString,
+			Description: `Serial number of the certificate to renew, in
+hyphen-separated or colon-separated hexadecimal. It must have been issued and
+stored by this mount, and not have been revoked.`,
+			Required: true,
+		},
+		"csr": {
+			Type: framework.TypeString,
+			Description: `PEM-format CSR whose key the new certificate is issued
+for, such as the one the existing certificate was signed from. When not
+provided, a new private key is generated.`,
+		},
+	}
+	for _, name := range issueFromCertPassthroughFields {
+		if field, ok := issueFields[name]; ok {
+			ret.Fields[name] = field
+		}
+	}
+
+	ret.HelpSynopsis = pathIssueFromCertHelpSyn
+	ret.HelpDescription = pathIssueFromCertHelpDesc
+
+	return ret
+}
+
+// pathIssueFromCert renews a stored certificate: a new certificate is issued
+// with its subject and SANs, subject to the current restrictions of the role.
+func (b *backend) pathIssueFromCert(ctx context.Context, req *logical.Request, data *framework.FieldData, role *roleEntry) (*logical.Response, error) {
+	serial := data.Get("serial").(string)
+	if serial == "" {
+		return logical.ErrorResponse("the serial of the certificate to renew is required"), nil
+	}
+
+	useCSR := data.Get("csr").(string) != ""
+	if !useCSR && role.KeyType == "any" {
+		return logical.ErrorResponse("role key type \"any\" not allowed for issuing certificates, only signing; provide a csr instead"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	certEntry, err := fetchCertBySerial(sc, "certs/", serial)
+	if err != nil {
+		return nil, err
+	}
+	if certEntry == nil {
+		return logical.ErrorResponse(fmt.Sprintf("certificate with serial %s not found", serial)), nil
+	}
+
+	current, err := x509.ParseCertificate(certEntry.Value)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse stored certificate: %w", err)
+	}
+
+	revInfo, err := sc.fetchRevocationInfo(serial)
+	if err != nil {
+		return nil, err
+	}
+	if revInfo != nil {
+		return logical.ErrorResponse(fmt.Sprintf("certificate with serial %s has been revoked and cannot be renewed", serial)), nil
+	}
+
+	raw, err := issueFromCertRequestData(current)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range issueFromCertPassthroughFields {
+		if value, ok := data.Raw[name]; ok {
+			raw[name] = value
+		}
+	}
+
+	// Metadata attached to the existing certificate carries over.
+	metadata, err := sc.fetchCertMetadata(serial)
+	if err != nil {
+		return nil, err
+	}
+	if metadata != nil && len(metadata.CertMetadata) > 0 && !role.NoStore {
+		raw["cert_metadata"] = metadata.CertMetadata
+	}
+
+	schema := addNonCACommonFields(map[string]*framework.FieldSchema{})
+	for name, field := range data.Schema {
+		schema[name] = field
+	}
+
+	// The CSR only provides the key; its subject and SANs are replaced by
+	// those of the existing certificate.
+	renewRole := *role
+	renewRole.UseCSRCommonName = false
+	renewRole.UseCSRSANs = false
+
+	return b.pathIssueSignCert(ctx, req, &framework.FieldData{Raw: raw, Schema: schema}, &renewRole, useCSR, false)
+}
+
+// issueFromCertRequestData returns the issuance parameters requesting the
+// subject and SANs of the given certificate.
+func issueFromCertRequestData(cert *x509.Certificate) (map[string]interface{}, error) {
+	altNames := append(append([]string{}, cert.DNSNames...), cert.EmailAddresses...)
+
+	excludeCN := true
+	for _, name := range altNames {
+		if name == cert.Subject.CommonName {
+			excludeCN = false
+			break
+		}
+	}
+
+	ipSans := make([]string, 0, len(cert.IPAddresses))
+	for _, ip := range cert.IPAddresses {
+		ipSans = append(ipSans, ip.String())
+	}
+
+	uriSans := make([]string, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uriSans = append(uriSans, uri.String())
+	}
+
+	others, err := getOtherSANsFromX509Extensions(cert.Extensions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse other SANs of stored certificate: %w", err)
+	}
+	otherSans := make([]string, 0, len(others))
+	for _, other := range others {
+		otherSans = append(otherSans, other.String())
+	}
+
+	userIDs := []string{}
+	for _, attr := range cert.Subject.Names {
+		if attr.Type.Equal(certutil.SubjectPilotUserIDAttributeOID) {
+			if value, ok := attr.Value.(string); ok {
+				userIDs = append(userIDs, value)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"common_name":          cert.Subject.CommonName,
+		"alt_names":            strings.Join(altNames, ","),
+		"ip_sans":              ipSans,
+		"uri_sans":             uriSans,
+		"other_sans":           otherSans,
+		"serial_number":        cert.Subject.SerialNumber,
+		"user_ids":             userIDs,
+		"exclude_cn_from_sans": excludeCN,
+	}, nil
+}
+
+const pathIssueFromCertHelpSyn = `
+Renew a certificate, issuing a new one with the same subject and SANs.
+`
+
+const pathIssueFromCertHelpDesc = `
+This path issues a new certificate with the common name, subject serial
+number, user IDs and subject alternative names of an existing certificate
+issued and stored by this mount, identified by its serial. The request is
+validated against the current configuration of the role, just as if these
+values had been requested through the issue path, so renewal fails once the
+role no longer allows them.
+
+A new private key is generated unless a CSR is provided, in which case the
+new certificate is issued for the CSR's key; the subject and SANs requested
+by the CSR are ignored. Revoked certificates cannot be
+renewed; expired ones can.
+`
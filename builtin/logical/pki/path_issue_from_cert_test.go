@@ -0,0 +1,131 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_IssueFromCert(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"allow_ip_sans":    true,
+		"allowed_uri_sans": "spiffe://example.com/*",
+		"key_type":         "ec",
+		"ttl":              "2h",
+	})
+	require.NoError(t, err)
+
+	resp, err = CBWrite(b, s, "issue/example", map[string]interface{}{
+		"common_name":   "www.example.com",
+		"alt_names":     "api.example.com",
+		"ip_sans":       "10.0.0.1",
+		"uri_sans":      "spiffe://example.com/web",
+		"cert_metadata": map[string]interface{}{"owner": "web"},
+		"ttl":           "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/example")
+	serial := resp.Data["serial_number"].(string)
+	original := parseCert(t, resp.Data["certificate"].(string))
+
+	requireSameNames := func(renewed *x509.Certificate) {
+		t.Helper()
+
+		require.Equal(t, original.Subject.String(), renewed.Subject.String())
+		require.ElementsMatch(t, original.DNSNames, renewed.DNSNames)
+		require.Equal(t, original.IPAddresses, renewed.IPAddresses)
+		require.Equal(t, original.URIs, renewed.URIs)
+		require.NotEqual(t, original.SerialNumber, renewed.SerialNumber)
+	}
+
+	// Renewal generates a new key by default.
+	resp, err = CBWrite(b, s, "issue-from-cert/example", map[string]interface{}{
+		"serial": serial,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue-from-cert/example")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issue-from-cert/example"), logical.UpdateOperation), resp, true)
+	require.NotEmpty(t, resp.Data["private_key"])
+
+	renewed := parseCert(t, resp.Data["certificate"].(string))
+	requireSameNames(renewed)
+	require.NotEqual(t, original.PublicKey, renewed.PublicKey)
+	require.Greater(t, renewed.NotAfter.Sub(renewed.NotBefore), original.NotAfter.Sub(original.NotBefore))
+
+	resp, err = CBRead(b, s, "cert/"+resp.Data["serial_number"].(string))
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, map[string]string{"owner": "web"}, resp.Data["cert_metadata"])
+
+	// Or keeps the key of a given CSR.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "ignored.example.com"},
+	}, key)
+	require.NoError(t, err)
+
+	resp, err = CBWrite(b, s, "issue-from-cert/example", map[string]interface{}{
+		"serial": serial,
+		"csr":    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+		"ttl":    "30m",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue-from-cert/example")
+	require.Empty(t, resp.Data["private_key"])
+
+	renewed = parseCert(t, resp.Data["certificate"].(string))
+	requireSameNames(renewed)
+	require.Equal(t, &key.PublicKey, renewed.PublicKey)
+
+	// Renewal is validated against the current role.
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"allow_ip_sans":    false,
+		"allowed_uri_sans": "spiffe://example.com/*",
+		"key_type":         "ec",
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "issue-from-cert/example", map[string]interface{}{
+		"serial": serial,
+	})
+	require.ErrorContains(t, err, "IP Subject Alternative Names are not allowed")
+
+	_, err = CBWrite(b, s, "issue-from-cert/example", map[string]interface{}{
+		"serial": "11:22:33",
+	})
+	require.ErrorContains(t, err, "not found")
+
+	// Revoked certificates cannot be renewed.
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serial,
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "issue-from-cert/example", map[string]interface{}{
+		"serial": serial,
+	})
+	require.ErrorContains(t, err, "has been revoked")
+}
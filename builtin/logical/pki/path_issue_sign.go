@@ -6,7 +6,7 @@ package pki
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
 	"fmt"
@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/helper/certutil"
 	"github.com/hashicorp/vault/sdk/helper/consts"
@@ -82,6 +83,11 @@ func buildPathIssue(b *backend, pattern string, displayAttrs *framework.DisplayA
 								Description: `Time of expiration`,
 								Required:    true,
 							},
+							"pkcs7": {
+								Type:        framework.TypeString,
+								Description: `Base64-encoded, degenerate PKCS#7 bundle of the certificate and chain`,
+								Required:    false,
+							},
 							"private_key": {
 								Type:        framework.TypeString,
 								Description: `Private key`,
@@ -92,6 +98,11 @@ func buildPathIssue(b *backend, pattern string, displayAttrs *framework.DisplayA
 								Description: `Private key type`,
 								Required:    false,
 							},
+							"pkcs12": {
+								Type:        framework.TypeString,
+								Description: `Base64-encoded, password protected PKCS#12 bundle of the private key, certificate and chain`,
+								Required:    false,
+							},
 						},
 					}},
 				},
@@ -103,6 +114,37 @@ func buildPathIssue(b *backend, pattern string, displayAttrs *framework.DisplayA
 	}
 
 	ret.Fields = addNonCACommonFields(map[string]*framework.FieldSchema{})
+
+	// Only issuance, where Vault holds the private key, can return it
+	// within a PKCS#12 bundle.
+	ret.Fields["format"].Description += ` If "pkcs12", the private key,
+certificate and chain will be returned as a
+base64 encoded PKCS#12 bundle, protected by
+"pkcs12_password".`
+	ret.Fields["format"].AllowedValues = append(ret.Fields["format"].AllowedValues, "pkcs12")
+	ret.Fields["pkcs12_password"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `Password protecting the PKCS#12 bundle; required
+when "format" is "pkcs12".`,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Sensitive: true,
+		},
+	}
+
+	return ret
+}
+
+func pathIssueWithSerial(b *backend) *framework.Path {
+	pattern := "issue-with-serial/" + framework.GenericNameRegex("role")
+
+	displayAttrs := &framework.DisplayAttributes{
+		OperationPrefix: operationPrefixPKI,
+		OperationVerb:   "issue",
+		OperationSuffix: "with-role-and-serial",
+	}
+
+	ret := buildPathIssue(b, pattern, displayAttrs)
+	addCertificateSerialNumberField(ret)
 	return ret
 }
 
@@ -130,6 +172,37 @@ func pathIssuerSign(b *backend) *framework.Path {
 	return buildPathSign(b, pattern, displayAttrs)
 }
 
+func pathSignWithSerial(b *backend) *framework.Path {
+	pattern := "sign-with-serial/" + framework.GenericNameRegex("role")
+
+	displayAttrs := &framework.DisplayAttributes{
+		OperationPrefix: operationPrefixPKI,
+		OperationVerb:   "sign",
+		OperationSuffix: "with-role-and-serial",
+	}
+
+	ret := buildPathSign(b, pattern, displayAttrs)
+	addCertificateSerialNumberField(ret)
+	return ret
+}
+
+// addCertificateSerialNumberField lets callers of the *-with-serial paths
+// choose the serial number of the certificate. These are separate paths so
+// that operators can grant this via policy independently of issue/sign.
+func addCertificateSerialNumberField(ret *framework.Path) {
+	ret.Fields["certificate_serial_number"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `Hex encoded serial number of the certificate,
+optionally separated by colons or hyphens, in place of one generated
+for the issuer. Must not have been issued by this mount before.`,
+		Required: true,
+		DisplayAttrs: &framework.DisplayAttributes{
+			Name: "Certificate Serial Number",
+		},
+	}
+	ret.HelpDescription += pathWithSerialHelpDesc
+}
+
 func buildPathSign(b *backend, pattern string, displayAttrs *framework.DisplayAttributes) *framework.Path {
 	ret := &framework.Path{
 		Pattern:      pattern,
@@ -167,6 +240,11 @@ func buildPathSign(b *backend, pattern string, displayAttrs *framework.DisplayAt
 								Description: `Time of expiration`,
 								Required:    true,
 							},
+							"pkcs7": {
+								Type:        framework.TypeString,
+								Description: `Base64-encoded, degenerate PKCS#7 bundle of the certificate and chain`,
+								Required:    false,
+							},
 						},
 					}},
 				},
@@ -185,6 +263,8 @@ func buildPathSign(b *backend, pattern string, displayAttrs *framework.DisplayAt
 		Description: `PEM-format CSR to be signed.`,
 	}
 
+	ret.Fields = addKeyAttestationField(ret.Fields)
+
 	return ret
 }
 
@@ -250,6 +330,11 @@ func buildPathIssuerSignVerbatim(b *backend, pattern string, displayAttrs *frame
 								Description: `Time of expiration`,
 								Required:    true,
 							},
+							"pkcs7": {
+								Type:        framework.TypeString,
+								Description: `Base64-encoded, degenerate PKCS#7 bundle of the certificate and chain`,
+								Required:    false,
+							},
 						},
 					}},
 				},
@@ -310,7 +395,7 @@ func (b *backend) pathSignVerbatim(ctx context.Context, req *logical.Request, da
 func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, data *framework.FieldData, role *roleEntry, useCSR, useCSRValues bool) (*logical.Response, error) {
 	// If storing the certificate and on a performance standby, forward this request on to the primary
 	// Allow performance secondaries to generate and store certificates locally to them.
-	if !role.NoStore && b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) {
+	if (!role.NoStore || role.EscrowPrivateKeys && !useCSR) && b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) {
 		return nil, logical.ErrReadOnly
 	}
 
@@ -325,7 +410,9 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 	//    allows users with access to those paths to manually choose their
 	//    issuer in desired scenarios).
 	var issuerName string
-	if strings.HasPrefix(req.Path, "sign-verbatim/") || strings.HasPrefix(req.Path, "sign/") || strings.HasPrefix(req.Path, "issue/") {
+	if strings.HasPrefix(req.Path, "sign-verbatim/") || strings.HasPrefix(req.Path, "sign/") || strings.HasPrefix(req.Path, "issue/") ||
+		strings.HasPrefix(req.Path, "sign-with-serial/") || strings.HasPrefix(req.Path, "issue-with-serial/") ||
+		strings.HasPrefix(req.Path, "issue-from-cert/") {
 		issuerName = role.Issuer
 		if len(issuerName) == 0 {
 			issuerName = defaultRef
@@ -339,15 +426,38 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		}
 	}
 
-	format := getFormat(data)
+	format := getIssueSignFormat(data, !useCSR)
 	if format == "" {
+		if !useCSR {
+			return logical.ErrorResponse(
+				`the "format" path parameter must be "pem", "der", "pem_bundle", "pkcs7", or "pkcs12"`), nil
+		}
 		return logical.ErrorResponse(
-			`the "format" path parameter must be "pem", "der", or "pem_bundle"`), nil
+			`the "format" path parameter must be "pem", "der", "pem_bundle", or "pkcs7"`), nil
+	}
+
+	if format == "pkcs12" {
+		password := data.Get("pkcs12_password").(string)
+		if password == "" {
+			return logical.ErrorResponse(`the "pkcs12_password" parameter is required when "format" is "pkcs12"`), nil
+		}
+		if _, err := bmpString(password); err != nil {
+			return logical.ErrorResponse(fmt.Sprintf(`invalid "pkcs12_password": %v`, err)), nil
+		}
+	}
+
+	if role.RequireKeyAttestation && !useCSR {
+		return logical.ErrorResponse("role requires keys to be attested, so they cannot be generated by Vault; use the sign endpoint with a key_attestation instead"), nil
+	}
+
+	certMetadata := data.Get("cert_metadata").(map[string]string)
+	if len(certMetadata) > 0 && role.NoStore {
+		return logical.ErrorResponse("cert_metadata cannot be stored as the role does not store certificates (no_store=true)"), nil
 	}
 
 	var caErr error
 	sc := b.makeStorageContext(ctx, req.Storage)
-	signingBundle, caErr := sc.fetchCAInfo(issuerName, IssuanceUsage)
+	signingBundle, issuerId, caErr := sc.fetchCAInfoWithIssuer(issuerName, IssuanceUsage)
 	if caErr != nil {
 		switch caErr.(type) {
 		case errutil.UserError:
@@ -364,13 +474,44 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		apiData: data,
 		role:    role,
 	}
+
+	if rawSerial, ok := data.GetOk("certificate_serial_number"); ok {
+		if role.NoStore {
+			return logical.ErrorResponse("certificate_serial_number cannot be used as the role does not store certificates (no_store=true), to detect duplicate serial numbers"), nil
+		}
+
+		serial, err := parseRequestedSerialNumber(rawSerial.(string))
+		if err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+
+		existing, err := fetchCertBySerial(sc, "certs/", normalizeSerialFromBigInt(serial))
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return logical.ErrorResponse(fmt.Sprintf("a certificate with serial number %v was already issued", serialFromBigInt(serial))), nil
+		}
+
+		input.serialNumber = serial
+	}
+
+	if err := loadPolicyWebhook(sc, input); err != nil {
+		return nil, err
+	}
+
+	release, err := b.acquireIssuanceLimits(sc, role)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	var parsedBundle *certutil.ParsedCertBundle
-	var err error
 	var warnings []string
 	if useCSR {
-		parsedBundle, warnings, err = signCert(b, input, signingBundle, false, useCSRValues)
+		parsedBundle, warnings, err = signCert(ctx, b, input, signingBundle, false, useCSRValues)
 	} else {
-		parsedBundle, warnings, err = generateCert(sc, input, signingBundle, false, rand.Reader)
+		parsedBundle, warnings, err = generateCert(sc, input, signingBundle, false, b.Backend.GetRandomReader())
 	}
 	if err != nil {
 		switch err.(type) {
@@ -383,6 +524,18 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	lintWarnings, err := lintCertificate(role, parsedBundle.Certificate)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	warnings = append(warnings, lintWarnings...)
+
+	if len(role.CTLogURLs) > 0 {
+		if err := embedSCTs(ctx, signingBundle, parsedBundle, role.CTLogURLs, b.Backend.GetRandomReader()); err != nil {
+			return nil, fmt.Errorf("error embedding SCTs: %w", err)
+		}
+	}
+
 	cb, err := parsedBundle.ToCertBundle()
 	if err != nil {
 		return nil, fmt.Errorf("error converting raw cert bundle to cert bundle: %w", err)
@@ -401,14 +554,32 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 	if !role.NoStore {
 		key := "certs/" + normalizeSerial(cb.SerialNumber)
 		certsCounted := b.certsCounted.Load()
-		err = req.Storage.Put(ctx, &logical.StorageEntry{
-			Key:   key,
-			Value: parsedBundle.CertificateBytes,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("unable to store certificate locally: %w", err)
+		if resp, err := b.storeIssuedCert(sc, input, key, parsedBundle.CertificateBytes); resp != nil || err != nil {
+			return resp, err
 		}
 		b.ifCountEnabledIncrementTotalCertificatesCount(certsCounted, key)
+
+		metadata := &certMetadataEntry{
+			Role:         role.Name,
+			IssuerId:     issuerId,
+			CertMetadata: certMetadata,
+		}
+		if err := sc.writeCertMetadata(cb.SerialNumber, metadata); err != nil {
+			return nil, fmt.Errorf("unable to store certificate metadata: %w", err)
+		}
+
+		pregenerateOcspResponse(sc, parsedBundle.Certificate, signingBundle.Certificate)
+	}
+
+	if role.EscrowPrivateKeys && parsedBundle.PrivateKey != nil {
+		escrowed := &escrowedKeyEntry{
+			PrivateKey:     cb.PrivateKey,
+			PrivateKeyType: cb.PrivateKeyType,
+			Role:           role.Name,
+		}
+		if err := sc.writeEscrowedKey(cb.SerialNumber, escrowed); err != nil {
+			return nil, fmt.Errorf("unable to escrow private key: %w", err)
+		}
 	}
 
 	if useCSR {
@@ -420,6 +591,13 @@ func (b *backend) pathIssueSignCert(ctx context.Context, req *logical.Request, d
 		}
 	}
 
+	eventType := eventTypeCertIssue
+	if useCSR {
+		eventType = eventTypeCertSign
+	}
+	b.sendCertEvent(ctx, eventType, parsedBundle.Certificate, role.Name, issuerId, req.EntityID)
+	b.countIssuedCertificate(role.Name)
+
 	resp = addWarnings(resp, warnings)
 
 	return resp, nil
@@ -469,6 +647,21 @@ func (cac *caChainOutput) derEncodedChain() []string {
 	return derCaChain
 }
 
+// pkcs7EncodedChain returns a base64 encoded, degenerate (certificates-only)
+// PKCS#7 bundle of the certificate followed by its chain.
+func (cac *caChainOutput) pkcs7EncodedChain(certificate []byte) (string, error) {
+	certs := append([]byte{}, certificate...)
+	for _, caCert := range cac.chain {
+		certs = append(certs, caCert.Bytes...)
+	}
+
+	p7, err := pkcs7.DegenerateCertificate(certs)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(p7), nil
+}
+
 func signIssueApiResponse(b *backend, data *framework.FieldData, parsedBundle *certutil.ParsedCertBundle, signingBundle *certutil.CAInfoBundle, generateLease bool, warnings []string) (*logical.Response, error) {
 	cb, err := parsedBundle.ToCertBundle()
 	if err != nil {
@@ -488,7 +681,7 @@ func signIssueApiResponse(b *backend, data *framework.FieldData, parsedBundle *c
 		"serial_number": cb.SerialNumber,
 	}
 
-	format := getFormat(data)
+	format := getIssueSignFormat(data, includeKey)
 	switch format {
 	case "pem":
 		respData["issuing_ca"] = signingCB.Certificate
@@ -512,7 +705,7 @@ func signIssueApiResponse(b *backend, data *framework.FieldData, parsedBundle *c
 			respData["private_key_type"] = cb.PrivateKeyType
 		}
 
-	case "der":
+	case "der", "pkcs7":
 		respData["certificate"] = base64.StdEncoding.EncodeToString(parsedBundle.CertificateBytes)
 		respData["issuing_ca"] = base64.StdEncoding.EncodeToString(signingBundle.CertificateBytes)
 
@@ -524,6 +717,36 @@ func signIssueApiResponse(b *backend, data *framework.FieldData, parsedBundle *c
 			respData["private_key"] = base64.StdEncoding.EncodeToString(parsedBundle.PrivateKeyBytes)
 			respData["private_key_type"] = cb.PrivateKeyType
 		}
+
+		if format == "pkcs7" {
+			p7, err := caChainGen.pkcs7EncodedChain(parsedBundle.CertificateBytes)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding PKCS#7 bundle: %w", err)
+			}
+			respData["pkcs7"] = p7
+		}
+
+	case "pkcs12":
+		// The private key is only returned within the password protected
+		// bundle; the certificates are also returned as PEM for convenience.
+		respData["issuing_ca"] = signingCB.Certificate
+		respData["certificate"] = cb.Certificate
+		if caChainGen.containsChain() {
+			respData["ca_chain"] = caChainGen.pemEncodedChain()
+		}
+
+		var chain []*x509.Certificate
+		for _, certBlock := range caChainGen.chain {
+			chain = append(chain, certBlock.Certificate)
+		}
+
+		bundle, err := encodePKCS12(parsedBundle.PrivateKey, parsedBundle.Certificate, chain, data.Get("pkcs12_password").(string))
+		if err != nil {
+			return nil, fmt.Errorf("error encoding PKCS#12 bundle: %w", err)
+		}
+		respData["pkcs12"] = base64.StdEncoding.EncodeToString(bundle)
+		respData["private_key_type"] = cb.PrivateKeyType
+
 	default:
 		return nil, fmt.Errorf("unsupported format: %s", format)
 	}
@@ -542,7 +765,7 @@ func signIssueApiResponse(b *backend, data *framework.FieldData, parsedBundle *c
 		}
 	}
 
-	if includeKey {
+	if includeKey && format != "pkcs12" {
 		if keyFormat := data.Get("private_key_format"); keyFormat == "pkcs8" {
 			err := convertRespToPKCS8(resp)
 			if err != nil {
@@ -582,3 +805,37 @@ requested common name is allowed by the role policy.
 This path requires a CSR; if you want Vault to generate a private key
 for you, use the issue path instead.
 `
+
+const pathWithSerialHelpDesc = `
+The *-with-serial variants of this path additionally take the serial
+number of the certificate in certificate_serial_number, for compatibility
+with existing asset tracking schemes. The serial number must not have been
+issued by this mount before, and the role must store certificates.
+`
+
+// storeIssuedCert stores a freshly issued certificate under key. When the
+// caller requested the serial number, the check that it wasn't issued in
+// the meantime and the write happen under requestedSerialLock, so two
+// concurrent requests for the same serial can't both succeed.
+func (b *backend) storeIssuedCert(sc *storageContext, input *inputBundle, key string, certBytes []byte) (*logical.Response, error) {
+	if input.serialNumber != nil {
+		b.requestedSerialLock.Lock()
+		defer b.requestedSerialLock.Unlock()
+
+		existing, err := sc.Storage.Get(sc.Context, key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check for an existing certificate: %w", err)
+		}
+		if existing != nil {
+			return logical.ErrorResponse(fmt.Sprintf("a certificate with serial number %v was already issued", serialFromBigInt(input.serialNumber))), nil
+		}
+	}
+
+	if err := sc.Storage.Put(sc.Context, &logical.StorageEntry{
+		Key:   key,
+		Value: certBytes,
+	}); err != nil {
+		return nil, fmt.Errorf("unable to store certificate locally: %w", err)
+	}
+	return nil, nil
+}
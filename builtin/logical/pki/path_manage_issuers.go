@@ -6,7 +6,9 @@ package pki
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"net/http"
@@ -188,6 +190,7 @@ func buildPathGenerateIntermediate(b *backend, pattern string, displayAttrs *fra
 
 	ret.Fields = addCACommonFields(map[string]*framework.FieldSchema{})
 	ret.Fields = addCAKeyGenerationFields(ret.Fields)
+	ret.Fields = addCANameConstraintsFields(ret.Fields)
 	ret.Fields["add_basic_constraints"] = &framework.FieldSchema{
 		Type: framework.TypeBool,
 		Description: `Whether to add a Basic Constraints
@@ -219,6 +222,21 @@ func pathImportIssuer(b *backend) *framework.Path {
 				Description: `PEM-format, concatenated unencrypted
 secret-key (optional) and certificates.`,
 			},
+			"pkcs12_bundle": {
+				Type: framework.TypeString,
+				Description: `Base64-encoded PKCS#12 bundle of the secret-key
+(optional) and
+certificates, decrypted with "passphrase".
+Mutually exclusive with "pem_bundle".`,
+			},
+			"passphrase": {
+				Type: framework.TypeString,
+				Description: `Passphrase decrypting the "pkcs12_bundle" or
+any encrypted private keys within the "pem_bundle".`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Sensitive: true,
+				},
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -281,14 +299,42 @@ func (b *backend) pathImportIssuers(ctx context.Context, req *logical.Request, d
 
 	var pemBundle string
 	var certificate string
+	var pkcs12Bundle string
+	var passphrase string
 	rawPemBundle, bundleOk := data.GetOk("pem_bundle")
 	rawCertificate, certOk := data.GetOk("certificate")
+	rawPkcs12Bundle, pkcs12Ok := data.GetOk("pkcs12_bundle")
+	rawPassphrase, passphraseOk := data.GetOk("passphrase")
 	if bundleOk {
 		pemBundle = rawPemBundle.(string)
 	}
 	if certOk {
 		certificate = rawCertificate.(string)
 	}
+	if pkcs12Ok {
+		pkcs12Bundle = rawPkcs12Bundle.(string)
+	}
+	if passphraseOk {
+		passphrase = rawPassphrase.(string)
+	}
+
+	if len(pkcs12Bundle) > 0 {
+		if len(pemBundle) > 0 || len(certificate) > 0 {
+			return logical.ErrorResponse("'pkcs12_bundle' cannot be provided alongside 'pem_bundle' or 'certificate'"), nil
+		}
+
+		// Decrypt the bundle into its PEM equivalent, so it is imported
+		// exactly as if the operator had converted it themselves.
+		pkcs12Bytes, err := base64.StdEncoding.DecodeString(pkcs12Bundle)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("'pkcs12_bundle' must be base64 encoded: %v", err)), nil
+		}
+
+		pemBundle, err = decodePKCS12(pkcs12Bytes, passphrase)
+		if err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("error decoding 'pkcs12_bundle': %v", err)), nil
+		}
+	}
 
 	if len(pemBundle) == 0 && len(certificate) == 0 {
 		return logical.ErrorResponse("'pem_bundle' and 'certificate' parameters were empty"), nil
@@ -356,8 +402,12 @@ func (b *backend) pathImportIssuers(ctx context.Context, req *logical.Request, d
 			// curves and 99% of software doesn't either, so discard them
 			// without parsing them.
 		default:
-			// Otherwise, treat them as keys.
-			keys = append(keys, pemBlockString)
+			// Otherwise, treat them as keys, decrypting any encrypted ones.
+			keyBlock, err := decryptPEMPrivateKey(pemBlock, passphrase)
+			if err != nil {
+				return logical.ErrorResponse(err.Error()), nil
+			}
+			keys = append(keys, string(pem.EncodeToMemory(keyBlock)))
 		}
 	}
 
@@ -566,6 +616,21 @@ func pathRevokeIssuer(b *backend) *framework.Path {
 								Description: ``,
 								Required:    true,
 							},
+							"policy_identifiers": {
+								Type:        framework.TypeStringSlice,
+								Description: `Policy Identifiers`,
+								Required:    false,
+							},
+							"serial_number_bits": {
+								Type:        framework.TypeInt,
+								Description: `Serial Number Bits`,
+								Required:    false,
+							},
+							"serial_number_prefix": {
+								Type:        framework.TypeString,
+								Description: `Serial Number Prefix`,
+								Required:    false,
+							},
 							"usage": {
 								Type:        framework.TypeString,
 								Description: `Allowed usage`,
@@ -596,6 +661,11 @@ func pathRevokeIssuer(b *backend) *framework.Path {
 								Description: `Specifies the URL values for the OCSP Servers field`,
 								Required:    true,
 							},
+							"delta_crl_distribution_points": {
+								Type:        framework.TypeStringSlice,
+								Description: `Specifies the URL values for the Freshest CRL extension of complete CRLs`,
+								Required:    false,
+							},
 							"revocation_time": {
 								Type:        framework.TypeInt64,
 								Description: `Time of revocation`,
@@ -661,8 +731,10 @@ func (b *backend) pathRevokeIssuer(ctx context.Context, req *logical.Request, da
 	// new revocations of leaves issued by this issuer to trigger a CRL
 	// rebuild still.
 	issuer.Revoked = true
-	if issuer.Usage.HasUsage(IssuanceUsage) {
-		issuer.Usage.ToggleUsage(IssuanceUsage)
+	for _, usage := range []issuerUsage{IssuanceUsage, IntermediateSigningUsage} {
+		if issuer.Usage.HasUsage(usage) {
+			issuer.Usage.ToggleUsage(usage)
+		}
 	}
 
 	currTime := time.Now()
@@ -729,6 +801,13 @@ func (b *backend) pathRevokeIssuer(ctx context.Context, req *logical.Request, da
 		}
 	}
 
+	b.ocspCache.purge()
+
+	// The issuer's own parent isn't necessarily known to this mount.
+	if issuerCert, err := issuer.GetCertificate(); err == nil {
+		b.sendCertEvent(ctx, eventTypeCertRevoke, issuerCert, "", "", req.EntityID)
+	}
+
 	// Rebuild the CRL to include the newly revoked issuer.
 	warnings, crlErr := b.crlBuilder.rebuild(sc, false)
 	if crlErr != nil {
@@ -764,6 +843,7 @@ func (b *backend) pathRevokeIssuer(ctx context.Context, req *logical.Request, da
 
 	isSelfSigned := false
 	haveOtherIssuer := false
+	var childIssuers []string
 	for _, candidateID := range allIssuers {
 		candidate, err := sc.fetchIssuerById(candidateID)
 		if err != nil {
@@ -789,10 +869,12 @@ func (b *backend) pathRevokeIssuer(ctx context.Context, req *logical.Request, da
 			}
 		}
 
-		// If we have both possible warning candidates, no sense continuing
-		// to check signatures; exit.
-		if isSelfSigned && haveOtherIssuer {
-			break
+		// Issuers signed by this one remain usable, but their chains now
+		// include a revoked certificate.
+		if candidate.ID != issuer.ID {
+			if err := candidateCert.CheckSignatureFrom(ourCert); err == nil {
+				childIssuers = append(childIssuers, candidate.ID.String())
+			}
 		}
 	}
 
@@ -809,6 +891,16 @@ func (b *backend) pathRevokeIssuer(ctx context.Context, req *logical.Request, da
 		response.AddWarning("This issuer is currently configured as the default issuer for this mount; operations such as certificate issuance may not work until a new default issuer is selected.")
 	}
 
+	if len(childIssuers) > 0 {
+		response.AddWarning(fmt.Sprintf("This issuer signed other issuers of this mount, whose chains now include a revoked certificate; consider revoking or replacing them as well: %v", strings.Join(childIssuers, ", ")))
+	}
+
+	// Roles may reference the issuer by either its identifier or its name.
+	addWarningOnDereferencing(sc, issuer.ID.String(), response)
+	if len(issuer.Name) > 0 {
+		addWarningOnDereferencing(sc, issuer.Name, response)
+	}
+
 	return response, nil
 }
 
@@ -822,6 +914,242 @@ mount point (utilizing the multi-issuer functionality). If no suitable
 parent is found, this revocation may not appear on any CRL in this mount.
 
 Once revoked, issuers cannot be unrevoked and may not be used to sign any
-more certificates.
+more certificates. The response warns about what still references the
+issuer: roles, the default issuer configuration, and issuers it signed.
+Reading roles or the issuers configuration which still reference it warns
+as well.
+`
+)
+
+func pathCrossSignIssuer(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "issuers/cross-sign",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKIIssuers,
+			OperationVerb:   "cross-sign",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"subject_issuer_ref": {
+				Type: framework.TypeString,
+				Description: `Reference to the issuer to cross-sign, either by
+Vault-generated identifier, the literal string "default" to refer to the
+currently configured default issuer, or the name assigned to an issuer.`,
+			},
+			"signing_issuer_ref": {
+				Type: framework.TypeString,
+				Description: `Reference to the issuer to sign the certificate of
+subject_issuer_ref with, either by Vault-generated identifier, the literal
+string "default" to refer to the currently configured default issuer, or the
+name assigned to an issuer.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathCrossSignIssuer,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"issuer_id": {
+								Type:        framework.TypeString,
+								Description: `The ID of the cross-signed issuer`,
+								Required:    true,
+							},
+							"issuer_name": {
+								Type:        framework.TypeString,
+								Description: `The name of the cross-signed issuer`,
+								Required:    true,
+							},
+							"key_id": {
+								Type:        framework.TypeString,
+								Description: `The ID of the key, shared with the subject issuer`,
+								Required:    true,
+							},
+							"serial_number": {
+								Type:        framework.TypeString,
+								Description: `The serial number of the cross-signed certificate`,
+								Required:    true,
+							},
+							"certificate": {
+								Type:        framework.TypeString,
+								Description: `The cross-signed certificate`,
+								Required:    true,
+							},
+							"ca_chain": {
+								Type:        framework.TypeStringSlice,
+								Description: `The chain of the cross-signed issuer`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+				// Read more about why these flags are set in backend.go
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathCrossSignIssuerHelpSyn,
+		HelpDescription: pathCrossSignIssuerHelpDesc,
+	}
+}
+
+func (b *backend) pathCrossSignIssuer(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Since we're planning on updating issuers here, grab the lock so we've
+	// got a consistent view.
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("Can not cross-sign issuers until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+
+	var issuerIds []issuerID
+	for _, field := range []string{"subject_issuer_ref", "signing_issuer_ref"} {
+		issuerRef := data.Get(field).(string)
+		if len(issuerRef) == 0 {
+			return logical.ErrorResponse("missing %v", field), nil
+		}
+
+		issuerId, err := sc.resolveIssuerReference(issuerRef)
+		if err != nil {
+			if issuerId == IssuerRefNotFound {
+				return logical.ErrorResponse("unable to resolve issuer id for reference: " + issuerRef), nil
+			}
+			return nil, err
+		}
+		issuerIds = append(issuerIds, issuerId)
+	}
+	subjectId, signingId := issuerIds[0], issuerIds[1]
+	if subjectId == signingId {
+		return logical.ErrorResponse("an issuer can't cross-sign itself"), nil
+	}
+
+	subject, err := sc.fetchIssuerById(subjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	crossSigned, err := sc.crossSignIssuer(subject, signingId)
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return logical.ErrorResponse(err.Error()), nil
+		default:
+			return nil, err
+		}
+	}
+
+	// Chains of other issuers were rebuilt on import; fetch ours as stored.
+	crossSigned, err = sc.fetchIssuerById(crossSigned.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &logical.Response{
+		Data: map[string]interface{}{
+			"issuer_id":     crossSigned.ID,
+			"issuer_name":   crossSigned.Name,
+			"key_id":        crossSigned.KeyID,
+			"serial_number": crossSigned.SerialNumber,
+			"certificate":   crossSigned.Certificate,
+			"ca_chain":      crossSigned.CAChain,
+		},
+	}
+
+	// Issuers sharing the subject's key and subject share its CRL, which
+	// now needs to be signed for the new issuer as well.
+	warnings, err := b.crlBuilder.rebuild(sc, true)
+	if err != nil {
+		return nil, err
+	}
+	for index, warning := range warnings {
+		response.AddWarning(fmt.Sprintf("Warning %d during CRL rebuild: %v", index+1, warning))
+	}
+
+	return response, nil
+}
+
+// crossSignIssuer signs the certificate of the subject issuer with the
+// signer issuer, keeping its subject and key, and imports the result as a
+// new issuer. Chains of issuers under the subject then also reach the
+// signer.
+func (sc *storageContext) crossSignIssuer(subject *issuerEntry, signerId issuerID) (*issuerEntry, error) {
+	subjectCert, err := subject.GetCertificate()
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error parsing certificate of issuer %v: %v", subject.ID, err)}
+	}
+
+	signingBundle, err := sc.fetchCAInfoByIssuerId(signerId, IntermediateSigningUsage)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chains can't be built through cross-signatures between issuers of the
+	// same subject, as their certificates would be indistinguishable issuers
+	// of each other.
+	if bytes.Equal(subjectCert.RawSubject, signingBundle.Certificate.RawSubject) {
+		return nil, errutil.UserError{Err: fmt.Sprintf("issuers %v and %v have the same subject; only issuers of distinct subjects can be cross-signed", subject.ID, signerId)}
+	}
+
+	serialNumber, err := generateSerialNumber(signingBundle)
+	if err != nil {
+		return nil, err
+	}
+
+	template := *subjectCert
+	template.SerialNumber = serialNumber
+	template.AuthorityKeyId = nil
+	template.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
+	if template.NotAfter.After(signingBundle.Certificate.NotAfter) {
+		template.NotAfter = signingBundle.Certificate.NotAfter
+	}
+
+	urls := signingBundle.URLs
+	if urls != nil {
+		template.IssuingCertificateURL = urls.IssuingCertificates
+		template.CRLDistributionPoints = urls.CRLDistributionPoints
+		template.OCSPServer = urls.OCSPServers
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, signingBundle.Certificate, subjectCert.PublicKey, signingBundle.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error cross-signing issuer %v with issuer %v: %w", subject.ID, signerId, err)
+	}
+
+	// Store it as just the certificate identified by serial number, so it
+	// can be revoked
+	key := "certs/" + normalizeSerial(serialFromBigInt(serialNumber))
+	certsCounted := sc.Backend.certsCounted.Load()
+	if err := sc.Storage.Put(sc.Context, &logical.StorageEntry{Key: key, Value: certBytes}); err != nil {
+		return nil, fmt.Errorf("unable to store certificate locally: %w", err)
+	}
+	sc.Backend.ifCountEnabledIncrementTotalCertificatesCount(certsCounted, key)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})
+	crossSigned, _, err := sc.importIssuer(string(certPem), "")
+	if err != nil {
+		return nil, fmt.Errorf("error importing cross-signed issuer: %w", err)
+	}
+
+	return crossSigned, nil
+}
+
+const (
+	pathCrossSignIssuerHelpSyn  = `Cross-sign an issuer with another issuer of this mount.`
+	pathCrossSignIssuerHelpDesc = `
+This endpoint signs the certificate of subject_issuer_ref with the key of
+signing_issuer_ref, keeping its subject and key, and imports the result as a
+new issuer. Chains are rebuilt, so that certificates issued under the subject
+issuer also chain to the signing issuer.
+
+Unlike cross-signing through intermediate/cross-sign, this needs no CSR and
+no separate signing and import steps, but both issuers must be in this mount
+and have distinct subjects.
 `
 )
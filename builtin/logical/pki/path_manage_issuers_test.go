@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_CrossSignIssuer(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "issuers/generate/root/internal", map[string]interface{}{
+		"common_name": "root x1",
+		"issuer_name": "x1",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuers/generate/root/internal")
+	x1KeyId := resp.Data["key_id"]
+	x1Cert := parseCert(t, resp.Data["certificate"].(string))
+
+	resp, err = CBWrite(b, s, "issuers/generate/root/internal", map[string]interface{}{
+		"common_name": "root x2",
+		"issuer_name": "x2",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuers/generate/root/internal")
+	x2Pem := resp.Data["certificate"].(string) + "\n"
+	x2Cert := parseCert(t, x2Pem)
+
+	resp, err = CBWrite(b, s, "issuers/generate/root/internal", map[string]interface{}{
+		"common_name": "root x2",
+		"issuer_name": "x2-reissued",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuers/generate/root/internal")
+
+	_, err = CBWrite(b, s, "issuers/cross-sign", map[string]interface{}{
+		"subject_issuer_ref": "x1",
+	})
+	require.ErrorContains(t, err, "missing signing_issuer_ref")
+	_, err = CBWrite(b, s, "issuers/cross-sign", map[string]interface{}{
+		"subject_issuer_ref": "x1",
+		"signing_issuer_ref": "x1",
+	})
+	require.ErrorContains(t, err, "can't cross-sign itself")
+	_, err = CBWrite(b, s, "issuers/cross-sign", map[string]interface{}{
+		"subject_issuer_ref": "x2",
+		"signing_issuer_ref": "x2-reissued",
+	})
+	require.ErrorContains(t, err, "have the same subject")
+
+	resp, err = CBWrite(b, s, "issuers/cross-sign", map[string]interface{}{
+		"subject_issuer_ref": "x1",
+		"signing_issuer_ref": "x2",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuers/cross-sign")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issuers/cross-sign"), logical.UpdateOperation), resp, true)
+
+	// The cross-signed issuer reuses x1's key and subject, and chains to x2.
+	require.Equal(t, x1KeyId, resp.Data["key_id"])
+	cert := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, x1Cert.RawSubject, cert.RawSubject)
+	require.Equal(t, x1Cert.RawSubjectPublicKeyInfo, cert.RawSubjectPublicKeyInfo)
+	require.NoError(t, cert.CheckSignatureFrom(x2Cert))
+	require.Contains(t, resp.Data["ca_chain"], x2Pem)
+
+	// So does x1 itself, through the cross-signed issuer.
+	resp, err = CBRead(b, s, "issuer/x1")
+	requireSuccessNonNilResponse(t, resp, err, "issuer/x1")
+	require.Contains(t, resp.Data["ca_chain"], x2Pem)
+
+	// The cross-signed certificate is stored, so it can be revoked.
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(cert))
+	requireSuccessNonNilResponse(t, resp, err, "cert")
+}
+
+func TestPki_RevokedIssuerWarnings(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"issuer_name": "root",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootId := resp.Data["issuer_id"].(issuerID)
+
+	resp, err = CBWrite(b, s, "intermediate/generate/internal", map[string]interface{}{
+		"common_name": "intermediate example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "intermediate/generate/internal")
+	resp, err = CBWrite(b, s, "root/sign-intermediate", map[string]interface{}{
+		"csr": resp.Data["csr"],
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/sign-intermediate")
+	resp, err = CBWrite(b, s, "issuers/import/cert", map[string]interface{}{
+		"pem_bundle": resp.Data["certificate"],
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuers/import/cert")
+	intId := resp.Data["imported_issuers"].([]string)[0]
+
+	resp, err = CBWrite(b, s, "roles/by-name", map[string]interface{}{
+		"allow_any_name": true,
+		"issuer_ref":     "root",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/by-name")
+	require.Empty(t, resp.Warnings)
+
+	resp, err = CBWrite(b, s, "issuer/root/revoke", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/root/revoke")
+	require.Contains(t, resp.Warnings, "1 roles reference root")
+	require.Contains(t, resp.Warnings, "This issuer signed other issuers of this mount, whose chains now include a revoked certificate; consider revoking or replacing them as well: "+intId)
+
+	// Issuance is blocked with an explicit error.
+	_, err = CBWrite(b, s, "issue/by-name", map[string]interface{}{
+		"common_name": "www.example.com",
+	})
+	require.ErrorContains(t, err, "was revoked and can no longer issue certificates")
+
+	// Paths still referencing the issuer warn.
+	revokedWarning := "Issuer root (" + rootId.String() + ") was revoked; certificates can no longer be issued through it"
+	resp, err = CBRead(b, s, "roles/by-name")
+	requireSuccessNonNilResponse(t, resp, err, "roles/by-name")
+	require.Contains(t, resp.Warnings, revokedWarning)
+
+	resp, err = CBWrite(b, s, "roles/by-name", map[string]interface{}{
+		"allow_any_name": true,
+		"issuer_ref":     "root",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/by-name")
+	require.Contains(t, resp.Warnings, revokedWarning)
+
+	resp, err = CBRead(b, s, "config/issuers")
+	requireSuccessNonNilResponse(t, resp, err, "config/issuers")
+	require.Contains(t, resp.Warnings, "Issuer "+rootId.String()+" ("+rootId.String()+") was revoked; certificates can no longer be issued through it")
+
+	resp, err = CBWrite(b, s, "config/issuers", map[string]interface{}{
+		"default": intId,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/issuers")
+	require.Empty(t, resp.Warnings)
+	resp, err = CBRead(b, s, "config/issuers")
+	requireSuccessNonNilResponse(t, resp, err, "config/issuers")
+	require.Empty(t, resp.Warnings)
+}
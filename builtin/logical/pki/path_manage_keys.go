@@ -6,7 +6,10 @@ package pki
 import (
 	"bytes"
 	"context"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -318,3 +321,363 @@ func (b *backend) pathImportKeyHandler(ctx context.Context, req *logical.Request
 
 	return &resp, nil
 }
+
+func pathImportWrappingKey(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/import-wrapping-key",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "read",
+			OperationSuffix: "import-wrapping-key",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathImportWrappingKeyRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"public_key": {
+								Type:        framework.TypeString,
+								Description: `PEM-format RSA public key to wrap imported keys to.`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+				// The wrapping key is generated on first read.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathImportWrappingKeyHelpSyn,
+		HelpDescription: pathImportWrappingKeyHelpDesc,
+	}
+}
+
+const (
+	pathImportWrappingKeyHelpSyn  = `Returns the public key to use for wrapping imported keys.`
+	pathImportWrappingKeyHelpDesc = `This endpoint returns the RSA-4096 public key of this mount, to which
+keys imported through keys/import-wrapped must be wrapped.`
+)
+
+func (b *backend) pathImportWrappingKeyRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	// The wrapping key may need to be generated; hold the lock so that
+	// concurrent requests agree on a single key.
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	wrappingKey, err := sc.getImportWrappingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(wrappingKey.Public())
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling import wrapping key: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		},
+	}, nil
+}
+
+func pathImportWrappedKey(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "keys/import-wrapped",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "import",
+			OperationSuffix: "wrapped-key",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			keyNameParam: {
+				Type:        framework.TypeString,
+				Description: "Optional name to be used for this key",
+			},
+			"ciphertext": {
+				Type: framework.TypeString,
+				Description: `The base64-encoded ciphertext of the keys. The
+AES key should be encrypted using OAEP with the
+wrapping key and then concatenated with the
+PKCS#8 private key wrapped by the AES key.`,
+			},
+			"hash_function": {
+				Type:    framework.TypeString,
+				Default: "SHA256",
+				Description: `The hash function used as a random oracle in
+the OAEP wrapping of the ephemeral AES key. Can
+be one of "SHA1", "SHA224", "SHA256" (default),
+"SHA384", or "SHA512".`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathImportWrappedKeyHandler,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"key_id": {
+								Type:        framework.TypeString,
+								Description: `ID assigned to this key.`,
+								Required:    true,
+							},
+							"key_name": {
+								Type:        framework.TypeString,
+								Description: `Name assigned to this key.`,
+								Required:    true,
+							},
+							"key_type": {
+								Type: framework.TypeString,
+								Description: `The type of key to use; defaults to RSA. "rsa"
+								"ec" and "ed25519" are the only valid values.`,
+								Required: true,
+							},
+						},
+					}},
+				},
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathImportWrappedKeyHelpSyn,
+		HelpDescription: pathImportWrappedKeyHelpDesc,
+	}
+}
+
+const (
+	pathImportWrappedKeyHelpSyn  = `Import the specified key, wrapped to the import wrapping key.`
+	pathImportWrappedKeyHelpDesc = `This endpoint allows importing a specified issuer key wrapped to the
+key returned by keys/import-wrapping-key, so that the key is never sent in
+plaintext. The wrapping scheme is the same as Transit's BYOK import. If
+key_name is set, that will be set on the key, assuming the key did not exist
+previously.`
+)
+
+func (b *backend) pathImportWrappedKeyHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	// Since we're planning on updating issuers here, grab the lock so we've
+	// got a consistent view.
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("Cannot import keys until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	keyName, err := getKeyName(sc, data)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	hashFn, err := parseWrappingHashFn(data.Get("hash_function").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(data.Get("ciphertext").(string))
+	if err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("error decoding ciphertext: %v", err)), nil
+	}
+
+	wrappingKey, err := sc.getImportWrappingKey()
+	if err != nil {
+		return nil, err
+	}
+
+	keyDer, err := unwrapImportedKey(wrappingKey, ciphertext, hashFn)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if _, err := x509.ParsePKCS8PrivateKey(keyDer); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("unwrapped key is not a PKCS#8 private key: %v", err)), nil
+	}
+
+	keyPem := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}))
+	key, existed, err := importKeyFromBytes(sc, keyPem, keyName)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	resp := logical.Response{
+		Data: map[string]interface{}{
+			keyIdParam:   key.ID,
+			keyNameParam: key.Name,
+			keyTypeParam: key.PrivateKeyType,
+		},
+	}
+
+	if existed {
+		resp.AddWarning("Key already imported, use key/ endpoint to update name.")
+	}
+
+	return &resp, nil
+}
+
+func pathExportWrappedKey(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "key/" + framework.GenericNameRegex(keyRefParam) + "/export-wrapped",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "export",
+			OperationSuffix: "wrapped-key",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			keyRefParam: {
+				Type:        framework.TypeString,
+				Description: `Reference to key; either "default" for the configured default key, an identifier of a key, or the name assigned to the key.`,
+				Default:     defaultRef,
+			},
+			"public_key": {
+				Type: framework.TypeString,
+				Description: `PEM-format RSA public key (of at least 2048
+bits) to wrap the exported key to. This may be the
+wrapping key of another mount, of Transit, or of
+an external KMS or HSM.`,
+			},
+			"hash_function": {
+				Type:    framework.TypeString,
+				Default: "SHA256",
+				Description: `The hash function used as a random oracle in
+the OAEP wrapping of the ephemeral AES key. Can
+be one of "SHA1", "SHA224", "SHA256" (default),
+"SHA384", or "SHA512".`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathExportWrappedKeyHandler,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"key_id": {
+								Type:        framework.TypeString,
+								Description: `Key Id`,
+								Required:    true,
+							},
+							"key_name": {
+								Type:        framework.TypeString,
+								Description: `Key Name`,
+								Required:    true,
+							},
+							"key_type": {
+								Type:        framework.TypeString,
+								Description: `Key Type`,
+								Required:    true,
+							},
+							"ciphertext": {
+								Type:        framework.TypeString,
+								Description: `The base64-encoded wrapped PKCS#8 private key`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+				ForwardPerformanceStandby:   false,
+				ForwardPerformanceSecondary: false,
+			},
+		},
+
+		HelpSynopsis:    pathExportWrappedKeyHelpSyn,
+		HelpDescription: pathExportWrappedKeyHelpDesc,
+	}
+}
+
+const (
+	pathExportWrappedKeyHelpSyn  = `Export the specified key, wrapped to a caller-supplied public key.`
+	pathExportWrappedKeyHelpDesc = `This endpoint allows exporting an issuer key, such as for escrow in
+offline storage, without the key ever being returned in plaintext. The
+PKCS#8 private key is wrapped with an ephemeral AES-256 key (AES-KWP), which
+is in turn encrypted with RSA-OAEP to the supplied public key; this is the
+same scheme accepted by keys/import-wrapped and by Transit's BYOK import.
+
+Exporting keys must first be enabled by setting allow_wrapped_export on
+config/keys. Managed keys cannot be exported.`
+)
+
+func (b *backend) pathExportWrappedKeyHandler(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("Can not export keys until migration has completed"), nil
+	}
+
+	keyRef := data.Get(keyRefParam).(string)
+	if len(keyRef) == 0 {
+		return logical.ErrorResponse("missing key reference"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getKeysConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.AllowWrappedExport {
+		return logical.ErrorResponse("wrapped key export is disabled; set allow_wrapped_export on config/keys to enable it"), nil
+	}
+
+	wrappingKey, err := parseExportWrappingKey(data.Get("public_key").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	hashFn, err := parseWrappingHashFn(data.Get("hash_function").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	keyId, err := sc.resolveKeyReference(keyRef)
+	if err != nil {
+		return nil, err
+	}
+	if keyId == "" {
+		return logical.ErrorResponse("unable to resolve key id for reference: " + keyRef), nil
+	}
+
+	key, err := sc.fetchKeyById(keyId)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.isManagedPrivateKey() {
+		return logical.ErrorResponse("managed keys cannot be exported"), nil
+	}
+
+	bundle, err := certutil.ParsePEMBundle(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing key %s: %w", key.ID, err)
+	}
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(bundle.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling key %s: %w", key.ID, err)
+	}
+
+	ciphertext, err := wrapExportedKey(wrappingKey, keyDer, hashFn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			keyIdParam:   key.ID,
+			keyNameParam: key.Name,
+			keyTypeParam: string(key.PrivateKeyType),
+			"ciphertext": base64.StdEncoding.EncodeToString(ciphertext),
+		},
+	}, nil
+}
@@ -5,13 +5,18 @@ package pki
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"testing"
 
+	"github.com/google/tink/go/kwp/subtle"
 	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
 
 	"github.com/hashicorp/vault/sdk/helper/certutil"
@@ -439,3 +444,136 @@ func TestPKI_PathManageKeys_ImportKeyRejectsMultipleKeys(t *testing.T) {
 		t.Logf("%s:%s", id.ID, id.Name)
 	}
 }
+
+func TestPKI_PathManageKeys_ImportWrappedKey(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBRead(b, s, "keys/import-wrapping-key")
+	requireSuccessNonNilResponse(t, resp, err, "keys/import-wrapping-key")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("keys/import-wrapping-key"), logical.ReadOperation), resp, true)
+
+	block, _ := pem.Decode([]byte(resp.Data["public_key"].(string)))
+	require.NotNil(t, block)
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+	wrappingKey := pub.(*rsa.PublicKey)
+
+	// The wrapping key is stable across reads.
+	resp, err = CBRead(b, s, "keys/import-wrapping-key")
+	requireSuccessNonNilResponse(t, resp, err, "keys/import-wrapping-key")
+	require.Equal(t, string(pem.EncodeToMemory(block)), resp.Data["public_key"])
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	ephKey := make([]byte, 32)
+	_, err = rand.Read(ephKey)
+	require.NoError(t, err)
+	wrappedEphKey, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, wrappingKey, ephKey, []byte{})
+	require.NoError(t, err)
+	kwp, err := subtle.NewKWP(ephKey)
+	require.NoError(t, err)
+	wrappedKey, err := kwp.Wrap(pkcs8Key)
+	require.NoError(t, err)
+	ciphertext := base64.StdEncoding.EncodeToString(append(wrappedEphKey, wrappedKey...))
+
+	// The hash function must match the one used for wrapping.
+	_, err = CBWrite(b, s, "keys/import-wrapped", map[string]interface{}{
+		"ciphertext":    ciphertext,
+		"hash_function": "SHA1",
+	})
+	require.Error(t, err)
+
+	resp, err = CBWrite(b, s, "keys/import-wrapped", map[string]interface{}{
+		"key_name":   "wrapped",
+		"ciphertext": ciphertext,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "keys/import-wrapped")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("keys/import-wrapped"), logical.UpdateOperation), resp, true)
+	require.Equal(t, "wrapped", resp.Data["key_name"])
+	require.Equal(t, certutil.ECPrivateKey, resp.Data["key_type"])
+	keyId := resp.Data["key_id"]
+
+	// Importing the same key in plaintext finds the unwrapped key.
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8Key}))
+	resp, err = CBWrite(b, s, "keys/import", map[string]interface{}{
+		"pem_bundle": pemKey,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "keys/import")
+	require.Equal(t, keyId, resp.Data["key_id"])
+	require.NotEmpty(t, resp.Warnings)
+}
+
+func TestPKI_PathManageKeys_ExportWrappedKey(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+	bImport, sImport := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "keys/generate/internal", map[string]interface{}{
+		"key_name": "escrowed",
+		"key_type": "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "keys/generate/internal")
+	keyId := resp.Data["key_id"]
+
+	resp, err = CBRead(bImport, sImport, "keys/import-wrapping-key")
+	requireSuccessNonNilResponse(t, resp, err, "keys/import-wrapping-key")
+	wrappingKey := resp.Data["public_key"].(string)
+
+	// Export is disabled by default.
+	_, err = CBWrite(b, s, "key/escrowed/export-wrapped", map[string]interface{}{
+		"public_key": wrappingKey,
+	})
+	require.ErrorContains(t, err, "wrapped key export is disabled")
+
+	resp, err = CBWrite(b, s, "config/keys", map[string]interface{}{
+		"allow_wrapped_export": true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/keys")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("config/keys"), logical.UpdateOperation), resp, true)
+	require.Equal(t, true, resp.Data["allow_wrapped_export"])
+	require.Equal(t, keyId, resp.Data["default"])
+
+	// Only RSA public keys may be wrapped to.
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecPub, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	require.NoError(t, err)
+	_, err = CBWrite(b, s, "key/escrowed/export-wrapped", map[string]interface{}{
+		"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecPub})),
+	})
+	require.ErrorContains(t, err, "must be an RSA key")
+
+	resp, err = CBWrite(b, s, "key/escrowed/export-wrapped", map[string]interface{}{
+		"public_key": wrappingKey,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "key/escrowed/export-wrapped")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("key/escrowed/export-wrapped"), logical.UpdateOperation), resp, true)
+	require.Equal(t, keyId, resp.Data["key_id"])
+	require.Equal(t, "escrowed", resp.Data["key_name"])
+	require.Equal(t, "ec", resp.Data["key_type"])
+	require.NotContains(t, resp.Data["ciphertext"], "PRIVATE KEY")
+
+	// The wrapped key can be imported into another mount.
+	resp, err = CBWrite(bImport, sImport, "keys/import-wrapped", map[string]interface{}{
+		"key_name":   "escrowed",
+		"ciphertext": resp.Data["ciphertext"],
+	})
+	requireSuccessNonNilResponse(t, resp, err, "keys/import-wrapped")
+
+	origKey, err := CBRead(b, s, "key/escrowed")
+	requireSuccessNonNilResponse(t, origKey, err, "key/escrowed")
+	importedKey, err := CBRead(bImport, sImport, "key/escrowed")
+	requireSuccessNonNilResponse(t, importedKey, err, "key/escrowed")
+	require.Equal(t, origKey.Data["subject_key_id"], importedKey.Data["subject_key_id"])
+
+	// Setting only the default key leaves export enabled.
+	resp, err = CBWrite(b, s, "config/keys", map[string]interface{}{
+		"default": "escrowed",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/keys")
+	require.Equal(t, true, resp.Data["allow_wrapped_export"])
+}
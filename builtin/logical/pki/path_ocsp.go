@@ -37,6 +37,7 @@ type ocspRespInfo struct {
 	serialNumber      *big.Int
 	ocspStatus        int
 	revocationTimeUTC *time.Time
+	revocationReason  int
 	issuerID          issuerID
 }
 
@@ -172,9 +173,30 @@ func (b *backend) ocspHandler(ctx context.Context, request *logical.Request, dat
 
 	useUnifiedStorage := canUseUnifiedStorage(request, cfg)
 
+	cacheTTL := cfg.ocspCacheTTL()
+	cacheKey := ocspCacheKey(ocspReq, useUnifiedStorage)
+	if cacheTTL > 0 {
+		if byteResp := b.ocspCache.get(cacheKey); byteResp != nil {
+			return ocspResponse(byteResp), nil
+		}
+	}
+
+	resp := respondToOcspRequest(sc, cfg, ocspReq, useUnifiedStorage)
+	if cacheTTL > 0 && resp.Data[logical.HTTPStatusCode] == http.StatusOK {
+		b.ocspCache.put(cacheKey, resp.Data[logical.HTTPRawBody].([]byte), cacheTTL)
+	}
+
+	return resp, nil
+}
+
+// respondToOcspRequest looks up the status of the requested certificate
+// and builds the signed response, or the appropriate error response.
+func respondToOcspRequest(sc *storageContext, cfg *crlConfig, ocspReq *ocsp.Request, useUnifiedStorage bool) *logical.Response {
+	b := sc.Backend
+
 	ocspStatus, err := getOcspStatus(sc, ocspReq, useUnifiedStorage)
 	if err != nil {
-		return logAndReturnInternalError(b, err), nil
+		return logAndReturnInternalError(b, err)
 	}
 
 	caBundle, issuer, err := lookupOcspIssuer(sc, ocspReq, ocspStatus.issuerID)
@@ -183,30 +205,39 @@ func (b *backend) ocspHandler(ctx context.Context, request *logical.Request, dat
 			// Since we were not able to find a matching issuer for the incoming request
 			// generate an Unknown OCSP response. This might turn into an Unauthorized if
 			// we find out that we don't have a default issuer or it's missing the proper Usage flags
-			return generateUnknownResponse(cfg, sc, ocspReq), nil
+			return generateUnknownResponse(cfg, sc, ocspReq)
 		}
 		if errors.Is(err, ErrMissingOcspUsage) {
 			// If we did find a matching issuer but aren't allowed to sign, the spec says
 			// we should be responding with an Unauthorized response as we don't have the
 			// ability to sign the response.
 			// https://www.rfc-editor.org/rfc/rfc5019#section-2.2.3
-			return OcspUnauthorizedResponse, nil
+			return OcspUnauthorizedResponse
 		}
-		return logAndReturnInternalError(b, err), nil
+		return logAndReturnInternalError(b, err)
 	}
 
-	byteResp, err := genResponse(cfg, caBundle, ocspStatus, ocspReq.HashAlgorithm, issuer.RevocationSigAlg)
+	signer, err := getOcspResponseSigner(sc, cfg, issuer)
 	if err != nil {
-		return logAndReturnInternalError(b, err), nil
+		return logAndReturnInternalError(b, err)
 	}
 
+	byteResp, err := genResponse(cfg, caBundle, signer, ocspStatus, ocspReq.HashAlgorithm, issuer.RevocationSigAlg)
+	if err != nil {
+		return logAndReturnInternalError(b, err)
+	}
+
+	return ocspResponse(byteResp)
+}
+
+func ocspResponse(byteResp []byte) *logical.Response {
 	return &logical.Response{
 		Data: map[string]interface{}{
 			logical.HTTPContentType: ocspResponseContentType,
 			logical.HTTPStatusCode:  http.StatusOK,
 			logical.HTTPRawBody:     byteResp,
 		},
-	}, nil
+	}
 }
 
 func canUseUnifiedStorage(req *logical.Request, cfg *crlConfig) bool {
@@ -257,18 +288,17 @@ func generateUnknownResponse(cfg *crlConfig, sc *storageContext, ocspReq *ocsp.R
 		ocspStatus:   ocsp.Unknown,
 	}
 
-	byteResp, err := genResponse(cfg, caBundle, info, ocspReq.HashAlgorithm, issuer.RevocationSigAlg)
+	signer, err := getOcspResponseSigner(sc, cfg, issuer)
 	if err != nil {
 		return logAndReturnInternalError(sc.Backend, err)
 	}
 
-	return &logical.Response{
-		Data: map[string]interface{}{
-			logical.HTTPContentType: ocspResponseContentType,
-			logical.HTTPStatusCode:  http.StatusOK,
-			logical.HTTPRawBody:     byteResp,
-		},
+	byteResp, err := genResponse(cfg, caBundle, signer, info, ocspReq.HashAlgorithm, issuer.RevocationSigAlg)
+	if err != nil {
+		return logAndReturnInternalError(sc.Backend, err)
 	}
+
+	return ocspResponse(byteResp)
 }
 
 func fetchDerEncodedRequest(request *logical.Request, data *framework.FieldData) ([]byte, error) {
@@ -340,6 +370,7 @@ func getOcspStatus(sc *storageContext, ocspReq *ocsp.Request, useUnifiedStorage
 
 		info.ocspStatus = ocsp.Revoked
 		info.revocationTimeUTC = &revEntry.RevocationTimeUTC
+		info.revocationReason = revEntry.RevocationReason
 		info.issuerID = revEntry.CertificateIssuer // This might be empty if the CRL hasn't been rebuilt
 	} else if useUnifiedStorage {
 		dashSerial := normalizeSerialFromBigInt(ocspReq.SerialNumber)
@@ -351,6 +382,7 @@ func getOcspStatus(sc *storageContext, ocspReq *ocsp.Request, useUnifiedStorage
 		if unifiedEntry != nil {
 			info.ocspStatus = ocsp.Revoked
 			info.revocationTimeUTC = &unifiedEntry.RevocationTimeUTC
+			info.revocationReason = unifiedEntry.RevocationReason
 			info.issuerID = unifiedEntry.CertificateIssuer
 		}
 	}
@@ -415,8 +447,10 @@ func lookupOcspIssuer(sc *storageContext, req *ocsp.Request, optRevokedIssuer is
 	return nil, nil, ErrUnknownIssuer
 }
 
+// getOcspIssuerParsedBundle returns the certificate of the issuer, without
+// its key, which getOcspResponseSigner only loads when it is needed.
 func getOcspIssuerParsedBundle(sc *storageContext, issuerId issuerID) (*certutil.ParsedCertBundle, *issuerEntry, error) {
-	issuer, bundle, err := sc.fetchCertBundleByIssuerId(issuerId, true)
+	issuer, bundle, err := sc.fetchCertBundleByIssuerId(issuerId, false)
 	if err != nil {
 		switch err.(type) {
 		case errutil.UserError:
@@ -473,7 +507,7 @@ func doesRequestMatchIssuer(parsedBundle *certutil.ParsedCertBundle, req *ocsp.R
 	return bytes.Equal(req.IssuerKeyHash, issuerKeyHash) && bytes.Equal(req.IssuerNameHash, issuerNameHash), nil
 }
 
-func genResponse(cfg *crlConfig, caBundle *certutil.ParsedCertBundle, info *ocspRespInfo, reqHash crypto.Hash, revSigAlg x509.SignatureAlgorithm) ([]byte, error) {
+func genResponse(cfg *crlConfig, caBundle *certutil.ParsedCertBundle, signer *certutil.ParsedCertBundle, info *ocspRespInfo, reqHash crypto.Hash, revSigAlg x509.SignatureAlgorithm) ([]byte, error) {
 	curTime := time.Now()
 	duration, err := parseutil.ParseDurationSecond(cfg.OcspExpiry)
 	if err != nil {
@@ -517,10 +551,21 @@ func genResponse(cfg *crlConfig, caBundle *certutil.ParsedCertBundle, info *ocsp
 
 	if info.ocspStatus == ocsp.Revoked {
 		template.RevokedAt = *info.revocationTimeUTC
-		template.RevocationReason = ocsp.Unspecified
+		template.RevocationReason = info.revocationReason
+	}
+
+	// Delegated responders, unlike the issuer, aren't known to the client
+	// and so must be included for it to validate the response.
+	if !bytes.Equal(signer.Certificate.Raw, caBundle.Certificate.Raw) {
+		template.Certificate = signer.Certificate
+
+		// A CRL signer may use another kind of key than the issuer.
+		if signer.Certificate.PublicKeyAlgorithm != caBundle.Certificate.PublicKeyAlgorithm {
+			template.SignatureAlgorithm = x509.UnknownSignatureAlgorithm
+		}
 	}
 
-	return ocsp.CreateResponse(caBundle.Certificate, caBundle.Certificate, template, caBundle.PrivateKey)
+	return ocsp.CreateResponse(caBundle.Certificate, signer.Certificate, template, signer.PrivateKey)
 }
 
 const pathOcspHelpSyn = `
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
 	vaulthttp "github.com/hashicorp/vault/http"
@@ -274,6 +275,31 @@ func TestOcsp_RevokedCertHasIssuerWithoutOcspUsage(t *testing.T) {
 	require.Equal(t, ocsp.UnauthorizedErrorResponse, respDer)
 }
 
+// Verify an issuer restricted to OCSP signing still answers OCSP requests for
+// the certificates it issued, while refusing to issue more.
+func TestOcsp_OcspSigningOnlyIssuer(t *testing.T) {
+	b, s, testEnv := setupOcspEnv(t, "ec")
+
+	resp, err := CBPatch(b, s, "issuer/"+testEnv.issuerId1.String(), map[string]interface{}{
+		"usage": "ocsp-signing-only",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "failed setting usage flags on issuer")
+	require.Equal(t, "ocsp-signing,read-only", resp.Data["usage"])
+
+	_, err = CBWrite(b, s, "issue/test0", map[string]interface{}{
+		"common_name": "test.foobar.com",
+	})
+	require.ErrorContains(t, err, "requested usage issuing-certificates")
+
+	resp, err = SendOcspRequest(t, b, s, "get", testEnv.leafCertIssuer1, testEnv.issuer1, crypto.SHA1)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp get request")
+	require.Equal(t, 200, resp.Data["http_status_code"])
+
+	ocspResp, err := ocsp.ParseResponse(resp.Data["http_raw_body"].([]byte), testEnv.issuer1)
+	require.NoError(t, err, "parsing ocsp get response")
+	require.Equal(t, ocsp.Good, ocspResp.Status)
+}
+
 // Verify if our matching issuer for a revocation entry has no key associated with it that
 // we bail with an Unauthorized response.
 func TestOcsp_RevokedCertHasIssuerWithoutAKey(t *testing.T) {
@@ -511,6 +537,199 @@ func TestOcsp_ValidRequests(t *testing.T) {
 	}
 }
 
+// Verify delegated OCSP responders are issued per issuer, sign responses in
+// place of the issuer and get rotated once revoked.
+func TestOcsp_DelegatedResponder(t *testing.T) {
+	t.Parallel()
+
+	b, s, testEnv := setupOcspEnv(t, "ec")
+
+	// Responders must outlive the responses they sign.
+	resp, err := CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_delegated_responder": true,
+		"ocsp_responder_ttl":       "20h",
+	})
+	require.Error(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_delegated_responder": true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/crl")
+	require.Equal(t, true, resp.Data["ocsp_delegated_responder"])
+	require.Equal(t, "72h", resp.Data["ocsp_responder_ttl"])
+
+	queryResponder := func(leaf, issuer *x509.Certificate) *x509.Certificate {
+		t.Helper()
+
+		resp, err := SendOcspRequest(t, b, s, "post", leaf, issuer, crypto.SHA256)
+		requireSuccessNonNilResponse(t, resp, err, "ocsp post request")
+		require.Equal(t, 200, resp.Data["http_status_code"])
+
+		// Parsing with the issuer verifies the embedded responder was
+		// issued by it, and the response was signed by the responder.
+		ocspResp, err := ocsp.ParseResponse(resp.Data["http_raw_body"].([]byte), issuer)
+		require.NoError(t, err, "parsing ocsp response")
+		require.Equal(t, ocsp.Good, ocspResp.Status)
+		require.NotNil(t, ocspResp.Certificate, "expected a delegated responder certificate in the response")
+		require.Error(t, ocspResp.CheckSignatureFrom(issuer), "response should not be signed by the issuer")
+		requireOcspResponseSignedBy(t, ocspResp, ocspResp.Certificate)
+
+		responder := ocspResp.Certificate
+		require.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageOCSPSigning}, responder.ExtKeyUsage)
+		require.False(t, responder.IsCA)
+		require.NoError(t, responder.CheckSignatureFrom(issuer))
+		require.True(t, responder.NotAfter.Sub(responder.NotBefore) <= 72*time.Hour+time.Minute)
+
+		var hasNoCheck bool
+		for _, ext := range responder.Extensions {
+			hasNoCheck = hasNoCheck || ext.Id.Equal(ocspNoCheckOID)
+		}
+		require.True(t, hasNoCheck, "expected the id-pkix-ocsp-nocheck extension")
+
+		return responder
+	}
+
+	responder1 := queryResponder(testEnv.leafCertIssuer1, testEnv.issuer1)
+	responder2 := queryResponder(testEnv.leafCertIssuer2, testEnv.issuer2)
+	require.NotEqual(t, responder1.SerialNumber, responder2.SerialNumber)
+
+	// Responders are tracked like any other certificate.
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(responder1))
+	requireSuccessNonNilResponse(t, resp, err, "cert/"+serialFromCert(responder1))
+
+	// Periodic rotation leaves current responders alone...
+	sc := b.makeStorageContext(context.Background(), s)
+	cfg, err := sc.getRevocationConfig()
+	require.NoError(t, err)
+	require.NoError(t, rotateOcspResponders(sc, cfg, false))
+	require.Equal(t, responder1.SerialNumber, queryResponder(testEnv.leafCertIssuer1, testEnv.issuer1).SerialNumber)
+
+	// ...but replaces revoked ones.
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(responder1),
+	})
+	require.NoError(t, err)
+	require.NoError(t, rotateOcspResponders(sc, cfg, false))
+	require.NotEqual(t, responder1.SerialNumber, queryResponder(testEnv.leafCertIssuer1, testEnv.issuer1).SerialNumber)
+	require.Equal(t, responder2.SerialNumber, queryResponder(testEnv.leafCertIssuer2, testEnv.issuer2).SerialNumber)
+
+	// Without a valid responder, requests fail rather than being signed
+	// with the issuer's key...
+	require.NoError(t, s.Delete(context.Background(), ocspResponderPrefix+testEnv.issuerId1.String()))
+	resp, err = SendOcspRequest(t, b, s, "post", testEnv.leafCertIssuer1, testEnv.issuer1, crypto.SHA256)
+	require.NoError(t, err)
+	require.Equal(t, 500, resp.Data["http_status_code"])
+
+	// ...unless the fallback to the issuer is enabled.
+	resp, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_responder_fallback": true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/crl")
+	require.Equal(t, true, resp.Data["ocsp_responder_fallback"])
+
+	resp, err = SendOcspRequest(t, b, s, "post", testEnv.leafCertIssuer1, testEnv.issuer1, crypto.SHA256)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp post request")
+	require.Equal(t, 200, resp.Data["http_status_code"])
+	ocspResp, err := ocsp.ParseResponse(resp.Data["http_raw_body"].([]byte), testEnv.issuer1)
+	require.NoError(t, err)
+	require.Nil(t, ocspResp.Certificate)
+	requireOcspResponseSignedBy(t, ocspResp, testEnv.issuer1)
+
+	// Once disabled, the issuer signs responses itself again.
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_delegated_responder": false,
+	})
+	require.NoError(t, err)
+
+	resp, err = SendOcspRequest(t, b, s, "post", testEnv.leafCertIssuer1, testEnv.issuer1, crypto.SHA256)
+	requireSuccessNonNilResponse(t, resp, err, "ocsp post request")
+	ocspResp, err = ocsp.ParseResponse(resp.Data["http_raw_body"].([]byte), testEnv.issuer1)
+	require.NoError(t, err)
+	require.Nil(t, ocspResp.Certificate)
+	requireOcspResponseSignedBy(t, ocspResp, testEnv.issuer1)
+}
+
+func TestOcsp_ResponseCache(t *testing.T) {
+	t.Parallel()
+
+	b, s, testEnv := setupOcspEnv(t, "ec")
+
+	// Pre-generation has nothing to store responses in without a cache.
+	resp, err := CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_pregenerate": true,
+	})
+	require.Error(t, err)
+	require.True(t, resp.IsError())
+
+	resp, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_cache_ttl": "10m",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/crl")
+	require.Equal(t, "10m", resp.Data["ocsp_cache_ttl"])
+	require.Equal(t, false, resp.Data["ocsp_pregenerate"])
+
+	queryStatus := func(leaf, issuer *x509.Certificate) []byte {
+		t.Helper()
+
+		resp, err := SendOcspRequest(t, b, s, "post", leaf, issuer, crypto.SHA1)
+		requireSuccessNonNilResponse(t, resp, err, "ocsp post request")
+		require.Equal(t, 200, resp.Data["http_status_code"])
+		return resp.Data["http_raw_body"].([]byte)
+	}
+
+	// Repeated requests are served the same, cached, response.
+	first := queryStatus(testEnv.leafCertIssuer1, testEnv.issuer1)
+	require.Equal(t, 1, b.ocspCache.cache.Len())
+	time.Sleep(1100 * time.Millisecond)
+	require.Equal(t, first, queryStatus(testEnv.leafCertIssuer1, testEnv.issuer1))
+
+	ocspResp, err := ocsp.ParseResponse(first, testEnv.issuer1)
+	require.NoError(t, err)
+	require.Equal(t, ocsp.Good, ocspResp.Status)
+
+	// Revocation purges the cache, so it is reflected right away.
+	_, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": serialFromCert(testEnv.leafCertIssuer1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, b.ocspCache.cache.Len())
+
+	ocspResp, err = ocsp.ParseResponse(queryStatus(testEnv.leafCertIssuer1, testEnv.issuer1), testEnv.issuer1)
+	require.NoError(t, err)
+	require.Equal(t, ocsp.Revoked, ocspResp.Status)
+
+	// With pre-generation, the response for a new certificate is cached on
+	// issuance.
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_pregenerate": true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, b.ocspCache.cache.Len())
+
+	resp, err = CBWrite(b, s, "issue/test0", map[string]interface{}{
+		"common_name": "pregenerated.foobar.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test0")
+	leaf := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, 1, b.ocspCache.cache.Len())
+
+	ocspResp, err = ocsp.ParseResponse(queryStatus(leaf, testEnv.issuer1), testEnv.issuer1)
+	require.NoError(t, err)
+	require.Equal(t, ocsp.Good, ocspResp.Status)
+	require.Equal(t, 0, leaf.SerialNumber.Cmp(ocspResp.SerialNumber))
+	require.Equal(t, 1, b.ocspCache.cache.Len(), "expected the pre-generated response to be served")
+
+	// Disabling the cache stops caching altogether.
+	_, err = CBWrite(b, s, "config/crl", map[string]interface{}{
+		"ocsp_cache_ttl":   "0s",
+		"ocsp_pregenerate": false,
+	})
+	require.NoError(t, err)
+	queryStatus(testEnv.leafCertIssuer2, testEnv.issuer2)
+	require.Equal(t, 0, b.ocspCache.cache.Len())
+}
+
 func runOcspRequestTest(t *testing.T, requestType string, caKeyType string, caKeyBits int, caKeySigBits int, requestHash crypto.Hash) {
 	b, s, testEnv := setupOcspEnvWithCaKeyConfig(t, caKeyType, caKeyBits, caKeySigBits)
 
@@ -0,0 +1,478 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// Certificate profiles hold the key usages, extensions and validity rules
+// of issued certificates, for many roles to share. Every field set on a
+// profile replaces the corresponding field of the roles referencing it.
+type profileEntry struct {
+	KeyUsage          []string          `json:"key_usage"`
+	ExtKeyUsage       []string          `json:"ext_key_usage"`
+	ExtKeyUsageOIDs   []string          `json:"ext_key_usage_oids"`
+	PolicyIdentifiers []string          `json:"policy_identifiers"`
+	CustomExtensions  []customExtension `json:"custom_extensions"`
+	TTL               time.Duration     `json:"ttl"`
+	MaxTTL            time.Duration     `json:"max_ttl"`
+	NotBeforeDuration time.Duration     `json:"not_before_duration"`
+	// EnforceMaxTTL also bounds requested not_after dates by MaxTTL
+	EnforceMaxTTL bool `json:"enforce_max_ttl"`
+	// RequireKeyAttestation requires CSRs to come with an attestation of
+	// their key, chaining to KeyAttestationRoots
+	RequireKeyAttestation bool   `json:"require_key_attestation"`
+	KeyAttestationRoots   string `json:"key_attestation_roots"`
+}
+
+func (p *profileEntry) ToResponseData() map[string]interface{} {
+	return map[string]interface{}{
+		"key_usage":               p.KeyUsage,
+		"ext_key_usage":           p.ExtKeyUsage,
+		"ext_key_usage_oids":      p.ExtKeyUsageOIDs,
+		"policy_identifiers":      p.PolicyIdentifiers,
+		"custom_extensions":       p.CustomExtensions,
+		"ttl":                     int64(p.TTL.Seconds()),
+		"max_ttl":                 int64(p.MaxTTL.Seconds()),
+		"not_before_duration":     int64(p.NotBeforeDuration.Seconds()),
+		"enforce_max_ttl":         p.EnforceMaxTTL,
+		"require_key_attestation": p.RequireKeyAttestation,
+		"key_attestation_roots":   p.KeyAttestationRoots,
+	}
+}
+
+// applyTo replaces the fields of role set on the profile.
+func (p *profileEntry) applyTo(role *roleEntry) {
+	if len(p.KeyUsage) > 0 {
+		role.KeyUsage = p.KeyUsage
+	}
+	if len(p.ExtKeyUsage) > 0 {
+		role.ExtKeyUsage = p.ExtKeyUsage
+		// The role's flags add to ExtKeyUsage; clear them so only the
+		// profile's extended key usages are used.
+		role.ServerFlag = false
+		role.ClientFlag = false
+		role.CodeSigningFlag = false
+		role.EmailProtectionFlag = false
+	}
+	if len(p.ExtKeyUsageOIDs) > 0 {
+		role.ExtKeyUsageOIDs = p.ExtKeyUsageOIDs
+	}
+	if len(p.PolicyIdentifiers) > 0 {
+		role.PolicyIdentifiers = p.PolicyIdentifiers
+	}
+	if len(p.CustomExtensions) > 0 {
+		role.CustomExtensions = p.CustomExtensions
+	}
+	if p.TTL > 0 {
+		role.TTL = p.TTL
+	}
+	if p.MaxTTL > 0 {
+		role.MaxTTL = p.MaxTTL
+	}
+	if p.NotBeforeDuration > 0 {
+		role.NotBeforeDuration = p.NotBeforeDuration
+	}
+	if p.EnforceMaxTTL {
+		role.EnforceMaxTTL = true
+	}
+	if p.RequireKeyAttestation {
+		role.RequireKeyAttestation = true
+		role.KeyAttestationRoots = p.KeyAttestationRoots
+	}
+}
+
+func pathListProfiles(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "profiles/?$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "profiles",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ListOperation: &framework.PathOperation{
+				Callback: b.pathProfileList,
+			},
+		},
+
+		HelpSynopsis:    pathListProfilesHelpSyn,
+		HelpDescription: pathListProfilesHelpDesc,
+	}
+}
+
+func pathProfiles(b *backend) *framework.Path {
+	pathProfilesResponseFields := map[string]*framework.FieldSchema{
+		"key_usage": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `Key usages of issued certificates`,
+			Required:    true,
+		},
+		"ext_key_usage": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `Extended key usages of issued certificates`,
+			Required:    true,
+		},
+		"ext_key_usage_oids": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `Extended key usage OIDs of issued certificates`,
+			Required:    true,
+		},
+		"policy_identifiers": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `Policy identifiers of issued certificates`,
+			Required:    true,
+		},
+		"custom_extensions": {
+			Type:        framework.TypeSlice,
+			Description: `Additional X.509 extensions of issued certificates`,
+			Required:    true,
+		},
+		"ttl": {
+			Type:        framework.TypeInt64,
+			Description: `Default validity period of issued certificates`,
+			Required:    true,
+		},
+		"max_ttl": {
+			Type:        framework.TypeInt64,
+			Description: `Maximum validity period of issued certificates`,
+			Required:    true,
+		},
+		"not_before_duration": {
+			Type:        framework.TypeInt64,
+			Description: `Duration by which to backdate the NotBefore of issued certificates`,
+			Required:    true,
+		},
+		"enforce_max_ttl": {
+			Type:        framework.TypeBool,
+			Description: `Whether max_ttl also bounds requested not_after dates`,
+			Required:    true,
+		},
+		"require_key_attestation": {
+			Type:        framework.TypeBool,
+			Description: `Whether CSRs must come with an attestation of their key`,
+			Required:    true,
+		},
+		"key_attestation_roots": {
+			Type:        framework.TypeString,
+			Description: `Root certificates key attestations must chain to`,
+			Required:    true,
+		},
+	}
+
+	return &framework.Path{
+		Pattern: "profiles/" + framework.GenericNameRegex("name"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "profile",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: "Name of the profile",
+			},
+
+			"key_usage": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `A comma-separated string or list of key usages (not extended
+key usages) replacing those of roles referencing this profile. Valid values
+are those of the role's key_usage parameter.`,
+			},
+
+			"ext_key_usage": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `A comma-separated string or list of extended key usages
+replacing those of roles referencing this profile, including the server_flag,
+client_flag, code_signing_flag and email_protection_flag of the role. Valid
+values are those of the role's ext_key_usage parameter.`,
+			},
+
+			"ext_key_usage_oids": {
+				Type:        framework.TypeCommaStringSlice,
+				Description: `A comma-separated string or list of extended key usage oids replacing those of roles referencing this profile.`,
+			},
+
+			"policy_identifiers": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `A comma-separated string or list of policy OIDs, or a JSON
+list of qualified policy information, replacing those of roles referencing
+this profile. The format is that of the role's policy_identifiers parameter.`,
+			},
+
+			"custom_extensions": {
+				Type: framework.TypeSlice,
+				Description: `List of additional X.509 extensions replacing those of
+roles referencing this profile. The format is that of the role's
+custom_extensions parameter.`,
+			},
+
+			"ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: `The default validity period of certificates issued by roles referencing this profile.`,
+			},
+
+			"max_ttl": {
+				Type:        framework.TypeDurationSecond,
+				Description: `The maximum validity period of certificates issued by roles referencing this profile.`,
+			},
+
+			"not_before_duration": {
+				Type:        framework.TypeDurationSecond,
+				Description: `The duration by which to backdate the NotBefore of certificates issued by roles referencing this profile.`,
+			},
+
+			"enforce_max_ttl": {
+				Type: framework.TypeBool,
+				Description: `If set, requests for a not_after date beyond the max_ttl
+of the profile (or of the role, if the profile sets none) are rejected rather
+than honored, making max_ttl a hard cap on the lifetime of certificates issued
+by roles referencing this profile.`,
+			},
+
+			"require_key_attestation": {
+				Type: framework.TypeBool,
+				Description: `If set, roles referencing this profile only sign CSRs
+submitted with a key_attestation, proving their key was generated and is held
+by hardware such as a smart card or HSM, and refuse to generate keys on the
+issue endpoints. Requires key_attestation_roots.`,
+			},
+
+			"key_attestation_roots": {
+				Type: framework.TypeString,
+				Description: `PEM bundle of the root certificates of the hardware
+vendors trusted to attest keys, such as the Yubico PIV attestation root.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathProfileRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      pathProfilesResponseFields,
+					}},
+				},
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathProfileWrite,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      pathProfilesResponseFields,
+					}},
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathProfileDelete,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+				// Read more about why these flags are set in backend.go.
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathProfileHelpSyn,
+		HelpDescription: pathProfileHelpDesc,
+	}
+}
+
+func (b *backend) getProfile(ctx context.Context, s logical.Storage, name string) (*profileEntry, error) {
+	entry, err := s.Get(ctx, "profile/"+name)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var result profileEntry
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// getRoleWithProfile fetches the role, with the profile it references, if
+// any, applied. Roles used for issuance must be fetched this way; roles
+// fetched to be read or modified must not.
+func (b *backend) getRoleWithProfile(ctx context.Context, s logical.Storage, name string) (*roleEntry, error) {
+	role, err := b.getRole(ctx, s, name)
+	if err != nil || role == nil || role.Profile == "" {
+		return role, err
+	}
+
+	profile, err := b.getProfile(ctx, s, role.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading profile %v of role %v: %w", role.Profile, name, err)
+	}
+	if profile == nil {
+		return nil, errutil.UserError{Err: fmt.Sprintf("role %v references unknown profile %v", name, role.Profile)}
+	}
+
+	profile.applyTo(role)
+	return role, nil
+}
+
+// validateRoleProfile ensures the profile referenced by a role exists.
+func (b *backend) validateRoleProfile(ctx context.Context, s logical.Storage, name string) (*logical.Response, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, err := b.getProfile(ctx, s, name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown profile %v", name)), nil
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathProfileList(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	entries, err := req.Storage.List(ctx, "profile/")
+	if err != nil {
+		return nil, err
+	}
+
+	return logical.ListResponse(entries), nil
+}
+
+func (b *backend) pathProfileRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	profile, err := b.getProfile(ctx, req.Storage, data.Get("name").(string))
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, nil
+	}
+
+	return &logical.Response{
+		Data: profile.ToResponseData(),
+	}, nil
+}
+
+func (b *backend) pathProfileWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	entry := &profileEntry{
+		KeyUsage:              data.Get("key_usage").([]string),
+		ExtKeyUsage:           data.Get("ext_key_usage").([]string),
+		ExtKeyUsageOIDs:       data.Get("ext_key_usage_oids").([]string),
+		PolicyIdentifiers:     getPolicyIdentifier(data, nil),
+		TTL:                   time.Duration(data.Get("ttl").(int)) * time.Second,
+		MaxTTL:                time.Duration(data.Get("max_ttl").(int)) * time.Second,
+		NotBeforeDuration:     time.Duration(data.Get("not_before_duration").(int)) * time.Second,
+		EnforceMaxTTL:         data.Get("enforce_max_ttl").(bool),
+		RequireKeyAttestation: data.Get("require_key_attestation").(bool),
+		KeyAttestationRoots:   data.Get("key_attestation_roots").(string),
+	}
+
+	var err error
+	entry.CustomExtensions, err = getCustomExtensions(data, []customExtension{})
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if entry.MaxTTL > 0 && entry.TTL > entry.MaxTTL {
+		return logical.ErrorResponse(`"ttl" value must be less than "max_ttl" value`), nil
+	}
+
+	for _, oidstr := range entry.ExtKeyUsageOIDs {
+		if _, err := certutil.StringToOid(oidstr); err != nil {
+			return logical.ErrorResponse(fmt.Sprintf("%q could not be parsed as a valid oid for an extended key usage", oidstr)), nil
+		}
+	}
+
+	if err := validatePolicyIdentifiers(entry.PolicyIdentifiers); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if entry.RequireKeyAttestation && entry.KeyAttestationRoots == "" {
+		return logical.ErrorResponse("key_attestation_roots is required when require_key_attestation is set"), nil
+	}
+	if _, err := parseKeyAttestationRoots(entry.KeyAttestationRoots); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("invalid key_attestation_roots: %v", err)), nil
+	}
+
+	jsonEntry, err := logical.StorageEntryJSON("profile/"+name, entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.Storage.Put(ctx, jsonEntry); err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: entry.ToResponseData(),
+	}, nil
+}
+
+func (b *backend) pathProfileDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	name := data.Get("name").(string)
+
+	roleNames, err := req.Storage.List(ctx, "role/")
+	if err != nil {
+		return nil, err
+	}
+
+	var referencing []string
+	for _, roleName := range roleNames {
+		role, err := b.getRole(ctx, req.Storage, roleName)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil && role.Profile == name {
+			referencing = append(referencing, roleName)
+		}
+	}
+	if len(referencing) > 0 {
+		sort.Strings(referencing)
+		return logical.ErrorResponse(fmt.Sprintf("profile %v is referenced by roles: %v", name, strings.Join(referencing, ", "))), nil
+	}
+
+	if err := req.Storage.Delete(ctx, "profile/"+name); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+const pathListProfilesHelpSyn = `List the existing certificate profiles in this backend`
+
+const pathListProfilesHelpDesc = `Profiles will be listed by the profile name.`
+
+const pathProfileHelpSyn = `Manage the certificate profiles roles can reference.`
+
+const pathProfileHelpDesc = `
+This path lets you manage certificate profiles: the key usages, extended key
+usages, extensions and validity rules of issued certificates, shared by the
+roles referencing the profile through their profile parameter. Every field
+set on a profile replaces the corresponding field of those roles when they
+issue certificates, so updating a profile updates all of them at once.
+`
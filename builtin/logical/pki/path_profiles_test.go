@@ -0,0 +1,254 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_Profiles(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "profiles/tls", map[string]interface{}{
+		"ttl":     "2h",
+		"max_ttl": "1h",
+	})
+	require.ErrorContains(t, err, `"ttl" value must be less than "max_ttl" value`)
+
+	resp, err = CBWrite(b, s, "profiles/tls", map[string]interface{}{
+		"key_usage":     "DigitalSignature",
+		"ext_key_usage": "ServerAuth",
+		"ttl":           "30m",
+		"max_ttl":       "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "profiles/tls")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("profiles/tls"), logical.UpdateOperation), resp, true)
+
+	resp, err = CBRead(b, s, "profiles/tls")
+	requireSuccessNonNilResponse(t, resp, err, "profiles/tls")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("profiles/tls"), logical.ReadOperation), resp, true)
+	require.Equal(t, []string{"DigitalSignature"}, resp.Data["key_usage"])
+	require.Equal(t, []string{"ServerAuth"}, resp.Data["ext_key_usage"])
+	require.Equal(t, int64(1800), resp.Data["ttl"])
+
+	resp, err = CBList(b, s, "profiles")
+	requireSuccessNonNilResponse(t, resp, err, "profiles")
+	require.Equal(t, []string{"tls"}, resp.Data["keys"])
+
+	_, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"profile":          "unknown",
+	})
+	require.ErrorContains(t, err, "unknown profile unknown")
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+		"client_flag":      true,
+		"ttl":              "10h",
+		"max_ttl":          "20h",
+		"profile":          "tls",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	// Roles are read without the profile applied.
+	resp, err = CBRead(b, s, "roles/test")
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+	require.Equal(t, "tls", resp.Data["profile"])
+	require.Equal(t, true, resp.Data["client_flag"])
+	require.Equal(t, int64(36000), resp.Data["ttl"])
+
+	issue := func() *x509.Certificate {
+		resp, err := CBWrite(b, s, "issue/test", map[string]interface{}{
+			"common_name": "www.example.com",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/test")
+		return parseCert(t, resp.Data["certificate"].(string))
+	}
+
+	cert := issue()
+	require.Equal(t, x509.KeyUsageDigitalSignature, cert.KeyUsage)
+	require.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, cert.ExtKeyUsage)
+	require.InDelta(t, (30 * time.Minute).Seconds(), cert.NotAfter.Sub(cert.NotBefore).Seconds(), 60)
+
+	// Updating the profile updates every role referencing it.
+	resp, err = CBWrite(b, s, "profiles/tls", map[string]interface{}{
+		"ext_key_usage": "ServerAuth,ClientAuth",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "profiles/tls")
+
+	cert = issue()
+	require.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}, cert.ExtKeyUsage)
+	require.InDelta(t, (10 * time.Hour).Seconds(), cert.NotAfter.Sub(cert.NotBefore).Seconds(), 60)
+
+	_, err = CBDelete(b, s, "profiles/tls")
+	require.ErrorContains(t, err, "profile tls is referenced by roles: test")
+
+	resp, err = CBPatch(b, s, "roles/test", map[string]interface{}{
+		"profile": "",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+	_, err = CBDelete(b, s, "profiles/tls")
+	require.NoError(t, err)
+
+	resp, err = CBRead(b, s, "profiles/tls")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestPki_CodeSigningProfile(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	// A hardware vendor's attestation root, and the attestation of a key
+	// held by one of its devices.
+	vendorKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	vendorTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Vendor Attestation Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	vendorDer, err := x509.CreateCertificate(rand.Reader, vendorTemplate, vendorTemplate, vendorKey.Public(), vendorKey)
+	require.NoError(t, err)
+	vendorCert, err := x509.ParseCertificate(vendorDer)
+	require.NoError(t, err)
+	vendorPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: vendorDer}))
+
+	attest := func(key *ecdsa.PrivateKey) string {
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "Device Key Attestation"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(24 * time.Hour),
+		}, vendorCert, key.Public(), vendorKey)
+		require.NoError(t, err)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+
+	_, err = CBWrite(b, s, "profiles/code-signing", map[string]interface{}{
+		"require_key_attestation": true,
+	})
+	require.ErrorContains(t, err, "key_attestation_roots is required")
+
+	resp, err = CBWrite(b, s, "profiles/code-signing", map[string]interface{}{
+		"key_usage":               "DigitalSignature",
+		"ext_key_usage":           "CodeSigning",
+		"max_ttl":                 "24h",
+		"enforce_max_ttl":         true,
+		"require_key_attestation": true,
+		"key_attestation_roots":   vendorPem,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "profiles/code-signing")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("profiles/code-signing"), logical.UpdateOperation), resp, true)
+	require.Equal(t, true, resp.Data["require_key_attestation"])
+
+	resp, err = CBWrite(b, s, "roles/signer", map[string]interface{}{
+		"allow_any_name":    true,
+		"enforce_hostnames": false,
+		"key_type":          "ec",
+		"server_flag":       true,
+		"profile":           "code-signing",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/signer")
+
+	// Keys generated by Vault can't be attested.
+	_, err = CBWrite(b, s, "issue/signer", map[string]interface{}{
+		"common_name": "Release Signing",
+	})
+	require.ErrorContains(t, err, "role requires keys to be attested")
+
+	deviceKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	csrDer, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "Release Signing"},
+	}, deviceKey)
+	require.NoError(t, err)
+	csrPem := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csrDer}))
+
+	_, err = CBWrite(b, s, "sign/signer", map[string]interface{}{
+		"csr": csrPem,
+	})
+	require.ErrorContains(t, err, "role requires a key_attestation")
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = CBWrite(b, s, "sign/signer", map[string]interface{}{
+		"csr":             csrPem,
+		"key_attestation": attest(otherKey),
+	})
+	require.ErrorContains(t, err, "does not attest the CSR's key")
+
+	// Attestations by an untrusted vendor are refused.
+	_, err = CBWrite(b, s, "sign/signer", map[string]interface{}{
+		"csr":             csrPem,
+		"key_attestation": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: selfSignedDer(t, deviceKey)})),
+	})
+	require.ErrorContains(t, err, "key_attestation is not trusted")
+
+	// The profile's max_ttl can't be exceeded through not_after.
+	_, err = CBWrite(b, s, "sign/signer", map[string]interface{}{
+		"csr":             csrPem,
+		"key_attestation": attest(deviceKey),
+		"not_after":       time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339),
+	})
+	require.ErrorContains(t, err, "beyond the role's maximum TTL")
+
+	resp, err = CBWrite(b, s, "sign/signer", map[string]interface{}{
+		"csr":             csrPem,
+		"key_attestation": attest(deviceKey),
+		"ttl":             "72h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign/signer")
+	cert := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning}, cert.ExtKeyUsage)
+	require.InDelta(t, (24 * time.Hour).Seconds(), cert.NotAfter.Sub(cert.NotBefore).Seconds(), 60)
+}
+
+func selfSignedDer(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "Self-Attested"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	return der
+}
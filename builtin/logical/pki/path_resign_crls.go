@@ -649,7 +649,7 @@ func getCaBundle(sc *storageContext, issuerRef string) (*certutil.CAInfoBundle,
 		return nil, fmt.Errorf("failed to resolve issuer %s: %w", issuerRefParam, err)
 	}
 
-	return sc.fetchCAInfoByIssuerId(issuerId, CRLSigningUsage)
+	return sc.fetchCRLSigningBundle(issuerId)
 }
 
 func decodePemCrls(rawCrls []string) ([]*x509.RevocationList, error) {
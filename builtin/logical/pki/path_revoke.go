@@ -11,6 +11,7 @@ import (
 	"crypto/rsa"
 	"crypto/subtle"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"net/http"
@@ -85,6 +86,13 @@ hyphen-separated octal`,
 				Description: `Certificate to revoke in PEM format; must be
 signed by an issuer in this mount.`,
 			},
+			"revocation_reason": {
+				Type: framework.TypeString,
+				Description: `RFC 5280 reason for the revocation, by name
+(such as keyCompromise or superseded) or code, recorded in CRLs and OCSP
+responses. Defaults to unspecified; certificateHold and removeFromCRL are
+not supported.`,
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -108,6 +116,11 @@ signed by an issuer in this mount.`,
 								Description: `Revocation Time`,
 								Required:    false,
 							},
+							"revocation_reason": {
+								Type:        framework.TypeString,
+								Description: `Revocation Reason`,
+								Required:    false,
+							},
 							"state": {
 								Type:        framework.TypeString,
 								Description: `Revocation State`,
@@ -144,11 +157,26 @@ hyphen-separated octal`,
 				Type: framework.TypeString,
 				Description: `Certificate to revoke in PEM format; must be
 signed by an issuer in this mount.`,
+			},
+			"revocation_reason": {
+				Type: framework.TypeString,
+				Description: `RFC 5280 reason for the revocation, by name
+(such as keyCompromise or superseded) or code, recorded in CRLs and OCSP
+responses. Defaults to unspecified; certificateHold and removeFromCRL are
+not supported.`,
 			},
 			"private_key": {
 				Type: framework.TypeString,
 				Description: `Key to use to verify revocation permission; must
-be in PEM format.`,
+be in PEM format. Mutually exclusive with signature.`,
+			},
+			"signature": {
+				Type: framework.TypeString,
+				Description: `Base64-encoded signature, by the certificate's
+private key, of the challenge "revoke-with-key:" followed by the
+lowercase, colon-separated serial number of the certificate; proves
+possession of the key without sending it. Uses SHA-256, with PKCS#1 v1.5
+or PSS padding for RSA keys. Mutually exclusive with private_key.`,
 			},
 		},
 
@@ -173,6 +201,11 @@ be in PEM format.`,
 								Description: `Revocation Time`,
 								Required:    false,
 							},
+							"revocation_reason": {
+								Type:        framework.TypeString,
+								Description: `Revocation Reason`,
+								Required:    false,
+							},
 							"state": {
 								Type:        framework.TypeString,
 								Description: `Revocation State`,
@@ -184,8 +217,8 @@ be in PEM format.`,
 			},
 		},
 
-		HelpSynopsis:    pathRevokeHelpSyn,
-		HelpDescription: pathRevokeHelpDesc,
+		HelpSynopsis:    pathRevokeWithKeyHelpSyn,
+		HelpDescription: pathRevokeWithKeyHelpDesc,
 	}
 }
 
@@ -410,7 +443,11 @@ func (b *backend) pathRevokeWriteHandleCertificate(ctx context.Context, req *log
 	return serial, false, nil, errutil.UserError{Err: "unable to verify signature on presented cert from any present issuer in this mount; certificates from previous CAs will need to have their issuing CA and key re-imported if revocation is necessary"}
 }
 
-func (b *backend) pathRevokeWriteHandleKey(req *logical.Request, certReference *x509.Certificate, keyPem string) error {
+func (b *backend) pathRevokeWriteHandleKey(req *logical.Request, certReference *x509.Certificate, keyPem string, signature []byte) error {
+	if len(signature) > 0 {
+		return validateRevocationSignature(certReference, signature)
+	}
+
 	if keyPem == "" {
 		// The only way to get here should be via the /revoke endpoint;
 		// validate the path one more time and return an error if necessary.
@@ -438,6 +475,35 @@ func (b *backend) pathRevokeWriteHandleKey(req *logical.Request, certReference *
 	return validatePrivateKeyMatchesCert(signer, certReference)
 }
 
+// revocationChallenge is the message a holder signs to revoke their
+// certificate via the /revoke-with-key path without sending its key.
+func revocationChallenge(certReference *x509.Certificate) string {
+	return "revoke-with-key:" + serialFromCert(certReference)
+}
+
+func validateRevocationSignature(certReference *x509.Certificate, signature []byte) error {
+	var algorithms []x509.SignatureAlgorithm
+	switch certReference.PublicKey.(type) {
+	case *rsa.PublicKey:
+		algorithms = []x509.SignatureAlgorithm{x509.SHA256WithRSA, x509.SHA256WithRSAPSS}
+	case *ecdsa.PublicKey:
+		algorithms = []x509.SignatureAlgorithm{x509.ECDSAWithSHA256}
+	case ed25519.PublicKey:
+		algorithms = []x509.SignatureAlgorithm{x509.PureEd25519}
+	default:
+		return errutil.UserError{Err: "certificate has an unknown public key algorithm; unable to validate provided signature; ask an admin to revoke this certificate instead"}
+	}
+
+	challenge := []byte(revocationChallenge(certReference))
+	for _, algorithm := range algorithms {
+		if err := certReference.CheckSignature(algorithm, challenge, signature); err == nil {
+			return nil
+		}
+	}
+
+	return errutil.UserError{Err: "provided signature does not verify against the certificate's public key"}
+}
+
 func validatePrivateKeyMatchesCert(signer crypto.Signer, certReference *x509.Certificate) error {
 	public := signer.Public()
 
@@ -497,7 +563,7 @@ func validatePublicKeyMatchesCert(verifier crypto.PublicKey, certReference *x509
 	return nil
 }
 
-func (b *backend) maybeRevokeCrossCluster(sc *storageContext, config *crlConfig, serial string, havePrivateKey bool) (*logical.Response, error) {
+func (b *backend) maybeRevokeCrossCluster(sc *storageContext, config *crlConfig, serial string, havePrivateKey bool, reason int) (*logical.Response, error) {
 	if !config.UseGlobalQueue {
 		return logical.ErrorResponse(fmt.Sprintf("certificate with serial %s not found.", serial)), nil
 	}
@@ -512,7 +578,8 @@ func (b *backend) maybeRevokeCrossCluster(sc *storageContext, config *crlConfig,
 	currTime := time.Now()
 	nSerial := normalizeSerial(serial)
 	queueReq := revocationRequest{
-		RequestedAt: currTime,
+		RequestedAt:      currTime,
+		RevocationReason: reason,
 	}
 	path := crossRevocationPath + nSerial
 
@@ -545,15 +612,32 @@ func (b *backend) pathRevokeWrite(ctx context.Context, req *logical.Request, dat
 		return logical.ErrorResponse("Must provide either the certificate or the serial to revoke; not both."), nil
 	}
 
+	reason, err := parseRevocationReason(data.Get("revocation_reason").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	var keyPem string
+	var signature []byte
 	if req.Path == "revoke-with-key" {
 		rawKey, haveKey := data.GetOk("private_key")
-		if !haveKey {
-			return logical.ErrorResponse("Must have private key to revoke via the /revoke-with-key path."), nil
+		rawSignature, haveSignature := data.GetOk("signature")
+		if !haveKey && !haveSignature {
+			return logical.ErrorResponse("Must have private key or signature to revoke via the /revoke-with-key path."), nil
+		} else if haveKey && haveSignature {
+			return logical.ErrorResponse("Must provide either the private key or the signature; not both."), nil
+		}
+
+		if haveSignature {
+			var err error
+			signature, err = base64.StdEncoding.DecodeString(rawSignature.(string))
+			if err != nil || len(signature) == 0 {
+				return logical.ErrorResponse("Provided signature must be non-empty and base64 encoded."), nil
+			}
 		}
 
-		keyPem = rawKey.(string)
-		if len(keyPem) < 64 {
+		keyPem, _ = rawKey.(string)
+		if haveKey && len(keyPem) < 64 {
 			// See note in pathImportKeyHandler...
 			return logical.ErrorResponse("Provided data for private_key was too short; perhaps a path was passed to the API rather than the contents of a PEM file?"), nil
 		}
@@ -617,13 +701,13 @@ func (b *backend) pathRevokeWrite(ctx context.Context, req *logical.Request, dat
 			}
 		}
 
-		return b.maybeRevokeCrossCluster(sc, config, serial, keyPem != "")
+		return b.maybeRevokeCrossCluster(sc, config, serial, keyPem != "" || len(signature) > 0, reason)
 	}
 
 	// Before we write the certificate, we've gotta verify the request in
 	// the event of a PoP-based revocation scheme; we don't want to litter
 	// storage with issued-but-not-revoked certificates.
-	if err := b.pathRevokeWriteHandleKey(req, cert, keyPem); err != nil {
+	if err := b.pathRevokeWriteHandleKey(req, cert, keyPem, signature); err != nil {
 		return nil, err
 	}
 
@@ -650,7 +734,7 @@ func (b *backend) pathRevokeWrite(ctx context.Context, req *logical.Request, dat
 	b.revokeStorageLock.Lock()
 	defer b.revokeStorageLock.Unlock()
 
-	return revokeCert(sc, config, cert)
+	return revokeCert(sc, config, cert, req.EntityID, reason)
 }
 
 func (b *backend) pathRotateCRLRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
@@ -835,6 +919,15 @@ This allows certificates to be revoke. A root token or corresponding
 private key is required.
 `
 
+const pathRevokeWithKeyHelpSyn = `
+Revoke a certificate by proving possession of its private key.
+`
+
+const pathRevokeWithKeyHelpDesc = `
+This allows the holder of a certificate to revoke it, by presenting
+either its private key or a signature of a challenge made with that key.
+`
+
 const pathRotateCRLHelpSyn = `
 Force a rebuild of the CRL.
 `
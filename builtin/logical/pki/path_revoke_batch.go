@@ -0,0 +1,287 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// revokeBatchSize is the number of certificates revoke-batch revokes at a time
+// while holding the revocation lock, so that other revocations and tidy can
+// make progress during large batches.
+const revokeBatchSize = 100
+
+func pathRevokeBatch(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: `revoke-batch`,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "revoke",
+			OperationSuffix: "batch",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"serial_numbers": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `Serial numbers of the certificates to revoke, in
+colon- or hyphen-separated hexadecimal. Mutually exclusive with role and
+issued_before.`,
+			},
+			"role": {
+				Type: framework.TypeString,
+				Description: `Revoke all unexpired certificates stored by this
+mount which were issued by this role.`,
+			},
+			"issued_before": {
+				Type: framework.TypeTime,
+				Description: `Revoke all unexpired certificates stored by this
+mount whose validity started before this time, as an RFC 3339 timestamp
+or seconds since the epoch. Combined with role when both are given.`,
+			},
+			"revocation_reason": {
+				Type: framework.TypeString,
+				Description: `RFC 5280 reason for the revocations, by name
+(such as keyCompromise or superseded) or code, recorded in CRLs and OCSP
+responses. Defaults to unspecified; certificateHold and removeFromCRL are
+not supported.`,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.metricsWrap("revoke-batch", noRole, b.pathRevokeBatchWrite),
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"revoked": {
+								Type:        framework.TypeStringSlice,
+								Description: `Serial numbers of the certificates revoked by this request`,
+								Required:    true,
+							},
+							"skipped": {
+								Type:        framework.TypeMap,
+								Description: `Serial numbers of the certificates which were not revoked, with the reason`,
+								Required:    true,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathRevokeBatchHelpSyn,
+		HelpDescription: pathRevokeBatchHelpDesc,
+	}
+}
+
+func (b *backend) pathRevokeBatchWrite(ctx context.Context, req *logical.Request, data *framework.FieldData, _ *roleEntry) (*logical.Response, error) {
+	rawSerials, haveSerials := data.GetOk("serial_numbers")
+	rawRole, haveRole := data.GetOk("role")
+	rawIssuedBefore, haveIssuedBefore := data.GetOk("issued_before")
+
+	if !haveSerials && !haveRole && !haveIssuedBefore {
+		return logical.ErrorResponse("The serial numbers, or a role or issued_before filter, of the certificates to revoke must be provided."), nil
+	} else if haveSerials && (haveRole || haveIssuedBefore) {
+		return logical.ErrorResponse("Must provide either the serial numbers or a role and issued_before filter; not both."), nil
+	}
+
+	reason, err := parseRevocationReason(data.Get("revocation_reason").(string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if b.System().ReplicationState().HasState(consts.ReplicationPerformanceStandby) {
+		return nil, logical.ErrReadOnly
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := b.crlBuilder.getConfigWithUpdate(sc)
+	if err != nil {
+		return nil, fmt.Errorf("error revoking certificates: failed reading config: %w", err)
+	}
+
+	// The candidates are gathered without holding the revocation lock; any
+	// revoked in the meantime are reported as already revoked below.
+	skipped := map[string]interface{}{}
+	var certs []*x509.Certificate
+	if haveSerials {
+		for _, serial := range rawSerials.([]string) {
+			cert, err := fetchRevocationCandidate(sc, serial)
+			if err != nil {
+				return nil, err
+			}
+			if cert == nil {
+				skipped[serial] = "certificate not found"
+				continue
+			}
+			certs = append(certs, cert)
+		}
+	} else {
+		role, _ := rawRole.(string)
+		issuedBefore, _ := rawIssuedBefore.(time.Time)
+		var noMetadata []string
+		certs, noMetadata, err = fetchRevocationCandidatesByFilter(sc, role, issuedBefore)
+		if err != nil {
+			return nil, err
+		}
+		for _, serial := range noMetadata {
+			skipped[serial] = "no metadata recording the role which issued the certificate"
+		}
+	}
+
+	resp := &logical.Response{}
+	revoked := []string{}
+	for start := 0; start < len(certs); start += revokeBatchSize {
+		end := start + revokeBatchSize
+		if end > len(certs) {
+			end = len(certs)
+		}
+		if err := b.revokeBatch(sc, config, certs[start:end], req.EntityID, reason, resp, &revoked, skipped); err != nil {
+			return nil, err
+		}
+	}
+
+	resp.Data = map[string]interface{}{
+		"revoked": revoked,
+		"skipped": skipped,
+	}
+	if len(revoked) == 0 {
+		return resp, nil
+	}
+
+	b.revokeStorageLock.Lock()
+	defer b.revokeStorageLock.Unlock()
+
+	return rebuildCRLAfterRevocation(sc, config, resp)
+}
+
+// revokeBatch revokes the given certificates while holding the revocation
+// lock, recording their serial numbers in revoked or, with the reason, in
+// skipped.
+func (b *backend) revokeBatch(sc *storageContext, config *crlConfig, certs []*x509.Certificate, entityId string, reason int, resp *logical.Response, revoked *[]string, skipped map[string]interface{}) error {
+	b.revokeStorageLock.Lock()
+	defer b.revokeStorageLock.Unlock()
+
+	for _, cert := range certs {
+		serial := serialFromCert(cert)
+		certResp, newlyRevoked, err := revokeCertWithoutRebuild(sc, config, cert, entityId, reason)
+		switch {
+		case err != nil:
+			return fmt.Errorf("error revoking certificate %v: %w", serial, err)
+		case newlyRevoked:
+			*revoked = append(*revoked, serial)
+			for _, warning := range certResp.Warnings {
+				resp.AddWarning(fmt.Sprintf("%v: %v", serial, warning))
+			}
+		case certResp == nil:
+			skipped[serial] = "mount is being removed"
+		case certResp.IsError():
+			skipped[serial] = certResp.Error().Error()
+		case len(certResp.Warnings) > 0:
+			skipped[serial] = certResp.Warnings[0]
+		default:
+			skipped[serial] = "certificate already revoked"
+		}
+	}
+
+	return nil
+}
+
+// fetchRevocationCandidate returns the stored certificate with the given
+// serial number, or nil when this mount doesn't store it.
+func fetchRevocationCandidate(sc *storageContext, serial string) (*x509.Certificate, error) {
+	certEntry, err := fetchCertBySerial(sc, "certs/", serial)
+	if err != nil || certEntry == nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(certEntry.Value)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing certificate %v: %w", serial, err)
+	}
+
+	return cert, nil
+}
+
+// fetchRevocationCandidatesByFilter returns the unexpired certificates
+// stored by this mount which were issued by the given role, when set, and
+// whose validity started before issuedBefore, when set. When filtering by
+// role, it also returns the serial numbers of the otherwise matching
+// certificates which have no metadata to tell the role which issued them.
+func fetchRevocationCandidatesByFilter(sc *storageContext, role string, issuedBefore time.Time) ([]*x509.Certificate, []string, error) {
+	serials, err := sc.Storage.List(sc.Context, "certs/")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing certificates: %w", err)
+	}
+
+	// Issuers have no metadata, but can't be revoked through this endpoint
+	// either, so they aren't reported.
+	issuerSerials := map[string]bool{}
+	if role != "" {
+		issuers, err := fetchIssuerMapForRevocationChecking(sc)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, issuer := range issuers {
+			issuerSerials[serialFromCert(issuer)] = true
+		}
+	}
+
+	now := time.Now()
+	var certs []*x509.Certificate
+	var noMetadata []string
+	for _, serial := range serials {
+		cert, err := fetchRevocationCandidate(sc, serial)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cert == nil || cert.NotAfter.Before(now) {
+			continue
+		}
+		if !issuedBefore.IsZero() && !cert.NotBefore.Before(issuedBefore) {
+			continue
+		}
+
+		if role != "" {
+			metadata, err := sc.fetchCertMetadata(serial)
+			if err != nil {
+				return nil, nil, err
+			}
+			if metadata == nil {
+				if serial := serialFromCert(cert); !issuerSerials[serial] {
+					noMetadata = append(noMetadata, serial)
+				}
+				continue
+			}
+			if metadata.Role != role {
+				continue
+			}
+		}
+
+		certs = append(certs, cert)
+	}
+
+	return certs, noMetadata, nil
+}
+
+const pathRevokeBatchHelpSyn = `
+Revoke several certificates at once.
+`
+
+const pathRevokeBatchHelpDesc = `
+This allows revoking several certificates, given by their serial numbers or
+selected by the role which issued them and the time they were issued before,
+while rebuilding the CRL only once. A root token or corresponding policy is
+required.
+`
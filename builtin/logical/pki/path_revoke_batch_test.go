@@ -0,0 +1,135 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_RevokeBatch(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+	ctx := context.Background()
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootSerial := resp.Data["serial_number"].(string)
+
+	for _, role := range []string{"web", "db"} {
+		resp, err = CBWrite(b, s, "roles/"+role, map[string]interface{}{
+			"allow_any_name": true,
+			"key_type":       "ec",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "roles/"+role)
+	}
+
+	issue := func(role string) string {
+		resp, err := CBWrite(b, s, "issue/"+role, map[string]interface{}{
+			"common_name": "www.example.com",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/"+role)
+		return resp.Data["serial_number"].(string)
+	}
+
+	crlNumber := func() int64 {
+		resp, err := CBRead(b, s, "crl")
+		require.NoError(t, err)
+		crl, err := x509.ParseRevocationList(resp.Data["http_raw_body"].([]byte))
+		require.NoError(t, err)
+		return crl.Number.Int64()
+	}
+
+	web1, web2, db1 := issue("web"), issue("web"), issue("db")
+
+	_, err = CBWrite(b, s, "revoke-batch", map[string]interface{}{})
+	require.ErrorContains(t, err, "must be provided")
+	_, err = CBWrite(b, s, "revoke-batch", map[string]interface{}{
+		"serial_numbers": []string{web1},
+		"role":           "web",
+	})
+	require.ErrorContains(t, err, "not both")
+
+	// A single revocation rebuilds the CRLs, advancing their number.
+	before := crlNumber()
+	resp, err = CBWrite(b, s, "revoke", map[string]interface{}{
+		"serial_number": web2,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke")
+	rebuildStep := crlNumber() - before
+	require.Positive(t, rebuildStep)
+
+	before = crlNumber()
+	resp, err = CBWrite(b, s, "revoke-batch", map[string]interface{}{
+		"serial_numbers":    []string{web1, db1, web2, rootSerial, "01:02:03"},
+		"revocation_reason": "superseded",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke-batch")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("revoke-batch"), logical.UpdateOperation), resp, true)
+	require.ElementsMatch(t, []string{web1, db1}, resp.Data["revoked"])
+	skipped := resp.Data["skipped"].(map[string]interface{})
+	require.Len(t, skipped, 3)
+	require.Equal(t, "certificate already revoked", skipped[web2])
+	require.Contains(t, skipped[rootSerial], "to its own CRL is not allowed")
+	require.Equal(t, "certificate not found", skipped["01:02:03"])
+
+	// The CRLs are rebuilt once for the whole batch.
+	require.Equal(t, before+rebuildStep, crlNumber())
+
+	resp, err = CBRead(b, s, "cert/"+db1)
+	requireSuccessNonNilResponse(t, resp, err, "cert")
+	require.Equal(t, "superseded", resp.Data["revocation_reason"])
+
+	// Filters select unrevoked certificates by role and issuance time.
+	// Certificates without metadata can't be matched to a role, and are
+	// reported as skipped.
+	web3, web4, db2 := issue("web"), issue("web"), issue("db")
+	require.NoError(t, s.Delete(ctx, certMetadataPrefix+normalizeSerial(web4)))
+	resp, err = CBWrite(b, s, "revoke-batch", map[string]interface{}{
+		"role":          "web",
+		"issued_before": time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke-batch")
+	require.Equal(t, []string{web3}, resp.Data["revoked"])
+	skipped = resp.Data["skipped"].(map[string]interface{})
+	require.Len(t, skipped, 3)
+	require.Equal(t, "certificate already revoked", skipped[web1])
+	require.Equal(t, "certificate already revoked", skipped[web2])
+	require.Contains(t, skipped[web4], "no metadata")
+
+	resp, err = CBWrite(b, s, "revoke-batch", map[string]interface{}{
+		"issued_before": time.Now().Add(-time.Hour).Format(time.RFC3339),
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke-batch")
+	require.Empty(t, resp.Data["revoked"])
+
+	resp, err = CBRead(b, s, "cert/"+db2)
+	requireSuccessNonNilResponse(t, resp, err, "cert")
+	require.Equal(t, int64(0), resp.Data["revocation_time"])
+
+	// Batches larger than revokeBatchSize are revoked in several steps.
+	many := []string{db2}
+	for len(many) <= revokeBatchSize {
+		many = append(many, issue("db"))
+	}
+	resp, err = CBWrite(b, s, "revoke-batch", map[string]interface{}{
+		"role": "db",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "revoke-batch")
+	require.ElementsMatch(t, many, resp.Data["revoked"])
+	skipped = resp.Data["skipped"].(map[string]interface{})
+	require.Len(t, skipped, 2)
+	require.Equal(t, "certificate already revoked", skipped[db1])
+	require.Contains(t, skipped[web4], "no metadata")
+}
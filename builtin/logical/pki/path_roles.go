@@ -123,6 +123,21 @@ the common name to be issued, conforming to RFC 6125's Section 6.4.3; e.g.,
 information.`,
 		},
 
+		"wildcard_full_label_only": {
+			Type:        framework.TypeBool,
+			Description: `If set, wildcards must make up the entire left-most label of names, e.g. "*.example.net" but not "b*z.example.net".`,
+		},
+
+		"max_wildcard_depth": {
+			Type:        framework.TypeInt,
+			Description: `The maximum number of labels in wildcard names, including the wildcard label. Zero means no limit.`,
+		},
+
+		"denied_wildcard_zones": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `If set, an array of zones in which wildcard names may not be issued, e.g. "example.net" denies "*.example.net" but not "*.prod.example.net".`,
+		},
+
 		"allow_any_name": {
 			Type:     framework.TypeBool,
 			Required: true,
@@ -142,7 +157,12 @@ CN and DNS SANs, and the host part of email addresses. Defaults to true.`,
 			Type:     framework.TypeBool,
 			Required: true,
 			Description: `If set, IP Subject Alternative Names are allowed.
-Any valid IP is accepted and No authorization checking is performed.`,
+Any valid IP is accepted, unless restricted by allowed_ip_sans.`,
+		},
+
+		"allowed_ip_sans": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `If set, an array of CIDRs which IP Subject Alternative Names must be within.`,
 		},
 
 		"allowed_uri_sans": {
@@ -159,12 +179,27 @@ Any valid URI is accepted, these values support globbing.`,
 				Non-templated URI SANs are also permitted.`,
 		},
 
+		"allowed_email_sans": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `If set, an array of allowed email addresses (RFC 822 names), which support globbing and identity templates.`,
+		},
+
+		"escrow_private_keys": {
+			Type:        framework.TypeBool,
+			Description: `If set, private keys generated by Vault are escrowed.`,
+		},
+
 		"allowed_other_sans": {
 			Type:        framework.TypeCommaStringSlice,
 			Required:    true,
 			Description: `If set, an array of allowed other names to put in SANs. These values support globbing and must be in the format <oid>;<type>:<value>. Currently only "utf8" is a valid type. All values, including globbing values, must use this syntax, with the exception being a single "*" which allows any OID and any value (but type must still be utf8).`,
 		},
 
+		"allowed_upn_sans": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `If set, an array of allowed Microsoft User Principal Names to put in otherName SANs. These values support globbing.`,
+		},
+
 		"allowed_serial_numbers": {
 			Type:        framework.TypeCommaStringSlice,
 			Required:    true,
@@ -349,6 +384,14 @@ for "generate_lease".`,
 			Description: `If set to false, makes the 'common_name' field optional while generating a certificate.`,
 		},
 
+		"allowed_acme_challenges": {
+			Type: framework.TypeCommaStringSlice,
+			Description: `List of ACME challenge types allowed to validate
+identifiers when issuing certificates with this role through ACME. Values
+can include 'http-01', 'dns-01' and 'tls-alpn-01'. When empty, all challenge
+types are allowed.`,
+		},
+
 		"cn_validations": {
 			Type: framework.TypeCommaStringSlice,
 			Description: `List of allowed validations to run against the
@@ -367,6 +410,81 @@ information, which must include an oid, and may include a notice and/or cps url,
 [{"oid"="1.3.6.1.4.1.7.8","notice"="I am a user Notice"}, {"oid"="1.3.6.1.4.1.44947.1.2.4 ","cps"="https://example.com"}].`,
 		},
 
+		"custom_extensions": {
+			Type: framework.TypeSlice,
+			Description: `List of additional X.509 extensions to add to issued
+certificates. Each is an object with an 'oid', an optional 'critical' flag,
+and either a 'value', the base64 encoded DER of the extension's value, or a
+'template', a string which may contain identity templates and is encoded as
+a UTF8String. Extensions managed by Vault, such as the SANs or key usages,
+cannot be set.`,
+		},
+
+		"ms_certificate_template": {
+			Type:        framework.TypeString,
+			Description: `OID of the Microsoft certificate template named in issued certificates.`,
+		},
+
+		"ms_template_major_version": {
+			Type:        framework.TypeInt,
+			Description: `Major version of the Microsoft certificate template.`,
+		},
+
+		"ms_template_minor_version": {
+			Type:        framework.TypeInt,
+			Description: `Minor version of the Microsoft certificate template.`,
+		},
+
+		"ms_application_policies": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `Microsoft application policy OIDs of issued certificates.`,
+		},
+
+		"ct_log_urls": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `Certificate Transparency logs to submit issued certificates to, embedding their SCTs.`,
+		},
+
+		"caa_identities": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `CA identities CAA records must authorize for issuance.`,
+		},
+
+		"caa_resolvers": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `DNS resolvers used for CAA lookups.`,
+		},
+
+		"caa_failure_mode": {
+			Type:        framework.TypeString,
+			Description: `Whether failed CAA lookups block issuance (hard) or only warn (soft).`,
+		},
+
+		"lint_mode": {
+			Type:        framework.TypeString,
+			Description: `Whether issued certificates are linted, and whether lint errors only warn (warn) or block issuance (block).`,
+		},
+
+		"lint_ignore": {
+			Type:        framework.TypeCommaStringSlice,
+			Description: `Lints skipped when linting issued certificates.`,
+		},
+
+		"profile": {
+			Type:        framework.TypeString,
+			Description: `The certificate profile overriding fields of this role on issuance.`,
+		},
+
+		"certs_per_minute": {
+			Type:        framework.TypeInt,
+			Description: `The number of certificates this role issues per minute.`,
+		},
+
+		"max_concurrent_signs": {
+			Type:        framework.TypeInt,
+			Description: `The number of certificates this role signs concurrently.`,
+		},
+
 		"basic_constraints_valid_for_non_ca": {
 			Type:        framework.TypeBool,
 			Description: `Mark Basic Constraints valid when issuing non-CA certificates.`,
@@ -375,11 +493,19 @@ information, which must include an oid, and may include a notice and/or cps url,
 			Type:        framework.TypeInt64,
 			Description: `The duration in seconds before now which the certificate needs to be backdated by.`,
 		},
+		"max_not_before_duration": {
+			Type:        framework.TypeInt64,
+			Description: `The duration in seconds before now which requests may backdate certificates by, through not_before.`,
+		},
 		"not_after": {
 			Type: framework.TypeString,
 			Description: `Set the not after field of the certificate with specified date value.
 The value format should be given in UTC format YYYY-MM-ddTHH:MM:SSZ.`,
 		},
+		"leaf_not_after_behavior": {
+			Type:        framework.TypeString,
+			Description: `Behavior when the NotAfter date of issued certificates exceeds that of the issuer, overriding the issuer's; empty to use the issuer's.`,
+		},
 		"issuer_ref": {
 			Type: framework.TypeString,
 			Description: `Reference to the issuer used to sign requests
@@ -484,6 +610,27 @@ information.`,
 				Default: true,
 			},
 
+			"wildcard_full_label_only": {
+				Type: framework.TypeBool,
+				Description: `If set, wildcards must make up the entire
+left-most label of names; e.g., "*.example.net" is allowed but "b*z.example.net"
+is not. Defaults to false.`,
+			},
+
+			"max_wildcard_depth": {
+				Type: framework.TypeInt,
+				Description: `The maximum number of labels in wildcard names,
+including the wildcard label; e.g., a value of 4 allows "*.prod.example.net"
+but not "*.eu.prod.example.net". Defaults to 0, no limit.`,
+			},
+
+			"denied_wildcard_zones": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `If set, an array of zones in which wildcard
+names may not be issued; e.g., "example.net" denies "*.example.net" but not
+"*.prod.example.net".`,
+			},
+
 			"allow_any_name": {
 				Type: framework.TypeBool,
 				Description: `If set, clients can request certificates for
@@ -505,13 +652,23 @@ CN and DNS SANs, and the host part of email addresses. Defaults to true.`,
 				Type:    framework.TypeBool,
 				Default: true,
 				Description: `If set, IP Subject Alternative Names are allowed.
-Any valid IP is accepted and No authorization checking is performed.`,
+Any valid IP is accepted, unless restricted by allowed_ip_sans.`,
 				DisplayAttrs: &framework.DisplayAttributes{
 					Name:  "Allow IP Subject Alternative Names",
 					Value: true,
 				},
 			},
 
+			"allowed_ip_sans": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `If set, an array of CIDRs, such as 10.0.0.0/8, which
+IP Subject Alternative Names must be within. When empty, any valid IP is
+accepted if allow_ip_sans is set.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Allowed IP Subject Alternative Names",
+				},
+			},
+
 			"allowed_uri_sans": {
 				Type: framework.TypeCommaStringSlice,
 				Description: `If set, an array of allowed URIs for URI Subject Alternative Names.
@@ -528,6 +685,26 @@ Any valid URI is accepted, these values support globbing.`,
 				Default: false,
 			},
 
+			"allowed_email_sans": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `If set, an array of allowed email addresses (RFC 822
+names), in the common name or SANs, in addition to the checks of allowed_domains.
+These values support globbing and identity templates, such as
+{{identity.entity.metadata.email}}, to only allow requesters their own email
+address for S/MIME.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Allowed Email Subject Alternative Names",
+				},
+			},
+
+			"escrow_private_keys": {
+				Type: framework.TypeBool,
+				Description: `If set, private keys generated by Vault when issuing
+certificates are escrowed, for later recovery through the escrowed-keys/<serial>
+endpoint, such as for decrypting email encrypted to S/MIME certificates.
+Defaults to false.`,
+			},
+
 			"allowed_other_sans": {
 				Type:        framework.TypeCommaStringSlice,
 				Description: `If set, an array of allowed other names to put in SANs. These values support globbing and must be in the format <oid>;<type>:<value>. Currently only "utf8" is a valid type. All values, including globbing values, must use this syntax, with the exception being a single "*" which allows any OID and any value (but type must still be utf8).`,
@@ -536,6 +713,17 @@ Any valid URI is accepted, these values support globbing.`,
 				},
 			},
 
+			"allowed_upn_sans": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `If set, an array of allowed Microsoft User Principal
+Names (UPNs) to put in otherName SANs, of the form user@suffix, such as for
+smartcard logon or 802.1X. These values support globbing, and UPNs matching
+them are allowed in addition to those allowed by allowed_other_sans.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Allowed UPN Subject Alternative Names",
+				},
+			},
+
 			"allowed_serial_numbers": {
 				Type:        framework.TypeCommaStringSlice,
 				Description: `If set, an array of allowed serial numbers to put in Subject. These values support globbing.`,
@@ -758,6 +946,17 @@ for "generate_lease".`,
 				},
 			},
 
+			"allowed_acme_challenges": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `List of ACME challenge types allowed to validate
+identifiers when issuing certificates with this role through ACME. Values
+can include 'http-01', 'dns-01' and 'tls-alpn-01'. When empty, all challenge
+types are allowed.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Allowed ACME challenges",
+				},
+			},
+
 			"cn_validations": {
 				Type:    framework.TypeCommaStringSlice,
 				Default: []string{"email", "hostname"},
@@ -780,6 +979,156 @@ information, which must include an oid, and may include a notice and/or cps url,
 [{"oid"="1.3.6.1.4.1.7.8","notice"="I am a user Notice"}, {"oid"="1.3.6.1.4.1.44947.1.2.4 ","cps"="https://example.com"}].`,
 			},
 
+			"custom_extensions": {
+				Type: framework.TypeSlice,
+				Description: `List of additional X.509 extensions to add to issued
+certificates. Each is an object with an 'oid', an optional 'critical' flag,
+and either a 'value', the base64 encoded DER of the extension's value, or a
+'template', a string which may contain identity templates and is encoded as
+a UTF8String. Extensions managed by Vault, such as the SANs or key usages,
+cannot be set.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Custom Extensions",
+				},
+			},
+
+			"ms_certificate_template": {
+				Type: framework.TypeString,
+				Description: `If set, the OID of a Microsoft certificate template,
+as listed by certutil -template on the Windows side, to name in the certificate
+template extension (szOID_CERTIFICATE_TEMPLATE) of issued certificates. Windows
+auto-enrollment and smartcard logon validation check this extension.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Microsoft Certificate Template",
+				},
+			},
+
+			"ms_template_major_version": {
+				Type:        framework.TypeInt,
+				Default:     100,
+				Description: `The major version of the Microsoft certificate template. Defaults to 100, the first version of templates created by ADCS.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Microsoft Certificate Template Major Version",
+				},
+			},
+
+			"ms_template_minor_version": {
+				Type:        framework.TypeInt,
+				Default:     0,
+				Description: `The minor version of the Microsoft certificate template.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Microsoft Certificate Template Minor Version",
+				},
+			},
+
+			"ms_application_policies": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `A comma-separated string or list of OIDs to place in
+the Microsoft application policies extension of issued certificates, which
+Windows checks in addition to the extended key usages, such as
+1.3.6.1.4.1.311.20.2.2 for smartcard logon.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Microsoft Application Policies",
+				},
+			},
+
+			"ct_log_urls": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `List of Certificate Transparency log URLs. If set,
+a precertificate of each certificate issued or signed with this role is
+submitted to every log, and the returned SCTs are embedded in the issued
+certificate. Issuance fails if any log fails to return an SCT.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "CT Log URLs",
+				},
+			},
+
+			"caa_identities": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `List of issuer domain names identifying this CA in
+CAA records (RFC 8659). If set, the CAA records of each DNS name requested
+are looked up before issuance, which is refused unless they authorize one
+of these identities.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "CAA Identities",
+				},
+			},
+
+			"caa_resolvers": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `List of DNS resolvers, as host or host:port, to
+use for CAA lookups. Defaults to the resolvers of the host running Vault.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "CAA Resolvers",
+				},
+			},
+
+			"caa_failure_mode": {
+				Type:    framework.TypeString,
+				Default: caaFailureModeHard,
+				Description: `What to do when CAA records cannot be looked up:
+"hard" refuses issuance, while "soft" issues the certificate with a warning.
+Records which don't authorize issuance always refuse it.`,
+				AllowedValues: []interface{}{caaFailureModeHard, caaFailureModeSoft},
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "CAA Failure Mode",
+					Value: caaFailureModeHard,
+				},
+			},
+
+			"lint_mode": {
+				Type:    framework.TypeString,
+				Default: lintModeOff,
+				Description: `Whether to lint certificates before they are
+released: "off" disables linting, "warn" returns every finding as a
+warning, and "block" refuses issuance on findings of error severity,
+while still warning about the others.`,
+				AllowedValues: []interface{}{lintModeOff, lintModeWarn, lintModeBlock},
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name:  "Lint Mode",
+					Value: lintModeOff,
+				},
+			},
+
+			"lint_ignore": {
+				Type: framework.TypeCommaStringSlice,
+				Description: `List of lints, by name, to skip when linting
+certificates issued by this role.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Lint Ignore",
+				},
+			},
+
+			"profile": {
+				Type: framework.TypeString,
+				Description: `Name of the certificate profile to issue
+certificates with. Every field set on the profile replaces the corresponding
+field of this role on issuance.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Profile",
+				},
+			},
+
+			"certs_per_minute": {
+				Type: framework.TypeInt,
+				Description: `The number of certificates this role issues
+per minute, on each node. Requests beyond it are refused with a 429 status
+code. Defaults to 0, no limit.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Certificates Per Minute",
+				},
+			},
+
+			"max_concurrent_signs": {
+				Type: framework.TypeInt,
+				Description: `The number of certificates this role signs
+concurrently, on each node. Requests beyond it are refused with a 429 status
+code. Defaults to 0, no limit.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Maximum Concurrent Signs",
+				},
+			},
+
 			"basic_constraints_valid_for_non_ca": {
 				Type:        framework.TypeBool,
 				Description: `Mark Basic Constraints valid when issuing non-CA certificates.`,
@@ -795,11 +1144,33 @@ information, which must include an oid, and may include a notice and/or cps url,
 					Value: 30,
 				},
 			},
+			"max_not_before_duration": {
+				Type: framework.TypeDurationSecond,
+				Description: `The furthest before now requests may backdate
+certificates to, through the not_before parameter, such as for devices with
+skewed clocks. Defaults to 0, not allowing requests to backdate certificates
+further than not_before_duration.`,
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Max Not Before Duration",
+				},
+			},
 			"not_after": {
 				Type: framework.TypeString,
 				Description: `Set the not after field of the certificate with specified date value.
 The value format should be given in UTC format YYYY-MM-ddTHH:MM:SSZ.`,
 			},
+			"leaf_not_after_behavior": {
+				Type: framework.TypeString,
+				Description: `Behavior when the computed NotAfter date of a
+certificate exceeds that of the issuer, overriding the issuer's
+leaf_not_after_behavior: "err" to refuse issuance, "truncate" to truncate it
+to that of the issuer, or "permit" to issue it regardless. Defaults to empty,
+using the setting of the issuer.`,
+				AllowedValues: []interface{}{"", "err", "truncate", "permit"},
+				DisplayAttrs: &framework.DisplayAttributes{
+					Name: "Leaf NotAfter Behavior",
+				},
+			},
 			"issuer_ref": {
 				Type: framework.TypeString,
 				Description: `Reference to the issuer used to sign requests
@@ -1013,6 +1384,8 @@ func (b *backend) pathRoleDelete(ctx context.Context, req *logical.Request, data
 		return nil, err
 	}
 
+	b.issuanceLimiter.forgetRole(data.Get("name").(string))
+
 	return nil, nil
 }
 
@@ -1033,6 +1406,9 @@ func (b *backend) pathRoleRead(ctx context.Context, req *logical.Request, data *
 	resp := &logical.Response{
 		Data: role.ToResponseData(),
 	}
+	if !b.useLegacyBundleCaStorage() {
+		addWarningOnRevokedIssuer(b.makeStorageContext(ctx, req.Storage), role.Issuer, resp)
+	}
 	return resp, nil
 }
 
@@ -1059,11 +1435,17 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 		AllowSubdomains:               data.Get("allow_subdomains").(bool),
 		AllowGlobDomains:              data.Get("allow_glob_domains").(bool),
 		AllowWildcardCertificates:     new(bool), // Handled specially below
+		WildcardFullLabelOnly:         data.Get("wildcard_full_label_only").(bool),
+		MaxWildcardDepth:              data.Get("max_wildcard_depth").(int),
+		DeniedWildcardZones:           data.Get("denied_wildcard_zones").([]string),
 		AllowAnyName:                  data.Get("allow_any_name").(bool),
 		AllowedURISANsTemplate:        data.Get("allowed_uri_sans_template").(bool),
 		EnforceHostnames:              data.Get("enforce_hostnames").(bool),
 		AllowIPSANs:                   data.Get("allow_ip_sans").(bool),
+		AllowedIPSANs:                 data.Get("allowed_ip_sans").([]string),
 		AllowedURISANs:                data.Get("allowed_uri_sans").([]string),
+		AllowedEmailSANs:              data.Get("allowed_email_sans").([]string),
+		EscrowPrivateKeys:             data.Get("escrow_private_keys").(bool),
 		ServerFlag:                    data.Get("server_flag").(bool),
 		ClientFlag:                    data.Get("client_flag").(bool),
 		CodeSigningFlag:               data.Get("code_signing_flag").(bool),
@@ -1088,16 +1470,71 @@ func (b *backend) pathRoleCreate(ctx context.Context, req *logical.Request, data
 		NoStore:                       data.Get("no_store").(bool),
 		RequireCN:                     data.Get("require_cn").(bool),
 		CNValidations:                 data.Get("cn_validations").([]string),
+		AllowedACMEChallenges:         data.Get("allowed_acme_challenges").([]string),
 		AllowedSerialNumbers:          data.Get("allowed_serial_numbers").([]string),
 		AllowedUserIDs:                data.Get("allowed_user_ids").([]string),
+		AllowedUPNSANs:                data.Get("allowed_upn_sans").([]string),
 		PolicyIdentifiers:             getPolicyIdentifier(data, nil),
 		BasicConstraintsValidForNonCA: data.Get("basic_constraints_valid_for_non_ca").(bool),
 		NotBeforeDuration:             time.Duration(data.Get("not_before_duration").(int)) * time.Second,
+		MaxNotBeforeDuration:          time.Duration(data.Get("max_not_before_duration").(int)) * time.Second,
 		NotAfter:                      data.Get("not_after").(string),
+		LeafNotAfterBehavior:          data.Get("leaf_not_after_behavior").(string),
 		Issuer:                        data.Get("issuer_ref").(string),
 		Name:                          name,
 	}
 
+	entry.CustomExtensions, err = getCustomExtensions(data, []customExtension{})
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.MSCertificateTemplate = data.Get("ms_certificate_template").(string)
+	entry.MSTemplateMajorVersion = data.Get("ms_template_major_version").(int)
+	entry.MSTemplateMinorVersion = data.Get("ms_template_minor_version").(int)
+	entry.MSApplicationPolicies = data.Get("ms_application_policies").([]string)
+
+	entry.CTLogURLs = data.Get("ct_log_urls").([]string)
+	if err := validateCTLogURLs(entry.CTLogURLs); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.CAAIdentities = data.Get("caa_identities").([]string)
+	entry.CAAFailureMode = data.Get("caa_failure_mode").(string)
+	if entry.CAAFailureMode == "" {
+		entry.CAAFailureMode = caaFailureModeHard
+	}
+	if err := validateCAAFailureMode(entry.CAAFailureMode); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	entry.CAAResolvers, err = normalizeCAAResolvers(data.Get("caa_resolvers").([]string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.LintMode = data.Get("lint_mode").(string)
+	if entry.LintMode == "" {
+		entry.LintMode = lintModeOff
+	}
+	if err := validateLintMode(entry.LintMode); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	entry.LintIgnore = data.Get("lint_ignore").([]string)
+	if err := validateLintNames(entry.LintIgnore); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.Profile = data.Get("profile").(string)
+	if resp, err := b.validateRoleProfile(ctx, req.Storage, entry.Profile); resp != nil || err != nil {
+		return resp, err
+	}
+
+	entry.CertsPerMinute = data.Get("certs_per_minute").(int)
+	entry.MaxConcurrentSigns = data.Get("max_concurrent_signs").(int)
+	if entry.CertsPerMinute < 0 || entry.MaxConcurrentSigns < 0 {
+		return logical.ErrorResponse("certs_per_minute and max_concurrent_signs must not be negative"), nil
+	}
+
 	allowedOtherSANs := data.Get("allowed_other_sans").([]string)
 	switch {
 	case len(allowedOtherSANs) == 0:
@@ -1179,10 +1616,37 @@ func validateRole(b *backend, entry *roleEntry, ctx context.Context, s logical.S
 		}
 	}
 
-	if len(entry.PolicyIdentifiers) > 0 {
-		_, err := certutil.CreatePolicyInformationExtensionFromStorageStrings(entry.PolicyIdentifiers)
-		if err != nil {
-			return nil, err
+	if err := validatePolicyIdentifiers(entry.PolicyIdentifiers); err != nil {
+		return nil, err
+	}
+
+	if err := validateMicrosoftExtensions(entry); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	if entry.MaxWildcardDepth < 0 {
+		return logical.ErrorResponse(`"max_wildcard_depth" must not be negative`), nil
+	}
+
+	if _, err := parseIPRanges(entry.AllowedIPSANs); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("invalid allowed_ip_sans: %v", err)), nil
+	}
+
+	if entry.MaxNotBeforeDuration < 0 {
+		return logical.ErrorResponse(`"max_not_before_duration" must not be negative`), nil
+	}
+
+	if entry.LeafNotAfterBehavior != "" {
+		if _, err := parseLeafNotAfterBehavior(entry.LeafNotAfterBehavior); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	for _, challenge := range entry.AllowedACMEChallenges {
+		switch ACMEChallengeType(challenge) {
+		case ACMEHTTPChallenge, ACMEDNSChallenge, ACMEALPNChallenge:
+		default:
+			return logical.ErrorResponse(fmt.Sprintf("unknown ACME challenge type %q in allowed_acme_challenges", challenge)), nil
 		}
 	}
 
@@ -1208,8 +1672,9 @@ func validateRole(b *backend, entry *roleEntry, ctx context.Context, s logical.S
 			} else {
 				return nil, err
 			}
+		} else {
+			addWarningOnRevokedIssuer(sc, entry.Issuer, resp)
 		}
-
 	}
 
 	// Ensures CNValidations are alright
@@ -1259,11 +1724,17 @@ func (b *backend) pathRolePatch(ctx context.Context, req *logical.Request, data
 		AllowSubdomains:               getWithExplicitDefault(data, "allow_subdomains", oldEntry.AllowSubdomains).(bool),
 		AllowGlobDomains:              getWithExplicitDefault(data, "allow_glob_domains", oldEntry.AllowGlobDomains).(bool),
 		AllowWildcardCertificates:     new(bool), // Handled specially below
+		WildcardFullLabelOnly:         getWithExplicitDefault(data, "wildcard_full_label_only", oldEntry.WildcardFullLabelOnly).(bool),
+		MaxWildcardDepth:              getWithExplicitDefault(data, "max_wildcard_depth", oldEntry.MaxWildcardDepth).(int),
+		DeniedWildcardZones:           getWithExplicitDefault(data, "denied_wildcard_zones", oldEntry.DeniedWildcardZones).([]string),
 		AllowAnyName:                  getWithExplicitDefault(data, "allow_any_name", oldEntry.AllowAnyName).(bool),
 		AllowedURISANsTemplate:        getWithExplicitDefault(data, "allowed_uri_sans_template", oldEntry.AllowedURISANsTemplate).(bool),
 		EnforceHostnames:              getWithExplicitDefault(data, "enforce_hostnames", oldEntry.EnforceHostnames).(bool),
 		AllowIPSANs:                   getWithExplicitDefault(data, "allow_ip_sans", oldEntry.AllowIPSANs).(bool),
+		AllowedIPSANs:                 getWithExplicitDefault(data, "allowed_ip_sans", oldEntry.AllowedIPSANs).([]string),
 		AllowedURISANs:                getWithExplicitDefault(data, "allowed_uri_sans", oldEntry.AllowedURISANs).([]string),
+		AllowedEmailSANs:              getWithExplicitDefault(data, "allowed_email_sans", oldEntry.AllowedEmailSANs).([]string),
+		EscrowPrivateKeys:             getWithExplicitDefault(data, "escrow_private_keys", oldEntry.EscrowPrivateKeys).(bool),
 		ServerFlag:                    getWithExplicitDefault(data, "server_flag", oldEntry.ServerFlag).(bool),
 		ClientFlag:                    getWithExplicitDefault(data, "client_flag", oldEntry.ClientFlag).(bool),
 		CodeSigningFlag:               getWithExplicitDefault(data, "code_signing_flag", oldEntry.CodeSigningFlag).(bool),
@@ -1288,15 +1759,70 @@ func (b *backend) pathRolePatch(ctx context.Context, req *logical.Request, data
 		NoStore:                       getWithExplicitDefault(data, "no_store", oldEntry.NoStore).(bool),
 		RequireCN:                     getWithExplicitDefault(data, "require_cn", oldEntry.RequireCN).(bool),
 		CNValidations:                 getWithExplicitDefault(data, "cn_validations", oldEntry.CNValidations).([]string),
+		AllowedACMEChallenges:         getWithExplicitDefault(data, "allowed_acme_challenges", oldEntry.AllowedACMEChallenges).([]string),
 		AllowedSerialNumbers:          getWithExplicitDefault(data, "allowed_serial_numbers", oldEntry.AllowedSerialNumbers).([]string),
 		AllowedUserIDs:                getWithExplicitDefault(data, "allowed_user_ids", oldEntry.AllowedUserIDs).([]string),
+		AllowedUPNSANs:                getWithExplicitDefault(data, "allowed_upn_sans", oldEntry.AllowedUPNSANs).([]string),
 		PolicyIdentifiers:             getPolicyIdentifier(data, &oldEntry.PolicyIdentifiers),
 		BasicConstraintsValidForNonCA: getWithExplicitDefault(data, "basic_constraints_valid_for_non_ca", oldEntry.BasicConstraintsValidForNonCA).(bool),
 		NotBeforeDuration:             getTimeWithExplicitDefault(data, "not_before_duration", oldEntry.NotBeforeDuration),
+		MaxNotBeforeDuration:          getTimeWithExplicitDefault(data, "max_not_before_duration", oldEntry.MaxNotBeforeDuration),
 		NotAfter:                      getWithExplicitDefault(data, "not_after", oldEntry.NotAfter).(string),
+		LeafNotAfterBehavior:          getWithExplicitDefault(data, "leaf_not_after_behavior", oldEntry.LeafNotAfterBehavior).(string),
 		Issuer:                        getWithExplicitDefault(data, "issuer_ref", oldEntry.Issuer).(string),
 	}
 
+	entry.CustomExtensions, err = getCustomExtensions(data, oldEntry.CustomExtensions)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.MSCertificateTemplate = getWithExplicitDefault(data, "ms_certificate_template", oldEntry.MSCertificateTemplate).(string)
+	entry.MSTemplateMajorVersion = getWithExplicitDefault(data, "ms_template_major_version", oldEntry.MSTemplateMajorVersion).(int)
+	entry.MSTemplateMinorVersion = getWithExplicitDefault(data, "ms_template_minor_version", oldEntry.MSTemplateMinorVersion).(int)
+	entry.MSApplicationPolicies = getWithExplicitDefault(data, "ms_application_policies", oldEntry.MSApplicationPolicies).([]string)
+
+	entry.CTLogURLs = getWithExplicitDefault(data, "ct_log_urls", oldEntry.CTLogURLs).([]string)
+	if err := validateCTLogURLs(entry.CTLogURLs); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.CAAIdentities = getWithExplicitDefault(data, "caa_identities", oldEntry.CAAIdentities).([]string)
+	entry.CAAFailureMode = getWithExplicitDefault(data, "caa_failure_mode", oldEntry.CAAFailureMode).(string)
+	if entry.CAAFailureMode == "" {
+		entry.CAAFailureMode = caaFailureModeHard
+	}
+	if err := validateCAAFailureMode(entry.CAAFailureMode); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	entry.CAAResolvers, err = normalizeCAAResolvers(getWithExplicitDefault(data, "caa_resolvers", oldEntry.CAAResolvers).([]string))
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.LintMode = getWithExplicitDefault(data, "lint_mode", oldEntry.LintMode).(string)
+	if entry.LintMode == "" {
+		entry.LintMode = lintModeOff
+	}
+	if err := validateLintMode(entry.LintMode); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	entry.LintIgnore = getWithExplicitDefault(data, "lint_ignore", oldEntry.LintIgnore).([]string)
+	if err := validateLintNames(entry.LintIgnore); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	entry.Profile = getWithExplicitDefault(data, "profile", oldEntry.Profile).(string)
+	if resp, err := b.validateRoleProfile(ctx, req.Storage, entry.Profile); resp != nil || err != nil {
+		return resp, err
+	}
+
+	entry.CertsPerMinute = getWithExplicitDefault(data, "certs_per_minute", oldEntry.CertsPerMinute).(int)
+	entry.MaxConcurrentSigns = getWithExplicitDefault(data, "max_concurrent_signs", oldEntry.MaxConcurrentSigns).(int)
+	if entry.CertsPerMinute < 0 || entry.MaxConcurrentSigns < 0 {
+		return logical.ErrorResponse("certs_per_minute and max_concurrent_signs must not be negative"), nil
+	}
+
 	allowedOtherSANsData, wasSet := data.GetOk("allowed_other_sans")
 	if wasSet {
 		allowedOtherSANs := allowedOtherSANsData.([]string)
@@ -1460,9 +1986,13 @@ type roleEntry struct {
 	AllowSubdomains               bool          `json:"allow_subdomains"`
 	AllowGlobDomains              bool          `json:"allow_glob_domains"`
 	AllowWildcardCertificates     *bool         `json:"allow_wildcard_certificates,omitempty"`
+	WildcardFullLabelOnly         bool          `json:"wildcard_full_label_only"`
+	MaxWildcardDepth              int           `json:"max_wildcard_depth"`
+	DeniedWildcardZones           []string      `json:"denied_wildcard_zones"`
 	AllowAnyName                  bool          `json:"allow_any_name"`
 	EnforceHostnames              bool          `json:"enforce_hostnames"`
 	AllowIPSANs                   bool          `json:"allow_ip_sans"`
+	AllowedIPSANs                 []string      `json:"allowed_ip_sans"`
 	ServerFlag                    bool          `json:"server_flag"`
 	ClientFlag                    bool          `json:"client_flag"`
 	CodeSigningFlag               bool          `json:"code_signing_flag"`
@@ -1490,17 +2020,50 @@ type roleEntry struct {
 	NoStore                       bool          `json:"no_store"`
 	RequireCN                     bool          `json:"require_cn"`
 	CNValidations                 []string      `json:"cn_validations"`
+	AllowedACMEChallenges         []string      `json:"allowed_acme_challenges"`
 	AllowedOtherSANs              []string      `json:"allowed_other_sans"`
 	AllowedSerialNumbers          []string      `json:"allowed_serial_numbers"`
 	AllowedUserIDs                []string      `json:"allowed_user_ids"`
+	AllowedUPNSANs                []string      `json:"allowed_upn_sans"`
 	AllowedURISANs                []string      `json:"allowed_uri_sans"`
 	AllowedURISANsTemplate        bool          `json:"allowed_uri_sans_template"`
+	AllowedEmailSANs              []string      `json:"allowed_email_sans"`
+	EscrowPrivateKeys             bool          `json:"escrow_private_keys"`
 	PolicyIdentifiers             []string      `json:"policy_identifiers"`
 	ExtKeyUsageOIDs               []string      `json:"ext_key_usage_oids"`
 	BasicConstraintsValidForNonCA bool          `json:"basic_constraints_valid_for_non_ca"`
 	NotBeforeDuration             time.Duration `json:"not_before_duration"`
+	MaxNotBeforeDuration          time.Duration `json:"max_not_before_duration"`
 	NotAfter                      string        `json:"not_after"`
+	LeafNotAfterBehavior          string        `json:"leaf_not_after_behavior"`
 	Issuer                        string        `json:"issuer"`
+	// CustomExtensions are additional extensions added to issued certificates
+	CustomExtensions []customExtension `json:"custom_extensions"`
+	// MSCertificateTemplate and MSApplicationPolicies are emitted in the
+	// Microsoft certificate template and application policies extensions
+	MSCertificateTemplate  string   `json:"ms_certificate_template"`
+	MSTemplateMajorVersion int      `json:"ms_template_major_version"`
+	MSTemplateMinorVersion int      `json:"ms_template_minor_version"`
+	MSApplicationPolicies  []string `json:"ms_application_policies"`
+	// CTLogURLs are the Certificate Transparency logs issued certificates are submitted to
+	CTLogURLs []string `json:"ct_log_urls"`
+	// CAAIdentities, CAAResolvers and CAAFailureMode configure CAA checking of requested DNS names
+	CAAIdentities  []string `json:"caa_identities"`
+	CAAResolvers   []string `json:"caa_resolvers"`
+	CAAFailureMode string   `json:"caa_failure_mode"`
+	// LintMode and LintIgnore configure linting of issued certificates
+	LintMode   string   `json:"lint_mode"`
+	LintIgnore []string `json:"lint_ignore"`
+	// Profile is the certificate profile applied on issuance, if any
+	Profile string `json:"profile"`
+	// EnforceMaxTTL, RequireKeyAttestation and KeyAttestationRoots are only
+	// set by the profile of the role, on issuance
+	EnforceMaxTTL         bool   `json:"-"`
+	RequireKeyAttestation bool   `json:"-"`
+	KeyAttestationRoots   string `json:"-"`
+	// CertsPerMinute and MaxConcurrentSigns limit the issuance rate of this role
+	CertsPerMinute     int `json:"certs_per_minute"`
+	MaxConcurrentSigns int `json:"max_concurrent_signs"`
 	// Name is only set when the role has been stored, on the fly roles have a blank name
 	Name string `json:"-"`
 }
@@ -1517,10 +2080,14 @@ func (r *roleEntry) ToResponseData() map[string]interface{} {
 		"allow_subdomains":                   r.AllowSubdomains,
 		"allow_glob_domains":                 r.AllowGlobDomains,
 		"allow_wildcard_certificates":        r.AllowWildcardCertificates,
+		"wildcard_full_label_only":           r.WildcardFullLabelOnly,
+		"max_wildcard_depth":                 r.MaxWildcardDepth,
+		"denied_wildcard_zones":              r.DeniedWildcardZones,
 		"allow_any_name":                     r.AllowAnyName,
 		"allowed_uri_sans_template":          r.AllowedURISANsTemplate,
 		"enforce_hostnames":                  r.EnforceHostnames,
 		"allow_ip_sans":                      r.AllowIPSANs,
+		"allowed_ip_sans":                    r.AllowedIPSANs,
 		"server_flag":                        r.ServerFlag,
 		"client_flag":                        r.ClientFlag,
 		"code_signing_flag":                  r.CodeSigningFlag,
@@ -1545,13 +2112,33 @@ func (r *roleEntry) ToResponseData() map[string]interface{} {
 		"allowed_other_sans":                 r.AllowedOtherSANs,
 		"allowed_serial_numbers":             r.AllowedSerialNumbers,
 		"allowed_user_ids":                   r.AllowedUserIDs,
+		"allowed_upn_sans":                   r.AllowedUPNSANs,
 		"allowed_uri_sans":                   r.AllowedURISANs,
+		"allowed_email_sans":                 r.AllowedEmailSANs,
+		"escrow_private_keys":                r.EscrowPrivateKeys,
 		"require_cn":                         r.RequireCN,
 		"cn_validations":                     r.CNValidations,
+		"allowed_acme_challenges":            r.AllowedACMEChallenges,
 		"policy_identifiers":                 r.PolicyIdentifiers,
+		"custom_extensions":                  r.CustomExtensions,
+		"ms_certificate_template":            r.MSCertificateTemplate,
+		"ms_template_major_version":          r.MSTemplateMajorVersion,
+		"ms_template_minor_version":          r.MSTemplateMinorVersion,
+		"ms_application_policies":            r.MSApplicationPolicies,
+		"ct_log_urls":                        r.CTLogURLs,
+		"caa_identities":                     r.CAAIdentities,
+		"caa_resolvers":                      r.CAAResolvers,
+		"caa_failure_mode":                   r.CAAFailureMode,
+		"lint_mode":                          r.LintMode,
+		"lint_ignore":                        r.LintIgnore,
+		"profile":                            r.Profile,
+		"certs_per_minute":                   r.CertsPerMinute,
+		"max_concurrent_signs":               r.MaxConcurrentSigns,
 		"basic_constraints_valid_for_non_ca": r.BasicConstraintsValidForNonCA,
 		"not_before_duration":                int64(r.NotBeforeDuration.Seconds()),
+		"max_not_before_duration":            int64(r.MaxNotBeforeDuration.Seconds()),
 		"not_after":                          r.NotAfter,
+		"leaf_not_after_behavior":            r.LeafNotAfterBehavior,
 		"issuer_ref":                         r.Issuer,
 	}
 	if r.MaxPathLength != nil {
@@ -1641,6 +2228,17 @@ func getPolicyIdentifier(data *framework.FieldData, defaultIdentifiers *[]string
 	return policyIdentifierEntry.([]string)
 }
 
+// validatePolicyIdentifiers ensures the policy identifiers, as returned by
+// getPolicyIdentifier, can be encoded into a certificatePolicies extension.
+func validatePolicyIdentifiers(policyIdentifiers []string) error {
+	if len(policyIdentifiers) == 0 {
+		return nil
+	}
+
+	_, err := certutil.CreatePolicyInformationExtensionFromStorageStrings(policyIdentifiers)
+	return err
+}
+
 func parsePolicyIdentifiersFromJson(policyIdentifiers string) ([]string, error) {
 	var entries []certutil.PolicyIdentifierWithQualifierEntry
 	var policyIdentifierList []string
@@ -11,6 +11,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/go-errors/errors"
 	"github.com/hashicorp/go-secure-stdlib/strutil"
@@ -447,6 +448,246 @@ func TestPki_RoleAllowedURISANs(t *testing.T) {
 	}
 }
 
+func TestPki_RoleAllowedACMEChallenges(t *testing.T) {
+	t.Parallel()
+	b, storage := CreateBackendWithStorage(t)
+
+	roleReq := &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "roles/testrole",
+		Storage:   storage,
+		Data: map[string]interface{}{
+			"allowed_acme_challenges": "http-01,dns-02",
+		},
+	}
+
+	resp, err := b.HandleRequest(context.Background(), roleReq)
+	require.NoError(t, err)
+	require.True(t, resp.IsError(), "expected error for unknown challenge type: %#v", resp)
+
+	roleReq.Data["allowed_acme_challenges"] = "http-01,dns-01"
+	resp, err = b.HandleRequest(context.Background(), roleReq)
+	requireSuccessNonNilResponse(t, resp, err)
+
+	roleReq.Operation = logical.ReadOperation
+	resp, err = b.HandleRequest(context.Background(), roleReq)
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, []string{"http-01", "dns-01"}, resp.Data["allowed_acme_challenges"])
+}
+
+func TestPki_RoleCustomExtensions(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "myvault.com",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	attestationValue, err := asn1.Marshal([]int{1, 2, 3})
+	require.NoError(t, err)
+
+	invalid := []map[string]interface{}{
+		{"oid": "2.5.29.17", "value": base64.StdEncoding.EncodeToString(attestationValue)},
+		{"oid": "1.3.6.1.5.5.7.1.1", "template": "aia"},
+		{"oid": "1.3.6.1.4.1.99999.1"},
+		{"oid": "1.3.6.1.4.1.99999.1", "value": base64.StdEncoding.EncodeToString(attestationValue), "template": "both"},
+		{"oid": "1.3.6.1.4.1.99999.1", "value": base64.StdEncoding.EncodeToString([]byte("not der"))},
+		{"oid": "not-an-oid", "template": "value"},
+		{"oid": "1.3.6.1.4.1.99999.1", "template": "value", "unknown": true},
+	}
+	for index, extension := range invalid {
+		_, err = CBWrite(b, s, "roles/testrole", map[string]interface{}{
+			"allow_any_name":    true,
+			"custom_extensions": []interface{}{extension},
+		})
+		require.Error(t, err, "expected error for invalid extension %d: %v", index, extension)
+	}
+
+	_, err = CBWrite(b, s, "roles/testrole", map[string]interface{}{
+		"allow_any_name":    true,
+		"custom_extensions": `[{"oid": "1.3.6.1.4.1.99999.1", "template": "a"}, {"oid": "1.3.6.1.4.1.99999.1", "template": "b"}]`,
+	})
+	require.ErrorContains(t, err, "specified multiple times")
+
+	resp, err = CBWrite(b, s, "roles/testrole", map[string]interface{}{
+		"allow_any_name": true,
+		"custom_extensions": []interface{}{
+			map[string]interface{}{
+				"oid":      "1.3.6.1.4.1.99999.1",
+				"critical": true,
+				"value":    base64.StdEncoding.EncodeToString(attestationValue),
+			},
+			`{"oid": "1.3.6.1.4.1.99999.2", "template": "device-class-a"}`,
+		},
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/testrole")
+
+	resp, err = CBRead(b, s, "roles/testrole")
+	requireSuccessNonNilResponse(t, resp, err, "roles/testrole")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("roles/testrole"), logical.ReadOperation), resp, true)
+	require.Len(t, resp.Data["custom_extensions"], 2)
+
+	// Patching other fields leaves the extensions in place.
+	_, err = CBPatch(b, s, "roles/testrole", map[string]interface{}{
+		"ttl": "1h",
+	})
+	require.NoError(t, err)
+
+	requireExtensions := func(cert *x509.Certificate) {
+		expectedDeviceClass, err := asn1.MarshalWithParams("device-class-a", "utf8")
+		require.NoError(t, err)
+
+		found := 0
+		for _, ext := range cert.Extensions {
+			switch ext.Id.String() {
+			case "1.3.6.1.4.1.99999.1":
+				require.True(t, ext.Critical)
+				require.Equal(t, attestationValue, ext.Value)
+				found++
+			case "1.3.6.1.4.1.99999.2":
+				require.False(t, ext.Critical)
+				require.Equal(t, expectedDeviceClass, ext.Value)
+				found++
+			}
+		}
+		require.Equal(t, 2, found, "expected both custom extensions on the certificate")
+	}
+
+	resp, err = CBWrite(b, s, "issue/testrole", map[string]interface{}{
+		"common_name": "device.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/testrole")
+	requireExtensions(parseCert(t, resp.Data["certificate"].(string)))
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{}, "rsa", 2048)
+	resp, err = CBWrite(b, s, "sign/testrole", map[string]interface{}{
+		"common_name": "device.example.com",
+		"csr":         csrPem,
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign/testrole")
+	requireExtensions(parseCert(t, resp.Data["certificate"].(string)))
+
+	// Identity templates require a requesting entity.
+	_, err = CBPatch(b, s, "roles/testrole", map[string]interface{}{
+		"custom_extensions": []interface{}{
+			map[string]interface{}{
+				"oid":      "1.3.6.1.4.1.99999.2",
+				"template": "{{identity.entity.name}}",
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "issue/testrole", map[string]interface{}{
+		"common_name": "device.example.com",
+		"ttl":         "1h",
+	})
+	require.ErrorContains(t, err, "no associated entity")
+}
+
+func TestPki_RoleMicrosoftExtensions(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "myvault.com",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/smartcard", map[string]interface{}{
+		"allow_any_name":          true,
+		"ms_certificate_template": "not-an-oid",
+	})
+	require.ErrorContains(t, err, "ms_certificate_template")
+
+	_, err = CBWrite(b, s, "roles/smartcard", map[string]interface{}{
+		"allow_any_name":          true,
+		"ms_application_policies": "1.3.6.1.4.1.311.20.2.2,bad",
+	})
+	require.ErrorContains(t, err, "ms_application_policies")
+
+	_, err = CBWrite(b, s, "roles/smartcard", map[string]interface{}{
+		"allow_any_name":          true,
+		"ms_certificate_template": "1.3.6.1.4.1.311.21.8.1.2",
+		"custom_extensions": []interface{}{
+			map[string]interface{}{
+				"oid":      "1.3.6.1.4.1.311.21.7",
+				"template": "conflicting",
+			},
+		},
+	})
+	require.ErrorContains(t, err, "conflicts with ms_certificate_template")
+
+	resp, err = CBWrite(b, s, "roles/smartcard", map[string]interface{}{
+		"allow_any_name":            true,
+		"ms_certificate_template":   "1.3.6.1.4.1.311.21.8.1.2",
+		"ms_template_minor_version": 3,
+		"ms_application_policies":   "1.3.6.1.5.5.7.3.2,1.3.6.1.4.1.311.20.2.2",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/smartcard")
+
+	resp, err = CBRead(b, s, "roles/smartcard")
+	requireSuccessNonNilResponse(t, resp, err, "roles/smartcard")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("roles/smartcard"), logical.ReadOperation), resp, true)
+	require.Equal(t, "1.3.6.1.4.1.311.21.8.1.2", resp.Data["ms_certificate_template"])
+	require.Equal(t, 100, resp.Data["ms_template_major_version"])
+	require.Equal(t, 3, resp.Data["ms_template_minor_version"])
+
+	resp, err = CBWrite(b, s, "issue/smartcard", map[string]interface{}{
+		"common_name": "alice.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/smartcard")
+	cert := parseCert(t, resp.Data["certificate"].(string))
+
+	var template msCertificateTemplate
+	var policies []msApplicationPolicy
+	found := 0
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidMicrosoftCertificateTemplate):
+			require.False(t, ext.Critical)
+			_, err := asn1.Unmarshal(ext.Value, &template)
+			require.NoError(t, err)
+			found++
+		case ext.Id.Equal(oidMicrosoftApplicationPolicies):
+			require.False(t, ext.Critical)
+			_, err := asn1.Unmarshal(ext.Value, &policies)
+			require.NoError(t, err)
+			found++
+		}
+	}
+	require.Equal(t, 2, found, "expected both Microsoft extensions on the certificate")
+	require.Equal(t, asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 21, 8, 1, 2}, template.TemplateID)
+	require.Equal(t, 100, template.MajorVersion)
+	require.Equal(t, 3, template.MinorVersion)
+	require.Equal(t, []msApplicationPolicy{
+		{PolicyIdentifier: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 2}},
+		{PolicyIdentifier: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 2}},
+	}, policies)
+
+	// Clearing the template leaves only the application policies.
+	_, err = CBPatch(b, s, "roles/smartcard", map[string]interface{}{
+		"ms_certificate_template": "",
+	})
+	require.NoError(t, err)
+
+	resp, err = CBWrite(b, s, "issue/smartcard", map[string]interface{}{
+		"common_name": "alice.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/smartcard")
+	cert = parseCert(t, resp.Data["certificate"].(string))
+	for _, ext := range cert.Extensions {
+		require.False(t, ext.Id.Equal(oidMicrosoftCertificateTemplate), "expected no certificate template extension")
+	}
+}
+
 func TestPki_RolePkixFields(t *testing.T) {
 	t.Parallel()
 	var resp *logical.Response
@@ -831,6 +1072,11 @@ func TestPki_RolePatch(t *testing.T) {
 			Before:  []string{"*"},
 			Patched: []string{""},
 		},
+		{
+			Field:   "allowed_acme_challenges",
+			Before:  []string{"http-01"},
+			Patched: []string{"dns-01", "tls-alpn-01"},
+		},
 		{
 			Field:   "server_flag",
 			Before:  true,
@@ -943,11 +1189,21 @@ func TestPki_RolePatch(t *testing.T) {
 			Before:  int64(30),
 			Patched: int64(300),
 		},
+		{
+			Field:   "max_not_before_duration",
+			Before:  int64(3600),
+			Patched: int64(7200),
+		},
 		{
 			Field:   "not_after",
 			Before:  "9999-12-31T23:59:59Z",
 			Patched: "1230-12-31T23:59:59Z",
 		},
+		{
+			Field:   "leaf_not_after_behavior",
+			Before:  "truncate",
+			Patched: "permit",
+		},
 		{
 			Field:   "issuer_ref",
 			Before:  "default",
@@ -1132,6 +1388,134 @@ func TestPKI_RolePolicyInformation_Flat(t *testing.T) {
 	}
 }
 
+func TestPki_RoleLeafNotAfterBehavior(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "10h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	rootNotAfter := parseCert(t, resp.Data["certificate"].(string)).NotAfter
+
+	issue := func(role string, behavior string) (*x509.Certificate, error) {
+		_, err := CBWrite(b, s, "roles/"+role, map[string]interface{}{
+			"allowed_domains":         "example.com",
+			"allow_subdomains":        true,
+			"key_type":                "ec",
+			"leaf_not_after_behavior": behavior,
+		})
+		require.NoError(t, err)
+
+		resp, err := CBWrite(b, s, "issue/"+role, map[string]interface{}{
+			"common_name": "www.example.com",
+			"ttl":         "20h",
+		})
+		if err != nil {
+			return nil, err
+		}
+		return parseCert(t, resp.Data["certificate"].(string)), nil
+	}
+
+	_, err = CBWrite(b, s, "roles/invalid", map[string]interface{}{
+		"leaf_not_after_behavior": "extend",
+	})
+	require.Error(t, err)
+
+	// By default, the role uses the issuer's behavior.
+	_, err = issue("inherit", "")
+	require.ErrorContains(t, err, "beyond the expiration of the CA certificate")
+
+	cert, err := issue("truncate", "truncate")
+	require.NoError(t, err)
+	require.Equal(t, rootNotAfter, cert.NotAfter)
+
+	cert, err = issue("permit", "permit")
+	require.NoError(t, err)
+	require.True(t, cert.NotAfter.After(rootNotAfter))
+
+	// The role overrides the issuer's behavior either way.
+	_, err = CBPatch(b, s, "issuer/default", map[string]interface{}{
+		"leaf_not_after_behavior": "truncate",
+	})
+	require.NoError(t, err)
+
+	_, err = issue("err", "err")
+	require.ErrorContains(t, err, "beyond the expiration of the CA certificate")
+
+	cert, err = issue("inherit", "")
+	require.NoError(t, err)
+	require.Equal(t, rootNotAfter, cert.NotAfter)
+}
+
+func TestPki_RoleNotBefore(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name":         "root example.com",
+		"key_type":            "ec",
+		"ttl":                 "40h",
+		"not_before_duration": "24h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	_, err = CBWrite(b, s, "roles/example", map[string]interface{}{
+		"allowed_domains":         "example.com",
+		"allow_subdomains":        true,
+		"key_type":                "ec",
+		"not_before_duration":     "5m",
+		"max_not_before_duration": "2h",
+	})
+	require.NoError(t, err)
+
+	issue := func(notBefore time.Time) (*x509.Certificate, error) {
+		resp, err := CBWrite(b, s, "issue/example", map[string]interface{}{
+			"common_name": "www.example.com",
+			"not_before":  notBefore.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return parseCert(t, resp.Data["certificate"].(string)), nil
+	}
+
+	// Without not_before, certificates are backdated by not_before_duration.
+	resp, err = CBWrite(b, s, "issue/example", map[string]interface{}{
+		"common_name": "www.example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/example")
+	cert := parseCert(t, resp.Data["certificate"].(string))
+	require.WithinDuration(t, time.Now().Add(-5*time.Minute), cert.NotBefore, 10*time.Second)
+
+	notBefore := time.Now().Add(-90 * time.Minute).Truncate(time.Second)
+	cert, err = issue(notBefore)
+	require.NoError(t, err)
+	require.True(t, notBefore.Equal(cert.NotBefore), "expected NotBefore %v, got %v", notBefore, cert.NotBefore)
+
+	_, err = issue(time.Now().Add(-3 * time.Hour))
+	require.ErrorContains(t, err, "further in the past than the role allows")
+
+	_, err = issue(time.Now().Add(time.Hour))
+	require.ErrorContains(t, err, "in the future")
+
+	// By default, requests can't backdate further than not_before_duration.
+	_, err = CBPatch(b, s, "roles/example", map[string]interface{}{
+		"max_not_before_duration": 0,
+	})
+	require.NoError(t, err)
+
+	_, err = issue(time.Now().Add(-90 * time.Minute))
+	require.ErrorContains(t, err, "further in the past than the role allows")
+
+	_, err = issue(time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+}
+
 func getPolicyIdentifiersOffCertificate(resp logical.Response) ([]string, error) {
 	stringCertificate := resp.Data["certificate"].(string)
 	block, _ := pem.Decode([]byte(stringCertificate))
@@ -153,9 +153,10 @@ func (b *backend) pathCAGenerateRoot(ctx context.Context, req *logical.Request,
 	if err != nil {
 		return logical.ErrorResponse(err.Error()), nil
 	}
-	// Handle the aliased path specifying the new issuer name as "next", but
+	// Handle the aliased paths specifying the new issuer name as "next", but
 	// only do it if its not in use.
-	if strings.HasPrefix(req.Path, "root/rotate/") && len(issuerName) == 0 {
+	isRotation := strings.HasPrefix(req.Path, "root/rotate/") || strings.HasPrefix(req.Path, "root/rotation/prepare/")
+	if isRotation && len(issuerName) == 0 {
 		// err is nil when the issuer name is in use.
 		_, err = sc.resolveIssuerReference("next")
 		if err != nil {
@@ -360,16 +361,21 @@ func (b *backend) pathIssuerSignIntermediate(ctx context.Context, req *logical.R
 		NotAfter:                  data.Get("not_after").(string),
 		NotBeforeDuration:         time.Duration(data.Get("not_before_duration").(int)) * time.Second,
 		CNValidations:             []string{"disabled"},
+		PolicyIdentifiers:         getPolicyIdentifier(data, nil),
 	}
 	*role.AllowWildcardCertificates = true
 
+	if err := validatePolicyIdentifiers(role.PolicyIdentifiers); err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
 	if cn := data.Get("common_name").(string); len(cn) == 0 {
 		role.UseCSRCommonName = true
 	}
 
 	var caErr error
 	sc := b.makeStorageContext(ctx, req.Storage)
-	signingBundle, caErr := sc.fetchCAInfo(issuerName, IssuanceUsage)
+	signingBundle, caErr := sc.fetchCAInfo(issuerName, IntermediateSigningUsage)
 	if caErr != nil {
 		switch caErr.(type) {
 		case errutil.UserError:
@@ -399,7 +405,7 @@ func (b *backend) pathIssuerSignIntermediate(ctx context.Context, req *logical.R
 		apiData: data,
 		role:    role,
 	}
-	parsedBundle, warnings, err := signCert(b, input, signingBundle, true, useCSRValues)
+	parsedBundle, warnings, err := signCert(ctx, b, input, signingBundle, true, useCSRValues)
 	if err != nil {
 		switch err.(type) {
 		case errutil.UserError:
@@ -542,7 +548,7 @@ func (b *backend) pathIssuerSignSelfIssued(ctx context.Context, req *logical.Req
 
 	var caErr error
 	sc := b.makeStorageContext(ctx, req.Storage)
-	signingBundle, caErr := sc.fetchCAInfo(issuerName, IssuanceUsage)
+	signingBundle, caErr := sc.fetchCAInfo(issuerName, IntermediateSigningUsage)
 	if caErr != nil {
 		switch caErr.(type) {
 		case errutil.UserError:
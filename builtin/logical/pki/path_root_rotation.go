@@ -0,0 +1,598 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const storageRootRotation = "config/root-rotation"
+
+// rootRotationEntry tracks a root rotation from its preparation until the
+// new root is the default issuer and the rotation is cleared.
+type rootRotationEntry struct {
+	OldIssuerId        issuerID   `json:"old_issuer_id"`
+	NewIssuerId        issuerID   `json:"new_issuer_id"`
+	CrossSignedIssuers []issuerID `json:"cross_signed_issuers"`
+	Flipped            bool       `json:"flipped"`
+	LastModified       time.Time  `json:"last_modified"`
+}
+
+func (sc *storageContext) getRootRotation() (*rootRotationEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, storageRootRotation)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	rotation := &rootRotationEntry{}
+	if err := entry.DecodeJSON(rotation); err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("unable to decode root rotation: %v", err)}
+	}
+
+	return rotation, nil
+}
+
+func (sc *storageContext) setRootRotation(rotation *rootRotationEntry) error {
+	rotation.LastModified = time.Now().UTC()
+
+	json, err := logical.StorageEntryJSON(storageRootRotation, rotation)
+	if err != nil {
+		return err
+	}
+
+	return sc.Storage.Put(sc.Context, json)
+}
+
+func pathRootRotation(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "root/rotation",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationSuffix: "root-rotation",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathRootRotationRead,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      rootRotationResponseFields(),
+					}},
+				},
+			},
+			logical.DeleteOperation: &framework.PathOperation{
+				Callback: b.pathRootRotationDelete,
+				Responses: map[int][]framework.Response{
+					http.StatusNoContent: {{
+						Description: "No Content",
+					}},
+				},
+				// Read more about why these flags are set in backend.go
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    pathRootRotationHelpSyn,
+		HelpDescription: pathRootRotationHelpDesc,
+	}
+}
+
+func pathRootRotationPrepare(b *backend) *framework.Path {
+	pattern := "root/rotation/prepare/" + framework.GenericNameRegex("exported")
+
+	displayAttrs := &framework.DisplayAttributes{
+		OperationPrefix: operationPrefixPKI,
+		OperationVerb:   "prepare",
+		OperationSuffix: "root-rotation",
+	}
+
+	ret := buildPathGenerateRoot(b, pattern, displayAttrs)
+	ret.Fields["cross_sign"] = &framework.FieldSchema{
+		Type: framework.TypeBool,
+		Description: `Whether to cross-sign the current and new roots with
+each other, so that clients trusting either root can validate
+certificates issued under the other. Defaults to false.`,
+	}
+
+	operation := ret.Operations[logical.UpdateOperation].(*framework.PathOperation)
+	operation.Callback = b.pathRootRotationPrepare
+	fields := operation.Responses[http.StatusOK][0].Fields
+	fields["old_issuer_id"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Description: `The ID of the root being rotated out`,
+		Required:    true,
+	}
+	fields["cross_signed_issuers"] = &framework.FieldSchema{
+		Type:        framework.TypeStringSlice,
+		Description: `The IDs of the cross-signed issuers created, if cross_sign was requested`,
+		Required:    false,
+	}
+
+	ret.HelpSynopsis = pathRootRotationPrepareHelpSyn
+	ret.HelpDescription = pathRootRotationPrepareHelpDesc
+	return ret
+}
+
+func pathRootRotationFlip(b *backend) *framework.Path {
+	return buildPathRootRotationDefault(b, "flip", b.pathRootRotationFlip,
+		pathRootRotationFlipHelpSyn, pathRootRotationFlipHelpDesc)
+}
+
+func pathRootRotationRollback(b *backend) *framework.Path {
+	return buildPathRootRotationDefault(b, "rollback", b.pathRootRotationRollback,
+		pathRootRotationRollbackHelpSyn, pathRootRotationRollbackHelpDesc)
+}
+
+func buildPathRootRotationDefault(b *backend, verb string, callback framework.OperationFunc, helpSyn string, helpDesc string) *framework.Path {
+	return &framework.Path{
+		Pattern: "root/rotation/" + verb,
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   verb,
+			OperationSuffix: "root-rotation",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: callback,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      rootRotationResponseFields(),
+					}},
+				},
+				// Read more about why these flags are set in backend.go
+				ForwardPerformanceStandby:   true,
+				ForwardPerformanceSecondary: true,
+			},
+		},
+
+		HelpSynopsis:    helpSyn,
+		HelpDescription: helpDesc,
+	}
+}
+
+func rootRotationResponseFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"old_issuer_id": {
+			Type:        framework.TypeString,
+			Description: `The ID of the root being rotated out`,
+			Required:    true,
+		},
+		"new_issuer_id": {
+			Type:        framework.TypeString,
+			Description: `The ID of the root being rotated in`,
+			Required:    true,
+		},
+		"cross_signed_issuers": {
+			Type:        framework.TypeStringSlice,
+			Description: `The IDs of the cross-signed issuers created during preparation`,
+			Required:    true,
+		},
+		"flipped": {
+			Type:        framework.TypeBool,
+			Description: `Whether the new root is the default issuer`,
+			Required:    true,
+		},
+		"default": {
+			Type:        framework.TypeString,
+			Description: `The ID of the current default issuer`,
+			Required:    true,
+		},
+		"old_root_only_certificates": {
+			Type:        framework.TypeStringSlice,
+			Description: `Serial numbers of valid certificates which chain to the old root but not to the new one`,
+			Required:    false,
+		},
+		"last_modified": {
+			Type:        framework.TypeString,
+			Description: `The time of the last change to the rotation`,
+			Required:    true,
+		},
+	}
+}
+
+func (b *backend) pathRootRotationRead(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("Cannot read root rotation until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	rotation, err := sc.getRootRotation()
+	if err != nil {
+		return nil, err
+	}
+	if rotation == nil {
+		return nil, nil
+	}
+
+	resp, err := b.formatRootRotationResponse(sc, rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	serials, err := sc.findOldRootOnlyCertificates(rotation)
+	if err != nil {
+		return nil, err
+	}
+	resp.Data["old_root_only_certificates"] = serials
+
+	return resp, nil
+}
+
+func (b *backend) pathRootRotationDelete(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	if err := req.Storage.Delete(ctx, storageRootRotation); err != nil {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func (b *backend) pathRootRotationPrepare(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("Cannot rotate root until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	rotation, err := sc.getRootRotation()
+	if err != nil {
+		return nil, err
+	}
+	if rotation != nil && !rotation.Flipped {
+		return logical.ErrorResponse("a root rotation from issuer %v to issuer %v is already prepared; flip or delete it before preparing another", rotation.OldIssuerId, rotation.NewIssuerId), nil
+	}
+
+	issuersConfig, err := sc.getIssuersConfig()
+	if err != nil {
+		return nil, err
+	}
+	oldIssuerId := issuersConfig.DefaultIssuerId
+	if len(oldIssuerId) == 0 {
+		return logical.ErrorResponse("no default issuer is set; use root/generate to create the first root instead"), nil
+	}
+	oldIssuer, err := sc.fetchIssuerById(oldIssuerId)
+	if err != nil {
+		return nil, err
+	}
+	oldCert, err := oldIssuer.GetCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing default issuer certificate: %w", err)
+	}
+	if !bytes.Equal(oldCert.RawIssuer, oldCert.RawSubject) || oldCert.CheckSignatureFrom(oldCert) != nil {
+		return logical.ErrorResponse("default issuer %v is not a root; only roots can be rotated", oldIssuerId), nil
+	}
+
+	crossSign := data.Get("cross_sign").(bool)
+	if crossSign && len(oldIssuer.KeyID) == 0 {
+		return logical.ErrorResponse("default issuer %v has no key; it can't cross-sign the new root", oldIssuerId), nil
+	}
+
+	resp, err := b.pathCAGenerateRoot(ctx, req, data)
+	if err != nil || resp == nil || resp.IsError() {
+		return resp, err
+	}
+	newIssuerId := resp.Data["issuer_id"].(issuerID)
+	resp.Data["old_issuer_id"] = oldIssuerId
+
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	rotation = &rootRotationEntry{
+		OldIssuerId:        oldIssuerId,
+		NewIssuerId:        newIssuerId,
+		CrossSignedIssuers: []issuerID{},
+	}
+
+	newIssuer, err := sc.fetchIssuerById(newIssuerId)
+	if err != nil {
+		return nil, err
+	}
+	newCert, err := newIssuer.GetCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing new root certificate: %w", err)
+	}
+
+	// Roots of the same subject can't be cross-signed; rather than failing
+	// once the new root exists, prepare the rotation without cross-signing.
+	if crossSign && bytes.Equal(oldCert.RawSubject, newCert.RawSubject) {
+		crossSign = false
+		resp.AddWarning("The roots weren't cross-signed, as the new root has the same subject as the old one; use a distinct subject to cross-sign them.")
+	}
+
+	if crossSign {
+		for _, pair := range [][2]*issuerEntry{{oldIssuer, newIssuer}, {newIssuer, oldIssuer}} {
+			crossSigned, err := sc.crossSignIssuer(pair[0], pair[1].ID)
+			if err != nil {
+				switch err.(type) {
+				case errutil.UserError:
+					return logical.ErrorResponse(fmt.Sprintf("new root %v was generated, but cross-signing failed: %v", newIssuerId, err)), nil
+				default:
+					return nil, fmt.Errorf("new root %v was generated, but cross-signing failed: %w", newIssuerId, err)
+				}
+			}
+			rotation.CrossSignedIssuers = append(rotation.CrossSignedIssuers, crossSigned.ID)
+		}
+		resp.Data["cross_signed_issuers"] = rotation.CrossSignedIssuers
+
+		warnings, err := b.crlBuilder.rebuild(sc, true)
+		if err != nil {
+			return nil, err
+		}
+		for index, warning := range warnings {
+			resp.AddWarning(fmt.Sprintf("Warning %d during CRL rebuild: %v", index+1, warning))
+		}
+	}
+
+	// With default_follows_latest_issuer, generating the root already made
+	// it the default.
+	issuersConfig, err = sc.getIssuersConfig()
+	if err != nil {
+		return nil, err
+	}
+	if issuersConfig.DefaultIssuerId == newIssuerId {
+		rotation.Flipped = true
+		resp.AddWarning("The new root is already the default issuer, as default_follows_latest_issuer is set; use root/rotation/rollback to restore the old root as the default.")
+	}
+
+	if err := sc.setRootRotation(rotation); err != nil {
+		return nil, fmt.Errorf("new root %v was generated, but the rotation couldn't be saved: %w", newIssuerId, err)
+	}
+
+	return resp, nil
+}
+
+func (b *backend) pathRootRotationFlip(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	return b.setRootRotationDefault(ctx, req, true)
+}
+
+func (b *backend) pathRootRotationRollback(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	return b.setRootRotationDefault(ctx, req, false)
+}
+
+// setRootRotationDefault makes either the new root (when flipping) or the old
+// root (when rolling back) the default issuer, in a single write of the
+// issuers configuration.
+func (b *backend) setRootRotationDefault(ctx context.Context, req *logical.Request, flip bool) (*logical.Response, error) {
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	if b.useLegacyBundleCaStorage() {
+		return logical.ErrorResponse("Cannot rotate root until migration has completed"), nil
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	rotation, err := sc.getRootRotation()
+	if err != nil {
+		return nil, err
+	}
+	if rotation == nil {
+		return logical.ErrorResponse("no root rotation is in progress; use root/rotation/prepare to start one"), nil
+	}
+	if rotation.Flipped == flip {
+		if flip {
+			return logical.ErrorResponse("the new root %v is already the default issuer", rotation.NewIssuerId), nil
+		}
+		return logical.ErrorResponse("the root rotation hasn't been flipped; nothing to roll back"), nil
+	}
+
+	target := rotation.OldIssuerId
+	if flip {
+		target = rotation.NewIssuerId
+	}
+	if _, err := sc.fetchIssuerById(target); err != nil {
+		return logical.ErrorResponse("unable to fetch issuer %v: %v", target, err), nil
+	}
+
+	config, err := sc.getIssuersConfig()
+	if err != nil {
+		return nil, err
+	}
+	config.DefaultIssuerId = target
+	if err := sc.setIssuersConfig(config); err != nil {
+		return nil, fmt.Errorf("error updating issuer configuration: %w", err)
+	}
+
+	rotation.Flipped = flip
+	if err := sc.setRootRotation(rotation); err != nil {
+		return nil, fmt.Errorf("default issuer was changed to %v, but the rotation couldn't be saved: %w", target, err)
+	}
+
+	return b.formatRootRotationResponse(sc, rotation)
+}
+
+func (b *backend) formatRootRotationResponse(sc *storageContext, rotation *rootRotationEntry) (*logical.Response, error) {
+	config, err := sc.getIssuersConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"old_issuer_id":        rotation.OldIssuerId,
+			"new_issuer_id":        rotation.NewIssuerId,
+			"cross_signed_issuers": rotation.CrossSignedIssuers,
+			"flipped":              rotation.Flipped,
+			"default":              config.DefaultIssuerId,
+			"last_modified":        rotation.LastModified.Format(time.RFC3339Nano),
+		},
+	}, nil
+}
+
+// findOldRootOnlyCertificates returns the serial numbers of stored, valid
+// leaf certificates whose issuers chain to the rotation's old root, but not
+// to its new root, through any of the mount's issuers.
+func (sc *storageContext) findOldRootOnlyCertificates(rotation *rootRotationEntry) ([]string, error) {
+	issuerIDCertMap, err := fetchIssuerMapForRevocationChecking(sc)
+	if err != nil {
+		return nil, err
+	}
+	oldCert, present := issuerIDCertMap[rotation.OldIssuerId]
+	if !present {
+		return nil, fmt.Errorf("old root %v of the rotation no longer exists", rotation.OldIssuerId)
+	}
+	newCert, present := issuerIDCertMap[rotation.NewIssuerId]
+	if !present {
+		return nil, fmt.Errorf("new root %v of the rotation no longer exists", rotation.NewIssuerId)
+	}
+
+	// Issuers sharing a subject and key are interchangeable when building
+	// chains, so walk between those identities rather than issuers.
+	identity := func(cert *x509.Certificate) string {
+		return string(cert.RawSubject) + string(cert.RawSubjectPublicKeyInfo)
+	}
+	parents := map[string][]string{}
+	for _, child := range issuerIDCertMap {
+		for _, parent := range issuerIDCertMap {
+			if bytes.Equal(child.RawIssuer, parent.RawSubject) && child.CheckSignatureFrom(parent) == nil {
+				parents[identity(child)] = append(parents[identity(child)], identity(parent))
+			}
+		}
+	}
+	reaches := func(from string, target string) bool {
+		seen := map[string]bool{}
+		pending := []string{from}
+		for len(pending) > 0 {
+			current := pending[0]
+			pending = pending[1:]
+			if current == target {
+				return true
+			}
+			if seen[current] {
+				continue
+			}
+			seen[current] = true
+			pending = append(pending, parents[current]...)
+		}
+		return false
+	}
+	oldRootOnly := map[issuerID]bool{}
+	for id, cert := range issuerIDCertMap {
+		oldRootOnly[id] = reaches(identity(cert), identity(oldCert)) && !reaches(identity(cert), identity(newCert))
+	}
+
+	revoked, err := sc.listRevokedCerts()
+	if err != nil {
+		return nil, err
+	}
+	revokedSet := make(map[string]bool, len(revoked))
+	for _, serial := range revoked {
+		revokedSet[serial] = true
+	}
+
+	entries, err := sc.Storage.List(sc.Context, "certs/")
+	if err != nil {
+		return nil, err
+	}
+
+	serials := []string{}
+	now := time.Now()
+	for _, serial := range entries {
+		if revokedSet[serial] {
+			continue
+		}
+
+		certEntry, err := sc.Storage.Get(sc.Context, "certs/"+serial)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching certificate %q: %w", serial, err)
+		}
+		if certEntry == nil {
+			continue
+		}
+		cert, err := x509.ParseCertificate(certEntry.Value)
+		if err != nil {
+			sc.Backend.Logger().Warn("skipping unparseable certificate in root rotation report", "serial", serial, "error", err)
+			continue
+		}
+		if cert.IsCA || cert.NotAfter.Before(now) {
+			continue
+		}
+
+		var issuerId issuerID
+		metadata, err := sc.fetchCertMetadata(serial)
+		if err != nil {
+			return nil, err
+		}
+		if metadata != nil {
+			issuerId = metadata.IssuerId
+		}
+		if _, present := issuerIDCertMap[issuerId]; !present {
+			issuerId = ""
+			for id, issuerCert := range issuerIDCertMap {
+				if bytes.Equal(cert.RawIssuer, issuerCert.RawSubject) && cert.CheckSignatureFrom(issuerCert) == nil {
+					issuerId = id
+					break
+				}
+			}
+		}
+
+		if oldRootOnly[issuerId] {
+			serials = append(serials, denormalizeSerial(serial))
+		}
+	}
+	sort.Strings(serials)
+
+	return serials, nil
+}
+
+const pathRootRotationHelpSyn = `Read or clear the state of a root rotation.`
+
+const pathRootRotationHelpDesc = `
+Reading this endpoint returns the root rotation in progress, if any: the old
+and new roots, the cross-signed issuers created while preparing it, whether
+the new root is the default issuer yet, and the serial numbers of the stored,
+valid leaf certificates which chain to the old root but not to the new one.
+Finding the latter reads every stored certificate.
+
+Deleting the rotation only forgets it; no issuer is removed and the default
+issuer is left unchanged.
+`
+
+const pathRootRotationPrepareHelpSyn = `Generate the next root to rotate the default root to.`
+
+const pathRootRotationPrepareHelpDesc = `
+This endpoint takes the same parameters as root/rotate, generating a new root
+(named "next" when no name is given and that name is free), and starts a
+rotation from the current default issuer, which must be a root, to it. With
+cross_sign, each root is also cross-signed by the other, so clients trusting
+either one can validate certificates issued under both.
+
+The default issuer isn't changed; use root/rotation/flip once clients trust
+the new root.
+`
+
+const pathRootRotationFlipHelpSyn = `Make the new root of the rotation the default issuer.`
+
+const pathRootRotationFlipHelpDesc = `
+This endpoint makes the new root of the prepared rotation the default issuer,
+in a single update of the issuers configuration. Use root/rotation/rollback to
+restore the old root as the default.
+`
+
+const pathRootRotationRollbackHelpSyn = `Restore the old root of the rotation as the default issuer.`
+
+const pathRootRotationRollbackHelpDesc = `
+This endpoint undoes root/rotation/flip, making the old root of the rotation
+the default issuer again. The new root is kept, so the rotation can be flipped
+again later.
+`
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/x509"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_RootRotation(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	oldIssuerId := resp.Data["issuer_id"].(issuerID)
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "old.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	oldLeafSerial := resp.Data["serial_number"].(string)
+
+	resp, err = CBRead(b, s, "root/rotation")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	_, err = CBWrite(b, s, "root/rotation/flip", map[string]interface{}{})
+	require.ErrorContains(t, err, "no root rotation is in progress")
+
+	resp, err = CBWrite(b, s, "root/rotation/prepare/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"cross_sign":  true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/rotation/prepare/internal")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("root/rotation/prepare/internal"), logical.UpdateOperation), resp, true)
+	require.Equal(t, "next", resp.Data["issuer_name"])
+	require.Equal(t, oldIssuerId, resp.Data["old_issuer_id"])
+	require.Empty(t, resp.Data["cross_signed_issuers"])
+	require.Contains(t, resp.Warnings, "The roots weren't cross-signed, as the new root has the same subject as the old one; use a distinct subject to cross-sign them.")
+	newIssuerId := resp.Data["issuer_id"].(issuerID)
+	newRootPem := resp.Data["certificate"].(string)
+
+	_, err = CBWrite(b, s, "root/rotation/prepare/internal", map[string]interface{}{
+		"common_name": "root example.com",
+	})
+	require.ErrorContains(t, err, "is already prepared")
+
+	// Certificates issued by the old root don't chain to the new one.
+	resp, err = CBRead(b, s, "root/rotation")
+	requireSuccessNonNilResponse(t, resp, err, "root/rotation")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("root/rotation"), logical.ReadOperation), resp, true)
+	require.Equal(t, newIssuerId, resp.Data["new_issuer_id"])
+	require.Equal(t, oldIssuerId, resp.Data["default"])
+	require.False(t, resp.Data["flipped"].(bool))
+	require.Equal(t, []string{oldLeafSerial}, resp.Data["old_root_only_certificates"])
+
+	_, err = CBWrite(b, s, "root/rotation/rollback", map[string]interface{}{})
+	require.ErrorContains(t, err, "nothing to roll back")
+
+	resp, err = CBWrite(b, s, "root/rotation/flip", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "root/rotation/flip")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("root/rotation/flip"), logical.UpdateOperation), resp, true)
+	require.True(t, resp.Data["flipped"].(bool))
+	require.Equal(t, newIssuerId, resp.Data["default"])
+
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "new.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	require.Equal(t, newRootPem, resp.Data["issuing_ca"])
+
+	resp, err = CBWrite(b, s, "root/rotation/rollback", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "root/rotation/rollback")
+	require.False(t, resp.Data["flipped"].(bool))
+	resp, err = CBRead(b, s, "config/issuers")
+	requireSuccessNonNilResponse(t, resp, err, "config/issuers")
+	require.Equal(t, oldIssuerId, resp.Data["default"])
+
+	resp, err = CBDelete(b, s, "root/rotation")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+	resp, err = CBRead(b, s, "root/rotation")
+	require.NoError(t, err)
+	require.Nil(t, resp)
+}
+
+func TestPki_RootRotationCrossSign(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+	oldCert := parseCert(t, resp.Data["certificate"].(string))
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "old.example.com",
+		"ttl":         "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+
+	resp, err = CBWrite(b, s, "root/rotation/prepare/internal", map[string]interface{}{
+		"common_name": "root x2 example.com",
+		"key_type":    "ec",
+		"cross_sign":  true,
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/rotation/prepare/internal")
+	newCert := parseCert(t, resp.Data["certificate"].(string))
+	crossSigned := resp.Data["cross_signed_issuers"].([]issuerID)
+	require.Len(t, crossSigned, 2)
+
+	// Each root is cross-signed by the other, keeping its subject and key.
+	for index, pair := range [][2]*x509.Certificate{{oldCert, newCert}, {newCert, oldCert}} {
+		resp, err = CBRead(b, s, "issuer/"+string(crossSigned[index]))
+		requireSuccessNonNilResponse(t, resp, err, "issuer")
+		cert := parseCert(t, resp.Data["certificate"].(string))
+
+		require.Equal(t, pair[0].RawSubjectPublicKeyInfo, cert.RawSubjectPublicKeyInfo)
+		require.Equal(t, pair[0].RawSubject, cert.RawSubject)
+		require.NoError(t, cert.CheckSignatureFrom(pair[1]))
+	}
+
+	// Through the cross-signed old root, every certificate chains to the
+	// new root as well.
+	resp, err = CBRead(b, s, "root/rotation")
+	requireSuccessNonNilResponse(t, resp, err, "root/rotation")
+	require.Empty(t, resp.Data["old_root_only_certificates"])
+}
@@ -0,0 +1,505 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	scepCaCertContentType   = "application/x-x509-ca-cert"
+	scepCaRaCertContentType = "application/x-x509-ca-ra-cert"
+	scepPkiMessageType      = "application/x-pki-message"
+	scepMaximumRequestSize  = 64 * 1024
+
+	pathScepHelpSyn  = `An endpoint implementing the SCEP enrollment protocol`
+	pathScepHelpDesc = `This API endpoint implements the GetCACaps, GetCACert and
+PKIOperation operations of the SCEP protocol defined in RFC 8894, selected by
+the operation query parameter. Requests and responses use the CMS formats
+mandated by the RFC rather than conventional Vault JSON, so a SCEP client
+should be used to interact with these endpoints.
+
+The unlabeled scep endpoint follows the default_path_policy of config/scep,
+while scep/<label> endpoints follow the policy configured for that label in
+label_to_path_policy. Initial enrollment requests are authenticated by the
+configured challenge password; renewal requests by being signed with a
+current certificate issued by this mount.`
+)
+
+// SCEP message attributes and values, RFC 8894 Section 3.2.1.
+var (
+	oidScepMessageType    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 2}
+	oidScepPkiStatus      = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 3}
+	oidScepFailInfo       = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 4}
+	oidScepSenderNonce    = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 5}
+	oidScepRecipientNonce = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 6}
+	oidScepTransactionID  = asn1.ObjectIdentifier{2, 16, 840, 1, 113733, 1, 9, 7}
+
+	oidChallengePassword = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 7}
+)
+
+const (
+	scepMessageTypeCertRep    = "3"
+	scepMessageTypeRenewalReq = "17"
+	scepMessageTypePKCSReq    = "19"
+
+	scepPkiStatusSuccess = "0"
+	scepPkiStatusFailure = "2"
+
+	scepFailInfoBadMessageCheck = "1"
+	scepFailInfoBadRequest      = "2"
+)
+
+// The capabilities advertised by GetCACaps; "AES" refers to AES-128-CBC.
+var scepCaCaps = []string{
+	"AES",
+	"POSTPKIOperation",
+	"Renewal",
+	"SCEPStandard",
+	"SHA-256",
+	"SHA-512",
+}
+
+func setupScepPaths(b *backend, scepPattern string, unauthPattern string) {
+	b.Backend.Paths = append(b.Backend.Paths, pathScep(b, scepPattern))
+
+	// SCEP requests are authenticated by the challenge password or the
+	// certificate being renewed rather than a Vault token.
+	b.PathsSpecial.Unauthenticated = append(b.PathsSpecial.Unauthenticated, unauthPattern)
+}
+
+func pathScep(b *backend, pattern string) *framework.Path {
+	fields := map[string]*framework.FieldSchema{
+		"operation": {
+			Type:        framework.TypeString,
+			Description: `The SCEP operation: GetCACaps, GetCACert or PKIOperation`,
+			Query:       true,
+		},
+		"message": {
+			Type:        framework.TypeString,
+			Description: `The base64 encoded PKIOperation message, for GET requests`,
+			Query:       true,
+		},
+	}
+	if strings.Contains(pattern, framework.GenericNameRegex("label")) {
+		fields["label"] = &framework.FieldSchema{
+			Type:        framework.TypeString,
+			Description: `The SCEP label, mapped to a path policy by label_to_path_policy in config/scep`,
+			Required:    true,
+		}
+	}
+
+	return &framework.Path{
+		Pattern: pattern,
+		Fields:  fields,
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.pathScepOperation,
+			},
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathScepOperation,
+			},
+		},
+
+		HelpSynopsis:    pathScepHelpSyn,
+		HelpDescription: pathScepHelpDesc,
+	}
+}
+
+func (b *backend) pathScepOperation(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, role, issuer, err := getScepRoleAndIssuer(sc, data)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	// POST bodies carry the binary message, so the operation is only
+	// available from the query string of the original request.
+	operation := data.Get("operation").(string)
+	if operation == "" && req.HTTPRequest != nil {
+		operation = req.HTTPRequest.URL.Query().Get("operation")
+	}
+
+	switch operation {
+	case "GetCACaps":
+		return &logical.Response{
+			Data: map[string]interface{}{
+				logical.HTTPContentType: "text/plain",
+				logical.HTTPStatusCode:  http.StatusOK,
+				logical.HTTPRawBody:     []byte(strings.Join(scepCaCaps, "\n")),
+			},
+		}, nil
+	case "GetCACert":
+		return getScepCaCert(sc, config, issuer)
+	case "PKIOperation":
+		message, err := getScepMessage(req, data)
+		if err != nil {
+			return logical.ErrorResponse("failed reading PKIOperation message: %v", err), nil
+		}
+		return b.scepPkiOperation(sc, req, config, role, issuer, message)
+	default:
+		return logical.ErrorResponse("unsupported SCEP operation %q", operation), nil
+	}
+}
+
+func getScepRoleAndIssuer(sc *storageContext, data *framework.FieldData) (*scepConfigEntry, *roleEntry, *issuerEntry, error) {
+	config, err := sc.getScepConfig()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if !config.Enabled {
+		return nil, nil, nil, errutil.UserError{Err: "SCEP is disabled on this mount"}
+	}
+
+	policy := config.DefaultPathPolicy
+	if labelRaw, ok := data.GetOk("label"); ok {
+		label := labelRaw.(string)
+		policy, ok = config.LabelToPathPolicy[label]
+		if !ok {
+			return nil, nil, nil, errutil.UserError{Err: fmt.Sprintf("unknown SCEP label %q", label)}
+		}
+	}
+
+	role, issuer, err := getEnrollmentRoleAndIssuer(sc, "SCEP", policy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return config, role, issuer, nil
+}
+
+// getScepRA returns the certificate and key used to decrypt requests and sign
+// responses: the configured registration authority, or the issuer itself.
+func getScepRA(sc *storageContext, config *scepConfigEntry, issuer *issuerEntry) (*x509.Certificate, *rsa.PrivateKey, error) {
+	if config.RAKeyID != "" {
+		return loadScepRA(sc, config)
+	}
+
+	signingBundle, err := sc.fetchCAInfoByIssuerId(issuer.ID, IssuanceUsage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed loading CA %s: %w", issuer.ID.String(), err)
+	}
+
+	rsaKey, ok := signingBundle.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errutil.UserError{Err: "SCEP requires either an RSA issuer or an RSA registration authority configured in config/scep"}
+	}
+
+	return signingBundle.Certificate, rsaKey, nil
+}
+
+func getScepCaCert(sc *storageContext, config *scepConfigEntry, issuer *issuerEntry) (*logical.Response, error) {
+	var chain []byte
+	for _, certPem := range issuer.CAChain {
+		block, _ := pem.Decode([]byte(certPem))
+		if block == nil {
+			return nil, fmt.Errorf("failed decoding CA chain of issuer %v", issuer.ID)
+		}
+		chain = append(chain, block.Bytes...)
+	}
+
+	// A lone CA is returned as a bare certificate; otherwise the RA (if
+	// any) and the CA chain are returned as a degenerate PKCS#7.
+	if config.RACertificate == "" && len(issuer.CAChain) == 1 {
+		return &logical.Response{
+			Data: map[string]interface{}{
+				logical.HTTPContentType: scepCaCertContentType,
+				logical.HTTPStatusCode:  http.StatusOK,
+				logical.HTTPRawBody:     chain,
+			},
+		}, nil
+	}
+
+	if config.RACertificate != "" {
+		block, _ := pem.Decode([]byte(config.RACertificate))
+		if block == nil {
+			return nil, fmt.Errorf("failed decoding SCEP RA certificate")
+		}
+		chain = append(append([]byte{}, block.Bytes...), chain...)
+	}
+
+	p7, err := pkcs7.DegenerateCertificate(chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed building PKCS#7 response: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: scepCaRaCertContentType,
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     p7,
+		},
+	}, nil
+}
+
+func getScepMessage(req *logical.Request, data *framework.FieldData) ([]byte, error) {
+	switch req.Operation {
+	case logical.ReadOperation:
+		message := data.Get("message").(string)
+		if message == "" {
+			return nil, errors.New("no message parameter was provided")
+		}
+		if len(message) >= scepMaximumRequestSize {
+			return nil, errors.New("request is too large")
+		}
+		return base64.StdEncoding.DecodeString(message)
+	default:
+		// NOTE: Writing an empty update request to Vault causes a nil request.HTTPRequest, and that object
+		//       says that it is possible for its Body element to be nil as well, so check both just in case.
+		if req.HTTPRequest == nil || req.HTTPRequest.Body == nil {
+			return nil, fmt.Errorf("no data in request body; the Content-Type must be %s", scepPkiMessageType)
+		}
+		defer req.HTTPRequest.Body.Close()
+
+		message, err := io.ReadAll(io.LimitReader(req.HTTPRequest.Body, scepMaximumRequestSize))
+		if err != nil {
+			return nil, err
+		}
+		if len(message) >= scepMaximumRequestSize {
+			return nil, errors.New("request is too large")
+		}
+		return message, nil
+	}
+}
+
+func (b *backend) scepPkiOperation(sc *storageContext, req *logical.Request, config *scepConfigEntry, role *roleEntry, issuer *issuerEntry, message []byte) (*logical.Response, error) {
+	raCert, raKey, err := getScepRA(sc, config, issuer)
+	if err != nil {
+		return enrollmentErrorResponse(err)
+	}
+
+	p7, err := pkcs7.Parse(message)
+	if err != nil {
+		return logical.ErrorResponse("failed parsing PKIOperation message: %v", err), nil
+	}
+
+	signer := p7.GetOnlySigner()
+	if signer == nil {
+		return logical.ErrorResponse("PKIOperation message must have exactly one signer"), nil
+	}
+
+	var messageType, transactionID string
+	var senderNonce []byte
+	if err := p7.UnmarshalSignedAttribute(oidScepMessageType, &messageType); err != nil {
+		return logical.ErrorResponse("PKIOperation message is missing its messageType: %v", err), nil
+	}
+	if err := p7.UnmarshalSignedAttribute(oidScepTransactionID, &transactionID); err != nil {
+		return logical.ErrorResponse("PKIOperation message is missing its transactionID: %v", err), nil
+	}
+	if err := p7.UnmarshalSignedAttribute(oidScepSenderNonce, &senderNonce); err != nil {
+		return logical.ErrorResponse("PKIOperation message is missing its senderNonce: %v", err), nil
+	}
+
+	// From here on, failures are reported to the client as signed CertRep
+	// messages so that it can correlate them with its transaction.
+	reply := &scepReply{
+		raCert:        raCert,
+		raKey:         raKey,
+		recipient:     signer,
+		transactionID: transactionID,
+		senderNonce:   senderNonce,
+	}
+
+	if err := p7.Verify(); err != nil {
+		return reply.failure(scepFailInfoBadMessageCheck)
+	}
+
+	envelope, err := pkcs7.Parse(p7.Content)
+	if err != nil {
+		return reply.failure(scepFailInfoBadMessageCheck)
+	}
+
+	csrDer, err := envelope.Decrypt(raCert, raKey)
+	if err != nil {
+		return reply.failure(scepFailInfoBadMessageCheck)
+	}
+	reply.encryptionAlg = getScepContentEncryptionAlgorithm(p7.Content)
+
+	csr, err := x509.ParseCertificateRequest(csrDer)
+	if err != nil || csr.CheckSignature() != nil {
+		return reply.failure(scepFailInfoBadRequest)
+	}
+
+	switch messageType {
+	case scepMessageTypePKCSReq:
+		challenge, err := getCsrChallengePassword(csr)
+		if err != nil || config.ChallengePassword == "" ||
+			subtle.ConstantTimeCompare([]byte(challenge), []byte(config.ChallengePassword)) != 1 {
+			return reply.failure(scepFailInfoBadRequest)
+		}
+	case scepMessageTypeRenewalReq:
+		// RFC 8894 Section 3.3.1.2: renewal requests are signed with the
+		// current certificate and must keep its subject.
+		if err := verifyEnrollmentCertificate(sc, signer, issuer); err != nil {
+			if errors.Is(err, logical.ErrPermissionDenied) {
+				return reply.failure(scepFailInfoBadRequest)
+			}
+			return nil, err
+		}
+		if err := validateReenrollCsr(signer, csr); err != nil {
+			return reply.failure(scepFailInfoBadRequest)
+		}
+	default:
+		return reply.failure(scepFailInfoBadRequest)
+	}
+
+	parsedBundle, err := b.signEnrollmentCsr(sc, req, role, issuer, csr, false)
+	if err != nil {
+		var userErr errutil.UserError
+		if errors.As(err, &userErr) {
+			b.Logger().Debug("refusing to sign SCEP request", "transaction_id", transactionID, "error", err)
+			return reply.failure(scepFailInfoBadRequest)
+		}
+		return nil, err
+	}
+
+	return reply.success(parsedBundle.CertificateBytes)
+}
+
+type scepReply struct {
+	raCert        *x509.Certificate
+	raKey         *rsa.PrivateKey
+	recipient     *x509.Certificate
+	encryptionAlg int
+	transactionID string
+	senderNonce   []byte
+}
+
+func (r *scepReply) success(cert []byte) (*logical.Response, error) {
+	certsOnly, err := pkcs7.DegenerateCertificate(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed building PKCS#7 response: %w", err)
+	}
+
+	envelope, err := pkcs7.EncryptWithAlgorithm(certsOnly, []*x509.Certificate{r.recipient}, r.encryptionAlg)
+	if err != nil {
+		return nil, fmt.Errorf("failed encrypting SCEP response: %w", err)
+	}
+
+	return r.certRep(envelope, scepPkiStatusSuccess, "")
+}
+
+func (r *scepReply) failure(failInfo string) (*logical.Response, error) {
+	return r.certRep(nil, scepPkiStatusFailure, failInfo)
+}
+
+func (r *scepReply) certRep(content []byte, pkiStatus string, failInfo string) (*logical.Response, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	attrs := []pkcs7.Attribute{
+		{Type: oidScepMessageType, Value: scepMessageTypeCertRep},
+		{Type: oidScepPkiStatus, Value: pkiStatus},
+		{Type: oidScepTransactionID, Value: r.transactionID},
+		{Type: oidScepSenderNonce, Value: nonce},
+		{Type: oidScepRecipientNonce, Value: r.senderNonce},
+	}
+	if failInfo != "" {
+		attrs = append(attrs, pkcs7.Attribute{Type: oidScepFailInfo, Value: failInfo})
+	}
+
+	sd, err := pkcs7.NewSignedData(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sd.AddSigner(r.raCert, r.raKey, pkcs7.SignerInfoConfig{ExtraSignedAttributes: attrs}); err != nil {
+		return nil, fmt.Errorf("failed signing SCEP response: %w", err)
+	}
+
+	signed, err := sd.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed signing SCEP response: %w", err)
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: scepPkiMessageType,
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     signed,
+		},
+	}, nil
+}
+
+// getScepContentEncryptionAlgorithm picks the algorithm to encrypt the
+// response with, mirroring the one used by the client's request envelope
+// when supported, and otherwise falling back to AES-128-CBC.
+func getScepContentEncryptionAlgorithm(envelope []byte) int {
+	var info struct {
+		ContentType asn1.ObjectIdentifier
+		Content     struct {
+			Version        int
+			RecipientInfos asn1.RawValue
+			Encrypted      struct {
+				ContentType      asn1.ObjectIdentifier
+				Algorithm        pkix.AlgorithmIdentifier
+				EncryptedContent asn1.RawValue `asn1:"optional,tag:0"`
+			}
+		} `asn1:"explicit,tag:0"`
+	}
+
+	if _, err := asn1.Unmarshal(envelope, &info); err == nil {
+		switch {
+		case info.Content.Encrypted.Algorithm.Algorithm.Equal(pkcs7.OIDEncryptionAlgorithmAES256CBC):
+			return pkcs7.EncryptionAlgorithmAES256CBC
+		case info.Content.Encrypted.Algorithm.Algorithm.Equal(pkcs7.OIDEncryptionAlgorithmDESCBC):
+			return pkcs7.EncryptionAlgorithmDESCBC
+		}
+	}
+
+	return pkcs7.EncryptionAlgorithmAES128CBC
+}
+
+// getCsrChallengePassword extracts the PKCS#9 challengePassword attribute of
+// a CSR, which the standard library does not expose.
+func getCsrChallengePassword(csr *x509.CertificateRequest) (string, error) {
+	var tbs struct {
+		Version    int
+		Subject    asn1.RawValue
+		PublicKey  asn1.RawValue
+		Attributes []struct {
+			Type   asn1.ObjectIdentifier
+			Values []asn1.RawValue `asn1:"set"`
+		} `asn1:"tag:0"`
+	}
+
+	if _, err := asn1.Unmarshal(csr.RawTBSCertificateRequest, &tbs); err != nil {
+		return "", err
+	}
+
+	for _, attr := range tbs.Attributes {
+		if !attr.Type.Equal(oidChallengePassword) || len(attr.Values) == 0 {
+			continue
+		}
+
+		var challenge string
+		if _, err := asn1.Unmarshal(attr.Values[0].FullBytes, &challenge); err != nil {
+			return "", fmt.Errorf("failed decoding challengePassword: %w", err)
+		}
+		return challenge, nil
+	}
+
+	return "", errors.New("no challengePassword attribute present")
+}
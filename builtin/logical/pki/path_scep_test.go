@@ -0,0 +1,313 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScepConfig(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBRead(b, s, "config/scep")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, false, resp.Data["enabled"])
+	require.Equal(t, "sign-verbatim", resp.Data["default_path_policy"])
+	require.Equal(t, false, resp.Data["challenge_password_set"])
+
+	resp, err = CBWrite(b, s, "config/scep", map[string]interface{}{
+		"enabled":            true,
+		"challenge_password": "secret",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, true, resp.Data["challenge_password_set"])
+	require.NotContains(t, resp.Data, "challenge_password")
+
+	// The RA certificate and key must be provided together and match.
+	_, err = CBWrite(b, s, "config/scep", map[string]interface{}{
+		"ra_key_ref": "missing",
+	})
+	require.Error(t, err)
+
+	resp, err = CBWrite(b, s, "keys/generate/internal", map[string]interface{}{
+		"key_type": "rsa",
+		"key_name": "ra",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "ra example.com",
+		"key_type":    "rsa",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	raCert := resp.Data["certificate"].(string)
+
+	_, err = CBWrite(b, s, "config/scep", map[string]interface{}{
+		"ra_certificate": raCert,
+		"ra_key_ref":     "ra",
+	})
+	require.Error(t, err, "expected mismatched RA certificate to be rejected")
+
+	_, err = CBWrite(b, s, "config/scep", map[string]interface{}{
+		"ra_certificate": raCert,
+		"ra_key_ref":     resp.Data["key_id"].(keyID).String(),
+	})
+	require.NoError(t, err)
+
+	resp, err = CBRead(b, s, "config/scep")
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, raCert, resp.Data["ra_certificate"])
+	require.Equal(t, true, resp.Data["challenge_password_set"])
+
+	_, err = CBWrite(b, s, "config/scep", map[string]interface{}{
+		"default_path_policy": "role:missing",
+	})
+	require.Error(t, err, "expected missing role to be rejected")
+}
+
+func TestScepEnrollment(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "rsa",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+
+	// Disabled by default.
+	_, err = scepRequest(b, s, "scep", "GetCACaps", nil)
+	require.Error(t, err)
+
+	_, err = CBWrite(b, s, "roles/device", map[string]interface{}{
+		"allowed_domains":  "devices.example.com",
+		"allow_subdomains": true,
+		"ttl":              "1h",
+	})
+	require.NoError(t, err)
+
+	_, err = CBWrite(b, s, "config/scep", map[string]interface{}{
+		"enabled":              true,
+		"default_path_policy":  "forbid",
+		"label_to_path_policy": map[string]string{"devices": "role:device"},
+		"challenge_password":   "secret",
+	})
+	require.NoError(t, err)
+
+	_, err = scepRequest(b, s, "scep", "GetCACaps", nil)
+	require.Error(t, err)
+
+	resp, err = scepRequest(b, s, "scep/devices", "GetCACaps", nil)
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Contains(t, strings.Split(string(resp.Data[logical.HTTPRawBody].([]byte)), "\n"), "Renewal")
+
+	resp, err = scepRequest(b, s, "scep/devices", "GetCACert", nil)
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, scepCaCertContentType, resp.Data[logical.HTTPContentType])
+	require.Equal(t, rootCert.Raw, resp.Data[logical.HTTPRawBody])
+
+	clientKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	selfSigned := scepSelfSignedCert(t, clientKey)
+
+	// A wrong challenge password is refused with a failure CertRep.
+	csr := scepCsr(t, clientKey, "one.devices.example.com", "wrong")
+	reply := scepPkiOperation(t, b, s, rootCert, selfSigned, clientKey, scepMessageTypePKCSReq, csr)
+	require.Equal(t, scepPkiStatusFailure, reply.status)
+	require.Equal(t, scepFailInfoBadRequest, reply.failInfo)
+
+	csr = scepCsr(t, clientKey, "one.devices.example.com", "secret")
+	reply = scepPkiOperation(t, b, s, rootCert, selfSigned, clientKey, scepMessageTypePKCSReq, csr)
+	require.Equal(t, scepPkiStatusSuccess, reply.status)
+	require.Len(t, reply.certs, 1)
+	leaf := reply.certs[0]
+	require.Equal(t, "one.devices.example.com", leaf.Subject.CommonName)
+	requireSignedBy(t, leaf, rootCert)
+
+	// The issued certificate must be stored.
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(leaf))
+	requireSuccessNonNilResponse(t, resp, err)
+
+	// Renewal with a self-signed certificate is refused.
+	renewCsr := scepCsr(t, clientKey, "one.devices.example.com", "")
+	reply = scepPkiOperation(t, b, s, rootCert, selfSigned, clientKey, scepMessageTypeRenewalReq, renewCsr)
+	require.Equal(t, scepPkiStatusFailure, reply.status)
+
+	reply = scepPkiOperation(t, b, s, rootCert, leaf, clientKey, scepMessageTypeRenewalReq, renewCsr)
+	require.Equal(t, scepPkiStatusSuccess, reply.status)
+	require.Len(t, reply.certs, 1)
+	require.Equal(t, leaf.Subject.CommonName, reply.certs[0].Subject.CommonName)
+	require.NotEqual(t, leaf.SerialNumber, reply.certs[0].SerialNumber)
+}
+
+type scepTestReply struct {
+	status   string
+	failInfo string
+	certs    []*x509.Certificate
+}
+
+func scepRequest(b *backend, s logical.Storage, path string, operation string, message []byte) (*logical.Response, error) {
+	data := map[string]interface{}{
+		"operation": operation,
+	}
+	if message != nil {
+		data["message"] = base64.StdEncoding.EncodeToString(message)
+	}
+
+	resp, err := CBReq(b, s, logical.ReadOperation, path, data)
+	if err == nil && resp != nil && resp.IsError() {
+		return resp, resp.Error()
+	}
+	return resp, err
+}
+
+func scepPkiOperation(t *testing.T, b *backend, s logical.Storage, caCert *x509.Certificate, clientCert *x509.Certificate, clientKey *rsa.PrivateKey, messageType string, csr []byte) scepTestReply {
+	t.Helper()
+
+	envelope, err := pkcs7.EncryptWithAlgorithm(csr, []*x509.Certificate{caCert}, pkcs7.EncryptionAlgorithmAES256CBC)
+	require.NoError(t, err)
+
+	sd, err := pkcs7.NewSignedData(envelope)
+	require.NoError(t, err)
+
+	nonce := make([]byte, 16)
+	_, err = rand.Read(nonce)
+	require.NoError(t, err)
+
+	err = sd.AddSigner(clientCert, clientKey, pkcs7.SignerInfoConfig{
+		ExtraSignedAttributes: []pkcs7.Attribute{
+			{Type: oidScepMessageType, Value: messageType},
+			{Type: oidScepTransactionID, Value: "test-transaction"},
+			{Type: oidScepSenderNonce, Value: nonce},
+		},
+	})
+	require.NoError(t, err)
+	message, err := sd.Finish()
+	require.NoError(t, err)
+
+	resp, err := scepRequest(b, s, "scep/devices", "PKIOperation", message)
+	requireSuccessNonNilResponse(t, resp, err)
+	require.Equal(t, scepPkiMessageType, resp.Data[logical.HTTPContentType])
+
+	p7, err := pkcs7.Parse(resp.Data[logical.HTTPRawBody].([]byte))
+	require.NoError(t, err)
+	require.NoError(t, p7.Verify())
+
+	var reply scepTestReply
+	var transactionID string
+	var recipientNonce []byte
+	require.NoError(t, p7.UnmarshalSignedAttribute(oidScepPkiStatus, &reply.status))
+	require.NoError(t, p7.UnmarshalSignedAttribute(oidScepTransactionID, &transactionID))
+	require.NoError(t, p7.UnmarshalSignedAttribute(oidScepRecipientNonce, &recipientNonce))
+	require.Equal(t, "test-transaction", transactionID)
+	require.Equal(t, nonce, recipientNonce)
+
+	if reply.status != scepPkiStatusSuccess {
+		require.NoError(t, p7.UnmarshalSignedAttribute(oidScepFailInfo, &reply.failInfo))
+		return reply
+	}
+
+	responseEnvelope, err := pkcs7.Parse(p7.Content)
+	require.NoError(t, err)
+	certsOnly, err := responseEnvelope.Decrypt(clientCert, clientKey)
+	require.NoError(t, err)
+
+	certs, err := pkcs7.Parse(certsOnly)
+	require.NoError(t, err)
+	reply.certs = certs.Certificates
+	return reply
+}
+
+func scepSelfSignedCert(t *testing.T, key *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "scep client"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+// scepCsr builds a CSR carrying the given challengePassword attribute, which
+// the standard library cannot encode itself.
+func scepCsr(t *testing.T, key *rsa.PrivateKey, commonName string, challenge string) []byte {
+	t.Helper()
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: commonName},
+		DNSNames: []string{commonName},
+	}, key)
+	require.NoError(t, err)
+	if challenge == "" {
+		return der
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	require.NoError(t, err)
+
+	var tbs struct {
+		Version    int
+		Subject    asn1.RawValue
+		PublicKey  asn1.RawValue
+		Attributes []asn1.RawValue `asn1:"tag:0"`
+	}
+	_, err = asn1.Unmarshal(csr.RawTBSCertificateRequest, &tbs)
+	require.NoError(t, err)
+
+	value, err := asn1.MarshalWithParams(challenge, "utf8")
+	require.NoError(t, err)
+	attr, err := asn1.Marshal(struct {
+		Type   asn1.ObjectIdentifier
+		Values []asn1.RawValue `asn1:"set"`
+	}{
+		Type:   oidChallengePassword,
+		Values: []asn1.RawValue{{FullBytes: value}},
+	})
+	require.NoError(t, err)
+	tbs.Attributes = append(tbs.Attributes, asn1.RawValue{FullBytes: attr})
+
+	rawTbs, err := asn1.Marshal(tbs)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256(rawTbs)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	der, err = asn1.Marshal(struct {
+		TBS                asn1.RawValue
+		SignatureAlgorithm pkix.AlgorithmIdentifier
+		Signature          asn1.BitString
+	}{
+		TBS:                asn1.RawValue{FullBytes: rawTbs},
+		SignatureAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}, Parameters: asn1.NullRawValue},
+		Signature:          asn1.BitString{Bytes: signature, BitLength: len(signature) * 8},
+	})
+	require.NoError(t, err)
+	return der
+}
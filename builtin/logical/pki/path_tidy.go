@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -31,8 +32,25 @@ const (
 	tidyStatusError                      = iota
 	tidyStatusCancelling                 = iota
 	tidyStatusCancelled                  = iota
+	tidyStatusPaused                     = iota
 )
 
+// Phases of the tidy operation, as reported by tidy-status.
+const (
+	tidyPhaseCertStore         = "cert_store"
+	tidyPhaseCertMetadata      = "cert_metadata"
+	tidyPhaseRevokedCerts      = "revoked_certs"
+	tidyPhaseExpiredIssuers    = "expired_issuers"
+	tidyPhaseIssuerAssocs      = "orphaned_issuer_associations"
+	tidyPhaseLegacyBundle      = "legacy_ca_bundle"
+	tidyPhaseRevocationQueue   = "revocation_queue"
+	tidyPhaseCrossRevokedCerts = "cross_revoked_certs"
+	tidyPhaseAcme              = "acme"
+)
+
+// How often a paused tidy operation checks whether it got resumed.
+const tidyPausePollInterval = 250 * time.Millisecond
+
 type tidyStatus struct {
 	// Parameters used to initiate the operation
 	safetyBuffer            int
@@ -48,6 +66,8 @@ type tidyStatus struct {
 	tidyRevocationQueue   bool
 	tidyCrossRevokedCerts bool
 	tidyAcme              bool
+	tidyCertMetadata      bool
+	tidyOrphanedAssocs    bool
 	pauseDuration         string
 
 	// Status
@@ -59,16 +79,57 @@ type tidyStatus struct {
 
 	// These counts use a custom incrementer that grab and release
 	// a lock prior to reading.
-	certStoreDeletedCount    uint
-	revokedCertDeletedCount  uint
-	missingIssuerCertCount   uint
-	revQueueDeletedCount     uint
-	crossRevokedDeletedCount uint
+	certStoreDeletedCount      uint
+	revokedCertDeletedCount    uint
+	missingIssuerCertCount     uint
+	revQueueDeletedCount       uint
+	crossRevokedDeletedCount   uint
+	certMetadataDeletedCount   uint
+	orphanedAssocsDeletedCount uint
 
 	acmeAccountsCount        uint
 	acmeAccountsRevokedCount uint
 	acmeAccountsDeletedCount uint
 	acmeOrdersDeletedCount   uint
+
+	// Progress of the current phase and durations of the finished ones.
+	// Resuming moves phaseStarted forward by the time spent paused, so
+	// it is excluded from both durations and estimates.
+	phase          string
+	phaseStarted   time.Time
+	phaseTotal     uint
+	phaseScanned   uint
+	scannedCount   uint
+	phaseDurations map[string]time.Duration
+	timePaused     time.Time
+}
+
+// endPhase records the duration of the current phase, if any; callers
+// hold tidyStatusLock.
+func (s *tidyStatus) endPhase(now time.Time) {
+	if s.phase == "" {
+		return
+	}
+
+	if s.phaseDurations == nil {
+		s.phaseDurations = map[string]time.Duration{}
+	}
+	s.phaseDurations[s.phase] = now.Sub(s.phaseStarted)
+	s.phase = ""
+}
+
+// phaseTimeRemaining estimates the time left in the current phase from its
+// progress so far, or returns false when there's nothing to go by.
+func (s *tidyStatus) phaseTimeRemaining(now time.Time) (time.Duration, bool) {
+	if s.phase == "" || s.phaseScanned == 0 || s.phaseTotal < s.phaseScanned {
+		return 0, false
+	}
+
+	if s.state == tidyStatusPaused {
+		now = s.timePaused
+	}
+	perEntry := now.Sub(s.phaseStarted) / time.Duration(s.phaseScanned)
+	return perEntry * time.Duration(s.phaseTotal-s.phaseScanned), true
 }
 
 type tidyConfig struct {
@@ -85,6 +146,8 @@ type tidyConfig struct {
 	RevocationQueue   bool `json:"tidy_revocation_queue"`
 	CrossRevokedCerts bool `json:"tidy_cross_cluster_revoked_certs"`
 	TidyAcme          bool `json:"tidy_acme"`
+	CertMetadata      bool `json:"tidy_cert_metadata"`
+	OrphanedAssocs    bool `json:"tidy_orphaned_issuer_associations"`
 
 	// Safety Buffers
 	SafetyBuffer            time.Duration `json:"safety_buffer"`
@@ -99,11 +162,11 @@ type tidyConfig struct {
 }
 
 func (tc *tidyConfig) IsAnyTidyEnabled() bool {
-	return tc.CertStore || tc.RevokedCerts || tc.IssuerAssocs || tc.ExpiredIssuers || tc.BackupBundle || tc.TidyAcme || tc.CrossRevokedCerts || tc.RevocationQueue
+	return tc.CertStore || tc.RevokedCerts || tc.IssuerAssocs || tc.ExpiredIssuers || tc.BackupBundle || tc.TidyAcme || tc.CrossRevokedCerts || tc.RevocationQueue || tc.CertMetadata || tc.OrphanedAssocs
 }
 
 func (tc *tidyConfig) AnyTidyConfig() string {
-	return "tidy_cert_store / tidy_revoked_certs / tidy_revoked_cert_issuer_associations / tidy_expired_issuers / tidy_move_legacy_ca_bundle / tidy_revocation_queue / tidy_cross_cluster_revoked_certs / tidy_acme"
+	return "tidy_cert_store / tidy_revoked_certs / tidy_revoked_cert_issuer_associations / tidy_expired_issuers / tidy_move_legacy_ca_bundle / tidy_revocation_queue / tidy_cross_cluster_revoked_certs / tidy_acme / tidy_cert_metadata / tidy_orphaned_issuer_associations"
 }
 
 var defaultTidyConfig = tidyConfig{
@@ -124,6 +187,8 @@ var defaultTidyConfig = tidyConfig{
 	RevocationQueue:         false,
 	QueueSafetyBuffer:       48 * time.Hour,
 	CrossRevokedCerts:       false,
+	CertMetadata:            false,
+	OrphanedAssocs:          false,
 }
 
 func pathTidy(b *backend) *framework.Path {
@@ -291,6 +356,26 @@ func pathTidyCancel(b *backend) *framework.Path {
 								Type:     framework.TypeInt,
 								Required: false,
 							},
+							"tidy_cert_metadata": {
+								Type:        framework.TypeBool,
+								Description: `Tidy the metadata of certificates no longer stored`,
+								Required:    false,
+							},
+							"cert_metadata_deleted_count": {
+								Type:        framework.TypeInt,
+								Description: `The number of certificate metadata entries deleted`,
+								Required:    false,
+							},
+							"tidy_orphaned_issuer_associations": {
+								Type:        framework.TypeBool,
+								Description: `Tidy references left behind by deleted issuers and keys`,
+								Required:    false,
+							},
+							"orphaned_issuer_association_deleted_count": {
+								Type:        framework.TypeInt,
+								Description: `The number of references to deleted issuers and keys removed`,
+								Required:    false,
+							},
 							"cross_revoked_cert_deleted_count": {
 								Type:     framework.TypeInt,
 								Required: false,
@@ -319,6 +404,41 @@ func pathTidyCancel(b *backend) *framework.Path {
 								Description: `The number of expired, unused acme orders removed`,
 								Required:    false,
 							},
+							"current_phase": {
+								Type:        framework.TypeString,
+								Description: `The tidy phase currently running`,
+								Required:    false,
+							},
+							"current_phase_entries_total": {
+								Type:        framework.TypeInt,
+								Description: `The number of entries found so far by the current phase`,
+								Required:    false,
+							},
+							"current_phase_entries_scanned": {
+								Type:        framework.TypeInt,
+								Description: `The number of entries scanned by the current phase`,
+								Required:    false,
+							},
+							"current_phase_time_remaining": {
+								Type:        framework.TypeString,
+								Description: `Estimated time remaining for the current phase`,
+								Required:    false,
+							},
+							"entries_scanned_count": {
+								Type:        framework.TypeInt,
+								Description: `The number of entries scanned across all phases`,
+								Required:    false,
+							},
+							"phase_durations": {
+								Type:        framework.TypeMap,
+								Description: `Time spent in each finished phase, excluding pauses`,
+								Required:    false,
+							},
+							"time_paused": {
+								Type:        framework.TypeString,
+								Description: `Time the operation was paused`,
+								Required:    false,
+							},
 						},
 					}},
 				},
@@ -330,6 +450,60 @@ func pathTidyCancel(b *backend) *framework.Path {
 	}
 }
 
+func pathTidyPause(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "tidy-pause$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "tidy",
+			OperationSuffix: "pause",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathTidyPauseWrite,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      tidyStatusResponseFields(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathTidyPauseHelpSyn,
+		HelpDescription: pathTidyPauseHelpDesc,
+	}
+}
+
+func pathTidyResume(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "tidy-resume$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "tidy",
+			OperationSuffix: "resume",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathTidyResumeWrite,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields:      tidyStatusResponseFields(),
+					}},
+				},
+				ForwardPerformanceStandby: true,
+			},
+		},
+		HelpSynopsis:    pathTidyResumeHelpSyn,
+		HelpDescription: pathTidyResumeHelpDesc,
+	}
+}
+
 func pathTidyStatus(b *backend) *framework.Path {
 	return &framework.Path{
 		Pattern: "tidy-status$",
@@ -346,165 +520,226 @@ func pathTidyStatus(b *backend) *framework.Path {
 				Responses: map[int][]framework.Response{
 					http.StatusOK: {{
 						Description: "OK",
-						Fields: map[string]*framework.FieldSchema{
-							"safety_buffer": {
-								Type:        framework.TypeInt,
-								Description: `Safety buffer time duration`,
-								Required:    true,
-							},
-							"issuer_safety_buffer": {
-								Type:        framework.TypeInt,
-								Description: `Issuer safety buffer`,
-								Required:    true,
-							},
-							"revocation_queue_safety_buffer": {
-								Type:        framework.TypeInt,
-								Description: `Revocation queue safety buffer`,
-								Required:    true,
-							},
-							"acme_account_safety_buffer": {
-								Type:        framework.TypeInt,
-								Description: `Safety buffer after creation after which accounts lacking orders are revoked`,
-								Required:    false,
-							},
-							"tidy_cert_store": {
-								Type:        framework.TypeBool,
-								Description: `Tidy certificate store`,
-								Required:    true,
-							},
-							"tidy_revoked_certs": {
-								Type:        framework.TypeBool,
-								Description: `Tidy revoked certificates`,
-								Required:    true,
-							},
-							"tidy_revoked_cert_issuer_associations": {
-								Type:        framework.TypeBool,
-								Description: `Tidy revoked certificate issuer associations`,
-								Required:    true,
-							},
-							"tidy_expired_issuers": {
-								Type:        framework.TypeBool,
-								Description: `Tidy expired issuers`,
-								Required:    true,
-							},
-							"tidy_cross_cluster_revoked_certs": {
-								Type:        framework.TypeBool,
-								Description: `Tidy the cross-cluster revoked certificate store`,
-								Required:    false,
-							},
-							"tidy_acme": {
-								Type:        framework.TypeBool,
-								Description: `Tidy Unused Acme Accounts, and Orders`,
-								Required:    true,
-							},
-							"pause_duration": {
-								Type:        framework.TypeString,
-								Description: `Duration to pause between tidying certificates`,
-								Required:    true,
-							},
-							"state": {
-								Type:        framework.TypeString,
-								Description: `One of Inactive, Running, Finished, or Error`,
-								Required:    true,
-							},
-							"error": {
-								Type:        framework.TypeString,
-								Description: `The error message`,
-								Required:    true,
-							},
-							"time_started": {
-								Type:        framework.TypeString,
-								Description: `Time the operation started`,
-								Required:    true,
-							},
-							"time_finished": {
-								Type:        framework.TypeString,
-								Description: `Time the operation finished`,
-								Required:    false,
-							},
-							"last_auto_tidy_finished": {
-								Type:        framework.TypeString,
-								Description: `Time the last auto-tidy operation finished`,
-								Required:    true,
-							},
-							"message": {
-								Type:        framework.TypeString,
-								Description: `Message of the operation`,
-								Required:    true,
-							},
-							"cert_store_deleted_count": {
-								Type:        framework.TypeInt,
-								Description: `The number of certificate storage entries deleted`,
-								Required:    true,
-							},
-							"revoked_cert_deleted_count": {
-								Type:        framework.TypeInt,
-								Description: `The number of revoked certificate entries deleted`,
-								Required:    true,
-							},
-							"current_cert_store_count": {
-								Type:        framework.TypeInt,
-								Description: `The number of revoked certificate entries deleted`,
-								Required:    true,
-							},
-							"cross_revoked_cert_deleted_count": {
-								Type:        framework.TypeInt,
-								Description: ``,
-								Required:    true,
-							},
-							"current_revoked_cert_count": {
-								Type:        framework.TypeInt,
-								Description: `The number of revoked certificate entries deleted`,
-								Required:    true,
-							},
-							"revocation_queue_deleted_count": {
-								Type:     framework.TypeInt,
-								Required: true,
-							},
-							"tidy_move_legacy_ca_bundle": {
-								Type:     framework.TypeBool,
-								Required: true,
-							},
-							"tidy_revocation_queue": {
-								Type:     framework.TypeBool,
-								Required: true,
-							},
-							"missing_issuer_cert_count": {
-								Type:     framework.TypeInt,
-								Required: true,
-							},
-							"internal_backend_uuid": {
-								Type:     framework.TypeString,
-								Required: true,
-							},
-							"total_acme_account_count": {
-								Type:        framework.TypeInt,
-								Description: `Total number of acme accounts iterated over`,
-								Required:    false,
-							},
-							"acme_account_deleted_count": {
-								Type:        framework.TypeInt,
-								Description: `The number of revoked acme accounts removed`,
-								Required:    false,
-							},
-							"acme_account_revoked_count": {
-								Type:        framework.TypeInt,
-								Description: `The number of unused acme accounts revoked`,
-								Required:    false,
-							},
-							"acme_orders_deleted_count": {
-								Type:        framework.TypeInt,
-								Description: `The number of expired, unused acme orders removed`,
-								Required:    false,
-							},
-						},
+						Fields:      tidyStatusResponseFields(),
 					}},
 				},
 				ForwardPerformanceStandby: true,
 			},
 		},
-		HelpSynopsis:    pathTidyStatusHelpSyn,
-		HelpDescription: pathTidyStatusHelpDesc,
+		HelpSynopsis:    pathTidyStatusHelpSyn,
+		HelpDescription: pathTidyStatusHelpDesc,
+	}
+}
+
+// tidyStatusResponseFields describes the status of the tidy operation, as
+// returned by tidy-status and the endpoints pausing and resuming it.
+func tidyStatusResponseFields() map[string]*framework.FieldSchema {
+	return map[string]*framework.FieldSchema{
+		"safety_buffer": {
+			Type:        framework.TypeInt,
+			Description: `Safety buffer time duration`,
+			Required:    true,
+		},
+		"issuer_safety_buffer": {
+			Type:        framework.TypeInt,
+			Description: `Issuer safety buffer`,
+			Required:    true,
+		},
+		"revocation_queue_safety_buffer": {
+			Type:        framework.TypeInt,
+			Description: `Revocation queue safety buffer`,
+			Required:    true,
+		},
+		"acme_account_safety_buffer": {
+			Type:        framework.TypeInt,
+			Description: `Safety buffer after creation after which accounts lacking orders are revoked`,
+			Required:    false,
+		},
+		"tidy_cert_store": {
+			Type:        framework.TypeBool,
+			Description: `Tidy certificate store`,
+			Required:    true,
+		},
+		"tidy_revoked_certs": {
+			Type:        framework.TypeBool,
+			Description: `Tidy revoked certificates`,
+			Required:    true,
+		},
+		"tidy_revoked_cert_issuer_associations": {
+			Type:        framework.TypeBool,
+			Description: `Tidy revoked certificate issuer associations`,
+			Required:    true,
+		},
+		"tidy_expired_issuers": {
+			Type:        framework.TypeBool,
+			Description: `Tidy expired issuers`,
+			Required:    true,
+		},
+		"tidy_cross_cluster_revoked_certs": {
+			Type:        framework.TypeBool,
+			Description: `Tidy the cross-cluster revoked certificate store`,
+			Required:    false,
+		},
+		"tidy_acme": {
+			Type:        framework.TypeBool,
+			Description: `Tidy Unused Acme Accounts, and Orders`,
+			Required:    true,
+		},
+		"pause_duration": {
+			Type:        framework.TypeString,
+			Description: `Duration to pause between tidying certificates`,
+			Required:    true,
+		},
+		"state": {
+			Type:        framework.TypeString,
+			Description: `One of Inactive, Running, Paused, Cancelling, Cancelled, Finished, or Error`,
+			Required:    true,
+		},
+		"error": {
+			Type:        framework.TypeString,
+			Description: `The error message`,
+			Required:    true,
+		},
+		"time_started": {
+			Type:        framework.TypeString,
+			Description: `Time the operation started`,
+			Required:    true,
+		},
+		"time_finished": {
+			Type:        framework.TypeString,
+			Description: `Time the operation finished`,
+			Required:    false,
+		},
+		"last_auto_tidy_finished": {
+			Type:        framework.TypeString,
+			Description: `Time the last auto-tidy operation finished`,
+			Required:    true,
+		},
+		"message": {
+			Type:        framework.TypeString,
+			Description: `Message of the operation`,
+			Required:    true,
+		},
+		"cert_store_deleted_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of certificate storage entries deleted`,
+			Required:    true,
+		},
+		"revoked_cert_deleted_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of revoked certificate entries deleted`,
+			Required:    true,
+		},
+		"current_cert_store_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of revoked certificate entries deleted`,
+			Required:    true,
+		},
+		"cross_revoked_cert_deleted_count": {
+			Type:        framework.TypeInt,
+			Description: ``,
+			Required:    true,
+		},
+		"current_revoked_cert_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of revoked certificate entries deleted`,
+			Required:    true,
+		},
+		"revocation_queue_deleted_count": {
+			Type:     framework.TypeInt,
+			Required: true,
+		},
+		"tidy_move_legacy_ca_bundle": {
+			Type:     framework.TypeBool,
+			Required: true,
+		},
+		"tidy_revocation_queue": {
+			Type:     framework.TypeBool,
+			Required: true,
+		},
+		"tidy_cert_metadata": {
+			Type:        framework.TypeBool,
+			Description: `Tidy the metadata of certificates no longer stored`,
+			Required:    true,
+		},
+		"cert_metadata_deleted_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of certificate metadata entries deleted`,
+			Required:    true,
+		},
+		"tidy_orphaned_issuer_associations": {
+			Type:        framework.TypeBool,
+			Description: `Tidy references left behind by deleted issuers and keys`,
+			Required:    true,
+		},
+		"orphaned_issuer_association_deleted_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of references to deleted issuers and keys removed`,
+			Required:    true,
+		},
+		"missing_issuer_cert_count": {
+			Type:     framework.TypeInt,
+			Required: true,
+		},
+		"internal_backend_uuid": {
+			Type:     framework.TypeString,
+			Required: true,
+		},
+		"total_acme_account_count": {
+			Type:        framework.TypeInt,
+			Description: `Total number of acme accounts iterated over`,
+			Required:    false,
+		},
+		"acme_account_deleted_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of revoked acme accounts removed`,
+			Required:    false,
+		},
+		"acme_account_revoked_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of unused acme accounts revoked`,
+			Required:    false,
+		},
+		"acme_orders_deleted_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of expired, unused acme orders removed`,
+			Required:    false,
+		},
+		"current_phase": {
+			Type:        framework.TypeString,
+			Description: `The tidy phase currently running`,
+			Required:    false,
+		},
+		"current_phase_entries_total": {
+			Type:        framework.TypeInt,
+			Description: `The number of entries found so far by the current phase`,
+			Required:    false,
+		},
+		"current_phase_entries_scanned": {
+			Type:        framework.TypeInt,
+			Description: `The number of entries scanned by the current phase`,
+			Required:    false,
+		},
+		"current_phase_time_remaining": {
+			Type:        framework.TypeString,
+			Description: `Estimated time remaining for the current phase`,
+			Required:    false,
+		},
+		"entries_scanned_count": {
+			Type:        framework.TypeInt,
+			Description: `The number of entries scanned across all phases`,
+			Required:    false,
+		},
+		"phase_durations": {
+			Type:        framework.TypeMap,
+			Description: `Time spent in each finished phase, excluding pauses`,
+			Required:    false,
+		},
+		"time_paused": {
+			Type:        framework.TypeString,
+			Description: `Time the operation was paused`,
+			Required:    false,
+		},
 	}
 }
 
@@ -617,6 +852,16 @@ available on the tidy-status endpoint.`,
 								Type:     framework.TypeBool,
 								Required: true,
 							},
+							"tidy_cert_metadata": {
+								Type:        framework.TypeBool,
+								Description: `Tidy the metadata of certificates no longer stored`,
+								Required:    true,
+							},
+							"tidy_orphaned_issuer_associations": {
+								Type:        framework.TypeBool,
+								Description: `Tidy references left behind by deleted issuers and keys`,
+								Required:    true,
+							},
 							"revocation_queue_safety_buffer": {
 								Type:     framework.TypeInt,
 								Required: true,
@@ -707,6 +952,16 @@ available on the tidy-status endpoint.`,
 								Type:     framework.TypeBool,
 								Required: true,
 							},
+							"tidy_cert_metadata": {
+								Type:        framework.TypeBool,
+								Description: `Tidy the metadata of certificates no longer stored`,
+								Required:    true,
+							},
+							"tidy_orphaned_issuer_associations": {
+								Type:        framework.TypeBool,
+								Description: `Tidy references left behind by deleted issuers and keys`,
+								Required:    true,
+							},
 							"tidy_move_legacy_ca_bundle": {
 								Type:     framework.TypeBool,
 								Required: true,
@@ -751,6 +1006,8 @@ func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *fr
 	tidyCrossRevokedCerts := d.Get("tidy_cross_cluster_revoked_certs").(bool)
 	tidyAcme := d.Get("tidy_acme").(bool)
 	acmeAccountSafetyBuffer := d.Get("acme_account_safety_buffer").(int)
+	tidyCertMetadata := d.Get("tidy_cert_metadata").(bool)
+	tidyOrphanedAssocs := d.Get("tidy_orphaned_issuer_associations").(bool)
 
 	if safetyBuffer < 1 {
 		return logical.ErrorResponse("safety_buffer must be greater than zero"), nil
@@ -802,6 +1059,8 @@ func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *fr
 		CrossRevokedCerts:       tidyCrossRevokedCerts,
 		TidyAcme:                tidyAcme,
 		AcmeAccountSafetyBuffer: acmeAccountSafetyBufferDuration,
+		CertMetadata:            tidyCertMetadata,
+		OrphanedAssocs:          tidyOrphanedAssocs,
 	}
 
 	if !atomic.CompareAndSwapUint32(b.tidyCASGuard, 0, 1) {
@@ -846,6 +1105,7 @@ func (b *backend) pathTidyWrite(ctx context.Context, req *logical.Request, d *fr
 func (b *backend) startTidyOperation(req *logical.Request, config *tidyConfig) {
 	go func() {
 		atomic.StoreUint32(b.tidyCancelCAS, 0)
+		atomic.StoreUint32(b.tidyPauseCAS, 0)
 		defer atomic.StoreUint32(b.tidyCASGuard, 0)
 
 		b.tidyStatusStart(config)
@@ -857,75 +1117,123 @@ func (b *backend) startTidyOperation(req *logical.Request, config *tidyConfig) {
 
 		doTidy := func() error {
 			if config.CertStore {
+				b.tidyStatusStartPhase(tidyPhaseCertStore)
 				if err := b.doTidyCertStore(ctx, req, logger, config); err != nil {
 					return err
 				}
+				b.tidyStatusEndPhase()
+			}
+
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
+			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
+				return tidyCancelledError
+			}
+
+			if config.CertMetadata {
+				b.tidyStatusStartPhase(tidyPhaseCertMetadata)
+				if err := b.doTidyCertMetadata(ctx, req, logger, config); err != nil {
+					return err
+				}
+				b.tidyStatusEndPhase()
 			}
 
-			// Check for cancel before continuing.
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
 			if config.RevokedCerts || config.IssuerAssocs {
+				b.tidyStatusStartPhase(tidyPhaseRevokedCerts)
 				if err := b.doTidyRevocationStore(ctx, req, logger, config); err != nil {
 					return err
 				}
+				b.tidyStatusEndPhase()
 			}
 
-			// Check for cancel before continuing.
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
 			if config.ExpiredIssuers {
+				b.tidyStatusStartPhase(tidyPhaseExpiredIssuers)
 				if err := b.doTidyExpiredIssuers(ctx, req, logger, config); err != nil {
 					return err
 				}
+				b.tidyStatusEndPhase()
+			}
+
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
+			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
+				return tidyCancelledError
+			}
+
+			if config.OrphanedAssocs {
+				b.tidyStatusStartPhase(tidyPhaseIssuerAssocs)
+				if err := b.doTidyOrphanedIssuerAssocs(ctx, req, logger, config); err != nil {
+					return err
+				}
+				b.tidyStatusEndPhase()
 			}
 
-			// Check for cancel before continuing.
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
 			if config.BackupBundle {
+				b.tidyStatusStartPhase(tidyPhaseLegacyBundle)
 				if err := b.doTidyMoveCABundle(ctx, req, logger, config); err != nil {
 					return err
 				}
+				b.tidyStatusEndPhase()
 			}
 
-			// Check for cancel before continuing.
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
 			if config.RevocationQueue {
+				b.tidyStatusStartPhase(tidyPhaseRevocationQueue)
 				if err := b.doTidyRevocationQueue(ctx, req, logger, config); err != nil {
 					return err
 				}
+				b.tidyStatusEndPhase()
 			}
 
-			// Check for cancel before continuing.
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
 			if config.CrossRevokedCerts {
+				b.tidyStatusStartPhase(tidyPhaseCrossRevokedCerts)
 				if err := b.doTidyCrossRevocationStore(ctx, req, logger, config); err != nil {
 					return err
 				}
+				b.tidyStatusEndPhase()
 			}
 
-			// Check for cancel before continuing.
+			// Check for pause and cancel before continuing.
+			b.tidyWaitWhilePaused()
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
 			if config.TidyAcme {
+				b.tidyStatusStartPhase(tidyPhaseAcme)
 				if err := b.doTidyAcme(ctx, req, logger, config); err != nil {
 					return err
 				}
+				b.tidyStatusEndPhase()
 			}
 
 			return nil
@@ -955,18 +1263,22 @@ func (b *backend) doTidyCertStore(ctx context.Context, req *logical.Request, log
 
 	serialCount := len(serials)
 	metrics.SetGauge([]string{"secrets", "pki", "tidy", "cert_store_total_entries"}, float32(serialCount))
+	b.tidyStatusAddPhaseTotal(serialCount)
 	for i, serial := range serials {
 		b.tidyStatusMessage(fmt.Sprintf("Tidying certificate store: checking entry %d of %d", i, serialCount))
 		metrics.SetGauge([]string{"secrets", "pki", "tidy", "cert_store_current_entry"}, float32(i))
+		b.tidyStatusIncScannedCount()
 
 		// Check for cancel before continuing.
 		if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 			return tidyCancelledError
 		}
 
-		// Check for pause duration to reduce resource consumption.
-		if config.PauseDuration > (0 * time.Second) {
+		// Check for pause duration to reduce resource consumption, and
+		// wait while the operation is paused.
+		if config.PauseDuration > 0 || b.tidyIsPaused() {
 			time.Sleep(config.PauseDuration)
+			b.tidyWaitWhilePaused()
 		}
 
 		certEntry, err := req.Storage.Get(ctx, "certs/"+serial)
@@ -1001,6 +1313,9 @@ func (b *backend) doTidyCertStore(ctx context.Context, req *logical.Request, log
 			if err := req.Storage.Delete(ctx, "certs/"+serial); err != nil {
 				return fmt.Errorf("error deleting serial %q from storage: %w", serial, err)
 			}
+			if err := req.Storage.Delete(ctx, certMetadataPrefix+serial); err != nil {
+				return fmt.Errorf("error deleting metadata of serial %q from storage: %w", serial, err)
+			}
 			b.tidyStatusIncCertStoreCount()
 		}
 	}
@@ -1012,6 +1327,51 @@ func (b *backend) doTidyCertStore(ctx context.Context, req *logical.Request, log
 	return nil
 }
 
+func (b *backend) doTidyCertMetadata(ctx context.Context, req *logical.Request, logger hclog.Logger, config *tidyConfig) error {
+	serials, err := req.Storage.List(ctx, certMetadataPrefix)
+	if err != nil {
+		return fmt.Errorf("error fetching list of certificate metadata: %w", err)
+	}
+
+	serialCount := len(serials)
+	b.tidyStatusAddPhaseTotal(serialCount)
+	for i, serial := range serials {
+		b.tidyStatusMessage(fmt.Sprintf("Tidying certificate metadata: checking entry %d of %d", i, serialCount))
+		b.tidyStatusIncScannedCount()
+
+		// Check for cancel before continuing.
+		if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
+			return tidyCancelledError
+		}
+
+		// Check for pause duration to reduce resource consumption, and
+		// wait while the operation is paused.
+		if config.PauseDuration > 0 || b.tidyIsPaused() {
+			time.Sleep(config.PauseDuration)
+			b.tidyWaitWhilePaused()
+		}
+
+		// Certificates are always written before their metadata, so
+		// metadata without a certificate was left behind when the latter
+		// got removed.
+		certEntry, err := req.Storage.Get(ctx, "certs/"+serial)
+		if err != nil {
+			return fmt.Errorf("error fetching certificate %q: %w", serial, err)
+		}
+		if certEntry != nil {
+			continue
+		}
+
+		logger.Debug("certificate metadata has no corresponding certificate; tidying up", "serial", serial)
+		if err := req.Storage.Delete(ctx, certMetadataPrefix+serial); err != nil {
+			return fmt.Errorf("error deleting metadata of serial %q from storage: %w", serial, err)
+		}
+		b.tidyStatusIncCertMetadataCount()
+	}
+
+	return nil
+}
+
 func (b *backend) doTidyRevocationStore(ctx context.Context, req *logical.Request, logger hclog.Logger, config *tidyConfig) error {
 	b.revokeStorageLock.Lock()
 	defer b.revokeStorageLock.Unlock()
@@ -1032,6 +1392,7 @@ func (b *backend) doTidyRevocationStore(ctx context.Context, req *logical.Reques
 
 	revokedSerialsCount := len(revokedSerials)
 	metrics.SetGauge([]string{"secrets", "pki", "tidy", "revoked_cert_total_entries"}, float32(revokedSerialsCount))
+	b.tidyStatusAddPhaseTotal(revokedSerialsCount)
 
 	fixedIssuers := 0
 
@@ -1039,16 +1400,19 @@ func (b *backend) doTidyRevocationStore(ctx context.Context, req *logical.Reques
 	for i, serial := range revokedSerials {
 		b.tidyStatusMessage(fmt.Sprintf("Tidying revoked certificates: checking certificate %d of %d", i, len(revokedSerials)))
 		metrics.SetGauge([]string{"secrets", "pki", "tidy", "revoked_cert_current_entry"}, float32(i))
+		b.tidyStatusIncScannedCount()
 
 		// Check for cancel before continuing.
 		if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 			return tidyCancelledError
 		}
 
-		// Check for pause duration to reduce resource consumption.
-		if config.PauseDuration > (0 * time.Second) {
+		// Check for pause duration to reduce resource consumption, and
+		// wait while the operation is paused.
+		if config.PauseDuration > 0 || b.tidyIsPaused() {
 			b.revokeStorageLock.Unlock()
 			time.Sleep(config.PauseDuration)
+			b.tidyWaitWhilePaused()
 			b.revokeStorageLock.Lock()
 		}
 
@@ -1080,16 +1444,23 @@ func (b *backend) doTidyRevocationStore(ctx context.Context, req *logical.Reques
 			return fmt.Errorf("error decoding revocation entry for serial %q: %w", serial, err)
 		}
 
-		revokedCert, err := x509.ParseCertificate(revInfo.CertificateBytes)
-		if err != nil {
-			return fmt.Errorf("unable to parse stored revoked certificate with serial %q: %w", serial, err)
+		// Imported revocations don't carry the certificate, and so can't
+		// have their issuer association fixed.
+		var revokedCert *x509.Certificate
+		notAfter := revInfo.CertExpiration
+		if !revInfo.isImported() {
+			revokedCert, err = x509.ParseCertificate(revInfo.CertificateBytes)
+			if err != nil {
+				return fmt.Errorf("unable to parse stored revoked certificate with serial %q: %w", serial, err)
+			}
+			notAfter = revokedCert.NotAfter
 		}
 
 		// Tidy operations over revoked certs should execute prior to
 		// tidyRevokedCerts as that may remove the entry. If that happens,
 		// we won't persist the revInfo changes (as it was deleted instead).
 		var storeCert bool
-		if config.IssuerAssocs {
+		if config.IssuerAssocs && revokedCert != nil {
 			if !isRevInfoIssuerValid(&revInfo, issuerIDCertMap) {
 				b.tidyStatusIncMissingIssuerCertCount()
 				revInfo.CertificateIssuer = issuerID("")
@@ -1105,13 +1476,16 @@ func (b *backend) doTidyRevocationStore(ctx context.Context, req *logical.Reques
 			// past its NotAfter value. This is because we use the
 			// information on revoked/ to build the CRL and the
 			// information on certs/ for lookup.
-			if time.Since(revokedCert.NotAfter) > config.SafetyBuffer {
+			if time.Since(notAfter) > config.SafetyBuffer {
 				if err := req.Storage.Delete(ctx, "revoked/"+serial); err != nil {
 					return fmt.Errorf("error deleting serial %q from revoked list: %w", serial, err)
 				}
 				if err := req.Storage.Delete(ctx, "certs/"+serial); err != nil {
 					return fmt.Errorf("error deleting serial %q from store when tidying revoked: %w", serial, err)
 				}
+				if err := req.Storage.Delete(ctx, certMetadataPrefix+serial); err != nil {
+					return fmt.Errorf("error deleting metadata of serial %q when tidying revoked: %w", serial, err)
+				}
 				rebuildCRL = true
 				storeCert = false
 				b.tidyStatusIncRevokedCertCount()
@@ -1278,6 +1652,195 @@ func (b *backend) doTidyExpiredIssuers(ctx context.Context, req *logical.Request
 	return nil
 }
 
+// isStoredCertExpired returns whether the certificate with the given
+// (normalized) serial is stored on this cluster and has expired.
+func isStoredCertExpired(sc *storageContext, serial string) (bool, error) {
+	certEntry, err := sc.Storage.Get(sc.Context, "certs/"+serial)
+	if err != nil {
+		return false, fmt.Errorf("error fetching certificate %q: %w", serial, err)
+	}
+	if certEntry == nil || len(certEntry.Value) == 0 {
+		return false, nil
+	}
+
+	cert, err := x509.ParseCertificate(certEntry.Value)
+	if err != nil {
+		return false, fmt.Errorf("unable to parse stored certificate with serial %q: %w", serial, err)
+	}
+
+	return time.Now().After(cert.NotAfter), nil
+}
+
+func (b *backend) doTidyOrphanedIssuerAssocs(ctx context.Context, req *logical.Request, logger hclog.Logger, config *tidyConfig) error {
+	// As with expired issuers, we do not support cancelling within this
+	// operation.
+
+	if b.System().ReplicationState().HasState(consts.ReplicationDRSecondary | consts.ReplicationPerformanceStandby) {
+		b.Logger().Debug("skipping orphaned issuer association tidy as we're not on an active node")
+		return nil
+	}
+
+	if b.useLegacyBundleCaStorage() {
+		return nil
+	}
+
+	// Issuers, keys and their configuration are replicated from the
+	// primary, as is the unified CRL configuration; only the cluster-local
+	// CRLs are ours to tidy on performance secondaries.
+	isPerfSecondary := !b.System().LocalMount() && b.System().ReplicationState().HasState(consts.ReplicationPerformanceSecondary)
+
+	b.issuersLock.Lock()
+	defer b.issuersLock.Unlock()
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	issuers, err := sc.listIssuers()
+	if err != nil {
+		return err
+	}
+	haveIssuers := make(map[issuerID]bool, len(issuers))
+	for _, issuer := range issuers {
+		haveIssuers[issuer] = true
+	}
+
+	if !isPerfSecondary {
+		// Deleting the default issuer or key and clearing the default
+		// isn't transactional, so the latter might not have happened.
+		iConfig, err := sc.getIssuersConfig()
+		if err != nil {
+			return err
+		}
+		if len(iConfig.DefaultIssuerId) > 0 && !haveIssuers[iConfig.DefaultIssuerId] {
+			logger.Info("removing default issuer setting referencing deleted issuer", "issuer_id", iConfig.DefaultIssuerId)
+			iConfig.DefaultIssuerId = issuerID("")
+			if err := sc.setIssuersConfig(iConfig); err != nil {
+				return err
+			}
+			b.tidyStatusIncOrphanedAssocsCount()
+		}
+
+		keys, err := sc.listKeys()
+		if err != nil {
+			return err
+		}
+		kConfig, err := sc.getKeysConfig()
+		if err != nil {
+			return err
+		}
+		haveDefaultKey := false
+		for _, key := range keys {
+			haveDefaultKey = haveDefaultKey || key == kConfig.DefaultKeyId
+		}
+		if len(kConfig.DefaultKeyId) > 0 && !haveDefaultKey {
+			logger.Info("removing default key setting referencing deleted key", "key_id", kConfig.DefaultKeyId)
+			kConfig.DefaultKeyId = keyID("")
+			if err := sc.setKeysConfig(kConfig); err != nil {
+				return err
+			}
+			b.tidyStatusIncOrphanedAssocsCount()
+		}
+	}
+
+	// Hold off CRL building while we update its configuration.
+	b.crlBuilder._builder.Lock()
+	defer b.crlBuilder._builder.Unlock()
+
+	if err := b.tidyOrphanedCRLs(sc, logger, haveIssuers, false); err != nil {
+		return err
+	}
+	if !isPerfSecondary {
+		if err := b.tidyOrphanedCRLs(sc, logger, haveIssuers, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// tidyOrphanedCRLs removes deleted issuers from the CRL configuration, along
+// with the numbering and stored CRLs no longer belonging to any issuer.
+// Building CRLs only drops the deleted issuers' mappings and their last
+// complete, cluster-local CRL, and only once a rebuild happens.
+func (b *backend) tidyOrphanedCRLs(sc *storageContext, logger hclog.Logger, haveIssuers map[issuerID]bool, isUnified bool) error {
+	getConfig, setConfig := sc.getLocalCRLConfig, sc.setLocalCRLConfig
+	configPath, crlPrefix := storageLocalCRLConfig, "crls/"
+	if isUnified {
+		getConfig, setConfig = sc.getUnifiedCRLConfig, sc.setUnifiedCRLConfig
+		configPath, crlPrefix = storageUnifiedCRLConfig, unifiedCRLPathPrefix+crlPrefix
+	}
+
+	crlConfig, err := getConfig()
+	if err != nil {
+		return err
+	}
+
+	modified := false
+	for issuer := range crlConfig.IssuerIDCRLMap {
+		if !haveIssuers[issuer] {
+			logger.Info("removing CRL mapping of deleted issuer", "issuer_id", issuer, "unified", isUnified)
+			delete(crlConfig.IssuerIDCRLMap, issuer)
+			modified = true
+			b.tidyStatusIncOrphanedAssocsCount()
+		}
+	}
+
+	inUse := make(map[crlID]bool, len(crlConfig.IssuerIDCRLMap))
+	for _, id := range crlConfig.IssuerIDCRLMap {
+		inUse[id] = true
+	}
+
+	orphaned := make(map[crlID]bool)
+	for id := range crlConfig.CRLNumberMap {
+		orphaned[id] = !inUse[id]
+	}
+	for id := range crlConfig.LastCompleteNumberMap {
+		orphaned[id] = !inUse[id]
+	}
+	for id := range crlConfig.CRLExpirationMap {
+		orphaned[id] = !inUse[id]
+	}
+	for id, isOrphaned := range orphaned {
+		if !isOrphaned {
+			continue
+		}
+
+		logger.Info("removing numbering of CRL no longer belonging to any issuer", "crl_id", id, "unified", isUnified)
+		delete(crlConfig.CRLNumberMap, id)
+		delete(crlConfig.LastCompleteNumberMap, id)
+		delete(crlConfig.CRLExpirationMap, id)
+		modified = true
+		b.tidyStatusIncOrphanedAssocsCount()
+	}
+
+	if modified {
+		if err := setConfig(crlConfig); err != nil {
+			return fmt.Errorf("error persisting updated CRL config: %w", err)
+		}
+	}
+
+	entries, err := sc.Storage.List(sc.Context, crlPrefix)
+	if err != nil {
+		return fmt.Errorf("error listing stored CRLs: %w", err)
+	}
+	for _, entry := range entries {
+		path := crlPrefix + entry
+		if path == configPath || strings.HasSuffix(entry, "/") {
+			continue
+		}
+
+		if inUse[crlID(strings.TrimSuffix(entry, deltaCRLPathSuffix))] {
+			continue
+		}
+
+		logger.Info("removing stored CRL no longer belonging to any issuer", "path", path)
+		if err := sc.Storage.Delete(sc.Context, path); err != nil {
+			return fmt.Errorf("error deleting stored CRL %v: %w", path, err)
+		}
+		b.tidyStatusIncOrphanedAssocsCount()
+	}
+
+	return nil
+}
+
 func (b *backend) doTidyMoveCABundle(ctx context.Context, req *logical.Request, logger hclog.Logger, config *tidyConfig) error {
 	// We do not support cancelling within this operation; any cancel will
 	// occur before or after this operation.
@@ -1366,17 +1929,22 @@ func (b *backend) doTidyRevocationQueue(ctx context.Context, req *logical.Reques
 		if err != nil {
 			return fmt.Errorf("failed to list cross-cluster revocation queue entries for cluster %v (%v): %w", cluster, cIndex, err)
 		}
+		b.tidyStatusAddPhaseTotal(len(serials))
 
 		for _, serial := range serials {
+			b.tidyStatusIncScannedCount()
+
 			// Check for cancellation.
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
-			// Check for pause duration to reduce resource consumption.
-			if config.PauseDuration > (0 * time.Second) {
+			// Check for pause duration to reduce resource consumption, and
+			// wait while the operation is paused.
+			if config.PauseDuration > 0 || b.tidyIsPaused() {
 				b.revokeStorageLock.Unlock()
 				time.Sleep(config.PauseDuration)
+				b.tidyWaitWhilePaused()
 				b.revokeStorageLock.Lock()
 			}
 
@@ -1434,7 +2002,16 @@ func (b *backend) doTidyRevocationQueue(ctx context.Context, req *logical.Reques
 			}
 
 			if time.Since(revRequest.RequestedAt) <= config.QueueSafetyBuffer {
-				continue
+				// Requests for certificates we issued which have since
+				// expired would be refused anyway, so they can go before
+				// the safety buffer passes.
+				expired, err := isStoredCertExpired(sc, serial)
+				if err != nil {
+					return err
+				}
+				if !expired {
+					continue
+				}
 			}
 
 			// Safe to remove this entry.
@@ -1488,17 +2065,22 @@ func (b *backend) doTidyCrossRevocationStore(ctx context.Context, req *logical.R
 		if err != nil {
 			return fmt.Errorf("failed to list cross-cluster revoked certificate store entries for cluster %v (%v): %w", cluster, cIndex, err)
 		}
+		b.tidyStatusAddPhaseTotal(len(serials))
 
 		for _, serial := range serials {
+			b.tidyStatusIncScannedCount()
+
 			// Check for cancellation.
 			if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 1, 0) {
 				return tidyCancelledError
 			}
 
-			// Check for pause duration to reduce resource consumption.
-			if config.PauseDuration > (0 * time.Second) {
+			// Check for pause duration to reduce resource consumption, and
+			// wait while the operation is paused.
+			if config.PauseDuration > 0 || b.tidyIsPaused() {
 				b.revokeStorageLock.Unlock()
 				time.Sleep(config.PauseDuration)
+				b.tidyWaitWhilePaused()
 				b.revokeStorageLock.Lock()
 			}
 
@@ -1545,8 +2127,10 @@ func (b *backend) doTidyAcme(ctx context.Context, req *logical.Request, logger h
 	b.tidyStatusLock.Lock()
 	b.tidyStatus.acmeAccountsCount = uint(len(thumbprints))
 	b.tidyStatusLock.Unlock()
+	b.tidyStatusAddPhaseTotal(len(thumbprints))
 
 	for _, thumbprint := range thumbprints {
+		b.tidyStatusIncScannedCount()
 		err := b.tidyAcmeAccountByThumbprint(b.acmeState, sc, thumbprint, config.SafetyBuffer, config.AcmeAccountSafetyBuffer)
 		if err != nil {
 			logger.Warn("error tidying account %v: %v", thumbprint, err.Error())
@@ -1557,10 +2141,12 @@ func (b *backend) doTidyAcme(ctx context.Context, req *logical.Request, logger h
 			return tidyCancelledError
 		}
 
-		// Check for pause duration to reduce resource consumption.
-		if config.PauseDuration > (0 * time.Second) {
+		// Check for pause duration to reduce resource consumption, and
+		// wait while the operation is paused.
+		if config.PauseDuration > 0 || b.tidyIsPaused() {
 			b.acmeAccountLock.Unlock() // Correct the Lock
 			time.Sleep(config.PauseDuration)
+			b.tidyWaitWhilePaused()
 			b.acmeAccountLock.Lock()
 		}
 
@@ -1571,8 +2157,11 @@ func (b *backend) doTidyAcme(ctx context.Context, req *logical.Request, logger h
 	if err != nil {
 		return fmt.Errorf("failed listing EAB ids: %w", err)
 	}
+	b.tidyStatusAddPhaseTotal(len(eabIds))
 
 	for _, eabId := range eabIds {
+		b.tidyStatusIncScannedCount()
+
 		eab, err := b.acmeState.LoadEab(sc, eabId)
 		if err != nil {
 			if errors.Is(err, ErrStorageItemNotFound) {
@@ -1595,10 +2184,12 @@ func (b *backend) doTidyAcme(ctx context.Context, req *logical.Request, logger h
 			return tidyCancelledError
 		}
 
-		// Check for pause duration to reduce resource consumption.
-		if config.PauseDuration > (0 * time.Second) {
+		// Check for pause duration to reduce resource consumption, and
+		// wait while the operation is paused.
+		if config.PauseDuration > 0 || b.tidyIsPaused() {
 			b.acmeAccountLock.Unlock() // Correct the Lock
 			time.Sleep(config.PauseDuration)
+			b.tidyWaitWhilePaused()
 			b.acmeAccountLock.Lock()
 		}
 	}
@@ -1619,9 +2210,10 @@ func (b *backend) pathTidyCancelWrite(ctx context.Context, req *logical.Request,
 	//
 	// Unlock needs to occur prior to calling read.
 	b.tidyStatusLock.Lock()
-	if b.tidyStatus.state == tidyStatusStarted || atomic.LoadUint32(b.tidyCASGuard) == 1 {
+	if b.tidyStatus.state == tidyStatusStarted || b.tidyStatus.state == tidyStatusPaused || atomic.LoadUint32(b.tidyCASGuard) == 1 {
 		if atomic.CompareAndSwapUint32(b.tidyCancelCAS, 0, 1) {
 			b.tidyStatus.state = tidyStatusCancelling
+			atomic.StoreUint32(b.tidyPauseCAS, 0)
 		}
 	}
 	b.tidyStatusLock.Unlock()
@@ -1629,41 +2221,94 @@ func (b *backend) pathTidyCancelWrite(ctx context.Context, req *logical.Request,
 	return b.pathTidyStatusRead(ctx, req, d)
 }
 
+func (b *backend) pathTidyPauseWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	// As with cancellation, update the status while holding its lock so
+	// it can't race with the operation finishing.
+	b.tidyStatusLock.Lock()
+	paused := false
+	if b.tidyStatus.state == tidyStatusStarted && atomic.LoadUint32(b.tidyCASGuard) == 1 {
+		if atomic.CompareAndSwapUint32(b.tidyPauseCAS, 0, 1) {
+			b.tidyStatus.state = tidyStatusPaused
+			b.tidyStatus.timePaused = time.Now()
+			paused = true
+		}
+	}
+	b.tidyStatusLock.Unlock()
+
+	resp, err := b.pathTidyStatusRead(ctx, req, d)
+	if err == nil && !paused {
+		resp.AddWarning("Tidy operation cannot be paused as none is currently running.")
+	}
+	return resp, err
+}
+
+func (b *backend) pathTidyResumeWrite(ctx context.Context, req *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	b.tidyStatusLock.Lock()
+	resumed := false
+	if b.tidyStatus.state == tidyStatusPaused {
+		if atomic.CompareAndSwapUint32(b.tidyPauseCAS, 1, 0) {
+			// Leave the time spent paused out of the phase's duration.
+			b.tidyStatus.phaseStarted = b.tidyStatus.phaseStarted.Add(time.Since(b.tidyStatus.timePaused))
+			b.tidyStatus.timePaused = time.Time{}
+			b.tidyStatus.state = tidyStatusStarted
+			resumed = true
+		}
+	}
+	b.tidyStatusLock.Unlock()
+
+	resp, err := b.pathTidyStatusRead(ctx, req, d)
+	if err == nil && !resumed {
+		resp.AddWarning("Tidy operation cannot be resumed as none is currently paused.")
+	}
+	return resp, err
+}
+
 func (b *backend) pathTidyStatusRead(_ context.Context, _ *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
 	b.tidyStatusLock.RLock()
 	defer b.tidyStatusLock.RUnlock()
 
 	resp := &logical.Response{
 		Data: map[string]interface{}{
-			"safety_buffer":                         nil,
-			"issuer_safety_buffer":                  nil,
-			"tidy_cert_store":                       nil,
-			"tidy_revoked_certs":                    nil,
-			"tidy_revoked_cert_issuer_associations": nil,
-			"tidy_expired_issuers":                  nil,
-			"tidy_move_legacy_ca_bundle":            nil,
-			"tidy_revocation_queue":                 nil,
-			"tidy_cross_cluster_revoked_certs":      nil,
-			"tidy_acme":                             nil,
-			"pause_duration":                        nil,
-			"state":                                 "Inactive",
-			"error":                                 nil,
-			"time_started":                          nil,
-			"time_finished":                         nil,
-			"message":                               nil,
-			"cert_store_deleted_count":              nil,
-			"revoked_cert_deleted_count":            nil,
-			"missing_issuer_cert_count":             nil,
-			"current_cert_store_count":              nil,
-			"current_revoked_cert_count":            nil,
-			"internal_backend_uuid":                 nil,
-			"revocation_queue_deleted_count":        nil,
-			"cross_revoked_cert_deleted_count":      nil,
-			"total_acme_account_count":              nil,
-			"acme_account_deleted_count":            nil,
-			"acme_account_revoked_count":            nil,
-			"acme_orders_deleted_count":             nil,
-			"acme_account_safety_buffer":            nil,
+			"safety_buffer":                             nil,
+			"issuer_safety_buffer":                      nil,
+			"tidy_cert_store":                           nil,
+			"tidy_revoked_certs":                        nil,
+			"tidy_revoked_cert_issuer_associations":     nil,
+			"tidy_expired_issuers":                      nil,
+			"tidy_move_legacy_ca_bundle":                nil,
+			"tidy_revocation_queue":                     nil,
+			"tidy_cross_cluster_revoked_certs":          nil,
+			"tidy_acme":                                 nil,
+			"tidy_cert_metadata":                        nil,
+			"tidy_orphaned_issuer_associations":         nil,
+			"pause_duration":                            nil,
+			"state":                                     "Inactive",
+			"error":                                     nil,
+			"time_started":                              nil,
+			"time_finished":                             nil,
+			"message":                                   nil,
+			"cert_store_deleted_count":                  nil,
+			"revoked_cert_deleted_count":                nil,
+			"missing_issuer_cert_count":                 nil,
+			"current_cert_store_count":                  nil,
+			"current_revoked_cert_count":                nil,
+			"internal_backend_uuid":                     nil,
+			"revocation_queue_deleted_count":            nil,
+			"cross_revoked_cert_deleted_count":          nil,
+			"cert_metadata_deleted_count":               nil,
+			"orphaned_issuer_association_deleted_count": nil,
+			"total_acme_account_count":                  nil,
+			"acme_account_deleted_count":                nil,
+			"acme_account_revoked_count":                nil,
+			"acme_orders_deleted_count":                 nil,
+			"acme_account_safety_buffer":                nil,
+			"current_phase":                             nil,
+			"current_phase_entries_total":               nil,
+			"current_phase_entries_scanned":             nil,
+			"current_phase_time_remaining":              nil,
+			"entries_scanned_count":                     nil,
+			"phase_durations":                           nil,
+			"time_paused":                               nil,
 		},
 	}
 
@@ -1695,6 +2340,8 @@ func (b *backend) pathTidyStatusRead(_ context.Context, _ *logical.Request, _ *f
 	resp.Data["tidy_revocation_queue"] = b.tidyStatus.tidyRevocationQueue
 	resp.Data["tidy_cross_cluster_revoked_certs"] = b.tidyStatus.tidyCrossRevokedCerts
 	resp.Data["tidy_acme"] = b.tidyStatus.tidyAcme
+	resp.Data["tidy_cert_metadata"] = b.tidyStatus.tidyCertMetadata
+	resp.Data["tidy_orphaned_issuer_associations"] = b.tidyStatus.tidyOrphanedAssocs
 	resp.Data["pause_duration"] = b.tidyStatus.pauseDuration
 	resp.Data["time_started"] = b.tidyStatus.timeStarted
 	resp.Data["message"] = b.tidyStatus.message
@@ -1703,6 +2350,8 @@ func (b *backend) pathTidyStatusRead(_ context.Context, _ *logical.Request, _ *f
 	resp.Data["missing_issuer_cert_count"] = b.tidyStatus.missingIssuerCertCount
 	resp.Data["revocation_queue_deleted_count"] = b.tidyStatus.revQueueDeletedCount
 	resp.Data["cross_revoked_cert_deleted_count"] = b.tidyStatus.crossRevokedDeletedCount
+	resp.Data["cert_metadata_deleted_count"] = b.tidyStatus.certMetadataDeletedCount
+	resp.Data["orphaned_issuer_association_deleted_count"] = b.tidyStatus.orphanedAssocsDeletedCount
 	resp.Data["revocation_queue_safety_buffer"] = b.tidyStatus.revQueueSafetyBuffer
 	resp.Data["last_auto_tidy_finished"] = b.lastTidy
 	resp.Data["total_acme_account_count"] = b.tidyStatus.acmeAccountsCount
@@ -1710,10 +2359,29 @@ func (b *backend) pathTidyStatusRead(_ context.Context, _ *logical.Request, _ *f
 	resp.Data["acme_account_revoked_count"] = b.tidyStatus.acmeAccountsRevokedCount
 	resp.Data["acme_orders_deleted_count"] = b.tidyStatus.acmeOrdersDeletedCount
 	resp.Data["acme_account_safety_buffer"] = b.tidyStatus.acmeAccountSafetyBuffer
+	resp.Data["entries_scanned_count"] = b.tidyStatus.scannedCount
+
+	if b.tidyStatus.phase != "" {
+		resp.Data["current_phase"] = b.tidyStatus.phase
+		resp.Data["current_phase_entries_total"] = b.tidyStatus.phaseTotal
+		resp.Data["current_phase_entries_scanned"] = b.tidyStatus.phaseScanned
+		if remaining, ok := b.tidyStatus.phaseTimeRemaining(time.Now()); ok {
+			resp.Data["current_phase_time_remaining"] = remaining.Round(time.Second).String()
+		}
+	}
+
+	phaseDurations := make(map[string]interface{}, len(b.tidyStatus.phaseDurations))
+	for phase, duration := range b.tidyStatus.phaseDurations {
+		phaseDurations[phase] = duration.String()
+	}
+	resp.Data["phase_durations"] = phaseDurations
 
 	switch b.tidyStatus.state {
 	case tidyStatusStarted:
 		resp.Data["state"] = "Running"
+	case tidyStatusPaused:
+		resp.Data["state"] = "Paused"
+		resp.Data["time_paused"] = b.tidyStatus.timePaused
 	case tidyStatusFinished:
 		resp.Data["state"] = "Finished"
 		resp.Data["time_finished"] = b.tidyStatus.timeFinished
@@ -1828,6 +2496,14 @@ func (b *backend) pathConfigAutoTidyWrite(ctx context.Context, req *logical.Requ
 		config.TidyAcme = tidyAcmeRaw.(bool)
 	}
 
+	if certMetadataRaw, ok := d.GetOk("tidy_cert_metadata"); ok {
+		config.CertMetadata = certMetadataRaw.(bool)
+	}
+
+	if orphanedAssocsRaw, ok := d.GetOk("tidy_orphaned_issuer_associations"); ok {
+		config.OrphanedAssocs = orphanedAssocsRaw.(bool)
+	}
+
 	if acmeAccountSafetyBufferRaw, ok := d.GetOk("acme_account_safety_buffer"); ok {
 		config.AcmeAccountSafetyBuffer = time.Duration(acmeAccountSafetyBufferRaw.(int)) * time.Second
 		if config.AcmeAccountSafetyBuffer < 1*time.Second {
@@ -1877,6 +2553,8 @@ func (b *backend) tidyStatusStart(config *tidyConfig) {
 		tidyRevocationQueue:     config.RevocationQueue,
 		tidyCrossRevokedCerts:   config.CrossRevokedCerts,
 		tidyAcme:                config.TidyAcme,
+		tidyCertMetadata:        config.CertMetadata,
+		tidyOrphanedAssocs:      config.OrphanedAssocs,
 		pauseDuration:           config.PauseDuration.String(),
 
 		state:       tidyStatusStarted,
@@ -1891,6 +2569,7 @@ func (b *backend) tidyStatusStop(err error) {
 	defer b.tidyStatusLock.Unlock()
 
 	b.tidyStatus.timeFinished = time.Now()
+	b.tidyStatus.endPhase(b.tidyStatus.timeFinished)
 	b.tidyStatus.err = err
 	if err == nil {
 		b.tidyStatus.state = tidyStatusFinished
@@ -1912,6 +2591,67 @@ func (b *backend) tidyStatusStop(err error) {
 	}
 }
 
+func (b *backend) tidyStatusStartPhase(phase string) {
+	b.tidyStatusLock.Lock()
+	defer b.tidyStatusLock.Unlock()
+
+	b.tidyStatus.phase = phase
+	b.tidyStatus.phaseStarted = time.Now()
+	b.tidyStatus.phaseTotal = 0
+	b.tidyStatus.phaseScanned = 0
+}
+
+func (b *backend) tidyStatusEndPhase() {
+	b.tidyStatusLock.Lock()
+	defer b.tidyStatusLock.Unlock()
+
+	b.tidyStatus.endPhase(time.Now())
+}
+
+// tidyStatusAddPhaseTotal adds to the number of entries the current phase
+// has to scan; phases listing entries in several batches call it for each.
+func (b *backend) tidyStatusAddPhaseTotal(count int) {
+	b.tidyStatusLock.Lock()
+	defer b.tidyStatusLock.Unlock()
+
+	b.tidyStatus.phaseTotal += uint(count)
+}
+
+func (b *backend) tidyStatusIncScannedCount() {
+	b.tidyStatusLock.Lock()
+	defer b.tidyStatusLock.Unlock()
+
+	b.tidyStatus.phaseScanned++
+	b.tidyStatus.scannedCount++
+}
+
+func (b *backend) tidyIsPaused() bool {
+	return atomic.LoadUint32(b.tidyPauseCAS) == 1
+}
+
+// tidyWaitWhilePaused blocks while the tidy operation is paused, returning
+// early when it gets cancelled. Callers release the storage locks they hold
+// beforehand, so a paused tidy doesn't hold up other operations. Once the
+// backend is cleaned up, the operation is cancelled, paused or not.
+func (b *backend) tidyWaitWhilePaused() {
+	for {
+		select {
+		case <-b.tidyStopCh:
+			atomic.StoreUint32(b.tidyCancelCAS, 1)
+			return
+		default:
+		}
+		if !b.tidyIsPaused() || atomic.LoadUint32(b.tidyCancelCAS) != 0 {
+			return
+		}
+
+		select {
+		case <-b.tidyStopCh:
+		case <-time.After(tidyPausePollInterval):
+		}
+	}
+}
+
 func (b *backend) tidyStatusMessage(msg string) {
 	b.tidyStatusLock.Lock()
 	defer b.tidyStatusLock.Unlock()
@@ -1951,6 +2691,20 @@ func (b *backend) tidyStatusIncRevQueueCount() {
 	b.tidyStatus.revQueueDeletedCount++
 }
 
+func (b *backend) tidyStatusIncCertMetadataCount() {
+	b.tidyStatusLock.Lock()
+	defer b.tidyStatusLock.Unlock()
+
+	b.tidyStatus.certMetadataDeletedCount++
+}
+
+func (b *backend) tidyStatusIncOrphanedAssocsCount() {
+	b.tidyStatusLock.Lock()
+	defer b.tidyStatusLock.Unlock()
+
+	b.tidyStatus.orphanedAssocsDeletedCount++
+}
+
 func (b *backend) tidyStatusIncCrossRevCertCount() {
 	b.tidyStatusLock.Lock()
 	defer b.tidyStatusLock.Unlock()
@@ -2018,6 +2772,29 @@ has been requested to be cancelled. If so, we'll stop the currently running
 tidy operation.
 `
 
+const pathTidyPauseHelpSyn = `
+Pauses a currently running tidy operation.
+`
+
+const pathTidyPauseHelpDesc = `
+This endpoint allows pausing a currently running tidy operation, for instance
+to free up resources during peak load. The operation stops before the next
+entry it would examine, releasing any storage locks it holds, until it is
+resumed with the tidy-resume endpoint or cancelled with tidy-cancel.
+
+Pausing isn't persisted: a paused operation doesn't survive a restart or
+leadership change of the node running it.
+`
+
+const pathTidyResumeHelpSyn = `
+Resumes a paused tidy operation.
+`
+
+const pathTidyResumeHelpDesc = `
+This endpoint allows resuming a tidy operation paused with the tidy-pause
+endpoint. It continues where it left off.
+`
+
 const pathTidyStatusHelpSyn = `
 Returns the status of the tidy operation.
 `
@@ -2031,7 +2808,7 @@ The result includes the following fields:
 * 'tidy_cert_store': the value of this parameter when initiating the tidy operation
 * 'tidy_revoked_certs': the value of this parameter when initiating the tidy operation
 * 'tidy_revoked_cert_issuer_associations': the value of this parameter when initiating the tidy operation
-* 'state': one of "Inactive", "Running", "Finished", "Error"
+* 'state': one of "Inactive", "Running", "Paused", "Cancelling", "Cancelled", "Finished", "Error"
 * 'error': the error message, if the operation ran into an error
 * 'time_started': the time the operation started
 * 'time_finished': the time the operation finished
@@ -2054,6 +2831,17 @@ The result includes the following fields:
 * 'acme_account_deleted_count': the number of revoked acme accounts deleted during the operation
 * 'acme_account_revoked_count': the number of acme accounts revoked during the operation
 * 'acme_orders_deleted_count': the number of acme orders deleted during the operation
+* 'current_phase': the phase of the operation currently running, if any
+* 'current_phase_entries_total': the number of entries the current phase has to scan
+* 'current_phase_entries_scanned': the number of entries the current phase has scanned so far
+* 'current_phase_time_remaining': an estimate of the time left in the current phase
+* 'entries_scanned_count': the number of entries scanned across all phases
+* 'phase_durations': the time taken by each finished phase, excluding time spent paused
+* 'time_paused': the time the operation was paused, if it currently is
+* 'tidy_cert_metadata': the value of this parameter when initiating the tidy operation
+* 'cert_metadata_deleted_count': the number of certificate metadata entries deleted
+* 'tidy_orphaned_issuer_associations': the value of this parameter when initiating the tidy operation
+* 'orphaned_issuer_association_deleted_count': the number of references to deleted issuers and keys removed
 `
 
 const pathConfigAutoTidySyn = `
@@ -2081,6 +2869,8 @@ func getTidyConfigData(config tidyConfig) map[string]interface{} {
 		"tidy_expired_issuers":                     config.ExpiredIssuers,
 		"tidy_move_legacy_ca_bundle":               config.BackupBundle,
 		"tidy_acme":                                config.TidyAcme,
+		"tidy_cert_metadata":                       config.CertMetadata,
+		"tidy_orphaned_issuer_associations":        config.OrphanedAssocs,
 		"safety_buffer":                            int(config.SafetyBuffer / time.Second),
 		"issuer_safety_buffer":                     int(config.IssuerSafetyBuffer / time.Second),
 		"acme_account_safety_buffer":               int(config.AcmeAccountSafetyBuffer / time.Second),
@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"path"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -427,6 +428,146 @@ func TestTidyCancellation(t *testing.T) {
 	}
 }
 
+func TestTidyPauseResume(t *testing.T) {
+	t.Parallel()
+
+	numLeaves := 20
+
+	b, s := CreateBackendWithStorage(t)
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"issuer_name": "root",
+		"ttl":         "20m",
+		"key_type":    "ec",
+	})
+	require.NoError(t, err)
+	_, err = CBWrite(b, s, "roles/local-testing", map[string]interface{}{
+		"allow_any_name":    true,
+		"enforce_hostnames": false,
+		"key_type":          "ec",
+	})
+	require.NoError(t, err)
+	for i := 0; i < numLeaves; i++ {
+		_, err = CBWrite(b, s, "issue/local-testing", map[string]interface{}{
+			"common_name": "testing",
+			"ttl":         "1s",
+		})
+		require.NoError(t, err)
+	}
+
+	// Nothing to pause or resume yet.
+	resp, err := CBWrite(b, s, "tidy-pause", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "tidy-pause")
+	require.Contains(t, resp.Warnings[0], "cannot be paused")
+	resp, err = CBWrite(b, s, "tidy-resume", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "tidy-resume")
+	require.Contains(t, resp.Warnings[0], "cannot be resumed")
+
+	resp, err = CBWrite(b, s, "tidy", map[string]interface{}{
+		"tidy_cert_store": true,
+		"safety_buffer":   "1s",
+		"pause_duration":  "500ms",
+	})
+	require.NoError(t, err)
+	time.Sleep(2 * time.Second)
+
+	resp, err = CBWrite(b, s, "tidy-pause", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "tidy-pause")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("tidy-pause"), logical.UpdateOperation), resp, true)
+	require.Empty(t, resp.Warnings)
+	require.Equal(t, "Paused", resp.Data["state"])
+	require.NotNil(t, resp.Data["time_paused"])
+	require.Equal(t, tidyPhaseCertStore, resp.Data["current_phase"])
+	// The root is kept in the certificate store alongside the leaves.
+	require.Equal(t, uint(numLeaves+1), resp.Data["current_phase_entries_total"])
+	scanned := resp.Data["current_phase_entries_scanned"].(uint)
+	require.Greater(t, scanned, uint(0))
+	require.Less(t, scanned, uint(numLeaves))
+
+	// A paused operation makes no progress, beyond the entry it may have
+	// been working on.
+	time.Sleep(2 * time.Second)
+	resp, err = CBRead(b, s, "tidy-status")
+	requireSuccessNonNilResponse(t, resp, err, "tidy-status")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("tidy-status"), logical.ReadOperation), resp, true)
+	require.Equal(t, "Paused", resp.Data["state"])
+	require.LessOrEqual(t, resp.Data["current_phase_entries_scanned"].(uint), scanned+1)
+
+	resp, err = CBWrite(b, s, "tidy-resume", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "tidy-resume")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("tidy-resume"), logical.UpdateOperation), resp, true)
+	require.Empty(t, resp.Warnings)
+	require.Equal(t, "Running", resp.Data["state"])
+	require.Nil(t, resp.Data["time_paused"])
+
+	// Paused operations can be cancelled too.
+	resp, err = CBWrite(b, s, "tidy-pause", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "tidy-pause")
+	require.Equal(t, "Paused", resp.Data["state"])
+	resp, err = CBWrite(b, s, "tidy-cancel", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "tidy-cancel")
+	require.Equal(t, "Cancelling", resp.Data["state"])
+
+	require.Eventually(t, func() bool {
+		resp, err = CBRead(b, s, "tidy-status")
+		return err == nil && resp != nil && resp.Data["state"] == "Cancelled"
+	}, 5*time.Second, 100*time.Millisecond)
+	require.Nil(t, resp.Data["current_phase"])
+	require.Contains(t, resp.Data["phase_durations"], tidyPhaseCertStore)
+	require.Greater(t, resp.Data["entries_scanned_count"].(uint), scanned)
+}
+
+// TestTidyPausedStopsOnCleanup ensures that a paused tidy operation exits
+// when the mount is torn down, rather than waiting to be resumed.
+func TestTidyPausedStopsOnCleanup(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"issuer_name": "root",
+		"ttl":         "20m",
+		"key_type":    "ec",
+	})
+	require.NoError(t, err)
+	_, err = CBWrite(b, s, "roles/local-testing", map[string]interface{}{
+		"allow_any_name":    true,
+		"enforce_hostnames": false,
+		"key_type":          "ec",
+	})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = CBWrite(b, s, "issue/local-testing", map[string]interface{}{
+			"common_name": "testing",
+			"ttl":         "1s",
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = CBWrite(b, s, "tidy", map[string]interface{}{
+		"tidy_cert_store": true,
+		"safety_buffer":   "1s",
+		"pause_duration":  "500ms",
+	})
+	require.NoError(t, err)
+	time.Sleep(2 * time.Second)
+
+	resp, err := CBWrite(b, s, "tidy-pause", map[string]interface{}{})
+	requireSuccessNonNilResponse(t, resp, err, "tidy-pause")
+	require.Equal(t, "Paused", resp.Data["state"])
+
+	b.cleanup(context.Background())
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint32(b.tidyCASGuard) == 0
+	}, 5*time.Second, 100*time.Millisecond)
+	resp, err = CBRead(b, s, "tidy-status")
+	requireSuccessNonNilResponse(t, resp, err, "tidy-status")
+	require.Equal(t, "Cancelled", resp.Data["state"])
+}
+
 func TestTidyIssuers(t *testing.T) {
 	t.Parallel()
 
@@ -534,6 +675,132 @@ func TestTidyIssuers(t *testing.T) {
 	require.Equal(t, statusResp.Data["tidy_expired_issuers"], true)
 }
 
+func TestTidyCertMetadataAndOrphanedIssuerAssociations(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+	ctx := context.Background()
+	sc := b.makeStorageContext(ctx, s)
+
+	_, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root1 example.com",
+		"issuer_name": "root1",
+		"ttl":         "60m",
+		"key_type":    "ec",
+	})
+	require.NoError(t, err)
+	_, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root2 example.com",
+		"issuer_name": "root2",
+		"ttl":         "60m",
+		"key_type":    "ec",
+	})
+	require.NoError(t, err)
+	_, err = CBWrite(b, s, "roles/local-testing", map[string]interface{}{
+		"allow_any_name":    true,
+		"enforce_hostnames": false,
+		"key_type":          "ec",
+	})
+	require.NoError(t, err)
+
+	issue := func(ttl string) string {
+		resp, err := CBWrite(b, s, "issue/local-testing", map[string]interface{}{
+			"common_name": "testing",
+			"ttl":         ttl,
+		})
+		requireSuccessNonNilResponse(t, resp, err, "issue/local-testing")
+		return normalizeSerial(resp.Data["serial_number"].(string))
+	}
+
+	// Metadata whose certificate was removed without it.
+	keptSerial := issue("30m")
+	orphanedSerial := issue("30m")
+	require.NoError(t, s.Delete(ctx, "certs/"+orphanedSerial))
+
+	// A revocation request for a certificate of ours which has expired,
+	// and one for a certificate we don't know about.
+	expiredSerial := issue("1s")
+	time.Sleep(2 * time.Second)
+	for _, serial := range []string{expiredSerial, "00-11-22-33"} {
+		entry, err := logical.StorageEntryJSON(crossRevocationPrefix+"other-cluster/"+serial, revocationRequest{RequestedAt: time.Now()})
+		require.NoError(t, err)
+		require.NoError(t, s.Put(ctx, entry))
+	}
+
+	// Deleting an issuer leaves its CRL and the CRL configuration behind.
+	_, err = CBDelete(b, s, "issuer/root2")
+	require.NoError(t, err)
+	crlConfig, err := sc.getLocalCRLConfig()
+	require.NoError(t, err)
+	require.Len(t, crlConfig.IssuerIDCRLMap, 2)
+	require.Len(t, crlConfig.CRLNumberMap, 2)
+	storedCRLs, err := s.List(ctx, "crls/")
+	require.NoError(t, err)
+	require.Len(t, storedCRLs, 5)
+
+	// A default key setting whose clearing failed when deleting the key.
+	require.NoError(t, sc.setKeysConfig(&keyConfigEntry{DefaultKeyId: keyID("deleted-key")}))
+
+	resp, err := CBWrite(b, s, "tidy", map[string]interface{}{
+		"tidy_cert_metadata":                true,
+		"tidy_revocation_queue":             true,
+		"tidy_orphaned_issuer_associations": true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	require.Eventually(t, func() bool {
+		resp, err = CBRead(b, s, "tidy-status")
+		return err == nil && resp != nil && resp.Data["state"] != "Running"
+	}, 10*time.Second, 100*time.Millisecond)
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("tidy-status"), logical.ReadOperation), resp, true)
+	require.Equal(t, "Finished", resp.Data["state"], "tidy failed: %v", resp.Data["error"])
+	require.Equal(t, uint(1), resp.Data["cert_metadata_deleted_count"])
+	require.Equal(t, uint(1), resp.Data["revocation_queue_deleted_count"])
+	// The default key, and root2's CRL mapping, numbering and stored
+	// complete and delta CRLs, both local and unified.
+	require.Equal(t, uint(9), resp.Data["orphaned_issuer_association_deleted_count"])
+
+	metadata, err := sc.fetchCertMetadata(orphanedSerial)
+	require.NoError(t, err)
+	require.Nil(t, metadata)
+	metadata, err = sc.fetchCertMetadata(keptSerial)
+	require.NoError(t, err)
+	require.NotNil(t, metadata)
+
+	requests, err := s.List(ctx, crossRevocationPrefix+"other-cluster/")
+	require.NoError(t, err)
+	require.Equal(t, []string{"00-11-22-33"}, requests)
+
+	keysConfig, err := sc.getKeysConfig()
+	require.NoError(t, err)
+	require.Empty(t, keysConfig.DefaultKeyId)
+
+	crlConfig, err = sc.getLocalCRLConfig()
+	require.NoError(t, err)
+	require.Len(t, crlConfig.IssuerIDCRLMap, 1)
+	require.Len(t, crlConfig.CRLNumberMap, 1)
+	require.Len(t, crlConfig.LastCompleteNumberMap, 1)
+	storedCRLs, err = s.List(ctx, "crls/")
+	require.NoError(t, err)
+	for _, id := range crlConfig.IssuerIDCRLMap {
+		require.ElementsMatch(t, []string{"config", id.String(), id.String() + deltaCRLPathSuffix}, storedCRLs)
+	}
+
+	crlConfig, err = sc.getUnifiedCRLConfig()
+	require.NoError(t, err)
+	require.Len(t, crlConfig.IssuerIDCRLMap, 1)
+	require.Len(t, crlConfig.CRLNumberMap, 1)
+	storedCRLs, err = s.List(ctx, "unified-crls/")
+	require.NoError(t, err)
+	require.Len(t, storedCRLs, 3)
+
+	// The remaining issuer's CRL is still served.
+	resp, err = CBRead(b, s, "issuer/root1/crl")
+	requireSuccessNonNilResponse(t, resp, err, "issuer/root1/crl")
+	require.NotEmpty(t, resp.Data["crl"])
+}
+
 func TestTidyIssuerConfig(t *testing.T) {
 	t.Parallel()
 
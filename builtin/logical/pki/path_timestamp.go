@@ -0,0 +1,280 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	timestampQueryContentType   = "application/timestamp-query"
+	timestampReplyContentType   = "application/timestamp-reply"
+	timestampMaximumRequestSize = 16 * 1024
+
+	pathTimestampHelpSyn  = `An endpoint implementing the RFC 3161 Time-Stamp Protocol`
+	pathTimestampHelpDesc = `This API endpoint accepts DER encoded TimeStampReq messages
+with the application/timestamp-query content type, and responds with DER
+encoded TimeStampResp messages, as defined in RFC 3161. A time-stamp client,
+such as openssl ts or signtool, should be used to interact with it.
+
+The endpoint is configured through config/timestamping.`
+)
+
+var (
+	oidCTTSTInfo                 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+	oidAttributeSigningCertV2    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	oidTimestampDigestSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidTimestampDigestSHA384     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidTimestampDigestSHA512     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	timestampAcceptedDigestSizes = []struct {
+		oid  asn1.ObjectIdentifier
+		size int
+	}{
+		{oidTimestampDigestSHA256, 32},
+		{oidTimestampDigestSHA384, 48},
+		{oidTimestampDigestSHA512, 64},
+	}
+)
+
+// PKIStatus and PKIFailureInfo values, RFC 3161 Section 2.4.2.
+const (
+	timestampStatusGranted   = 0
+	timestampStatusRejection = 2
+
+	timestampFailBadAlg              = 0
+	timestampFailBadRequest          = 2
+	timestampFailBadDataFormat       = 5
+	timestampFailUnacceptedPolicy    = 15
+	timestampFailUnacceptedExtension = 16
+	timestampFailSystemFailure       = 25
+)
+
+type timestampMessageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timestampReq struct {
+	Version        int
+	MessageImprint timestampMessageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional,default:false"`
+	Extensions     []pkix.Extension      `asn1:"optional,tag:0"`
+}
+
+type timestampTSTInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint timestampMessageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+type timestampStatusInfo struct {
+	Status   int
+	FailInfo asn1.BitString `asn1:"optional"`
+}
+
+type timestampResp struct {
+	Status         timestampStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+// ESS signing-certificate-v2 attribute, RFC 5816; the hash algorithm of
+// the certificate identifier defaults to SHA-256.
+type timestampSigningCertificateV2 struct {
+	Certs []timestampESSCertIDv2
+}
+
+type timestampESSCertIDv2 struct {
+	CertHash []byte
+}
+
+func pathTimestamp(b *backend) *framework.Path {
+	return &framework.Path{
+		Pattern: "timestamp",
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.pathTimestampWrite,
+			},
+		},
+
+		HelpSynopsis:    pathTimestampHelpSyn,
+		HelpDescription: pathTimestampHelpDesc,
+	}
+}
+
+func (b *backend) pathTimestampWrite(ctx context.Context, req *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+	sc := b.makeStorageContext(ctx, req.Storage)
+	config, err := sc.getTimestampingConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !config.Enabled {
+		return logical.ErrorResponse("timestamping is disabled on this mount"), nil
+	}
+
+	message, err := getTimestampQuery(req)
+	if err != nil {
+		return logical.ErrorResponse("failed reading time-stamp request: %v", err), nil
+	}
+
+	var query timestampReq
+	if rest, err := asn1.Unmarshal(message, &query); err != nil || len(rest) > 0 {
+		return timestampRejection(timestampFailBadDataFormat)
+	}
+	if query.Version != 1 {
+		return timestampRejection(timestampFailBadRequest)
+	}
+
+	validDigest := false
+	for _, accepted := range timestampAcceptedDigestSizes {
+		if query.MessageImprint.HashAlgorithm.Algorithm.Equal(accepted.oid) {
+			if len(query.MessageImprint.HashedMessage) != accepted.size {
+				return timestampRejection(timestampFailBadDataFormat)
+			}
+			validDigest = true
+		}
+	}
+	if !validDigest {
+		return timestampRejection(timestampFailBadAlg)
+	}
+
+	policy, err := certutil.StringToOid(config.PolicyOID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configured policy_oid: %w", err)
+	}
+	if len(query.ReqPolicy) > 0 && !query.ReqPolicy.Equal(policy) {
+		return timestampRejection(timestampFailUnacceptedPolicy)
+	}
+	if len(query.Extensions) > 0 {
+		return timestampRejection(timestampFailUnacceptedExtension)
+	}
+
+	token, err := b.signTimestamp(sc, config, policy, &query)
+	if err != nil {
+		b.Logger().Error("failed signing time-stamp token", "error", err)
+		return timestampRejection(timestampFailSystemFailure)
+	}
+
+	return timestampResponse(timestampResp{
+		Status:         timestampStatusInfo{Status: timestampStatusGranted},
+		TimeStampToken: asn1.RawValue{FullBytes: token},
+	})
+}
+
+// signTimestamp builds the TSTInfo for the request and signs it into a
+// time-stamp token, a CMS SignedData structure, RFC 3161 Section 2.4.2.
+func (b *backend) signTimestamp(sc *storageContext, config *timestampingConfigEntry, policy asn1.ObjectIdentifier, query *timestampReq) ([]byte, error) {
+	signer, err := sc.fetchTimestampingSigner(config)
+	if err != nil {
+		return nil, err
+	}
+
+	serialNumber, err := certutil.GenerateSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	tstInfo, err := asn1.Marshal(timestampTSTInfo{
+		Version:        1,
+		Policy:         policy,
+		MessageImprint: query.MessageImprint,
+		SerialNumber:   serialNumber,
+		GenTime:        time.Now().UTC(),
+		Nonce:          query.Nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sd, err := pkcs7.NewSignedData(tstInfo)
+	if err != nil {
+		return nil, err
+	}
+	sd.SetContentType(oidCTTSTInfo)
+
+	certHash := sha256.Sum256(signer.Certificate.Raw)
+	attrs := []pkcs7.Attribute{
+		{
+			Type: oidAttributeSigningCertV2,
+			Value: timestampSigningCertificateV2{
+				Certs: []timestampESSCertIDv2{{CertHash: certHash[:]}},
+			},
+		},
+	}
+	if err := sd.AddSigner(signer.Certificate, signer.PrivateKey, pkcs7.SignerInfoConfig{ExtraSignedAttributes: attrs}); err != nil {
+		return nil, err
+	}
+
+	// The TSA's certificate must only be included when requested.
+	if !query.CertReq {
+		sd.RemoveCertificates()
+	}
+
+	return sd.Finish()
+}
+
+func getTimestampQuery(req *logical.Request) ([]byte, error) {
+	// NOTE: Writing an empty update request to Vault causes a nil request.HTTPRequest, and that object
+	//       says that it is possible for its Body element to be nil as well, so check both just in case.
+	if req.HTTPRequest == nil || req.HTTPRequest.Body == nil {
+		return nil, fmt.Errorf("no data in request body; the Content-Type must be %s", timestampQueryContentType)
+	}
+	defer req.HTTPRequest.Body.Close()
+
+	message, err := io.ReadAll(io.LimitReader(req.HTTPRequest.Body, timestampMaximumRequestSize))
+	if err != nil {
+		return nil, err
+	}
+	if len(message) >= timestampMaximumRequestSize {
+		return nil, errors.New("request is too large")
+	}
+
+	return message, nil
+}
+
+func timestampRejection(failInfo int) (*logical.Response, error) {
+	bits := make([]byte, failInfo/8+1)
+	bits[failInfo/8] = 0x80 >> (failInfo % 8)
+
+	return timestampResponse(timestampResp{
+		Status: timestampStatusInfo{
+			Status:   timestampStatusRejection,
+			FailInfo: asn1.BitString{Bytes: bits, BitLength: failInfo + 1},
+		},
+	})
+}
+
+func timestampResponse(resp timestampResp) (*logical.Response, error) {
+	der, err := asn1.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			logical.HTTPContentType: timestampReplyContentType,
+			logical.HTTPStatusCode:  http.StatusOK,
+			logical.HTTPRawBody:     der,
+		},
+	}, nil
+}
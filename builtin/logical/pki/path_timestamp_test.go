@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/vault/builtin/credential/aws/pkcs7"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestamping(t *testing.T) {
+	t.Parallel()
+
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "Code Signing Root",
+		"key_type":    "ec",
+		"issuer_name": "tsa-issuer",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	rootCert := parseCert(t, resp.Data["certificate"].(string))
+	rootKeyId := resp.Data["key_id"].(keyID)
+
+	resp, err = CBWrite(b, s, "keys/generate/internal", map[string]interface{}{
+		"key_type": "rsa",
+		"key_name": "tsa",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+
+	digest := sha256.Sum256([]byte("release artifact"))
+	query := timestampReq{
+		Version: 1,
+		MessageImprint: timestampMessageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidTimestampDigestSHA256},
+			HashedMessage: digest[:],
+		},
+		Nonce:   big.NewInt(42),
+		CertReq: true,
+	}
+
+	// Disabled by default.
+	_, err = timestampRequest(b, s, query)
+	require.ErrorContains(t, err, "timestamping is disabled")
+
+	_, err = CBWrite(b, s, "config/timestamping", map[string]interface{}{
+		"enabled":    true,
+		"policy_oid": "1.2.3.4",
+	})
+	require.ErrorContains(t, err, "issuer_ref and key_ref are required")
+
+	_, err = CBWrite(b, s, "config/timestamping", map[string]interface{}{
+		"issuer_ref": "tsa-issuer",
+		"key_ref":    rootKeyId.String(),
+	})
+	require.ErrorContains(t, err, "is the issuer's own key")
+
+	resp, err = CBWrite(b, s, "config/timestamping", map[string]interface{}{
+		"enabled":    true,
+		"issuer_ref": "tsa-issuer",
+		"key_ref":    "tsa",
+		"policy_oid": "1.2.3.4",
+	})
+	requireSuccessNonNilResponse(t, resp, err)
+	tsaCert := parseCert(t, resp.Data["certificate"].(string))
+	requireSignedBy(t, tsaCert, rootCert)
+	require.Equal(t, []x509.ExtKeyUsage{x509.ExtKeyUsageTimeStamping}, tsaCert.ExtKeyUsage)
+	for _, ext := range tsaCert.Extensions {
+		if ext.Id.Equal(oidExtensionExtendedKeyUsage) {
+			require.True(t, ext.Critical)
+		}
+	}
+
+	// The certificate is tracked like issued ones.
+	resp, err = CBRead(b, s, "cert/"+serialFromCert(tsaCert))
+	requireSuccessNonNilResponse(t, resp, err)
+
+	reply, err := timestampRequest(b, s, query)
+	require.NoError(t, err)
+	require.Equal(t, timestampStatusGranted, reply.Status.Status)
+
+	token, err := pkcs7.Parse(reply.TimeStampToken.FullBytes)
+	require.NoError(t, err)
+	require.NoError(t, token.Verify())
+	require.Len(t, token.Certificates, 1)
+	require.Equal(t, tsaCert.Raw, token.Certificates[0].Raw)
+
+	var tstInfo timestampTSTInfo
+	_, err = asn1.Unmarshal(token.Content, &tstInfo)
+	require.NoError(t, err)
+	require.Equal(t, asn1.ObjectIdentifier{1, 2, 3, 4}, tstInfo.Policy)
+	require.Equal(t, digest[:], tstInfo.MessageImprint.HashedMessage)
+	require.Equal(t, int64(42), tstInfo.Nonce.Int64())
+
+	// The certificate is only included on request.
+	query.CertReq = false
+	reply, err = timestampRequest(b, s, query)
+	require.NoError(t, err)
+	require.Equal(t, timestampStatusGranted, reply.Status.Status)
+	token, err = pkcs7.Parse(reply.TimeStampToken.FullBytes)
+	require.NoError(t, err)
+	require.Empty(t, token.Certificates)
+
+	query.ReqPolicy = asn1.ObjectIdentifier{1, 2, 3, 5}
+	reply, err = timestampRequest(b, s, query)
+	require.NoError(t, err)
+	require.Equal(t, timestampStatusRejection, reply.Status.Status)
+	require.Equal(t, 1, reply.Status.FailInfo.At(timestampFailUnacceptedPolicy))
+
+	sha1Digest := sha1.Sum([]byte("release artifact"))
+	query.ReqPolicy = nil
+	query.MessageImprint = timestampMessageImprint{
+		HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}},
+		HashedMessage: sha1Digest[:],
+	}
+	reply, err = timestampRequest(b, s, query)
+	require.NoError(t, err)
+	require.Equal(t, timestampStatusRejection, reply.Status.Status)
+	require.Equal(t, 1, reply.Status.FailInfo.At(timestampFailBadAlg))
+}
+
+func timestampRequest(b *backend, s logical.Storage, query timestampReq) (*timestampResp, error) {
+	der, err := asn1.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/v1/pki/timestamp", bytes.NewReader(der))
+	httpReq.Header.Set("Content-Type", timestampQueryContentType)
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation:   logical.UpdateOperation,
+		Path:        "timestamp",
+		Storage:     s,
+		MountPoint:  "pki/",
+		HTTPRequest: httpReq,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.IsError() {
+		return nil, resp.Error()
+	}
+
+	var reply timestampResp
+	if _, err := asn1.Unmarshal(resp.Data[logical.HTTPRawBody].([]byte), &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
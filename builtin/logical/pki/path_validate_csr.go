@@ -0,0 +1,259 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func pathValidateCSR(b *backend) *framework.Path {
+	ret := &framework.Path{
+		Pattern: "validate-csr/" + framework.GenericNameRegex("role"),
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: operationPrefixPKI,
+			OperationVerb:   "validate",
+			OperationSuffix: "csr-with-role",
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.metricsWrap("validate-csr", roleRequired, b.pathValidateCSR),
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"valid": {
+								Type:        framework.TypeBool,
+								Description: `Whether the CSR would be signed`,
+								Required:    true,
+							},
+							"rejected": {
+								Type:        framework.TypeStringSlice,
+								Description: `Reasons the CSR would be rejected`,
+								Required:    true,
+							},
+							"stripped": {
+								Type:        framework.TypeStringSlice,
+								Description: `Values of the CSR which would not be included in the certificate`,
+								Required:    true,
+							},
+							"common_name": {
+								Type:        framework.TypeString,
+								Description: `Common name of the certificate`,
+								Required:    false,
+							},
+							"alt_names": {
+								Type:        framework.TypeStringSlice,
+								Description: `DNS and email subject alternative names of the certificate`,
+								Required:    false,
+							},
+							"ip_sans": {
+								Type:        framework.TypeStringSlice,
+								Description: `IP subject alternative names of the certificate`,
+								Required:    false,
+							},
+							"uri_sans": {
+								Type:        framework.TypeStringSlice,
+								Description: `URI subject alternative names of the certificate`,
+								Required:    false,
+							},
+							"not_after": {
+								Type:        framework.TypeString,
+								Description: `Time the certificate would expire at`,
+								Required:    false,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    pathValidateCSRHelpSyn,
+		HelpDescription: pathValidateCSRHelpDesc,
+	}
+
+	ret.Fields = addNonCACommonFields(map[string]*framework.FieldSchema{})
+
+	ret.Fields["csr"] = &framework.FieldSchema{
+		Type:        framework.TypeString,
+		Default:     "",
+		Description: `PEM-format CSR to be validated.`,
+	}
+
+	ret.Fields = addKeyAttestationField(ret.Fields)
+
+	return ret
+}
+
+// pathValidateCSR evaluates a CSR against the role's policy the same way
+// sign/:role does, reporting the outcome instead of signing it.
+func (b *backend) pathValidateCSR(ctx context.Context, req *logical.Request, data *framework.FieldData, role *roleEntry) (*logical.Response, error) {
+	issuerName := role.Issuer
+	if len(issuerName) == 0 {
+		issuerName = defaultRef
+	}
+
+	sc := b.makeStorageContext(ctx, req.Storage)
+	signingBundle, _, err := sc.fetchCAInfoWithIssuer(issuerName, IssuanceUsage)
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return nil, errutil.UserError{Err: fmt.Sprintf(
+				"could not fetch the CA certificate (was one set?): %s", err)}
+		default:
+			return nil, errutil.InternalError{Err: fmt.Sprintf(
+				"error fetching CA certificate: %s", err)}
+		}
+	}
+
+	input := &inputBundle{
+		req:     req,
+		apiData: data,
+		role:    role,
+	}
+	creation, warnings, err := prepareSignCert(ctx, b, input, signingBundle, false, false)
+	if err != nil {
+		switch err.(type) {
+		case errutil.UserError:
+			return &logical.Response{
+				Data: map[string]interface{}{
+					"valid":    false,
+					"rejected": []string{err.Error()},
+					"stripped": []string{},
+				},
+			}, nil
+		case errutil.InternalError:
+			return nil, err
+		default:
+			return nil, fmt.Errorf("error validating CSR: %w", err)
+		}
+	}
+
+	params := creation.Params
+	altNames := append(append([]string{}, params.DNSNames...), params.EmailAddresses...)
+	ipSANs := make([]string, 0, len(params.IPAddresses))
+	for _, ip := range params.IPAddresses {
+		ipSANs = append(ipSANs, ip.String())
+	}
+	uriSANs := make([]string, 0, len(params.URIs))
+	for _, uri := range params.URIs {
+		uriSANs = append(uriSANs, uri.String())
+	}
+
+	resp := &logical.Response{
+		Data: map[string]interface{}{
+			"valid":       true,
+			"rejected":    []string{},
+			"stripped":    strippedCSRValues(creation.CSR, params),
+			"common_name": params.Subject.CommonName,
+			"alt_names":   altNames,
+			"ip_sans":     ipSANs,
+			"uri_sans":    uriSANs,
+			"not_after":   params.NotAfter.UTC().Format(time.RFC3339),
+		},
+	}
+
+	return addWarnings(resp, warnings), nil
+}
+
+// strippedCSRValues lists the values requested in the CSR which the
+// certificate issued with the given parameters would not contain.
+func strippedCSRValues(csr *x509.CertificateRequest, params *certutil.CreationParameters) []string {
+	stripped := []string{}
+
+	if csr.Subject.CommonName != "" && csr.Subject.CommonName != params.Subject.CommonName {
+		stripped = append(stripped, fmt.Sprintf("common name %q", csr.Subject.CommonName))
+	}
+
+	subjectFields := []struct {
+		name      string
+		requested []string
+		issued    []string
+	}{
+		{"organization", csr.Subject.Organization, params.Subject.Organization},
+		{"organizational unit", csr.Subject.OrganizationalUnit, params.Subject.OrganizationalUnit},
+		{"country", csr.Subject.Country, params.Subject.Country},
+		{"locality", csr.Subject.Locality, params.Subject.Locality},
+		{"province", csr.Subject.Province, params.Subject.Province},
+		{"street address", csr.Subject.StreetAddress, params.Subject.StreetAddress},
+		{"postal code", csr.Subject.PostalCode, params.Subject.PostalCode},
+	}
+	for _, field := range subjectFields {
+		stripped = appendMissing(stripped, field.name, field.requested, field.issued)
+	}
+	if csr.Subject.SerialNumber != "" && csr.Subject.SerialNumber != params.Subject.SerialNumber {
+		stripped = append(stripped, fmt.Sprintf("serial number %q", csr.Subject.SerialNumber))
+	}
+
+	stripped = appendMissing(stripped, "DNS SAN", csr.DNSNames, params.DNSNames)
+	stripped = appendMissing(stripped, "email SAN", csr.EmailAddresses, params.EmailAddresses)
+
+	var requestedIPs, issuedIPs []string
+	for _, ip := range csr.IPAddresses {
+		requestedIPs = append(requestedIPs, ip.String())
+	}
+	for _, ip := range params.IPAddresses {
+		issuedIPs = append(issuedIPs, ip.String())
+	}
+	stripped = appendMissing(stripped, "IP SAN", requestedIPs, issuedIPs)
+
+	var requestedURIs, issuedURIs []string
+	for _, uri := range csr.URIs {
+		requestedURIs = append(requestedURIs, uri.String())
+	}
+	for _, uri := range params.URIs {
+		issuedURIs = append(issuedURIs, uri.String())
+	}
+	stripped = appendMissing(stripped, "URI SAN", requestedURIs, issuedURIs)
+
+	// Extensions requested in the CSR are never copied; the certificate's
+	// extensions are determined by the role alone.
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(oidExtensionSubjectAltName) {
+			continue
+		}
+		stripped = append(stripped, fmt.Sprintf("extension %v", ext.Id))
+	}
+
+	return stripped
+}
+
+func appendMissing(stripped []string, name string, requested []string, issued []string) []string {
+	for _, value := range requested {
+		found := false
+		for _, candidate := range issued {
+			if candidate == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			stripped = append(stripped, fmt.Sprintf("%v %q", name, value))
+		}
+	}
+
+	return stripped
+}
+
+const pathValidateCSRHelpSyn = `
+Validate a CSR against the policy of a role, without signing it.
+`
+
+const pathValidateCSRHelpDesc = `
+This path evaluates a CSR and request parameters the same way the sign
+path of the given role does, without issuing a certificate. It reports
+whether the CSR would be signed, why it would be rejected otherwise, and
+which values requested in the CSR would not be included in the
+certificate.
+`
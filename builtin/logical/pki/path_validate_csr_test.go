@@ -0,0 +1,105 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_ValidateCSR(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+		"organization":     "Example Inc",
+		"use_csr_sans":     false,
+		"max_ttl":          "2h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	resp, err = CBList(b, s, "certs")
+	requireSuccessNonNilResponse(t, resp, err, "certs")
+	storedCerts := len(resp.Data["keys"].([]string))
+
+	validate := func(csr string, extra map[string]interface{}) *logical.Response {
+		data := map[string]interface{}{"csr": csr}
+		for key, value := range extra {
+			data[key] = value
+		}
+		resp, err := CBWrite(b, s, "validate-csr/test", data)
+		requireSuccessNonNilResponse(t, resp, err, "validate-csr/test")
+		schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("validate-csr/test"), logical.UpdateOperation), resp, true)
+		return resp
+	}
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:   "www.example.com",
+			Organization: []string{"Evil Corp"},
+		},
+		DNSNames: []string{"www.example.com", "api.example.com"},
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, 1}, Value: []byte{0x05, 0x00}},
+		},
+	}, "ec", 256)
+
+	resp = validate(csrPem, map[string]interface{}{"ttl": "1h"})
+	require.Equal(t, true, resp.Data["valid"])
+	require.Empty(t, resp.Data["rejected"])
+	require.Equal(t, []string{
+		`organization "Evil Corp"`,
+		`DNS SAN "api.example.com"`,
+		`extension 1.3.6.1.4.1.99999.1`,
+	}, resp.Data["stripped"])
+	require.Equal(t, "www.example.com", resp.Data["common_name"])
+	require.Equal(t, []string{"www.example.com"}, resp.Data["alt_names"])
+	require.NotEmpty(t, resp.Data["not_after"])
+
+	// Request parameters are evaluated as on sign/:role.
+	resp = validate(csrPem, map[string]interface{}{"alt_names": "api.example.com"})
+	require.Equal(t, true, resp.Data["valid"])
+	require.NotContains(t, resp.Data["stripped"], `DNS SAN "api.example.com"`)
+	require.ElementsMatch(t, []string{"www.example.com", "api.example.com"}, resp.Data["alt_names"])
+
+	resp = validate(csrPem, map[string]interface{}{"alt_names": "www.example.org"})
+	require.Equal(t, false, resp.Data["valid"])
+	require.Equal(t, []string{"subject alternate name www.example.org not allowed by this role"}, resp.Data["rejected"])
+
+	_, _, csrPem = generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "www.example.org"},
+	}, "ec", 256)
+	resp = validate(csrPem, nil)
+	require.Equal(t, false, resp.Data["valid"])
+	require.Equal(t, []string{"common name www.example.org not allowed by this role"}, resp.Data["rejected"])
+	require.Empty(t, resp.Data["stripped"])
+
+	_, _, csrPem = generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "www.example.com"},
+	}, "rsa", 2048)
+	resp = validate(csrPem, nil)
+	require.Equal(t, false, resp.Data["valid"])
+	require.Equal(t, []string{"role requires keys of type ec"}, resp.Data["rejected"])
+
+	// Nothing was signed.
+	resp, err = CBList(b, s, "certs")
+	requireSuccessNonNilResponse(t, resp, err, "certs")
+	require.Len(t, resp.Data["keys"], storedCerts)
+}
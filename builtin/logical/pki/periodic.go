@@ -303,11 +303,15 @@ func readRevocationEntryAndTransfer(sc *storageContext, serial string) error {
 		sc.Backend.Logger().Debug("no certificate revocation entry for serial", "serial", serial)
 		return nil
 	}
-	cert, err := x509.ParseCertificate(revInfo.CertificateBytes)
-	if err != nil {
-		sc.Backend.Logger().Debug("failed parsing certificate stored in revocation entry for serial",
-			"serial", serial, "error", err)
-		return nil
+	certExpiration := revInfo.CertExpiration
+	if !revInfo.isImported() {
+		cert, err := x509.ParseCertificate(revInfo.CertificateBytes)
+		if err != nil {
+			sc.Backend.Logger().Debug("failed parsing certificate stored in revocation entry for serial",
+				"serial", serial, "error", err)
+			return nil
+		}
+		certExpiration = cert.NotAfter
 	}
 	if revInfo.CertificateIssuer == "" {
 		// No certificate issuer assigned to this serial yet, just drop it for now,
@@ -321,16 +325,17 @@ func readRevocationEntryAndTransfer(sc *storageContext, serial string) error {
 		revocationTime = time.Unix(revInfo.RevocationTime, 0)
 	}
 
-	if time.Now().After(cert.NotAfter) {
+	if time.Now().After(certExpiration) {
 		// ignore transferring this entry as it has already expired.
 		return nil
 	}
 
 	entry := &unifiedRevocationEntry{
 		SerialNumber:      hyphenSerial,
-		CertExpiration:    cert.NotAfter,
+		CertExpiration:    certExpiration,
 		RevocationTimeUTC: revocationTime,
 		CertificateIssuer: revInfo.CertificateIssuer,
+		RevocationReason:  revInfo.RevocationReason,
 	}
 
 	return writeUnifiedRevocationEntry(sc, entry)
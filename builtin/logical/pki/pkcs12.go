@@ -0,0 +1,703 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// PKCS#12 (RFC 7292) bundles are encoded with the legacy SHA-1/3DES
+// algorithms, as those remain the only ones understood by every Windows
+// and Java version in use. When decoding, the PBES2 (RFC 8018) algorithms
+// used by default by current OpenSSL versions are understood as well.
+const pkcs12Iterations = 2048
+
+var (
+	oidPKCS7Data                     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidPKCS12CertBag                 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidPKCS12ShroudedKeyBag          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidPKCS9X509Certificate          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidPKCS9FriendlyName             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 20}
+	oidPKCS9LocalKeyID               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidSHA1                          = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+
+	oidPKCS7EncryptedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidPKCS12KeyBag       = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPBES2              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1       = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512     = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidSHA256             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512             = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidDESEDE3CBC         = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+	oidAES128CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC          = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+type pkcs12PFX struct {
+	Version  int
+	AuthSafe pkcs12ContentInfo
+	MacData  pkcs12MacData
+}
+
+type pkcs12ContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue
+}
+
+type pkcs12MacData struct {
+	Mac        pkcs12DigestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type pkcs12DigestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type pkcs12SafeBag struct {
+	Id         asn1.ObjectIdentifier
+	Value      asn1.RawValue
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	Id     asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type pkcs12CertBag struct {
+	Id   asn1.ObjectIdentifier
+	Data asn1.RawValue
+}
+
+type pkcs12PBEParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type pkcs12EncryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pkcs12EncryptedData struct {
+	Version              int
+	EncryptedContentInfo pkcs12EncryptedContentInfo
+}
+
+type pkcs12EncryptedContentInfo struct {
+	ContentType      asn1.ObjectIdentifier
+	Algorithm        pkix.AlgorithmIdentifier
+	EncryptedContent []byte `asn1:"tag:0,optional"`
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// encodePKCS12 builds a password protected PKCS#12 bundle of the private key,
+// its certificate and the certificate's chain. The key is shrouded with the
+// password while the (public) certificates are stored unencrypted, with the
+// whole bundle's integrity protected by a MAC over the password.
+func encodePKCS12(key crypto.PrivateKey, cert *x509.Certificate, chain []*x509.Certificate, password string) ([]byte, error) {
+	encodedPassword, err := bmpStringZeroTerminated(password)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pair the key with its certificate, so that consumers importing the
+	// bundle know which of the certificates the key belongs to.
+	localKeyId := sha1.Sum(cert.Raw)
+	leafAttributes, err := pkcs12BagAttributes(localKeyId[:], cert.Subject.CommonName)
+	if err != nil {
+		return nil, err
+	}
+
+	var certBags []pkcs12SafeBag
+	for index, c := range append([]*x509.Certificate{cert}, chain...) {
+		bag, err := pkcs12NewCertBag(c)
+		if err != nil {
+			return nil, err
+		}
+		if index == 0 {
+			bag.Attributes = leafAttributes
+		}
+		certBags = append(certBags, *bag)
+	}
+
+	keyBag, err := pkcs12NewShroudedKeyBag(key, encodedPassword)
+	if err != nil {
+		return nil, err
+	}
+	keyBag.Attributes = leafAttributes
+
+	var authenticatedSafe []pkcs12ContentInfo
+	for _, bags := range [][]pkcs12SafeBag{certBags, {*keyBag}} {
+		safeContents, err := asn1.Marshal(bags)
+		if err != nil {
+			return nil, err
+		}
+
+		contentInfo, err := pkcs12DataContentInfo(safeContents)
+		if err != nil {
+			return nil, err
+		}
+		authenticatedSafe = append(authenticatedSafe, *contentInfo)
+	}
+
+	authenticatedSafeBytes, err := asn1.Marshal(authenticatedSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	authSafe, err := pkcs12DataContentInfo(authenticatedSafeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	macSalt, err := pkcs12Salt()
+	if err != nil {
+		return nil, err
+	}
+
+	macKey := pkcs12KDF(sha1.New, macSalt, encodedPassword, pkcs12Iterations, 3, sha1.Size)
+	mac := hmac.New(sha1.New, macKey)
+	mac.Write(authenticatedSafeBytes)
+
+	return asn1.Marshal(pkcs12PFX{
+		Version:  3,
+		AuthSafe: *authSafe,
+		MacData: pkcs12MacData{
+			Mac: pkcs12DigestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{
+					Algorithm:  oidSHA1,
+					Parameters: asn1.NullRawValue,
+				},
+				Digest: mac.Sum(nil),
+			},
+			MacSalt:    macSalt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+func pkcs12NewCertBag(cert *x509.Certificate) (*pkcs12SafeBag, error) {
+	certValue, err := asn1.Marshal(cert.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	certBag, err := asn1.Marshal(pkcs12CertBag{
+		Id:   oidPKCS9X509Certificate,
+		Data: pkcs12ExplicitContent(certValue),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pkcs12SafeBag{
+		Id:    oidPKCS12CertBag,
+		Value: pkcs12ExplicitContent(certBag),
+	}, nil
+}
+
+func pkcs12NewShroudedKeyBag(key crypto.PrivateKey, encodedPassword []byte) (*pkcs12SafeBag, error) {
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling private key: %w", err)
+	}
+
+	salt, err := pkcs12Salt()
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := asn1.Marshal(pkcs12PBEParams{Salt: salt, Iterations: pkcs12Iterations})
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKey := pkcs12KDF(sha1.New, salt, encodedPassword, pkcs12Iterations, 1, 24)
+	iv := pkcs12KDF(sha1.New, salt, encodedPassword, pkcs12Iterations, 2, des.BlockSize)
+	block, err := des.NewTripleDESCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	padding := des.BlockSize - len(pkcs8Key)%des.BlockSize
+	encrypted := append(pkcs8Key, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+
+	keyInfo, err := asn1.Marshal(pkcs12EncryptedPrivateKeyInfo{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBEWithSHAAnd3KeyTripleDESCBC,
+			Parameters: asn1.RawValue{FullBytes: params},
+		},
+		EncryptedData: encrypted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pkcs12SafeBag{
+		Id:    oidPKCS12ShroudedKeyBag,
+		Value: pkcs12ExplicitContent(keyInfo),
+	}, nil
+}
+
+func pkcs12BagAttributes(localKeyId []byte, friendlyName string) ([]pkcs12Attribute, error) {
+	keyIdValue, err := asn1.Marshal(localKeyId)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := []pkcs12Attribute{{Id: oidPKCS9LocalKeyID, Values: pkcs12AttributeValues(keyIdValue)}}
+	// The friendly name is only informational, so it is left out when it
+	// can't be encoded.
+	if name, err := bmpString(friendlyName); err == nil && len(name) > 0 {
+		nameValue, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagBMPString, Bytes: name})
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, pkcs12Attribute{Id: oidPKCS9FriendlyName, Values: pkcs12AttributeValues(nameValue)})
+	}
+
+	return attributes, nil
+}
+
+func pkcs12DataContentInfo(content []byte) (*pkcs12ContentInfo, error) {
+	octets, err := asn1.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pkcs12ContentInfo{
+		ContentType: oidPKCS7Data,
+		Content:     pkcs12ExplicitContent(octets),
+	}, nil
+}
+
+// pkcs12ExplicitContent wraps already encoded content in an explicit [0]
+// tag, as used throughout PKCS#7 and PKCS#12.
+func pkcs12ExplicitContent(encoded []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: encoded}
+}
+
+func pkcs12AttributeValues(encoded []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: encoded}
+}
+
+func pkcs12Salt() ([]byte, error) {
+	salt := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+	return salt, nil
+}
+
+// bmpString encodes s as UTF-16 big endian, as PKCS#12 expects for both
+// passwords and friendly names.
+func bmpString(s string) ([]byte, error) {
+	encoded := make([]byte, 0, 2*len(s))
+	for _, r := range s {
+		if r > 0xFFFF {
+			return nil, fmt.Errorf("character %q cannot be encoded in a BMPString", r)
+		}
+		encoded = append(encoded, byte(r>>8), byte(r))
+	}
+	return encoded, nil
+}
+
+func bmpStringZeroTerminated(s string) ([]byte, error) {
+	encoded, err := bmpString(s)
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, 0, 0), nil
+}
+
+// pkcs12KDF derives size bytes of key material from the password, per
+// RFC 7292 Appendix B.2. The id selects encryption keys (1), IVs (2) or MAC
+// keys (3).
+func pkcs12KDF(hashFunc func() hash.Hash, salt, password []byte, iterations int, id byte, size int) []byte {
+	h := hashFunc()
+	u := h.Size()
+	v := h.BlockSize()
+
+	D := bytes.Repeat([]byte{id}, v)
+	I := append(pkcs12FillBlocks(salt, v), pkcs12FillBlocks(password, v)...)
+
+	c := (size + u - 1) / u
+	A := make([]byte, 0, c*u)
+	for i := 0; i < c; i++ {
+		h.Reset()
+		h.Write(D)
+		h.Write(I)
+		Ai := h.Sum(nil)
+		for j := 1; j < iterations; j++ {
+			h.Reset()
+			h.Write(Ai)
+			Ai = h.Sum(Ai[:0])
+		}
+		A = append(A, Ai...)
+
+		if i < c-1 {
+			B := pkcs12FillBlocks(Ai, v)
+			for j := 0; j < len(I); j += v {
+				// I_j = (I_j + B + 1) mod 2^v
+				carry := 1
+				for k := v - 1; k >= 0; k-- {
+					sum := int(I[j+k]) + int(B[k]) + carry
+					I[j+k] = byte(sum)
+					carry = sum >> 8
+				}
+			}
+		}
+	}
+
+	return A[:size]
+}
+
+// pkcs12FillBlocks repeats data up to the next multiple of the block size.
+func pkcs12FillBlocks(data []byte, blockSize int) []byte {
+	if len(data) == 0 {
+		return nil
+	}
+
+	filled := make([]byte, blockSize*((len(data)+blockSize-1)/blockSize))
+	for i := range filled {
+		filled[i] = data[i%len(data)]
+	}
+	return filled
+}
+
+// decodePKCS12 decrypts a PKCS#12 bundle into a PEM bundle of the (PKCS#8)
+// private keys and certificates within it, after verifying the bundle's MAC
+// with the password.
+func decodePKCS12(data []byte, password string) (string, error) {
+	var pfx pkcs12PFX
+	if rest, err := asn1.Unmarshal(data, &pfx); err != nil {
+		return "", fmt.Errorf("error parsing PKCS#12 bundle: %w", err)
+	} else if len(rest) > 0 {
+		return "", errors.New("error parsing PKCS#12 bundle: trailing data")
+	}
+
+	if pfx.Version != 3 {
+		return "", fmt.Errorf("unsupported PKCS#12 version: %v", pfx.Version)
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidPKCS7Data) {
+		return "", errors.New("unsupported PKCS#12 bundle: only password integrity mode is supported")
+	}
+
+	var authenticatedSafeBytes []byte
+	if _, err := asn1.Unmarshal(pfx.AuthSafe.Content.Bytes, &authenticatedSafeBytes); err != nil {
+		return "", fmt.Errorf("error parsing PKCS#12 bundle: %w", err)
+	}
+
+	encodedPassword, err := bmpStringZeroTerminated(password)
+	if err != nil {
+		return "", err
+	}
+
+	if err := pkcs12VerifyMac(&pfx.MacData, authenticatedSafeBytes, encodedPassword); err != nil {
+		return "", err
+	}
+
+	var authenticatedSafe []pkcs12ContentInfo
+	if _, err := asn1.Unmarshal(authenticatedSafeBytes, &authenticatedSafe); err != nil {
+		return "", fmt.Errorf("error parsing PKCS#12 bundle: %w", err)
+	}
+
+	var pemBundle []byte
+	for _, contentInfo := range authenticatedSafe {
+		var safeContentsBytes []byte
+		switch {
+		case contentInfo.ContentType.Equal(oidPKCS7Data):
+			if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &safeContentsBytes); err != nil {
+				return "", fmt.Errorf("error parsing PKCS#12 bundle: %w", err)
+			}
+		case contentInfo.ContentType.Equal(oidPKCS7EncryptedData):
+			var encryptedData pkcs12EncryptedData
+			if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &encryptedData); err != nil {
+				return "", fmt.Errorf("error parsing PKCS#12 bundle: %w", err)
+			}
+
+			encryptedContent := encryptedData.EncryptedContentInfo
+			safeContentsBytes, err = pbeDecrypt(encryptedContent.Algorithm, encryptedContent.EncryptedContent, password)
+			if err != nil {
+				return "", err
+			}
+		default:
+			return "", fmt.Errorf("unsupported PKCS#12 content type: %v", contentInfo.ContentType)
+		}
+
+		var safeContents []pkcs12SafeBag
+		if _, err := asn1.Unmarshal(safeContentsBytes, &safeContents); err != nil {
+			return "", fmt.Errorf("error parsing PKCS#12 bundle: %w", err)
+		}
+
+		for _, bag := range safeContents {
+			block, err := pkcs12DecodeBag(&bag, password)
+			if err != nil {
+				return "", err
+			}
+			if block != nil {
+				pemBundle = append(pemBundle, pem.EncodeToMemory(block)...)
+			}
+		}
+	}
+
+	return string(pemBundle), nil
+}
+
+func pkcs12VerifyMac(macData *pkcs12MacData, content []byte, encodedPassword []byte) error {
+	hashFunc, err := hashForOid(macData.Mac.Algorithm.Algorithm, false)
+	if err != nil {
+		return err
+	}
+
+	macKey := pkcs12KDF(hashFunc, macData.MacSalt, encodedPassword, macData.Iterations, 3, hashFunc().Size())
+	mac := hmac.New(hashFunc, macKey)
+	mac.Write(content)
+	if !hmac.Equal(mac.Sum(nil), macData.Mac.Digest) {
+		return errors.New("PKCS#12 MAC verification failed: incorrect password or corrupted bundle")
+	}
+
+	return nil
+}
+
+// pkcs12DecodeBag returns the certificate or (decrypted) key in the bag as a
+// PEM block, or nil for bags of other types, such as CRLs.
+func pkcs12DecodeBag(bag *pkcs12SafeBag, password string) (*pem.Block, error) {
+	switch {
+	case bag.Id.Equal(oidPKCS12CertBag):
+		var certBag pkcs12CertBag
+		if _, err := asn1.Unmarshal(bag.Value.Bytes, &certBag); err != nil {
+			return nil, fmt.Errorf("error parsing PKCS#12 certificate: %w", err)
+		}
+		if !certBag.Id.Equal(oidPKCS9X509Certificate) {
+			return nil, nil
+		}
+
+		var certDer []byte
+		if _, err := asn1.Unmarshal(certBag.Data.Bytes, &certDer); err != nil {
+			return nil, fmt.Errorf("error parsing PKCS#12 certificate: %w", err)
+		}
+		return &pem.Block{Type: "CERTIFICATE", Bytes: certDer}, nil
+
+	case bag.Id.Equal(oidPKCS12KeyBag):
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: bag.Value.Bytes}, nil
+
+	case bag.Id.Equal(oidPKCS12ShroudedKeyBag):
+		keyDer, err := decryptPKCS8PrivateKey(bag.Value.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}, nil
+	}
+
+	return nil, nil
+}
+
+// decryptPKCS8PrivateKey decrypts a DER encoded PKCS#8 EncryptedPrivateKeyInfo,
+// such as within an "ENCRYPTED PRIVATE KEY" PEM block, into the DER encoded
+// PKCS#8 private key.
+func decryptPKCS8PrivateKey(der []byte, password string) ([]byte, error) {
+	var keyInfo pkcs12EncryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &keyInfo); err != nil {
+		return nil, fmt.Errorf("error parsing encrypted private key: %w", err)
+	}
+
+	return pbeDecrypt(keyInfo.Algorithm, keyInfo.EncryptedData, password)
+}
+
+// decryptPEMPrivateKey decrypts an encrypted private key PEM block: either
+// PKCS#8 ("ENCRYPTED PRIVATE KEY") or OpenSSL's traditional format, with
+// its encryption given in the PEM headers. Unencrypted blocks are returned
+// as-is.
+func decryptPEMPrivateKey(block *pem.Block, password string) (*pem.Block, error) {
+	encrypted := block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block)
+	if encrypted && password == "" {
+		return nil, errors.New("encrypted private key provided without a passphrase")
+	}
+
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		keyDer, err := decryptPKCS8PrivateKey(block.Bytes, password)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: keyDer}, nil
+	}
+
+	// While deprecated for its lack of authentication, this remains the only
+	// way of reading keys encrypted by OpenSSL's traditional format.
+	if x509.IsEncryptedPEMBlock(block) {
+		keyDer, err := x509.DecryptPEMBlock(block, []byte(password))
+		if err != nil {
+			return nil, fmt.Errorf("error decrypting private key: %w", err)
+		}
+		return &pem.Block{Type: block.Type, Bytes: keyDer}, nil
+	}
+
+	return block, nil
+}
+
+// pbeDecrypt decrypts data encrypted with either the PKCS#12 SHA-1/3DES
+// scheme or PBES2, using PBKDF2 with AES or 3DES.
+func pbeDecrypt(algorithm pkix.AlgorithmIdentifier, encrypted []byte, password string) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+
+	switch {
+	case algorithm.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+		var params pkcs12PBEParams
+		if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+			return nil, fmt.Errorf("error parsing encryption parameters: %w", err)
+		}
+
+		encodedPassword, err := bmpStringZeroTerminated(password)
+		if err != nil {
+			return nil, err
+		}
+
+		key := pkcs12KDF(sha1.New, params.Salt, encodedPassword, params.Iterations, 1, 24)
+		iv = pkcs12KDF(sha1.New, params.Salt, encodedPassword, params.Iterations, 2, des.BlockSize)
+		block, err = des.NewTripleDESCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+	case algorithm.Algorithm.Equal(oidPBES2):
+		var params pbes2Params
+		if _, err := asn1.Unmarshal(algorithm.Parameters.FullBytes, &params); err != nil {
+			return nil, fmt.Errorf("error parsing encryption parameters: %w", err)
+		}
+		if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+			return nil, fmt.Errorf("unsupported key derivation function: %v", params.KeyDerivationFunc.Algorithm)
+		}
+
+		var kdfParams pbkdf2Params
+		if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+			return nil, fmt.Errorf("error parsing key derivation parameters: %w", err)
+		}
+
+		// The PRF defaults to HMAC-SHA1 when left out.
+		hashFunc := sha1.New
+		if len(kdfParams.PRF.Algorithm) > 0 {
+			var err error
+			hashFunc, err = hashForOid(kdfParams.PRF.Algorithm, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var keySize int
+		newCipher := aes.NewCipher
+		scheme := params.EncryptionScheme.Algorithm
+		switch {
+		case scheme.Equal(oidAES128CBC):
+			keySize = 16
+		case scheme.Equal(oidAES192CBC):
+			keySize = 24
+		case scheme.Equal(oidAES256CBC):
+			keySize = 32
+		case scheme.Equal(oidDESEDE3CBC):
+			keySize = 24
+			newCipher = des.NewTripleDESCipher
+		default:
+			return nil, fmt.Errorf("unsupported encryption scheme: %v", scheme)
+		}
+
+		if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+			return nil, fmt.Errorf("error parsing encryption scheme parameters: %w", err)
+		}
+
+		var err error
+		key := pbkdf2.Key([]byte(password), kdfParams.Salt, kdfParams.IterationCount, keySize, hashFunc)
+		block, err = newCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm: %v; re-export using AES or 3DES", algorithm.Algorithm)
+	}
+
+	if len(iv) != block.BlockSize() {
+		return nil, errors.New("invalid encryption parameters: unexpected IV length")
+	}
+	if len(encrypted) == 0 || len(encrypted)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid encrypted data: not a multiple of the block size")
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+
+	padding := int(decrypted[len(decrypted)-1])
+	if padding == 0 || padding > block.BlockSize() || !bytes.Equal(decrypted[len(decrypted)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("error decrypting data: incorrect password")
+	}
+
+	return decrypted[:len(decrypted)-padding], nil
+}
+
+// hashForOid returns the hash function for either the digest algorithm (as
+// used in PKCS#12 MACs) or the HMAC algorithm (as used as the PBKDF2 PRF)
+// with the given identifier.
+func hashForOid(oid asn1.ObjectIdentifier, hmacOid bool) (func() hash.Hash, error) {
+	oids := map[string]func() hash.Hash{
+		oidSHA1.String():   sha1.New,
+		oidSHA256.String(): sha256.New,
+		oidSHA384.String(): sha512.New384,
+		oidSHA512.String(): sha512.New,
+	}
+	if hmacOid {
+		oids = map[string]func() hash.Hash{
+			oidHMACWithSHA1.String():   sha1.New,
+			oidHMACWithSHA256.String(): sha256.New,
+			oidHMACWithSHA384.String(): sha512.New384,
+			oidHMACWithSHA512.String(): sha512.New,
+		}
+	}
+
+	hashFunc, ok := oids[oid.String()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported hash algorithm: %v", oid)
+	}
+	return hashFunc, nil
+}
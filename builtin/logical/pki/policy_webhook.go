@@ -0,0 +1,250 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+// External issuance policy: when configured, the webhook is sent each leaf
+// certificate about to be issued, after role validation, and decides
+// whether it may be issued, optionally modifying its template.
+const policyWebhookMaxResponseSize = 1024 * 1024
+
+type policyWebhookRequester struct {
+	EntityID            string `json:"entity_id"`
+	DisplayName         string `json:"display_name"`
+	ClientTokenAccessor string `json:"client_token_accessor"`
+	RemoteAddress       string `json:"remote_address"`
+}
+
+// policyWebhookTemplate holds the values of the certificate to be issued
+// which the webhook may modify.
+type policyWebhookTemplate struct {
+	CommonName         string    `json:"common_name"`
+	DNSNames           []string  `json:"dns_names"`
+	EmailAddresses     []string  `json:"email_addresses"`
+	IPAddresses        []string  `json:"ip_addresses"`
+	URIs               []string  `json:"uris"`
+	Organization       []string  `json:"organization"`
+	OrganizationalUnit []string  `json:"organizational_unit"`
+	NotAfter           time.Time `json:"not_after"`
+}
+
+type policyWebhookRequest struct {
+	Role      string                 `json:"role"`
+	Requester policyWebhookRequester `json:"requester"`
+	CSR       string                 `json:"csr"`
+	Template  policyWebhookTemplate  `json:"template"`
+}
+
+type policyWebhookResponse struct {
+	Allow    bool                   `json:"allow"`
+	Reason   string                 `json:"reason"`
+	Warnings []string               `json:"warnings"`
+	Template *policyWebhookTemplate `json:"template"`
+}
+
+// applyPolicyWebhook consults the policy webhook, if enabled, about the
+// certificate described by creation, applying the modifications it returns
+// to the creation parameters.
+func applyPolicyWebhook(ctx context.Context, config *policyWebhookConfigEntry, input *inputBundle, creation *certutil.CreationBundle) ([]string, error) {
+	if config == nil || !config.Enabled {
+		return nil, nil
+	}
+
+	template := policyWebhookTemplateFromParams(creation)
+	webhookReq := policyWebhookRequest{
+		Role:     input.role.Name,
+		Template: template,
+	}
+	if creation.CSR != nil {
+		webhookReq.CSR = string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: creation.CSR.Raw}))
+	}
+	if req := input.req; req != nil {
+		webhookReq.Requester = policyWebhookRequester{
+			EntityID:            req.EntityID,
+			DisplayName:         req.DisplayName,
+			ClientTokenAccessor: req.ClientTokenAccessor,
+		}
+		if req.Connection != nil {
+			webhookReq.Requester.RemoteAddress = req.Connection.RemoteAddr
+		}
+	}
+
+	// Fields absent from the webhook's template keep their current values.
+	modified := template
+	webhookResp := policyWebhookResponse{Template: &modified}
+	if err := callPolicyWebhook(ctx, config, webhookReq, &webhookResp); err != nil {
+		err = fmt.Errorf("error consulting policy webhook: %w", err)
+		if config.FailOpen {
+			return []string{fmt.Sprintf("%v; issuing regardless", err)}, nil
+		}
+		return nil, err
+	}
+
+	if !webhookResp.Allow {
+		reason := webhookResp.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		return nil, errutil.UserError{Err: fmt.Sprintf("certificate request denied by policy webhook: %v", reason)}
+	}
+
+	if webhookResp.Template != nil && !reflect.DeepEqual(template, *webhookResp.Template) {
+		if creation.Params.UseCSRValues {
+			return nil, fmt.Errorf("policy webhook modified the template of a certificate whose values are taken from the CSR verbatim")
+		}
+		if err := applyPolicyWebhookTemplate(creation.Params, template, *webhookResp.Template); err != nil {
+			return nil, fmt.Errorf("invalid template returned by policy webhook: %w", err)
+		}
+	}
+
+	return webhookResp.Warnings, nil
+}
+
+func policyWebhookTemplateFromParams(creation *certutil.CreationBundle) policyWebhookTemplate {
+	params := creation.Params
+	subject := params.Subject
+	dnsNames, emailAddresses, ipAddresses, uris := params.DNSNames, params.EmailAddresses, params.IPAddresses, params.URIs
+	if params.UseCSRValues && creation.CSR != nil {
+		csr := creation.CSR
+		subject = csr.Subject
+		dnsNames, emailAddresses, ipAddresses, uris = csr.DNSNames, csr.EmailAddresses, csr.IPAddresses, csr.URIs
+	}
+
+	template := policyWebhookTemplate{
+		CommonName:         subject.CommonName,
+		DNSNames:           append([]string{}, dnsNames...),
+		EmailAddresses:     append([]string{}, emailAddresses...),
+		IPAddresses:        []string{},
+		URIs:               []string{},
+		Organization:       append([]string{}, subject.Organization...),
+		OrganizationalUnit: append([]string{}, subject.OrganizationalUnit...),
+		NotAfter:           params.NotAfter.UTC(),
+	}
+	for _, ip := range ipAddresses {
+		template.IPAddresses = append(template.IPAddresses, ip.String())
+	}
+	for _, uri := range uris {
+		template.URIs = append(template.URIs, uri.String())
+	}
+
+	return template
+}
+
+// applyPolicyWebhookTemplate applies the webhook's template to params. The
+// webhook may shorten, but not extend, the validity of the certificate.
+func applyPolicyWebhookTemplate(params *certutil.CreationParameters, original, modified policyWebhookTemplate) error {
+	if modified.NotAfter.After(original.NotAfter) {
+		return fmt.Errorf("not_after %v is later than %v", modified.NotAfter.Format(time.RFC3339), original.NotAfter.Format(time.RFC3339))
+	}
+	if !modified.NotAfter.After(time.Now()) {
+		return fmt.Errorf("not_after %v is in the past", modified.NotAfter.Format(time.RFC3339))
+	}
+
+	var ipAddresses []net.IP
+	for _, ipString := range modified.IPAddresses {
+		ip := net.ParseIP(ipString)
+		if ip == nil {
+			return fmt.Errorf("invalid IP address %q", ipString)
+		}
+		ipAddresses = append(ipAddresses, ip)
+	}
+
+	var uris []*url.URL
+	for _, uriString := range modified.URIs {
+		uri, err := url.Parse(uriString)
+		if err != nil {
+			return fmt.Errorf("invalid URI %q: %w", uriString, err)
+		}
+		uris = append(uris, uri)
+	}
+
+	params.Subject.CommonName = modified.CommonName
+	params.DNSNames = modified.DNSNames
+	params.EmailAddresses = modified.EmailAddresses
+	params.IPAddresses = ipAddresses
+	params.URIs = uris
+	params.Subject.Organization = modified.Organization
+	params.Subject.OrganizationalUnit = modified.OrganizationalUnit
+	params.NotAfter = modified.NotAfter
+
+	return nil
+}
+
+func callPolicyWebhook(ctx context.Context, config *policyWebhookConfigEntry, webhookReq policyWebhookRequest, webhookResp *policyWebhookResponse) error {
+	body, err := json.Marshal(webhookReq)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	transport := cleanhttp.DefaultTransport()
+	if config.CACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(config.CACertificate)) {
+			return fmt.Errorf("ca_certificate contains no PEM encoded certificates")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, policyWebhookMaxResponseSize))
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response status %v: %v", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+
+	if err := json.Unmarshal(respBody, webhookResp); err != nil {
+		return fmt.Errorf("error decoding response: %w", err)
+	}
+
+	return nil
+}
+
+// loadPolicyWebhook reads the policy webhook configuration into input, for
+// signCert and generateCert to consult.
+func loadPolicyWebhook(sc *storageContext, input *inputBundle) error {
+	config, err := sc.getPolicyWebhookConfig()
+	if err != nil {
+		return err
+	}
+
+	input.policyWebhook = config
+	return nil
+}
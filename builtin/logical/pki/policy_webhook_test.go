@@ -0,0 +1,237 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_PolicyWebhook(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	var lock sync.Mutex
+	var lastRequest policyWebhookRequest
+	var decide func(req policyWebhookRequest) (int, interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		require.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&lastRequest))
+
+		status, body := decide(lastRequest)
+		w.WriteHeader(status)
+		require.NoError(t, json.NewEncoder(w).Encode(body))
+	}))
+	defer server.Close()
+	setDecision := func(status int, body interface{}) {
+		lock.Lock()
+		defer lock.Unlock()
+		decide = func(policyWebhookRequest) (int, interface{}) { return status, body }
+	}
+	getLastRequest := func() policyWebhookRequest {
+		lock.Lock()
+		defer lock.Unlock()
+		return lastRequest
+	}
+
+	resp, err := CBRead(b, s, "config/policy-webhook")
+	requireSuccessNonNilResponse(t, resp, err, "config/policy-webhook")
+	require.Equal(t, false, resp.Data["enabled"])
+	require.Equal(t, int64(10), resp.Data["timeout"])
+
+	_, err = CBWrite(b, s, "config/policy-webhook", map[string]interface{}{
+		"enabled": true,
+	})
+	require.ErrorContains(t, err, "url is required")
+	_, err = CBWrite(b, s, "config/policy-webhook", map[string]interface{}{
+		"enabled": true,
+		"url":     "ftp://policy.example.com",
+	})
+	require.ErrorContains(t, err, "must be an absolute http or https url")
+	_, err = CBWrite(b, s, "config/policy-webhook", map[string]interface{}{
+		"ca_certificate": "not a certificate",
+	})
+	require.ErrorContains(t, err, "contains no PEM encoded certificates")
+
+	resp, err = CBWrite(b, s, "config/policy-webhook", map[string]interface{}{
+		"enabled": true,
+		"url":     server.URL,
+		"timeout": "5s",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/policy-webhook")
+	require.Equal(t, int64(5), resp.Data["timeout"])
+
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+		"max_ttl":          "2h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	issue := func() (*x509.Certificate, []string, error) {
+		resp, err := CBWrite(b, s, "issue/test", map[string]interface{}{
+			"common_name": "www.example.com",
+			"ttl":         "1h",
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return parseCert(t, resp.Data["certificate"].(string)), resp.Warnings, nil
+	}
+
+	// Unmodified templates are issued as requested.
+	setDecision(http.StatusOK, map[string]interface{}{"allow": true})
+	cert, _, err := issue()
+	require.NoError(t, err)
+	require.Equal(t, []string{"www.example.com"}, cert.DNSNames)
+	webhookReq := getLastRequest()
+	require.Equal(t, "test", webhookReq.Role)
+	require.Empty(t, webhookReq.CSR)
+	require.Equal(t, "www.example.com", webhookReq.Template.CommonName)
+	require.Equal(t, []string{"www.example.com"}, webhookReq.Template.DNSNames)
+	require.WithinDuration(t, time.Now().Add(time.Hour), webhookReq.Template.NotAfter, time.Minute)
+
+	setDecision(http.StatusOK, map[string]interface{}{"allow": false, "reason": "blocked by policy"})
+	_, _, err = issue()
+	require.ErrorContains(t, err, "certificate request denied by policy webhook: blocked by policy")
+
+	// Fields returned by the webhook replace those of the template.
+	notAfter := time.Now().Add(30 * time.Minute).UTC().Truncate(time.Second)
+	setDecision(http.StatusOK, map[string]interface{}{
+		"allow":    true,
+		"warnings": []string{"organization set by policy"},
+		"template": map[string]interface{}{
+			"dns_names":    []string{"www.example.com", "extra.example.com"},
+			"organization": []string{"Example Inc"},
+			"not_after":    notAfter,
+		},
+	})
+	cert, warnings, err := issue()
+	require.NoError(t, err)
+	require.Contains(t, warnings, "organization set by policy")
+	require.Equal(t, "www.example.com", cert.Subject.CommonName)
+	require.Equal(t, []string{"www.example.com", "extra.example.com"}, cert.DNSNames)
+	require.Equal(t, []string{"Example Inc"}, cert.Subject.Organization)
+	require.Equal(t, notAfter, cert.NotAfter.UTC())
+
+	setDecision(http.StatusOK, map[string]interface{}{
+		"allow":    true,
+		"template": map[string]interface{}{"not_after": time.Now().Add(24 * time.Hour)},
+	})
+	_, _, err = issue()
+	require.ErrorContains(t, err, "invalid template returned by policy webhook")
+
+	// The CSR is sent along on /sign.
+	setDecision(http.StatusOK, map[string]interface{}{"allow": true})
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "api.example.com"},
+	}, "ec", 256)
+	resp, err = CBWrite(b, s, "sign/test", map[string]interface{}{
+		"csr": csrPem,
+		"ttl": "1h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign/test")
+	block, _ := pem.Decode([]byte(getLastRequest().CSR))
+	require.NotNil(t, block)
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	require.NoError(t, err)
+	require.Equal(t, "api.example.com", csr.Subject.CommonName)
+
+	// Failures to consult the webhook refuse issuance, unless failing open.
+	setDecision(http.StatusInternalServerError, map[string]interface{}{"error": "policy engine unavailable"})
+	_, _, err = issue()
+	require.ErrorContains(t, err, "policy engine unavailable")
+
+	_, err = CBWrite(b, s, "config/policy-webhook", map[string]interface{}{
+		"fail_open": true,
+	})
+	require.NoError(t, err)
+	_, warnings, err = issue()
+	require.NoError(t, err)
+	require.NotEmpty(t, warnings)
+	require.Contains(t, warnings[0], "error consulting policy webhook")
+
+	// Disabling the webhook stops it from being consulted.
+	_, err = CBWrite(b, s, "config/policy-webhook", map[string]interface{}{
+		"enabled": false,
+	})
+	require.NoError(t, err)
+	setDecision(http.StatusOK, map[string]interface{}{"allow": false})
+	_, _, err = issue()
+	require.NoError(t, err)
+}
+
+// TestPki_PolicyWebhookRequestContext ensures that cancelling a sign request
+// stops the call to the webhook, rather than waiting for its timeout.
+func TestPki_PolicyWebhookRequestContext(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	resp, err := CBWrite(b, s, "config/policy-webhook", map[string]interface{}{
+		"enabled": true,
+		"url":     server.URL,
+		"timeout": "60s",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "config/policy-webhook")
+
+	resp, err = CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allowed_domains":  "example.com",
+		"allow_subdomains": true,
+		"key_type":         "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "api.example.com"},
+	}, "ec", 256)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	resp, err = b.HandleRequest(ctx, &logical.Request{
+		Operation:  logical.UpdateOperation,
+		Path:       "sign/test",
+		Data:       map[string]interface{}{"csr": csrPem},
+		Storage:    s,
+		MountPoint: "pki/",
+	})
+	require.True(t, err != nil || resp.IsError(), "expected the sign request to fail")
+	require.Less(t, time.Since(start), 10*time.Second)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+	"golang.org/x/crypto/ocsp"
+)
+
+// oidExtensionReasonCode is the CRL entry extension carrying the CRLReason
+// of a revoked certificate, per RFC 5280 Section 5.3.1.
+var oidExtensionReasonCode = asn1.ObjectIdentifier{2, 5, 29, 21}
+
+// revocationReasons maps the RFC 5280 CRLReason names to their codes.
+// certificateHold and removeFromCRL are left out: Vault has no notion of
+// suspending a certificate and later lifting its revocation.
+var revocationReasons = map[string]int{
+	"unspecified":          ocsp.Unspecified,
+	"keyCompromise":        ocsp.KeyCompromise,
+	"cACompromise":         ocsp.CACompromise,
+	"affiliationChanged":   ocsp.AffiliationChanged,
+	"superseded":           ocsp.Superseded,
+	"cessationOfOperation": ocsp.CessationOfOperation,
+	"privilegeWithdrawn":   ocsp.PrivilegeWithdrawn,
+	"aACompromise":         ocsp.AACompromise,
+}
+
+// parseRevocationReason parses the revocation_reason of a revocation
+// request, either by its (case insensitive) RFC 5280 name or its code.
+func parseRevocationReason(reason string) (int, error) {
+	if reason == "" {
+		return ocsp.Unspecified, nil
+	}
+
+	if code, err := strconv.Atoi(reason); err == nil {
+		if revocationReasonName(code) != "" {
+			return code, nil
+		}
+	} else {
+		for name, code := range revocationReasons {
+			if strings.EqualFold(name, reason) {
+				return code, nil
+			}
+		}
+	}
+
+	return 0, errutil.UserError{Err: fmt.Sprintf("unsupported revocation_reason %q; see RFC 5280 Section 5.3.1 for reason names and codes, excluding certificateHold and removeFromCRL", reason)}
+}
+
+// revocationReasonName returns the RFC 5280 name of a supported reason code,
+// or the empty string for unsupported ones.
+func revocationReasonName(code int) string {
+	for name, candidate := range revocationReasons {
+		if candidate == code {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// revocationReasonExtensions returns the CRL entry extensions conveying the
+// given reason. The reasonCode extension is omitted for unspecified
+// revocations, as RFC 5280 recommends.
+func revocationReasonExtensions(code int) ([]pkix.Extension, error) {
+	if code == ocsp.Unspecified {
+		return nil, nil
+	}
+
+	value, err := asn1.Marshal(asn1.Enumerated(code))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode revocation reason %d: %w", code, err)
+	}
+
+	return []pkix.Extension{{Id: oidExtensionReasonCode, Value: value}}, nil
+}
+
+// revocationReasonFromExtensions returns the reason conveyed by the CRL
+// entry extensions of a revoked certificate, or unspecified without one.
+func revocationReasonFromExtensions(extensions []pkix.Extension) (int, error) {
+	for _, ext := range extensions {
+		if !ext.Id.Equal(oidExtensionReasonCode) {
+			continue
+		}
+
+		var code asn1.Enumerated
+		rest, err := asn1.Unmarshal(ext.Value, &code)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode revocation reason: %w", err)
+		}
+		if len(rest) != 0 {
+			return 0, fmt.Errorf("failed to decode revocation reason: trailing data")
+		}
+
+		return int(code), nil
+	}
+
+	return ocsp.Unspecified, nil
+}
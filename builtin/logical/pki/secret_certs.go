@@ -10,6 +10,7 @@ import (
 
 	"github.com/hashicorp/vault/sdk/framework"
 	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/ocsp"
 )
 
 // SecretCertsType is the name used to identify this type
@@ -82,5 +83,5 @@ func (b *backend) secretCredsRevoke(ctx context.Context, req *logical.Request, _
 		return nil, fmt.Errorf("error revoking serial: %s: failed reading config: %w", serial, err)
 	}
 
-	return revokeCert(sc, config, cert)
+	return revokeCert(sc, config, cert, req.EntityID, ocsp.Unspecified)
 }
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/errutil"
+)
+
+const (
+	// Serial numbers are at most 20 octets and positive (RFC 5280 Section
+	// 4.1.2.2), leaving 159 bits; the CA/Browser Forum Baseline Requirements
+	// ask for at least 64 of them to be random.
+	maxSerialNumberBits = 159
+	minSerialNumberBits = 64
+)
+
+// parseSerialNumberPrefix parses the hex encoded serial_number_prefix of an
+// issuer, optionally separated by colons or hyphens like serial numbers.
+func parseSerialNumberPrefix(prefix string) ([]byte, error) {
+	if prefix == "" {
+		return nil, nil
+	}
+
+	raw, err := hex.DecodeString(strings.NewReplacer(":", "", "-", "").Replace(prefix))
+	if err != nil || len(raw) == 0 || raw[0] == 0 {
+		return nil, fmt.Errorf("serial_number_prefix %q must be hex encoded, without leading zero bytes", prefix)
+	}
+
+	return raw, nil
+}
+
+// validateSerialNumberFormat ensures serial numbers of the given number of
+// random bits, below the given prefix, fit into 20 octets.
+func validateSerialNumberFormat(bits int, prefix []byte) error {
+	if bits != 0 && (bits < minSerialNumberBits || bits > maxSerialNumberBits) {
+		return fmt.Errorf("serial_number_bits must be between %d and %d, or 0 for the default of %d", minSerialNumberBits, maxSerialNumberBits, maxSerialNumberBits)
+	}
+	if bits == 0 {
+		bits = maxSerialNumberBits
+	}
+
+	if prefixBits := new(big.Int).SetBytes(prefix).BitLen(); prefixBits+bits > maxSerialNumberBits {
+		return fmt.Errorf("serial_number_prefix of %d bits leaves room for at most %d random bits, less than the %d of serial_number_bits", prefixBits, maxSerialNumberBits-prefixBits, bits)
+	}
+
+	return nil
+}
+
+// normalizeSerialNumberFormat validates the serial_number_bits and
+// serial_number_prefix of an issuer, returning the prefix in the colon
+// separated form of serial numbers.
+func normalizeSerialNumberFormat(bits int, prefix string) (string, error) {
+	raw, err := parseSerialNumberPrefix(prefix)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateSerialNumberFormat(bits, raw); err != nil {
+		return "", err
+	}
+
+	if len(raw) == 0 {
+		return "", nil
+	}
+	return certutil.GetHexFormatted(raw, ":"), nil
+}
+
+// generateSerialNumber returns a serial number for a certificate signed by
+// the given issuer: serial_number_bits random bits, below its
+// serial_number_prefix. Issuers without a configured format, and
+// self-signed roots, get certutil's default of 159 random bits.
+func generateSerialNumber(caSign *certutil.CAInfoBundle) (*big.Int, error) {
+	if caSign == nil || (caSign.SerialNumberBits == 0 && len(caSign.SerialNumberPrefix) == 0) {
+		return certutil.GenerateSerialNumber()
+	}
+
+	bits := caSign.SerialNumberBits
+	if bits == 0 {
+		bits = maxSerialNumberBits
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), uint(bits)))
+	if err != nil {
+		return nil, errutil.InternalError{Err: fmt.Sprintf("error generating serial number: %v", err)}
+	}
+
+	prefix := new(big.Int).SetBytes(caSign.SerialNumberPrefix)
+	return serial.Or(serial, prefix.Lsh(prefix, uint(bits))), nil
+}
+
+// parseRequestedSerialNumber parses a caller supplied serial number, in
+// hex, optionally separated by colons or hyphens.
+func parseRequestedSerialNumber(requested string) (*big.Int, error) {
+	raw, err := hex.DecodeString(strings.NewReplacer(":", "", "-", "").Replace(requested))
+	if err != nil || len(raw) == 0 {
+		return nil, errutil.UserError{Err: fmt.Sprintf("certificate_serial_number %q must be hex encoded", requested)}
+	}
+
+	serial := new(big.Int).SetBytes(raw)
+	if serial.Sign() == 0 || serial.BitLen() > maxSerialNumberBits {
+		return nil, errutil.UserError{Err: fmt.Sprintf("certificate_serial_number %q must be positive and at most 20 octets long", requested)}
+	}
+
+	return serial, nil
+}
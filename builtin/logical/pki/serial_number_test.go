@@ -0,0 +1,211 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package pki
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/certutil"
+	"github.com/hashicorp/vault/sdk/helper/testhelpers/schema"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPki_SerialNumberFormat(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+		"issuer_name": "root",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	for _, invalid := range []map[string]interface{}{
+		{"serial_number_bits": 32},
+		{"serial_number_bits": 160},
+		{"serial_number_prefix": "zz"},
+		{"serial_number_prefix": "00:0a"},
+		{"serial_number_prefix": "0a"},
+		{"serial_number_bits": 150, "serial_number_prefix": "0a:1b"},
+	} {
+		_, err = CBPatch(b, s, "issuer/root", invalid)
+		require.Error(t, err, "expected %v to be refused", invalid)
+	}
+
+	resp, err = CBPatch(b, s, "issuer/root", map[string]interface{}{
+		"serial_number_bits":   64,
+		"serial_number_prefix": "0A-1B",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/root")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issuer/root"), logical.PatchOperation), resp, true)
+	require.Equal(t, 64, resp.Data["serial_number_bits"])
+	require.Equal(t, "0a:1b", resp.Data["serial_number_prefix"])
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "www.example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	cert := parseCert(t, resp.Data["certificate"].(string))
+	require.True(t, strings.HasPrefix(resp.Data["serial_number"].(string), "0a:1b:"), "serial number %v", resp.Data["serial_number"])
+	require.Equal(t, 64+12, cert.SerialNumber.BitLen())
+
+	// Without the prefix, only the number of random bits applies.
+	resp, err = CBPatch(b, s, "issuer/root", map[string]interface{}{
+		"serial_number_prefix": "",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issuer/root")
+	require.Equal(t, 64, resp.Data["serial_number_bits"])
+
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name": "www.example.com",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	cert = parseCert(t, resp.Data["certificate"].(string))
+	require.LessOrEqual(t, cert.SerialNumber.BitLen(), 64)
+}
+
+func TestPki_IssueWithSerial(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	for _, role := range []string{"test", "nostore"} {
+		resp, err = CBWrite(b, s, "roles/"+role, map[string]interface{}{
+			"allow_any_name": true,
+			"key_type":       "ec",
+			"no_store":       role == "nostore",
+		})
+		requireSuccessNonNilResponse(t, resp, err, "roles/"+role)
+	}
+
+	resp, err = CBWrite(b, s, "issue-with-serial/test", map[string]interface{}{
+		"common_name":               "www.example.com",
+		"certificate_serial_number": "01:02:03:04",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue-with-serial/test")
+	schema.ValidateResponse(t, schema.GetResponseSchema(t, b.Route("issue-with-serial/test"), logical.UpdateOperation), resp, true)
+	require.Equal(t, "01:02:03:04", resp.Data["serial_number"])
+	cert := parseCert(t, resp.Data["certificate"].(string))
+	require.Equal(t, int64(0x01020304), cert.SerialNumber.Int64())
+
+	resp, err = CBRead(b, s, "cert/01:02:03:04")
+	requireSuccessNonNilResponse(t, resp, err, "cert/01:02:03:04")
+
+	_, err = CBWrite(b, s, "issue-with-serial/test", map[string]interface{}{
+		"common_name":               "www.example.com",
+		"certificate_serial_number": "01-02-03-04",
+	})
+	require.ErrorContains(t, err, "a certificate with serial number 01:02:03:04 was already issued")
+
+	_, _, csrPem := generateCSR(t, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "www.example.com"},
+	}, "ec", 256)
+	resp, err = CBWrite(b, s, "sign-with-serial/test", map[string]interface{}{
+		"csr":                       csrPem,
+		"certificate_serial_number": "0a0b",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "sign-with-serial/test")
+	require.Equal(t, "0a:0b", resp.Data["serial_number"])
+
+	for _, invalid := range []string{"", "zz", "00", strings.Repeat("ff", 20)} {
+		_, err = CBWrite(b, s, "issue-with-serial/test", map[string]interface{}{
+			"common_name":               "www.example.com",
+			"certificate_serial_number": invalid,
+		})
+		require.Error(t, err, "expected %q to be refused", invalid)
+	}
+
+	_, err = CBWrite(b, s, "issue-with-serial/nostore", map[string]interface{}{
+		"common_name":               "www.example.com",
+		"certificate_serial_number": "05",
+	})
+	require.ErrorContains(t, err, "no_store=true")
+
+	// The regular paths ignore a requested serial number.
+	resp, err = CBWrite(b, s, "issue/test", map[string]interface{}{
+		"common_name":               "www.example.com",
+		"certificate_serial_number": "06",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "issue/test")
+	require.NotEqual(t, "06", resp.Data["serial_number"])
+}
+
+// TestPki_IssueWithSerialConcurrent ensures that of several concurrent
+// requests for the same serial number, exactly one is issued.
+func TestPki_IssueWithSerialConcurrent(t *testing.T) {
+	t.Parallel()
+	b, s := CreateBackendWithStorage(t)
+
+	resp, err := CBWrite(b, s, "root/generate/internal", map[string]interface{}{
+		"common_name": "root example.com",
+		"key_type":    "ec",
+		"ttl":         "40h",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "root/generate/internal")
+
+	resp, err = CBWrite(b, s, "roles/test", map[string]interface{}{
+		"allow_any_name": true,
+		"key_type":       "ec",
+	})
+	requireSuccessNonNilResponse(t, resp, err, "roles/test")
+
+	const requests = 8
+	errs := make([]error, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = CBWrite(b, s, "issue-with-serial/test", map[string]interface{}{
+				"common_name":               "www.example.com",
+				"certificate_serial_number": "0c:0d",
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	issued := 0
+	for _, err := range errs {
+		if err == nil {
+			issued++
+			continue
+		}
+		require.ErrorContains(t, err, "a certificate with serial number 0c:0d was already issued")
+	}
+	require.Equal(t, 1, issued)
+}
+
+func TestGenerateSerialNumber(t *testing.T) {
+	t.Parallel()
+
+	serial, err := generateSerialNumber(&certutil.CAInfoBundle{
+		SerialNumberBits:   64,
+		SerialNumberPrefix: []byte{0x12, 0x34},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(0x1234), serial.Rsh(serial, 64).Int64())
+
+	serial, err = generateSerialNumber(&certutil.CAInfoBundle{})
+	require.NoError(t, err)
+	require.LessOrEqual(t, serial.BitLen(), maxSerialNumberBits)
+}
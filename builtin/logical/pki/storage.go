@@ -44,13 +44,21 @@ const (
 	autoTidyConfigPath = "config/auto-tidy"
 	clusterConfigPath  = "config/cluster"
 
+	// Metadata of stored certificates, such as the role they were issued
+	// through, keyed by serial like the certificates themselves under certs/.
+	certMetadataPrefix = "cert-metadata/"
+
+	// Private keys of issued certificates escrowed by roles with
+	// escrow_private_keys, keyed by serial like the certificates.
+	escrowedKeyPrefix = "escrowed-keys/"
+
 	// Used as a quick sanity check for a reference id lookups...
 	uuidLength = 36
 
 	maxRolesToScanOnIssuerChange = 100
 	maxRolesToFindOnIssuerChange = 10
 
-	latestIssuerVersion = 1
+	latestIssuerVersion = 2
 )
 
 type keyID string
@@ -102,10 +110,16 @@ const (
 	CRLSigningUsage  issuerUsage = 1 << iota
 	OCSPSigningUsage issuerUsage = 1 << iota
 
+	// IntermediateSigningUsage covers signing CA certificates, which used to
+	// be part of IssuanceUsage. It is implied by IssuanceUsage when usages
+	// are given by name, and upgradeIssuerIfRequired infers it for issuers
+	// of earlier versions.
+	IntermediateSigningUsage issuerUsage = 1 << iota
+
 	// When adding a new usage in the future, we'll need to create a usage
 	// mask field on the IssuerEntry and handle migrations to a newer mask,
 	// inferring a value for the new bits.
-	AllIssuerUsages = ReadOnlyUsage | IssuanceUsage | CRLSigningUsage | OCSPSigningUsage
+	AllIssuerUsages = ReadOnlyUsage | IssuanceUsage | CRLSigningUsage | OCSPSigningUsage | IntermediateSigningUsage
 )
 
 var namedIssuerUsages = map[string]issuerUsage{
@@ -113,6 +127,14 @@ var namedIssuerUsages = map[string]issuerUsage{
 	"issuing-certificates": IssuanceUsage,
 	"crl-signing":          CRLSigningUsage,
 	"ocsp-signing":         OCSPSigningUsage,
+	"intermediate-signing": IntermediateSigningUsage,
+}
+
+// issuerUsageModes are shorthands for restricting an issuer to a single
+// duty; they can't be combined with other usages.
+var issuerUsageModes = map[string]issuerUsage{
+	"ocsp-signing-only":      OCSPSigningUsage,
+	"sign-intermediate-only": IntermediateSigningUsage,
 }
 
 func (i *issuerUsage) ToggleUsage(usages ...issuerUsage) {
@@ -156,12 +178,23 @@ func (i issuerUsage) Names() string {
 func NewIssuerUsageFromNames(names []string) (issuerUsage, error) {
 	var result issuerUsage
 	for index, name := range names {
+		if mode, ok := issuerUsageModes[name]; ok {
+			if len(names) != 1 {
+				return ReadOnlyUsage, fmt.Errorf("usage %v at index %v can't be combined with other usages", name, index)
+			}
+			return mode, nil
+		}
+
 		usage, ok := namedIssuerUsages[name]
 		if !ok {
 			return ReadOnlyUsage, fmt.Errorf("unknown name for usage at index %v: %v", index, name)
 		}
 
-		result.ToggleUsage(usage)
+		// Issuing certificates has always included signing intermediates.
+		if usage == IssuanceUsage {
+			usage |= IntermediateSigningUsage
+		}
+		result |= usage
 	}
 
 	return result, nil
@@ -182,6 +215,10 @@ type issuerEntry struct {
 	RevocationTime       int64                     `json:"revocation_time"`
 	RevocationTimeUTC    time.Time                 `json:"revocation_time_utc"`
 	AIAURIs              *aiaConfigEntry           `json:"aia_uris,omitempty"`
+	PolicyIdentifiers    []string                  `json:"policy_identifiers,omitempty"`
+	SerialNumberBits     int                       `json:"serial_number_bits,omitempty"`
+	SerialNumberPrefix   string                    `json:"serial_number_prefix,omitempty"`
+	CRLSigner            *crlSignerEntry           `json:"crl_signer,omitempty"`
 	LastModified         time.Time                 `json:"last_modified"`
 	Version              uint                      `json:"version"`
 }
@@ -197,7 +234,8 @@ type internalCRLConfigEntry struct {
 }
 
 type keyConfigEntry struct {
-	DefaultKeyId keyID `json:"default"`
+	DefaultKeyId       keyID `json:"default"`
+	AllowWrappedExport bool  `json:"allow_wrapped_export"`
 }
 
 type issuerConfigEntry struct {
@@ -219,17 +257,27 @@ type aiaConfigEntry struct {
 	CRLDistributionPoints []string `json:"crl_distribution_points"`
 	OCSPServers           []string `json:"ocsp_servers"`
 	EnableTemplating      bool     `json:"enable_templating"`
+
+	// DeltaCRLDistributionPoints are not placed on issued certificates;
+	// instead, they're advertised on complete CRLs through the Freshest
+	// CRL extension when delta CRLs are enabled.
+	DeltaCRLDistributionPoints []string `json:"delta_crl_distribution_points,omitempty"`
+}
+
+func (c *aiaConfigEntry) isEmpty() bool {
+	return len(c.IssuingCertificates) == 0 && len(c.CRLDistributionPoints) == 0 && len(c.OCSPServers) == 0 && len(c.DeltaCRLDistributionPoints) == 0
 }
 
 func (c *aiaConfigEntry) toURLEntries(sc *storageContext, issuer issuerID) (*certutil.URLEntries, error) {
-	if len(c.IssuingCertificates) == 0 && len(c.CRLDistributionPoints) == 0 && len(c.OCSPServers) == 0 {
+	if c.isEmpty() {
 		return &certutil.URLEntries{}, nil
 	}
 
 	result := certutil.URLEntries{
-		IssuingCertificates:   c.IssuingCertificates[:],
-		CRLDistributionPoints: c.CRLDistributionPoints[:],
-		OCSPServers:           c.OCSPServers[:],
+		IssuingCertificates:        c.IssuingCertificates[:],
+		CRLDistributionPoints:      c.CRLDistributionPoints[:],
+		OCSPServers:                c.OCSPServers[:],
+		DeltaCRLDistributionPoints: c.DeltaCRLDistributionPoints[:],
 	}
 
 	if c.EnableTemplating {
@@ -239,9 +287,10 @@ func (c *aiaConfigEntry) toURLEntries(sc *storageContext, issuer issuerID) (*cer
 		}
 
 		for name, source := range map[string]*[]string{
-			"issuing_certificates":    &result.IssuingCertificates,
-			"crl_distribution_points": &result.CRLDistributionPoints,
-			"ocsp_servers":            &result.OCSPServers,
+			"issuing_certificates":          &result.IssuingCertificates,
+			"crl_distribution_points":       &result.CRLDistributionPoints,
+			"ocsp_servers":                  &result.OCSPServers,
+			"delta_crl_distribution_points": &result.DeltaCRLDistributionPoints,
 		} {
 			templated := make([]string, len(*source))
 			for index, uri := range *source {
@@ -535,6 +584,12 @@ func (i issuerEntry) EnsureUsage(usage issuerUsage) error {
 		issuerRef = fmt.Sprintf("%v / name:%v", issuerRef, i.Name)
 	}
 
+	// Revocation strips the issuance usages; say so rather than leaving
+	// the caller to guess why they're missing.
+	if i.Revoked && (usage.HasUsage(IssuanceUsage) || usage.HasUsage(IntermediateSigningUsage)) {
+		return fmt.Errorf("issuer [%v] was revoked and can no longer issue certificates", issuerRef)
+	}
+
 	// These usages differ at some point in time. We've gotta find the first
 	// usage that differs and return a logical-sounding error message around
 	// that difference.
@@ -599,7 +654,7 @@ func (i issuerEntry) GetAIAURLs(sc *storageContext) (*certutil.URLEntries, error
 
 	// If none are set (either due to a nil entry or because no URLs have
 	// been provided), fall back to the global AIA URL config.
-	if entries == nil || (len(entries.IssuingCertificates) == 0 && len(entries.CRLDistributionPoints) == 0 && len(entries.OCSPServers) == 0) {
+	if entries == nil || entries.isEmpty() {
 		var err error
 
 		entries, err = getGlobalAIAURLs(sc.Context, sc.Storage)
@@ -732,6 +787,14 @@ func (sc *storageContext) upgradeIssuerIfRequired(issuer *issuerEntry) *issuerEn
 		}
 	}
 
+	if issuer.Version < 2 {
+		// Signing intermediates was split out of issuing certificates; keep
+		// issuers which could issue able to sign intermediates.
+		if issuer.Usage.HasUsage(IssuanceUsage) && !issuer.Usage.HasUsage(IntermediateSigningUsage) {
+			issuer.Usage.ToggleUsage(IntermediateSigningUsage)
+		}
+	}
+
 	issuer.Version = latestIssuerVersion
 	return issuer
 }
@@ -747,7 +810,14 @@ func (sc *storageContext) writeIssuer(issuer *issuerEntry) error {
 		return err
 	}
 
-	return sc.Storage.Put(sc.Context, json)
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return err
+	}
+
+	// Cached OCSP responses may have been signed by, or on behalf of,
+	// this issuer.
+	sc.Backend.ocspCache.purge()
+	return nil
 }
 
 func (sc *storageContext) deleteIssuer(id issuerID) (bool, error) {
@@ -768,6 +838,7 @@ func (sc *storageContext) deleteIssuer(id issuerID) (bool, error) {
 		}
 	}
 
+	sc.Backend.ocspCache.purge()
 	return wasDefault, sc.Storage.Delete(sc.Context, issuerPrefix+id.String())
 }
 
@@ -1036,6 +1107,9 @@ func (sc *storageContext) setIssuersConfig(config *issuerConfigEntry) error {
 		return err
 	}
 
+	// Unknown OCSP responses are signed by the default issuer.
+	sc.Backend.ocspCache.purge()
+
 	if err := sc.changeDefaultIssuerTimestamps(config.fetchedDefault, config.DefaultIssuerId); err != nil {
 		return err
 	}
@@ -1247,6 +1321,9 @@ func (sc *storageContext) isKeyInUse(keyId string) (inUse bool, issuerId string,
 		if issuerEntry.KeyID.String() == keyId {
 			return true, issuerId.String(), nil
 		}
+		if issuerEntry.CRLSigner != nil && issuerEntry.CRLSigner.KeyID.String() == keyId {
+			return true, issuerId.String(), nil
+		}
 	}
 
 	return false, "", nil
@@ -1324,6 +1401,12 @@ func (sc *storageContext) getRevocationConfig() (*crlConfig, error) {
 	if result.Expiry == "" {
 		result.Expiry = defaultCrlConfig.Expiry
 	}
+	if result.OcspResponderTTL == "" {
+		result.OcspResponderTTL = defaultCrlConfig.OcspResponderTTL
+	}
+	if result.OcspCacheTTL == "" {
+		result.OcspCacheTTL = defaultCrlConfig.OcspCacheTTL
+	}
 
 	isLocalMount := sc.Backend.System().LocalMount()
 	if (!constants.IsEnterprise || isLocalMount) && (result.UnifiedCRLOnExistingPaths || result.UnifiedCRL || result.UseGlobalQueue) {
@@ -1446,6 +1529,75 @@ func (sc *storageContext) writeClusterConfig(config *clusterConfigEntry) error {
 	return sc.Storage.Put(sc.Context, entry)
 }
 
+// certMetadataEntry records how a stored certificate was issued, along with
+// any metadata the client provided. Certificates stored before roles were
+// recorded, and ones not issued through a role (like issuers), have none.
+type certMetadataEntry struct {
+	Role         string            `json:"role"`
+	IssuerId     issuerID          `json:"issuer_id"`
+	CertMetadata map[string]string `json:"cert_metadata,omitempty"`
+}
+
+func (sc *storageContext) fetchCertMetadata(serial string) (*certMetadataEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, certMetadataPrefix+normalizeSerial(serial))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata of certificate %v: %w", serial, err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var metadata certMetadataEntry
+	if err := entry.DecodeJSON(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata of certificate %v: %w", serial, err)
+	}
+
+	return &metadata, nil
+}
+
+func (sc *storageContext) writeCertMetadata(serial string, metadata *certMetadataEntry) error {
+	entry, err := logical.StorageEntryJSON(certMetadataPrefix+normalizeSerial(serial), metadata)
+	if err != nil {
+		return err
+	}
+
+	return sc.Storage.Put(sc.Context, entry)
+}
+
+// escrowedKeyEntry holds the private key of an issued certificate, escrowed
+// for its recovery.
+type escrowedKeyEntry struct {
+	PrivateKey     string                  `json:"private_key"`
+	PrivateKeyType certutil.PrivateKeyType `json:"private_key_type"`
+	Role           string                  `json:"role"`
+}
+
+func (sc *storageContext) fetchEscrowedKey(serial string) (*escrowedKeyEntry, error) {
+	entry, err := sc.Storage.Get(sc.Context, escrowedKeyPrefix+normalizeSerial(serial))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch escrowed key of certificate %v: %w", serial, err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	var escrowed escrowedKeyEntry
+	if err := entry.DecodeJSON(&escrowed); err != nil {
+		return nil, fmt.Errorf("failed to decode escrowed key of certificate %v: %w", serial, err)
+	}
+
+	return &escrowed, nil
+}
+
+func (sc *storageContext) writeEscrowedKey(serial string, escrowed *escrowedKeyEntry) error {
+	entry, err := logical.StorageEntryJSON(escrowedKeyPrefix+normalizeSerial(serial), escrowed)
+	if err != nil {
+		return err
+	}
+
+	return sc.Storage.Put(sc.Context, entry)
+}
+
 func (sc *storageContext) fetchRevocationInfo(serial string) (*revocationInfo, error) {
 	var revInfo *revocationInfo
 	revEntry, err := fetchCertBySerial(sc, revokedPath, serial)
@@ -191,7 +191,7 @@ func Test_IssuerUpgrade(t *testing.T) {
 	newIssuer, err := sc.fetchIssuerById(issuer.ID)
 	require.NoError(t, err, "failed fetching issuer")
 
-	require.Equal(t, uint(1), newIssuer.Version)
+	require.Equal(t, uint(latestIssuerVersion), newIssuer.Version)
 	require.True(t, newIssuer.Usage.HasUsage(OCSPSigningUsage))
 
 	// If CRLSigning is not present on a v0, we should not have OCSP signing after upgrade.
@@ -206,8 +206,28 @@ func Test_IssuerUpgrade(t *testing.T) {
 	newIssuer, err = sc.fetchIssuerById(issuer.ID)
 	require.NoError(t, err, "failed fetching issuer")
 
-	require.Equal(t, uint(1), newIssuer.Version)
+	require.Equal(t, uint(latestIssuerVersion), newIssuer.Version)
 	require.False(t, newIssuer.Usage.HasUsage(OCSPSigningUsage))
+
+	// Make sure that v1 issuers able to issue certificates can still sign
+	// intermediates, and others can't.
+	for _, canIssue := range []bool{true, false} {
+		issuer, _ = genIssuerAndKey(t, b, s)
+		issuer.Version = 1
+		issuer.Usage.ToggleUsage(IntermediateSigningUsage)
+		if !canIssue {
+			issuer.Usage.ToggleUsage(IssuanceUsage)
+		}
+
+		err = sc.writeIssuer(&issuer)
+		require.NoError(t, err, "failed writing out issuer")
+
+		newIssuer, err = sc.fetchIssuerById(issuer.ID)
+		require.NoError(t, err, "failed fetching issuer")
+
+		require.Equal(t, uint(latestIssuerVersion), newIssuer.Version)
+		require.Equal(t, canIssue, newIssuer.Usage.HasUsage(IntermediateSigningUsage))
+	}
 }
 
 func genIssuerAndKey(t *testing.T, b *backend, s logical.Storage) (issuerEntry, keyEntry) {
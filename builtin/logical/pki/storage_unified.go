@@ -21,6 +21,7 @@ type unifiedRevocationEntry struct {
 	CertExpiration    time.Time `json:"certificate_expiration_utc"`
 	RevocationTimeUTC time.Time `json:"revocation_time_utc"`
 	CertificateIssuer issuerID  `json:"issuer_id"`
+	RevocationReason  int       `json:"revocation_reason,omitempty"`
 }
 
 func getUnifiedRevocationBySerial(sc *storageContext, serial string) (*unifiedRevocationEntry, error) {
@@ -55,7 +56,12 @@ func writeUnifiedRevocationEntry(sc *storageContext, ure *unifiedRevocationEntry
 		return err
 	}
 
-	return sc.Storage.Put(sc.Context, json)
+	if err := sc.Storage.Put(sc.Context, json); err != nil {
+		return err
+	}
+
+	sc.Backend.ocspCache.purge()
+	return nil
 }
 
 // listClusterSpecificUnifiedRevokedCerts returns a list of revoked certificates from a given cluster
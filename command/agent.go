@@ -6,6 +6,7 @@ package command
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -77,6 +78,17 @@ type AgentCommand struct {
 	tlsReloadFuncsLock sync.RWMutex
 	tlsReloadFuncs     []reloadutil.ReloadFunc
 
+	// templateServer is set if Vault Agent was started with templates, and is
+	// used to reload them without restarting auto-auth or the cache.
+	templateServer *template.Server
+
+	// reloadLock serializes reloads triggered by SIGHUP and the reload API.
+	reloadLock sync.Mutex
+
+	// restartRequiredConfig holds the stanzas of the configuration files
+	// which can't be reloaded, as last read, to reject reloads changing them.
+	restartRequiredConfig map[string]string
+
 	logWriter io.Writer
 	logGate   *gatedwriter.Writer
 	logger    log.Logger
@@ -199,6 +211,14 @@ func (c *AgentCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Record the stanzas that can't be reloaded before flags, env vars and
+	// the setup below modify them.
+	c.restartRequiredConfig, err = restartRequiredConfig(config)
+	if err != nil {
+		c.outputErrors(err)
+		return 1
+	}
+
 	if config.AutoAuth == nil {
 		c.UI.Info("No auto_auth block found in config, the automatic authentication feature will not be started")
 	}
@@ -586,11 +606,13 @@ func (c *AgentCommand) Run(args []string) int {
 		// Create a muxer and add paths relevant for the lease cache layer
 		mux := http.NewServeMux()
 		quitEnabled := lnConfig.AgentAPI != nil && lnConfig.AgentAPI.EnableQuit
+		reloadEnabled := lnConfig.AgentAPI != nil && lnConfig.AgentAPI.EnableReload
 
 		mux.Handle(consts.AgentPathMetrics, c.handleMetrics())
 		if "metrics_only" != lnConfig.Role {
 			mux.Handle(consts.AgentPathCacheClear, leaseCache.HandleCacheClear(ctx))
 			mux.Handle(consts.AgentPathQuit, c.handleQuit(quitEnabled))
+			mux.Handle(consts.AgentPathReload, c.handleReload(reloadEnabled))
 			mux.Handle("/", muxHandler)
 		}
 
@@ -640,16 +662,7 @@ func (c *AgentCommand) Run(args []string) int {
 		for {
 			select {
 			case <-c.SighupCh:
-				c.UI.Output("==> Vault Agent config reload triggered")
-				err := c.reloadConfig(c.flagConfigs)
-				if err != nil {
-					c.outputErrors(err)
-				}
-				// Send the 'reloaded' message on the relevant channel
-				select {
-				case c.reloadedCh <- struct{}{}:
-				default:
-				}
+				c.reload()
 			case <-ctx.Done():
 				return nil
 			}
@@ -728,6 +741,9 @@ func (c *AgentCommand) Run(args []string) int {
 			Namespace:     templateNamespace,
 			ExitAfterAuth: config.ExitAfterAuth,
 		})
+		if len(config.Templates) > 0 {
+			c.templateServer = ts
+		}
 
 		es := exec.NewServer(&exec.ServerConfig{
 			AgentConfig: c.config,
@@ -1086,6 +1102,33 @@ func (c *AgentCommand) handleQuit(enabled bool) http.Handler {
 	})
 }
 
+func (c *AgentCommand) handleReload(enabled bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !enabled {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		c.logger.Debug("received reload request")
+		if err := c.reload(); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, errRestartRequired) {
+				status = http.StatusBadRequest
+			}
+			logical.RespondError(w, status, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
 // newLogger creates a logger based on parsed config field on the Agent Command struct.
 func (c *AgentCommand) newLogger() (log.InterceptLogger, error) {
 	if c.config == nil {
@@ -1157,6 +1200,26 @@ func (c *AgentCommand) loadConfig(paths []string) (*agentConfig.Config, error) {
 	return cfg, nil
 }
 
+// reload reloads the configuration, reporting any error, on SIGHUP or a
+// request to the reload API.
+func (c *AgentCommand) reload() error {
+	c.reloadLock.Lock()
+	defer c.reloadLock.Unlock()
+
+	c.UI.Output("==> Vault Agent config reload triggered")
+	err := c.reloadConfig(c.flagConfigs)
+	if err != nil {
+		c.outputErrors(err)
+	}
+	// Send the 'reloaded' message on the relevant channel
+	select {
+	case c.reloadedCh <- struct{}{}:
+	default:
+	}
+
+	return err
+}
+
 // reloadConfig will attempt to reload the config from file(s) and adjust certain
 // config values without requiring a restart of the Vault Agent.
 // If config is retrieved without error it is stored in the config field of the AgentCommand.
@@ -1166,6 +1229,9 @@ func (c *AgentCommand) loadConfig(paths []string) (*agentConfig.Config, error) {
 // Currently only reloading the following are supported:
 // * log level
 // * TLS certs for listeners
+// * templates, the template_config stanza and pki_bundle blocks
+// The lease cache and the auto-auth token are kept across reloads. A config
+// changing any of the stanzas in restartRequiredConfig is rejected as a whole.
 func (c *AgentCommand) reloadConfig(paths []string) error {
 	// Notify systemd that the server is reloading
 	c.notifySystemd(systemd.SdNotifyReloading)
@@ -1179,6 +1245,25 @@ func (c *AgentCommand) reloadConfig(paths []string) error {
 		// Returning single error as we won't continue with bad config and won't 'commit' it.
 		return err
 	}
+
+	// The stanzas read on start-up are recorded by Run.
+	if c.restartRequiredConfig != nil {
+		fixed, err := restartRequiredConfig(cfg)
+		if err != nil {
+			return err
+		}
+		var changed []string
+		for name, value := range fixed {
+			if c.restartRequiredConfig[name] != value {
+				changed = append(changed, name)
+			}
+		}
+		if len(changed) > 0 {
+			sort.Strings(changed)
+			return fmt.Errorf("%w: %s", errRestartRequired, strings.Join(changed, ", "))
+		}
+	}
+
 	c.config = cfg
 
 	// Update the log level
@@ -1193,9 +1278,45 @@ func (c *AgentCommand) reloadConfig(paths []string) error {
 		errors = multierror.Append(errors, err)
 	}
 
+	// Update templates
+	err = c.reloadTemplates()
+	if err != nil {
+		errors = multierror.Append(errors, err)
+	}
+
 	return errors
 }
 
+// errRestartRequired is returned when reloading a config which changes stanzas
+// that are only read on start-up.
+var errRestartRequired = errors.New("the configuration of the following stanzas can't be reloaded and requires a restart of Vault Agent")
+
+// restartRequiredConfig returns the stanzas of cfg which are only read on
+// start-up, keyed by name and encoded so that they can be compared.
+func restartRequiredConfig(cfg *agentConfig.Config) (map[string]string, error) {
+	stanzas := map[string]interface{}{
+		"auto_auth":       cfg.AutoAuth,
+		"listener":        cfg.Listeners,
+		"cache":           cfg.Cache,
+		"api_proxy":       cfg.APIProxy,
+		"vault":           cfg.Vault,
+		"exit_after_auth": cfg.ExitAfterAuth,
+		"exec":            cfg.Exec,
+		"env_template":    cfg.EnvTemplates,
+	}
+
+	encoded := make(map[string]string, len(stanzas))
+	for name, stanza := range stanzas {
+		b, err := json.Marshal(stanza)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding %s stanza: %w", name, err)
+		}
+		encoded[name] = string(b)
+	}
+
+	return encoded, nil
+}
+
 // reloadLogLevel will attempt to update the log level for the logger attached
 // to the AgentComment struct using the value currently set in config.
 func (c *AgentCommand) reloadLogLevel() error {
@@ -1209,6 +1330,21 @@ func (c *AgentCommand) reloadLogLevel() error {
 	return nil
 }
 
+// reloadTemplates hands the templates currently set in config to the template
+// server, which re-renders them using the current auto-auth token.
+func (c *AgentCommand) reloadTemplates() error {
+	if c.templateServer == nil {
+		if len(c.config.Templates) > 0 {
+			return fmt.Errorf("templates can only be reloaded if Vault Agent was started with templates")
+		}
+		return nil
+	}
+
+	c.templateServer.Reload(c.config)
+
+	return nil
+}
+
 // reloadCerts will attempt to reload certificates using a reload func which
 // was provided when the listeners were configured, only funcs that were appended
 // to the AgentCommand slice will be invoked.
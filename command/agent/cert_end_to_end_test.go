@@ -5,6 +5,7 @@ package agent
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
@@ -579,3 +580,161 @@ func TestCertEndToEnd_CertsInConfig(t *testing.T) {
 		time.Sleep(250 * time.Millisecond)
 	}
 }
+
+func TestCertEndToEnd_CertRenewal(t *testing.T) {
+	logger := logging.NewVaultLogger(hclog.Trace)
+	coreConfig := &vault.CoreConfig{
+		CredentialBackends: map[string]logical.Factory{
+			"cert": vaultcert.Factory,
+		},
+		LogicalBackends: map[string]logical.Factory{
+			"pki": pki.Factory,
+		},
+	}
+	cluster := vault.NewTestCluster(t, coreConfig, &vault.TestClusterOptions{
+		HandlerFunc: vaulthttp.Handler,
+	})
+	cluster.Start()
+	defer cluster.Cleanup()
+
+	vault.TestWaitActive(t, cluster.Cores[0].Core)
+	client := cluster.Cores[0].Client
+
+	// Mount /pki as a root CA using the cluster's CA, so the re-issued
+	// certificates are trusted by the cluster's TLS listeners.
+	err := client.Sys().Mount("pki", &api.MountInput{
+		Type: "pki",
+		Config: api.MountConfigInput{
+			DefaultLeaseTTL: "16h",
+			MaxLeaseTTL:     "32h",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("pki/config/ca", map[string]interface{}{
+		"pem_bundle": string(cluster.CACertPEM) + string(cluster.CAKeyPEM),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("pki/roles/agent", map[string]interface{}{
+		"allowed_domains":     "myvault.com",
+		"allow_subdomains":    "true",
+		"max_ttl":             "5m",
+		"not_before_duration": "1s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := client.Logical().Write("pki/issue/agent", map[string]interface{}{
+		"common_name": "cert.myvault.com",
+		"ttl":         "30s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafCertPEM := secret.Data["certificate"].(string)
+	leafCertKeyPEM := secret.Data["private_key"].(string)
+
+	dir := t.TempDir()
+	caCertFile := dir + "/ca.pem"
+	leafCertFile := dir + "/cert.pem"
+	leafCertKeyFile := dir + "/key.pem"
+	if err := os.WriteFile(caCertFile, cluster.CACertPEM, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(leafCertFile, []byte(leafCertPEM), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(leafCertKeyFile, []byte(leafCertKeyPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens issued via cert auth may re-issue their own certificate.
+	err = client.Sys().PutPolicy("renew", `path "pki/issue/agent" { capabilities = ["update"] }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = client.Sys().EnableAuthWithOptions("cert", &api.EnableAuthOptions{
+		Type: "cert",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Logical().Write("auth/cert/certs/agent", map[string]interface{}{
+		"display_name": "myvault.com",
+		"policies":     "default,renew",
+		"certificate":  string(cluster.CACertPEM),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	am, err := agentcert.NewCertAuthMethod(&auth.AuthConfig{
+		Logger:    logger.Named("auth.cert"),
+		MountPath: "auth/cert",
+		Config: map[string]interface{}{
+			"name":           "agent",
+			"ca_cert":        caCertFile,
+			"client_cert":    leafCertFile,
+			"client_key":     leafCertKeyFile,
+			"pki_issue_path": "pki/issue/agent",
+			"pki_ttl":        "1m",
+			"renew_before":   "25s",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ah := auth.NewAuthHandler(&auth.AuthHandlerConfig{
+		Logger:                       logger.Named("auth.handler"),
+		Client:                       client,
+		EnableReauthOnNewCredentials: true,
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- ah.Run(ctx, am)
+	}()
+
+	var tokens []string
+	timeout := time.After(20 * time.Second)
+	for len(tokens) < 2 {
+		select {
+		case <-timeout:
+			t.Fatalf("expected a re-authentication after certificate renewal, got %d tokens", len(tokens))
+		case err := <-errCh:
+			t.Fatalf("auth handler exited early: %v", err)
+		case token := <-ah.OutputCh:
+			tokens = append(tokens, token)
+		}
+	}
+
+	renewedPEM, err := os.ReadFile(leafCertFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(renewedPEM)
+	if block == nil {
+		t.Fatal("renewed certificate is not PEM encoded")
+	}
+	renewed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.Subject.CommonName != "cert.myvault.com" {
+		t.Fatalf("unexpected common name on renewed certificate: %q", renewed.Subject.CommonName)
+	}
+	if string(renewedPEM) == leafCertPEM {
+		t.Fatal("expected client certificate to have been replaced")
+	}
+	if renewedKey, err := os.ReadFile(leafCertKeyFile); err != nil {
+		t.Fatal(err)
+	} else if string(renewedKey) == leafCertKeyPEM {
+		t.Fatal("expected client key to have been replaced")
+	}
+}
@@ -51,6 +51,10 @@ type Config struct {
 	DisableKeepAlivesAutoAuth   bool                       `hcl:"-"`
 	Exec                        *ExecConfig                `hcl:"exec,optional"`
 	EnvTemplates                []*ctconfig.TemplateConfig `hcl:"env_template,optional"`
+
+	// TemplatePKIBundles holds the pki_bundle blocks of templates, keyed by
+	// the destination of the template they were defined in.
+	TemplatePKIBundles map[string]*TemplatePKIBundle `hcl:"-"`
 }
 
 const (
@@ -167,6 +171,15 @@ type TemplateConfig struct {
 	StaticSecretRenderInt    time.Duration `hcl:"-"`
 }
 
+// TemplatePKIBundle splits the PEM blocks rendered by a template into separate
+// certificate, private key and CA files. The template destination keeps the
+// full bundle so that pkiCert can reuse the certificate until it nears expiry.
+type TemplatePKIBundle struct {
+	CertDestination string `mapstructure:"cert_destination"`
+	KeyDestination  string `mapstructure:"key_destination"`
+	CADestination   string `mapstructure:"ca_destination"`
+}
+
 type ExecConfig struct {
 	Command                []string  `hcl:"command,attr" mapstructure:"command"`
 	RestartOnSecretChanges string    `hcl:"restart_on_secret_changes,optional" mapstructure:"restart_on_secret_changes"`
@@ -255,6 +268,19 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.Templates = append(result.Templates, l)
 	}
 
+	for dest, b := range c.TemplatePKIBundles {
+		if result.TemplatePKIBundles == nil {
+			result.TemplatePKIBundles = make(map[string]*TemplatePKIBundle)
+		}
+		result.TemplatePKIBundles[dest] = b
+	}
+	for dest, b := range c2.TemplatePKIBundles {
+		if result.TemplatePKIBundles == nil {
+			result.TemplatePKIBundles = make(map[string]*TemplatePKIBundle)
+		}
+		result.TemplatePKIBundles[dest] = b
+	}
+
 	result.ExitAfterAuth = c.ExitAfterAuth
 	if c2.ExitAfterAuth {
 		result.ExitAfterAuth = c2.ExitAfterAuth
@@ -1065,6 +1091,15 @@ func parseSinks(result *Config, list *ast.ObjectList) error {
 		switch s.DHType {
 		case "":
 		case "curve25519":
+		case "age":
+			// age derives its own keys and authenticates the whole payload,
+			// so the curve25519 envelope options do not apply.
+			if s.AAD != "" || s.AADEnvVar != "" {
+				return multierror.Prefix(errors.New("'aad' and 'aad_env_var' are not supported with 'dh_type' of \"age\""), fmt.Sprintf("sink.%s", s.Type))
+			}
+			if s.DeriveKey {
+				return multierror.Prefix(errors.New("'derive_key' is not supported with 'dh_type' of \"age\""), fmt.Sprintf("sink.%s", s.Type))
+			}
 		default:
 			return multierror.Prefix(errors.New("invalid value for 'dh_type'"), fmt.Sprintf("sink.%s", s.Type))
 		}
@@ -1167,6 +1202,18 @@ func parseTemplates(result *Config, list *ast.ObjectList) error {
 			parsed["exec"] = exec[len(exec)-1]
 		}
 
+		// pki_bundle is an Agent specific block that Consul Template does not
+		// know about, so it is removed before decoding the template config.
+		var bundle *TemplatePKIBundle
+		if rawBundle, ok := parsed["pki_bundle"]; ok {
+			delete(parsed, "pki_bundle")
+			b, err := parseTemplatePKIBundle(rawBundle)
+			if err != nil {
+				return err
+			}
+			bundle = b
+		}
+
 		var tc ctconfig.TemplateConfig
 
 		// Use mapstructure to populate the basic config fields
@@ -1188,12 +1235,58 @@ func parseTemplates(result *Config, list *ast.ObjectList) error {
 		if err := decoder.Decode(parsed); err != nil {
 			return err
 		}
+
+		if bundle != nil {
+			if tc.Destination == nil || *tc.Destination == "" {
+				return errors.New("template: pki_bundle requires a destination to be set")
+			}
+			if result.TemplatePKIBundles == nil {
+				result.TemplatePKIBundles = make(map[string]*TemplatePKIBundle)
+			}
+			result.TemplatePKIBundles[*tc.Destination] = bundle
+		}
+
 		tcs = append(tcs, &tc)
 	}
 	result.Templates = tcs
 	return nil
 }
 
+func parseTemplatePKIBundle(raw interface{}) (*TemplatePKIBundle, error) {
+	// As with wait and exec, only the last pki_bundle block is used if
+	// several are given.
+	var m map[string]interface{}
+	switch v := raw.(type) {
+	case []map[string]interface{}:
+		if len(v) == 0 {
+			return nil, errors.New("template: empty pki_bundle block")
+		}
+		m = v[len(v)-1]
+	case map[string]interface{}:
+		m = v
+	default:
+		return nil, errors.New("template: error converting pki_bundle block")
+	}
+
+	var bundle TemplatePKIBundle
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		ErrorUnused: true,
+		Result:      &bundle,
+	})
+	if err != nil {
+		return nil, errors.New("mapstructure decoder creation failed")
+	}
+	if err := decoder.Decode(m); err != nil {
+		return nil, fmt.Errorf("template: error decoding pki_bundle: %w", err)
+	}
+
+	if bundle.CertDestination == "" && bundle.KeyDestination == "" && bundle.CADestination == "" {
+		return nil, errors.New("template: pki_bundle requires at least one of cert_destination, key_destination or ca_destination")
+	}
+
+	return &bundle, nil
+}
+
 func parseExec(result *Config, list *ast.ObjectList) error {
 	name := "exec"
 
@@ -678,6 +678,13 @@ func TestLoadConfigFile_Bad_AutoAuth_Wrapped_Multiple_Sinks(t *testing.T) {
 	}
 }
 
+func TestLoadConfigFile_Bad_AutoAuth_Age_Sink_AAD(t *testing.T) {
+	_, err := LoadConfigFile("./test-fixtures/bad-config-auto_auth-age-sink-aad.hcl")
+	if err == nil {
+		t.Fatalf("LoadConfigFile should return an error for this config, err: %v", err)
+	}
+}
+
 func TestLoadConfigFile_Bad_AutoAuth_Nosinks_Nocache_Notemplates(t *testing.T) {
 	config, err := LoadConfigFile("./test-fixtures/bad-config-auto_auth-nosinks-nocache-notemplates.hcl")
 	if err != nil {
@@ -1150,6 +1157,35 @@ func TestLoadConfigFile_Template(t *testing.T) {
 	}
 }
 
+// TestLoadConfigFile_Template_PKIBundle tests parsing of the pki_bundle block
+// of a template stanza
+func TestLoadConfigFile_Template_PKIBundle(t *testing.T) {
+	config, err := LoadConfigFile("./test-fixtures/config-template-pki-bundle.hcl")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(config.Templates) != 1 {
+		t.Fatalf("expected 1 template, got %d", len(config.Templates))
+	}
+
+	expected := map[string]*TemplatePKIBundle{
+		"/path/on/disk/bundle.pem": {
+			CertDestination: "/path/on/disk/cert.pem",
+			KeyDestination:  "/path/on/disk/key.pem",
+			CADestination:   "/path/on/disk/ca.pem",
+		},
+	}
+	if diff := deep.Equal(config.TemplatePKIBundles, expected); diff != nil {
+		t.Fatal(diff)
+	}
+
+	_, err = LoadConfigFile("./test-fixtures/bad-config-template-pki-bundle-no-destination.hcl")
+	if err == nil {
+		t.Fatal("LoadConfigFile should return an error for a pki_bundle without a destination")
+	}
+}
+
 // TestLoadConfigFile_Template_NoSinks tests template definitions without sinks in Vault Agent
 func TestLoadConfigFile_Template_NoSinks(t *testing.T) {
 	testCases := map[string]struct {
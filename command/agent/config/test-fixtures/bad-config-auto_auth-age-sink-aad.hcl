@@ -0,0 +1,22 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: BUSL-1.1
+
+pid_file = "./pidfile"
+
+auto_auth {
+  method "aws" {
+    mount_path = "auth/aws"
+    config = {
+      role = "foobar"
+    }
+  }
+
+  sink "file" {
+    dh_type = "age"
+    dh_path = "/tmp/file-foo-age-recipients"
+    aad = "foobar"
+    config = {
+      path = "/tmp/file-foo"
+    }
+  }
+}
@@ -0,0 +1,23 @@
+# Copyright (c) HashiCorp, Inc.
+# SPDX-License-Identifier: BUSL-1.1
+
+pid_file = "./pidfile"
+
+auto_auth {
+  method {
+    type = "aws"
+
+    config = {
+      role = "foobar"
+    }
+  }
+}
+
+template {
+  contents = "{{ with pkiCert \"pki/issue/example\" \"common_name=foo.example.com\" }}{{ .Cert }}{{ .Key }}{{ end }}"
+
+  pki_bundle {
+    cert_destination = "/path/on/disk/cert.pem"
+    key_destination  = "/path/on/disk/key.pem"
+  }
+}
@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/consul-template/manager"
+
+	"github.com/hashicorp/vault/command/agent/config"
+)
+
+// pkiBundlePEMs holds the PEM encoded parts of a rendered PKI bundle.
+type pkiBundlePEMs struct {
+	cert []byte
+	key  []byte
+	ca   []byte
+}
+
+// splitPKIBundle sorts the PEM blocks found in contents into the leaf
+// certificate, private key and CA certificates.
+func splitPKIBundle(contents []byte) (*pkiBundlePEMs, error) {
+	var pems pkiBundlePEMs
+	rest := contents
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		encoded := pem.EncodeToMemory(block)
+		switch {
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			pems.key = append(pems.key, encoded...)
+		case block.Type == "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing rendered certificate: %w", err)
+			}
+			if cert.IsCA {
+				pems.ca = append(pems.ca, encoded...)
+			} else {
+				pems.cert = append(pems.cert, encoded...)
+			}
+		}
+	}
+
+	if len(pems.cert) == 0 && len(pems.key) == 0 && len(pems.ca) == 0 {
+		return nil, errors.New("no PEM blocks found in rendered template")
+	}
+
+	return &pems, nil
+}
+
+// writePKIBundle writes the parts of pems to the destinations configured in
+// bundle. All files are first written to temporary files next to their
+// destination and only renamed into place once every part has been written,
+// so no file is replaced if any part fails to be written. The renames are not
+// atomic as a group: readers may briefly observe a new certificate next to the
+// previous key, and files already renamed are kept if a later rename fails.
+func writePKIBundle(bundle *config.TemplatePKIBundle, pems *pkiBundlePEMs) error {
+	type part struct {
+		dest string
+		data []byte
+		mode os.FileMode
+	}
+	var parts []part
+	for _, p := range []part{
+		{dest: bundle.CertDestination, data: pems.cert, mode: 0o644},
+		{dest: bundle.KeyDestination, data: pems.key, mode: 0o600},
+		{dest: bundle.CADestination, data: pems.ca, mode: 0o644},
+	} {
+		if p.dest == "" {
+			continue
+		}
+		if len(p.data) == 0 {
+			return fmt.Errorf("rendered template has no content for %q", p.dest)
+		}
+		parts = append(parts, p)
+	}
+
+	var tmpFiles []string
+	cleanup := func() {
+		for _, f := range tmpFiles {
+			os.Remove(f)
+		}
+	}
+
+	for _, p := range parts {
+		if existing, err := os.ReadFile(p.dest); err == nil && bytes.Equal(existing, p.data) {
+			tmpFiles = append(tmpFiles, "")
+			continue
+		}
+
+		dir := filepath.Dir(p.dest)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			cleanup()
+			return err
+		}
+		f, err := os.CreateTemp(dir, "."+filepath.Base(p.dest)+".tmp")
+		if err != nil {
+			cleanup()
+			return err
+		}
+		tmpFiles = append(tmpFiles, f.Name())
+
+		_, err = f.Write(p.data)
+		if err == nil {
+			err = f.Chmod(p.mode)
+		}
+		if err == nil {
+			err = f.Sync()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("error writing %q: %w", p.dest, err)
+		}
+	}
+
+	for i, p := range parts {
+		if tmpFiles[i] == "" {
+			continue
+		}
+		if err := os.Rename(tmpFiles[i], p.dest); err != nil {
+			cleanup()
+			return fmt.Errorf("error moving %q into place: %w", p.dest, err)
+		}
+	}
+
+	return nil
+}
+
+// writePKIBundles splits the contents of a rendered template into the files
+// configured by its pki_bundle block, if it has one.
+func (ts *Server) writePKIBundles(event *manager.RenderEvent) {
+	if ts.config.AgentConfig == nil || len(ts.config.AgentConfig.TemplatePKIBundles) == 0 {
+		return
+	}
+
+	for _, tc := range event.TemplateConfigs {
+		if tc == nil || tc.Destination == nil {
+			continue
+		}
+		bundle, ok := ts.config.AgentConfig.TemplatePKIBundles[*tc.Destination]
+		if !ok {
+			continue
+		}
+
+		pems, err := splitPKIBundle(event.Contents)
+		if err == nil {
+			err = writePKIBundle(bundle, pems)
+		}
+		if err != nil {
+			ts.logger.Error("failed to write pki bundle", "destination", *tc.Destination, "error", err)
+			continue
+		}
+		ts.logger.Debug("wrote pki bundle", "destination", *tc.Destination)
+	}
+}
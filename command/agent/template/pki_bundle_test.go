@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package template
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/command/agent/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testPKIBundle(t *testing.T) (cert, key, ca []byte) {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, caKey.Public(), caKey)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "leaf"},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTmpl, caTmpl, leafKey.Public(), caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(leafKey)
+	require.NoError(t, err)
+
+	cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	key = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	ca = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	return cert, key, ca
+}
+
+func TestSplitPKIBundle(t *testing.T) {
+	cert, key, ca := testPKIBundle(t)
+
+	contents := append(append(append([]byte{}, cert...), key...), ca...)
+	pems, err := splitPKIBundle(contents)
+	require.NoError(t, err)
+	require.Equal(t, cert, pems.cert)
+	require.Equal(t, key, pems.key)
+	require.Equal(t, ca, pems.ca)
+
+	_, err = splitPKIBundle([]byte("not a pem"))
+	require.Error(t, err)
+}
+
+func TestWritePKIBundle(t *testing.T) {
+	cert, key, ca := testPKIBundle(t)
+	dir := t.TempDir()
+
+	bundle := &config.TemplatePKIBundle{
+		CertDestination: filepath.Join(dir, "cert.pem"),
+		KeyDestination:  filepath.Join(dir, "certs", "key.pem"),
+		CADestination:   filepath.Join(dir, "ca.pem"),
+	}
+	require.NoError(t, writePKIBundle(bundle, &pkiBundlePEMs{cert: cert, key: key, ca: ca}))
+
+	for dest, expected := range map[string][]byte{
+		bundle.CertDestination: cert,
+		bundle.KeyDestination:  key,
+		bundle.CADestination:   ca,
+	} {
+		actual, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	}
+
+	info, err := os.Stat(bundle.KeyDestination)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// A missing part must not leave any file half updated.
+	newCert, _, _ := testPKIBundle(t)
+	err = writePKIBundle(bundle, &pkiBundlePEMs{cert: newCert, ca: ca})
+	require.Error(t, err)
+	actual, err := os.ReadFile(bundle.CertDestination)
+	require.NoError(t, err)
+	require.Equal(t, cert, actual)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+}
@@ -13,7 +13,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
+	"github.com/armon/go-metrics"
 	"go.uber.org/atomic"
 
 	ctconfig "github.com/hashicorp/consul-template/config"
@@ -67,6 +69,10 @@ type Server struct {
 	DoneCh  chan struct{}
 	stopped *atomic.Bool
 
+	// reloadCh receives reloaded Agent configurations whose templates should
+	// replace the ones currently rendered by the runner.
+	reloadCh chan *config.Config
+
 	logger        hclog.Logger
 	exitAfterAuth bool
 }
@@ -77,6 +83,7 @@ func NewServer(conf *ServerConfig) *Server {
 		DoneCh:        make(chan struct{}),
 		stopped:       atomic.NewBool(false),
 		runnerStarted: atomic.NewBool(false),
+		reloadCh:      make(chan *config.Config, 1),
 
 		logger:        conf.Logger,
 		config:        conf,
@@ -128,22 +135,13 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 		return fmt.Errorf("template server failed to create: %w", err)
 	}
 
-	// Build the lookup map using the id mapping from the Template runner. This is
-	// used to check the template rendering against the expected templates. This
-	// returns a map with a generated ID and a slice of templates for that id. The
-	// slice is determined by the source or contents of the template, so if a
-	// configuration has multiple templates specified, but are the same source /
-	// contents, they will be identified by the same key.
-	idMap := ts.runner.TemplateConfigMapping()
-	lookupMap := make(map[string][]*ctconfig.TemplateConfig, len(idMap))
-	for id, ctmpls := range idMap {
-		for _, ctmpl := range ctmpls {
-			tl := lookupMap[id]
-			tl = append(tl, ctmpl)
-			lookupMap[id] = tl
-		}
-	}
-	ts.lookupMap = lookupMap
+	ts.lookupMap = buildLookupMap(ts.runner)
+
+	// lastRendered tracks the last render time seen for each template ID so
+	// that render metrics are only emitted once per render.
+	lastRendered := make(map[string]time.Time, len(ts.lookupMap))
+	var runnerStartedAt time.Time
+	firstRenderRecorded := false
 
 	for {
 		select {
@@ -180,6 +178,59 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 					continue
 				}
 				ts.runnerStarted.CAS(false, true)
+				runnerStartedAt = time.Now()
+				firstRenderRecorded = false
+				go ts.runner.Start()
+			}
+
+		case newConfig := <-ts.reloadCh:
+			if len(newConfig.Templates) == 0 {
+				ts.logger.Warn("reloaded configuration has no templates, keeping the current templates")
+				continue
+			}
+
+			// Only the template related parts of the configuration are taken
+			// from the reloaded config. Everything else, such as the in-process
+			// dialer to the cache, is kept from the running configuration.
+			agentConfig := *ts.config.AgentConfig
+			agentConfig.Templates = newConfig.Templates
+			agentConfig.TemplateConfig = newConfig.TemplateConfig
+			agentConfig.TemplatePKIBundles = newConfig.TemplatePKIBundles
+
+			managerConfig.AgentConfig = &agentConfig
+			newRunnerConfig, err := ctmanager.NewConfig(managerConfig, newConfig.Templates)
+			if err != nil {
+				ts.logger.Error("template server failed to generate runner config from reloaded configuration", "error", err)
+				continue
+			}
+			if *latestToken != "" {
+				newRunnerConfig = newRunnerConfig.Merge(&ctconfig.Config{
+					Vault: &ctconfig.VaultConfig{
+						Token:           latestToken,
+						ClientUserAgent: pointerutil.StringPtr(useragent.AgentTemplatingString()),
+					},
+				})
+			}
+
+			newRunner, err := manager.NewRunner(newRunnerConfig, false)
+			if err != nil {
+				ts.logger.Error("template server failed to create runner from reloaded configuration", "error", err)
+				continue
+			}
+
+			ts.logger.Info("template server reloading templates")
+			ts.runner.Stop()
+			ts.config.AgentConfig = &agentConfig
+			runnerConfig = newRunnerConfig
+			ts.runner = newRunner
+			ts.lookupMap = buildLookupMap(ts.runner)
+			lastRendered = make(map[string]time.Time, len(ts.lookupMap))
+
+			// The runner is only started once a token has been received,
+			// otherwise it will be started when the first token arrives.
+			if *latestToken != "" {
+				runnerStartedAt = time.Now()
+				firstRenderRecorded = false
 				go ts.runner.Start()
 			}
 
@@ -202,6 +253,13 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 		case <-ts.runner.TemplateRenderedCh():
 			// A template has been rendered, figure out what to do
 			events := ts.runner.RenderEvents()
+			rendered := recordRenderEvents(events, lastRendered)
+			if len(rendered) > 0 {
+				metrics.IncrCounter([]string{"agent", "template", "render"}, float32(len(rendered)))
+			}
+			for _, id := range rendered {
+				ts.writePKIBundles(events[id])
+			}
 
 			// events are keyed by template ID, and can be matched up to the id's from
 			// the lookupMap
@@ -219,6 +277,11 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 				}
 			}
 
+			if doneRendering && !firstRenderRecorded && !runnerStartedAt.IsZero() {
+				metrics.MeasureSince([]string{"agent", "template", "render", "duration"}, runnerStartedAt)
+				firstRenderRecorded = true
+			}
+
 			if doneRendering && ts.exitAfterAuth {
 				// if we want to exit after auth, go ahead and shut down the runner and
 				// return. The deferred closing of the DoneCh will allow agent to
@@ -230,6 +293,61 @@ func (ts *Server) Run(ctx context.Context, incoming chan string, templates []*ct
 	}
 }
 
+// Reload replaces the templates rendered by the server with the templates in
+// conf, keeping the current Vault token. The template_config stanza and
+// pki_bundle blocks are also taken from conf. If a previous reload has not been
+// picked up yet, it is superseded by this one.
+func (ts *Server) Reload(conf *config.Config) {
+	for {
+		select {
+		case ts.reloadCh <- conf:
+			return
+		default:
+		}
+		select {
+		case <-ts.reloadCh:
+		default:
+		}
+	}
+}
+
+// buildLookupMap builds the lookup map using the id mapping from the Template
+// runner. This is used to check the template rendering against the expected
+// templates. This returns a map with a generated ID and a slice of templates
+// for that id. The slice is determined by the source or contents of the
+// template, so if a configuration has multiple templates specified, but are
+// the same source / contents, they will be identified by the same key.
+func buildLookupMap(runner *manager.Runner) map[string][]*ctconfig.TemplateConfig {
+	idMap := runner.TemplateConfigMapping()
+	lookupMap := make(map[string][]*ctconfig.TemplateConfig, len(idMap))
+	for id, ctmpls := range idMap {
+		for _, ctmpl := range ctmpls {
+			tl := lookupMap[id]
+			tl = append(tl, ctmpl)
+			lookupMap[id] = tl
+		}
+	}
+	return lookupMap
+}
+
+// recordRenderEvents updates lastRendered with the render times found in events
+// and returns the IDs of the templates that have been rendered since the last
+// call.
+func recordRenderEvents(events map[string]*manager.RenderEvent, lastRendered map[string]time.Time) []string {
+	var rendered []string
+	for id, event := range events {
+		if event == nil || event.LastDidRender.IsZero() {
+			continue
+		}
+		if last, ok := lastRendered[id]; ok && !event.LastDidRender.After(last) {
+			continue
+		}
+		lastRendered[id] = event.LastDidRender
+		rendered = append(rendered, id)
+	}
+	return rendered
+}
+
 func (ts *Server) Stop() {
 	if ts.stopped.CAS(false, true) {
 		close(ts.DoneCh)
@@ -10,11 +10,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	ctconfig "github.com/hashicorp/consul-template/config"
+	"github.com/hashicorp/consul-template/manager"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/command/agent/config"
 	"github.com/hashicorp/vault/command/agent/internal/ctmanager"
@@ -447,6 +449,88 @@ func TestServerRun(t *testing.T) {
 	}
 }
 
+// TestServerReload tests that reloading the server renders the new set of
+// templates with the token it already has.
+func TestServerReload(t *testing.T) {
+	ts := createHttpTestServer()
+	defer ts.Close()
+
+	tmpDir := t.TempDir()
+	before := filepath.Join(tmpDir, "before")
+	after := filepath.Join(tmpDir, "after")
+
+	agentConfig := &config.Config{
+		Vault: &config.Vault{
+			Address: ts.URL,
+			Retry: &config.Retry{
+				NumRetries: 3,
+			},
+		},
+	}
+	server := NewServer(&ServerConfig{
+		Logger:      logging.NewVaultLogger(hclog.Trace),
+		AgentConfig: agentConfig,
+		LogLevel:    hclog.Trace,
+		LogWriter:   hclog.DefaultOutput,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	templateTokenCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Run(ctx, templateTokenCh, []*ctconfig.TemplateConfig{
+			{
+				Contents:    pointerutil.StringPtr(templateContents),
+				Destination: pointerutil.StringPtr(before),
+			},
+		})
+	}()
+	templateTokenCh <- "test"
+
+	waitForFile := func(path string) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}, 10*time.Second, 50*time.Millisecond, "%s was not rendered", path)
+	}
+	waitForFile(before)
+
+	server.Reload(&config.Config{
+		Templates: []*ctconfig.TemplateConfig{
+			{
+				Contents:    pointerutil.StringPtr(templateContents),
+				Destination: pointerutil.StringPtr(after),
+			},
+		},
+	})
+	waitForFile(after)
+
+	cancel()
+	require.NoError(t, <-errCh)
+}
+
+// TestRecordRenderEvents verifies that render events are only counted once
+// per render of a template.
+func TestRecordRenderEvents(t *testing.T) {
+	now := time.Now()
+	lastRendered := make(map[string]time.Time)
+	events := map[string]*manager.RenderEvent{
+		"a": {LastDidRender: now},
+		"b": {},
+	}
+
+	require.Equal(t, []string{"a"}, recordRenderEvents(events, lastRendered))
+	require.Empty(t, recordRenderEvents(events, lastRendered))
+
+	events["a"] = &manager.RenderEvent{LastDidRender: now.Add(time.Second)}
+	events["b"] = &manager.RenderEvent{LastDidRender: now}
+	require.ElementsMatch(t, []string{"a", "b"}, recordRenderEvents(events, lastRendered))
+	require.Empty(t, recordRenderEvents(events, lastRendered))
+}
+
 // TestNewServerLogLevels tests that the server can be started with any log
 // level.
 func TestNewServerLogLevels(t *testing.T) {
@@ -2756,6 +2756,127 @@ cache {}
 	wg.Wait()
 }
 
+// TestAgent_ReloadAPI tests that the reload API is only available on
+// listeners that enable it, and that it triggers a config reload.
+func TestAgent_ReloadAPI(t *testing.T) {
+	cluster := minimal.NewTestSoloCluster(t, nil)
+	serverClient := cluster.Cores[0].Client
+
+	// Unset the environment variable so that agent picks up the right test
+	// cluster address
+	defer os.Setenv(api.EnvVaultAddress, os.Getenv(api.EnvVaultAddress))
+	err := os.Unsetenv(api.EnvVaultAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listenAddr := generateListenerAddress(t)
+	listenAddr2 := generateListenerAddress(t)
+	config := fmt.Sprintf(`
+vault {
+  address = "%s"
+  tls_skip_verify = true
+}
+
+listener "tcp" {
+	address = "%s"
+	tls_disable = true
+}
+
+listener "tcp" {
+	address = "%s"
+	tls_disable = true
+	agent_api {
+		enable_reload = true
+	}
+}
+
+cache {}
+`, serverClient.Address(), listenAddr, listenAddr2)
+
+	configPath := makeTempFile(t, "config.hcl", config)
+	defer os.Remove(configPath)
+
+	// Start the agent
+	_, cmd := testAgentCommand(t, nil)
+	cmd.startedCh = make(chan struct{})
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		cmd.Run([]string{"-config", configPath})
+		wg.Done()
+	}()
+
+	select {
+	case <-cmd.startedCh:
+	case <-time.After(5 * time.Second):
+		t.Errorf("timeout")
+	}
+	client, err := api.NewClient(api.DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.SetToken(serverClient.Token())
+	client.SetMaxRetries(0)
+	err = client.SetAddress("http://" + listenAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First try on listener 1 where the API should be disabled.
+	resp, err := client.RawRequest(client.NewRequest(http.MethodPost, "/agent/v1/reload"))
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if resp != nil && resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected %d but got: %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	// Now try on listener 2 where the reload API should be enabled.
+	err = client.SetAddress("http://" + listenAddr2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = client.RawRequest(client.NewRequest(http.MethodPost, "/agent/v1/reload"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected %d but got: %d", http.StatusNoContent, resp.StatusCode)
+	}
+
+	select {
+	case <-cmd.reloadedCh:
+	case <-time.After(5 * time.Second):
+		t.Errorf("timeout")
+	}
+
+	// Changes to listeners require a restart, so reloading them is rejected.
+	listenAddr3 := generateListenerAddress(t)
+	if err := os.WriteFile(configPath, []byte(strings.Replace(config, listenAddr, listenAddr3, 1)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.RawRequest(client.NewRequest(http.MethodPost, "/agent/v1/reload"))
+	respErr, ok := err.(*api.ResponseError)
+	if !ok || respErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a %d response error, got: %v", http.StatusBadRequest, err)
+	}
+	if !strings.Contains(respErr.Error(), "listener") {
+		t.Fatalf("expected the error to name the listener stanza, got: %v", respErr)
+	}
+
+	select {
+	case <-cmd.reloadedCh:
+	case <-time.After(5 * time.Second):
+		t.Errorf("timeout")
+	}
+
+	close(cmd.ShutdownCh)
+	wg.Wait()
+}
+
 func TestAgent_LogFile_CliOverridesConfig(t *testing.T) {
 	// Create basic config
 	configFile := populateTempFile(t, "agent-config.hcl", BasicHclConfig)
@@ -495,6 +495,7 @@ func (ah *AuthHandler) Run(ctx context.Context, am AuthMethod) error {
 
 			case <-watcher.RenewCh():
 				metrics.IncrCounter([]string{ah.metricsSignifier, "auth", "success"}, 1)
+				metrics.IncrCounter([]string{ah.metricsSignifier, "auth", "renewal"}, 1)
 				ah.logger.Info("renewed auth token")
 
 			case <-credCh:
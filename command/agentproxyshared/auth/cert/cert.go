@@ -5,16 +5,32 @@ package cert
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/command/agentproxyshared/auth"
 	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/parseutil"
 )
 
+// certRenewRetryInterval is how long the renewer waits before trying again
+// after a failed attempt to re-issue the client certificate.
+const certRenewRetryInterval = 30 * time.Second
+
+// keyPairPendingSuffix is appended to the client certificate and key paths to
+// stage a re-issued key-pair before it is moved into place.
+const keyPairPendingSuffix = ".pending"
+
 type certMethod struct {
 	logger    hclog.Logger
 	mountPath string
@@ -25,6 +41,24 @@ type certMethod struct {
 	clientKey  string
 	reload     bool
 
+	// pkiIssuePath, when set, is the PKI issue endpoint (e.g.
+	// pki/issue/agent) used to re-issue the client certificate before it
+	// expires.
+	pkiIssuePath string
+	pkiTTL       string
+	renewBefore  time.Duration
+
+	credsFound      chan struct{}
+	stopCh          chan struct{}
+	doneCh          chan struct{}
+	credSuccessGate chan struct{}
+	once            *sync.Once
+
+	// l protects the fields below, which are shared with the renewer.
+	l         sync.Mutex
+	address   string
+	namespace string
+
 	// Client is the cached client to use if cert info was provided.
 	client *api.Client
 }
@@ -85,6 +119,54 @@ func NewCertAuthMethod(conf *auth.AuthConfig) (auth.AuthMethod, error) {
 				return nil, errors.New("could not convert 'reload' config value to bool")
 			}
 		}
+
+		pkiIssuePathRaw, ok := conf.Config["pki_issue_path"]
+		if ok {
+			c.pkiIssuePath, ok = pkiIssuePathRaw.(string)
+			if !ok {
+				return nil, errors.New("could not convert 'pki_issue_path' config value to string")
+			}
+		}
+
+		pkiTTLRaw, ok := conf.Config["pki_ttl"]
+		if ok {
+			c.pkiTTL, ok = pkiTTLRaw.(string)
+			if !ok {
+				return nil, errors.New("could not convert 'pki_ttl' config value to string")
+			}
+		}
+
+		if renewBeforeRaw, ok := conf.Config["renew_before"]; ok {
+			renewBefore, err := parseutil.ParseDurationSecond(renewBeforeRaw)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing 'renew_before' value: %w", err)
+			}
+			c.renewBefore = renewBefore
+		}
+	}
+
+	if c.pkiIssuePath != "" {
+		if c.clientCert == "" || c.clientKey == "" {
+			return nil, errors.New("'pki_issue_path' requires 'client_cert' and 'client_key' to be set")
+		}
+
+		c.credsFound = make(chan struct{})
+		c.stopCh = make(chan struct{})
+		c.doneCh = make(chan struct{})
+		c.credSuccessGate = make(chan struct{})
+		c.once = new(sync.Once)
+
+		recovered, err := recoverKeyPair(c.clientCert, c.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("error recovering interrupted client certificate renewal: %w", err)
+		}
+		if recovered {
+			c.logger.Warn("completed interrupted client certificate renewal", "client_cert", c.clientCert, "client_key", c.clientKey)
+		}
+
+		go c.runRenewer()
+
+		c.logger.Info("cert auth method created with certificate renewal", "pki_issue_path", c.pkiIssuePath)
 	}
 
 	return c, nil
@@ -103,12 +185,25 @@ func (c *certMethod) Authenticate(_ context.Context, client *api.Client) (string
 }
 
 func (c *certMethod) NewCreds() chan struct{} {
-	return nil
+	return c.credsFound
 }
 
-func (c *certMethod) CredSuccess() {}
+func (c *certMethod) CredSuccess() {
+	if c.once == nil {
+		return
+	}
+	c.once.Do(func() {
+		close(c.credSuccessGate)
+	})
+}
 
-func (c *certMethod) Shutdown() {}
+func (c *certMethod) Shutdown() {
+	if c.stopCh == nil {
+		return
+	}
+	close(c.stopCh)
+	<-c.doneCh
+}
 
 // AuthClient uses the existing client's address and returns a new client with
 // the auto-auth method's certificate information if that's provided in its
@@ -119,36 +214,22 @@ func (c *certMethod) AuthClient(client *api.Client) (*api.Client, error) {
 	clientToAuth := client
 
 	if c.caCert != "" || (c.clientKey != "" && c.clientCert != "") {
+		c.l.Lock()
+		defer c.l.Unlock()
+
 		// Return cached client if present
 		if c.client != nil && !c.reload {
 			return c.client, nil
 		}
 
-		config := api.DefaultConfig()
-		if config.Error != nil {
-			return nil, config.Error
-		}
-		config.Address = client.Address()
-
-		t := &api.TLSConfig{
-			CACert:     c.caCert,
-			ClientCert: c.clientCert,
-			ClientKey:  c.clientKey,
-		}
-
-		// Setup TLS config
-		if err := config.ConfigureTLS(t); err != nil {
-			return nil, err
-		}
+		c.address = client.Address()
+		c.namespace = client.Headers().Get(consts.NamespaceHeaderName)
 
 		var err error
-		clientToAuth, err = api.NewClient(config)
+		clientToAuth, err = c.newTLSClient(c.address, c.namespace)
 		if err != nil {
 			return nil, err
 		}
-		if ns := client.Headers().Get(consts.NamespaceHeaderName); ns != "" {
-			clientToAuth.SetNamespace(ns)
-		}
 
 		// Cache the client for future use
 		c.client = clientToAuth
@@ -156,3 +237,305 @@ func (c *certMethod) AuthClient(client *api.Client) (*api.Client, error) {
 
 	return clientToAuth, nil
 }
+
+// newTLSClient returns a client for the given address which presents the
+// key-pair currently on disk.
+func (c *certMethod) newTLSClient(address, namespace string) (*api.Client, error) {
+	config := api.DefaultConfig()
+	if config.Error != nil {
+		return nil, config.Error
+	}
+	config.Address = address
+
+	t := &api.TLSConfig{
+		CACert:     c.caCert,
+		ClientCert: c.clientCert,
+		ClientKey:  c.clientKey,
+	}
+
+	// Setup TLS config
+	if err := config.ConfigureTLS(t); err != nil {
+		return nil, err
+	}
+
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	if namespace != "" {
+		client.SetNamespace(namespace)
+	}
+
+	return client, nil
+}
+
+func (c *certMethod) runRenewer() {
+	defer close(c.doneCh)
+
+	select {
+	case <-c.stopCh:
+		return
+
+	case <-c.credSuccessGate:
+		// We only start watching the certificate once we're initially
+		// successful, as we need the server address from the auth handler
+		// and a certificate that the cert auth mount accepts.
+	}
+
+	var delay time.Duration
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-c.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		leaf, err := readLeafCertificate(c.clientCert)
+		if err != nil {
+			c.logger.Error("error reading client certificate", "error", err)
+			delay = certRenewRetryInterval
+			continue
+		}
+
+		if delay = time.Until(c.renewalTime(leaf)); delay > 0 {
+			c.logger.Debug("scheduled client certificate renewal", "renew_at", time.Now().Add(delay).Format(time.RFC3339))
+			continue
+		}
+
+		c.logger.Info("client certificate is approaching expiry, re-issuing", "expiration", leaf.NotAfter.Format(time.RFC3339))
+		if err := c.rotateCertificate(leaf); err != nil {
+			c.logger.Error("error re-issuing client certificate", "error", err, "retry", certRenewRetryInterval)
+			delay = certRenewRetryInterval
+			continue
+		}
+		c.logger.Info("client certificate re-issued")
+
+		select {
+		case <-c.stopCh:
+			return
+		case c.credsFound <- struct{}{}:
+		}
+	}
+}
+
+// renewalTime returns the time at which the given certificate should be
+// re-issued. Unless renew_before is configured, this is once two thirds of the
+// certificate's validity period has elapsed.
+func (c *certMethod) renewalTime(leaf *x509.Certificate) time.Time {
+	renewBefore := c.renewBefore
+	if renewBefore <= 0 {
+		renewBefore = leaf.NotAfter.Sub(leaf.NotBefore) / 3
+	}
+	return leaf.NotAfter.Add(-renewBefore)
+}
+
+// rotateCertificate logs in with the current key-pair, requests a new
+// certificate with the same names from the configured PKI role, and replaces
+// the on-disk key-pair with the result.
+func (c *certMethod) rotateCertificate(leaf *x509.Certificate) error {
+	c.l.Lock()
+	address, namespace := c.address, c.namespace
+	c.l.Unlock()
+
+	client, err := c.newTLSClient(address, namespace)
+	if err != nil {
+		return err
+	}
+	client.SetMaxRetries(0)
+
+	loginData := map[string]interface{}{}
+	if c.name != "" {
+		loginData["name"] = c.name
+	}
+	secret, err := client.Logical().Write(fmt.Sprintf("%s/login", c.mountPath), loginData)
+	if err != nil {
+		return fmt.Errorf("error logging in with current certificate: %w", err)
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return errors.New("login with current certificate returned no token")
+	}
+	client.SetToken(secret.Auth.ClientToken)
+	defer func() {
+		if err := client.Auth().Token().RevokeSelf(""); err != nil {
+			c.logger.Warn("error revoking token used for certificate renewal", "error", err)
+		}
+	}()
+
+	issueData := map[string]interface{}{
+		"common_name": leaf.Subject.CommonName,
+		"format":      "pem",
+	}
+	altNames := append([]string{}, leaf.DNSNames...)
+	altNames = append(altNames, leaf.EmailAddresses...)
+	if len(altNames) > 0 {
+		issueData["alt_names"] = strings.Join(altNames, ",")
+	}
+	if len(leaf.IPAddresses) > 0 {
+		ipSANs := make([]string, 0, len(leaf.IPAddresses))
+		for _, ip := range leaf.IPAddresses {
+			ipSANs = append(ipSANs, ip.String())
+		}
+		issueData["ip_sans"] = strings.Join(ipSANs, ",")
+	}
+	if len(leaf.URIs) > 0 {
+		uriSANs := make([]string, 0, len(leaf.URIs))
+		for _, uri := range leaf.URIs {
+			uriSANs = append(uriSANs, uri.String())
+		}
+		issueData["uri_sans"] = strings.Join(uriSANs, ",")
+	}
+	if c.pkiTTL != "" {
+		issueData["ttl"] = c.pkiTTL
+	}
+
+	resp, err := client.Logical().Write(c.pkiIssuePath, issueData)
+	if err != nil {
+		return fmt.Errorf("error issuing certificate: %w", err)
+	}
+	if resp == nil || resp.Data == nil {
+		return errors.New("issue request returned no data")
+	}
+
+	certPEM, _ := resp.Data["certificate"].(string)
+	keyPEM, _ := resp.Data["private_key"].(string)
+	if certPEM == "" || keyPEM == "" {
+		return errors.New("issue response is missing the certificate or private key")
+	}
+
+	// Append the intermediates so the cert auth mount can verify the chain
+	// if it only trusts the root.
+	if chain, ok := resp.Data["ca_chain"].([]interface{}); ok {
+		for _, caRaw := range chain {
+			if ca, ok := caRaw.(string); ok && ca != "" {
+				certPEM = strings.TrimSpace(certPEM) + "\n" + strings.TrimSpace(ca)
+			}
+		}
+	}
+
+	// Hold the lock while the files are replaced so that AuthClient never
+	// loads a key and certificate from different pairs.
+	c.l.Lock()
+	defer c.l.Unlock()
+
+	if err := writeKeyPair(c.clientCert, c.clientKey, []byte(strings.TrimSpace(certPEM)+"\n"), []byte(strings.TrimSpace(keyPEM)+"\n")); err != nil {
+		return fmt.Errorf("error writing client key-pair: %w", err)
+	}
+
+	// Force the next authentication to pick up the new key-pair.
+	c.client = nil
+
+	return nil
+}
+
+// writeKeyPair replaces the client certificate and key. The two files cannot
+// be renamed into place together, so both are first staged next to their
+// targets, the certificate last. Its staged copy therefore marks a complete
+// pair, which recoverKeyPair moves into place should the process stop before
+// both renames are done.
+func writeKeyPair(certPath, keyPath string, certPEM, keyPEM []byte) error {
+	for _, path := range []string{certPath, keyPath} {
+		if err := os.Remove(path + keyPairPendingSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if err := writeFileAtomic(keyPath+keyPairPendingSuffix, keyPEM, fileMode(keyPath, 0o600)); err != nil {
+		return fmt.Errorf("error staging client key: %w", err)
+	}
+	if err := writeFileAtomic(certPath+keyPairPendingSuffix, certPEM, fileMode(certPath, 0o644)); err != nil {
+		return fmt.Errorf("error staging client certificate: %w", err)
+	}
+
+	return commitKeyPair(certPath, keyPath)
+}
+
+// commitKeyPair moves a staged key-pair into place. The key is moved first, so
+// that the staged certificate remains until the replacement is complete.
+func commitKeyPair(certPath, keyPath string) error {
+	if err := os.Rename(keyPath+keyPairPendingSuffix, keyPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.Rename(certPath+keyPairPendingSuffix, certPath)
+}
+
+// recoverKeyPair finishes a key-pair replacement by writeKeyPair that was
+// interrupted, returning whether one was found. A staged key without a staged
+// certificate is left over from incomplete staging and is discarded.
+func recoverKeyPair(certPath, keyPath string) (bool, error) {
+	if _, err := os.Stat(certPath + keyPairPendingSuffix); err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+		if err := os.Remove(keyPath + keyPairPendingSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if err := commitKeyPair(certPath, keyPath); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// readLeafCertificate parses the first certificate in the PEM file at path.
+func readLeafCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found in %q", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+}
+
+// writeFileAtomic replaces the file at path with data by writing a temporary
+// file in the same directory and renaming it into place. The mode of an
+// existing file is preserved; defaultMode is used otherwise.
+func writeFileAtomic(path string, data []byte, defaultMode os.FileMode) error {
+	mode := fileMode(path, defaultMode)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, path)
+}
+
+// fileMode returns the permissions of the file at path, or defaultMode if it
+// does not exist.
+func fileMode(path string, defaultMode os.FileMode) os.FileMode {
+	if fi, err := os.Stat(path); err == nil {
+		return fi.Mode().Perm()
+	}
+	return defaultMode
+}
@@ -5,10 +5,13 @@ package cert
 
 import (
 	"context"
+	"crypto/x509"
 	"os"
 	"path"
+	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
@@ -189,3 +192,190 @@ func TestCertAuthMethod_AuthClient_withCertsReload(t *testing.T) {
 		t.Fatal("expected client from AuthClient to return back a new client")
 	}
 }
+
+func TestCertAuthMethod_RenewalRequiresKeyPair(t *testing.T) {
+	config := &auth.AuthConfig{
+		Logger:    hclog.NewNullLogger(),
+		MountPath: "cert-test",
+		Config: map[string]interface{}{
+			"name":           "renewal-without-certs",
+			"pki_issue_path": "pki/issue/agent",
+		},
+	}
+
+	if _, err := NewCertAuthMethod(config); err == nil {
+		t.Fatal("expected error when pki_issue_path is set without client_cert and client_key")
+	}
+}
+
+func TestCertAuthMethod_RenewalTime(t *testing.T) {
+	notBefore := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	leaf := &x509.Certificate{
+		NotBefore: notBefore,
+		NotAfter:  notBefore.Add(3 * time.Hour),
+	}
+
+	c := &certMethod{}
+	if got, expected := c.renewalTime(leaf), notBefore.Add(2*time.Hour); !got.Equal(expected) {
+		t.Fatalf("unexpected default renewal time: got %v, expected %v", got, expected)
+	}
+
+	c.renewBefore = 10 * time.Minute
+	if got, expected := c.renewalTime(leaf), leaf.NotAfter.Add(-10*time.Minute); !got.Equal(expected) {
+		t.Fatalf("unexpected renewal time: got %v, expected %v", got, expected)
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, []byte("old"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Fatalf("unexpected file contents: %q", data)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o640 {
+		t.Fatalf("expected existing file mode to be preserved, got %v", fi.Mode().Perm())
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected temporary file to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestWriteKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certPath, []byte("old cert"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyPath, []byte("old key"), 0o400); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeKeyPair(certPath, keyPath, []byte("new cert"), []byte("new key")); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]string{certPath: "new cert", keyPath: "new key"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != expected {
+			t.Fatalf("unexpected contents of %q: %q", path, data)
+		}
+	}
+	for path, expected := range map[string]os.FileMode{certPath: 0o640, keyPath: 0o400} {
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fi.Mode().Perm() != expected {
+			t.Fatalf("expected mode of %q to be preserved, got %v", path, fi.Mode().Perm())
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected staged files to be cleaned up, found %d entries", len(entries))
+	}
+}
+
+func TestRecoverKeyPair(t *testing.T) {
+	tests := map[string]struct {
+		stagedCert, stagedKey     bool
+		movedKey                  bool
+		expectedCert, expectedKey string
+		expectedRecovered         bool
+	}{
+		"nothing staged": {
+			expectedCert: "old cert",
+			expectedKey:  "old key",
+		},
+		"interrupted before any rename": {
+			stagedCert:        true,
+			stagedKey:         true,
+			expectedCert:      "new cert",
+			expectedKey:       "new key",
+			expectedRecovered: true,
+		},
+		"interrupted after the key rename": {
+			stagedCert:        true,
+			movedKey:          true,
+			expectedCert:      "new cert",
+			expectedKey:       "new key",
+			expectedRecovered: true,
+		},
+		"interrupted while staging": {
+			stagedKey:    true,
+			expectedCert: "old cert",
+			expectedKey:  "old key",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			certPath, keyPath := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+			files := map[string]string{certPath: "old cert", keyPath: "old key"}
+			if tc.stagedCert {
+				files[certPath+keyPairPendingSuffix] = "new cert"
+			}
+			if tc.stagedKey {
+				files[keyPath+keyPairPendingSuffix] = "new key"
+			}
+			if tc.movedKey {
+				files[keyPath] = "new key"
+			}
+			for path, data := range files {
+				if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			recovered, err := recoverKeyPair(certPath, keyPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recovered != tc.expectedRecovered {
+				t.Fatalf("expected recovered to be %t", tc.expectedRecovered)
+			}
+
+			for path, expected := range map[string]string{certPath: tc.expectedCert, keyPath: tc.expectedKey} {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != expected {
+					t.Fatalf("unexpected contents of %q: %q", path, data)
+				}
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 2 {
+				t.Fatalf("expected staged files to be removed, found %d entries", len(entries))
+			}
+		})
+	}
+}
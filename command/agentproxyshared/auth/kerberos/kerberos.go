@@ -5,15 +5,20 @@ package kerberos
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-secure-stdlib/parseutil"
 	kerberos "github.com/hashicorp/vault-plugin-auth-kerberos"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/command/agentproxyshared/auth"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
 	"github.com/jcmturner/gokrb5/v8/spnego"
 )
 
@@ -21,6 +26,11 @@ type kerberosMethod struct {
 	logger    hclog.Logger
 	mountPath string
 	loginCfg  *kerberos.LoginCfg
+
+	// ccachePath is set instead of loginCfg.KeytabPath when authenticating
+	// with the tickets of an existing credential cache, e.g. the one
+	// maintained by the host's SSSD or k5start.
+	ccachePath string
 }
 
 func NewKerberosAuthMethod(conf *auth.AuthConfig) (auth.AuthMethod, error) {
@@ -30,27 +40,46 @@ func NewKerberosAuthMethod(conf *auth.AuthConfig) (auth.AuthMethod, error) {
 	if conf.Config == nil {
 		return nil, errors.New("empty config data")
 	}
-	username, err := read("username", conf.Config)
-	if err != nil {
-		return nil, err
-	}
 	service, err := read("service", conf.Config)
 	if err != nil {
 		return nil, err
 	}
-	realm, err := read("realm", conf.Config)
-	if err != nil {
-		return nil, err
-	}
-	keytabPath, err := read("keytab_path", conf.Config)
-	if err != nil {
-		return nil, err
-	}
 	krb5ConfPath, err := read("krb5conf_path", conf.Config)
 	if err != nil {
 		return nil, err
 	}
 
+	_, hasKeytab := conf.Config["keytab_path"]
+	_, hasCCache := conf.Config["ccache_path"]
+	var username, realm, keytabPath, ccachePath string
+	switch {
+	case hasKeytab && hasCCache:
+		return nil, errors.New("only one of \"keytab_path\" and \"ccache_path\" may be set")
+	case hasCCache:
+		// The client principal and realm are read from the credential cache.
+		ccachePath, err = read("ccache_path", conf.Config)
+		if err != nil {
+			return nil, err
+		}
+		ccachePath = strings.TrimPrefix(ccachePath, "FILE:")
+		if ccachePath == "" {
+			return nil, errors.New("\"ccache_path\" must not be empty")
+		}
+	default:
+		username, err = read("username", conf.Config)
+		if err != nil {
+			return nil, err
+		}
+		realm, err = read("realm", conf.Config)
+		if err != nil {
+			return nil, err
+		}
+		keytabPath, err = read("keytab_path", conf.Config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	disableFast := false
 	disableFastRaw, ok := conf.Config["disable_fast_negotiation"]
 	if ok {
@@ -61,8 +90,9 @@ func NewKerberosAuthMethod(conf *auth.AuthConfig) (auth.AuthMethod, error) {
 	}
 
 	return &kerberosMethod{
-		logger:    conf.Logger,
-		mountPath: conf.MountPath,
+		logger:     conf.Logger,
+		mountPath:  conf.MountPath,
+		ccachePath: ccachePath,
 		loginCfg: &kerberos.LoginCfg{
 			Username:               username,
 			Service:                service,
@@ -76,7 +106,13 @@ func NewKerberosAuthMethod(conf *auth.AuthConfig) (auth.AuthMethod, error) {
 
 func (k *kerberosMethod) Authenticate(context.Context, *api.Client) (string, http.Header, map[string]interface{}, error) {
 	k.logger.Trace("beginning authentication")
-	authHeaderVal, err := kerberos.GetAuthHeaderVal(k.loginCfg)
+	var authHeaderVal string
+	var err error
+	if k.ccachePath != "" {
+		authHeaderVal, err = k.ccacheAuthHeaderVal()
+	} else {
+		authHeaderVal, err = kerberos.GetAuthHeaderVal(k.loginCfg)
+	}
 	if err != nil {
 		return "", nil, nil, err
 	}
@@ -86,6 +122,48 @@ func (k *kerberosMethod) Authenticate(context.Context, *api.Client) (string, htt
 	return k.mountPath + "/login", header, make(map[string]interface{}), nil
 }
 
+// ccacheAuthHeaderVal builds the SPNEGO authorization header from the tickets
+// in the configured credential cache. The cache is re-read on every call so
+// that tickets renewed by an external process are picked up.
+func (k *kerberosMethod) ccacheAuthHeaderVal() (string, error) {
+	ccache, err := credentials.LoadCCache(k.ccachePath)
+	if err != nil {
+		return "", fmt.Errorf("couldn't load credential cache: %w", err)
+	}
+
+	krb5Conf, err := config.Load(k.loginCfg.Krb5ConfPath)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse krb5Conf: %w", err)
+	}
+
+	var settings []func(*client.Settings)
+	if k.loginCfg.DisableFASTNegotiation {
+		settings = append(settings, client.DisablePAFXFAST(true))
+	}
+
+	cl, err := client.NewFromCCache(ccache, krb5Conf, settings...)
+	if err != nil {
+		return "", fmt.Errorf("couldn't create client from credential cache: %w", err)
+	}
+	defer cl.Destroy()
+
+	spnegoClient := spnego.SPNEGOClient(cl, k.loginCfg.Service)
+	if err := spnegoClient.AcquireCred(); err != nil {
+		return "", fmt.Errorf("couldn't acquire client credential: %w", err)
+	}
+
+	spnegoToken, err := spnegoClient.InitSecContext()
+	if err != nil {
+		return "", fmt.Errorf("couldn't initialize context: %w", err)
+	}
+
+	marshalledToken, err := spnegoToken.Marshal()
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal SPNEGO: %w", err)
+	}
+	return "Negotiate " + base64.StdEncoding.EncodeToString(marshalledToken), nil
+}
+
 // These functions are implemented to meet the AuthHandler interface,
 // but we don't need to take advantage of them.
 func (k *kerberosMethod) NewCreds() chan struct{} { return nil }
@@ -4,6 +4,7 @@
 package kerberos
 
 import (
+	"context"
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
@@ -71,6 +72,43 @@ func TestNewKerberosAuthMethod(t *testing.T) {
 	}
 }
 
+func TestNewKerberosAuthMethod_CCache(t *testing.T) {
+	authConfig := simpleAuthConfig()
+	authConfig.Config["ccache_path"] = "/tmp/krb5cc_1000"
+	if _, err := NewKerberosAuthMethod(authConfig); err == nil {
+		t.Fatal("err should be returned when both keytab_path and ccache_path are set")
+	}
+
+	// The principal and realm come from the credential cache, so they are
+	// not required.
+	authConfig = simpleAuthConfig()
+	delete(authConfig.Config, "keytab_path")
+	delete(authConfig.Config, "username")
+	delete(authConfig.Config, "realm")
+	authConfig.Config["ccache_path"] = "FILE:/tmp/krb5cc_1000"
+	authMethod, err := NewKerberosAuthMethod(authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := authMethod.(*kerberosMethod).ccachePath; actual != "/tmp/krb5cc_1000" {
+		t.Fatalf("unexpected ccache path: %q", actual)
+	}
+
+	authConfig.Config["ccache_path"] = "FILE:"
+	if _, err := NewKerberosAuthMethod(authConfig); err == nil {
+		t.Fatal("err should be returned for empty ccache_path")
+	}
+
+	authConfig.Config["ccache_path"] = "/nonexistent/krb5cc"
+	authMethod, err = NewKerberosAuthMethod(authConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := authMethod.Authenticate(context.Background(), nil); err == nil {
+		t.Fatal("err should be returned for a missing credential cache")
+	}
+}
+
 func simpleAuthConfig() *auth.AuthConfig {
 	return &auth.AuthConfig{
 		Logger:    hclog.NewNullLogger(),
@@ -55,7 +55,9 @@ func ProxyHandler(ctx context.Context, logger hclog.Logger, proxier Proxier, inm
 			RequestBody: reqBody,
 		}
 
+		start := time.Now()
 		resp, err := proxier.Send(ctx, req)
+		metrics.MeasureSince([]string{"agent", "proxy", "request"}, start)
 		if err != nil {
 			// If this is an api.Response error, don't wrap the response.
 			if resp != nil && resp.Response.Error() != nil {
@@ -79,13 +81,6 @@ func ProxyHandler(ctx context.Context, logger hclog.Logger, proxier Proxier, inm
 		defer resp.Response.Body.Close()
 
 		metrics.IncrCounter([]string{"agent", "proxy", "success"}, 1)
-		if resp.CacheMeta != nil {
-			if resp.CacheMeta.Hit {
-				metrics.IncrCounter([]string{"agent", "cache", "hit"}, 1)
-			} else {
-				metrics.IncrCounter([]string{"agent", "cache", "miss"}, 1)
-			}
-		}
 
 		// Set headers
 		setHeaders(w, resp)
@@ -0,0 +1,100 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package keymanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+
+	wrapping "github.com/hashicorp/go-kms-wrapping/v2"
+	"github.com/hashicorp/go-kms-wrapping/wrappers/aead/v2"
+	"golang.org/x/crypto/hkdf"
+	"google.golang.org/protobuf/proto"
+)
+
+const derivedKeyInfo = "vault-persistent-cache-key-protection"
+
+var _ KeyManager = (*ProtectedKeyManager)(nil)
+
+// ProtectedKeyManager is a KeyManager whose retrieval token is the encryption
+// key encrypted with a key-encryption wrapper, so that the persisted cache can
+// only be decrypted by a process with access to that wrapper.
+type ProtectedKeyManager struct {
+	*PassthroughKeyManager
+	kek wrapping.Wrapper
+}
+
+// NewProtectedKeyManager returns a ProtectedKeyManager using kek to protect
+// the encryption key. If retrievalToken is empty, a new encryption key is
+// generated; otherwise the key is recovered by decrypting retrievalToken.
+func NewProtectedKeyManager(ctx context.Context, kek wrapping.Wrapper, retrievalToken []byte) (*ProtectedKeyManager, error) {
+	if kek == nil {
+		return nil, errors.New("key-encryption wrapper is nil")
+	}
+
+	var key []byte
+	if len(retrievalToken) > 0 {
+		blob := new(wrapping.BlobInfo)
+		if err := proto.Unmarshal(retrievalToken, blob); err != nil {
+			return nil, fmt.Errorf("failed to decode protected retrieval token: %w", err)
+		}
+		var err error
+		key, err = kek.Decrypt(ctx, blob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt protected retrieval token: %w", err)
+		}
+	}
+
+	passthrough, err := NewPassthroughKeyManager(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProtectedKeyManager{
+		PassthroughKeyManager: passthrough,
+		kek:                   kek,
+	}, nil
+}
+
+// RetrievalToken returns the encryption key encrypted with the key-encryption
+// wrapper.
+func (w *ProtectedKeyManager) RetrievalToken(ctx context.Context) ([]byte, error) {
+	key, err := w.PassthroughKeyManager.RetrievalToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := w.kek.Encrypt(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt retrieval token: %w", err)
+	}
+
+	return proto.Marshal(blob)
+}
+
+// NewDerivedKeyWrapper returns an AEAD wrapper keyed with a key derived from
+// the given secret material via HKDF-SHA256.
+func NewDerivedKeyWrapper(ctx context.Context, secret []byte) (wrapping.Wrapper, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cannot derive key from empty secret")
+	}
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(derivedKeyInfo)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	wrapper := aead.NewWrapper()
+	if _, err := wrapper.SetConfig(ctx, wrapping.WithConfigMap(map[string]string{"key_id": KeyID + "-kek"})); err != nil {
+		return nil, err
+	}
+	if err := wrapper.SetAesGcmKeyBytes(key); err != nil {
+		return nil, err
+	}
+
+	return wrapper, nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package keymanager
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyManager_ProtectedKeyManager(t *testing.T) {
+	ctx := context.Background()
+
+	kek, err := NewDerivedKeyWrapper(ctx, []byte("service-account-token"))
+	require.NoError(t, err)
+
+	m, err := NewProtectedKeyManager(ctx, kek, nil)
+	require.NoError(t, err)
+
+	token, err := m.RetrievalToken(ctx)
+	require.NoError(t, err)
+
+	key, err := m.Wrapper().(interface {
+		KeyBytes(context.Context) ([]byte, error)
+	}).KeyBytes(ctx)
+	require.NoError(t, err)
+	if bytes.Contains(token, key) {
+		t.Fatal("expected retrieval token not to contain the plaintext key")
+	}
+
+	// The same secret recovers the key
+	kek, err = NewDerivedKeyWrapper(ctx, []byte("service-account-token"))
+	require.NoError(t, err)
+	restored, err := NewProtectedKeyManager(ctx, kek, token)
+	require.NoError(t, err)
+	restoredKey, err := restored.PassthroughKeyManager.RetrievalToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, key, restoredKey)
+
+	// A different secret does not
+	otherKEK, err := NewDerivedKeyWrapper(ctx, []byte("another-token"))
+	require.NoError(t, err)
+	_, err = NewProtectedKeyManager(ctx, otherKEK, token)
+	require.Error(t, err)
+
+	_, err = NewDerivedKeyWrapper(ctx, nil)
+	require.Error(t, err)
+}
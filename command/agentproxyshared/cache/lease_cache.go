@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-secure-stdlib/base62"
@@ -280,9 +281,11 @@ func (c *LeaseCache) Send(ctx context.Context, req *SendRequest) (*SendResponse,
 		return nil, err
 	}
 	if cachedResp != nil {
+		metrics.IncrCounter([]string{"agent", "cache", "hit"}, 1)
 		c.logger.Debug("returning cached response", "path", req.Request.URL.Path)
 		return cachedResp, nil
 	}
+	metrics.IncrCounter([]string{"agent", "cache", "miss"}, 1)
 
 	c.logger.Debug("forwarding request from cache", "method", req.Request.Method, "path", req.Request.URL.Path)
 
@@ -1090,6 +1093,11 @@ func (c *LeaseCache) restoreLeaseRenewCtx(index *cachememdb.Index) error {
 		return err
 	}
 
+	// The lifetime watcher schedules renewals from the lease duration, which
+	// for a restored entry must not include the time that has elapsed since it
+	// was last renewed, e.g. while no agent was running.
+	remainingLeaseDuration(secret, index.LastRenewed, time.Now().UTC())
+
 	var renewCtxInfo *cachememdb.ContextInfo
 	switch {
 	case secret.LeaseID != "":
@@ -1148,6 +1156,30 @@ func (c *LeaseCache) restoreLeaseRenewCtx(index *cachememdb.Index) error {
 	return nil
 }
 
+// remainingLeaseDuration reduces the lease duration of the secret by the time
+// elapsed since lastRenewed, with a floor of one second so the lifetime
+// watcher attempts a renewal right away.
+func remainingLeaseDuration(secret *api.Secret, lastRenewed, now time.Time) {
+	elapsed := int(now.Sub(lastRenewed).Seconds())
+	if elapsed <= 0 {
+		return
+	}
+
+	remaining := func(duration int) int {
+		if duration -= elapsed; duration < 1 {
+			return 1
+		}
+		return duration
+	}
+
+	switch {
+	case secret.LeaseID != "":
+		secret.LeaseDuration = remaining(secret.LeaseDuration)
+	case secret.Auth != nil:
+		secret.Auth.LeaseDuration = remaining(secret.Auth.LeaseDuration)
+	}
+}
+
 // deriveNamespaceAndRevocationPath returns the namespace and relative path for
 // revocation paths.
 //
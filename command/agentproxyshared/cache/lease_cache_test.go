@@ -17,6 +17,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/armon/go-metrics"
 	"github.com/go-test/deep"
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
@@ -248,6 +249,46 @@ func TestLeaseCache_SendCacheable(t *testing.T) {
 	}
 }
 
+// TestLeaseCache_SendCacheMetrics tests that cache lookups are counted as hits
+// and misses. This test is not parallelizable.
+func TestLeaseCache_SendCacheMetrics(t *testing.T) {
+	inmemSink := metrics.NewInmemSink(1000000*time.Hour, 2000000*time.Hour)
+	metricsConf := metrics.DefaultConfig("")
+	metricsConf.EnableHostname = false
+	metricsConf.EnableHostnameLabel = false
+	metricsConf.EnableServiceLabel = false
+	metricsConf.EnableTypePrefix = false
+	if _, err := metrics.NewGlobal(metricsConf, inmemSink); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := []*SendResponse{
+		newTestSendResponse(http.StatusOK, `{"lease_id": "foo", "renewable": true, "data": {"value": "foo"}}`),
+	}
+	lc := testNewLeaseCache(t, responses)
+	require.NoError(t, lc.RegisterAutoAuthToken("autoauthtoken"))
+
+	// The first request misses the cache and the next two hit it.
+	for i := 0; i < 3; i++ {
+		sendReq := &SendRequest{
+			Token:   "autoauthtoken",
+			Request: httptest.NewRequest("GET", "http://example.com/v1/sample/api", strings.NewReader(`{"value": "input"}`)),
+		}
+		if _, err := lc.Send(context.Background(), sendReq); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	intervals := inmemSink.Data()
+	counters := intervals[len(intervals)-1].Counters
+	if hits := counters["agent.cache.hit"].Count; hits != 2 {
+		t.Fatalf("expected 2 cache hits, got %d", hits)
+	}
+	if misses := counters["agent.cache.miss"].Count; misses != 1 {
+		t.Fatalf("expected 1 cache miss, got %d", misses)
+	}
+}
+
 func TestLeaseCache_SendNonCacheable(t *testing.T) {
 	responses := []*SendResponse{
 		newTestSendResponse(http.StatusOK, `{"value": "output"}`),
@@ -1136,6 +1177,28 @@ func Test_hasExpired(t *testing.T) {
 	}
 }
 
+func Test_remainingLeaseDuration(t *testing.T) {
+	now := time.Now().UTC()
+
+	secret := &api.Secret{LeaseID: "foo", LeaseDuration: 60}
+	remainingLeaseDuration(secret, now.Add(-20*time.Second), now)
+	assert.Equal(t, 40, secret.LeaseDuration)
+
+	secret = &api.Secret{Auth: &api.SecretAuth{ClientToken: "testtoken", LeaseDuration: 60}}
+	remainingLeaseDuration(secret, now.Add(-20*time.Second), now)
+	assert.Equal(t, 40, secret.Auth.LeaseDuration)
+
+	// Overdue renewals are attempted right away
+	secret = &api.Secret{LeaseID: "foo", LeaseDuration: 60}
+	remainingLeaseDuration(secret, now.Add(-90*time.Second), now)
+	assert.Equal(t, 1, secret.LeaseDuration)
+
+	// A last renewal in the future is left alone
+	secret = &api.Secret{LeaseID: "foo", LeaseDuration: 60}
+	remainingLeaseDuration(secret, now.Add(10*time.Second), now)
+	assert.Equal(t, 60, secret.LeaseDuration)
+}
+
 func TestLeaseCache_hasExpired_wrong_type(t *testing.T) {
 	index := &cachememdb.Index{
 		Type: cacheboltdb.TokenType,
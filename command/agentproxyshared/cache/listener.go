@@ -46,17 +46,7 @@ func StartListener(lnConfig *configutil.Listener) (*ListenerBundle, error) {
 		ln = &server.TCPKeepAliveListener{ln.(*net.TCPListener)}
 
 	case "unix":
-		var uConfig *listenerutil.UnixSocketsConfig
-		if lnConfig.SocketMode != "" &&
-			lnConfig.SocketUser != "" &&
-			lnConfig.SocketGroup != "" {
-			uConfig = &listenerutil.UnixSocketsConfig{
-				Mode:  lnConfig.SocketMode,
-				User:  lnConfig.SocketUser,
-				Group: lnConfig.SocketGroup,
-			}
-		}
-		ln, err = listenerutil.UnixSocketListener(addr, uConfig)
+		ln, err = listenerutil.UnixSocketListener(addr, listenerutil.UnixSocketsConfigFromListener(lnConfig))
 		if err != nil {
 			return nil, err
 		}
@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	log "github.com/hashicorp/go-hclog"
+	wrapping "github.com/hashicorp/go-kms-wrapping/v2"
 	"github.com/hashicorp/vault/command/agentproxyshared/auth"
 	"github.com/hashicorp/vault/command/agentproxyshared/auth/alicloud"
 	"github.com/hashicorp/vault/command/agentproxyshared/auth/approle"
@@ -30,6 +31,7 @@ import (
 	"github.com/hashicorp/vault/command/agentproxyshared/cache/cacheboltdb"
 	"github.com/hashicorp/vault/command/agentproxyshared/cache/cachememdb"
 	"github.com/hashicorp/vault/command/agentproxyshared/cache/keymanager"
+	"github.com/hashicorp/vault/internalshared/configutil"
 )
 
 // GetAutoAuthMethodFromConfig Calls the appropriate NewAutoAuthMethod function, initializing
@@ -77,6 +79,15 @@ type PersistConfig struct {
 	KeepAfterImport         bool   `hcl:"keep_after_import"`
 	ExitOnErr               bool   `hcl:"exit_on_err"`
 	ServiceAccountTokenFile string `hcl:"service_account_token_file"`
+
+	// EncryptionKeyProtection controls how the key encrypting the persistent
+	// cache is stored in the cache file: "" stores it as is,
+	// "service_account" encrypts it with a key derived from the service
+	// account token, and "kms" encrypts it with the KMS described by KMSType
+	// and KMSConfig. It doesn't apply to anything written by sinks.
+	EncryptionKeyProtection string            `hcl:"encryption_key_protection"`
+	KMSType                 string            `hcl:"kms_type"`
+	KMSConfig               map[string]string `hcl:"kms_config"`
 }
 
 // AddPersistentStorageToLeaseCache adds persistence to a lease cache, based on a given PersistConfig
@@ -107,6 +118,16 @@ func AddPersistentStorageToLeaseCache(ctx context.Context, leaseCache *cache.Lea
 		return nil, "", fmt.Errorf("persistent key protection type %q not supported", persistConfig.Type)
 	}
 
+	kek, err := persistKeyEncryptionWrapper(ctx, persistConfig, aad, logger)
+	if err != nil {
+		return nil, "", err
+	}
+	if kek != nil {
+		if finalizer, ok := kek.(wrapping.InitFinalizer); ok {
+			defer finalizer.Finalize(ctx)
+		}
+	}
+
 	// Check if bolt file exists already
 	dbFileExists, err := cacheboltdb.DBFileExists(persistConfig.Path)
 	if err != nil {
@@ -133,7 +154,7 @@ func AddPersistentStorageToLeaseCache(ctx context.Context, leaseCache *cache.Lea
 			return nil, "", fmt.Errorf("failed to close persistent cache file after getting retrieval token: %w", err)
 		}
 
-		km, err := keymanager.NewPassthroughKeyManager(ctx, token)
+		km, err := newPersistKeyManager(ctx, kek, token)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to configure persistence encryption for cache: %w", err)
 		}
@@ -197,7 +218,7 @@ func AddPersistentStorageToLeaseCache(ctx context.Context, leaseCache *cache.Lea
 			return nil, previousToken, nil
 		}
 	} else {
-		km, err := keymanager.NewPassthroughKeyManager(ctx, nil)
+		km, err := newPersistKeyManager(ctx, kek, nil)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to configure persistence encryption for cache: %w", err)
 		}
@@ -226,6 +247,55 @@ func AddPersistentStorageToLeaseCache(ctx context.Context, leaseCache *cache.Lea
 	}
 }
 
+// persistKeyEncryptionWrapper returns the wrapper used to protect the cache
+// encryption key, or nil if the key is stored unprotected.
+func persistKeyEncryptionWrapper(ctx context.Context, persistConfig *PersistConfig, serviceAccountJWT string, logger log.Logger) (wrapping.Wrapper, error) {
+	switch persistConfig.EncryptionKeyProtection {
+	case "":
+		return nil, nil
+	case "service_account":
+		if persistConfig.Type != "kubernetes" {
+			return nil, fmt.Errorf("encryption_key_protection %q requires the kubernetes persist type", persistConfig.EncryptionKeyProtection)
+		}
+		return keymanager.NewDerivedKeyWrapper(ctx, []byte(serviceAccountJWT))
+	case "kms":
+		if persistConfig.KMSType == "" {
+			return nil, errors.New("encryption_key_protection \"kms\" requires kms_type to be set")
+		}
+		kmsConfig := make(map[string]string, len(persistConfig.KMSConfig))
+		for k, v := range persistConfig.KMSConfig {
+			kmsConfig[k] = v
+		}
+		wrapper, err := configutil.ConfigureWrapper(&configutil.KMS{
+			Type:   persistConfig.KMSType,
+			Config: kmsConfig,
+		}, nil, nil, logger.Named("kms"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure %q kms for persistent cache key protection: %w", persistConfig.KMSType, err)
+		}
+		if wrapper == nil {
+			return nil, fmt.Errorf("kms type %q cannot be used for persistent cache key protection", persistConfig.KMSType)
+		}
+		if initializer, ok := wrapper.(wrapping.InitFinalizer); ok {
+			if err := initializer.Init(ctx); err != nil {
+				return nil, fmt.Errorf("failed to initialize kms for persistent cache key protection: %w", err)
+			}
+		}
+		return wrapper, nil
+	default:
+		return nil, fmt.Errorf("persistent cache encryption_key_protection %q not supported", persistConfig.EncryptionKeyProtection)
+	}
+}
+
+// newPersistKeyManager returns the key manager for the persistent cache,
+// protecting the encryption key with kek if it is set.
+func newPersistKeyManager(ctx context.Context, kek wrapping.Wrapper, token []byte) (keymanager.KeyManager, error) {
+	if kek == nil {
+		return keymanager.NewPassthroughKeyManager(ctx, token)
+	}
+	return keymanager.NewProtectedKeyManager(ctx, kek, token)
+}
+
 // getServiceAccountJWT attempts to read the service account JWT from the specified token file path.
 // Defaults to using the Kubernetes default service account file path if token file path is empty.
 func getServiceAccountJWT(tokenFile string) (string, error) {
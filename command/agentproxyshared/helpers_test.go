@@ -90,3 +90,57 @@ func Test_AddPersistentStorageToLeaseCache(t *testing.T) {
 		t.Fatal("expected deferFunc to not be nil")
 	}
 }
+
+// Test_AddPersistentStorageToLeaseCache_EncryptionKeyProtection tests that a persistent
+// cache created with service account key protection can only be restored with
+// the same service account token, and that the encryption key is not stored
+// in plaintext.
+func Test_AddPersistentStorageToLeaseCache_EncryptionKeyProtection(t *testing.T) {
+	tempDir := t.TempDir()
+	serviceAccountTokenFile := populateTempFile(t, "token", "token")
+
+	persistConfig := &PersistConfig{
+		Type:                    "kubernetes",
+		Path:                    tempDir,
+		KeepAfterImport:         true,
+		ServiceAccountTokenFile: serviceAccountTokenFile.Name(),
+		EncryptionKeyProtection: "service_account",
+	}
+	logger := logging.NewVaultLogger(hclog.Info)
+
+	leaseCache := testNewLeaseCache(t, nil)
+	deferFunc, _, err := AddPersistentStorageToLeaseCache(context.Background(), leaseCache, persistConfig, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retrievalToken, err := leaseCache.PersistentStorage().GetRetrievalToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(retrievalToken) == 32 {
+		t.Fatal("expected retrieval token to be protected")
+	}
+	if err := deferFunc(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring with the same token succeeds
+	deferFunc, _, err = AddPersistentStorageToLeaseCache(context.Background(), testNewLeaseCache(t, nil), persistConfig, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := deferFunc(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring with another token fails
+	persistConfig.ServiceAccountTokenFile = populateTempFile(t, "token", "other-token").Name()
+	if _, _, err := AddPersistentStorageToLeaseCache(context.Background(), testNewLeaseCache(t, nil), persistConfig, logger); err == nil {
+		t.Fatal("expected error restoring persistent cache with a different service account token")
+	}
+
+	persistConfig.EncryptionKeyProtection = "bogus"
+	if _, _, err := AddPersistentStorageToLeaseCache(context.Background(), testNewLeaseCache(t, nil), persistConfig, logger); err == nil {
+		t.Fatal("expected error for unsupported encryption_key_protection")
+	}
+}
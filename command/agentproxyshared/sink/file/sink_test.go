@@ -4,15 +4,21 @@
 package file
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"filippo.io/age"
+	"filippo.io/age/armor"
 	hclog "github.com/hashicorp/go-hclog"
 	uuid "github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/command/agentproxyshared/sink"
@@ -69,6 +75,68 @@ func TestSinkServer(t *testing.T) {
 	}
 }
 
+func TestSinkServerAgeEncryption(t *testing.T) {
+	log := logging.NewVaultLogger(hclog.Trace)
+
+	fs, path := testFileSink(t, log)
+	defer os.RemoveAll(path)
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipientsPath := filepath.Join(path, "recipients.txt")
+	if err := os.WriteFile(recipientsPath, []byte("# consumer\n"+identity.Recipient().String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fs.DHType = "age"
+	fs.DHPath = recipientsPath
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+
+	ss := sink.NewSinkServer(&sink.SinkServerConfig{
+		Logger: log.Named("sink.server"),
+	})
+
+	uuidStr, _ := uuid.GenerateUUID()
+	in := make(chan string)
+	errCh := make(chan error)
+	go func() {
+		errCh <- ss.Run(ctx, in, []*sink.SinkConfig{fs})
+	}()
+
+	in <- uuidStr
+
+	timer := time.AfterFunc(3*time.Second, func() {
+		cancelFunc()
+	})
+	defer timer.Stop()
+
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	fileBytes, err := ioutil.ReadFile(filepath.Join(path, "token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(fileBytes), uuidStr) {
+		t.Fatal("expected token to be encrypted")
+	}
+
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(fileBytes)), identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decrypted) != uuidStr {
+		t.Fatalf("expected %s, got %s", uuidStr, string(decrypted))
+	}
+}
+
 type badSink struct {
 	tryCount uint32
 	logger   hclog.Logger
@@ -4,6 +4,7 @@
 package sink
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -13,6 +14,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"filippo.io/age"
+	"filippo.io/age/armor"
 	hclog "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/helper/dhutil"
@@ -40,6 +43,7 @@ type SinkConfig struct {
 	cachedRemotePubKey []byte
 	cachedPubKey       []byte
 	cachedPriKey       []byte
+	cachedRecipients   []age.Recipient
 }
 
 type SinkServerConfig struct {
@@ -173,6 +177,10 @@ func (ss *SinkServer) Run(ctx context.Context, incoming chan string, sinks []*Si
 }
 
 func (s *SinkConfig) encryptToken(token string) (string, error) {
+	if s.DHType == "age" {
+		return s.encryptTokenAge(token)
+	}
+
 	var aesKey []byte
 	var err error
 	resp := new(dhutil.Envelope)
@@ -237,6 +245,46 @@ func (s *SinkConfig) encryptToken(token string) (string, error) {
 	return string(m), nil
 }
 
+// encryptTokenAge encrypts the token to the age X25519 recipients listed in
+// the file at DHPath, returning an ASCII-armored age file that can only be
+// decrypted by a holder of one of the matching identities.
+func (s *SinkConfig) encryptTokenAge(token string) (string, error) {
+	if len(s.cachedRecipients) == 0 {
+		f, err := os.Open(s.DHPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", errors.New("no age recipients file found, and no cached recipients")
+			}
+			return "", fmt.Errorf("error opening age recipients file: %w", err)
+		}
+		defer f.Close()
+
+		recipients, err := age.ParseRecipients(f)
+		if err != nil {
+			return "", fmt.Errorf("error parsing age recipients: %w", err)
+		}
+		s.cachedRecipients = recipients
+	}
+
+	buf := new(bytes.Buffer)
+	armorWriter := armor.NewWriter(buf)
+	w, err := age.Encrypt(armorWriter, s.cachedRecipients...)
+	if err != nil {
+		return "", fmt.Errorf("error encrypting to age recipients: %w", err)
+	}
+	if _, err := w.Write([]byte(token)); err != nil {
+		return "", fmt.Errorf("error encrypting to age recipients: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("error encrypting to age recipients: %w", err)
+	}
+	if err := armorWriter.Close(); err != nil {
+		return "", fmt.Errorf("error armoring age payload: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
 func (s *SinkConfig) wrapToken(client *api.Client, wrapTTL time.Duration, token string) (string, error) {
 	wrapClient, err := client.CloneWithHeaders()
 	if err != nil {
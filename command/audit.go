@@ -24,7 +24,7 @@ func (c *AuditCommand) Help() string {
 Usage: vault audit <subcommand> [options] [args]
 
   This command groups subcommands for interacting with Vault's audit devices.
-  Users can list, enable, and disable audit devices.
+  Users can list, enable, and disable audit devices, and tail audit entries.
 
   *NOTE*: Once an audit device has been enabled, failure to audit could prevent
   Vault from servicing future requests. It is highly recommended that you enable
@@ -38,6 +38,10 @@ Usage: vault audit <subcommand> [options] [args]
 
        $ vault audit enable file file_path=/var/log/audit.log
 
+  Stream audit entries for requests under "secret/":
+
+      $ vault audit tail -filter="path matches secret/*"
+
   Please see the individual subcommand help for detailed usage information.
 `
 
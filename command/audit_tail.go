@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*AuditTailCommand)(nil)
+	_ cli.CommandAutocomplete = (*AuditTailCommand)(nil)
+)
+
+type AuditTailCommand struct {
+	*BaseCommand
+
+	flagFilters []string
+	flagDevice  string
+
+	// ShutdownCh is used to capture interrupt signal and end streaming
+	ShutdownCh chan struct{}
+}
+
+func (c *AuditTailCommand) Synopsis() string {
+	return "Stream audit entries from a Vault server"
+}
+
+func (c *AuditTailCommand) Help() string {
+	helpText := `
+Usage: vault audit tail [options]
+
+  Streams request and response audit entries from the Vault server as they
+  are logged, one JSON entry per line. Sensitive values are HMAC'd in the same
+  way as they are in audit logs, so at least one audit device must be enabled.
+  Only entries audited by the node the client is connected to are streamed.
+
+  Filters have the form "<field> <operator> <value>". Supported fields are
+  "type", "path", "operation", "mount_point", "mount_type", "namespace",
+  "remote_address" and "error". Supported operators are "==", "!=" and
+  "matches", the latter accepting "*" wildcards. When several filters are
+  given, an entry must match all of them.
+
+  Stream every audit entry:
+
+      $ vault audit tail
+
+  Watch writes to the "secret/" mount during a change window:
+
+      $ vault audit tail -filter="path matches secret/*" -filter="operation == update"
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AuditTailCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringSliceVar(&StringSliceVar{
+		Name:   "filter",
+		Target: &c.flagFilters,
+		Usage: "Filter expression of the form \"<field> <operator> <value>\" " +
+			"which entries must match to be streamed. This can be specified " +
+			"multiple times.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "device",
+		Target:     &c.flagDevice,
+		Completion: c.PredictVaultAudits(),
+		Usage: "Path of the audit device whose salt is used to hash the " +
+			"streamed entries. Defaults to the first enabled audit device.",
+	})
+
+	return set
+}
+
+func (c *AuditTailCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *AuditTailCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *AuditTailCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	if len(args) > 0 {
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 0, got %d)", len(args)))
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	// Remove the default 60 second timeout so we can stream indefinitely
+	client.SetClientTimeout(0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	entryCh, err := client.Sys().AuditTail(ctx, &api.AuditTailOptions{
+		Filters: c.flagFilters,
+		Device:  strings.TrimSpace(c.flagDevice),
+	})
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error tailing audit entries: %s", err))
+		return 2
+	}
+
+	for {
+		select {
+		case entry, ok := <-entryCh:
+			if !ok {
+				return 0
+			}
+			c.UI.Output(entry)
+		case <-c.ShutdownCh:
+			return 0
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mitchellh/cli"
+)
+
+func testAuditTailCommand(tb testing.TB) (*cli.MockUi, *AuditTailCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &AuditTailCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func TestAuditTailCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name   string
+		args   []string
+		enable bool
+		out    string
+		code   int64
+	}{
+		{
+			"too_many_args",
+			[]string{"foo"},
+			true,
+			"Too many arguments",
+			1,
+		},
+		{
+			"no_devices",
+			nil,
+			false,
+			"no audit devices are enabled",
+			2,
+		},
+		{
+			"bad_filter",
+			[]string{"-filter=path ~= secret/*"},
+			true,
+			"unknown operator",
+			2,
+		},
+		{
+			"streams",
+			[]string{"-filter=path matches sys/mounts*"},
+			true,
+			`"path":"sys/mounts"`,
+			0,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			client, closer := testVaultServer(t)
+			defer closer()
+
+			if tc.enable {
+				if err := client.Sys().EnableAuditWithOptions("file", &api.EnableAuditOptions{
+					Type: "file",
+					Options: map[string]string{
+						"file_path": "discard",
+					},
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var code int64
+			shutdownCh := make(chan struct{})
+
+			ui, cmd := testAuditTailCommand(t)
+			cmd.client = client
+			cmd.ShutdownCh = shutdownCh
+
+			go func() {
+				atomic.StoreInt64(&code, int64(cmd.Run(tc.args)))
+			}()
+
+			<-time.After(1 * time.Second)
+			if _, err := client.Sys().ListMounts(); err != nil {
+				t.Fatal(err)
+			}
+			<-time.After(2 * time.Second)
+			close(shutdownCh)
+
+			if atomic.LoadInt64(&code) != tc.code {
+				t.Errorf("expected %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+			if !strings.Contains(combined, tc.out) {
+				t.Fatalf("expected %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
@@ -60,6 +60,7 @@ type BaseCommand struct {
 	flagFormat           string
 	flagField            string
 	flagDetailed         bool
+	flagColumns          string
 	flagOutputCurlString bool
 	flagOutputPolicy     bool
 	flagNonInteractive   bool
@@ -70,6 +71,10 @@ type BaseCommand struct {
 
 	tokenHelper token.TokenHelper
 
+	// namespaceContextPath overrides the file the namespace set with
+	// "vault namespace use" is stored in. Tests may set it.
+	namespaceContextPath string
+
 	client *api.Client
 }
 
@@ -133,6 +138,24 @@ func (c *BaseCommand) Client() (*api.Client, error) {
 	// Set the wrapping function
 	client.SetWrappingLookupFunc(c.DefaultWrappingLookupFunc)
 
+	// flagNS takes precedence over flagNamespace. After resolution, point both
+	// flags to the same value to be able to use them interchangeably anywhere.
+	if c.flagNS != notSetValue {
+		c.flagNamespace = c.flagNS
+	}
+	if c.flagNamespace != notSetValue {
+		client.SetNamespace(namespace.Canonicalize(c.flagNamespace))
+	} else if client.Namespace() == "" {
+		// Fall back to the namespace set with "vault namespace use"
+		ns, err := c.namespaceContext()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read namespace context")
+		}
+		if ns != "" {
+			client.SetNamespace(ns)
+		}
+	}
+
 	// Get the token if it came in from the environment
 	token := client.Token()
 
@@ -142,6 +165,7 @@ func (c *BaseCommand) Client() (*api.Client, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get token helper")
 		}
+		setTokenHelperContext(helper, client)
 		token, err = helper.Get()
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get token from token helper")
@@ -155,14 +179,6 @@ func (c *BaseCommand) Client() (*api.Client, error) {
 
 	client.SetMFACreds(c.flagMFA)
 
-	// flagNS takes precedence over flagNamespace. After resolution, point both
-	// flags to the same value to be able to use them interchangeably anywhere.
-	if c.flagNS != notSetValue {
-		c.flagNamespace = c.flagNS
-	}
-	if c.flagNamespace != notSetValue {
-		client.SetNamespace(namespace.Canonicalize(c.flagNamespace))
-	}
 	if c.flagPolicyOverride {
 		client.SetPolicyOverride(c.flagPolicyOverride)
 	}
@@ -209,9 +225,23 @@ func (c *BaseCommand) TokenHelper() (token.TokenHelper, error) {
 	if err != nil {
 		return nil, err
 	}
+	if c.client != nil {
+		setTokenHelperContext(helper, c.client)
+	}
 	return helper, nil
 }
 
+// setTokenHelperContext passes the server and namespace of client to token
+// helpers that keep separate tokens per server.
+func setTokenHelperContext(helper token.TokenHelper, client *api.Client) {
+	if h, ok := helper.(token.ContextualTokenHelper); ok {
+		h.SetContext(token.Context{
+			Address:   client.Address(),
+			Namespace: client.Namespace(),
+		})
+	}
+}
+
 // DefaultWrappingLookupFunc is the default wrapping function based on the
 // CLI flag.
 func (c *BaseCommand) DefaultWrappingLookupFunc(operation, path string) string {
@@ -526,6 +556,18 @@ func (c *BaseCommand) flagSet(bit FlagSetBit) *FlagSets {
 						are "table", "json", "yaml", or "pretty". "raw" is allowed
 						for 'vault read' operations only.`,
 				})
+
+				outputSet.StringVar(&StringVar{
+					Name:       "columns",
+					Target:     &c.flagColumns,
+					Default:    "",
+					EnvVar:     EnvVaultColumns,
+					Completion: complete.PredictAnything,
+					Usage: `Comma separated list of the fields to show, in order, when
+						using the "table" format. For "vault read", this selects the
+						keys to print. For "vault list", this selects the columns of
+						additional key information to print alongside the keys.`,
+				})
 			}
 
 			if bit&FlagSetOutputDetailed != 0 {
@@ -4,6 +4,8 @@
 package command
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"sort"
 	"strings"
@@ -16,6 +18,7 @@ import (
 type Predict struct {
 	client     *api.Client
 	clientOnce sync.Once
+	cache      *predictCache
 }
 
 func NewPredict() *Predict {
@@ -32,6 +35,7 @@ func (p *Predict) Client() *api.Client {
 				if err != nil {
 					return
 				}
+				setTokenHelperContext(helper, client)
 				token, err := helper.Get()
 				if err != nil {
 					return
@@ -44,7 +48,13 @@ func (p *Predict) Client() *api.Client {
 				client.SetMaxRetries(0)
 			}
 
+			// Don't let an unreachable server block the shell
+			if os.Getenv(api.EnvVaultClientTimeout) == "" {
+				client.SetClientTimeout(defaultPredictTimeout)
+			}
+
 			p.client = client
+			p.cache = newPredictCache()
 		}
 	})
 	return p.client
@@ -171,9 +181,13 @@ func (b *BaseCommand) PredictVaultDebugTargets() complete.Predictor {
 	return complete.PredictSet(
 		"config",
 		"host",
+		"log",
 		"metrics",
+		"mounts",
 		"pprof",
+		"raft",
 		"replication-status",
+		"requests",
 		"server-status",
 	)
 }
@@ -451,21 +465,76 @@ func (p *Predict) policies() []string {
 
 // mountInfos returns a map with mount paths as keys and MountOutputs as values
 // for the Vault server which the client is configured to communicate with.
-// Returns error if server communication fails.
+// The mounts are read from sys/internal/ui/mounts, which lists the mounts the
+// token has access to without requiring access to sys/mounts, falling back to
+// sys/mounts for servers that do not support it. Returns error if server
+// communication fails.
 func (p *Predict) mountInfos() (map[string]*api.MountOutput, error) {
 	client := p.Client()
 	if client == nil {
 		return nil, nil
 	}
 
-	mounts, err := client.Sys().ListMounts()
+	var mounts map[string]*api.MountOutput
+	if p.cache.get(&mounts, p.cacheKey("mounts")...) {
+		return mounts, nil
+	}
+
+	mounts, err := p.uiMountInfos()
+	if err != nil {
+		mounts, err = client.Sys().ListMounts()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.cache.set(mounts, p.cacheKey("mounts")...)
+	return mounts, nil
+}
+
+// uiMountInfos returns the secret mounts listed by sys/internal/ui/mounts.
+func (p *Predict) uiMountInfos() (map[string]*api.MountOutput, error) {
+	secret, err := p.Client().Logical().Read("sys/internal/ui/mounts")
 	if err != nil {
 		return nil, err
 	}
+	if secret == nil || secret.Data == nil {
+		return nil, errors.New("no mounts returned")
+	}
 
+	// Only decode the fields needed for prediction, as the remaining mount
+	// information differs between authenticated and unauthenticated requests.
+	raw, err := json.Marshal(secret.Data["secret"])
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]struct {
+		Type    string            `json:"type"`
+		Options map[string]string `json:"options"`
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no mounts returned")
+	}
+
+	mounts := make(map[string]*api.MountOutput, len(entries))
+	for path, entry := range entries {
+		mounts[path] = &api.MountOutput{
+			Type:    entry.Type,
+			Options: entry.Options,
+		}
+	}
 	return mounts, nil
 }
 
+// cacheKey returns the key completion results for kind are cached under.
+func (p *Predict) cacheKey(kind string, parts ...string) []string {
+	client := p.Client()
+	return append([]string{client.Address(), client.Namespace(), client.Token(), kind}, parts...)
+}
+
 // mounts returns a sorted list of the mount paths for Vault server for
 // which the client is configured to communicate with. This function returns the
 // default list of mounts if an error occurs.
@@ -521,6 +590,11 @@ func (p *Predict) listPaths(path string) []string {
 		return nil
 	}
 
+	var list []string
+	if p.cache.get(&list, p.cacheKey("list", path)...) {
+		return list
+	}
+
 	secret, err := client.Logical().List(path)
 	if err != nil || secret == nil || secret.Data == nil {
 		return nil
@@ -531,13 +605,15 @@ func (p *Predict) listPaths(path string) []string {
 		return nil
 	}
 
-	list := make([]string, 0, len(paths))
+	list = make([]string, 0, len(paths))
 	for _, p := range paths {
 		if str, ok := p.(string); ok {
 			list = append(list, str)
 		}
 	}
 	sort.Strings(list)
+
+	p.cache.set(list, p.cacheKey("list", path)...)
 	return list
 }
 
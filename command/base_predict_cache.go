@@ -0,0 +1,129 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-secure-stdlib/parseutil"
+)
+
+const (
+	// defaultPredictCacheTTL is how long completion results are reused for.
+	// Every completion runs a new process, so without a cache each press of
+	// tab would query the server again.
+	defaultPredictCacheTTL = 30 * time.Second
+
+	// defaultPredictTimeout bounds the requests made for completions, so an
+	// unreachable server does not block the shell.
+	defaultPredictTimeout = 3 * time.Second
+)
+
+// predictCache caches completion results on disk between invocations of the
+// CLI. Entries are keyed by the server, namespace and token they were fetched
+// with, so results are never shared between tokens.
+type predictCache struct {
+	dir string
+	ttl time.Duration
+}
+
+type predictCacheEntry struct {
+	Expires time.Time       `json:"expires"`
+	Value   json.RawMessage `json:"value"`
+}
+
+// newPredictCache returns the cache configured by the environment, or nil if
+// caching is disabled or no cache directory is available.
+func newPredictCache() *predictCache {
+	ttl := defaultPredictCacheTTL
+	if raw := os.Getenv(EnvVaultCompletionCacheTTL); raw != "" {
+		parsed, err := parseutil.ParseDurationSecond(raw)
+		if err != nil {
+			return nil
+		}
+		ttl = parsed
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return &predictCache{
+		dir: filepath.Join(dir, "vault", "completion"),
+		ttl: ttl,
+	}
+}
+
+func (c *predictCache) path(key ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(key, "\x00")))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// get decodes the cached value for key into out, returning false if there is
+// no unexpired entry.
+func (c *predictCache) get(out interface{}, key ...string) bool {
+	if c == nil {
+		return false
+	}
+
+	raw, err := os.ReadFile(c.path(key...))
+	if err != nil {
+		return false
+	}
+	var entry predictCacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return false
+	}
+	if time.Now().After(entry.Expires) {
+		return false
+	}
+	return json.Unmarshal(entry.Value, out) == nil
+}
+
+// set stores value for key. Errors are ignored, as the cache only saves
+// requests.
+func (c *predictCache) set(value interface{}, key ...string) {
+	if c == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	raw, err := json.Marshal(&predictCacheEntry{
+		Expires: time.Now().Add(c.ttl),
+		Value:   encoded,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return
+	}
+	f, err := os.CreateTemp(c.dir, ".entry")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(raw)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(key...))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
@@ -6,6 +6,7 @@ package command
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/hashicorp/vault/api"
@@ -321,6 +322,80 @@ func TestPredict_Mounts(t *testing.T) {
 	})
 }
 
+func TestPredict_MountsRestrictedToken(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	if err := client.Sys().PutPolicy("secret-list", `path "secret/*" { capabilities = ["list"] }`); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := client.Auth().Token().Create(&api.TokenCreateRequest{
+		Policies:        []string{"secret-list"},
+		NoDefaultPolicy: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restricted, err := client.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restricted.SetToken(secret.Auth.ClientToken)
+
+	// The token cannot read sys/mounts, but the mounts it has access to are
+	// still predicted.
+	if _, err := restricted.Sys().ListMounts(); err == nil {
+		t.Fatal("expected listing sys/mounts to be denied")
+	}
+
+	p := NewPredict()
+	p.client = restricted
+
+	act := p.mounts()
+	if exp := []string{"secret/"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("expected %q to be %q", act, exp)
+	}
+}
+
+func TestPredictCache(t *testing.T) {
+	t.Parallel()
+
+	c := &predictCache{dir: t.TempDir(), ttl: time.Minute}
+
+	var act []string
+	if c.get(&act, "addr", "token-a", "list", "secret/") {
+		t.Fatal("expected no cached value")
+	}
+
+	exp := []string{"bar", "foo/"}
+	c.set(exp, "addr", "token-a", "list", "secret/")
+	if !c.get(&act, "addr", "token-a", "list", "secret/") || !reflect.DeepEqual(act, exp) {
+		t.Errorf("expected %q to be %q", act, exp)
+	}
+
+	// Results are not shared between tokens.
+	if c.get(&act, "addr", "token-b", "list", "secret/") {
+		t.Error("expected no cached value for another token")
+	}
+
+	// Expired entries are ignored.
+	expired := &predictCache{dir: c.dir, ttl: -time.Second}
+	expired.set(exp, "addr", "token-c", "list", "secret/")
+	if c.get(&act, "addr", "token-c", "list", "secret/") {
+		t.Error("expected expired value to be ignored")
+	}
+
+	// A nil cache is disabled.
+	var disabled *predictCache
+	disabled.set(exp, "addr", "token-a", "list", "secret/")
+	if disabled.get(&act, "addr", "token-a", "list", "secret/") {
+		t.Error("expected disabled cache to return nothing")
+	}
+}
+
 func TestPredict_Plugins(t *testing.T) {
 	t.Parallel()
 
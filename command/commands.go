@@ -30,7 +30,6 @@ import (
 	credCentrify "github.com/hashicorp/vault-plugin-auth-centrify"
 	credCF "github.com/hashicorp/vault-plugin-auth-cf"
 	credGcp "github.com/hashicorp/vault-plugin-auth-gcp/plugin"
-	credOIDC "github.com/hashicorp/vault-plugin-auth-jwt"
 	credKerb "github.com/hashicorp/vault-plugin-auth-kerberos"
 	credOCI "github.com/hashicorp/vault-plugin-auth-oci"
 	credAws "github.com/hashicorp/vault/builtin/credential/aws"
@@ -85,6 +84,11 @@ const (
 	EnvVaultLicensePath = "VAULT_LICENSE_PATH"
 	// EnvVaultDetailed is to output detailed information (e.g., ListResponseWithInfo).
 	EnvVaultDetailed = `VAULT_DETAILED`
+	// EnvVaultColumns is a comma separated list of the columns to show in table output
+	EnvVaultColumns = `VAULT_COLUMNS`
+	// EnvVaultCompletionCacheTTL is how long shell completion results are
+	// cached for. Setting it to 0 disables the cache.
+	EnvVaultCompletionCacheTTL = `VAULT_COMPLETION_CACHE_TTL`
 	// EnvVaultLogFormat is used to specify the log format. Supported values are "standard" and "json"
 	EnvVaultLogFormat = "VAULT_LOG_FORMAT"
 	// EnvVaultLogLevel is used to specify the log level applied to logging
@@ -134,6 +138,8 @@ const (
 	flagNameTokenType = "token-type"
 	// flagNameAllowedManagedKeys is the flag name used for auth/secrets enable
 	flagNameAllowedManagedKeys = "allowed-managed-keys"
+	// flagNameReadCacheSize is the flag name used to set the size of the read cache of a secrets mount
+	flagNameReadCacheSize = "read-cache-size"
 	// flagNamePluginVersion selects what version of a plugin should be used.
 	flagNamePluginVersion = "plugin-version"
 	// flagNameUserLockoutThreshold is the flag name used for tuning the auth mount lockout threshold parameter
@@ -229,7 +235,7 @@ var (
 		"kerberos": &credKerb.CLIHandler{},
 		"ldap":     &credLdap.CLIHandler{},
 		"oci":      &credOCI.CLIHandler{},
-		"oidc":     &credOIDC.CLIHandler{},
+		"oidc":     &oidcCLIHandler{},
 		"okta":     &credOkta.CLIHandler{},
 		"pcf":      &credCF.CLIHandler{}, // Deprecated.
 		"radius": &credUserpass.CLIHandler{
@@ -289,6 +295,12 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"audit tail": func() (cli.Command, error) {
+			return &AuditTailCommand{
+				BaseCommand: getBaseCommand(),
+				ShutdownCh:  MakeShutdownCh(),
+			}, nil
+		},
 		"auth tune": func() (cli.Command, error) {
 			return &AuthTuneCommand{
 				BaseCommand: getBaseCommand(),
@@ -387,6 +399,16 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"namespace use": func() (cli.Command, error) {
+			return &NamespaceUseCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
+		"namespace current": func() (cli.Command, error) {
+			return &NamespaceCurrentCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"namespace create": func() (cli.Command, error) {
 			return &NamespaceCreateCommand{
 				BaseCommand: getBaseCommand(),
@@ -484,6 +506,11 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"operator raft snapshot inspect": func() (cli.Command, error) {
+			return &OperatorRaftSnapshotInspectCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"operator raft snapshot restore": func() (cli.Command, error) {
 			return &OperatorRaftSnapshotRestoreCommand{
 				BaseCommand: getBaseCommand(),
@@ -554,6 +581,11 @@ func initCommands(ui, serverCmdUi cli.Ui, runOpts *RunOptions) map[string]cli.Co
 				BaseCommand: getBaseCommand(),
 			}, nil
 		},
+		"pki issue-cert": func() (cli.Command, error) {
+			return &PKIIssueCertCommand{
+				BaseCommand: getBaseCommand(),
+			}, nil
+		},
 		"pki list-intermediates": func() (cli.Command, error) {
 			return &PKIListIntermediateCommand{
 				BaseCommand: getBaseCommand(),
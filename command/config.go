@@ -24,6 +24,13 @@ type DefaultConfig struct {
 	// is not specified, then vault's internal token store will be used, which
 	// stores the token on disk unencrypted.
 	TokenHelper string `hcl:"token_helper"`
+
+	// TokenHelperProtocol is the version of the protocol spoken with the
+	// external token helper, 1 (default) or 2.
+	TokenHelperProtocol int `hcl:"token_helper_protocol"`
+
+	// TokenProfile selects one of several tokens stored for the same server.
+	TokenProfile string `hcl:"token_profile"`
 }
 
 // Config loads the configuration and returns it. If the configuration
@@ -21,6 +21,10 @@ const (
 	// ConfigPathEnv is the environment variable that can be used to
 	// override where the Vault configuration is.
 	ConfigPathEnv = "VAULT_CONFIG_PATH"
+
+	// TokenProfileEnv is the environment variable that can be used to
+	// override the token profile of the configuration.
+	TokenProfileEnv = "VAULT_TOKEN_PROFILE"
 )
 
 // Config is the CLI configuration for Vault that can be specified via
@@ -30,7 +34,20 @@ type DefaultConfig struct {
 	// and retrieving the authentication token for the Vault CLI. If this
 	// is not specified, then vault's internal token store will be used, which
 	// stores the token on disk unencrypted.
+	//
+	// The values "keychain" and "secret-service" select the built-in helpers
+	// storing tokens in the macOS keychain or the freedesktop.org Secret
+	// Service.
 	TokenHelper string `hcl:"token_helper"`
+
+	// TokenHelperProtocol is the version of the protocol spoken with the
+	// external token helper, 1 (default) or 2.
+	TokenHelperProtocol int `hcl:"token_helper_protocol"`
+
+	// TokenProfile selects one of several tokens stored for the same server
+	// by the built-in keychain helpers and helpers speaking protocol
+	// version 2.
+	TokenProfile string `hcl:"token_profile"`
 }
 
 // Config loads the configuration and returns it. If the configuration
@@ -90,6 +107,8 @@ func ParseConfig(contents string) (*DefaultConfig, error) {
 
 	valid := []string{
 		"token_helper",
+		"token_helper_protocol",
+		"token_profile",
 	}
 	if err := hclutil.CheckHCLKeys(list, valid); err != nil {
 		return nil, err
@@ -99,5 +118,12 @@ func ParseConfig(contents string) (*DefaultConfig, error) {
 	if err := hcl.DecodeObject(&c, list); err != nil {
 		return nil, err
 	}
+
+	switch c.TokenHelperProtocol {
+	case 0, 1, 2:
+	default:
+		return nil, fmt.Errorf("unsupported token_helper_protocol %d, must be 1 or 2", c.TokenHelperProtocol)
+	}
+
 	return &c, nil
 }
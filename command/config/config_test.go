@@ -50,3 +50,28 @@ nope = "true"
 		t.Errorf("bad error: %s", err.Error())
 	}
 }
+
+func TestParseConfig_tokenHelperProtocol(t *testing.T) {
+	config, err := ParseConfig(`
+token_helper = "/token"
+token_helper_protocol = 2
+token_profile = "ops"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &DefaultConfig{
+		TokenHelper:         "/token",
+		TokenHelperProtocol: 2,
+		TokenProfile:        "ops",
+	}
+	if !reflect.DeepEqual(expected, config) {
+		t.Fatalf("bad: %#v", config)
+	}
+
+	_, err = ParseConfig(`token_helper_protocol = 3`)
+	if err == nil || !strings.Contains(err.Error(), "unsupported token_helper_protocol 3") {
+		t.Fatalf("expected protocol error, got %v", err)
+	}
+}
@@ -4,6 +4,8 @@
 package config
 
 import (
+	"os"
+
 	"github.com/hashicorp/vault/command/token"
 )
 
@@ -15,14 +17,26 @@ func DefaultTokenHelper() (token.TokenHelper, error) {
 		return nil, err
 	}
 
+	profile := config.TokenProfile
+	if v := os.Getenv(TokenProfileEnv); v != "" {
+		profile = v
+	}
+
 	path := config.TokenHelper
-	if path == "" {
+	switch {
+	case path == "":
 		return token.NewInternalTokenHelper()
+	case token.IsKeychainBackend(path):
+		return token.NewKeychainTokenHelper(path, profile)
 	}
 
 	path, err = token.ExternalTokenHelperPath(path)
 	if err != nil {
 		return nil, err
 	}
-	return &token.ExternalTokenHelper{BinaryPath: path}, nil
+	return &token.ExternalTokenHelper{
+		BinaryPath: path,
+		Protocol:   config.TokenHelperProtocol,
+		Profile:    profile,
+	}, nil
 }
@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -54,8 +55,40 @@ const (
 	// fileFriendlyTimeFormat is the time format used for file and directory
 	// naming.
 	fileFriendlyTimeFormat = "2006-01-02T15-04-05Z"
+
+	// debugRedactedValue replaces sensitive strings matched by a redaction
+	// rule.
+	debugRedactedValue = "[redacted]"
 )
 
+// debugRedactRule is a rule replacing sensitive strings in the bundle.
+type debugRedactRule struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// debugDefaultRedactRules are applied to the bundle unless -redact-defaults
+// is disabled. They cover Vault tokens and the values of JSON keys that
+// commonly hold secrets.
+var debugDefaultRedactRules = []debugRedactRule{
+	{
+		re:          regexp.MustCompile(`\bhv[sbr]\.[A-Za-z0-9_-]{20,}`),
+		replacement: debugRedactedValue,
+	},
+	{
+		re:          regexp.MustCompile(`\b[sbr]\.[A-Za-z0-9]{24}\b`),
+		replacement: debugRedactedValue,
+	},
+	{
+		re:          regexp.MustCompile(`"((?:[a-z_]*_)?(?:password|secret|token|secret_key|private_key|secret_id))"(\s*:\s*)"[^"]+"`),
+		replacement: `"$1"$2"` + debugRedactedValue + `"`,
+	},
+}
+
+// debugRedactExts are the extensions of the bundle files redaction applies
+// to. Binary profiles are left untouched.
+var debugRedactExts = []string{".json", ".log", ".txt"}
+
 // debugIndex represents the data structure in the index file
 type debugIndex struct {
 	Version                int                    `json:"version"`
@@ -71,6 +104,7 @@ type debugIndex struct {
 	Targets                []string               `json:"targets"`
 	Output                 map[string]interface{} `json:"output"`
 	Errors                 []*captureError        `json:"errors"`
+	Redacted               []string               `json:"redacted,omitempty"`
 }
 
 // captureError holds an error entry that can occur during polling capture.
@@ -89,12 +123,15 @@ var (
 type DebugCommand struct {
 	*BaseCommand
 
-	flagCompress        bool
-	flagDuration        time.Duration
-	flagInterval        time.Duration
-	flagMetricsInterval time.Duration
-	flagOutput          string
-	flagTargets         []string
+	flagCompress          bool
+	flagDuration          time.Duration
+	flagInterval          time.Duration
+	flagMetricsInterval   time.Duration
+	flagGoroutineInterval time.Duration
+	flagOutput            string
+	flagTargets           []string
+	flagRedact            []string
+	flagRedactDefaults    bool
 
 	// logFormat defines the output format for Monitor
 	logFormat string
@@ -117,6 +154,11 @@ type DebugCommand struct {
 	replicationStatusCollection []map[string]interface{}
 	serverStatusCollection      []map[string]interface{}
 	inFlightReqStatusCollection []map[string]interface{}
+	raftCollection              []map[string]interface{}
+	mountMetricsCollection      []map[string]interface{}
+
+	// redactRules holds the compiled redaction rules
+	redactRules []debugRedactRule
 
 	// cachedClient holds the client retrieved during preflight
 	cachedClient *api.Client
@@ -170,6 +212,15 @@ func (c *DebugCommand) Flags() *FlagSets {
 		Usage:      "The polling interval at which to collect metrics data.",
 	})
 
+	f.DurationVar(&DurationVar{
+		Name:       "goroutine-interval",
+		Target:     &c.flagGoroutineInterval,
+		Completion: complete.PredictAnything,
+		Usage: "The polling interval at which to collect goroutine profiles " +
+			"if the \"pprof\" target is specified, in addition to the " +
+			"profiles collected every interval. Disabled by default.",
+	})
+
 	f.StringVar(&StringVar{
 		Name:       "output",
 		Target:     &c.flagOutput,
@@ -182,8 +233,24 @@ func (c *DebugCommand) Flags() *FlagSets {
 		Target: &c.flagTargets,
 		Usage: "Target to capture, defaulting to all if none specified. " +
 			"This can be specified multiple times to capture multiple targets. " +
-			"Available targets are: config, host, metrics, pprof, " +
-			"replication-status, server-status, log.",
+			"Available targets are: config, host, requests, metrics, pprof, " +
+			"replication-status, server-status, log, and the targets which " +
+			"are only captured if specified: raft, mounts.",
+	})
+
+	f.StringSliceVar(&StringSliceVar{
+		Name:   "redact",
+		Target: &c.flagRedact,
+		Usage: "Regular expression matching sensitive strings to replace in " +
+			"the captured files. This can be specified multiple times.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:    "redact-defaults",
+		Target:  &c.flagRedactDefaults,
+		Default: true,
+		Usage: "Toggles whether to redact Vault tokens and the values of JSON " +
+			"keys such as \"password\" or \"secret_key\" in the captured files.",
 	})
 
 	f.StringVar(&StringVar{
@@ -225,6 +292,15 @@ Usage: vault debug [options]
 
   $ vault debug -target=host -target=metrics
 
+  To additionally capture raft and autopilot state, per-mount metrics, and
+  goroutine profiles every 10 seconds:
+
+  $ vault debug -target=raft -target=mounts -goroutine-interval=10s
+
+  To redact strings matching a regular expression from the captured files:
+
+  $ vault debug -redact='customer-[0-9]+'
+
 ` + c.Flags().Help()
 
 	return helpText
@@ -264,6 +340,9 @@ func (c *DebugCommand) Run(args []string) int {
 	c.UI.Info(fmt.Sprintf("              Duration: %s", c.flagDuration))
 	c.UI.Info(fmt.Sprintf("              Interval: %s", c.flagInterval))
 	c.UI.Info(fmt.Sprintf("      Metrics Interval: %s", c.flagMetricsInterval))
+	if c.flagGoroutineInterval > 0 {
+		c.UI.Info(fmt.Sprintf("    Goroutine Interval: %s", c.flagGoroutineInterval))
+	}
 	c.UI.Info(fmt.Sprintf("               Targets: %s", strings.Join(c.flagTargets, ", ")))
 	c.UI.Info(fmt.Sprintf("                Output: %s", dstOutputFile))
 	c.UI.Output("")
@@ -291,6 +370,12 @@ func (c *DebugCommand) Run(args []string) int {
 
 	c.UI.Output("Finished capturing information, bundling files...")
 
+	// Redact the captured files before they are indexed and compressed
+	if err := c.redactBundle(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error redacting captured files: %s", err))
+		return 1
+	}
+
 	// Generate index file
 	if err := c.generateIndex(); err != nil {
 		c.UI.Error(fmt.Sprintf("Error generating index: %s", err))
@@ -399,6 +484,10 @@ func (c *DebugCommand) preflight(rawArgs []string) (string, error) {
 			c.UI.Info(fmt.Sprintf("Overwriting metrics interval value %q to the minimum value of %q", c.flagMetricsInterval, debugMinInterval))
 			c.flagMetricsInterval = debugMinInterval
 		}
+		if c.flagGoroutineInterval > 0 && c.flagGoroutineInterval < debugMinInterval {
+			c.UI.Info(fmt.Sprintf("Overwriting goroutine interval value %q to the minimum value of %q", c.flagGoroutineInterval, debugMinInterval))
+			c.flagGoroutineInterval = debugMinInterval
+		}
 	}
 
 	// These timing checks are always applicable since interval shouldn't be
@@ -411,12 +500,29 @@ func (c *DebugCommand) preflight(rawArgs []string) (string, error) {
 		c.UI.Info(fmt.Sprintf("Overwriting metrics interval value %q to the duration value %q", c.flagMetricsInterval, c.flagDuration))
 		c.flagMetricsInterval = c.flagDuration
 	}
+	if c.flagGoroutineInterval > c.flagDuration {
+		c.UI.Info(fmt.Sprintf("Overwriting goroutine interval value %q to the duration value %q", c.flagGoroutineInterval, c.flagDuration))
+		c.flagGoroutineInterval = c.flagDuration
+	}
+
+	// Compile redaction rules before capturing anything
+	c.redactRules = nil
+	if c.flagRedactDefaults {
+		c.redactRules = append(c.redactRules, debugDefaultRedactRules...)
+	}
+	for _, expr := range c.flagRedact {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return "", fmt.Errorf("invalid redaction rule %q: %s", expr, err)
+		}
+		c.redactRules = append(c.redactRules, debugRedactRule{re: re, replacement: debugRedactedValue})
+	}
 
 	if len(c.flagTargets) == 0 {
 		c.flagTargets = c.defaultTargets()
 	} else {
 		// Check for any invalid targets and ignore them if found
-		invalidTargets := strutil.Difference(c.flagTargets, c.defaultTargets(), true)
+		invalidTargets := strutil.Difference(c.flagTargets, append(c.defaultTargets(), c.optionalTargets()...), true)
 		if len(invalidTargets) != 0 {
 			c.UI.Info(fmt.Sprintf("Ignoring invalid targets: %s", strings.Join(invalidTargets, ", ")))
 			c.flagTargets = strutil.Difference(c.flagTargets, invalidTargets, true)
@@ -515,6 +621,12 @@ func (c *DebugCommand) defaultTargets() []string {
 	return []string{"config", "host", "requests", "metrics", "pprof", "replication-status", "server-status", "log"}
 }
 
+// optionalTargets returns the targets that are only captured if specified
+// explicitly.
+func (c *DebugCommand) optionalTargets() []string {
+	return []string{"raft", "mounts"}
+}
+
 func (c *DebugCommand) validDRSecondaryTargets() []string {
 	return []string{"metrics", "replication-status", "server-status"}
 }
@@ -597,6 +709,36 @@ func (c *DebugCommand) capturePollingTargets() error {
 		})
 	}
 
+	// Collect goroutine profiles at their own interval if requested
+	if strutil.StrListContains(c.flagTargets, "pprof") && c.flagGoroutineInterval > 0 {
+		g.Add(func() error {
+			c.collectGoroutines(ctx)
+			return nil
+		}, func(error) {
+			cancelFunc()
+		})
+	}
+
+	// Collect raft and autopilot state if target is specified
+	if strutil.StrListContains(c.flagTargets, "raft") {
+		g.Add(func() error {
+			c.collectRaftState(ctx)
+			return nil
+		}, func(error) {
+			cancelFunc()
+		})
+	}
+
+	// Collect per-mount metrics if target is specified
+	if strutil.StrListContains(c.flagTargets, "mounts") {
+		g.Add(func() error {
+			c.collectMountMetrics(ctx)
+			return nil
+		}, func(error) {
+			cancelFunc()
+		})
+	}
+
 	// Collect replication status if target is specified
 	if strutil.StrListContains(c.flagTargets, "replication-status") {
 		g.Add(func() error {
@@ -661,6 +803,12 @@ func (c *DebugCommand) capturePollingTargets() error {
 	if err := c.persistCollection(c.inFlightReqStatusCollection, "requests.json"); err != nil {
 		c.UI.Error(fmt.Sprintf("Error writing data to %s: %v", "requests.json", err))
 	}
+	if err := c.persistCollection(c.raftCollection, "raft.json"); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing data to %s: %v", "raft.json", err))
+	}
+	if err := c.persistCollection(c.mountMetricsCollection, "mount_metrics.json"); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing data to %s: %v", "mount_metrics.json", err))
+	}
 	return nil
 }
 
@@ -845,6 +993,166 @@ func (c *DebugCommand) collectPprof(ctx context.Context) {
 	}
 }
 
+// collectGoroutines captures goroutine profiles every goroutine interval,
+// in the same per-timestamp directories as the other profiles.
+func (c *DebugCommand) collectGoroutines(ctx context.Context) {
+	idxCount := 0
+	intervalTicker := time.Tick(c.flagGoroutineInterval)
+
+	for {
+		// The first frame is captured by collectPprof
+		select {
+		case <-ctx.Done():
+			return
+		case <-intervalTicker:
+		}
+
+		currentTimestamp := time.Now().UTC()
+		c.logger.Info("capturing goroutine profiles", "count", idxCount)
+		idxCount++
+
+		dirName := filepath.Join(c.flagOutput, currentTimestamp.Format(fileFriendlyTimeFormat))
+		if err := os.MkdirAll(dirName, 0o700); err != nil {
+			c.UI.Error(fmt.Sprintf("Error creating sub-directory for time interval: %s", err))
+			continue
+		}
+
+		data, err := pprofTarget(ctx, c.cachedClient, "goroutine", nil)
+		if err != nil {
+			c.captureError("pprof.goroutine", err)
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dirName, "goroutine.prof"), data, 0o600); err != nil {
+			c.captureError("pprof.goroutine", err)
+		}
+
+		data, err = pprofTarget(ctx, c.cachedClient, "goroutine", url.Values{"debug": []string{"2"}})
+		if err != nil {
+			c.captureError("pprof.goroutines-text", err)
+			continue
+		}
+		if err := ioutil.WriteFile(filepath.Join(dirName, "goroutines.txt"), data, 0o600); err != nil {
+			c.captureError("pprof.goroutines-text", err)
+		}
+	}
+}
+
+func (c *DebugCommand) collectRaftState(ctx context.Context) {
+	idxCount := 0
+	intervalTicker := time.Tick(c.flagInterval)
+
+	for {
+		if idxCount > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-intervalTicker:
+			}
+		}
+
+		c.logger.Info("capturing raft state", "count", idxCount)
+		idxCount++
+
+		// Stop polling if the server does not use raft storage
+		configuration, err := c.cachedClient.Logical().ReadWithContext(ctx, "sys/storage/raft/configuration")
+		if err != nil {
+			c.captureError("raft.configuration", err)
+			return
+		}
+		raftEntry := map[string]interface{}{
+			"timestamp": time.Now().UTC(),
+		}
+		if configuration != nil {
+			raftEntry["configuration"] = configuration.Data
+		}
+
+		autopilot, err := c.cachedClient.Logical().ReadWithContext(ctx, "sys/storage/raft/autopilot/state")
+		if err != nil {
+			c.captureError("raft.autopilot", err)
+		} else if autopilot != nil {
+			raftEntry["autopilot"] = autopilot.Data
+		}
+
+		c.raftCollection = append(c.raftCollection, raftEntry)
+	}
+}
+
+func (c *DebugCommand) collectMountMetrics(ctx context.Context) {
+	idxCount := 0
+	intervalTicker := time.Tick(c.flagMetricsInterval)
+
+	for {
+		if idxCount > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-intervalTicker:
+			}
+		}
+
+		c.logger.Info("capturing per-mount metrics", "count", idxCount)
+		idxCount++
+
+		r := c.cachedClient.NewRequest("GET", "/v1/sys/metrics")
+		resp, err := c.cachedClient.RawRequestWithContext(ctx, r)
+		if err != nil {
+			c.captureError("mounts", err)
+			continue
+		}
+		if resp != nil {
+			defer resp.Body.Close()
+
+			metricsEntry := make(map[string]interface{})
+			err := json.NewDecoder(resp.Body).Decode(&metricsEntry)
+			if err != nil {
+				c.captureError("mounts", err)
+				continue
+			}
+			c.mountMetricsCollection = append(c.mountMetricsCollection, map[string]interface{}{
+				"timestamp": time.Now().UTC(),
+				"mounts":    debugMountMetrics(metricsEntry),
+			})
+		}
+	}
+}
+
+// debugMountMetrics groups the gauges, counters and samples of a metrics
+// snapshot by the mount they belong to. Metrics are attributed to a mount by
+// their "mount_point" label, or by the mount path in the name of route
+// metrics, such as "vault.route.read.secret-". Other metrics are dropped.
+func debugMountMetrics(metrics map[string]interface{}) map[string]map[string][]interface{} {
+	mounts := make(map[string]map[string][]interface{})
+	for _, kind := range []string{"Gauges", "Counters", "Samples"} {
+		entries, _ := metrics[kind].([]interface{})
+		for _, raw := range entries {
+			entry, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			var mount string
+			if labels, ok := entry["Labels"].(map[string]interface{}); ok {
+				mount, _ = labels["mount_point"].(string)
+			}
+			if mount == "" {
+				name, _ := entry["Name"].(string)
+				if parts := strings.SplitN(name, ".", 4); len(parts) == 4 && parts[1] == "route" {
+					mount = parts[3]
+				}
+			}
+			if mount == "" {
+				continue
+			}
+
+			if mounts[mount] == nil {
+				mounts[mount] = make(map[string][]interface{})
+			}
+			mounts[mount][kind] = append(mounts[mount][kind], entry)
+		}
+	}
+	return mounts
+}
+
 func (c *DebugCommand) collectReplicationStatus(ctx context.Context) {
 	idxCount := 0
 	intervalTicker := time.Tick(c.flagInterval)
@@ -978,6 +1286,50 @@ func (c *DebugCommand) persistCollection(collection []map[string]interface{}, ou
 	return nil
 }
 
+// redactBundle applies the redaction rules to the text files captured in the
+// output directory, and records the files that were changed in the index.
+func (c *DebugCommand) redactBundle() error {
+	if len(c.redactRules) == 0 {
+		return nil
+	}
+
+	return filepath.Walk(c.flagOutput, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strutil.StrListContains(debugRedactExts, filepath.Ext(path)) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		redacted := redactDebugData(data, c.redactRules)
+		if string(redacted) == string(data) {
+			return nil
+		}
+		if err := ioutil.WriteFile(path, redacted, 0o600); err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(c.flagOutput, path)
+		if err != nil {
+			return err
+		}
+		c.debugIndex.Redacted = append(c.debugIndex.Redacted, relPath)
+		return nil
+	})
+}
+
+// redactDebugData replaces the strings matched by the rules in data.
+func redactDebugData(data []byte, rules []debugRedactRule) []byte {
+	for _, rule := range rules {
+		data = rule.re.ReplaceAll(data, []byte(rule.replacement))
+	}
+	return data
+}
+
 func (c *DebugCommand) compress(dst string) error {
 	if runtime.GOOS != "windows" {
 		defer osutil.Umask(osutil.Umask(0o077))
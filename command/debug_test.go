@@ -423,6 +423,180 @@ func TestDebugCommand_IndexFile(t *testing.T) {
 	}
 }
 
+func TestDebugCommand_OptionalTargets(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	ui, cmd := testDebugCommand(t)
+	cmd.client = client
+	cmd.skipTimingChecks = true
+
+	outputPath := filepath.Join(testDir, "optional")
+	args := []string{
+		"-compress=false",
+		"-duration=1s",
+		"-metrics-interval=1s",
+		fmt.Sprintf("-output=%s", outputPath),
+		"-target=raft",
+		"-target=mounts",
+	}
+
+	code := cmd.Run(args)
+	if exp := 0; code != exp {
+		t.Log(ui.ErrorWriter.String())
+		t.Fatalf("expected %d to be %d", code, exp)
+	}
+	if strings.Contains(ui.OutputWriter.String(), "Ignoring invalid targets") {
+		t.Fatalf("expected optional targets to be valid: %s", ui.OutputWriter.String())
+	}
+
+	if _, err := os.Stat(filepath.Join(outputPath, "mount_metrics.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The test server does not use raft storage, which is recorded as an
+	// error instead of aborting the capture.
+	content, err := ioutil.ReadFile(filepath.Join(outputPath, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := &debugIndex{}
+	if err := json.Unmarshal(content, index); err != nil {
+		t.Fatal(err)
+	}
+	var raftErr bool
+	for _, captureErr := range index.Errors {
+		if captureErr.Target == "raft.configuration" {
+			raftErr = true
+		}
+	}
+	if !raftErr {
+		t.Fatalf("expected a raft.configuration error, got: %v", index.Errors)
+	}
+}
+
+func TestDebugCommand_Redact(t *testing.T) {
+	t.Parallel()
+
+	testDir := t.TempDir()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	t.Run("invalid_rule", func(t *testing.T) {
+		ui, cmd := testDebugCommand(t)
+		cmd.client = client
+		cmd.skipTimingChecks = true
+
+		code := cmd.Run([]string{
+			"-duration=1s",
+			fmt.Sprintf("-output=%s", filepath.Join(testDir, "invalid")),
+			"-redact=[",
+		})
+		if exp := 1; code != exp {
+			t.Fatalf("expected %d to be %d", code, exp)
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), "invalid redaction rule") {
+			t.Fatalf("expected error about the redaction rule, got: %s", ui.ErrorWriter.String())
+		}
+	})
+
+	t.Run("custom_rule", func(t *testing.T) {
+		ui, cmd := testDebugCommand(t)
+		cmd.client = client
+		cmd.skipTimingChecks = true
+
+		outputPath := filepath.Join(testDir, "custom")
+		code := cmd.Run([]string{
+			"-compress=false",
+			"-duration=1s",
+			fmt.Sprintf("-output=%s", outputPath),
+			"-target=server-status",
+			`-redact="cluster_name":\s*"[^"]+"`,
+		})
+		if exp := 0; code != exp {
+			t.Log(ui.ErrorWriter.String())
+			t.Fatalf("expected %d to be %d", code, exp)
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(outputPath, "server_status.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(content), "cluster_name") || !strings.Contains(string(content), debugRedactedValue) {
+			t.Fatalf("expected cluster name to be redacted: %s", content)
+		}
+
+		content, err = ioutil.ReadFile(filepath.Join(outputPath, "index.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		index := &debugIndex{}
+		if err := json.Unmarshal(content, index); err != nil {
+			t.Fatal(err)
+		}
+		if len(index.Redacted) != 1 || index.Redacted[0] != "server_status.json" {
+			t.Fatalf("expected server_status.json to be listed as redacted, got: %v", index.Redacted)
+		}
+	})
+}
+
+func TestDebugCommand_RedactDefaults(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		`token hvs.CAESIJlWh6ZBnUZWbpZoMqr6PqsVWL0pcr5HSDFhA3YBDmWAGh4KHGh2cy5w`: `token [redacted]`,
+		`legacy s.Ad3kqvbfAtQcXk7cgd7S9ALj used`:                                 `legacy [redacted] used`,
+		`{"password": "hunter2", "username": "bob"}`:                             `{"password": "[redacted]", "username": "bob"}`,
+		`{"aws_secret_key":"abc","client_token":"def"}`:                          `{"aws_secret_key":"[redacted]","client_token":"[redacted]"}`,
+		`{"mount_point": "secret/"}`:                                             `{"mount_point": "secret/"}`,
+	}
+	for in, exp := range cases {
+		if got := string(redactDebugData([]byte(in), debugDefaultRedactRules)); got != exp {
+			t.Errorf("expected %q to be redacted to %q, got %q", in, exp, got)
+		}
+	}
+}
+
+func TestDebugMountMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := map[string]interface{}{
+		"Gauges": []interface{}{
+			map[string]interface{}{
+				"Name":   "vault.kv.secret.count",
+				"Value":  3,
+				"Labels": map[string]interface{}{"mount_point": "secret/"},
+			},
+			map[string]interface{}{
+				"Name":   "vault.runtime.num_goroutines",
+				"Value":  100,
+				"Labels": map[string]interface{}{},
+			},
+		},
+		"Samples": []interface{}{
+			map[string]interface{}{
+				"Name": "vault.route.read.secret-",
+			},
+		},
+	}
+
+	mounts := debugMountMetrics(metrics)
+	if len(mounts) != 2 {
+		t.Fatalf("expected 2 mounts, got: %v", mounts)
+	}
+	if len(mounts["secret/"]["Gauges"]) != 1 {
+		t.Fatalf("expected a gauge for secret/, got: %v", mounts["secret/"])
+	}
+	if len(mounts["secret-"]["Samples"]) != 1 {
+		t.Fatalf("expected a sample for secret-, got: %v", mounts["secret-"])
+	}
+}
+
 func TestDebugCommand_TimingChecks(t *testing.T) {
 	t.Parallel()
 
@@ -99,6 +99,41 @@ func Detailed(ui cli.Ui) bool {
 	return false
 }
 
+// Columns returns the fields that table output should be limited to, in the
+// order they should be shown. An empty result means all fields are shown.
+func Columns(ui cli.Ui) []string {
+	switch ui := ui.(type) {
+	case *VaultUI:
+		return ui.columns
+	}
+
+	return nil
+}
+
+// filterColumns limits Key/Value table rows to the keys given in columns, in
+// the order of columns. Rows are expected to be formatted as "key <delim>
+// value".
+func filterColumns(rows []string, columns []string) []string {
+	if len(columns) == 0 {
+		return rows
+	}
+
+	byKey := make(map[string]string, len(rows))
+	for _, row := range rows {
+		key, _, _ := strings.Cut(row, hopeDelim)
+		key = strings.TrimSuffix(strings.TrimSpace(key), ":")
+		byKey[key] = row
+	}
+
+	filtered := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if row, ok := byKey[column]; ok {
+			filtered = append(filtered, row)
+		}
+	}
+	return filtered
+}
+
 // An output formatter for json output of an object
 type JsonFormatter struct{}
 
@@ -419,9 +454,12 @@ func (t TableFormatter) OutputList(ui cli.Ui, secret *api.Secret, data interface
 	t.printWarnings(ui, secret)
 
 	// Determine if we have additional information from a ListResponseWithInfo endpoint.
+	// Asking for specific columns implies that the additional information
+	// should be shown.
+	columns := Columns(ui)
 	var additionalInfo map[string]interface{}
 	if secret != nil {
-		shouldListWithInfo := Detailed(ui)
+		shouldListWithInfo := Detailed(ui) || len(columns) > 0
 		if additional, ok := secret.Data["key_info"]; shouldListWithInfo && ok && len(additional.(map[string]interface{})) > 0 {
 			additionalInfo = additional.(map[string]interface{})
 		}
@@ -475,12 +513,22 @@ func (t TableFormatter) OutputList(ui cli.Ui, secret *api.Secret, data interface
 				}
 			}
 
-			for key := range seenHeaders {
-				headers = append(headers, key)
+			if len(columns) > 0 {
+				for _, column := range columns {
+					if seenHeaders[column] {
+						headers = append(headers, column)
+					}
+				}
+			} else {
+				for key := range seenHeaders {
+					headers = append(headers, key)
+				}
+				sort.Strings(headers)
 			}
-			sort.Strings(headers)
 
-			header = header + hopeDelim + strings.Join(headers, hopeDelim)
+			if len(headers) > 0 {
+				header = header + hopeDelim + strings.Join(headers, hopeDelim)
+			}
 		}
 
 		// Finally, if we have a ListResponseWithInfo, we'll need to update
@@ -615,6 +663,8 @@ func (t TableFormatter) OutputSecret(ui cli.Ui, secret *api.Secret) error {
 		}
 	}
 
+	out = filterColumns(out, Columns(ui))
+
 	// If we got this far and still don't have any data, there's nothing to print,
 	// sorry.
 	if len(out) == 0 {
@@ -651,6 +701,8 @@ func (t TableFormatter) OutputMap(ui cli.Ui, data map[string]interface{}) error
 		}
 	}
 
+	out = filterColumns(out, Columns(ui))
+
 	// If we got this far and still don't have any data, there's nothing to print,
 	// sorry.
 	if len(out) == 0 {
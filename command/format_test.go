@@ -88,6 +88,66 @@ func TestTableFormatter(t *testing.T) {
 	}
 }
 
+func TestTableFormatter_Columns(t *testing.T) {
+	var output string
+	ui := &VaultUI{
+		Ui:      mockUi{t: t, outputData: &output},
+		format:  "table",
+		columns: []string{"c", "a"},
+	}
+
+	// Secrets only show the requested keys, in the requested order
+	s := &api.Secret{Data: map[string]interface{}{"a": "one", "b": "two", "c": "three"}}
+	if err := outputWithFormat(ui, s, s); err != 0 {
+		t.Fatal(err)
+	}
+	if strings.Contains(output, "two") {
+		t.Fatalf("expected b to be filtered from output:\n%s", output)
+	}
+	if strings.Index(output, "three") > strings.Index(output, "one") {
+		t.Fatalf("expected c to be printed before a:\n%s", output)
+	}
+
+	// Lists show the requested key_info columns without -detailed
+	l := &api.Secret{Data: map[string]interface{}{
+		"keys": []interface{}{"foo"},
+		"key_info": map[string]interface{}{
+			"foo": map[string]interface{}{"a": "one", "b": "two", "c": "three"},
+		},
+	}}
+	if code := OutputList(ui, l); code != 0 {
+		t.Fatal(code)
+	}
+	header := strings.Fields(strings.Split(output, "\n")[0])
+	if expected := []string{"Keys", "c", "a"}; strings.Join(header, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected header %v, got %v", expected, header)
+	}
+}
+
+func TestSetupColumns(t *testing.T) {
+	defer os.Setenv(EnvVaultColumns, os.Getenv(EnvVaultColumns))
+
+	os.Setenv(EnvVaultColumns, "env")
+	cases := map[string]struct {
+		args     []string
+		expected []string
+	}{
+		"none":     {[]string{"read", "foo"}, []string{"env"}},
+		"equals":   {[]string{"read", "-columns=a,b", "foo"}, []string{"a", "b"}},
+		"separate": {[]string{"read", "-columns", "a", "foo"}, []string{"a"}},
+		"repeated": {[]string{"read", "-columns=a", "--columns=b", "foo"}, []string{"a", "b"}},
+		"after_dd": {[]string{"read", "--", "-columns=a"}, []string{"env"}},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			actual := setupColumns(tc.args)
+			if strings.Join(actual, ",") != strings.Join(tc.expected, ",") {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
 // TestStatusFormat tests to verify that the embedded struct
 // SealStatusOutput ignores omitEmpty fields and prints out
 // fields in the embedded struct explicitly. It also checks the spacing,
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
 	"strings"
@@ -289,6 +290,12 @@ func (c *KVPatchCommand) readThenWrite(client *api.Client, path string, newData
 	client.SetOutputCurlString(curOutputCurl)
 	client.SetOutputPolicy(outputPolicy)
 
+	// The write depends on the pre-read, so the generated policy must allow
+	// it as well.
+	if outputPolicy {
+		client.AddOutputPolicyPrerequisite(http.MethodGet, path, nil)
+	}
+
 	// Make sure a value already exists
 	if secret == nil || secret.Data == nil {
 		c.UI.Error(fmt.Sprintf("No value found at %s", path))
@@ -242,7 +242,8 @@ func (c *KVRollbackCommand) Run(args []string) int {
 
 		// Verify it hasn't been deleted
 		if meta["deletion_time"] != nil && meta["deletion_time"].(string) != "" {
-			c.UI.Error("Cannot roll back to a version that has been deleted")
+			c.UI.Error(fmt.Sprintf("Cannot roll back to a version that has been deleted, "+
+				"use \"vault kv undelete -versions=%d\" to restore it first", c.flagVersion))
 			return 2
 		}
 
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -851,6 +852,138 @@ func TestKVMetadataGetCommand(t *testing.T) {
 	})
 }
 
+func testKVUndeleteCommand(tb testing.TB) (*cli.MockUi, *KVUndeleteCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &KVUndeleteCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func TestKVUndeleteCommand(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+	if err := client.Sys().Mount("kv/", &api.MountInput{
+		Type: "kv-v2",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Give time for the upgrade code to run/finish
+	time.Sleep(time.Second)
+
+	for i := 0; i < 4; i++ {
+		if _, err := client.Logical().Write("kv/data/foo", map[string]interface{}{
+			"data": map[string]interface{}{
+				"foo": fmt.Sprintf("bar%d", i),
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deleteVersions := func(t *testing.T, versions ...int) {
+		t.Helper()
+		if _, err := client.Logical().Write("kv/delete/foo", map[string]interface{}{
+			"versions": versions,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deletedVersions := func(t *testing.T) []string {
+		t.Helper()
+		versions, err := kvDeletedVersions(client, "kv/metadata/foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return versions
+	}
+
+	deleteVersions(t, 1, 2, 3)
+	if _, err := client.Logical().Write("kv/destroy/foo", map[string]interface{}{
+		"versions": []int{3},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if exp, act := []string{"1", "2"}, deletedVersions(t); !reflect.DeepEqual(exp, act) {
+		t.Fatalf("expected deleted versions %q, got %q", exp, act)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"all_and_versions",
+			[]string{"-all", "-versions=1", "kv/foo"},
+			"cannot be used together",
+			1,
+		},
+		{
+			"invalid_range",
+			[]string{"-versions=3-1", "kv/foo"},
+			"Invalid version range",
+			1,
+		},
+		{
+			"all",
+			[]string{"-all", "kv/foo"},
+			"Undeleted versions: 1, 2",
+			0,
+		},
+		{
+			"all_nothing_deleted",
+			[]string{"-mount=kv", "-all", "foo"},
+			"No deleted versions found",
+			0,
+		},
+	}
+
+	// The cases build on each other, so they run in order.
+	for _, tc := range cases {
+		ui, cmd := testKVUndeleteCommand(t)
+		cmd.client = client
+
+		code := cmd.Run(tc.args)
+		if code != tc.code {
+			t.Errorf("%s: expected %d to be %d", tc.name, code, tc.code)
+		}
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, tc.out) {
+			t.Errorf("%s: expected %q to contain %q", tc.name, combined, tc.out)
+		}
+	}
+	if act := deletedVersions(t); len(act) != 0 {
+		t.Fatalf("expected no deleted versions, got %q", act)
+	}
+
+	t.Run("range", func(t *testing.T) {
+		deleteVersions(t, 1, 2, 4)
+
+		ui, cmd := testKVUndeleteCommand(t)
+		cmd.client = client
+
+		if code := cmd.Run([]string{"-versions=1-2", "kv/foo"}); code != 0 {
+			t.Fatalf("expected 0, got %d: %s", code, ui.ErrorWriter.String())
+		}
+		if exp, act := []string{"4"}, deletedVersions(t); !reflect.DeepEqual(exp, act) {
+			t.Errorf("expected deleted versions %q, got %q", exp, act)
+		}
+	})
+
+	t.Run("no_tabs", func(t *testing.T) {
+		_, cmd := testKVUndeleteCommand(t)
+		assertNoTabs(t, cmd)
+	})
+}
+
 func testKVPatchCommand(tb testing.TB) (*cli.MockUi, *KVPatchCommand) {
 	tb.Helper()
 
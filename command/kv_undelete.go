@@ -5,8 +5,12 @@ package command
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/vault/api"
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 )
@@ -20,6 +24,7 @@ type KVUndeleteCommand struct {
 	*BaseCommand
 
 	flagVersions []string
+	flagAll      bool
 	flagMount    string
 }
 
@@ -38,6 +43,14 @@ Usage: vault kv undelete [options] KEY
   
       $ vault kv undelete -mount=secret -versions=3 foo
 
+  To undelete versions 3 through 7 of key "foo":
+
+      $ vault kv undelete -mount=secret -versions=3-7 foo
+
+  To undelete every deleted version of key "foo" that has not been destroyed:
+
+      $ vault kv undelete -mount=secret -all foo
+
   The deprecated path-like syntax can also be used, but this should be avoided, 
   as the fact that it is not actually the full API path to 
   the secret (secret/data/foo) can cause confusion: 
@@ -60,7 +73,16 @@ func (c *KVUndeleteCommand) Flags() *FlagSets {
 		Name:    "versions",
 		Target:  &c.flagVersions,
 		Default: nil,
-		Usage:   `Specifies the version numbers to undelete.`,
+		Usage: `Specifies the version numbers to undelete. Ranges of versions
+		can be given as "3-7".`,
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:    "all",
+		Target:  &c.flagAll,
+		Default: false,
+		Usage: `Undelete every version that has been deleted but not destroyed,
+		as listed in the metadata of the secret.`,
 	})
 
 	f.StringVar(&StringVar{
@@ -103,11 +125,21 @@ func (c *KVUndeleteCommand) Run(args []string) int {
 		return 1
 	}
 
-	if len(c.flagVersions) == 0 {
+	switch {
+	case c.flagAll && len(c.flagVersions) > 0:
+		c.UI.Error("The \"-all\" and \"-versions\" flags cannot be used together.")
+		return 1
+	case !c.flagAll && len(c.flagVersions) == 0:
 		c.UI.Error("No versions provided, use the \"-versions\" flag to specify the version to undelete.")
 		return 1
 	}
 
+	versions, err := kvExpandVersionRanges(kvParseVersionsFlags(c.flagVersions))
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	client, err := c.Client()
 	if err != nil {
 		c.UI.Error(err.Error())
@@ -150,9 +182,24 @@ func (c *KVUndeleteCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.flagAll {
+		metadataPath := addPrefixToKVPath(partialPath, mountPath, "metadata", false)
+		versions, err = kvDeletedVersions(client, metadataPath)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 2
+		}
+		if len(versions) == 0 {
+			if Format(c.UI) == "table" {
+				c.UI.Info(fmt.Sprintf("No deleted versions found at %s", metadataPath))
+			}
+			return 0
+		}
+	}
+
 	undeletePath := addPrefixToKVPath(partialPath, mountPath, "undelete", false)
 	data := map[string]interface{}{
-		"versions": kvParseVersionsFlags(c.flagVersions),
+		"versions": versions,
 	}
 
 	secret, err := client.Logical().Write(undeletePath, data)
@@ -167,9 +214,89 @@ func (c *KVUndeleteCommand) Run(args []string) int {
 		// Don't output anything unless using the "table" format
 		if Format(c.UI) == "table" {
 			c.UI.Info(fmt.Sprintf("Success! Data written to: %s", undeletePath))
+			if c.flagAll {
+				c.UI.Info(fmt.Sprintf("Undeleted versions: %s", strings.Join(versions, ", ")))
+			}
 		}
 		return 0
 	}
 
 	return OutputSecret(c.UI, secret)
 }
+
+// kvExpandVersionRanges expands version ranges such as "3-7" into the
+// individual versions they cover.
+func kvExpandVersionRanges(versions []string) ([]string, error) {
+	out := make([]string, 0, len(versions))
+	for _, v := range versions {
+		from, to, isRange := strings.Cut(v, "-")
+		if !isRange {
+			out = append(out, v)
+			continue
+		}
+
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid version range %q: %s", v, err)
+		}
+		end, err := strconv.Atoi(strings.TrimSpace(to))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid version range %q: %s", v, err)
+		}
+		if start < 1 || end < start {
+			return nil, fmt.Errorf("Invalid version range %q: the range must be ascending and start at version 1 or later", v)
+		}
+		for i := start; i <= end; i++ {
+			out = append(out, strconv.Itoa(i))
+		}
+	}
+	return out, nil
+}
+
+// kvDeletedVersions returns the versions of a secret that have been deleted
+// but not destroyed, in ascending order. Versions that are only scheduled to
+// be deleted by delete_version_after are not included.
+func kvDeletedVersions(client *api.Client, metadataPath string) ([]string, error) {
+	secret, err := client.Logical().Read(metadataPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading metadata at %s: %s", metadataPath, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("No metadata found at %s", metadataPath)
+	}
+
+	rawVersions, ok := secret.Data["versions"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Metadata found at %s has no versions", metadataPath)
+	}
+
+	var deleted []int
+	for v, rawMeta := range rawVersions {
+		meta, ok := rawMeta.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if destroyed, _ := meta["destroyed"].(bool); destroyed {
+			continue
+		}
+		deletionTime, _ := meta["deletion_time"].(string)
+		if deletionTime == "" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339Nano, deletionTime); err == nil && t.After(time.Now()) {
+			continue
+		}
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		deleted = append(deleted, version)
+	}
+	sort.Ints(deleted)
+
+	out := make([]string, 0, len(deleted))
+	for _, v := range deleted {
+		out = append(out, strconv.Itoa(v))
+	}
+	return out, nil
+}
@@ -30,6 +30,7 @@ type LoginCommand struct {
 	flagNoStore   bool
 	flagNoPrint   bool
 	flagTokenOnly bool
+	flagFlow      string
 
 	testStdin io.Reader // for tests
 }
@@ -131,6 +132,16 @@ func (c *LoginCommand) Flags() *FlagSets {
 			"values will have no affect.",
 	})
 
+	f.StringVar(&StringVar{
+		Name:    "flow",
+		Target:  &c.flagFlow,
+		Default: "",
+		Usage: "Login flow to use for auth methods that support more than one. " +
+			"The oidc method supports \"browser\" (default), which waits for a " +
+			"callback on localhost, and \"device\", which prints a verification " +
+			"URL and code to complete the login on any device.",
+	})
+
 	return set
 }
 
@@ -206,6 +217,14 @@ func (c *LoginCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.flagFlow != "" {
+		if authMethod != "oidc" {
+			c.UI.Error(fmt.Sprintf("The -flow flag is not supported by the %s auth method", authMethod))
+			return 1
+		}
+		config["flow"] = c.flagFlow
+	}
+
 	// If the user did not specify a mount path, use the provided mount path.
 	if config["mount"] == "" && authPath != "" {
 		config["mount"] = authPath
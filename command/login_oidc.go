@@ -0,0 +1,324 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	credOIDC "github.com/hashicorp/vault-plugin-auth-jwt"
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	oidcFlowBrowser = "browser"
+	oidcFlowDevice  = "device"
+
+	// oidcDeviceGrantType is the grant type of the OAuth 2.0 device
+	// authorization grant, see RFC 8628.
+	oidcDeviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+	oidcDeviceDefaultInterval = 5 * time.Second
+)
+
+// oidcCLIHandler extends the CLI handler of the OIDC auth method with the
+// device authorization flow. The browser flow, which needs a callback
+// listener on localhost, is left to the plugin's handler.
+type oidcCLIHandler struct {
+	credOIDC.CLIHandler
+
+	// httpClient is used to talk to the OIDC provider. Tests may override it.
+	httpClient *http.Client
+}
+
+func (h *oidcCLIHandler) Auth(c *api.Client, m map[string]string) (*api.Secret, error) {
+	flow := m["flow"]
+	delete(m, "flow")
+
+	switch flow {
+	case "", oidcFlowBrowser:
+		return h.CLIHandler.Auth(c, m)
+	case oidcFlowDevice:
+		return h.deviceAuth(c, m)
+	default:
+		return nil, fmt.Errorf("unsupported OIDC login flow %q, must be %q or %q", flow, oidcFlowBrowser, oidcFlowDevice)
+	}
+}
+
+// oidcDeviceAuthResponse is the response of the device authorization
+// endpoint of the provider.
+type oidcDeviceAuthResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// oidcTokenResponse is the response of the token endpoint of the provider,
+// either on success or on error.
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// deviceAuth runs the OAuth 2.0 device authorization grant against the OIDC
+// provider and logs in to Vault with the resulting ID token. The ID token is
+// validated by a role of type "jwt" on the same mount, since the "oidc" role
+// type only supports the redirect based flow.
+func (h *oidcCLIHandler) deviceAuth(c *api.Client, m map[string]string) (*api.Secret, error) {
+	mount, ok := m["mount"]
+	if !ok {
+		mount = "oidc"
+	}
+
+	discoveryURL := m["oidc_discovery_url"]
+	if discoveryURL == "" {
+		return nil, errors.New("'oidc_discovery_url' must be specified for the device flow")
+	}
+	clientID := m["client_id"]
+	if clientID == "" {
+		return nil, errors.New("'client_id' must be specified for the device flow")
+	}
+
+	scopes := []string{"openid"}
+	if raw := m["scopes"]; raw != "" {
+		scopes = scopes[:0]
+		for _, scope := range strings.Split(raw, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	// handle ctrl-c while waiting for the user to complete the login
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	deviceEndpoint, tokenEndpoint, err := h.discoverDeviceEndpoints(ctx, discoveryURL)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"client_id": {clientID},
+		"scope":     {strings.Join(scopes, " ")},
+	}
+	if secret := m["client_secret"]; secret != "" {
+		form.Set("client_secret", secret)
+	}
+
+	var deviceAuth oidcDeviceAuthResponse
+	if err := h.postForm(ctx, deviceEndpoint, form, &deviceAuth); err != nil {
+		return nil, fmt.Errorf("error starting device authorization: %w", err)
+	}
+	if deviceAuth.DeviceCode == "" || deviceAuth.VerificationURI == "" {
+		return nil, errors.New("provider returned an incomplete device authorization response")
+	}
+
+	fmt.Fprintf(os.Stderr, "Complete the login via your OIDC provider. Open the following link in a browser on any device:\n\n    %s\n\nand enter the code:\n\n    %s\n\n", deviceAuth.VerificationURI, deviceAuth.UserCode)
+	if deviceAuth.VerificationURIComplete != "" {
+		fmt.Fprintf(os.Stderr, "Alternatively, open the following link which already contains the code:\n\n    %s\n\n", deviceAuth.VerificationURIComplete)
+	}
+	fmt.Fprintf(os.Stderr, "Waiting for OIDC authentication to complete...\n")
+
+	idToken, err := h.pollDeviceToken(ctx, tokenEndpoint, form, &deviceAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := c.Logical().Write(path.Join("auth", mount, "login"), map[string]interface{}{
+		"role": m["role"],
+		"jwt":  idToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, errors.New("empty response from credential provider")
+	}
+
+	return secret, nil
+}
+
+// discoverDeviceEndpoints reads the device authorization and token endpoints
+// from the discovery document of the provider.
+func (h *oidcCLIHandler) discoverDeviceEndpoints(ctx context.Context, discoveryURL string) (string, string, error) {
+	wellKnown := strings.TrimSuffix(discoveryURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("error fetching provider discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("error fetching provider discovery document: unexpected status %d", resp.StatusCode)
+	}
+
+	var discovery struct {
+		DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+		TokenEndpoint               string `json:"token_endpoint"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return "", "", fmt.Errorf("error decoding provider discovery document: %w", err)
+	}
+	if discovery.DeviceAuthorizationEndpoint == "" {
+		return "", "", errors.New("provider does not support the device authorization flow")
+	}
+	if discovery.TokenEndpoint == "" {
+		return "", "", errors.New("provider discovery document has no token endpoint")
+	}
+
+	return discovery.DeviceAuthorizationEndpoint, discovery.TokenEndpoint, nil
+}
+
+// pollDeviceToken polls the token endpoint until the user has completed the
+// login, the device code expired or the provider returned an error.
+func (h *oidcCLIHandler) pollDeviceToken(ctx context.Context, tokenEndpoint string, form url.Values, deviceAuth *oidcDeviceAuthResponse) (string, error) {
+	interval := oidcDeviceDefaultInterval
+	if deviceAuth.Interval > 0 {
+		interval = time.Duration(deviceAuth.Interval) * time.Second
+	}
+	expiresIn := 2 * time.Minute
+	if deviceAuth.ExpiresIn > 0 {
+		expiresIn = time.Duration(deviceAuth.ExpiresIn) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, expiresIn)
+	defer cancel()
+
+	tokenForm := url.Values{}
+	for k, v := range form {
+		if k != "scope" {
+			tokenForm[k] = v
+		}
+	}
+	tokenForm.Set("grant_type", oidcDeviceGrantType)
+	tokenForm.Set("device_code", deviceAuth.DeviceCode)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", errors.New("Timed out waiting for response from provider")
+			}
+			return "", errors.New("Interrupted")
+		case <-time.After(interval):
+		}
+
+		var token oidcTokenResponse
+		err := h.postForm(ctx, tokenEndpoint, tokenForm, &token)
+		switch {
+		case err != nil && token.Error == "":
+			if ctx.Err() != nil {
+				continue
+			}
+			return "", fmt.Errorf("error polling for the ID token: %w", err)
+		case token.Error == "authorization_pending":
+			continue
+		case token.Error == "slow_down":
+			interval += 5 * time.Second
+			continue
+		case token.Error != "":
+			if token.ErrorDescription != "" {
+				return "", fmt.Errorf("provider returned error %q: %s", token.Error, token.ErrorDescription)
+			}
+			return "", fmt.Errorf("provider returned error %q", token.Error)
+		case token.IDToken == "":
+			return "", errors.New("provider did not return an ID token, make sure the \"openid\" scope is requested")
+		default:
+			return token.IDToken, nil
+		}
+	}
+}
+
+// postForm posts form to endpoint and decodes the JSON response into out.
+// Error responses are decoded as well, as OAuth 2.0 returns error details in
+// the body.
+func (h *oidcCLIHandler) postForm(ctx context.Context, endpoint string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("error decoding response with status %d: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (h *oidcCLIHandler) client() *http.Client {
+	if h.httpClient != nil {
+		return h.httpClient
+	}
+	return cleanhttp.DefaultClient()
+}
+
+func (h *oidcCLIHandler) Help() string {
+	help := h.CLIHandler.Help() + `
+
+  flow=<string>
+    Login flow to use, either "browser" (default) or "device". The -flow flag
+    of "vault login" sets this value.
+
+Device flow:
+
+  The device flow prints a verification URL and code that can be opened in a
+  browser on any device, and polls the OIDC provider until the login has been
+  completed. It does not need a callback listener on localhost, so it can be
+  used over SSH and in containers. The resulting ID token is used to log in
+  with a role of type "jwt" on the same mount, which must bind the audience
+  to the client ID.
+
+      $ vault login -method=oidc -flow=device role=engineering-cli \
+          oidc_discovery_url=https://myco.auth0.com/ client_id=abc123
+
+  role=<string>
+    Vault role of type "JWT" to use for authentication.
+
+  oidc_discovery_url=<string>
+    The OIDC discovery URL of the provider, without any .well-known component.
+
+  client_id=<string>
+    The client ID of an application allowed to use the device flow.
+
+  client_secret=<string>
+    Optional client secret, for providers that require it in the device flow.
+
+  scopes=<string>
+    Optional comma separated list of scopes to request (default: openid).`
+
+	return strings.TrimSpace(help)
+}
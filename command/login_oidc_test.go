@@ -0,0 +1,183 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+)
+
+// testOIDCDeviceProvider starts a provider that returns authorization_pending
+// for the given number of polls before issuing the ID token.
+func testOIDCDeviceProvider(t *testing.T, pending int32, tokenErr string) *httptest.Server {
+	t.Helper()
+
+	var polls int32
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"device_authorization_endpoint": srv.URL + "/device",
+				"token_endpoint":                srv.URL + "/token",
+			})
+		case "/device":
+			if r.FormValue("client_id") != "vault-cli" || r.FormValue("scope") != "openid profile" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_request"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"device_code":      "device-code",
+				"user_code":        "ABCD-EFGH",
+				"verification_uri": srv.URL + "/activate",
+				"expires_in":       60,
+				"interval":         1,
+			})
+		case "/token":
+			if r.FormValue("grant_type") != oidcDeviceGrantType || r.FormValue("device_code") != "device-code" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+				return
+			}
+			if atomic.AddInt32(&polls, 1) <= pending {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+				return
+			}
+			if tokenErr != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": tokenErr})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"id_token": "id-token"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// testOIDCDeviceVault starts a fake Vault server that accepts a JWT login
+// with the ID token issued by testOIDCDeviceProvider.
+func testOIDCDeviceVault(t *testing.T) *api.Client {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.URL.Path != "/v1/auth/oidc/login" || body["jwt"] != "id-token" || body["role"] != "cli" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {"bad login"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": "s.device"},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	config := api.DefaultConfig()
+	config.Address = srv.URL
+	client, err := api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestOIDCCLIHandler_DeviceFlow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		provider := testOIDCDeviceProvider(t, 1, "")
+		client := testOIDCDeviceVault(t)
+
+		h := &oidcCLIHandler{}
+		secret, err := h.Auth(client, map[string]string{
+			"flow":               "device",
+			"mount":              "oidc/",
+			"role":               "cli",
+			"oidc_discovery_url": provider.URL,
+			"client_id":          "vault-cli",
+			"scopes":             "openid,profile",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if secret.Auth == nil || secret.Auth.ClientToken != "s.device" {
+			t.Errorf("unexpected secret %#v", secret)
+		}
+	})
+
+	t.Run("access_denied", func(t *testing.T) {
+		t.Parallel()
+
+		provider := testOIDCDeviceProvider(t, 0, "access_denied")
+		client := testOIDCDeviceVault(t)
+
+		h := &oidcCLIHandler{}
+		_, err := h.Auth(client, map[string]string{
+			"flow":               "device",
+			"role":               "cli",
+			"oidc_discovery_url": provider.URL,
+			"client_id":          "vault-cli",
+			"scopes":             "openid,profile",
+		})
+		if err == nil || !strings.Contains(err.Error(), "access_denied") {
+			t.Fatalf("expected access_denied error, got %v", err)
+		}
+	})
+
+	t.Run("missing_client_id", func(t *testing.T) {
+		t.Parallel()
+
+		h := &oidcCLIHandler{}
+		_, err := h.Auth(nil, map[string]string{
+			"flow":               "device",
+			"oidc_discovery_url": "https://example.com",
+		})
+		if err == nil || !strings.Contains(err.Error(), "client_id") {
+			t.Fatalf("expected client_id error, got %v", err)
+		}
+	})
+
+	t.Run("unknown_flow", func(t *testing.T) {
+		t.Parallel()
+
+		h := &oidcCLIHandler{}
+		_, err := h.Auth(nil, map[string]string{"flow": "implicit"})
+		if err == nil || !strings.Contains(err.Error(), "unsupported OIDC login flow") {
+			t.Fatalf("expected unsupported flow error, got %v", err)
+		}
+	})
+}
+
+func TestLoginCommand_FlowUnsupportedMethod(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	ui, cmd := testLoginCommand(t)
+	cmd.client = client
+
+	code := cmd.Run([]string{"-method=userpass", "-flow=device", "username=foo"})
+	if exp := 1; code != exp {
+		t.Errorf("expected %d to be %d", code, exp)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "-flow flag is not supported") {
+		t.Errorf("unexpected error %q", ui.ErrorWriter.String())
+	}
+}
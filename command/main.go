@@ -25,6 +25,7 @@ type VaultUI struct {
 	cli.Ui
 	format   string
 	detailed bool
+	columns  []string
 }
 
 const (
@@ -32,10 +33,11 @@ const (
 	globalFlagOutputPolicy     = "output-policy"
 	globalFlagFormat           = "format"
 	globalFlagDetailed         = "detailed"
+	globalFlagColumns          = "columns"
 )
 
 var globalFlags = []string{
-	globalFlagOutputCurlString, globalFlagOutputPolicy, globalFlagFormat, globalFlagDetailed,
+	globalFlagOutputCurlString, globalFlagOutputPolicy, globalFlagFormat, globalFlagDetailed, globalFlagColumns,
 }
 
 // setupEnv parses args and may replace them and sets some env vars to known
@@ -119,6 +121,49 @@ func setupEnv(args []string) (retArgs []string, format string, detailed bool, ou
 	return args, format, detailed, outputCurlString, outputPolicy
 }
 
+// setupColumns parses the columns to show in table output from args, falling
+// back to the VAULT_COLUMNS env var. Columns may be given as a comma separated
+// list, or by specifying the flag multiple times.
+func setupColumns(args []string) []string {
+	var columns []string
+	var nextArgColumns bool
+	var haveColumns bool
+
+	addColumns := func(value string) {
+		haveColumns = true
+		for _, column := range strings.Split(value, ",") {
+			if column = strings.TrimSpace(column); column != "" {
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	for _, arg := range args {
+		if nextArgColumns {
+			nextArgColumns = false
+			addColumns(arg)
+			continue
+		}
+
+		if arg == "--" {
+			break
+		}
+
+		if isGlobalFlagWithValue(arg, globalFlagColumns) {
+			addColumns(getGlobalFlagValue(arg))
+		}
+		if isGlobalFlag(arg, globalFlagColumns) {
+			nextArgColumns = true
+		}
+	}
+
+	if envVaultColumns := os.Getenv(EnvVaultColumns); !haveColumns && envVaultColumns != "" {
+		addColumns(envVaultColumns)
+	}
+
+	return columns
+}
+
 func isGlobalFlag(arg string, flag string) bool {
 	return arg == "-"+flag || arg == "--"+flag
 }
@@ -157,6 +202,7 @@ func RunCustom(args []string, runOpts *RunOptions) int {
 	var outputCurlString bool
 	var outputPolicy bool
 	args, format, detailed, outputCurlString, outputPolicy = setupEnv(args)
+	columns := setupColumns(args)
 
 	// Don't use color if disabled
 	useColor := true
@@ -201,6 +247,7 @@ func RunCustom(args []string, runOpts *RunOptions) int {
 		},
 		format:   format,
 		detailed: detailed,
+		columns:  columns,
 	}
 
 	serverCmdUi := &VaultUI{
@@ -35,6 +35,14 @@ Usage: vault namespace <subcommand> [options] [args]
 
       $ vault namespace lookup
 
+  Use a namespace for subsequent commands:
+
+      $ vault namespace use
+
+  Print the namespace used by commands:
+
+      $ vault namespace current
+
   Create a new namespace:
 
       $ vault namespace create
@@ -0,0 +1,87 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*NamespaceCurrentCommand)(nil)
+	_ cli.CommandAutocomplete = (*NamespaceCurrentCommand)(nil)
+)
+
+type NamespaceCurrentCommand struct {
+	*BaseCommand
+}
+
+func (c *NamespaceCurrentCommand) Synopsis() string {
+	return "Print the namespace used by commands"
+}
+
+func (c *NamespaceCurrentCommand) Help() string {
+	helpText := `
+Usage: vault namespace current [options]
+
+  Prints the namespace commands are run in. This is the value of the
+  -namespace flag or the VAULT_NAMESPACE environment variable if given, or
+  else the namespace set with "vault namespace use". Nothing is printed for
+  the root namespace. The command does not contact the server, so it can be
+  used in a shell prompt:
+
+      $ PS1='$(vault namespace current) \$ '
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NamespaceCurrentCommand) Flags() *FlagSets {
+	return c.flagSet(FlagSetHTTP)
+}
+
+func (c *NamespaceCurrentCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *NamespaceCurrentCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *NamespaceCurrentCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if args = f.Args(); len(args) > 0 {
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 0, got %d)", len(args)))
+		return 1
+	}
+
+	ns := c.flagNamespace
+	if c.flagNS != notSetValue {
+		ns = c.flagNS
+	}
+	if ns == notSetValue {
+		var err error
+		ns, err = c.namespaceContext()
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading namespace context: %s", err))
+			return 2
+		}
+	}
+
+	if ns = namespace.Canonicalize(ns); ns != "" {
+		c.UI.Output(ns)
+	}
+	return 0
+}
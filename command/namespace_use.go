@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/mitchellh/cli"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/natefinch/atomic"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*NamespaceUseCommand)(nil)
+	_ cli.CommandAutocomplete = (*NamespaceUseCommand)(nil)
+)
+
+// namespaceContextFilename is the name of the file in the home directory the
+// active namespace is stored in, next to the ".vault-token" file of the
+// default token helper.
+const namespaceContextFilename = ".vault-namespace"
+
+type NamespaceUseCommand struct {
+	*BaseCommand
+}
+
+func (c *NamespaceUseCommand) Synopsis() string {
+	return "Set the namespace used by subsequent commands"
+}
+
+func (c *NamespaceUseCommand) Help() string {
+	helpText := `
+Usage: vault namespace use [options] PATH
+
+  Sets the namespace used by all subsequent commands that are not given the
+  -namespace flag or the VAULT_NAMESPACE environment variable. The namespace
+  is stored in ~/.vault-namespace, next to the token of the default token
+  helper.
+
+  Use the namespace ns1/ns2/ for subsequent commands:
+
+      $ vault namespace use ns1/ns2
+
+  Go back to the root namespace:
+
+      $ vault namespace use root
+
+  Print the namespace in use, for example in a shell prompt:
+
+      $ vault namespace current
+
+` + c.Flags().Help()
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NamespaceUseCommand) Flags() *FlagSets {
+	return c.flagSet(FlagSetHTTP)
+}
+
+func (c *NamespaceUseCommand) AutocompleteArgs() complete.Predictor {
+	return c.PredictVaultNamespaces()
+}
+
+func (c *NamespaceUseCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *NamespaceUseCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	switch {
+	case len(args) < 1:
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 1, got %d)", len(args)))
+		return 1
+	case len(args) > 1:
+		c.UI.Error(fmt.Sprintf("Too many arguments (expected 1, got %d)", len(args)))
+		return 1
+	}
+
+	namespacePath := strings.TrimSpace(args[0])
+	if namespacePath == "root" || namespacePath == "/" {
+		namespacePath = ""
+	}
+	namespacePath = namespace.Canonicalize(namespacePath)
+
+	if err := c.setNamespaceContext(namespacePath); err != nil {
+		c.UI.Error(fmt.Sprintf("Error setting namespace: %s", err))
+		return 2
+	}
+
+	if namespacePath == "" {
+		c.UI.Output("Success! Subsequent commands will use the root namespace.")
+	} else {
+		c.UI.Output(fmt.Sprintf("Success! Subsequent commands will use namespace %q.", namespacePath))
+	}
+	return 0
+}
+
+// namespaceContextFile returns the path of the file the namespace set with
+// "vault namespace use" is stored in.
+func (c *BaseCommand) namespaceContextFile() (string, error) {
+	if c.namespaceContextPath != "" {
+		return c.namespaceContextPath, nil
+	}
+
+	homeDir, err := homedir.Dir()
+	if err != nil {
+		return "", fmt.Errorf("error getting user's home directory: %w", err)
+	}
+	return filepath.Join(homeDir, namespaceContextFilename), nil
+}
+
+// namespaceContext returns the namespace set with "vault namespace use", or
+// an empty string if the root namespace is in use.
+func (c *BaseCommand) namespaceContext() (string, error) {
+	path, err := c.namespaceContextFile()
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return namespace.Canonicalize(strings.TrimSpace(string(raw))), nil
+}
+
+// setNamespaceContext stores the namespace used by subsequent commands. An
+// empty namespace removes the stored namespace.
+func (c *BaseCommand) setNamespaceContext(ns string) error {
+	path, err := c.namespaceContextFile()
+	if err != nil {
+		return err
+	}
+
+	if ns == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	// Write to a temporary file first so that concurrent commands never read
+	// a partially written namespace.
+	tmpFile := path + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer os.Remove(tmpFile)
+
+	if _, err := io.WriteString(f, ns+"\n"); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return atomic.ReplaceFile(tmpFile, path)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/command/token"
+	"github.com/mitchellh/cli"
+)
+
+func testNamespaceUseCommand(tb testing.TB, contextPath string) (*cli.MockUi, *NamespaceUseCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &NamespaceUseCommand{
+		BaseCommand: &BaseCommand{
+			UI:                   ui,
+			namespaceContextPath: contextPath,
+		},
+	}
+}
+
+func testNamespaceCurrentCommand(tb testing.TB, contextPath string) (*cli.MockUi, *NamespaceCurrentCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &NamespaceCurrentCommand{
+		BaseCommand: &BaseCommand{
+			UI:                   ui,
+			namespaceContextPath: contextPath,
+		},
+	}
+}
+
+func TestNamespaceUseCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"not_enough_args",
+			[]string{},
+			"Not enough arguments",
+			1,
+		},
+		{
+			"too_many_args",
+			[]string{"foo", "bar"},
+			"Too many arguments",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ui, cmd := testNamespaceUseCommand(t, filepath.Join(t.TempDir(), ".vault-namespace"))
+			code := cmd.Run(tc.args)
+			if code != tc.code {
+				t.Errorf("expected %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+
+	t.Run("use_and_current", func(t *testing.T) {
+		t.Parallel()
+
+		contextPath := filepath.Join(t.TempDir(), ".vault-namespace")
+
+		ui, cmd := testNamespaceUseCommand(t, contextPath)
+		if code := cmd.Run([]string{"/ns1/ns2"}); code != 0 {
+			t.Fatalf("expected 0, got %d: %s", code, ui.ErrorWriter.String())
+		}
+		info, err := os.Stat(contextPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+		}
+
+		ui, current := testNamespaceCurrentCommand(t, contextPath)
+		if code := current.Run(nil); code != 0 {
+			t.Fatalf("expected 0, got %d: %s", code, ui.ErrorWriter.String())
+		}
+		if got, exp := ui.OutputWriter.String(), "ns1/ns2/\n"; got != exp {
+			t.Errorf("expected %q to be %q", got, exp)
+		}
+
+		// The -namespace flag takes precedence over the stored namespace
+		ui, current = testNamespaceCurrentCommand(t, contextPath)
+		if code := current.Run([]string{"-namespace=other"}); code != 0 {
+			t.Fatalf("expected 0, got %d: %s", code, ui.ErrorWriter.String())
+		}
+		if got, exp := ui.OutputWriter.String(), "other/\n"; got != exp {
+			t.Errorf("expected %q to be %q", got, exp)
+		}
+
+		// Switching back to the root namespace removes the stored namespace
+		ui, cmd = testNamespaceUseCommand(t, contextPath)
+		if code := cmd.Run([]string{"root"}); code != 0 {
+			t.Fatalf("expected 0, got %d: %s", code, ui.ErrorWriter.String())
+		}
+		if _, err := os.Stat(contextPath); !os.IsNotExist(err) {
+			t.Errorf("expected namespace context to be removed, got %v", err)
+		}
+
+		ui, current = testNamespaceCurrentCommand(t, contextPath)
+		if code := current.Run(nil); code != 0 {
+			t.Fatalf("expected 0, got %d: %s", code, ui.ErrorWriter.String())
+		}
+		if got := ui.OutputWriter.String(); got != "" {
+			t.Errorf("expected no output for the root namespace, got %q", got)
+		}
+	})
+
+	t.Run("no_tabs", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := testNamespaceUseCommand(t, "")
+		assertNoTabs(t, cmd)
+		_, current := testNamespaceCurrentCommand(t, "")
+		assertNoTabs(t, current)
+	})
+}
+
+func TestBaseCommand_NamespaceContext(t *testing.T) {
+	t.Parallel()
+
+	contextPath := filepath.Join(t.TempDir(), ".vault-namespace")
+	if err := os.WriteFile(contextPath, []byte("ns1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	newBase := func(ns string) *BaseCommand {
+		return &BaseCommand{
+			UI:                   cli.NewMockUi(),
+			tokenHelper:          token.NewTestingTokenHelper(),
+			namespaceContextPath: contextPath,
+			flagNamespace:        ns,
+			flagNS:               notSetValue,
+		}
+	}
+
+	client, err := newBase(notSetValue).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := client.Namespace(), "ns1/"; got != exp {
+		t.Errorf("expected %q to be %q", got, exp)
+	}
+
+	client, err = newBase("ns2").Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := client.Namespace(), "ns2/"; got != exp {
+		t.Errorf("expected %q to be %q", got, exp)
+	}
+}
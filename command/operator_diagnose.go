@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -85,6 +86,12 @@ Usage: vault operator diagnose
 
      $ vault operator diagnose -config=/etc/vault/config.hcl -skip=listener
 
+  If a Vault server is reachable at VAULT_ADDR, diagnose also checks the clock
+  skew with it and, if a token is available, that the enabled audit devices
+  are writable from this host. Print the results as JSON for pipelines:
+
+     $ vault operator diagnose -config=/etc/vault/config.hcl -format=json
+
 ` + c.Flags().Help()
 	return strings.TrimSpace(helpText)
 }
@@ -110,7 +117,7 @@ func (c *OperatorDiagnoseCommand) Flags() *FlagSets {
 	f.StringSliceVar(&StringSliceVar{
 		Name:   "skip",
 		Target: &c.flagSkips,
-		Usage:  "Skip the health checks named as arguments. May be 'listener', 'storage', or 'autounseal'.",
+		Usage:  "Skip the health checks named as arguments, such as 'Check Time Skew' or 'Check Audit Devices'.",
 	})
 
 	f.BoolVar(&BoolVar{
@@ -121,9 +128,13 @@ func (c *OperatorDiagnoseCommand) Flags() *FlagSets {
 	})
 
 	f.StringVar(&StringVar{
-		Name:   "format",
-		Target: &c.flagFormat,
-		Usage:  "The output format",
+		Name:       "format",
+		Target:     &c.flagFormat,
+		Completion: complete.PredictSet("table", "json"),
+		Usage: "Print the results in the given format. Valid formats are \"table\" " +
+			"or \"json\". The exit code is 1 if any check failed and 2 if any warned, " +
+			"so the JSON output can be used in pipelines. This can also be " +
+			"specified via the VAULT_FORMAT environment variable.",
 	})
 	return set
 }
@@ -159,6 +170,16 @@ func (c *OperatorDiagnoseCommand) RunWithParsedFlags() int {
 		return 3
 	}
 
+	if c.flagFormat == "" {
+		c.flagFormat = os.Getenv(EnvVaultFormat)
+	}
+	switch c.flagFormat {
+	case "", "table", "json":
+	default:
+		c.UI.Error(fmt.Sprintf("Invalid output format %q, must be \"table\" or \"json\".", c.flagFormat))
+		return 3
+	}
+
 	if c.diagnose == nil {
 		if c.flagFormat == "json" {
 			c.diagnose = diagnose.New(io.Discard)
@@ -327,7 +348,9 @@ func (c *OperatorDiagnoseCommand) offlineDiagnostics(ctx context.Context) error
 				}
 				diagnose.RaftFileChecks(ctx, path)
 			}
-			diagnose.RaftStorageQuorum(ctx, (*backend).(*raft.RaftBackend))
+			raftBackend := (*backend).(*raft.RaftBackend)
+			diagnose.RaftStorageQuorum(ctx, raftBackend)
+			diagnose.RaftPeerConnectivity(ctx, raftBackend, raftBackend.NodeID())
 		}
 
 		// Consul storage checks
@@ -362,6 +385,7 @@ func (c *OperatorDiagnoseCommand) offlineDiagnostics(ctx context.Context) error
 					return err
 				}
 				uuid := "diagnose/latency/" + uuidSuffix
+				start := time.Now()
 				dur, err := diagnose.EndToEndLatencyCheckWrite(ctx, uuid, *backend)
 				if err != nil {
 					return err
@@ -386,6 +410,8 @@ func (c *OperatorDiagnoseCommand) offlineDiagnostics(ctx context.Context) error
 
 				if maxDuration > time.Duration(0) {
 					diagnose.Warn(ctx, diagnose.LatencyWarning+fmt.Sprintf("duration: %s, operation: %s", maxDuration, maxDurationCrudOperation))
+				} else {
+					diagnose.Success(ctx, fmt.Sprintf("Storage write, read and delete completed in %s.", time.Since(start).Round(time.Microsecond)))
 				}
 				return nil
 			}))
@@ -531,6 +557,10 @@ SEALFAIL:
 		return nil
 	})
 
+	diagnose.Test(ctx, "Check Seal Reachability", func(ctx context.Context) error {
+		return diagnose.SealReachabilityChecks(ctx, config.Seals)
+	})
+
 	var coreConfig vault.CoreConfig
 	diagnose.Test(ctx, "Create Core Configuration", func(ctx context.Context) error {
 		var secureRandomReader io.Reader
@@ -784,9 +814,118 @@ SEALFAIL:
 		}
 	}
 
+	c.runningServerDiagnostics(ctx)
+
 	return nil
 }
 
+// runningServerDiagnostics runs the checks that need a running Vault server,
+// which is reached at the address configured by VAULT_ADDR. The checks are
+// skipped if no server is reachable, for example before its first start.
+func (c *OperatorDiagnoseCommand) runningServerDiagnostics(ctx context.Context) {
+	ctx, span := diagnose.StartSpan(ctx, "Check Running Server")
+	defer span.End()
+
+	client, err := c.Client()
+	if err != nil {
+		diagnose.Skipped(ctx, fmt.Sprintf("Could not create a client for the running server: %s.", err))
+		return
+	}
+	client = client.WithNamespace("")
+	client.SetClientTimeout(diagnose.ReachabilityDialTimeout)
+
+	start := time.Now()
+	health, err := client.Sys().HealthWithContext(ctx)
+	end := time.Now()
+	if err != nil {
+		diagnose.Skipped(ctx, fmt.Sprintf("No Vault server reachable at %s: %s.", client.Address(), err))
+		return
+	}
+
+	diagnose.Test(ctx, "Check Time Skew", func(ctx context.Context) error {
+		if health.ServerTimeUTC == 0 {
+			diagnose.Skipped(ctx, "The server did not report its time.")
+			return nil
+		}
+		diagnose.TimeSkew(ctx, time.Unix(health.ServerTimeUTC, 0), start, end)
+		return nil
+	})
+
+	diagnose.Test(ctx, "Check Audit Devices", func(ctx context.Context) error {
+		if health.Sealed || !health.Initialized {
+			diagnose.Skipped(ctx, "The server is sealed or not initialized.")
+			return nil
+		}
+		if client.Token() == "" {
+			diagnose.Skipped(ctx, "No token available to list the audit devices. Set VAULT_TOKEN to run this check.")
+			return nil
+		}
+
+		devices, err := client.Sys().ListAuditWithContext(ctx)
+		if err != nil {
+			diagnose.Skipped(ctx, fmt.Sprintf("Could not list the audit devices: %s.", err))
+			return nil
+		}
+		if len(devices) == 0 {
+			diagnose.Warn(ctx, "No audit devices are enabled.")
+			diagnose.Advise(ctx, "Enable at least one audit device to keep a record of all requests.")
+			return nil
+		}
+
+		paths := make([]string, 0, len(devices))
+		for path := range devices {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		var failed bool
+		for _, path := range paths {
+			device := devices[path]
+			checkName := fmt.Sprintf("Check %s Audit Device %q", diagnose.CapitalizeFirstLetter(device.Type), path)
+			var err error
+			switch device.Type {
+			case "file":
+				filePath := device.Options["file_path"]
+				if filePath == "" {
+					filePath = device.Options["path"]
+				}
+				switch filePath {
+				case "stdout", "discard":
+					diagnose.SpotSkipped(ctx, checkName, fmt.Sprintf("Audit device writes to %s.", filePath))
+					continue
+				}
+				if err = diagnose.AuditFileWritable(filePath); err != nil {
+					err = fmt.Errorf("Audit file %s is not writable: %w.", filePath, err)
+				}
+			case "socket":
+				if socketType := device.Options["socket_type"]; socketType != "" && socketType != "tcp" {
+					diagnose.SpotSkipped(ctx, checkName, fmt.Sprintf("Socket type %s is not checked.", socketType))
+					continue
+				}
+				address := device.Options["address"]
+				if err = diagnose.DialAddress(ctx, address, diagnose.ReachabilityDialTimeout); err != nil {
+					err = fmt.Errorf("Audit socket %s is not reachable: %w.", address, err)
+				}
+			default:
+				diagnose.SpotSkipped(ctx, checkName, fmt.Sprintf("Audit devices of type %s are not checked.", device.Type))
+				continue
+			}
+
+			if err != nil {
+				failed = true
+				diagnose.SpotError(ctx, checkName, err)
+			} else {
+				diagnose.SpotOk(ctx, checkName, "")
+			}
+		}
+		if failed {
+			diagnose.Advise(ctx, "Vault fails requests it cannot audit if no audit device succeeds. Run diagnose on each node, as file audit devices write to the local disk.")
+			return fmt.Errorf("At least one audit device is not writable.")
+		}
+		return nil
+	})
+}
+
 func coalesce(values ...interface{}) interface{} {
 	for _, val := range values {
 		if val != nil && val != "" {
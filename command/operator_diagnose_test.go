@@ -10,9 +10,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/vault/diagnose"
 	"github.com/mitchellh/cli"
 )
@@ -495,6 +497,62 @@ func TestOperatorDiagnoseCommand_Run(t *testing.T) {
 	})
 }
 
+func TestOperatorDiagnoseCommand_RunningServer(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	if err := client.Sys().EnableAuditWithOptions("file", &api.EnableAuditOptions{
+		Type:    "file",
+		Options: map[string]string{"file_path": auditPath},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := testOperatorDiagnoseCommand(t)
+	cmd.client = client
+	cmd.Run([]string{"-config", "./server/test-fixtures/config_diagnose_ok.hcl"})
+	result := cmd.diagnose.Finalize(context.Background())
+
+	expected := []*diagnose.Result{
+		{
+			Name:   "Check Running Server",
+			Status: diagnose.OkStatus,
+			Children: []*diagnose.Result{
+				{
+					Name:   "Check Time Skew",
+					Status: diagnose.OkStatus,
+				},
+				{
+					Name:   "Check Audit Devices",
+					Status: diagnose.OkStatus,
+					Children: []*diagnose.Result{
+						{
+							Name:   `Check File Audit Device "file/"`,
+							Status: diagnose.OkStatus,
+						},
+					},
+				},
+			},
+		},
+	}
+	if err := compareResults(expected, result.Children); err != nil {
+		t.Fatalf("Did not find expected test results: %v", err)
+	}
+}
+
+func TestOperatorDiagnoseCommand_InvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	cmd := testOperatorDiagnoseCommand(t)
+	code := cmd.Run([]string{"-config", "./server/test-fixtures/config_diagnose_ok.hcl", "-format", "yaml"})
+	if exp := 3; code != exp {
+		t.Errorf("expected %d to be %d", code, exp)
+	}
+}
+
 func compareResults(expected []*diagnose.Result, actual []*diagnose.Result) error {
 	for _, exp := range expected {
 		found := false
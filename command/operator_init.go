@@ -25,11 +25,12 @@ var (
 type OperatorInitCommand struct {
 	*BaseCommand
 
-	flagStatus          bool
-	flagKeyShares       int
-	flagKeyThreshold    int
-	flagPGPKeys         []string
-	flagRootTokenPGPKey string
+	flagStatus           bool
+	flagKeyShares        int
+	flagKeyThreshold     int
+	flagPGPKeys          []string
+	flagRootTokenPGPKey  string
+	flagBarrierAlgorithm string
 
 	// Auto Unseal
 	flagRecoveryShares    int
@@ -145,6 +146,16 @@ func (c *OperatorInitCommand) Flags() *FlagSets {
 			"key.",
 	})
 
+	f.StringVar(&StringVar{
+		Name:       "barrier-algorithm",
+		Target:     &c.flagBarrierAlgorithm,
+		Completion: complete.PredictSet("aes256-gcm96", "chacha20-poly1305"),
+		Usage: "Algorithm used to encrypt data in the barrier, either " +
+			"\"aes256-gcm96\" or \"chacha20-poly1305\". ChaCha20-Poly1305 is " +
+			"faster on platforms without AES hardware acceleration. The default " +
+			"is \"aes256-gcm96\".",
+	})
+
 	f.IntVar(&IntVar{
 		Name:    "stored-shares",
 		Target:  &c.flagStoredShares,
@@ -278,10 +289,11 @@ func (c *OperatorInitCommand) Run(args []string) int {
 
 	// Build the initial init request
 	initReq := &api.InitRequest{
-		SecretShares:    c.flagKeyShares,
-		SecretThreshold: c.flagKeyThreshold,
-		PGPKeys:         c.flagPGPKeys,
-		RootTokenPGPKey: c.flagRootTokenPGPKey,
+		SecretShares:     c.flagKeyShares,
+		SecretThreshold:  c.flagKeyThreshold,
+		PGPKeys:          c.flagPGPKeys,
+		RootTokenPGPKey:  c.flagRootTokenPGPKey,
+		BarrierAlgorithm: c.flagBarrierAlgorithm,
 
 		RecoveryShares:    c.flagRecoveryShares,
 		RecoveryThreshold: c.flagRecoveryThreshold,
@@ -0,0 +1,196 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/raft"
+	physRaft "github.com/hashicorp/vault/physical/raft"
+	"github.com/hashicorp/vault/sdk/plugin/pb"
+	"github.com/mitchellh/cli"
+)
+
+func testOperatorRaftSnapshotInspectCommand(tb testing.TB) (*cli.MockUi, *OperatorRaftSnapshotInspectCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &OperatorRaftSnapshotInspectCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+// testRaftSnapshotFile writes a snapshot archive in the format produced by
+// "vault operator raft snapshot save" containing the given entries.
+func testRaftSnapshotFile(tb testing.TB, entries map[string]string, corrupt bool) string {
+	tb.Helper()
+
+	var state bytes.Buffer
+	w := physRaft.NewDelimitedWriter(&state)
+	for k, v := range entries {
+		if err := w.WriteMsg(&pb.StorageEntry{Key: k, Value: []byte(v)}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	meta, err := json.Marshal(&raft.SnapshotMeta{
+		Version: 1,
+		ID:      "2-23-1700000000000",
+		Index:   23,
+		Term:    2,
+		Size:    int64(state.Len()),
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	metaSum := sha256.Sum256(meta)
+	stateSum := sha256.Sum256(state.Bytes())
+	if corrupt {
+		stateSum[0] ^= 0xff
+	}
+	sums := fmt.Sprintf("%x  meta.json\n%x  state.bin\n", metaSum, stateSum)
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{"meta.json", meta},
+		{"state.bin", state.Bytes()},
+		{"SHA256SUMS", []byte(sums)},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: file.name, Mode: 0o600, Size: int64(len(file.data))}); err != nil {
+			tb.Fatal(err)
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		tb.Fatal(err)
+	}
+
+	path := filepath.Join(tb.TempDir(), "raft.snap")
+	if err := os.WriteFile(path, archive.Bytes(), 0o600); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestOperatorRaftSnapshotInspectCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	entries := map[string]string{
+		"core/mounts":                 "mounts",
+		"core/auth":                   "auth",
+		"logical/1111/foo":            "foo",
+		"logical/1111/bar":            "bar",
+		"logical/2222/baz":            "baz",
+		"auth/3333/role/a":            "a",
+		"sys/token/id/h1234567890abc": "token",
+	}
+
+	t.Run("not_enough_args", func(t *testing.T) {
+		t.Parallel()
+
+		ui, cmd := testOperatorRaftSnapshotInspectCommand(t)
+		code := cmd.Run([]string{})
+		if exp := 1; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, "Incorrect arguments") {
+			t.Errorf("expected usage error, got %q", combined)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+
+		ui, cmd := testOperatorRaftSnapshotInspectCommand(t)
+		code := cmd.Run([]string{testRaftSnapshotFile(t, entries, false)})
+		if exp := 0; code != exp {
+			t.Fatalf("expected %d to be %d: %s", code, exp, ui.ErrorWriter.String())
+		}
+		out := ui.OutputWriter.String()
+		for _, expected := range []string{
+			"2-23-1700000000000",
+			"logical/    3",
+			"secret        logical/1111/",
+			"auth          auth/3333/",
+		} {
+			if !strings.Contains(out, expected) {
+				t.Errorf("expected %q in output:\n%s", expected, out)
+			}
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		ui, cmd := testOperatorRaftSnapshotInspectCommand(t)
+		cmd.UI = &VaultUI{Ui: ui, format: "json"}
+		code := cmd.Run([]string{testRaftSnapshotFile(t, entries, false)})
+		if exp := 0; code != exp {
+			t.Fatalf("expected %d to be %d: %s", code, exp, ui.ErrorWriter.String())
+		}
+
+		var info RaftSnapshotInfo
+		if err := json.Unmarshal(ui.OutputWriter.Bytes(), &info); err != nil {
+			t.Fatal(err)
+		}
+		if info.Index != 23 || info.Term != 2 {
+			t.Errorf("unexpected index %d and term %d", info.Index, info.Term)
+		}
+		if info.Keys != len(entries) {
+			t.Errorf("expected %d keys, got %d", len(entries), info.Keys)
+		}
+		if len(info.Mounts) != 3 {
+			t.Fatalf("expected 3 mounts, got %d", len(info.Mounts))
+		}
+		if m := info.Mounts[1]; m.Prefix != "logical/1111/" || m.Keys != 2 || m.Size != int64(len("logical/1111/foo")*2+6) {
+			t.Errorf("unexpected mount info %#v", m)
+		}
+		if !info.MountTables["core/mounts"] || info.MountTables["core/local-mounts"] {
+			t.Errorf("unexpected mount tables %#v", info.MountTables)
+		}
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		t.Parallel()
+
+		ui, cmd := testOperatorRaftSnapshotInspectCommand(t)
+		code := cmd.Run([]string{testRaftSnapshotFile(t, entries, true)})
+		if exp := 2; code != exp {
+			t.Errorf("expected %d to be %d", code, exp)
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), "hash check failed") {
+			t.Errorf("expected hash check error, got %q", ui.ErrorWriter.String())
+		}
+	})
+
+	t.Run("no_tabs", func(t *testing.T) {
+		t.Parallel()
+
+		_, cmd := testOperatorRaftSnapshotInspectCommand(t)
+		assertNoTabs(t, cmd)
+	})
+}
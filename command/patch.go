@@ -23,7 +23,9 @@ var (
 type PatchCommand struct {
 	*BaseCommand
 
-	flagForce bool
+	flagForce      bool
+	flagCAS        int
+	flagRemoveData []string
 
 	testStdin io.Reader // for tests
 }
@@ -59,6 +61,15 @@ Usage: vault patch [options] PATH [DATA K=V...]
 
       $ echo "example.com" | vault patch pki/roles/example allowed_domains=-
 
+  Fields can be removed by sending them as null in the merge patch:
+
+      $ vault patch -remove-data=allowed_domains pki/roles/example
+
+  On KV version 2 mounts, the data is sent as the secret's fields and -cas can
+  be used to only apply the patch if the secret is at the given version:
+
+      $ vault patch -cas=3 secret/creds password=s3cr3t
+
   For a full list of examples and paths, please see the documentation that
   corresponds to the secret engines in use.
 
@@ -82,6 +93,25 @@ func (c *PatchCommand) Flags() *FlagSets {
 			"allows writing to keys that do not need or expect data.",
 	})
 
+	f.IntVar(&IntVar{
+		Name:    "cas",
+		Target:  &c.flagCAS,
+		Default: 0,
+		Usage: "Specifies to use a Check-And-Set operation. If set to 0 or not " +
+			"set, the patch will be allowed. If the index is non-zero the patch " +
+			"will only be allowed if the secret's current version matches the " +
+			"version specified in the cas parameter. Only supported on KV " +
+			"version 2 mounts.",
+	})
+
+	f.StringSliceVar(&StringSliceVar{
+		Name:    "remove-data",
+		Target:  &c.flagRemoveData,
+		Default: []string{},
+		Usage: "Key to remove from the data at the path. To specify multiple " +
+			"values, specify this flag multiple times.",
+	})
+
 	return set
 }
 
@@ -108,7 +138,7 @@ func (c *PatchCommand) Run(args []string) int {
 	case len(args) < 1:
 		c.UI.Error(fmt.Sprintf("Not enough arguments (expected 1, got %d)", len(args)))
 		return 1
-	case len(args) == 1 && !c.flagForce:
+	case len(args) == 1 && !c.flagForce && len(c.flagRemoveData) == 0:
 		c.UI.Error("Must supply data or use -force")
 		return 1
 	}
@@ -133,6 +163,34 @@ func (c *PatchCommand) Run(args []string) int {
 		return 2
 	}
 
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	for _, key := range c.flagRemoveData {
+		// A null in a JSON merge patch payload will remove the associated key
+		data[key] = nil
+	}
+
+	if c.flagCAS > 0 {
+		mountPath, v2, err := isKVv2(path, client)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 2
+		}
+		if !v2 {
+			c.UI.Error("The -cas flag is only supported on K/V version 2 mounts")
+			return 1
+		}
+
+		path = addPrefixToKVPath(path, mountPath, "data", true)
+		data = map[string]interface{}{
+			"data": data,
+			"options": map[string]interface{}{
+				"cas": c.flagCAS,
+			},
+		}
+	}
+
 	secret, err := client.Logical().JSONMergePatch(context.Background(), path, data)
 	return handleWriteSecretOutput(c.BaseCommand, path, secret, err)
 }
@@ -173,6 +173,83 @@ func TestPatchCommand_Run(t *testing.T) {
 		}
 	})
 
+	t.Run("kv_v2_cas", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		if err := client.Sys().Mount("kv/", &api.MountInput{
+			Type: "kv-v2",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Only have to potentially retry the first time.
+		code, combined := kvPutWithRetry(t, client, []string{
+			"kv/patch/cas", "foo=a", "bar=b",
+		})
+		if code != 0 {
+			t.Fatalf("expected 0 to be %d: %s", code, combined)
+		}
+
+		ui, cmd := testPatchCommand(t)
+		cmd.client = client
+		code = cmd.Run([]string{
+			"-cas", "1", "-remove-data", "bar", "kv/patch/cas", "foo=c",
+		})
+		if code != 0 {
+			combined = ui.OutputWriter.String() + ui.ErrorWriter.String()
+			t.Fatalf("expected 0 to be %d: %s", code, combined)
+		}
+
+		secret, err := client.Logical().Read("kv/data/patch/cas")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := secret.Data["data"].(map[string]interface{})
+		if exp, act := "c", data["foo"]; exp != act {
+			t.Errorf("expected foo=%v to be %v", act, exp)
+		}
+		if _, ok := data["bar"]; ok {
+			t.Errorf("expected bar to be removed, got %v", data)
+		}
+
+		ui, cmd = testPatchCommand(t)
+		cmd.client = client
+		code = cmd.Run([]string{
+			"-cas", "1", "kv/patch/cas", "foo=d",
+		})
+		if code != 2 {
+			t.Fatalf("expected 2 to be %d", code)
+		}
+		combined = ui.OutputWriter.String() + ui.ErrorWriter.String()
+		if !strings.Contains(combined, "check-and-set parameter did not match the current version") {
+			t.Errorf("expected %q to contain %q", combined, "check-and-set parameter did not match the current version")
+		}
+	})
+
+	t.Run("cas_not_kv_v2", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := testVaultServer(t)
+		defer closer()
+
+		ui, cmd := testPatchCommand(t)
+		cmd.client = client
+		code := cmd.Run([]string{
+			"-cas", "1", "sys/mounts/secret/tune", "description=foo",
+		})
+		if code != 1 {
+			t.Fatalf("expected 1 to be %d", code)
+		}
+		combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+		expected := "only supported on K/V version 2 mounts"
+		if !strings.Contains(combined, expected) {
+			t.Errorf("expected %q to contain %q", combined, expected)
+		}
+	})
+
 	t.Run("communication_failure", func(t *testing.T) {
 		t.Parallel()
 
@@ -31,6 +31,11 @@ Usage: vault pki <subcommand> [options] [args]
 
       $ vault pki health-check pki
 
+  Issue a certificate from a role and write it to files:
+
+      $ vault pki issue-cert -cert-file=cert.pem -key-file=key.pem \
+          pki/roles/web common_name=www.example.com
+
   Please see the individual subcommand help for detailed usage information.
 `
 
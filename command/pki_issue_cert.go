@@ -0,0 +1,397 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+var (
+	_ cli.Command             = (*PKIIssueCertCommand)(nil)
+	_ cli.CommandAutocomplete = (*PKIIssueCertCommand)(nil)
+)
+
+type PKIIssueCertCommand struct {
+	*BaseCommand
+
+	flagCertFile      string
+	flagKeyFile       string
+	flagCAFile        string
+	flagCertMode      string
+	flagKeyMode       string
+	flagOwner         string
+	flagReloadCommand string
+
+	testStdin io.Reader // for tests
+}
+
+func (c *PKIIssueCertCommand) Synopsis() string {
+	return "Issue a certificate from a role and write it to files"
+}
+
+func (c *PKIIssueCertCommand) Help() string {
+	helpText := `
+Usage: vault pki issue-cert [options] ROLE [K=V...]
+
+  Issues a new certificate from the given PKI role and writes the certificate,
+  private key and CA chain to the given files. ROLE is the path of the role,
+  either as MOUNT/roles/NAME or MOUNT/issue/NAME. The K=V pairs are passed to
+  the issue endpoint of the role.
+
+  Each file is written to a temporary file first and moved into place after
+  all files have been written, so that no file is replaced if writing any of
+  them fails. The files are moved into place one after the other; the reload
+  command only runs once all of them are in place.
+
+  Issue a certificate and reload nginx once it has been written:
+
+      $ vault pki issue-cert \
+          -cert-file=/etc/nginx/tls/cert.pem \
+          -key-file=/etc/nginx/tls/key.pem \
+          -ca-file=/etc/nginx/tls/ca.pem \
+          -owner=nginx \
+          -reload-command="systemctl reload nginx" \
+          pki/roles/web common_name=www.example.com ttl=72h
+
+` + c.Flags().Help()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *PKIIssueCertCommand) Flags() *FlagSets {
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&StringVar{
+		Name:       "cert-file",
+		Target:     &c.flagCertFile,
+		Completion: complete.PredictFiles("*"),
+		Usage:      "Path to write the issued certificate to.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "key-file",
+		Target:     &c.flagKeyFile,
+		Completion: complete.PredictFiles("*"),
+		Usage:      "Path to write the private key of the issued certificate to.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:       "ca-file",
+		Target:     &c.flagCAFile,
+		Completion: complete.PredictFiles("*"),
+		Usage: "Path to write the CA chain of the issued certificate to. If " +
+			"the role's issuer has no chain, the issuing CA is written.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:    "cert-mode",
+		Target:  &c.flagCertMode,
+		Default: "0644",
+		Usage:   "File mode, in octal, of the certificate and CA chain files.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:    "key-mode",
+		Target:  &c.flagKeyMode,
+		Default: "0600",
+		Usage:   "File mode, in octal, of the private key file.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:   "owner",
+		Target: &c.flagOwner,
+		Usage: "User, and optionally group in the form USER:GROUP, that should " +
+			"own the written files. Changing ownership usually requires root.",
+	})
+
+	f.StringVar(&StringVar{
+		Name:   "reload-command",
+		Target: &c.flagReloadCommand,
+		Usage: "Command to run through the shell after all files have been " +
+			"written, for example to reload the service using the certificate.",
+	})
+
+	return set
+}
+
+func (c *PKIIssueCertCommand) AutocompleteArgs() complete.Predictor {
+	return c.PredictVaultFiles()
+}
+
+func (c *PKIIssueCertCommand) AutocompleteFlags() complete.Flags {
+	return c.Flags().Completions()
+}
+
+func (c *PKIIssueCertCommand) Run(args []string) int {
+	f := c.Flags()
+
+	if err := f.Parse(args); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args = f.Args()
+	if len(args) < 1 {
+		c.UI.Error(fmt.Sprintf("Not enough arguments (expected at least 1, got %d)", len(args)))
+		return 1
+	}
+
+	if c.flagCertFile == "" && c.flagKeyFile == "" && c.flagCAFile == "" {
+		c.UI.Error("At least one of -cert-file, -key-file or -ca-file must be set")
+		return 1
+	}
+
+	certMode, err := parseFileMode(c.flagCertMode)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Invalid -cert-mode: %s", err))
+		return 1
+	}
+	keyMode, err := parseFileMode(c.flagKeyMode)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Invalid -key-mode: %s", err))
+		return 1
+	}
+
+	uid, gid, err := lookupOwner(c.flagOwner)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Invalid -owner: %s", err))
+		return 1
+	}
+
+	issuePath, err := pkiIssuePath(sanitizePath(args[0]))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Invalid role path: %s", err))
+		return 1
+	}
+
+	stdin := (io.Reader)(os.Stdin)
+	if c.testStdin != nil {
+		stdin = c.testStdin
+	}
+	data, err := parseArgsData(stdin, args[1:])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Failed to parse K=V data: %s", err))
+		return 1
+	}
+
+	client, err := c.Client()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	secret, err := client.Logical().WriteWithContext(context.Background(), issuePath, data)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error issuing certificate from %s: %s", issuePath, err))
+		return 2
+	}
+	if secret == nil || secret.Data == nil {
+		c.UI.Error(fmt.Sprintf("No certificate was returned from %s", issuePath))
+		return 2
+	}
+
+	certificate, _ := secret.Data["certificate"].(string)
+	privateKey, _ := secret.Data["private_key"].(string)
+	if certificate == "" {
+		c.UI.Error(fmt.Sprintf("No certificate was returned from %s", issuePath))
+		return 2
+	}
+
+	var files []pkiIssuedFile
+	if c.flagCertFile != "" {
+		files = append(files, pkiIssuedFile{path: c.flagCertFile, data: certificate, mode: certMode})
+	}
+	if c.flagKeyFile != "" {
+		if privateKey == "" {
+			c.UI.Error(fmt.Sprintf("No private key was returned from %s", issuePath))
+			return 2
+		}
+		files = append(files, pkiIssuedFile{path: c.flagKeyFile, data: privateKey, mode: keyMode})
+	}
+	if c.flagCAFile != "" {
+		files = append(files, pkiIssuedFile{path: c.flagCAFile, data: issuedCAChain(secret.Data), mode: certMode})
+	}
+
+	if err := writePKIIssuedFiles(files, uid, gid); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing certificate files: %s", err))
+		return 2
+	}
+
+	out := map[string]interface{}{
+		"serial_number": secret.Data["serial_number"],
+		"expiration":    secret.Data["expiration"],
+	}
+	if c.flagCertFile != "" {
+		out["cert_file"] = c.flagCertFile
+	}
+	if c.flagKeyFile != "" {
+		out["key_file"] = c.flagKeyFile
+	}
+	if c.flagCAFile != "" {
+		out["ca_file"] = c.flagCAFile
+	}
+
+	if c.flagReloadCommand != "" {
+		output, err := runReloadCommand(c.flagReloadCommand)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Certificate written, but the reload command failed: %s\n%s", err, output))
+			return 2
+		}
+	}
+
+	return OutputData(c.UI, out)
+}
+
+// pkiIssuePath converts the path of a PKI role into the path of its issue
+// endpoint.
+func pkiIssuePath(rolePath string) (string, error) {
+	switch {
+	case strings.Contains(rolePath, "/issue/"):
+		return rolePath, nil
+	case strings.Contains(rolePath, "/roles/"):
+		idx := strings.LastIndex(rolePath, "/roles/")
+		return rolePath[:idx] + "/issue/" + rolePath[idx+len("/roles/"):], nil
+	default:
+		return "", fmt.Errorf("%q is not of the format MOUNT/roles/NAME or MOUNT/issue/NAME", rolePath)
+	}
+}
+
+// issuedCAChain returns the CA chain from an issue response as a single PEM
+// bundle, falling back to the issuing CA.
+func issuedCAChain(data map[string]interface{}) string {
+	if rawChain, ok := data["ca_chain"].([]interface{}); ok && len(rawChain) > 0 {
+		var chain []string
+		for _, cert := range rawChain {
+			if s, ok := cert.(string); ok {
+				chain = append(chain, strings.TrimSpace(s))
+			}
+		}
+		return strings.Join(chain, "\n") + "\n"
+	}
+	issuingCA, _ := data["issuing_ca"].(string)
+	return strings.TrimSpace(issuingCA) + "\n"
+}
+
+type pkiIssuedFile struct {
+	path string
+	data string
+	mode os.FileMode
+}
+
+// writePKIIssuedFiles writes all files to temporary files next to their
+// destination, and only renames them into place once all of them have been
+// written successfully. Each rename is atomic, but readers may observe the
+// files in between renames, and files already renamed are kept if a later
+// rename fails.
+func writePKIIssuedFiles(files []pkiIssuedFile, uid, gid int) error {
+	tmpFiles := make([]string, 0, len(files))
+	cleanup := func() {
+		for _, f := range tmpFiles {
+			os.Remove(f)
+		}
+	}
+
+	for _, file := range files {
+		data := file.data
+		if !strings.HasSuffix(data, "\n") {
+			data += "\n"
+		}
+
+		f, err := os.CreateTemp(filepath.Dir(file.path), "."+filepath.Base(file.path)+".tmp")
+		if err != nil {
+			cleanup()
+			return err
+		}
+		tmpFiles = append(tmpFiles, f.Name())
+
+		_, err = f.WriteString(data)
+		if err == nil {
+			err = f.Chmod(file.mode)
+		}
+		if err == nil && (uid != -1 || gid != -1) {
+			err = f.Chown(uid, gid)
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("error writing %q: %w", file.path, err)
+		}
+	}
+
+	for i, file := range files {
+		if err := os.Rename(tmpFiles[i], file.path); err != nil {
+			cleanup()
+			return fmt.Errorf("error moving %q into place: %w", file.path, err)
+		}
+	}
+
+	return nil
+}
+
+func parseFileMode(raw string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(raw, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+	if mode > 0o777 {
+		return 0, fmt.Errorf("mode %q must only contain permission bits", raw)
+	}
+	return os.FileMode(mode), nil
+}
+
+// lookupOwner resolves a USER[:GROUP] string into a uid and gid. -1 is
+// returned for any part that is not set, which leaves it unchanged on chown.
+func lookupOwner(owner string) (int, int, error) {
+	if owner == "" {
+		return -1, -1, nil
+	}
+
+	userName, groupName, _ := strings.Cut(owner, ":")
+
+	uid, gid := -1, -1
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			return 0, 0, err
+		}
+		if uid, err = strconv.Atoi(u.Uid); err != nil {
+			return 0, 0, fmt.Errorf("unsupported uid %q for user %q", u.Uid, userName)
+		}
+	}
+	if groupName != "" {
+		g, err := user.LookupGroup(groupName)
+		if err != nil {
+			return 0, 0, err
+		}
+		if gid, err = strconv.Atoi(g.Gid); err != nil {
+			return 0, 0, fmt.Errorf("unsupported gid %q for group %q", g.Gid, groupName)
+		}
+	}
+
+	return uid, gid, nil
+}
+
+func runReloadCommand(command string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("/bin/sh", "-c", command)
+	}
+	return cmd.CombinedOutput()
+}
@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/mitchellh/cli"
+)
+
+func testPKIIssueCertCommand(tb testing.TB) (*cli.MockUi, *PKIIssueCertCommand) {
+	tb.Helper()
+
+	ui := cli.NewMockUi()
+	return ui, &PKIIssueCertCommand{
+		BaseCommand: &BaseCommand{
+			UI: ui,
+		},
+	}
+}
+
+func TestPKIIssueCertCommand_Run(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+
+	if err := client.Sys().Mount("pki", &api.MountInput{
+		Type: "pki",
+	}); err != nil {
+		t.Fatalf("pki mount error: %#v", err)
+	}
+	if _, err := client.Logical().Write("pki/root/generate/internal", map[string]interface{}{
+		"key_type":    "ec",
+		"common_name": "Root X1",
+		"ttl":         "8760h",
+	}); err != nil {
+		t.Fatalf("failed to prime CA: %v", err)
+	}
+	if _, err := client.Logical().Write("pki/roles/example", map[string]interface{}{
+		"allow_any_name": true,
+		"ttl":            "1h",
+	}); err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+
+	t.Run("not_enough_args", func(t *testing.T) {
+		ui, cmd := testPKIIssueCertCommand(t)
+		cmd.client = client
+
+		if code := cmd.Run([]string{}); code != 1 {
+			t.Fatalf("expected 1 to be %d", code)
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), "Not enough arguments") {
+			t.Fatalf("unexpected output: %s", ui.ErrorWriter.String())
+		}
+	})
+
+	t.Run("no_files", func(t *testing.T) {
+		ui, cmd := testPKIIssueCertCommand(t)
+		cmd.client = client
+
+		if code := cmd.Run([]string{"pki/roles/example", "common_name=foo.example.com"}); code != 1 {
+			t.Fatalf("expected 1 to be %d", code)
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), "must be set") {
+			t.Fatalf("unexpected output: %s", ui.ErrorWriter.String())
+		}
+	})
+
+	t.Run("bad_role_path", func(t *testing.T) {
+		ui, cmd := testPKIIssueCertCommand(t)
+		cmd.client = client
+
+		if code := cmd.Run([]string{"-cert-file", filepath.Join(t.TempDir(), "cert.pem"), "pki/example"}); code != 1 {
+			t.Fatalf("expected 1 to be %d", code)
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), "Invalid role path") {
+			t.Fatalf("unexpected output: %s", ui.ErrorWriter.String())
+		}
+	})
+
+	t.Run("writes_files", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile := filepath.Join(dir, "cert.pem")
+		keyFile := filepath.Join(dir, "key.pem")
+		caFile := filepath.Join(dir, "ca.pem")
+		reloaded := filepath.Join(dir, "reloaded")
+
+		ui, cmd := testPKIIssueCertCommand(t)
+		cmd.client = client
+
+		code := cmd.Run([]string{
+			"-cert-file", certFile,
+			"-key-file", keyFile,
+			"-ca-file", caFile,
+			"-reload-command", "touch " + reloaded,
+			"pki/roles/example", "common_name=foo.example.com",
+		})
+		if code != 0 {
+			t.Fatalf("expected 0 to be %d: %s", code, ui.ErrorWriter.String())
+		}
+		if !strings.Contains(ui.OutputWriter.String(), "serial_number") {
+			t.Fatalf("expected output to contain serial_number: %s", ui.OutputWriter.String())
+		}
+
+		if _, err := tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+			t.Fatalf("written certificate and key do not match: %v", err)
+		}
+
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(ca), "BEGIN CERTIFICATE") {
+			t.Fatalf("expected CA file to contain a certificate: %s", ca)
+		}
+
+		for file, mode := range map[string]os.FileMode{certFile: 0o644, keyFile: 0o600, caFile: 0o644} {
+			info, err := os.Stat(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != mode {
+				t.Fatalf("expected %s to have mode %o, got %o", file, mode, info.Mode().Perm())
+			}
+		}
+
+		if _, err := os.Stat(reloaded); err != nil {
+			t.Fatalf("expected reload command to have run: %v", err)
+		}
+	})
+
+	t.Run("no_tabs", func(t *testing.T) {
+		_, cmd := testPKIIssueCertCommand(t)
+		assertNoTabs(t, cmd)
+	})
+}
+
+func TestPKIIssuePath(t *testing.T) {
+	cases := map[string]string{
+		"pki/roles/web":       "pki/issue/web",
+		"pki/issue/web":       "pki/issue/web",
+		"ns1/pki/roles/web":   "ns1/pki/issue/web",
+		"pki-roles/roles/web": "pki-roles/issue/web",
+	}
+	for in, expected := range cases {
+		actual, err := pkiIssuePath(in)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", in, err)
+		}
+		if actual != expected {
+			t.Fatalf("expected %q to be %q, got %q", in, expected, actual)
+		}
+	}
+
+	if _, err := pkiIssuePath("pki/web"); err == nil {
+		t.Fatal("expected an error for a path that is not a role")
+	}
+}
@@ -25,6 +25,7 @@ type PluginRegisterCommand struct {
 	flagSHA256   string
 	flagVersion  string
 	flagOCIImage string
+	flagRuntime  string
 	flagEnv      []string
 }
 
@@ -100,6 +101,13 @@ func (c *PluginRegisterCommand) Flags() *FlagSets {
 			"container's entrypoint, args, and environment variables (append-only) respectively.",
 	})
 
+	f.StringVar(&StringVar{
+		Name:       "runtime",
+		Target:     &c.flagRuntime,
+		Completion: complete.PredictAnything,
+		Usage:      "Name of the plugin runtime to run the OCI image with. Only valid if oci_image is specified.",
+	})
+
 	f.StringSliceVar(&StringSliceVar{
 		Name:       "env",
 		Target:     &c.flagEnv,
@@ -175,6 +183,7 @@ func (c *PluginRegisterCommand) Run(args []string) int {
 		SHA256:   c.flagSHA256,
 		Version:  c.flagVersion,
 		OCIImage: c.flagOCIImage,
+		Runtime:  c.flagRuntime,
 		Env:      c.flagEnv,
 	}); err != nil {
 		c.UI.Error(fmt.Sprintf("Error registering plugin %s: %s", pluginName, err))
@@ -802,6 +802,15 @@ func parseSinks(result *Config, list *ast.ObjectList) error {
 		switch s.DHType {
 		case "":
 		case "curve25519":
+		case "age":
+			// age derives its own keys and authenticates the whole payload,
+			// so the curve25519 envelope options do not apply.
+			if s.AAD != "" || s.AADEnvVar != "" {
+				return multierror.Prefix(errors.New("'aad' and 'aad_env_var' are not supported with 'dh_type' of \"age\""), fmt.Sprintf("sink.%s", s.Type))
+			}
+			if s.DeriveKey {
+				return multierror.Prefix(errors.New("'derive_key' is not supported with 'dh_type' of \"age\""), fmt.Sprintf("sink.%s", s.Type))
+			}
 		default:
 			return multierror.Prefix(errors.New("invalid value for 'dh_type'"), fmt.Sprintf("sink.%s", s.Type))
 		}
@@ -18,6 +18,8 @@ var (
 
 type OperatorRotateCommand struct {
 	*BaseCommand
+
+	flagAlgorithm string
 }
 
 func (c *OperatorRotateCommand) Synopsis() string {
@@ -41,6 +43,10 @@ Usage: vault operator rotate [options]
 
       $ vault operator rotate
 
+  Switch to the ChaCha20-Poly1305 algorithm for new data:
+
+      $ vault operator rotate -algorithm=chacha20-poly1305
+
   For a full list of examples, please see the documentation.
 
 ` + c.Flags().Help()
@@ -49,7 +55,20 @@ Usage: vault operator rotate [options]
 }
 
 func (c *OperatorRotateCommand) Flags() *FlagSets {
-	return c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+	set := c.flagSet(FlagSetHTTP | FlagSetOutputFormat)
+
+	f := set.NewFlagSet("Command Options")
+
+	f.StringVar(&StringVar{
+		Name:       "algorithm",
+		Target:     &c.flagAlgorithm,
+		Completion: complete.PredictSet("aes256-gcm96", "chacha20-poly1305"),
+		Usage: "Algorithm of the new encryption key, either \"aes256-gcm96\" " +
+			"or \"chacha20-poly1305\". Defaults to the algorithm of the " +
+			"active key.",
+	})
+
+	return set
 }
 
 func (c *OperatorRotateCommand) AutocompleteArgs() complete.Predictor {
@@ -81,7 +100,7 @@ func (c *OperatorRotateCommand) Run(args []string) int {
 	}
 
 	// Rotate the key
-	err = client.Sys().Rotate()
+	err = client.Sys().RotateWithAlgorithm(c.flagAlgorithm)
 	if err != nil {
 		c.UI.Error(fmt.Sprintf("Error rotating key: %s", err))
 		return 2
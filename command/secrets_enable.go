@@ -41,6 +41,7 @@ type SecretsEnableCommand struct {
 	flagExternalEntropyAccess     bool
 	flagVersion                   int
 	flagAllowedManagedKeys        []string
+	flagReadCacheSize             int
 }
 
 func (c *SecretsEnableCommand) Synopsis() string {
@@ -228,6 +229,14 @@ func (c *SecretsEnableCommand) Flags() *FlagSets {
 			"each time with 1 key.",
 	})
 
+	f.IntVar(&IntVar{
+		Name:   flagNameReadCacheSize,
+		Target: &c.flagReadCacheSize,
+		Usage: "Number of decrypted storage entries of the secrets engine to keep " +
+			"in memory, so that frequent reads of the same entries don't hit " +
+			"storage. Set to 0 to disable the cache.",
+	})
+
 	return set
 }
 
@@ -334,6 +343,10 @@ func (c *SecretsEnableCommand) Run(args []string) int {
 		if fl.Name == flagNamePluginVersion {
 			mountInput.Config.PluginVersion = c.flagPluginVersion
 		}
+
+		if fl.Name == flagNameReadCacheSize {
+			mountInput.Config.ReadCacheSize = &c.flagReadCacheSize
+		}
 	})
 
 	if err := client.Sys().Mount(mountPath, mountInput); err != nil {
@@ -35,6 +35,7 @@ type SecretsTuneCommand struct {
 	flagVersion                   int
 	flagPluginVersion             string
 	flagAllowedManagedKeys        []string
+	flagReadCacheSize             int
 }
 
 func (c *SecretsTuneCommand) Synopsis() string {
@@ -158,6 +159,14 @@ func (c *SecretsTuneCommand) Flags() *FlagSets {
 			"the plugin catalog, and will not start running until the plugin is reloaded.",
 	})
 
+	f.IntVar(&IntVar{
+		Name:   flagNameReadCacheSize,
+		Target: &c.flagReadCacheSize,
+		Usage: "Number of decrypted storage entries of the secrets engine to keep " +
+			"in memory, so that frequent reads of the same entries don't hit " +
+			"storage. Set to 0 to disable the cache.",
+	})
+
 	return set
 }
 
@@ -242,6 +251,10 @@ func (c *SecretsTuneCommand) Run(args []string) int {
 		if fl.Name == flagNamePluginVersion {
 			mountConfigInput.PluginVersion = c.flagPluginVersion
 		}
+
+		if fl.Name == flagNameReadCacheSize {
+			mountConfigInput.ReadCacheSize = &c.flagReadCacheSize
+		}
 	})
 
 	if err := client.Sys().TuneMount(mountPath, mountConfigInput); err != nil {
@@ -1304,6 +1304,19 @@ func (c *ServerCommand) Run(args []string) int {
 	}
 
 	coreConfig := createCoreConfig(c, config, backend, configSR, setSealResponse.barrierSeal, setSealResponse.unwrapSeal, metricsHelper, metricSink, secureRandomReader)
+	for _, keyPath := range config.PluginSigningKeys {
+		pemBytes, err := os.ReadFile(keyPath)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading plugin signing key %q: %s", keyPath, err))
+			return 1
+		}
+		key, err := vault.ParsePluginSigningKey(pemBytes)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error parsing plugin signing key %q: %s", keyPath, err))
+			return 1
+		}
+		coreConfig.PluginSigningKeys = append(coreConfig.PluginSigningKeys, key)
+	}
 	if c.flagDevThreeNode {
 		return c.enableThreeNodeDevCluster(&coreConfig, info, infoKeys, c.flagDevListenAddr, os.Getenv("VAULT_DEV_TEMP_DIR"))
 	}
@@ -3010,6 +3023,7 @@ func createCoreConfig(c *ServerCommand, config *server.Config, backend physical.
 		PluginDirectory:                config.PluginDirectory,
 		PluginFileUid:                  config.PluginFileUid,
 		PluginFilePermissions:          config.PluginFilePermissions,
+		PluginRegistryURL:              config.PluginRegistryURL,
 		EnableUI:                       config.EnableUI,
 		EnableRaw:                      config.EnableRawEndpoint,
 		EnableIntrospection:            config.EnableIntrospectionEndpoint,
@@ -81,6 +81,10 @@ type Config struct {
 	PluginFilePermissions    int         `hcl:"-"`
 	PluginFilePermissionsRaw interface{} `hcl:"plugin_file_permissions,alias:PluginFilePermissions"`
 
+	PluginRegistryURL string `hcl:"plugin_registry_url"`
+
+	PluginSigningKeys []string `hcl:"plugin_signing_keys"`
+
 	EnableIntrospectionEndpoint    bool        `hcl:"-"`
 	EnableIntrospectionEndpointRaw interface{} `hcl:"introspection_endpoint,alias:EnableIntrospectionEndpoint"`
 
@@ -384,6 +388,16 @@ func (c *Config) Merge(c2 *Config) *Config {
 		result.PluginFilePermissionsRaw = c2.PluginFilePermissionsRaw
 	}
 
+	result.PluginRegistryURL = c.PluginRegistryURL
+	if c2.PluginRegistryURL != "" {
+		result.PluginRegistryURL = c2.PluginRegistryURL
+	}
+
+	result.PluginSigningKeys = c.PluginSigningKeys
+	if len(c2.PluginSigningKeys) > 0 {
+		result.PluginSigningKeys = c2.PluginSigningKeys
+	}
+
 	result.DisablePerformanceStandby = c.DisablePerformanceStandby
 	if c2.DisablePerformanceStandby {
 		result.DisablePerformanceStandby = c2.DisablePerformanceStandby
@@ -1129,6 +1143,10 @@ func (c *Config) Sanitized() map[string]interface{} {
 
 		"plugin_file_permissions": c.PluginFilePermissions,
 
+		"plugin_registry_url": c.PluginRegistryURL,
+
+		"plugin_signing_keys": c.PluginSigningKeys,
+
 		"raw_storage_endpoint": c.EnableRawEndpoint,
 
 		"introspection_endpoint": c.EnableIntrospectionEndpoint,
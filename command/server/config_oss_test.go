@@ -7,16 +7,24 @@ package server
 
 import (
 	"testing"
+
+	"github.com/hashicorp/vault/internalshared/configutil"
 )
 
 func TestLoadConfigFile_topLevel(t *testing.T) {
-	testLoadConfigFile_topLevel(t, nil)
+	testLoadConfigFile_topLevel(t, &configutil.Entropy{
+		Type: configutil.EntropyTypeSeal,
+		Mode: configutil.EntropyAugmentation,
+	})
 }
 
 func TestLoadConfigFile_json2(t *testing.T) {
-	testLoadConfigFile_json2(t, nil)
+	testLoadConfigFile_json2(t, &configutil.Entropy{
+		Type: configutil.EntropyTypeSeal,
+		Mode: configutil.EntropyAugmentation,
+	})
 }
 
 func TestParseEntropy(t *testing.T) {
-	testParseEntropy(t, true)
+	testParseEntropy(t, false)
 }
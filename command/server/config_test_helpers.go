@@ -281,19 +281,33 @@ func testParseEntropy(t *testing.T, oss bool) {
 				mode = "augmentation"
 				}`,
 			outErr:     nil,
-			outEntropy: configutil.Entropy{Mode: configutil.EntropyAugmentation},
+			outEntropy: configutil.Entropy{Type: configutil.EntropyTypeSeal, Mode: configutil.EntropyAugmentation},
+		},
+		{
+			inConfig: `entropy "file" {
+				mode = "augmentation"
+				path = "/dev/hwrng"
+				}`,
+			outErr:     nil,
+			outEntropy: configutil.Entropy{Type: configutil.EntropyTypeFile, Mode: configutil.EntropyAugmentation, Path: "/dev/hwrng"},
+		},
+		{
+			inConfig: `entropy "file" {
+				mode = "augmentation"
+				}`,
+			outErr: fmt.Errorf("entropy.file: 'path' is required"),
 		},
 		{
 			inConfig: `entropy "seal" {
 				mode = "a_mode_that_is_not_supported"
 				}`,
-			outErr: fmt.Errorf("the specified entropy mode %q is not supported", "a_mode_that_is_not_supported"),
+			outErr: fmt.Errorf("entropy.seal: unknown mode %q, must be \"augmentation\"", "a_mode_that_is_not_supported"),
 		},
 		{
 			inConfig: `entropy "device_that_is_not_supported" {
 				mode = "augmentation"
 				}`,
-			outErr: fmt.Errorf("only the %q type of external entropy is supported", "seal"),
+			outErr: fmt.Errorf("unknown entropy type %q, must be %q or %q", "device_that_is_not_supported", "seal", "file"),
 		},
 		{
 			inConfig: `entropy "seal" {
@@ -770,6 +784,8 @@ func testConfig_Sanitized(t *testing.T) {
 		"experiments":                         []string(nil),
 		"plugin_file_uid":                     0,
 		"plugin_file_permissions":             0,
+		"plugin_registry_url":                 "",
+		"plugin_signing_keys":                 []string(nil),
 		"disable_printable_check":             false,
 		"disable_sealwrap":                    true,
 		"raw_storage_endpoint":                true,
@@ -326,6 +326,46 @@ func TestTCPListener_proxyProtocol(t *testing.T) {
 				TransportProtocol: proxyproto.UNSPEC,
 			},
 		},
+		// PROXY protocol v2 headers may carry TLVs, such as the VPC endpoint
+		// ID added by AWS Network Load Balancers, which must not prevent the
+		// client address from being used
+		"use_always-header-v2-tlvs": {
+			Behavior:     "use_always",
+			ExpectedAddr: "10.1.1.1",
+			Header: proxyHeaderWithTLVs(&proxyproto.Header{
+				Version:           2,
+				Command:           proxyproto.PROXY,
+				TransportProtocol: proxyproto.TCPv4,
+				SourceAddr: &net.TCPAddr{
+					IP:   net.ParseIP("10.1.1.1"),
+					Port: 1000,
+				},
+				DestinationAddr: &net.TCPAddr{
+					IP:   net.ParseIP("20.2.2.2"),
+					Port: 2000,
+				},
+			}, []proxyproto.TLV{
+				{Type: proxyproto.PP2_TYPE_AUTHORITY, Value: []byte("vault.example.com")},
+				{Type: 0xEA, Value: append([]byte{0x01}, []byte("vpce-08d2bf15fac5001c9")...)},
+			}),
+		},
+		"use_always-header-v2-tcp6": {
+			Behavior:     "use_always",
+			ExpectedAddr: "2001:db8::1",
+			Header: &proxyproto.Header{
+				Version:           2,
+				Command:           proxyproto.PROXY,
+				TransportProtocol: proxyproto.TCPv6,
+				SourceAddr: &net.TCPAddr{
+					IP:   net.ParseIP("2001:db8::1"),
+					Port: 1000,
+				},
+				DestinationAddr: &net.TCPAddr{
+					IP:   net.ParseIP("2001:db8::2"),
+					Port: 2000,
+				},
+			},
+		},
 		"allow_authorized-no-header-in": {
 			Behavior:       "allow_authorized",
 			AuthorizedAddr: "127.0.0.1/32",
@@ -405,6 +445,45 @@ func TestTCPListener_proxyProtocol(t *testing.T) {
 				},
 			},
 		},
+		"deny_unauthorized-v2-tlvs-in": {
+			Behavior:       "deny_unauthorized",
+			AuthorizedAddr: "127.0.0.1/32",
+			ExpectedAddr:   "10.1.1.1",
+			Header: proxyHeaderWithTLVs(&proxyproto.Header{
+				Version:           2,
+				Command:           proxyproto.PROXY,
+				TransportProtocol: proxyproto.TCPv4,
+				SourceAddr: &net.TCPAddr{
+					IP:   net.ParseIP("10.1.1.1"),
+					Port: 1000,
+				},
+				DestinationAddr: &net.TCPAddr{
+					IP:   net.ParseIP("20.2.2.2"),
+					Port: 2000,
+				},
+			}, []proxyproto.TLV{
+				{Type: proxyproto.PP2_TYPE_UNIQUE_ID, Value: []byte("e7e8b4c5")},
+			}),
+		},
+		"deny_unauthorized-v2-not-in": {
+			Behavior:       "deny_unauthorized",
+			AuthorizedAddr: "10.0.0.1/32",
+			ExpectedAddr:   "127.0.0.1",
+			ExpectError:    true,
+			Header: &proxyproto.Header{
+				Version:           2,
+				Command:           proxyproto.PROXY,
+				TransportProtocol: proxyproto.TCPv4,
+				SourceAddr: &net.TCPAddr{
+					IP:   net.ParseIP("10.1.1.1"),
+					Port: 1000,
+				},
+				DestinationAddr: &net.TCPAddr{
+					IP:   net.ParseIP("20.2.2.2"),
+					Port: 2000,
+				},
+			},
+		},
 		"deny_unauthorized-v1-not-in": {
 			Behavior:       "deny_unauthorized",
 			AuthorizedAddr: "10.0.0.1/32",
@@ -464,3 +543,11 @@ func TestTCPListener_proxyProtocol(t *testing.T) {
 		})
 	}
 }
+
+// proxyHeaderWithTLVs attaches the given TLVs to a PROXY protocol v2 header.
+func proxyHeaderWithTLVs(header *proxyproto.Header, tlvs []proxyproto.TLV) *proxyproto.Header {
+	if err := header.SetTLVs(tlvs); err != nil {
+		panic(err)
+	}
+	return header
+}
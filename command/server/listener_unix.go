@@ -19,18 +19,7 @@ func unixListenerFactory(l *configutil.Listener, _ io.Writer, ui cli.Ui) (net.Li
 		addr = "/run/vault.sock"
 	}
 
-	var cfg *listenerutil.UnixSocketsConfig
-	if l.SocketMode != "" &&
-		l.SocketUser != "" &&
-		l.SocketGroup != "" {
-		cfg = &listenerutil.UnixSocketsConfig{
-			Mode:  l.SocketMode,
-			User:  l.SocketUser,
-			Group: l.SocketGroup,
-		}
-	}
-
-	ln, err := listenerutil.UnixSocketListener(addr, cfg)
+	ln, err := listenerutil.UnixSocketListener(addr, listenerutil.UnixSocketsConfigFromListener(l))
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -5,6 +5,7 @@ package server
 
 import (
 	"net"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -26,3 +27,25 @@ func TestUnixListener(t *testing.T) {
 
 	testListenerImpl(t, ln, connFn, "", 0, "", false)
 }
+
+func TestUnixListener_SocketMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.sock")
+
+	// Only the mode is set; the owner defaults to the current user and group.
+	ln, _, _, err := unixListenerFactory(&configutil.Listener{
+		Address:    path,
+		SocketMode: "600",
+	}, nil, cli.NewMockUi())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer ln.Close()
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Fatalf("expected socket mode 0600, got %o", fi.Mode().Perm())
+	}
+}
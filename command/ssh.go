@@ -13,6 +13,7 @@ import (
 	"os/user"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/builtin/logical/ssh"
@@ -45,6 +46,9 @@ type SSHCommand struct {
 	flagHostKeyMountPoint string
 	flagHostKeyHostnames  string
 	flagValidPrincipals   string
+	flagCertificatePath   string
+	flagRenewBefore       time.Duration
+	flagProxyCommand      bool
 }
 
 func (c *SSHCommand) Synopsis() string {
@@ -78,6 +82,12 @@ Usage: vault ssh [options] username@ip [ssh options]
           -host-key-hostnames=example.com \
           user@example.com
 
+  Keep a signed certificate next to the private key and renew it before it
+  expires, by using vault ssh as the ProxyCommand in ~/.ssh/config:
+
+      Host *.example.com
+        ProxyCommand vault ssh -mode=ca -role=my-role -proxy-command %r@%h -p %p
+
   For the full list of options and arguments, please see the documentation.
 
 ` + c.Flags().Help()
@@ -208,6 +218,40 @@ func (c *SSHCommand) Flags() *FlagSets {
 			"user certificate. This is specified as a comma-separated list of values.",
 	})
 
+	f.StringVar(&StringVar{
+		Name:       "certificate-path",
+		Target:     &c.flagCertificatePath,
+		Default:    "",
+		EnvVar:     "VAULT_SSH_CERTIFICATE_PATH",
+		Completion: complete.PredictFiles("*"),
+		Usage: "Path to keep the signed certificate at. When set, the " +
+			"certificate is reused until it is about to expire instead of signing " +
+			"the public key on every connection. With -proxy-command, this " +
+			"defaults to the private key path with a \"-cert.pub\" suffix, which " +
+			"ssh loads automatically.",
+	})
+
+	f.DurationVar(&DurationVar{
+		Name:       "renew-before",
+		Target:     &c.flagRenewBefore,
+		Default:    time.Minute,
+		EnvVar:     "VAULT_SSH_RENEW_BEFORE",
+		Completion: complete.PredictAnything,
+		Usage: "Sign a new certificate when the certificate at " +
+			"-certificate-path expires within this duration.",
+	})
+
+	f.BoolVar(&BoolVar{
+		Name:       "proxy-command",
+		Target:     &c.flagProxyCommand,
+		Default:    false,
+		EnvVar:     "",
+		Completion: complete.PredictNothing,
+		Usage: "Run as the ProxyCommand of ssh. The certificate at " +
+			"-certificate-path is renewed if needed and the connection to the " +
+			"host is relayed over stdin and stdout. Requires -mode=ca and -role.",
+	})
+
 	f.StringVar(&StringVar{
 		Name:       "ssh-executable",
 		Target:     &c.flagSSHExecutable,
@@ -250,6 +294,7 @@ func (c *SSHCommand) Run(args []string) int {
 	c.flagUserKnownHostsFile = expandPath(c.flagUserKnownHostsFile)
 	c.flagPublicKeyPath = expandPath(c.flagPublicKeyPath)
 	c.flagPrivateKeyPath = expandPath(c.flagPrivateKeyPath)
+	c.flagCertificatePath = expandPath(c.flagCertificatePath)
 
 	args = f.Args()
 	if len(args) < 1 {
@@ -280,6 +325,13 @@ func (c *SSHCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Anything written to stdout in proxy mode would corrupt the connection,
+	// so refuse to guess the mode or role.
+	if c.flagProxyCommand && (strings.ToLower(c.flagMode) != ssh.KeyTypeCA || c.flagRole == "") {
+		c.UI.Error("-proxy-command requires -mode=ca and -role to be set")
+		return 1
+	}
+
 	// Set the client in the command
 	_, err = c.Client()
 	if err != nil {
@@ -373,54 +425,51 @@ func (c *SSHCommand) handleTypeCA(username, ip, port string, sshArgs []string) i
 		return 1
 	}
 
-	sshClient := c.client.SSHWithMountPoint(c.flagMountPoint)
-
 	principals := username
 	if c.flagValidPrincipals != "" {
 		principals = c.flagValidPrincipals
 	}
 
-	// Attempt to sign the public key
-	secret, err := sshClient.SignKey(c.flagRole, map[string]interface{}{
-		// WARNING: publicKey is []byte, which is b64 encoded on JSON upload. We
-		// have to convert it to a string. SV lost many hours to this...
-		"public_key":       string(publicKey),
-		"valid_principals": principals,
-		"cert_type":        "user",
-
-		// TODO: let the user configure these. In the interim, if users want to
-		// customize these values, they can produce the key themselves.
-		"extensions": map[string]string{
-			"permit-X11-forwarding":   "",
-			"permit-agent-forwarding": "",
-			"permit-port-forwarding":  "",
-			"permit-pty":              "",
-			"permit-user-rc":          "",
-		},
-	})
-	if err != nil {
-		c.UI.Error(fmt.Sprintf("failed to sign public key %s: %s",
-			c.flagPublicKeyPath, err))
-		return 2
-	}
-	if secret == nil || secret.Data == nil {
-		c.UI.Error("missing signed key")
-		return 2
+	if c.flagProxyCommand {
+		return c.handleProxyCommand(username, ip, port, publicKey, principals)
 	}
 
-	// Handle no-exec
-	if c.flagNoExec {
-		if c.flagField != "" {
-			return PrintRawField(c.UI, secret, c.flagField)
+	var key string
+	switch {
+	case c.flagCertificatePath != "" && !c.flagNoExec:
+		key, err = c.ensureSSHCertificate(c.flagCertificatePath, publicKey, principals)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 2
+		}
+	default:
+		// Attempt to sign the public key
+		secret, err := c.signSSHKey(publicKey, principals)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("failed to sign public key %s: %s",
+				c.flagPublicKeyPath, err))
+			return 2
+		}
+		if secret == nil || secret.Data == nil {
+			c.UI.Error("missing signed key")
+			return 2
 		}
-		return OutputSecret(c.UI, secret)
-	}
 
-	// Extract public key
-	key, ok := secret.Data["signed_key"].(string)
-	if !ok || key == "" {
-		c.UI.Error("signed key is empty")
-		return 2
+		// Handle no-exec
+		if c.flagNoExec {
+			if c.flagField != "" {
+				return PrintRawField(c.UI, secret, c.flagField)
+			}
+			return OutputSecret(c.UI, secret)
+		}
+
+		// Extract public key
+		var ok bool
+		key, ok = secret.Data["signed_key"].(string)
+		if !ok || key == "" {
+			c.UI.Error("signed key is empty")
+			return 2
+		}
 	}
 
 	// Capture the current value - this could be overwritten later if the user
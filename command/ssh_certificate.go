@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// defaultSSHCertificatePath returns the path OpenSSH loads the certificate of
+// the given private key from, when no CertificateFile is configured.
+func defaultSSHCertificatePath(privateKeyPath string) string {
+	return privateKeyPath + "-cert.pub"
+}
+
+// sshCertificateValid reports whether the certificate at path was issued for
+// publicKey and principals, and remains valid for longer than renewBefore.
+func sshCertificateValid(path string, publicKey []byte, principals []string, renewBefore time.Duration) bool {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	parsed, _, _, _, err := cryptossh.ParseAuthorizedKey(raw)
+	if err != nil {
+		return false
+	}
+	cert, ok := parsed.(*cryptossh.Certificate)
+	if !ok || cert.CertType != cryptossh.UserCert {
+		return false
+	}
+
+	key, _, _, _, err := cryptossh.ParseAuthorizedKey(publicKey)
+	if err != nil || !bytes.Equal(cert.Key.Marshal(), key.Marshal()) {
+		return false
+	}
+
+	for _, principal := range principals {
+		found := false
+		for _, valid := range cert.ValidPrincipals {
+			if valid == principal {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if cert.ValidBefore == cryptossh.CertTimeInfinity {
+		return true
+	}
+	validBefore := time.Unix(int64(cert.ValidBefore), 0)
+	return time.Now().Add(renewBefore).Before(validBefore)
+}
+
+// writeSSHCertificate atomically replaces the certificate at path, so that an
+// ssh process reading it never observes a partially written file.
+func writeSSHCertificate(path string, cert string) error {
+	if !strings.HasSuffix(cert, "\n") {
+		cert += "\n"
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteString(cert)
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// signSSHKey asks the SSH secrets engine to sign the given public key for
+// the given principals.
+func (c *SSHCommand) signSSHKey(publicKey []byte, principals string) (*api.Secret, error) {
+	sshClient := c.client.SSHWithMountPoint(c.flagMountPoint)
+
+	// Attempt to sign the public key
+	return sshClient.SignKey(c.flagRole, map[string]interface{}{
+		// WARNING: publicKey is []byte, which is b64 encoded on JSON upload. We
+		// have to convert it to a string. SV lost many hours to this...
+		"public_key":       string(publicKey),
+		"valid_principals": principals,
+		"cert_type":        "user",
+
+		// TODO: let the user configure these. In the interim, if users want to
+		// customize these values, they can produce the key themselves.
+		"extensions": map[string]string{
+			"permit-X11-forwarding":   "",
+			"permit-agent-forwarding": "",
+			"permit-port-forwarding":  "",
+			"permit-pty":              "",
+			"permit-user-rc":          "",
+		},
+	})
+}
+
+// ensureSSHCertificate makes sure a valid certificate for publicKey is stored
+// at path, signing a new one if the existing certificate is missing, was
+// issued for other principals or expires within -renew-before.
+func (c *SSHCommand) ensureSSHCertificate(path string, publicKey []byte, principals string) (string, error) {
+	if sshCertificateValid(path, publicKey, strings.Split(principals, ","), c.flagRenewBefore) {
+		cert, err := os.ReadFile(path)
+		if err == nil {
+			return string(cert), nil
+		}
+	}
+
+	secret, err := c.signSSHKey(publicKey, principals)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign public key %s: %w", c.flagPublicKeyPath, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return "", fmt.Errorf("missing signed key")
+	}
+	key, ok := secret.Data["signed_key"].(string)
+	if !ok || key == "" {
+		return "", fmt.Errorf("signed key is empty")
+	}
+
+	if err := writeSSHCertificate(path, key); err != nil {
+		return "", fmt.Errorf("failed to write signed public key to %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// handleProxyCommand is used when vault ssh runs as the ProxyCommand of ssh.
+// It renews the certificate at the known location if needed and then relays
+// the connection to the host over stdin and stdout.
+func (c *SSHCommand) handleProxyCommand(username, ip, port string, publicKey []byte, principals string) int {
+	certPath := c.flagCertificatePath
+	if certPath == "" {
+		certPath = defaultSSHCertificatePath(c.flagPrivateKeyPath)
+	}
+
+	if _, err := c.ensureSSHCertificate(certPath, publicKey, principals); err != nil {
+		c.UI.Error(err.Error())
+		return 2
+	}
+
+	if port == "" {
+		port = "22"
+	}
+	conn, err := net.Dial("tcp", net.JoinHostPort(ip, port))
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("failed to connect to %s@%s: %s", username, net.JoinHostPort(ip, port), err))
+		return 2
+	}
+	defer conn.Close()
+
+	if err := proxySSHConn(conn, os.Stdin, os.Stdout); err != nil {
+		c.UI.Error(fmt.Sprintf("failed to proxy ssh connection: %s", err))
+		return 2
+	}
+	return 0
+}
+
+// proxySSHConn copies data between conn and stdin/stdout until the remote
+// side closes the connection.
+func proxySSHConn(conn net.Conn, stdin io.Reader, stdout io.Writer) error {
+	go func() {
+		io.Copy(conn, stdin)
+		if tcpConn, ok := conn.(interface{ CloseWrite() error }); ok {
+			tcpConn.CloseWrite()
+		}
+	}()
+
+	_, err := io.Copy(stdout, conn)
+	return err
+}
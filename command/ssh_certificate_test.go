@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package command
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+func testSSHPublicKey(t *testing.T) []byte {
+	t.Helper()
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := cryptossh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cryptossh.MarshalAuthorizedKey(sshPub)
+}
+
+func testSSHCertificateMount(t *testing.T, client *api.Client) {
+	t.Helper()
+
+	if err := client.Sys().Mount("ssh", &api.MountInput{Type: "ssh"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Logical().Write("ssh/config/ca", map[string]interface{}{
+		"generate_signing_key": true,
+		"key_type":             "ed25519",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Logical().Write("ssh/roles/user", map[string]interface{}{
+		"key_type":                "ca",
+		"allow_user_certificates": true,
+		"allowed_users":           "*",
+		"allowed_extensions":      "*",
+		"ttl":                     "1h",
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSSHCommand_EnsureCertificate(t *testing.T) {
+	t.Parallel()
+
+	client, closer := testVaultServer(t)
+	defer closer()
+	testSSHCertificateMount(t, client)
+
+	_, cmd := testSSHCommand(t)
+	cmd.client = client
+	cmd.flagMountPoint = "ssh/"
+	cmd.flagRole = "user"
+	cmd.flagRenewBefore = time.Minute
+
+	publicKey := testSSHPublicKey(t)
+	certPath := filepath.Join(t.TempDir(), "id_ed25519-cert.pub")
+
+	first, err := cmd.ensureSSHCertificate(certPath, publicKey, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sshCertificateValid(certPath, publicKey, []string{"alice"}, time.Minute) {
+		t.Fatal("expected a valid certificate to be written")
+	}
+
+	// A valid certificate is reused.
+	second, err := cmd.ensureSSHCertificate(certPath, publicKey, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("expected the existing certificate to be reused")
+	}
+
+	// A certificate expiring within -renew-before is renewed.
+	if sshCertificateValid(certPath, publicKey, []string{"alice"}, 2*time.Hour) {
+		t.Error("expected the certificate to need renewal within 2h")
+	}
+	cmd.flagRenewBefore = 2 * time.Hour
+	third, err := cmd.ensureSSHCertificate(certPath, publicKey, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == second {
+		t.Error("expected a new certificate to be signed")
+	}
+
+	// Certificates for other keys or principals are not reused.
+	if sshCertificateValid(certPath, testSSHPublicKey(t), []string{"alice"}, time.Minute) {
+		t.Error("expected the certificate to be invalid for another key")
+	}
+	if sshCertificateValid(certPath, publicKey, []string{"bob"}, time.Minute) {
+		t.Error("expected the certificate to be invalid for another principal")
+	}
+
+	info, err := os.Stat(certPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o644 {
+		t.Errorf("expected mode 0644, got %v", info.Mode().Perm())
+	}
+}
+
+func TestSSHCommand_ProxyCommandRequiresRole(t *testing.T) {
+	t.Parallel()
+
+	ui, cmd := testSSHCommand(t)
+	code := cmd.Run([]string{"-proxy-command", "-mode=ca", "user@127.0.0.1"})
+	if exp := 1; code != exp {
+		t.Errorf("expected %d to be %d", code, exp)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "-proxy-command requires") {
+		t.Errorf("unexpected error %q", ui.ErrorWriter.String())
+	}
+}
+
+func TestProxySSHConn(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// Echo everything back until the client closes its side.
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.Copy(conn, conn)
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	var stdout strings.Builder
+	if err := proxySSHConn(conn, strings.NewReader("SSH-2.0-test\r\n"), &stdout); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "SSH-2.0-test\r\n" {
+		t.Errorf("unexpected output %q", stdout.String())
+	}
+}
@@ -14,3 +14,22 @@ type TokenHelper interface {
 	Get() (string, error)
 	Store(string) error
 }
+
+// Context describes the server and namespace a token is used with. It is
+// passed to token helpers that keep separate tokens per server or profile.
+type Context struct {
+	// Address is the address of the Vault server.
+	Address string
+
+	// Namespace is the namespace commands are run in, if any.
+	Namespace string
+}
+
+// ContextualTokenHelper is implemented by token helpers that make use of the
+// server and namespace a token is used with.
+type ContextualTokenHelper interface {
+	TokenHelper
+
+	// SetContext sets the context of subsequent Get, Store and Erase calls.
+	SetContext(Context)
+}
@@ -5,6 +5,7 @@ package token
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -40,7 +41,18 @@ func ExternalTokenHelperPath(path string) (string, error) {
 	return path, nil
 }
 
-var _ TokenHelper = (*ExternalTokenHelper)(nil)
+const (
+	// ExternalTokenHelperProtocolV1 is the original protocol, in which the
+	// token is exchanged as plain text on stdin and stdout.
+	ExternalTokenHelperProtocolV1 = 1
+
+	// ExternalTokenHelperProtocolV2 exchanges JSON documents on stdin and
+	// stdout, which carry the server address, namespace and profile along
+	// with the token.
+	ExternalTokenHelperProtocolV2 = 2
+)
+
+var _ ContextualTokenHelper = (*ExternalTokenHelper)(nil)
 
 // ExternalTokenHelper should only be used in a dev mode. For all other cases,
 // InternalTokenHelper should be used.
@@ -56,13 +68,62 @@ var _ TokenHelper = (*ExternalTokenHelper)(nil)
 //
 // Any errors can be written on stdout. If the helper exits with a non-zero
 // exit code then the stderr will be made part of the error value.
+//
+// With protocol version 2 the operation is still appended as the last
+// argument, but the helper is also given an ExternalTokenHelperRequest as JSON
+// on stdin for every operation, and may write an ExternalTokenHelperResponse
+// as JSON to stdout.
 type ExternalTokenHelper struct {
 	BinaryPath string
 	Env        []string
+
+	// Protocol is the version of the protocol spoken with the helper. It
+	// defaults to ExternalTokenHelperProtocolV1.
+	Protocol int
+
+	// Profile is passed to helpers speaking protocol version 2, so they can
+	// keep several tokens for the same server.
+	Profile string
+
+	context Context
+}
+
+// ExternalTokenHelperRequest is written to the stdin of helpers speaking
+// protocol version 2.
+type ExternalTokenHelperRequest struct {
+	Version   int    `json:"version"`
+	Operation string `json:"operation"`
+	Address   string `json:"address,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+
+	// Token is only set for the "store" operation.
+	Token string `json:"token,omitempty"`
+}
+
+// ExternalTokenHelperResponse is read from the stdout of helpers speaking
+// protocol version 2. An empty output is treated as an empty response.
+type ExternalTokenHelperResponse struct {
+	// Token is the stored token, returned by the "get" operation.
+	Token string `json:"token,omitempty"`
+
+	// Error can be set instead of exiting with a non-zero exit code.
+	Error string `json:"error,omitempty"`
+}
+
+// SetContext sets the server and namespace passed to helpers speaking
+// protocol version 2.
+func (h *ExternalTokenHelper) SetContext(ctx Context) {
+	h.context = ctx
 }
 
 // Erase deletes the contents from the helper.
 func (h *ExternalTokenHelper) Erase() error {
+	if h.Protocol >= ExternalTokenHelperProtocolV2 {
+		_, err := h.exchange("erase", "")
+		return err
+	}
+
 	cmd, err := h.cmd("erase")
 	if err != nil {
 		return err
@@ -75,6 +136,14 @@ func (h *ExternalTokenHelper) Erase() error {
 
 // Get gets the token value from the helper.
 func (h *ExternalTokenHelper) Get() (string, error) {
+	if h.Protocol >= ExternalTokenHelperProtocolV2 {
+		resp, err := h.exchange("get", "")
+		if err != nil {
+			return "", err
+		}
+		return resp.Token, nil
+	}
+
 	var buf, stderr bytes.Buffer
 	cmd, err := h.cmd("get")
 	if err != nil {
@@ -91,6 +160,11 @@ func (h *ExternalTokenHelper) Get() (string, error) {
 
 // Store stores the token value into the helper.
 func (h *ExternalTokenHelper) Store(v string) error {
+	if h.Protocol >= ExternalTokenHelperProtocolV2 {
+		_, err := h.exchange("store", v)
+		return err
+	}
+
 	buf := bytes.NewBufferString(v)
 	cmd, err := h.cmd("store")
 	if err != nil {
@@ -108,6 +182,44 @@ func (h *ExternalTokenHelper) Path() string {
 	return h.BinaryPath
 }
 
+// exchange runs the helper for the given operation using protocol version 2.
+func (h *ExternalTokenHelper) exchange(op, token string) (*ExternalTokenHelperResponse, error) {
+	input, err := json.Marshal(&ExternalTokenHelperRequest{
+		Version:   ExternalTokenHelperProtocolV2,
+		Operation: op,
+		Address:   h.context.Address,
+		Namespace: h.context.Namespace,
+		Profile:   h.Profile,
+		Token:     token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd, err := h.cmd(op)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%q: %w", stderr.String(), err)
+	}
+
+	var resp ExternalTokenHelperResponse
+	if output := bytes.TrimSpace(stdout.Bytes()); len(output) > 0 {
+		if err := json.Unmarshal(output, &resp); err != nil {
+			return nil, fmt.Errorf("error decoding token helper response: %w", err)
+		}
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("token helper returned an error: %s", resp.Error)
+	}
+	return &resp, nil
+}
+
 func (h *ExternalTokenHelper) cmd(op string) (*exec.Cmd, error) {
 	script := strings.ReplaceAll(h.BinaryPath, "\\", "\\\\") + " " + op
 	cmd, err := ExecScript(script)
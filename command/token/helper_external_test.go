@@ -4,6 +4,7 @@
 package token
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -57,6 +58,36 @@ func TestExternalTokenHelper(t *testing.T) {
 	Test(t, testExternalTokenHelper(t))
 }
 
+func TestExternalTokenHelper_ProtocolV2(t *testing.T) {
+	h := &ExternalTokenHelper{
+		BinaryPath: helperPath("helper-v2"),
+		Env:        helperEnv(),
+		Protocol:   ExternalTokenHelperProtocolV2,
+		Profile:    "ops",
+	}
+	h.SetContext(Context{Address: "https://vault.example.com:8200", Namespace: "ns1/"})
+	Test(t, h)
+
+	// The helper keys tokens by address and profile
+	if err := h.Store("foo"); err != nil {
+		t.Fatal(err)
+	}
+	h.SetContext(Context{Address: "https://other.example.com:8200"})
+	v, err := h.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "" {
+		t.Fatalf("expected no token for another address, got %q", v)
+	}
+
+	// Errors in the response are returned
+	h.Profile = "broken"
+	if _, err := h.Get(); err == nil || !strings.Contains(err.Error(), "profile is broken") {
+		t.Fatalf("expected error from helper, got %v", err)
+	}
+}
+
 func testExternalTokenHelper(t *testing.T) *ExternalTokenHelper {
 	return &ExternalTokenHelper{BinaryPath: helperPath("helper"), Env: helperEnv()}
 }
@@ -134,6 +165,42 @@ func TestExternalTokenHelperProcess(*testing.T) {
 			defer f.Close()
 			io.Copy(f, os.Stdin)
 		}
+	case "helper-v2":
+		var req ExternalTokenHelperRequest
+		if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+			fmt.Fprintf(os.Stderr, "Err: %s\n", err)
+			os.Exit(1)
+		}
+		if req.Version != ExternalTokenHelperProtocolV2 || req.Operation != args[0] {
+			fmt.Fprintf(os.Stderr, "Bad request: %#v\n", req)
+			os.Exit(1)
+		}
+		if req.Profile == "broken" {
+			json.NewEncoder(os.Stdout).Encode(&ExternalTokenHelperResponse{Error: "profile is broken"})
+			return
+		}
+
+		tokens := map[string]string{}
+		path := os.Getenv("GO_HELPER_PATH")
+		if raw, err := ioutil.ReadFile(path); err == nil && len(raw) > 0 {
+			json.Unmarshal(raw, &tokens)
+		}
+		key := req.Profile + "@" + req.Address
+
+		switch req.Operation {
+		case "erase":
+			delete(tokens, key)
+		case "get":
+			json.NewEncoder(os.Stdout).Encode(&ExternalTokenHelperResponse{Token: tokens[key]})
+			return
+		case "store":
+			tokens[key] = req.Token
+		}
+		raw, _ := json.Marshal(tokens)
+		if err := ioutil.WriteFile(path, raw, 0o600); err != nil {
+			fmt.Fprintf(os.Stderr, "Err: %s\n", err)
+			os.Exit(1)
+		}
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %q\n", cmd)
 		os.Exit(2)
@@ -0,0 +1,216 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package token
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const (
+	// KeychainBackendMacOS stores tokens in the macOS keychain, using the
+	// "security" tool.
+	KeychainBackendMacOS = "keychain"
+
+	// KeychainBackendSecretService stores tokens with the freedesktop.org
+	// Secret Service (GNOME Keyring, KWallet), using the "secret-tool" tool
+	// of libsecret.
+	KeychainBackendSecretService = "secret-service"
+
+	// keychainService is the service tokens are stored under.
+	keychainService = "vault"
+
+	// keychainDefaultProfile is the profile used when none is configured.
+	keychainDefaultProfile = "default"
+)
+
+var _ ContextualTokenHelper = (*KeychainTokenHelper)(nil)
+
+// KeychainTokenHelper is a built-in token helper that stores tokens in the
+// credential store of the operating system instead of a plain text file.
+// Tokens are stored per server address and profile, so switching VAULT_ADDR
+// does not require logging in again.
+type KeychainTokenHelper struct {
+	// Backend is either KeychainBackendMacOS or KeychainBackendSecretService.
+	Backend string
+
+	// Profile allows to keep several tokens for the same server.
+	Profile string
+
+	// ToolPath overrides the path of the tool used to talk to the credential
+	// store. Tests may set it.
+	ToolPath string
+
+	context Context
+}
+
+// NewKeychainTokenHelper returns a helper storing tokens with the given
+// backend.
+func NewKeychainTokenHelper(backend, profile string) (*KeychainTokenHelper, error) {
+	switch backend {
+	case KeychainBackendMacOS, KeychainBackendSecretService:
+	default:
+		return nil, fmt.Errorf("unknown keychain backend %q", backend)
+	}
+	return &KeychainTokenHelper{Backend: backend, Profile: profile}, nil
+}
+
+// IsKeychainBackend reports whether name is the name of a built-in keychain
+// token helper.
+func IsKeychainBackend(name string) bool {
+	return name == KeychainBackendMacOS || name == KeychainBackendSecretService
+}
+
+// SetContext sets the server address tokens are stored for.
+func (h *KeychainTokenHelper) SetContext(ctx Context) {
+	h.context = ctx
+}
+
+func (h *KeychainTokenHelper) Path() string {
+	return h.Backend
+}
+
+// Get gets the token stored for the current server and profile, if any.
+func (h *KeychainTokenHelper) Get() (string, error) {
+	var args []string
+	switch h.Backend {
+	case KeychainBackendMacOS:
+		args = append([]string{"find-generic-password"}, h.securityArgs()...)
+		args = append(args, "-w")
+	default:
+		args = append([]string{"lookup"}, h.secretToolArgs()...)
+	}
+
+	stdout, stderr, err := h.run(nil, args...)
+	if err != nil {
+		if h.notFound(stderr, err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("error reading token from %s: %q: %w", h.Backend, stderr, err)
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// Store stores the token for the current server and profile, replacing any
+// existing token.
+func (h *KeychainTokenHelper) Store(token string) error {
+	var stderr string
+	var err error
+	switch h.Backend {
+	case KeychainBackendMacOS:
+		// Pass the token on stdin of the interactive mode, so that it never
+		// appears on the command line of a process.
+		command := fmt.Sprintf("add-generic-password -U %s -w %s\n",
+			strings.Join(quoteSecurityArgs(h.securityArgs()), " "), quoteSecurityArg(token))
+		_, stderr, err = h.run(strings.NewReader(command), "-i")
+	default:
+		args := append([]string{"store", "--label=" + h.label()}, h.secretToolArgs()...)
+		_, stderr, err = h.run(strings.NewReader(token), args...)
+	}
+	if err != nil {
+		return fmt.Errorf("error storing token in %s: %q: %w", h.Backend, stderr, err)
+	}
+	return nil
+}
+
+// Erase erases the token stored for the current server and profile.
+func (h *KeychainTokenHelper) Erase() error {
+	var args []string
+	switch h.Backend {
+	case KeychainBackendMacOS:
+		args = append([]string{"delete-generic-password"}, h.securityArgs()...)
+	default:
+		args = append([]string{"clear"}, h.secretToolArgs()...)
+	}
+
+	if _, stderr, err := h.run(nil, args...); err != nil && !h.notFound(stderr, err) {
+		return fmt.Errorf("error erasing token from %s: %q: %w", h.Backend, stderr, err)
+	}
+	return nil
+}
+
+func (h *KeychainTokenHelper) profile() string {
+	if h.Profile == "" {
+		return keychainDefaultProfile
+	}
+	return h.Profile
+}
+
+func (h *KeychainTokenHelper) label() string {
+	return fmt.Sprintf("Vault token for %s (%s)", h.context.Address, h.profile())
+}
+
+// securityArgs returns the arguments selecting the keychain item of the
+// current server and profile.
+func (h *KeychainTokenHelper) securityArgs() []string {
+	return []string{
+		"-s", keychainService + ":" + h.profile(),
+		"-a", h.context.Address,
+	}
+}
+
+// secretToolArgs returns the attributes selecting the secret of the current
+// server and profile.
+func (h *KeychainTokenHelper) secretToolArgs() []string {
+	return []string{
+		"service", keychainService,
+		"address", h.context.Address,
+		"profile", h.profile(),
+	}
+}
+
+// notFound reports whether the tool failed because no token is stored.
+// secret-tool exits without output in that case.
+func (h *KeychainTokenHelper) notFound(stderr string, err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	switch h.Backend {
+	case KeychainBackendMacOS:
+		return strings.Contains(stderr, "could not be found")
+	default:
+		return strings.TrimSpace(stderr) == ""
+	}
+}
+
+func (h *KeychainTokenHelper) run(stdin *strings.Reader, args ...string) (string, string, error) {
+	tool := h.ToolPath
+	if tool == "" {
+		switch h.Backend {
+		case KeychainBackendMacOS:
+			tool = "/usr/bin/security"
+		default:
+			tool = "secret-tool"
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(tool, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// quoteSecurityArg quotes s for the interactive mode of the security tool,
+// which splits commands like a shell.
+func quoteSecurityArg(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func quoteSecurityArgs(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteSecurityArg(arg)
+	}
+	return quoted
+}
@@ -0,0 +1,84 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package token
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// fakeSecretTool mimics the lookup, store and clear commands of secret-tool,
+// storing each secret in a file named after its attributes.
+const fakeSecretTool = `#!/bin/sh
+op=$1
+shift
+if [ "$op" = "store" ]; then
+	shift
+fi
+file="$FAKE_SECRET_DIR/$(echo "$@" | tr -c 'A-Za-z0-9' '_')"
+case "$op" in
+lookup)
+	[ -f "$file" ] || exit 1
+	cat "$file"
+	;;
+store)
+	cat > "$file"
+	;;
+clear)
+	rm -f "$file"
+	;;
+esac
+`
+
+func TestKeychainTokenHelper_SecretService(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "secret-tool")
+	if err := os.WriteFile(tool, []byte(fakeSecretTool), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FAKE_SECRET_DIR", dir)
+
+	h, err := NewKeychainTokenHelper(KeychainBackendSecretService, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ToolPath = tool
+	h.SetContext(Context{Address: "https://vault.example.com:8200"})
+	Test(t, h)
+
+	// Tokens are kept per address and profile
+	if err := h.Store("foo"); err != nil {
+		t.Fatal(err)
+	}
+	other := &KeychainTokenHelper{Backend: KeychainBackendSecretService, Profile: "ops", ToolPath: tool}
+	other.SetContext(Context{Address: "https://vault.example.com:8200"})
+	if v, err := other.Get(); err != nil || v != "" {
+		t.Fatalf("expected no token for another profile, got %q, %v", v, err)
+	}
+	if v, err := h.Get(); err != nil || v != "foo" {
+		t.Fatalf("expected %q, got %q, %v", "foo", v, err)
+	}
+}
+
+func TestKeychainTokenHelper_MissingTool(t *testing.T) {
+	h := &KeychainTokenHelper{
+		Backend:  KeychainBackendSecretService,
+		ToolPath: filepath.Join(t.TempDir(), "nope"),
+	}
+	if _, err := h.Get(); err == nil {
+		t.Fatal("expected an error when the tool is missing")
+	}
+}
+
+func TestNewKeychainTokenHelper_UnknownBackend(t *testing.T) {
+	if _, err := NewKeychainTokenHelper("wincred", ""); err == nil {
+		t.Fatal("expected error")
+	}
+}
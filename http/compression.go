@@ -0,0 +1,311 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package http
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/sdk/helper/pathmanager"
+	"github.com/hashicorp/vault/vault"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+
+	// defaultCompressionMinSize is the response size, in bytes, below which
+	// responses are sent uncompressed as compressing them is not worth it.
+	defaultCompressionMinSize = 1024
+)
+
+var (
+	// defaultCompressionAlgorithms are the algorithms offered when a listener
+	// does not configure any, in order of preference.
+	defaultCompressionAlgorithms = []string{compressionZstd, compressionGzip}
+
+	// defaultCompressionExcludedPaths are never compressed: streaming endpoints
+	// must deliver every write as it is made, and raft snapshots are already
+	// compressed.
+	defaultCompressionExcludedPaths = []string{
+		"sys/monitor",
+		"sys/audit-tail",
+		"sys/events/subscribe/",
+		"sys/pprof/",
+		"sys/storage/raft/snapshot",
+	}
+
+	gzipWriterPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(nil)
+		},
+	}
+
+	zstdWriterPool = sync.Pool{
+		New: func() interface{} {
+			// Options are constant, so NewWriter cannot fail. The window is
+			// kept small since responses are compressed one at a time.
+			w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithWindowSize(1<<20))
+			return w
+		},
+	}
+)
+
+// responseCompressor is implemented by the gzip and zstd writers.
+type responseCompressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// wrapCompressionHandler compresses API responses according to the listener's
+// response_compression configuration. Responses are only compressed when the
+// client accepts one of the configured algorithms and the response is at
+// least the configured minimum size.
+func wrapCompressionHandler(h http.Handler, props *vault.HandlerProperties) http.Handler {
+	if props.ListenerConfig == nil || !props.ListenerConfig.ResponseCompression.Enabled {
+		return h
+	}
+	cfg := props.ListenerConfig.ResponseCompression
+
+	algorithms := cfg.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = defaultCompressionAlgorithms
+	}
+
+	minSize := int(cfg.MinSize)
+	if minSize == 0 {
+		minSize = defaultCompressionMinSize
+	}
+
+	excludedPaths := pathmanager.New()
+	excludedPaths.AddPaths(defaultCompressionExcludedPaths)
+	for _, path := range cfg.ExcludedPaths {
+		excludedPaths.AddPaths([]string{strings.TrimPrefix(strings.TrimPrefix(path, "/"), "v1/")})
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The UI assets are compressed separately, and upgraded or ranged
+		// requests must be left untouched.
+		if !strings.HasPrefix(r.URL.Path, "/v1/") ||
+			r.Method == http.MethodHead ||
+			r.Header.Get("Upgrade") != "" ||
+			r.Header.Get("Range") != "" ||
+			excludedPaths.HasPath(strings.TrimPrefix(r.URL.Path, "/v1/")) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateCompression(r.Header.Get("Accept-Encoding"), algorithms)
+		if encoding == "" {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressResponseWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			minSize:        minSize,
+			statusCode:     http.StatusOK,
+		}
+		defer cw.close()
+
+		h.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateCompression returns the first of the given algorithms accepted by
+// the Accept-Encoding header, or the empty string if there is none.
+func negotiateCompression(acceptEncoding string, algorithms []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	accepted := make(map[string]bool)
+	var wildcard, wildcardSet bool
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+
+		ok := true
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(name) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			ok = err == nil && q > 0
+		}
+
+		if coding == "*" {
+			wildcard, wildcardSet = ok, true
+			continue
+		}
+		accepted[coding] = ok
+	}
+
+	for _, algorithm := range algorithms {
+		if ok, found := accepted[algorithm]; found {
+			if ok {
+				return algorithm
+			}
+			continue
+		}
+		if wildcardSet && wildcard {
+			return algorithm
+		}
+	}
+
+	return ""
+}
+
+// compressResponseWriter buffers the start of a response until it reaches the
+// minimum size and then compresses it. Smaller responses, responses which are
+// flushed before reaching the minimum size and responses that already have a
+// Content-Encoding are sent as they are.
+type compressResponseWriter struct {
+	http.ResponseWriter
+
+	encoding string
+	minSize  int
+
+	statusCode  int
+	wroteHeader bool
+	buf         []byte
+	passthrough bool
+	compressor  responseCompressor
+}
+
+func (w *compressResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader || w.passthrough || w.compressor != nil {
+		return
+	}
+	w.statusCode = code
+
+	// Responses without a body cannot be compressed.
+	if code == http.StatusNoContent || code == http.StatusNotModified || code < http.StatusOK {
+		w.startPassthrough()
+	}
+}
+
+func (w *compressResponseWriter) Write(b []byte) (int, error) {
+	switch {
+	case w.compressor != nil:
+		return w.compressor.Write(b)
+	case w.passthrough:
+		return w.ResponseWriter.Write(b)
+	}
+
+	if w.Header().Get("Content-Encoding") != "" {
+		if err := w.startPassthrough(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.minSize {
+		if err := w.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// Flush sends any buffered data. A response flushed before reaching the
+// minimum size is assumed to be streamed and is not compressed.
+func (w *compressResponseWriter) Flush() {
+	switch {
+	case w.compressor != nil:
+		w.compressor.Flush()
+	case !w.passthrough:
+		w.startPassthrough()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("could not hijack connection: %T is not a http.Hijacker", w.ResponseWriter)
+	}
+	return hj.Hijack()
+}
+
+// startPassthrough sends the header and buffered data without compression.
+func (w *compressResponseWriter) startPassthrough() error {
+	w.passthrough = true
+	w.writeHeader()
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// startCompression sends the header and compresses the buffered data.
+func (w *compressResponseWriter) startCompression() error {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Del("Content-Length")
+	w.writeHeader()
+
+	switch w.encoding {
+	case compressionGzip:
+		w.compressor = gzipWriterPool.Get().(*gzip.Writer)
+	case compressionZstd:
+		w.compressor = zstdWriterPool.Get().(*zstd.Encoder)
+	}
+	w.compressor.Reset(w.ResponseWriter)
+
+	_, err := w.compressor.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+func (w *compressResponseWriter) writeHeader() {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(w.statusCode)
+}
+
+// close finishes the response once the wrapped handler has returned.
+func (w *compressResponseWriter) close() {
+	if w.compressor == nil {
+		if !w.passthrough {
+			w.startPassthrough()
+		}
+		return
+	}
+
+	w.compressor.Close()
+	w.compressor.Reset(nil)
+	switch c := w.compressor.(type) {
+	case *gzip.Writer:
+		gzipWriterPool.Put(c)
+	case *zstd.Encoder:
+		zstdWriterPool.Put(c)
+	}
+	w.compressor = nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package http
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/vault/internalshared/configutil"
+	"github.com/hashicorp/vault/vault"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	t.Parallel()
+
+	algorithms := []string{compressionZstd, compressionGzip}
+	for acceptEncoding, expected := range map[string]string{
+		"":                       "",
+		"identity":               "",
+		"gzip":                   compressionGzip,
+		"gzip, deflate, br":      compressionGzip,
+		"gzip, zstd":             compressionZstd,
+		"GZIP;q=0.5, ZSTD;q=0.1": compressionZstd,
+		"zstd;q=0, gzip":         compressionGzip,
+		"*":                      compressionZstd,
+		"*;q=0":                  "",
+		"zstd;q=0, *":            compressionGzip,
+	} {
+		if actual := negotiateCompression(acceptEncoding, algorithms); actual != expected {
+			t.Errorf("Accept-Encoding %q: expected %q, got %q", acceptEncoding, expected, actual)
+		}
+	}
+
+	if actual := negotiateCompression("zstd", []string{compressionGzip}); actual != "" {
+		t.Errorf("expected an algorithm that is not configured to be ignored, got %q", actual)
+	}
+}
+
+func TestCompressionHandler(t *testing.T) {
+	t.Parallel()
+
+	large := strings.Repeat(`{"key":"value"}`, 200)
+	small := `{"key":"value"}`
+
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/small"):
+			io.WriteString(w, small)
+		case strings.HasSuffix(r.URL.Path, "/streamed"):
+			io.WriteString(w, small)
+			w.(http.Flusher).Flush()
+			io.WriteString(w, large)
+		case strings.HasSuffix(r.URL.Path, "/no-content"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, large)
+		}
+	})
+
+	handler := wrapCompressionHandler(inner, &vault.HandlerProperties{
+		ListenerConfig: &configutil.Listener{
+			ResponseCompression: configutil.ListenerResponseCompression{
+				Enabled:       true,
+				ExcludedPaths: []string{"/v1/secret/excluded"},
+			},
+		},
+	})
+
+	cases := map[string]struct {
+		path           string
+		acceptEncoding string
+		status         int
+		encoding       string
+		body           string
+	}{
+		"gzip":            {"/v1/secret/large", "gzip", http.StatusCreated, compressionGzip, large},
+		"zstd":            {"/v1/secret/large", "gzip, zstd", http.StatusCreated, compressionZstd, large},
+		"not-accepted":    {"/v1/secret/large", "br", http.StatusCreated, "", large},
+		"small":           {"/v1/secret/small", "gzip", http.StatusOK, "", small},
+		"streamed":        {"/v1/secret/streamed", "gzip", http.StatusOK, "", small + large},
+		"no-content":      {"/v1/secret/no-content", "gzip", http.StatusNoContent, "", ""},
+		"excluded":        {"/v1/secret/excluded/large", "gzip", http.StatusCreated, "", large},
+		"default-exclude": {"/v1/sys/monitor", "gzip", http.StatusCreated, "", large},
+		"ui":              {"/ui/large", "gzip", http.StatusCreated, "", large},
+	}
+
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, rec.Code)
+			}
+			if actual := rec.Header().Get("Content-Encoding"); actual != tc.encoding {
+				t.Fatalf("expected Content-Encoding %q, got %q", tc.encoding, actual)
+			}
+
+			var body []byte
+			var err error
+			switch tc.encoding {
+			case compressionGzip:
+				var r *gzip.Reader
+				if r, err = gzip.NewReader(rec.Body); err == nil {
+					body, err = io.ReadAll(r)
+				}
+			case compressionZstd:
+				var r *zstd.Decoder
+				if r, err = zstd.NewReader(rec.Body); err == nil {
+					body, err = io.ReadAll(r)
+					r.Close()
+				}
+			default:
+				body = rec.Body.Bytes()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(body, []byte(tc.body)) {
+				t.Fatalf("unexpected body: %q", body)
+			}
+		})
+	}
+}
+
+func TestCompressionHandler_Disabled(t *testing.T) {
+	t.Parallel()
+
+	inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("a", 4096))
+	})
+	handler := wrapCompressionHandler(inner, &vault.HandlerProperties{
+		ListenerConfig: &configutil.Listener{},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/secret/foo", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if actual := rec.Header().Get("Content-Encoding"); actual != "" {
+		t.Fatalf("expected no Content-Encoding, got %q", actual)
+	}
+}
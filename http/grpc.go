@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/vault/sdk/grpcapi"
+	"github.com/hashicorp/vault/sdk/helper/pathmanager"
+	"github.com/hashicorp/vault/vault"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcOperationMethods maps the operations of a gRPC API request to the HTTP
+// method of the equivalent HTTP request.
+var grpcOperationMethods = map[string]string{
+	"read":   http.MethodGet,
+	"list":   "LIST",
+	"create": http.MethodPost,
+	"update": http.MethodPost,
+	"patch":  http.MethodPatch,
+	"delete": http.MethodDelete,
+}
+
+// grpcUnsupportedPaths are streaming endpoints, which cannot be used through
+// the gRPC API as their response never completes.
+var grpcUnsupportedPaths = pathmanager.New()
+
+func init() {
+	grpcUnsupportedPaths.AddPaths([]string{
+		"sys/monitor",
+		"sys/audit-tail",
+		"sys/events/subscribe/",
+		"sys/pprof/",
+	})
+}
+
+// grpcIgnoredMetadata are gRPC metadata keys which describe the gRPC call
+// itself and so are not passed on as request headers.
+var grpcIgnoredMetadata = map[string]struct{}{
+	"content-type": {},
+	"te":           {},
+	"user-agent":   {},
+}
+
+// grpcAPIServer implements the gRPC API. Each request is turned into the
+// equivalent HTTP request and run through the regular API handler, so that
+// authentication, namespaces, quotas, request forwarding and auditing behave
+// exactly as they do over HTTP.
+type grpcAPIServer struct {
+	grpcapi.UnimplementedVaultServer
+
+	handler http.Handler
+}
+
+// wrapGRPCHandler serves the gRPC API on the listener when it is enabled with
+// grpc_enabled, sending every other request to the given handler. gRPC
+// requires HTTP/2, which is negotiated over TLS; on listeners without TLS,
+// HTTP/2 cleartext is accepted instead.
+func wrapGRPCHandler(h http.Handler, props *vault.HandlerProperties) http.Handler {
+	if props.ListenerConfig == nil || !props.ListenerConfig.GRPCEnabled {
+		return h
+	}
+
+	server := grpc.NewServer()
+	grpcapi.RegisterVaultServer(server, &grpcAPIServer{handler: h})
+
+	wrapped := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			server.ServeHTTP(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+
+	if props.ListenerConfig.TLSDisable {
+		wrapped = h2c.NewHandler(wrapped, &http2.Server{})
+	}
+
+	return wrapped
+}
+
+// Do handles a single request.
+func (s *grpcAPIServer) Do(ctx context.Context, req *grpcapi.Request) (*grpcapi.Response, error) {
+	return s.handle(ctx, req)
+}
+
+// DoStream handles the requests sent on the stream in order.
+func (s *grpcAPIServer) DoStream(stream grpcapi.Vault_DoStreamServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.handle(stream.Context(), req)
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// handle runs the request through the API handler and captures the result.
+func (s *grpcAPIServer) handle(ctx context.Context, req *grpcapi.Request) (*grpcapi.Response, error) {
+	r, err := newGRPCHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, r)
+
+	result := rec.Result()
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read response: %v", err)
+	}
+
+	headers := make(map[string]string, len(result.Header))
+	for k, v := range result.Header {
+		headers[strings.ToLower(k)] = strings.Join(v, ", ")
+	}
+
+	return &grpcapi.Response{
+		Id:         req.Id,
+		StatusCode: int32(result.StatusCode),
+		Headers:    headers,
+		Data:       data,
+	}, nil
+}
+
+// newGRPCHTTPRequest builds the HTTP request equivalent to the gRPC request,
+// taking the headers from the call's metadata and the remote address and TLS
+// state from its peer.
+func newGRPCHTTPRequest(ctx context.Context, req *grpcapi.Request) (*http.Request, error) {
+	method, ok := grpcOperationMethods[strings.ToLower(req.Operation)]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported operation %q", req.Operation)
+	}
+
+	path := strings.TrimPrefix(req.Path, "/")
+	switch {
+	case path == "":
+		return nil, status.Error(codes.InvalidArgument, "missing path")
+	case grpcUnsupportedPaths.HasPath(path):
+		return nil, status.Errorf(codes.Unimplemented, "streaming endpoint %q is not available over gRPC", path)
+	}
+
+	u := &url.URL{Path: "/v1/" + path}
+	if len(req.Query) > 0 {
+		query := make(url.Values, len(req.Query))
+		for k, v := range req.Query {
+			query.Set(k, v)
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	// As for requests received by the HTTP server, the body is never nil.
+	var body io.Reader = http.NoBody
+	if len(req.Data) > 0 {
+		body = bytes.NewReader(req.Data)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if authority := md.Get(":authority"); len(authority) > 0 {
+			r.Host = authority[0]
+		}
+		for k, values := range md {
+			if _, ignored := grpcIgnoredMetadata[k]; ignored || strings.HasPrefix(k, ":") || strings.HasPrefix(k, "grpc-") {
+				continue
+			}
+			for _, v := range values {
+				r.Header.Add(k, v)
+			}
+		}
+	}
+
+	switch method {
+	case http.MethodPatch:
+		r.Header.Set("Content-Type", MergePatchContentTypeHeader)
+	default:
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			r.RemoteAddr = p.Addr.String()
+		}
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state := tlsInfo.State
+			r.TLS = &state
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/vault/internalshared/configutil"
+	"github.com/hashicorp/vault/sdk/grpcapi"
+	"github.com/hashicorp/vault/vault"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAPI(t *testing.T) {
+	core, _, token := vault.TestCoreUnsealed(t)
+	ln, _ := TestListener(t)
+	defer ln.Close()
+
+	// The gRPC API relies on HTTP/2 cleartext when TLS is disabled, which
+	// requires the handler to be served directly rather than through a mux.
+	server := &http.Server{
+		Handler: Handler.Handler(&vault.HandlerProperties{
+			Core: core,
+			ListenerConfig: &configutil.Listener{
+				TLSDisable:  true,
+				GRPCEnabled: true,
+			},
+		}),
+	}
+	go server.Serve(ln)
+	defer server.Close()
+
+	conn, err := grpc.Dial(ln.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := grpcapi.NewVaultClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Requests without a token are rejected just as over HTTP
+	resp, err := client.Do(ctx, &grpcapi.Request{Operation: "read", Path: "sys/mounts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusForbidden, resp.StatusCode, resp.Data)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-vault-token", token)
+
+	if _, err := client.Do(ctx, &grpcapi.Request{Operation: "frobnicate", Path: "sys/mounts"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected %v, got %v", codes.InvalidArgument, err)
+	}
+	if _, err := client.Do(ctx, &grpcapi.Request{Operation: "read", Path: "sys/monitor"}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("expected %v, got %v", codes.Unimplemented, err)
+	}
+
+	stream, err := client.DoStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []*grpcapi.Request{
+		{Id: "write", Operation: "update", Path: "cubbyhole/foo", Data: []byte(`{"bar":"baz"}`)},
+		{Id: "read", Operation: "read", Path: "cubbyhole/foo"},
+		{Id: "list", Operation: "list", Path: "cubbyhole/"},
+	} {
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []struct {
+		id     string
+		status int32
+	}{
+		{"write", http.StatusNoContent},
+		{"read", http.StatusOK},
+		{"list", http.StatusOK},
+	} {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Id != expected.id || resp.StatusCode != expected.status {
+			t.Fatalf("expected response %q with status %d, got %q with status %d: %s", expected.id, expected.status, resp.Id, resp.StatusCode, resp.Data)
+		}
+
+		switch resp.Id {
+		case "read":
+			var body struct {
+				Data map[string]interface{} `json:"data"`
+			}
+			if err := json.Unmarshal(resp.Data, &body); err != nil {
+				t.Fatal(err)
+			}
+			if body.Data["bar"] != "baz" {
+				t.Fatalf("unexpected data: %s", resp.Data)
+			}
+		case "list":
+			var body struct {
+				Data struct {
+					Keys []string `json:"keys"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(resp.Data, &body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Data.Keys) != 1 || body.Data.Keys[0] != "foo" {
+				t.Fatalf("unexpected keys: %s", resp.Data)
+			}
+		}
+	}
+}
@@ -93,7 +93,7 @@ func buildLogicalRequestNoAuth(perfStandby bool, w http.ResponseWriter, r *http.
 			responseWriter = w
 		case path == "sys/internal/counters/activity/export":
 			responseWriter = w
-		case path == "sys/monitor":
+		case path == "sys/monitor", path == "sys/audit-tail":
 			passHTTPReq = true
 			responseWriter = w
 		}
@@ -106,11 +106,13 @@ func buildLogicalRequestNoAuth(perfStandby bool, w http.ResponseWriter, r *http.
 		bufferedBody := newBufferedReader(r.Body)
 		r.Body = bufferedBody
 
-		// If we are uploading a snapshot or receiving an ocsp-request (which
-		// is der encoded) we don't want to parse it. Instead, we will simply
-		// add the HTTP request to the logical request object for later consumption.
+		// If we are uploading a snapshot, receiving an ocsp-request (which
+		// is der encoded), an EST pkcs10, SCEP pki-message or CMP pkixcmp
+		// enrollment request or an RFC 3161 timestamp-query we don't want to
+		// parse it. Instead, we will simply add the HTTP request to the
+		// logical request object for later consumption.
 		contentType := r.Header.Get("Content-Type")
-		if path == "sys/storage/raft/snapshot" || path == "sys/storage/raft/snapshot-force" || isOcspRequest(contentType) {
+		if path == "sys/storage/raft/snapshot" || path == "sys/storage/raft/snapshot-force" || isOcspRequest(contentType) || isEnrollmentRequest(contentType) {
 			passHTTPReq = true
 			origBody = r.Body
 		} else {
@@ -224,6 +226,20 @@ func isOcspRequest(contentType string) bool {
 	return contentType == "application/ocsp-request"
 }
 
+func isEnrollmentRequest(contentType string) bool {
+	contentType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch contentType {
+	case "application/pkcs10", "application/x-pki-message", "application/pkixcmp", "application/timestamp-query":
+		return true
+	default:
+		return false
+	}
+}
+
 func buildLogicalPath(r *http.Request) (string, int, error) {
 	ns, err := namespace.FromContext(r.Context())
 	if err != nil {
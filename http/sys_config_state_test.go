@@ -164,6 +164,8 @@ func TestSysConfigState_Sanitized(t *testing.T) {
 				"plugin_directory":                    "",
 				"plugin_file_uid":                     json.Number("0"),
 				"plugin_file_permissions":             json.Number("0"),
+				"plugin_registry_url":                 "",
+				"plugin_signing_keys":                 nil,
 				"enable_response_header_hostname":     false,
 				"enable_response_header_raft_node_id": false,
 				"log_requests_level":                  "",
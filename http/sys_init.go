@@ -70,9 +70,10 @@ func handleSysInitPut(core *vault.Core, w http.ResponseWriter, r *http.Request)
 	}
 
 	initParams := &vault.InitParams{
-		BarrierConfig:   barrierConfig,
-		RecoveryConfig:  recoveryConfig,
-		RootTokenPGPKey: req.RootTokenPGPKey,
+		BarrierConfig:    barrierConfig,
+		RecoveryConfig:   recoveryConfig,
+		RootTokenPGPKey:  req.RootTokenPGPKey,
+		BarrierAlgorithm: req.BarrierAlgorithm,
 	}
 
 	result, initErr := core.Initialize(ctx, initParams)
@@ -126,6 +127,7 @@ type InitRequest struct {
 	RecoveryThreshold int      `json:"recovery_threshold"`
 	RecoveryPGPKeys   []string `json:"recovery_pgp_keys"`
 	RootTokenPGPKey   string   `json:"root_token_pgp_key"`
+	BarrierAlgorithm  string   `json:"barrier_algorithm"`
 }
 
 type InitResponse struct {
@@ -164,6 +166,12 @@ func validateInitParameters(core *vault.Core, req InitRequest) error {
 		recoveryFlags = append(recoveryFlags, "recovery_pgp_keys")
 	}
 
+	if req.BarrierAlgorithm != "" {
+		if err := vault.ValidateBarrierAlgorithm(req.BarrierAlgorithm); err != nil {
+			return err
+		}
+	}
+
 	switch core.SealAccess().RecoveryKeySupported() {
 	case true:
 		if len(barrierFlags) > 0 {
@@ -31,9 +31,11 @@ func TestSysRotate(t *testing.T) {
 		"warnings":       nil,
 		"auth":           nil,
 		"data": map[string]interface{}{
-			"term": json.Number("2"),
+			"term":      json.Number("2"),
+			"algorithm": "aes256-gcm96",
 		},
-		"term": json.Number("2"),
+		"term":      json.Number("2"),
+		"algorithm": "aes256-gcm96",
 	}
 
 	testResponseStatus(t, resp, 200)
@@ -6,6 +6,11 @@
 package configutil
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl"
 	"github.com/hashicorp/hcl/hcl/ast"
 )
 
@@ -16,5 +21,43 @@ func (ec *EntSharedConfig) ParseConfig(list *ast.ObjectList) error {
 }
 
 func ParseEntropy(result *SharedConfig, list *ast.ObjectList, blockName string) error {
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one %q block is permitted", blockName)
+	}
+
+	item := list.Items[0]
+	if len(item.Keys) != 1 {
+		return fmt.Errorf("%q block must have a type, e.g. %s %q", blockName, blockName, EntropyTypeSeal)
+	}
+	key := strings.ToLower(item.Keys[0].Token.Value().(string))
+
+	var m map[string]string
+	if err := hcl.DecodeObject(&m, item.Val); err != nil {
+		return multierror.Prefix(err, fmt.Sprintf("%s.%s:", blockName, key))
+	}
+
+	e := &Entropy{
+		Type: key,
+	}
+	switch mode := m["mode"]; mode {
+	case "augmentation":
+		e.Mode = EntropyAugmentation
+	default:
+		return multierror.Prefix(fmt.Errorf("unknown mode %q, must be \"augmentation\"", mode), fmt.Sprintf("%s.%s:", blockName, key))
+	}
+
+	switch key {
+	case EntropyTypeSeal:
+		e.SealName = m["seal_name"]
+	case EntropyTypeFile:
+		e.Path = m["path"]
+		if e.Path == "" {
+			return multierror.Prefix(fmt.Errorf("'path' is required"), fmt.Sprintf("%s.%s:", blockName, key))
+		}
+	default:
+		return fmt.Errorf("unknown %s type %q, must be %q or %q", blockName, key, EntropyTypeSeal, EntropyTypeFile)
+	}
+
+	result.Entropy = e
 	return nil
 }
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package configutil
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-kms-wrapping/entropy/v2"
+)
+
+// augmentedReader mixes the output of an external entropy source into the
+// platform's randomness. The two are XORed together, so the result is at
+// least as strong as either of them. Reads fail if the external source does,
+// rather than silently falling back to the platform alone.
+//
+// It also implements entropy.Sourcer, which is how core recognizes that
+// entropy augmentation is enabled and hands it to mounts with external
+// entropy access.
+type augmentedReader struct {
+	sourcer entropy.Sourcer
+	logger  hclog.Logger
+
+	// lock serializes reads, as external sources are generally not safe for
+	// concurrent use.
+	lock sync.Mutex
+}
+
+var (
+	_ io.Reader       = (*augmentedReader)(nil)
+	_ entropy.Sourcer = (*augmentedReader)(nil)
+)
+
+func newAugmentedReader(sourcer entropy.Sourcer, logger hclog.Logger) (*augmentedReader, error) {
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+	r := &augmentedReader{
+		sourcer: sourcer,
+		logger:  logger,
+	}
+
+	// Make sure the source works before it is relied upon.
+	if _, err := r.GetRandom(32); err != nil {
+		return nil, fmt.Errorf("failed to read from entropy source: %w", err)
+	}
+	return r, nil
+}
+
+func (r *augmentedReader) Read(p []byte) (int, error) {
+	if _, err := io.ReadFull(rand.Reader, p); err != nil {
+		return 0, err
+	}
+
+	r.lock.Lock()
+	external, err := r.sourcer.GetRandom(len(p))
+	r.lock.Unlock()
+	if err == nil && len(external) != len(p) {
+		err = fmt.Errorf("requested %d bytes of entropy but got %d", len(p), len(external))
+	}
+	if err != nil {
+		r.logger.Error("failed to read from entropy source", "error", err)
+		return 0, fmt.Errorf("failed to read from entropy source: %w", err)
+	}
+
+	for i := range p {
+		p[i] ^= external[i]
+	}
+	return len(p), nil
+}
+
+func (r *augmentedReader) GetRandom(bytes int) ([]byte, error) {
+	buf := make([]byte, bytes)
+	if _, err := r.Read(buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// fileEntropySourcer reads entropy from a file. The file is opened for every
+// request, so that a named pipe can be fed by a daemon that is restarted.
+type fileEntropySourcer struct {
+	path string
+}
+
+func (f *fileEntropySourcer) GetRandom(bytes int) ([]byte, error) {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, bytes)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		return nil, fmt.Errorf("failed to read %d bytes from %q: %w", bytes, f.path, err)
+	}
+	return buf, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package configutil
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-kms-wrapping/entropy/v2"
+	"github.com/stretchr/testify/require"
+)
+
+type testSourcer struct {
+	b   byte
+	err error
+}
+
+func (s *testSourcer) GetRandom(n int) ([]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return bytes.Repeat([]byte{s.b}, n), nil
+}
+
+func TestParseEntropy(t *testing.T) {
+	conf, err := ParseConfig(`
+entropy "seal" {
+  mode      = "augmentation"
+  seal_name = "hsm"
+}`)
+	require.NoError(t, err)
+	require.Equal(t, &Entropy{Type: EntropyTypeSeal, Mode: EntropyAugmentation, SealName: "hsm"}, conf.Entropy)
+
+	conf, err = ParseConfig(`
+entropy "file" {
+  mode = "augmentation"
+  path = "/dev/hwrng"
+}`)
+	require.NoError(t, err)
+	require.Equal(t, &Entropy{Type: EntropyTypeFile, Mode: EntropyAugmentation, Path: "/dev/hwrng"}, conf.Entropy)
+
+	for name, config := range map[string]string{
+		"unknown mode": `entropy "seal" { mode = "replacement" }`,
+		"unknown type": `entropy "daemon" { mode = "augmentation" }`,
+		"no path":      `entropy "file" { mode = "augmentation" }`,
+		"two blocks":   `entropy "seal" { mode = "augmentation" } entropy "file" { mode = "augmentation" path = "/dev/hwrng" }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseConfig(config)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCreateSecureRandomReader(t *testing.T) {
+	reader, err := createSecureRandomReader(&SharedConfig{}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, rand.Reader, reader)
+
+	conf := &SharedConfig{Entropy: &Entropy{Type: EntropyTypeSeal, Mode: EntropyAugmentation, SealName: "hsm"}}
+	_, err = createSecureRandomReader(conf, nil, nil)
+	require.Error(t, err)
+
+	sources := []*EntropySourcerInfo{
+		{Name: "other", Sourcer: &testSourcer{b: 1}},
+		{Name: "hsm", Sourcer: &testSourcer{b: 2}},
+	}
+	reader, err = createSecureRandomReader(conf, sources, nil)
+	require.NoError(t, err)
+	require.Equal(t, sources[1].Sourcer, reader.(*augmentedReader).sourcer)
+	require.Implements(t, (*entropy.Sourcer)(nil), reader)
+
+	path := filepath.Join(t.TempDir(), "entropy")
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte{0xff}, 64), 0o600))
+	conf = &SharedConfig{Entropy: &Entropy{Type: EntropyTypeFile, Mode: EntropyAugmentation, Path: path}}
+	reader, err = createSecureRandomReader(conf, nil, nil)
+	require.NoError(t, err)
+	buf := make([]byte, 64)
+	_, err = reader.Read(buf)
+	require.NoError(t, err)
+
+	// The file is too short to satisfy the read.
+	_, err = reader.Read(make([]byte, 128))
+	require.Error(t, err)
+
+	conf.Entropy.Path = filepath.Join(t.TempDir(), "missing")
+	_, err = createSecureRandomReader(conf, nil, nil)
+	require.Error(t, err)
+}
+
+func TestAugmentedReader(t *testing.T) {
+	sourcer := &testSourcer{}
+	r, err := newAugmentedReader(sourcer, nil)
+	require.NoError(t, err)
+
+	// A source of zeroes leaves the platform randomness intact, which is
+	// still random.
+	a, err := r.GetRandom(32)
+	require.NoError(t, err)
+	b, err := r.GetRandom(32)
+	require.NoError(t, err)
+	require.NotEqual(t, a, b)
+
+	// Failures of the source are not hidden.
+	sourcer.err = errors.New("device unavailable")
+	_, err = r.Read(make([]byte, 32))
+	require.ErrorContains(t, err, "device unavailable")
+}
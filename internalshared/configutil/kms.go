@@ -46,9 +46,21 @@ const (
 	EntropyAugmentation
 )
 
+const (
+	// EntropyTypeSeal sources entropy from a seal whose wrapper provides it,
+	// such as an HSM accessed via PKCS#11.
+	EntropyTypeSeal = "seal"
+
+	// EntropyTypeFile sources entropy by reading a file, such as a hardware
+	// RNG device node or a named pipe fed by an external entropy daemon.
+	EntropyTypeFile = "file"
+)
+
 type Entropy struct {
+	Type     string
 	Mode     EntropyMode
 	SealName string
+	Path     string
 }
 
 type EntropySourcerInfo struct {
@@ -404,8 +416,33 @@ var GetTransitKMSFunc = func(kms *KMS, opts ...wrapping.Option) (wrapping.Wrappe
 	return wrapper, info, nil
 }
 
-func createSecureRandomReader(_ *SharedConfig, _ []*EntropySourcerInfo, _ hclog.Logger) (io.Reader, error) {
-	return rand.Reader, nil
+func createSecureRandomReader(conf *SharedConfig, sources []*EntropySourcerInfo, logger hclog.Logger) (io.Reader, error) {
+	if conf == nil || conf.Entropy == nil || conf.Entropy.Mode != EntropyAugmentation {
+		return rand.Reader, nil
+	}
+
+	var sourcer entropy.Sourcer
+	switch conf.Entropy.Type {
+	case EntropyTypeSeal, "":
+		for _, s := range sources {
+			if conf.Entropy.SealName == "" || s.Name == conf.Entropy.SealName {
+				sourcer = s.Sourcer
+				break
+			}
+		}
+		if sourcer == nil {
+			if conf.Entropy.SealName != "" {
+				return nil, fmt.Errorf("entropy augmentation requires seal %q, which does not provide entropy", conf.Entropy.SealName)
+			}
+			return nil, errors.New("entropy augmentation requires a seal that provides entropy")
+		}
+	case EntropyTypeFile:
+		sourcer = &fileEntropySourcer{path: conf.Entropy.Path}
+	default:
+		return nil, fmt.Errorf("unknown entropy type %q", conf.Entropy.Type)
+	}
+
+	return newAugmentedReader(sourcer, logger)
 }
 
 func getEnvConfig(kms *KMS) map[string]string {
@@ -40,6 +40,17 @@ type ListenerInFlightRequestLogging struct {
 	UnauthenticatedInFlightAccessRaw interface{}  `hcl:"unauthenticated_in_flight_requests_access,alias:unauthenticatedInFlightAccessRaw"`
 }
 
+type ListenerResponseCompression struct {
+	UnusedKeys    UnusedKeyMap `hcl:",unusedKeyPositions"`
+	Enabled       bool         `hcl:"-"`
+	EnabledRaw    interface{}  `hcl:"enabled,alias:Enabled"`
+	Algorithms    []string     `hcl:"-"`
+	AlgorithmsRaw interface{}  `hcl:"algorithms,alias:Algorithms"`
+	MinSize       int64        `hcl:"-"`
+	MinSizeRaw    interface{}  `hcl:"min_size,alias:MinSize"`
+	ExcludedPaths []string     `hcl:"excluded_paths"`
+}
+
 // Listener is the listener configuration for the server.
 type Listener struct {
 	UnusedKeys UnusedKeyMap `hcl:",unusedKeyPositions"`
@@ -72,6 +83,10 @@ type Listener struct {
 	TLSClientCAFile                  string      `hcl:"tls_client_ca_file"`
 	TLSDisableClientCerts            bool        `hcl:"-"`
 	TLSDisableClientCertsRaw         interface{} `hcl:"tls_disable_client_certs"`
+	TLSAllowedClientDNSSANs          []string    `hcl:"-"`
+	TLSAllowedClientDNSSANsRaw       interface{} `hcl:"tls_allowed_client_dns_sans"`
+	TLSAllowedClientURISANs          []string    `hcl:"-"`
+	TLSAllowedClientURISANsRaw       interface{} `hcl:"tls_allowed_client_uri_sans"`
 
 	HTTPReadTimeout          time.Duration `hcl:"-"`
 	HTTPReadTimeoutRaw       interface{}   `hcl:"http_read_timeout"`
@@ -106,10 +121,14 @@ type Listener struct {
 	Telemetry              ListenerTelemetry              `hcl:"telemetry"`
 	Profiling              ListenerProfiling              `hcl:"profiling"`
 	InFlightRequestLogging ListenerInFlightRequestLogging `hcl:"inflight_requests_logging"`
+	ResponseCompression    ListenerResponseCompression    `hcl:"response_compression"`
 
 	// RandomPort is used only for some testing purposes
 	RandomPort bool `hcl:"-"`
 
+	GRPCEnabledRaw interface{} `hcl:"grpc_enabled"`
+	GRPCEnabled    bool        `hcl:"-"`
+
 	CorsEnabledRaw        interface{} `hcl:"cors_enabled"`
 	CorsEnabled           bool        `hcl:"-"`
 	CorsAllowedOrigins    []string    `hcl:"cors_allowed_origins"`
@@ -127,7 +146,8 @@ type Listener struct {
 
 // AgentAPI allows users to select which parts of the Agent API they want enabled.
 type AgentAPI struct {
-	EnableQuit bool `hcl:"enable_quit"`
+	EnableQuit   bool `hcl:"enable_quit"`
+	EnableReload bool `hcl:"enable_reload"`
 }
 
 // ProxyAPI allows users to select which parts of the Vault Proxy API they want enabled.
@@ -141,7 +161,8 @@ func (l *Listener) GoString() string {
 
 func (l *Listener) Validate(path string) []ConfigError {
 	results := append(ValidateUnusedFields(l.UnusedKeys, path), ValidateUnusedFields(l.Telemetry.UnusedKeys, path)...)
-	return append(results, ValidateUnusedFields(l.Profiling.UnusedKeys, path)...)
+	results = append(results, ValidateUnusedFields(l.Profiling.UnusedKeys, path)...)
+	return append(results, ValidateUnusedFields(l.ResponseCompression.UnusedKeys, path)...)
 }
 
 func ParseListeners(result *SharedConfig, list *ast.ObjectList) error {
@@ -267,6 +288,26 @@ func ParseListeners(result *SharedConfig, list *ast.ObjectList) error {
 
 				l.TLSDisableClientCertsRaw = nil
 			}
+
+			if l.TLSAllowedClientDNSSANsRaw != nil {
+				if l.TLSAllowedClientDNSSANs, err = parseutil.ParseCommaStringSlice(l.TLSAllowedClientDNSSANsRaw); err != nil {
+					return multierror.Prefix(fmt.Errorf("invalid value for tls_allowed_client_dns_sans: %w", err), fmt.Sprintf("listeners.%d", i))
+				}
+
+				l.TLSAllowedClientDNSSANsRaw = nil
+			}
+
+			if l.TLSAllowedClientURISANsRaw != nil {
+				if l.TLSAllowedClientURISANs, err = parseutil.ParseCommaStringSlice(l.TLSAllowedClientURISANsRaw); err != nil {
+					return multierror.Prefix(fmt.Errorf("invalid value for tls_allowed_client_uri_sans: %w", err), fmt.Sprintf("listeners.%d", i))
+				}
+
+				l.TLSAllowedClientURISANsRaw = nil
+			}
+
+			if (len(l.TLSAllowedClientDNSSANs) > 0 || len(l.TLSAllowedClientURISANs) > 0) && !l.TLSRequireAndVerifyClientCert {
+				return multierror.Prefix(errors.New("tls_allowed_client_dns_sans and tls_allowed_client_uri_sans require tls_require_and_verify_client_cert"), fmt.Sprintf("listeners.%d", i))
+			}
 		}
 
 		// HTTP timeouts
@@ -394,6 +435,56 @@ func ParseListeners(result *SharedConfig, list *ast.ObjectList) error {
 			}
 		}
 
+		// Response compression
+		{
+			if l.ResponseCompression.EnabledRaw != nil {
+				if l.ResponseCompression.Enabled, err = parseutil.ParseBool(l.ResponseCompression.EnabledRaw); err != nil {
+					return multierror.Prefix(fmt.Errorf("invalid value for response_compression.enabled: %w", err), fmt.Sprintf("listeners.%d", i))
+				}
+
+				l.ResponseCompression.EnabledRaw = nil
+			}
+
+			if l.ResponseCompression.AlgorithmsRaw != nil {
+				if l.ResponseCompression.Algorithms, err = parseutil.ParseCommaStringSlice(l.ResponseCompression.AlgorithmsRaw); err != nil {
+					return multierror.Prefix(fmt.Errorf("invalid value for response_compression.algorithms: %w", err), fmt.Sprintf("listeners.%d", i))
+				}
+				for j, algorithm := range l.ResponseCompression.Algorithms {
+					algorithm = strings.ToLower(algorithm)
+					switch algorithm {
+					case "gzip", "zstd":
+					default:
+						return multierror.Prefix(fmt.Errorf("unsupported response_compression algorithm %q, must be one of gzip or zstd", algorithm), fmt.Sprintf("listeners.%d", i))
+					}
+					l.ResponseCompression.Algorithms[j] = algorithm
+				}
+
+				l.ResponseCompression.AlgorithmsRaw = nil
+			}
+
+			if l.ResponseCompression.MinSizeRaw != nil {
+				if l.ResponseCompression.MinSize, err = parseutil.ParseInt(l.ResponseCompression.MinSizeRaw); err != nil {
+					return multierror.Prefix(fmt.Errorf("error parsing response_compression.min_size: %w", err), fmt.Sprintf("listeners.%d", i))
+				}
+				if l.ResponseCompression.MinSize < 0 {
+					return multierror.Prefix(errors.New("response_compression.min_size cannot be negative"), fmt.Sprintf("listeners.%d", i))
+				}
+
+				l.ResponseCompression.MinSizeRaw = nil
+			}
+		}
+
+		// gRPC API
+		{
+			if l.GRPCEnabledRaw != nil {
+				if l.GRPCEnabled, err = parseutil.ParseBool(l.GRPCEnabledRaw); err != nil {
+					return multierror.Prefix(fmt.Errorf("invalid value for grpc_enabled: %w", err), fmt.Sprintf("listeners.%d", i))
+				}
+
+				l.GRPCEnabledRaw = nil
+			}
+		}
+
 		// CORS
 		{
 			if l.CorsEnabledRaw != nil {
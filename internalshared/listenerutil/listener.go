@@ -6,6 +6,7 @@ package listenerutil
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -15,6 +16,7 @@ import (
 
 	"github.com/hashicorp/errwrap"
 	"github.com/hashicorp/go-secure-stdlib/reloadutil"
+	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/hashicorp/go-secure-stdlib/tlsutil"
 	"github.com/hashicorp/vault/internalshared/configutil"
 	"github.com/jefferai/isbadcipher"
@@ -50,6 +52,21 @@ func (l *rmListener) Close() error {
 	return os.Remove(l.Path)
 }
 
+// UnixSocketsConfigFromListener returns the socket permissions configured on
+// a unix listener, or nil if none of socket_mode, socket_user or socket_group
+// are set. Unset values keep the defaults of the process creating the socket.
+func UnixSocketsConfigFromListener(l *configutil.Listener) *UnixSocketsConfig {
+	if l.SocketMode == "" && l.SocketUser == "" && l.SocketGroup == "" {
+		return nil
+	}
+
+	return &UnixSocketsConfig{
+		Mode:  l.SocketMode,
+		User:  l.SocketUser,
+		Group: l.SocketGroup,
+	}
+}
+
 func UnixSocketListener(path string, unixSocketsConfig *UnixSocketsConfig) (net.Listener, error) {
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to remove socket file: %v", err)
@@ -178,6 +195,15 @@ Please see https://tools.ietf.org/html/rfc7540#appendix-A for further informatio
 			}
 			tlsConf.ClientCAs = caPool
 		}
+
+		if len(l.TLSAllowedClientDNSSANs) > 0 || len(l.TLSAllowedClientURISANs) > 0 {
+			tlsConf.VerifyConnection = func(cs tls.ConnectionState) error {
+				if len(cs.PeerCertificates) == 0 {
+					return errors.New("no client certificate presented")
+				}
+				return verifyClientSANs(cs.PeerCertificates[0], l.TLSAllowedClientDNSSANs, l.TLSAllowedClientURISANs)
+			}
+		}
 	}
 
 	if l.TLSDisableClientCerts {
@@ -191,6 +217,23 @@ Please see https://tools.ietf.org/html/rfc7540#appendix-A for further informatio
 	return tlsConf, cg.Reload, nil
 }
 
+// verifyClientSANs checks that the client certificate carries at least one
+// DNS or URI SAN matching the allowed glob patterns, e.g.
+// "spiffe://cluster.local/ns/app/*".
+func verifyClientSANs(cert *x509.Certificate, allowedDNSSANs, allowedURISANs []string) error {
+	for _, dnsName := range cert.DNSNames {
+		if strutil.StrListContainsGlob(allowedDNSSANs, dnsName) {
+			return nil
+		}
+	}
+	for _, uri := range cert.URIs {
+		if strutil.StrListContainsGlob(allowedURISANs, uri.String()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("client certificate %q does not have an allowed DNS or URI SAN", cert.Subject.CommonName)
+}
+
 // setFilePermissions handles configuring ownership and permissions
 // settings on a given file. All permission/ownership settings are
 // optional. If no user or group is specified, the current user/group
@@ -223,7 +266,7 @@ GROUP:
 		// Try looking up the user by name
 		g, err := osuser.LookupGroup(group)
 		if err != nil {
-			return fmt.Errorf("failed to look up group %q: %v", user, err)
+			return fmt.Errorf("failed to look up group %q: %v", group, err)
 		}
 		gid, _ = strconv.Atoi(g.Gid)
 	}
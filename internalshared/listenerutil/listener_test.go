@@ -4,7 +4,9 @@
 package listenerutil
 
 import (
+	"crypto/x509"
 	"io/ioutil"
+	"net/url"
 	"os"
 	osuser "os/user"
 	"strconv"
@@ -88,3 +90,39 @@ func TestUnixSocketListener(t *testing.T) {
 		}
 	})
 }
+
+func TestVerifyClientSANs(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://cluster.local/ns/app/sa/web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &x509.Certificate{
+		DNSNames: []string{"web.app.svc"},
+		URIs:     []*url.URL{spiffeID},
+	}
+
+	cases := []struct {
+		name           string
+		allowedDNSSANs []string
+		allowedURISANs []string
+		expectErr      bool
+	}{
+		{"matching dns san", []string{"*.app.svc"}, nil, false},
+		{"matching uri san", nil, []string{"spiffe://cluster.local/ns/app/*"}, false},
+		{"non-matching dns san", []string{"*.other.svc"}, nil, true},
+		{"non-matching uri san", nil, []string{"spiffe://cluster.local/ns/other/*"}, true},
+		{"uri pattern does not match dns san", nil, []string{"web.app.svc"}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyClientSANs(cert, tc.allowedDNSSANs, tc.allowedURISANs)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected error")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -60,6 +60,8 @@ var (
 
 type restoreCallback func(context.Context) error
 
+type invalidateCallback func(keys []string)
+
 type FSMEntry struct {
 	Key   string
 	Value []byte
@@ -103,6 +105,10 @@ type FSM struct {
 	// retoreCb is called after we've restored a snapshot
 	restoreCb restoreCallback
 
+	// invalidateCb is called with the keys written by each batch of logs,
+	// once they are committed
+	invalidateCb invalidateCallback
+
 	chunker *raftchunking.ChunkingBatchingFSM
 
 	localID         string
@@ -653,7 +659,9 @@ func (f *FSM) ApplyBatch(logs []*raft.Log) []interface{} {
 		f.applyCallback()
 	}
 
+	var written []string
 	err = f.db.Update(func(tx *bolt.Tx) error {
+		written = written[:0]
 		b := tx.Bucket(dataBucketName)
 		for _, commandRaw := range commands {
 			entrySlice := make([]*FSMEntry, 0)
@@ -664,8 +672,10 @@ func (f *FSM) ApplyBatch(logs []*raft.Log) []interface{} {
 					switch op.OpType {
 					case putOp:
 						err = b.Put([]byte(op.Key), op.Value)
+						written = append(written, op.Key)
 					case deleteOp:
 						err = b.Delete([]byte(op.Key))
+						written = append(written, op.Key)
 					case getOp:
 						fsmEntry := &FSMEntry{
 							Key: op.Key,
@@ -722,6 +732,10 @@ func (f *FSM) ApplyBatch(logs []*raft.Log) []interface{} {
 		panic("failed to store data")
 	}
 
+	if f.invalidateCb != nil && len(written) > 0 {
+		f.invalidateCb(written)
+	}
+
 	// If we advanced the latest value, update the in-memory representation too.
 	if len(logIndex) > 0 {
 		atomic.StoreUint64(f.latestTerm, lastLog.Term)
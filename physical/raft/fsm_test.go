@@ -132,6 +132,38 @@ func TestFSM_Batching(t *testing.T) {
 	}
 }
 
+func TestFSM_InvalidateCallback(t *testing.T) {
+	fsm, dir := getFSM(t)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var invalidated []string
+	fsm.invalidateCb = func(keys []string) {
+		invalidated = append(invalidated, keys...)
+	}
+
+	command := &LogData{
+		Operations: []*LogOperation{
+			{OpType: putOp, Key: "foo", Value: []byte("bar")},
+			{OpType: getOp, Key: "foo"},
+			{OpType: deleteOp, Key: "baz"},
+		},
+	}
+	commandBytes, err := proto.Marshal(command)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsm.ApplyBatch([]*raft.Log{{
+		Index: 1,
+		Term:  1,
+		Type:  raft.LogCommand,
+		Data:  commandBytes,
+	}})
+
+	if diff := deep.Equal(invalidated, []string{"foo", "baz"}); diff != nil {
+		t.Fatal(diff)
+	}
+}
+
 func TestFSM_List(t *testing.T) {
 	fsm, dir := getFSM(t)
 	defer func() { _ = os.RemoveAll(dir) }()
@@ -779,6 +779,14 @@ func (b *RaftBackend) SetRestoreCallback(restoreCb restoreCallback) {
 	b.fsm.l.Unlock()
 }
 
+// SetInvalidateCallback sets the callback to be called with the keys written
+// by the logs applied through the FSM.
+func (b *RaftBackend) SetInvalidateCallback(invalidateCb invalidateCallback) {
+	b.fsm.l.Lock()
+	b.fsm.invalidateCb = invalidateCb
+	b.fsm.l.Unlock()
+}
+
 func (b *RaftBackend) applyConfigSettings(config *raft.Config) error {
 	config.Logger = b.logger
 	multiplierRaw, ok := b.conf["performance_multiplier"]
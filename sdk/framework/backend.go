@@ -82,6 +82,11 @@ type Backend struct {
 	// to prevent it from attempting to write on a Vault instance with read-only storage.
 	PeriodicFunc periodicFunc
 
+	// PeriodicJobs are named jobs which, like PeriodicFunc, are run by the
+	// periodic timer of RollbackManager, optionally on a cron schedule with
+	// a per-mount jitter. Their status is reported at PeriodicJobsPath.
+	PeriodicJobs []*PeriodicJob
+
 	// WALRollback is called when a WAL entry (see wal.go) has to be rolled
 	// back. It is called with the data from the entry.
 	//
@@ -109,11 +114,13 @@ type Backend struct {
 	// RunningVersion is the optional version that will be self-reported
 	RunningVersion string
 
-	logger  log.Logger
-	system  logical.SystemView
-	events  logical.EventSender
-	once    sync.Once
-	pathsRe []*regexp.Regexp
+	logger       log.Logger
+	system       logical.SystemView
+	events       logical.EventSender
+	backendUUID  string
+	once         sync.Once
+	pathsRe      []*regexp.Regexp
+	periodicJobs *periodicJobs
 }
 
 // periodicFunc is the callback called when the RollbackManager's timer ticks.
@@ -418,6 +425,7 @@ func (b *Backend) Setup(ctx context.Context, config *logical.BackendConfig) erro
 	b.logger = config.Logger
 	b.system = config.System
 	b.events = config.EventsSender
+	b.backendUUID = config.BackendUUID
 	return nil
 }
 
@@ -491,6 +499,8 @@ func (b *Backend) WriteSafeReplicationState() bool {
 // For builtin plugins, this is unit tested in helper/builtinplugins/builtinplugins_test.go.
 // For other plugins, any unit test that attempts to perform any request to the plugin will exercise these checks.
 func (b *Backend) init() {
+	b.initPeriodicJobs()
+
 	b.pathsRe = make([]*regexp.Regexp, len(b.Paths))
 	for i, p := range b.Paths {
 		// Detect the coding error of failing to initialise Pattern
@@ -652,6 +662,10 @@ func (b *Backend) handleRollback(ctx context.Context, req *logical.Request) (*lo
 		}
 	}
 
+	if err := b.runPeriodicJobs(ctx, req); err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
 	if b.WALRollback != nil {
 		var err error
 		resp, err = b.handleWALRollback(ctx, req)
@@ -777,11 +791,31 @@ type FieldSchema struct {
 	Query bool
 
 	// AllowedValues is an optional list of permitted values for this field.
-	// This constraint is not (yet) enforced by the framework, but the list is
-	// output as part of OpenAPI generation and may affect documentation and
-	// dynamic UI generation.
+	// This constraint is only enforced by the framework if
+	// EnforceAllowedValues is set, but the list is output as part of OpenAPI
+	// generation and may affect documentation and dynamic UI generation.
 	AllowedValues []interface{}
 
+	// EnforceAllowedValues rejects requests setting this field to a value, or
+	// for slice types an element, that is not in AllowedValues.
+	EnforceAllowedValues bool
+
+	// Pattern is an optional regular expression that string values, and each
+	// element of string slices, must match. It is not implicitly anchored.
+	Pattern string
+
+	// Min and Max optionally bound the value of numeric fields (durations in
+	// seconds), the length of strings, and the number of elements of slices
+	// and maps.
+	Min *int64
+	Max *int64
+
+	// ConflictsWith lists fields which may not be set together with this one.
+	ConflictsWith []string
+
+	// RequiredWith lists fields which must be set whenever this one is.
+	RequiredWith []string
+
 	// DisplayAttrs provides hints for UI and documentation generators. They
 	// will be included in OpenAPI output if set.
 	DisplayAttrs *DisplayAttributes
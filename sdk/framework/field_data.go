@@ -30,8 +30,9 @@ type FieldData struct {
 
 // Validate cycles through raw data and validates conversions in
 // the schema, so we don't get an error/panic later when
-// trying to get data out.  Data not in the schema is not
-// an error at this point, so we don't worry about it.
+// trying to get data out, and then checks the values against
+// the constraints declared in the schema.  Data not in the schema
+// is not an error at this point, so we don't worry about it.
 func (d *FieldData) Validate() error {
 	for field, value := range d.Raw {
 
@@ -53,7 +54,7 @@ func (d *FieldData) Validate() error {
 		}
 	}
 
-	return nil
+	return d.validateConstraints()
 }
 
 // ValidateStrict cycles through raw data and validates conversions in the
@@ -0,0 +1,167 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package framework
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// validateConstraints checks the fields set in the raw data against the
+// constraints declared in their schema. Fields are checked in name order so
+// that the error returned for a request is deterministic.
+func (d *FieldData) validateConstraints() error {
+	fields := make([]string, 0, len(d.Raw))
+	for field := range d.Raw {
+		if _, ok := d.Schema[field]; ok && d.isSet(field) {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		schema := d.Schema[field]
+
+		for _, other := range schema.ConflictsWith {
+			if d.isSet(other) {
+				return fmt.Errorf("field %q cannot be set together with %q", field, other)
+			}
+		}
+		for _, other := range schema.RequiredWith {
+			if !d.isSet(other) {
+				return fmt.Errorf("field %q requires %q to also be set", field, other)
+			}
+		}
+
+		if schema.Pattern == "" && schema.Min == nil && schema.Max == nil && !schema.EnforceAllowedValues {
+			continue
+		}
+
+		value, _, err := d.getPrimitive(field, schema)
+		if err != nil {
+			return fmt.Errorf("error converting input for field %q: %w", field, err)
+		}
+
+		if err := schema.validateValue(value); err != nil {
+			return fmt.Errorf("field %q %w", field, err)
+		}
+	}
+
+	return nil
+}
+
+// isSet returns whether the field is part of the schema and was given a
+// non-nil value.
+func (d *FieldData) isSet(field string) bool {
+	if _, ok := d.Schema[field]; !ok {
+		return false
+	}
+	raw, ok := d.Raw[field]
+	return ok && raw != nil
+}
+
+// validateValue checks a parsed value against the schema's Pattern, Min, Max
+// and, if enforced, AllowedValues constraints. The returned error completes a
+// sentence starting with the field name.
+func (s *FieldSchema) validateValue(value interface{}) error {
+	var (
+		size     int64
+		hasSize  bool
+		elements []interface{}
+	)
+
+	switch v := value.(type) {
+	case string:
+		size, hasSize = int64(utf8.RuneCountInString(v)), true
+		elements = []interface{}{v}
+	case int:
+		size, hasSize = int64(v), true
+		elements = []interface{}{v}
+	case int64:
+		size, hasSize = v, true
+		elements = []interface{}{v}
+	case float64:
+		if s.Min != nil && v < float64(*s.Min) {
+			return fmt.Errorf("must be at least %d", *s.Min)
+		}
+		if s.Max != nil && v > float64(*s.Max) {
+			return fmt.Errorf("must be at most %d", *s.Max)
+		}
+		elements = []interface{}{v}
+	case []string:
+		size, hasSize = int64(len(v)), true
+		for _, e := range v {
+			elements = append(elements, e)
+		}
+	case []int:
+		size, hasSize = int64(len(v)), true
+		for _, e := range v {
+			elements = append(elements, e)
+		}
+	case []interface{}:
+		size, hasSize = int64(len(v)), true
+		elements = v
+	case map[string]interface{}:
+		size, hasSize = int64(len(v)), true
+	case map[string]string:
+		size, hasSize = int64(len(v)), true
+	case http.Header:
+		size, hasSize = int64(len(v)), true
+	case bool, time.Time:
+		elements = []interface{}{v}
+	}
+
+	if hasSize {
+		unit := ""
+		switch value.(type) {
+		case string:
+			unit = " characters"
+		case []string, []int, []interface{}:
+			unit = " elements"
+		case map[string]interface{}, map[string]string, http.Header:
+			unit = " entries"
+		}
+		if s.Min != nil && size < *s.Min {
+			return fmt.Errorf("must be at least %d%s", *s.Min, unit)
+		}
+		if s.Max != nil && size > *s.Max {
+			return fmt.Errorf("must be at most %d%s", *s.Max, unit)
+		}
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("has an invalid pattern %q: %w", s.Pattern, err)
+		}
+		for _, e := range elements {
+			if str, ok := e.(string); ok && !re.MatchString(str) {
+				return fmt.Errorf("value %q does not match pattern %q", str, s.Pattern)
+			}
+		}
+	}
+
+	if s.EnforceAllowedValues {
+		for _, e := range elements {
+			if !s.isAllowedValue(e) {
+				return fmt.Errorf("value %q is not one of %v", fmt.Sprint(e), s.AllowedValues)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *FieldSchema) isAllowedValue(value interface{}) bool {
+	for _, allowed := range s.AllowedValues {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
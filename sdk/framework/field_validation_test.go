@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package framework
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/vault/sdk/helper/pointerutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestFieldDataValidate_Constraints(t *testing.T) {
+	cases := map[string]struct {
+		Schema map[string]*FieldSchema
+		Raw    map[string]interface{}
+		Error  string
+	}{
+		"pattern match": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, Pattern: "^[a-z]+$"},
+			},
+			map[string]interface{}{"foo": "bar"},
+			"",
+		},
+		"pattern mismatch": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, Pattern: "^[a-z]+$"},
+			},
+			map[string]interface{}{"foo": "Bar"},
+			`field "foo" value "Bar" does not match pattern "^[a-z]+$"`,
+		},
+		"pattern mismatch in slice": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeCommaStringSlice, Pattern: "^[a-z]+$"},
+			},
+			map[string]interface{}{"foo": "bar,b4z"},
+			`field "foo" value "b4z" does not match pattern "^[a-z]+$"`,
+		},
+		"int within bounds": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeInt, Min: pointerutil.Int64Ptr(1), Max: pointerutil.Int64Ptr(10)},
+			},
+			map[string]interface{}{"foo": 10},
+			"",
+		},
+		"int below min": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeInt, Min: pointerutil.Int64Ptr(1)},
+			},
+			map[string]interface{}{"foo": "0"},
+			`field "foo" must be at least 1`,
+		},
+		"float above max": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeFloat, Max: pointerutil.Int64Ptr(1)},
+			},
+			map[string]interface{}{"foo": 1.5},
+			`field "foo" must be at most 1`,
+		},
+		"duration above max": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeDurationSecond, Max: pointerutil.Int64Ptr(3600)},
+			},
+			map[string]interface{}{"foo": "2h"},
+			`field "foo" must be at most 3600`,
+		},
+		"string too long": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, Max: pointerutil.Int64Ptr(3)},
+			},
+			map[string]interface{}{"foo": "abcd"},
+			`field "foo" must be at most 3 characters`,
+		},
+		"slice too short": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeStringSlice, Min: pointerutil.Int64Ptr(2)},
+			},
+			map[string]interface{}{"foo": []string{"a"}},
+			`field "foo" must be at least 2 elements`,
+		},
+		"allowed values not enforced": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, AllowedValues: []interface{}{"a", "b"}},
+			},
+			map[string]interface{}{"foo": "c"},
+			"",
+		},
+		"allowed value": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, AllowedValues: []interface{}{"a", "b"}, EnforceAllowedValues: true},
+			},
+			map[string]interface{}{"foo": "b"},
+			"",
+		},
+		"disallowed value": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, AllowedValues: []interface{}{"a", "b"}, EnforceAllowedValues: true},
+			},
+			map[string]interface{}{"foo": "c"},
+			`field "foo" value "c" is not one of [a b]`,
+		},
+		"disallowed int in slice": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeCommaIntSlice, AllowedValues: []interface{}{1, 2}, EnforceAllowedValues: true},
+			},
+			map[string]interface{}{"foo": "1,3"},
+			`field "foo" value "3" is not one of [1 2]`,
+		},
+		"conflicting fields": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, ConflictsWith: []string{"bar"}},
+				"bar": {Type: TypeString},
+			},
+			map[string]interface{}{"foo": "a", "bar": "b"},
+			`field "foo" cannot be set together with "bar"`,
+		},
+		"conflicting field unset": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, ConflictsWith: []string{"bar"}},
+				"bar": {Type: TypeString},
+			},
+			map[string]interface{}{"foo": "a", "bar": nil},
+			"",
+		},
+		"required field missing": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, RequiredWith: []string{"bar"}},
+				"bar": {Type: TypeString},
+			},
+			map[string]interface{}{"foo": "a"},
+			`field "foo" requires "bar" to also be set`,
+		},
+		"required field present": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, RequiredWith: []string{"bar"}},
+				"bar": {Type: TypeString},
+			},
+			map[string]interface{}{"foo": "a", "bar": "b"},
+			"",
+		},
+		"constraints only apply to set fields": {
+			map[string]*FieldSchema{
+				"foo": {Type: TypeString, Min: pointerutil.Int64Ptr(1), RequiredWith: []string{"bar"}},
+				"bar": {Type: TypeString},
+			},
+			map[string]interface{}{},
+			"",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data := &FieldData{
+				Raw:    tc.Raw,
+				Schema: tc.Schema,
+			}
+
+			err := data.Validate()
+			switch {
+			case tc.Error == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tc.Error != "" && err == nil:
+				t.Fatalf("expected error %q", tc.Error)
+			case tc.Error != "" && err.Error() != tc.Error:
+				t.Fatalf("expected error %q, got %q", tc.Error, err.Error())
+			}
+		})
+	}
+}
+
+func TestBackendHandleRequest_constraints(t *testing.T) {
+	called := false
+	b := &Backend{
+		Paths: []*Path{
+			{
+				Pattern: "foo/bar",
+				Fields: map[string]*FieldSchema{
+					"value": {Type: TypeInt, Max: pointerutil.Int64Ptr(5)},
+				},
+				Callbacks: map[logical.Operation]OperationFunc{
+					logical.UpdateOperation: func(context.Context, *logical.Request, *FieldData) (*logical.Response, error) {
+						called = true
+						return nil, nil
+					},
+				},
+			},
+		},
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.UpdateOperation,
+		Path:      "foo/bar",
+		Data:      map[string]interface{}{"value": 6},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if called {
+		t.Fatal("expected callback not to be called")
+	}
+	if resp.Data["error"] != `Field validation failed: field "value" must be at most 5` {
+		t.Fatalf("bad: %#v", resp)
+	}
+}
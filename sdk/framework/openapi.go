@@ -116,6 +116,7 @@ type OASPathItem struct {
 
 	Get    *OASOperation `json:"get,omitempty"`
 	Post   *OASOperation `json:"post,omitempty"`
+	Patch  *OASOperation `json:"patch,omitempty"`
 	Delete *OASOperation `json:"delete,omitempty"`
 }
 
@@ -173,6 +174,12 @@ type OASSchema struct {
 	Items      *OASSchema    `json:"items,omitempty"`
 	Format     string        `json:"format,omitempty"`
 	Pattern    string        `json:"pattern,omitempty"`
+	Minimum    *int64        `json:"minimum,omitempty"`
+	Maximum    *int64        `json:"maximum,omitempty"`
+	MinLength  *int64        `json:"minLength,omitempty"`
+	MaxLength  *int64        `json:"maxLength,omitempty"`
+	MinItems   *int64        `json:"minItems,omitempty"`
+	MaxItems   *int64        `json:"maxItems,omitempty"`
 	Enum       []interface{} `json:"enum,omitempty"`
 	Default    interface{}   `json:"default,omitempty"`
 	Example    interface{}   `json:"example,omitempty"`
@@ -311,6 +318,7 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 				Required:   true,
 				Deprecated: field.Deprecated,
 			}
+			addFieldConstraintsToOASSchema(p.Schema, field)
 			pi.Parameters = append(pi.Parameters, p)
 		}
 
@@ -354,11 +362,9 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 			op.OperationID = operationID
 
 			switch opType {
-			// For the operation types which map to POST/PUT methods, and so allow for request body parameters,
+			// For the operation types which map to POST/PUT/PATCH methods, and so allow for request body parameters,
 			// prepare the request body definition
-			case logical.CreateOperation:
-				fallthrough
-			case logical.UpdateOperation:
+			case logical.CreateOperation, logical.UpdateOperation, logical.PatchOperation:
 				s := &OASSchema{
 					Type:       "object",
 					Properties: make(map[string]*OASSchema),
@@ -382,6 +388,15 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 					addFieldToOASSchema(s, name, field)
 				}
 
+				// A PATCH request only carries the fields being changed, so none of them are required and omitted
+				// fields keep their current value rather than a default.
+				if opType == logical.PatchOperation {
+					s.Required = nil
+					for _, prop := range s.Properties {
+						prop.Default = nil
+					}
+				}
+
 				// Make the ordering deterministic, so that the generated OpenAPI spec document, observed over several
 				// versions, doesn't contain spurious non-semantic changes.
 				sort.Strings(s.Required)
@@ -404,14 +419,20 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 					s.AdditionalProperties = true
 				}
 
-				// Set the final request body. Only JSON request data is supported.
+				// Set the final request body. Only JSON request data is supported, which PATCH requests must send
+				// as a JSON merge patch.
+				mediaType := "application/json"
+				if opType == logical.PatchOperation {
+					mediaType = "application/merge-patch+json"
+				}
+
 				if len(s.Properties) > 0 {
 					requestName := hyphenatedToTitleCase(operationID) + "Request"
 					doc.Components.Schemas[requestName] = s
 					op.RequestBody = &OASRequestBody{
 						Required: true,
 						Content: OASContent{
-							"application/json": &OASMediaTypeObject{
+							mediaType: &OASMediaTypeObject{
 								Schema: &OASSchema{Ref: fmt.Sprintf("#/components/schemas/%s", requestName)},
 							},
 						},
@@ -422,7 +443,7 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 					op.RequestBody = &OASRequestBody{
 						Required: true,
 						Content: OASContent{
-							"application/json": &OASMediaTypeObject{
+							mediaType: &OASMediaTypeObject{
 								Schema: s,
 							},
 						},
@@ -460,6 +481,7 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 						},
 						Deprecated: field.Deprecated,
 					}
+					addFieldConstraintsToOASSchema(p.Schema, field)
 					op.Parameters = append(op.Parameters, p)
 				}
 
@@ -492,8 +514,22 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 				}
 			}
 
-			// Add any defined response details.
-			for code, responses := range props.Responses {
+			// Add any defined response details. Codes are visited in order so that
+			// response schema names are stable: the first code declaring fields
+			// gets the plain "<Operation>Response" schema, and any further codes
+			// are suffixed with their status code.
+			responseCodes := make([]int, 0, len(props.Responses))
+			for code := range props.Responses {
+				responseCodes = append(responseCodes, code)
+			}
+			sort.Ints(responseCodes)
+
+			operationTitle := hyphenatedToTitleCase(operationID)
+			responseNameUsed := false
+
+			for _, code := range responseCodes {
+				responses := props.Responses[code]
+				var responseName string
 				var description string
 				content := make(OASContent)
 
@@ -526,27 +562,19 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 					}
 
 					for name, field := range resp.Fields {
-						openapiField := convertType(field.Type)
-						p := OASSchema{
-							Type:         openapiField.baseType,
-							Description:  cleanString(field.Description),
-							Format:       openapiField.format,
-							Pattern:      openapiField.pattern,
-							Enum:         field.AllowedValues,
-							Default:      field.Default,
-							Deprecated:   field.Deprecated,
-							DisplayAttrs: withoutOperationHints(field.DisplayAttrs),
-						}
-						if openapiField.baseType == "array" {
-							p.Items = &OASSchema{
-								Type: openapiField.items,
-							}
-						}
-						responseSchema.Properties[name] = &p
+						addFieldToOASSchema(responseSchema, name, field)
 					}
+					sort.Strings(responseSchema.Required)
 
 					if len(resp.Fields) != 0 {
-						responseName := hyphenatedToTitleCase(operationID) + "Response"
+						if responseName == "" {
+							responseName = operationTitle + "Response"
+							if responseNameUsed {
+								responseName = fmt.Sprintf("%s%dResponse", operationTitle, code)
+							}
+							responseNameUsed = true
+						}
+
 						doc.Components.Schemas[responseName] = responseSchema
 						content = OASContent{
 							"application/json": &OASMediaTypeObject{
@@ -565,6 +593,8 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 			switch opType {
 			case logical.CreateOperation, logical.UpdateOperation:
 				pi.Post = op
+			case logical.PatchOperation:
+				pi.Patch = op
 			case logical.ReadOperation:
 				pi.Get = op
 			case logical.DeleteOperation:
@@ -579,12 +609,13 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 		// the two following blocks of code (non-list, and list) write an OpenAPI path to the output document, then the
 		// first one will definitely not have a trailing slash.
 		originalPathHasTrailingSlash := strings.HasSuffix(path, "/")
-		if originalPathHasTrailingSlash && (pi.Get != nil || pi.Post != nil || pi.Delete != nil) {
+		if originalPathHasTrailingSlash && (pi.Get != nil || pi.Post != nil || pi.Patch != nil || pi.Delete != nil) {
 			backend.Logger().Warn(
 				"OpenAPI spec generation: discarding impossible-to-invoke non-list operations from path with "+
 					"required trailing slash; this is a bug in the backend code", "path", path)
 			pi.Get = nil
 			pi.Post = nil
+			pi.Patch = nil
 			pi.Delete = nil
 		}
 
@@ -597,7 +628,7 @@ func documentPath(p *Path, backend *Backend, requestResponsePrefix string, doc *
 		// to provide documentation to a human that an endpoint exists, even if it has no invokable OpenAPI operations.
 		// Examples of this include kv-v2's ".*" endpoint (regex cannot be translated to OpenAPI parameters), and the
 		// auth/oci/login endpoint (implements ResolveRoleOperation only, only callable from inside Vault).
-		if listOperation == nil || pi.Get != nil || pi.Post != nil || pi.Delete != nil {
+		if listOperation == nil || pi.Get != nil || pi.Post != nil || pi.Patch != nil || pi.Delete != nil {
 			openAPIPath := "/" + path
 			if doc.Paths[openAPIPath] != nil {
 				backend.Logger().Warn(
@@ -664,10 +695,33 @@ func addFieldToOASSchema(s *OASSchema, name string, field *FieldSchema) {
 			Type: openapiField.items,
 		}
 	}
+	addFieldConstraintsToOASSchema(&p, field)
 
 	s.Properties[name] = &p
 }
 
+// addFieldConstraintsToOASSchema documents the validation constraints of the
+// field in its schema.
+func addFieldConstraintsToOASSchema(p *OASSchema, field *FieldSchema) {
+	switch {
+	case p.Format == "duration":
+		// Bounds of durations are in seconds, which cannot be expressed for
+		// the string representation.
+	case p.Type == "string":
+		p.MinLength, p.MaxLength = field.Min, field.Max
+		if field.Pattern != "" {
+			p.Pattern = field.Pattern
+		}
+	case p.Type == "integer" || p.Type == "number":
+		p.Minimum, p.Maximum = field.Min, field.Max
+	case p.Type == "array":
+		p.MinItems, p.MaxItems = field.Min, field.Max
+		if field.Pattern != "" && p.Items != nil && p.Items.Type == "string" {
+			p.Items.Pattern = field.Pattern
+		}
+	}
+}
+
 // specialPathMatch checks whether the given path matches one of the special
 // paths, taking into account * and + wildcards (e.g. foo/+/bar/*)
 func specialPathMatch(path string, specialPaths []string) bool {
@@ -1220,13 +1274,15 @@ func (d *OASDocument) CreateOperationIDs(context string) {
 
 	for _, path := range paths {
 		pi := d.Paths[path]
-		for _, method := range []string{"get", "post", "delete"} {
+		for _, method := range []string{"get", "post", "patch", "delete"} {
 			var oasOperation *OASOperation
 			switch method {
 			case "get":
 				oasOperation = pi.Get
 			case "post":
 				oasOperation = pi.Post
+			case "patch":
+				oasOperation = pi.Patch
 			case "delete":
 				oasOperation = pi.Delete
 			}
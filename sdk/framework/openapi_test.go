@@ -16,6 +16,7 @@ import (
 
 	"github.com/go-test/deep"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/helper/pointerutil"
 	"github.com/hashicorp/vault/sdk/helper/wrapping"
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -542,6 +543,41 @@ func TestOpenAPI_Paths(t *testing.T) {
 		testPath(t, p, sp, expected("operations_list"))
 	})
 
+	t.Run("Operations - Patch", func(t *testing.T) {
+		p := &Path{
+			Pattern: "foo/" + GenericNameRegex("id"),
+			Fields: map[string]*FieldSchema{
+				"id": {
+					Type:        TypeString,
+					Description: "id path parameter",
+				},
+				"name": {
+					Type:        TypeNameString,
+					Default:     "Larry",
+					Description: "the name",
+				},
+				"age": {
+					Type:        TypeInt,
+					Description: "the age",
+					Required:    true,
+				},
+			},
+			HelpSynopsis:    "Synopsis",
+			HelpDescription: "Description",
+			Operations: map[logical.Operation]OperationHandler{
+				logical.UpdateOperation: &PathOperation{
+					Summary: "Update Summary",
+				},
+				logical.PatchOperation: &PathOperation{
+					Summary: "Patch Summary",
+				},
+			},
+		}
+
+		sp := &logical.Paths{}
+		testPath(t, p, sp, expected("operations_patch"))
+	})
+
 	t.Run("Responses", func(t *testing.T) {
 		p := &Path{
 			Pattern:         "foo",
@@ -584,6 +620,49 @@ func TestOpenAPI_Paths(t *testing.T) {
 
 		testPath(t, p, sp, expected("responses"))
 	})
+
+	t.Run("Responses - Multiple Status Codes", func(t *testing.T) {
+		p := &Path{
+			Pattern:      "foo",
+			HelpSynopsis: "Synopsis",
+			Operations: map[logical.Operation]OperationHandler{
+				logical.UpdateOperation: &PathOperation{
+					Summary: "Update stuff",
+					Responses: map[int][]Response{
+						200: {{
+							Description: "OK",
+							Fields: map[string]*FieldSchema{
+								"id": {
+									Type:        TypeString,
+									Description: "id description",
+									Required:    true,
+								},
+								"tags": {
+									Type:        TypeCommaStringSlice,
+									Description: "tags description",
+								},
+							},
+						}},
+						202: {{
+							Description: "Accepted",
+							Fields: map[string]*FieldSchema{
+								"request_id": {
+									Type:        TypeString,
+									Description: "request_id description",
+									Required:    true,
+								},
+							},
+						}},
+						204: {{
+							Description: "empty body",
+						}},
+					},
+				},
+			},
+		}
+
+		testPath(t, p, &logical.Paths{}, expected("responses_status_codes"))
+	})
 }
 
 func TestOpenAPI_CustomDecoder(t *testing.T) {
@@ -639,6 +718,28 @@ func TestOpenAPI_CustomDecoder(t *testing.T) {
 	}
 }
 
+func TestOpenAPI_FieldConstraints(t *testing.T) {
+	s := &OASSchema{Properties: make(map[string]*OASSchema)}
+	addFieldToOASSchema(s, "name", &FieldSchema{Type: TypeString, Pattern: "^[a-z]+$", Max: pointerutil.Int64Ptr(16)})
+	addFieldToOASSchema(s, "count", &FieldSchema{Type: TypeInt, Min: pointerutil.Int64Ptr(1)})
+	addFieldToOASSchema(s, "tags", &FieldSchema{Type: TypeCommaStringSlice, Pattern: "^[a-z]+$", Max: pointerutil.Int64Ptr(3)})
+	addFieldToOASSchema(s, "ttl", &FieldSchema{Type: TypeDurationSecond, Max: pointerutil.Int64Ptr(60)})
+
+	expected := map[string]*OASSchema{
+		"name":  {Type: "string", Pattern: "^[a-z]+$", MaxLength: pointerutil.Int64Ptr(16)},
+		"count": {Type: "integer", Minimum: pointerutil.Int64Ptr(1)},
+		"tags": {
+			Type:     "array",
+			Items:    &OASSchema{Type: "string", Pattern: "^[a-z]+$"},
+			MaxItems: pointerutil.Int64Ptr(3),
+		},
+		"ttl": {Type: "string", Format: "duration"},
+	}
+	if diff := deep.Equal(s.Properties, expected); diff != nil {
+		t.Fatal(diff)
+	}
+}
+
 func TestOpenAPI_CleanResponse(t *testing.T) {
 	// Verify that an all-null input results in empty JSON
 	orig := &logical.Response{}
@@ -923,6 +1024,8 @@ func getPathOp(pi *OASPathItem, op string) *OASOperation {
 		return pi.Get
 	case "post":
 		return pi.Post
+	case "patch":
+		return pi.Patch
 	case "delete":
 		return pi.Delete
 	default:
@@ -0,0 +1,254 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package framework
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/vault/sdk/logical"
+	"github.com/robfig/cron/v3"
+)
+
+// PeriodicJobsPath is the path at which a backend with PeriodicJobs reports
+// the status of its jobs.
+const PeriodicJobsPath = "periodic-jobs"
+
+var periodicJobParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// PeriodicJob is a named job that is run by the periodic timer of the
+// RollbackManager, which ticks about once a minute.
+type PeriodicJob struct {
+	// Name identifies the job in its status.
+	Name string
+
+	// Schedule is an optional cron expression with 5 fields, or a
+	// descriptor such as "@daily", giving the times at which the job runs.
+	// If it is empty, the job runs on every tick of the periodic timer,
+	// like PeriodicFunc.
+	Schedule string
+
+	// Jitter is the maximum delay after each scheduled time before the job
+	// runs. The delay is derived from the mount and the job name, so that it
+	// is stable for a mount but the same job of different mounts does not run
+	// at the same time. It should be shorter than the time between two
+	// scheduled runs, and is ignored for jobs without a Schedule.
+	Jitter time.Duration
+
+	// Func is called to run the job. The same considerations about writing
+	// to storage as for PeriodicFunc apply.
+	Func func(context.Context, *logical.Request) error
+}
+
+// PeriodicJobStatus reports the last and next run of a periodic job. It is
+// kept in memory, so it is reset when the backend is reloaded.
+type PeriodicJobStatus struct {
+	Schedule        string
+	Jitter          time.Duration
+	LastRunTime     time.Time
+	LastRunDuration time.Duration
+	LastError       string
+	NextRunTime     time.Time
+}
+
+type periodicJobState struct {
+	job     *PeriodicJob
+	spec    cron.Schedule
+	offset  time.Duration
+	running bool
+	status  PeriodicJobStatus
+}
+
+type periodicJobs struct {
+	lock  sync.Mutex
+	jobs  []*periodicJobState
+	index map[string]*periodicJobState
+	now   func() time.Time
+}
+
+// initPeriodicJobs validates the backend's PeriodicJobs, panicking on coding
+// errors like init does for paths, and adds the status path.
+func (b *Backend) initPeriodicJobs() {
+	if len(b.PeriodicJobs) == 0 {
+		return
+	}
+
+	b.periodicJobs = &periodicJobs{
+		index: make(map[string]*periodicJobState, len(b.PeriodicJobs)),
+		now:   time.Now,
+	}
+	for _, job := range b.PeriodicJobs {
+		if job.Name == "" || job.Func == nil {
+			panic("periodic jobs must have a name and a function")
+		}
+		if _, ok := b.periodicJobs.index[job.Name]; ok {
+			panic(fmt.Sprintf("duplicate periodic job %q", job.Name))
+		}
+
+		state := &periodicJobState{
+			job: job,
+			status: PeriodicJobStatus{
+				Schedule: job.Schedule,
+				Jitter:   job.Jitter,
+			},
+		}
+		if job.Schedule != "" {
+			spec, err := periodicJobParser.Parse(job.Schedule)
+			if err != nil {
+				panic(fmt.Sprintf("invalid schedule %q for periodic job %q: %v", job.Schedule, job.Name, err))
+			}
+			state.spec = spec
+		}
+
+		b.periodicJobs.jobs = append(b.periodicJobs.jobs, state)
+		b.periodicJobs.index[job.Name] = state
+	}
+
+	b.Paths = append(b.Paths, b.periodicJobsStatusPath())
+}
+
+// runPeriodicJobs runs the periodic jobs which are due.
+func (b *Backend) runPeriodicJobs(ctx context.Context, req *logical.Request) error {
+	pj := b.periodicJobs
+	if pj == nil {
+		return nil
+	}
+
+	now := pj.now()
+
+	pj.lock.Lock()
+	var due []*periodicJobState
+	for _, state := range pj.jobs {
+		if state.running {
+			continue
+		}
+		if state.spec != nil && state.status.NextRunTime.IsZero() {
+			// The first tick only schedules the job, so that jobs do not
+			// all run whenever the backend is set up.
+			state.offset = b.periodicJobOffset(state.job)
+			state.status.NextRunTime = state.spec.Next(now).Add(state.offset)
+			continue
+		}
+		if now.Before(state.status.NextRunTime) {
+			continue
+		}
+		state.running = true
+		due = append(due, state)
+	}
+	pj.lock.Unlock()
+
+	var merr *multierror.Error
+	for _, state := range due {
+		err := state.job.Func(ctx, req)
+		finished := pj.now()
+
+		pj.lock.Lock()
+		state.running = false
+		state.status.LastRunTime = now
+		state.status.LastRunDuration = finished.Sub(now)
+		state.status.LastError = ""
+		if err != nil {
+			state.status.LastError = err.Error()
+			merr = multierror.Append(merr, fmt.Errorf("periodic job %q: %w", state.job.Name, err))
+		}
+		if state.spec != nil {
+			state.status.NextRunTime = state.spec.Next(now.Add(-state.offset)).Add(state.offset)
+		}
+		pj.lock.Unlock()
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// periodicJobOffset returns the jitter of the job for this mount.
+func (b *Backend) periodicJobOffset(job *PeriodicJob) time.Duration {
+	if job.Jitter <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(b.backendUUID + "/" + job.Name))
+	return time.Duration(h.Sum64() % uint64(job.Jitter))
+}
+
+// PeriodicJobStatuses returns the status of the backend's periodic jobs,
+// keyed by name.
+func (b *Backend) PeriodicJobStatuses() map[string]PeriodicJobStatus {
+	b.once.Do(b.init)
+
+	pj := b.periodicJobs
+	if pj == nil {
+		return nil
+	}
+
+	pj.lock.Lock()
+	defer pj.lock.Unlock()
+
+	statuses := make(map[string]PeriodicJobStatus, len(pj.jobs))
+	for _, state := range pj.jobs {
+		statuses[state.job.Name] = state.status
+	}
+	return statuses
+}
+
+func (b *Backend) periodicJobsStatusPath() *Path {
+	return &Path{
+		Pattern: PeriodicJobsPath + "/?$",
+
+		Operations: map[logical.Operation]OperationHandler{
+			logical.ReadOperation: &PathOperation{
+				Callback: b.handlePeriodicJobsStatus,
+				Summary:  "Report the status of the periodic jobs of the mount.",
+				Responses: map[int][]Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*FieldSchema{
+							"jobs": {
+								Type:        TypeMap,
+								Description: "The status of each periodic job, keyed by name.",
+								Required:    true,
+							},
+						},
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    "Report the status of the periodic jobs of the mount.",
+		HelpDescription: "Reports the schedule, last run and next run of each periodic job of the mount.",
+	}
+}
+
+func (b *Backend) handlePeriodicJobsStatus(_ context.Context, _ *logical.Request, _ *FieldData) (*logical.Response, error) {
+	statuses := b.PeriodicJobStatuses()
+
+	jobs := make(map[string]interface{}, len(statuses))
+	for name, status := range statuses {
+		jobs[name] = map[string]interface{}{
+			"schedule":          status.Schedule,
+			"jitter":            int64(status.Jitter.Seconds()),
+			"last_run_time":     formatPeriodicJobTime(status.LastRunTime),
+			"last_run_duration": status.LastRunDuration.String(),
+			"last_error":        status.LastError,
+			"next_run_time":     formatPeriodicJobTime(status.NextRunTime),
+		}
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"jobs": jobs,
+		},
+	}, nil
+}
+
+func formatPeriodicJobTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
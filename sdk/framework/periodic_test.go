@@ -0,0 +1,173 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package framework
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestBackend_PeriodicJobs(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 30, 0, time.UTC)
+
+	var hourly, everyTick int
+	var fail bool
+	b := &Backend{
+		PeriodicJobs: []*PeriodicJob{
+			{
+				Name:     "hourly",
+				Schedule: "0 * * * *",
+				Func: func(context.Context, *logical.Request) error {
+					hourly++
+					if fail {
+						return errors.New("boom")
+					}
+					return nil
+				},
+			},
+			{
+				Name: "every-tick",
+				Func: func(context.Context, *logical.Request) error {
+					everyTick++
+					return nil
+				},
+			},
+		},
+	}
+	b.once.Do(b.init)
+	b.periodicJobs.now = func() time.Time { return now }
+
+	rollback := func() error {
+		_, err := b.HandleRequest(context.Background(), &logical.Request{Operation: logical.RollbackOperation})
+		return err
+	}
+
+	// The first tick only schedules the cron job.
+	if err := rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if hourly != 0 || everyTick != 1 {
+		t.Fatalf("unexpected runs: hourly %d, every tick %d", hourly, everyTick)
+	}
+	status := b.PeriodicJobStatuses()["hourly"]
+	if !status.NextRunTime.Equal(time.Date(2023, 6, 1, 13, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next run time %s", status.NextRunTime)
+	}
+
+	now = now.Add(30 * time.Minute)
+	if err := rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if hourly != 0 || everyTick != 2 {
+		t.Fatalf("unexpected runs: hourly %d, every tick %d", hourly, everyTick)
+	}
+
+	now = time.Date(2023, 6, 1, 13, 0, 30, 0, time.UTC)
+	fail = true
+	if err := rollback(); err == nil {
+		t.Fatal("expected error from failing job")
+	}
+	if hourly != 1 {
+		t.Fatalf("expected 1 hourly run, got %d", hourly)
+	}
+	status = b.PeriodicJobStatuses()["hourly"]
+	if !status.LastRunTime.Equal(now) || status.LastError != "boom" ||
+		!status.NextRunTime.Equal(time.Date(2023, 6, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected status %#v", status)
+	}
+
+	resp, err := b.HandleRequest(context.Background(), &logical.Request{
+		Operation: logical.ReadOperation,
+		Path:      PeriodicJobsPath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs := resp.Data["jobs"].(map[string]interface{})
+	if len(jobs) != 2 {
+		t.Fatalf("unexpected jobs %#v", jobs)
+	}
+	job := jobs["hourly"].(map[string]interface{})
+	if job["last_error"] != "boom" || job["next_run_time"] != "2023-06-01T14:00:00Z" || job["schedule"] != "0 * * * *" {
+		t.Fatalf("unexpected job status %#v", job)
+	}
+}
+
+func TestBackend_PeriodicJobs_Jitter(t *testing.T) {
+	job := &PeriodicJob{Name: "job", Schedule: "@daily", Jitter: time.Hour, Func: func(context.Context, *logical.Request) error { return nil }}
+
+	offset := func(uuid string) time.Duration {
+		b := &Backend{backendUUID: uuid}
+		return b.periodicJobOffset(job)
+	}
+
+	a := offset("a4b1d6e2-0000-0000-0000-000000000001")
+	if a < 0 || a >= job.Jitter {
+		t.Fatalf("offset %s is out of range", a)
+	}
+	if a != offset("a4b1d6e2-0000-0000-0000-000000000001") {
+		t.Fatal("expected the offset to be stable for a mount")
+	}
+	if a == offset("a4b1d6e2-0000-0000-0000-000000000002") {
+		t.Fatal("expected different mounts to have different offsets")
+	}
+
+	// The jitter applies to every scheduled run.
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	runs := 0
+	b := &Backend{
+		backendUUID: "a4b1d6e2-0000-0000-0000-000000000001",
+		PeriodicJobs: []*PeriodicJob{{
+			Name:     job.Name,
+			Schedule: job.Schedule,
+			Jitter:   job.Jitter,
+			Func: func(context.Context, *logical.Request) error {
+				runs++
+				return nil
+			},
+		}},
+	}
+	b.once.Do(b.init)
+	b.periodicJobs.now = func() time.Time { return now }
+
+	midnight := time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC)
+	b.runPeriodicJobs(context.Background(), &logical.Request{})
+	if next := b.PeriodicJobStatuses()[job.Name].NextRunTime; !next.Equal(midnight.Add(a)) {
+		t.Fatalf("unexpected next run time %s", next)
+	}
+
+	now = midnight.Add(a)
+	b.runPeriodicJobs(context.Background(), &logical.Request{})
+	if runs != 1 {
+		t.Fatalf("expected 1 run, got %d", runs)
+	}
+	if next := b.PeriodicJobStatuses()[job.Name].NextRunTime; !next.Equal(midnight.Add(24 * time.Hour).Add(a)) {
+		t.Fatalf("unexpected next run time %s", next)
+	}
+}
+
+func TestBackend_PeriodicJobs_Invalid(t *testing.T) {
+	noop := func(context.Context, *logical.Request) error { return nil }
+
+	for name, jobs := range map[string][]*PeriodicJob{
+		"no name":      {{Func: noop}},
+		"no func":      {{Name: "job"}},
+		"duplicate":    {{Name: "job", Func: noop}, {Name: "job", Func: noop}},
+		"bad schedule": {{Name: "job", Schedule: "not a schedule", Func: noop}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			b := &Backend{PeriodicJobs: jobs}
+			b.once.Do(b.init)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// VaultClient is the client API for Vault service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type VaultClient interface {
+	// Do handles a single request.
+	Do(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error)
+	// DoStream handles every request sent on the stream, in order, and sends
+	// back a response for each of them. It avoids the per-request overhead of
+	// the HTTP API for clients issuing many requests, such as bulk transit
+	// operations or KV reads.
+	DoStream(ctx context.Context, opts ...grpc.CallOption) (Vault_DoStreamClient, error)
+}
+
+type vaultClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVaultClient(cc grpc.ClientConnInterface) VaultClient {
+	return &vaultClient{cc}
+}
+
+func (c *vaultClient) Do(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Response, error) {
+	out := new(Response)
+	err := c.cc.Invoke(ctx, "/grpcapi.Vault/Do", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vaultClient) DoStream(ctx context.Context, opts ...grpc.CallOption) (Vault_DoStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Vault_ServiceDesc.Streams[0], "/grpcapi.Vault/DoStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &vaultDoStreamClient{stream}
+	return x, nil
+}
+
+type Vault_DoStreamClient interface {
+	Send(*Request) error
+	Recv() (*Response, error)
+	grpc.ClientStream
+}
+
+type vaultDoStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *vaultDoStreamClient) Send(m *Request) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *vaultDoStreamClient) Recv() (*Response, error) {
+	m := new(Response)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VaultServer is the server API for Vault service.
+// All implementations must embed UnimplementedVaultServer
+// for forward compatibility
+type VaultServer interface {
+	// Do handles a single request.
+	Do(context.Context, *Request) (*Response, error)
+	// DoStream handles every request sent on the stream, in order, and sends
+	// back a response for each of them. It avoids the per-request overhead of
+	// the HTTP API for clients issuing many requests, such as bulk transit
+	// operations or KV reads.
+	DoStream(Vault_DoStreamServer) error
+	mustEmbedUnimplementedVaultServer()
+}
+
+// UnimplementedVaultServer must be embedded to have forward compatible implementations.
+type UnimplementedVaultServer struct {
+}
+
+func (UnimplementedVaultServer) Do(context.Context, *Request) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Do not implemented")
+}
+func (UnimplementedVaultServer) DoStream(Vault_DoStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method DoStream not implemented")
+}
+func (UnimplementedVaultServer) mustEmbedUnimplementedVaultServer() {}
+
+// UnsafeVaultServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VaultServer will
+// result in compilation errors.
+type UnsafeVaultServer interface {
+	mustEmbedUnimplementedVaultServer()
+}
+
+func RegisterVaultServer(s grpc.ServiceRegistrar, srv VaultServer) {
+	s.RegisterService(&Vault_ServiceDesc, srv)
+}
+
+func _Vault_Do_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VaultServer).Do(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Vault/Do",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VaultServer).Do(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Vault_DoStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(VaultServer).DoStream(&vaultDoStreamServer{stream})
+}
+
+type Vault_DoStreamServer interface {
+	Send(*Response) error
+	Recv() (*Request, error)
+	grpc.ServerStream
+}
+
+type vaultDoStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *vaultDoStreamServer) Send(m *Response) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *vaultDoStreamServer) Recv() (*Request, error) {
+	m := new(Request)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Vault_ServiceDesc is the grpc.ServiceDesc for Vault service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Vault_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcapi.Vault",
+	HandlerType: (*VaultServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Do",
+			Handler:    _Vault_Do_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DoStream",
+			Handler:       _Vault_DoStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "sdk/grpcapi/grpcapi.proto",
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math/big"
 	mathrand "math/rand"
+	"net"
 	"reflect"
 	"strings"
 	"sync"
@@ -1006,6 +1007,63 @@ func TestBasicConstraintExtension(t *testing.T) {
 	})
 }
 
+// TestNameConstraintsExtension verifies the name constraints extension
+// requested in CSRs matches the one Go encodes into certificates.
+func TestNameConstraintsExtension(t *testing.T) {
+	t.Parallel()
+
+	_, permittedIP, _ := net.ParseCIDR("10.0.0.0/8")
+	_, excludedIP, _ := net.ParseCIDR("2001:db8::/32")
+	params := &CreationParameters{
+		PermittedDNSDomains:     []string{"example.com", ".internal.example.com"},
+		ExcludedDNSDomains:      []string{"secret.example.com"},
+		PermittedIPRanges:       []*net.IPNet{permittedIP},
+		ExcludedIPRanges:        []*net.IPNet{excludedIP},
+		PermittedEmailAddresses: []string{"example.com"},
+		ExcludedEmailAddresses:  []string{"root@example.com"},
+		PermittedURIDomains:     []string{".example.com"},
+		ExcludedURIDomains:      []string{"bad.example.com"},
+	}
+
+	ext, err := CreateNameConstraintsExtension(params)
+	if err != nil {
+		t.Fatalf("failed generating name constraints extension: %v", err)
+	}
+	if !ext.Critical {
+		t.Fatalf("expected name constraints extension to be critical")
+	}
+
+	key := genRsaKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "name constraints"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	AddNameConstraints(&CreationBundle{Params: params}, template)
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed creating certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed parsing certificate: %v", err)
+	}
+
+	for _, certExt := range cert.Extensions {
+		if certExt.Id.Equal(ExtensionNameConstraintsOID) {
+			if !bytes.Equal(certExt.Value, ext.Value) {
+				t.Fatalf("name constraints extension differs from Go's encoding:\n%x\n%x", ext.Value, certExt.Value)
+			}
+			return
+		}
+	}
+	t.Fatalf("certificate lacks a name constraints extension")
+}
+
 func genRsaKey(t *testing.T) *rsa.PrivateKey {
 	key, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
@@ -92,6 +92,11 @@ var CRLNumberOID = asn1.ObjectIdentifier([]int{2, 5, 29, 20})
 // > id-ce-deltaCRLIndicator OBJECT IDENTIFIER ::= { id-ce 27 }
 var DeltaCRLIndicatorOID = asn1.ObjectIdentifier([]int{2, 5, 29, 27})
 
+// OID for RFC 5280 Freshest CRL extension.
+//
+// > id-ce-freshestCRL OBJECT IDENTIFIER ::=  { id-ce 46 }
+var FreshestCRLOID = asn1.ObjectIdentifier([]int{2, 5, 29, 46})
+
 // GetHexFormatted returns the byte buffer formatted in hex with
 // the specified separator between bytes.
 func GetHexFormatted(buf []byte, sep string) string {
@@ -547,6 +552,105 @@ func AddExtKeyUsageOids(data *CreationBundle, certTemplate *x509.Certificate) {
 	}
 }
 
+// AddExtraExtensions adds the additional extensions from the CreationBundle to
+// the certificate, replacing any extension already present with the same OID
+func AddExtraExtensions(data *CreationBundle, certTemplate *x509.Certificate) {
+	for _, ext := range data.Params.ExtraExtensions {
+		replaced := false
+		for index, existing := range certTemplate.ExtraExtensions {
+			if existing.Id.Equal(ext.Id) {
+				certTemplate.ExtraExtensions[index] = ext
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			certTemplate.ExtraExtensions = append(certTemplate.ExtraExtensions, ext)
+		}
+	}
+}
+
+// HasNameConstraints returns whether any name constraints were requested
+// for the CA certificate being generated or signed.
+func (p *CreationParameters) HasNameConstraints() bool {
+	return len(p.PermittedDNSDomains) > 0 || len(p.ExcludedDNSDomains) > 0 ||
+		len(p.PermittedIPRanges) > 0 || len(p.ExcludedIPRanges) > 0 ||
+		len(p.PermittedEmailAddresses) > 0 || len(p.ExcludedEmailAddresses) > 0 ||
+		len(p.PermittedURIDomains) > 0 || len(p.ExcludedURIDomains) > 0
+}
+
+// AddNameConstraints adds the name constraints extension, marked critical as
+// RFC 5280 requires, based on CreationBundle
+func AddNameConstraints(data *CreationBundle, certTemplate *x509.Certificate) {
+	if !data.Params.HasNameConstraints() {
+		return
+	}
+
+	certTemplate.PermittedDNSDomainsCritical = true
+	certTemplate.PermittedDNSDomains = data.Params.PermittedDNSDomains
+	certTemplate.ExcludedDNSDomains = data.Params.ExcludedDNSDomains
+	certTemplate.PermittedIPRanges = data.Params.PermittedIPRanges
+	certTemplate.ExcludedIPRanges = data.Params.ExcludedIPRanges
+	certTemplate.PermittedEmailAddresses = data.Params.PermittedEmailAddresses
+	certTemplate.ExcludedEmailAddresses = data.Params.ExcludedEmailAddresses
+	certTemplate.PermittedURIDomains = data.Params.PermittedURIDomains
+	certTemplate.ExcludedURIDomains = data.Params.ExcludedURIDomains
+}
+
+// CreateNameConstraintsExtension marshals the name constraints of the given
+// parameters into an extension, for requesting them in a CSR; Go only
+// encodes name constraints itself when creating certificates.
+func CreateNameConstraintsExtension(params *CreationParameters) (*pkix.Extension, error) {
+	addSubtrees := func(b *cryptobyte.Builder, tag cbasn1.Tag, dns []string, ips []*net.IPNet, emails []string, uris []string) {
+		if len(dns)+len(ips)+len(emails)+len(uris) == 0 {
+			return
+		}
+
+		b.AddASN1(tag, func(b *cryptobyte.Builder) {
+			addSubtree := func(nameTag cbasn1.Tag, value []byte) {
+				b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+					b.AddASN1(nameTag, func(b *cryptobyte.Builder) {
+						b.AddBytes(value)
+					})
+				})
+			}
+
+			for _, domain := range dns {
+				addSubtree(cbasn1.Tag(2).ContextSpecific(), []byte(domain))
+			}
+			for _, ipNet := range ips {
+				addSubtree(cbasn1.Tag(7).ContextSpecific(), append(ipNet.IP.Mask(ipNet.Mask), ipNet.Mask...))
+			}
+			for _, email := range emails {
+				addSubtree(cbasn1.Tag(1).ContextSpecific(), []byte(email))
+			}
+			for _, uriDomain := range uris {
+				addSubtree(cbasn1.Tag(6).ContextSpecific(), []byte(uriDomain))
+			}
+		})
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(cbasn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		addSubtrees(b, cbasn1.Tag(0).ContextSpecific().Constructed(),
+			params.PermittedDNSDomains, params.PermittedIPRanges, params.PermittedEmailAddresses, params.PermittedURIDomains)
+		addSubtrees(b, cbasn1.Tag(1).ContextSpecific().Constructed(),
+			params.ExcludedDNSDomains, params.ExcludedIPRanges, params.ExcludedEmailAddresses, params.ExcludedURIDomains)
+	})
+
+	value, err := b.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return &pkix.Extension{
+		Id:       ExtensionNameConstraintsOID,
+		Critical: true,
+		Value:    value,
+	}, nil
+}
+
 func HandleOtherCSRSANs(in *x509.CertificateRequest, sans map[string][]string) error {
 	certTemplate := &x509.Certificate{
 		DNSNames:       in.DNSNames,
@@ -880,9 +984,12 @@ func createCertificate(data *CreationBundle, randReader io.Reader, privateKeyGen
 	var err error
 	result := &ParsedCertBundle{}
 
-	serialNumber, err := GenerateSerialNumber()
-	if err != nil {
-		return nil, err
+	serialNumber := data.Params.SerialNumber
+	if serialNumber == nil {
+		serialNumber, err = GenerateSerialNumber()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if err := privateKeyGenerator(data.Params.KeyType,
@@ -925,10 +1032,7 @@ func createCertificate(data *CreationBundle, randReader io.Reader, privateKeyGen
 	}
 
 	// This will only be filled in from the generation paths
-	if len(data.Params.PermittedDNSDomains) > 0 {
-		certTemplate.PermittedDNSDomains = data.Params.PermittedDNSDomains
-		certTemplate.PermittedDNSDomainsCritical = true
-	}
+	AddNameConstraints(data, certTemplate)
 
 	AddPolicyIdentifiers(data, certTemplate)
 
@@ -936,6 +1040,8 @@ func createCertificate(data *CreationBundle, randReader io.Reader, privateKeyGen
 
 	AddExtKeyUsageOids(data, certTemplate)
 
+	AddExtraExtensions(data, certTemplate)
+
 	certTemplate.IssuingCertificateURL = data.Params.URLs.IssuingCertificates
 	certTemplate.CRLDistributionPoints = data.Params.URLs.CRLDistributionPoints
 	certTemplate.OCSPServer = data.Params.URLs.OCSPServers
@@ -1057,6 +1163,7 @@ func selectSignatureAlgorithmForECDSA(pub crypto.PublicKey, signatureBits int) x
 var (
 	ExtensionBasicConstraintsOID = []int{2, 5, 29, 19}
 	ExtensionSubjectAltNameOID   = []int{2, 5, 29, 17}
+	ExtensionNameConstraintsOID  = []int{2, 5, 29, 30}
 )
 
 // CreateCSR creates a CSR with the default rand.Reader to
@@ -1101,6 +1208,22 @@ func createCSR(data *CreationBundle, addBasicConstraints bool, randReader io.Rea
 		return nil, errutil.InternalError{Err: errwrap.Wrapf("error marshaling other SANs: {{err}}", err).Error()}
 	}
 
+	if len(data.Params.PolicyIdentifiers) > 0 {
+		ext, err := CreatePolicyInformationExtensionFromStorageStrings(data.Params.PolicyIdentifiers)
+		if err != nil {
+			return nil, errutil.UserError{Err: fmt.Sprintf("error marshaling policy identifiers: %v", err)}
+		}
+		csrTemplate.ExtraExtensions = append(csrTemplate.ExtraExtensions, *ext)
+	}
+
+	if data.Params.HasNameConstraints() {
+		ext, err := CreateNameConstraintsExtension(data.Params)
+		if err != nil {
+			return nil, errutil.InternalError{Err: fmt.Sprintf("error marshaling name constraints: %v", err)}
+		}
+		csrTemplate.ExtraExtensions = append(csrTemplate.ExtraExtensions, *ext)
+	}
+
 	if addBasicConstraints {
 		type basicConstraints struct {
 			IsCA       bool `asn1:"optional"`
@@ -1172,16 +1295,22 @@ func signCertificate(data *CreationBundle, randReader io.Reader) (*ParsedCertBun
 		return nil, errutil.UserError{Err: "nil csr given to signCertificate"}
 	}
 
-	err := data.CSR.CheckSignature()
-	if err != nil {
-		return nil, errutil.UserError{Err: "request signature invalid"}
+	if !data.SkipCSRSignatureCheck {
+		err := data.CSR.CheckSignature()
+		if err != nil {
+			return nil, errutil.UserError{Err: "request signature invalid"}
+		}
 	}
 
 	result := &ParsedCertBundle{}
 
-	serialNumber, err := GenerateSerialNumber()
-	if err != nil {
-		return nil, err
+	serialNumber := data.Params.SerialNumber
+	if serialNumber == nil {
+		var err error
+		serialNumber, err = GenerateSerialNumber()
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	subjKeyID, err := getSubjectKeyIDFromBundle(data)
@@ -1232,7 +1361,9 @@ func signCertificate(data *CreationBundle, randReader io.Reader) (*ParsedCertBun
 		certTemplate.URIs = data.CSR.URIs
 
 		for _, name := range data.CSR.Extensions {
-			if !name.Id.Equal(ExtensionBasicConstraintsOID) && !(len(data.Params.OtherSANs) > 0 && name.Id.Equal(ExtensionSubjectAltNameOID)) {
+			if !name.Id.Equal(ExtensionBasicConstraintsOID) && !(len(data.Params.OtherSANs) > 0 && name.Id.Equal(ExtensionSubjectAltNameOID)) &&
+				!(len(data.Params.PolicyIdentifiers) > 0 && name.Id.Equal(policyInformationOid)) &&
+				!(data.Params.HasNameConstraints() && name.Id.Equal(ExtensionNameConstraintsOID)) {
 				certTemplate.ExtraExtensions = append(certTemplate.ExtraExtensions, name)
 			}
 		}
@@ -1254,6 +1385,8 @@ func signCertificate(data *CreationBundle, randReader io.Reader) (*ParsedCertBun
 
 	AddExtKeyUsageOids(data, certTemplate)
 
+	AddExtraExtensions(data, certTemplate)
+
 	var certBytes []byte
 
 	certTemplate.IssuingCertificateURL = data.Params.URLs.IssuingCertificates
@@ -1278,10 +1411,7 @@ func signCertificate(data *CreationBundle, randReader io.Reader) (*ParsedCertBun
 		certTemplate.IsCA = false
 	}
 
-	if len(data.Params.PermittedDNSDomains) > 0 {
-		certTemplate.PermittedDNSDomains = data.Params.PermittedDNSDomains
-		certTemplate.PermittedDNSDomainsCritical = true
-	}
+	AddNameConstraints(data, certTemplate)
 
 	certBytes, err = x509.CreateCertificate(randReader, certTemplate, caCert, data.CSR.PublicKey, data.SigningBundle.PrivateKey)
 
@@ -1406,6 +1536,44 @@ func CreateDeltaCRLIndicatorExt(completeCRLNumber int64) (pkix.Extension, error)
 	}, nil
 }
 
+// crlDistributionPoint and crlDistributionPointName mirror the structures
+// used by crypto/x509 for the CRL Distribution Points extension.
+type crlDistributionPoint struct {
+	DistributionPoint crlDistributionPointName `asn1:"optional,tag:0"`
+}
+
+type crlDistributionPointName struct {
+	FullName []asn1.RawValue `asn1:"optional,tag:0"`
+}
+
+// CreateFreshestCRLExt allows complete CRLs to point at the delta CRLs
+// published alongside them.
+func CreateFreshestCRLExt(deltaCRLURLs []string) (pkix.Extension, error) {
+	// > FreshestCRL ::= CRLDistributionPoints
+	var points []crlDistributionPoint
+	for _, uri := range deltaCRLURLs {
+		points = append(points, crlDistributionPoint{
+			DistributionPoint: crlDistributionPointName{
+				FullName: []asn1.RawValue{
+					{Tag: 6, Class: asn1.ClassContextSpecific, Bytes: []byte(uri)},
+				},
+			},
+		})
+	}
+
+	value, err := asn1.Marshal(points)
+	if err != nil {
+		return pkix.Extension{}, fmt.Errorf("unable to marshal delta CRL distribution points (%v): %v", deltaCRLURLs, err)
+	}
+
+	return pkix.Extension{
+		Id: FreshestCRLOID,
+		// > The extension MUST be marked as non-critical by conforming CAs.
+		Critical: false,
+		Value:    value,
+	}, nil
+}
+
 // ParseBasicConstraintExtension parses a basic constraint pkix.Extension, useful if attempting to validate
 // CSRs are requesting CA privileges as Go does not expose its implementation. Values returned are
 // IsCA, MaxPathLen or error. If MaxPathLen was not set, a value of -1 will be returned.
@@ -685,6 +685,10 @@ type URLEntries struct {
 	IssuingCertificates   []string `json:"issuing_certificates" structs:"issuing_certificates" mapstructure:"issuing_certificates"`
 	CRLDistributionPoints []string `json:"crl_distribution_points" structs:"crl_distribution_points" mapstructure:"crl_distribution_points"`
 	OCSPServers           []string `json:"ocsp_servers" structs:"ocsp_servers" mapstructure:"ocsp_servers"`
+
+	// DeltaCRLDistributionPoints are not encoded into issued certificates;
+	// see CreateFreshestCRLExt.
+	DeltaCRLDistributionPoints []string `json:"delta_crl_distribution_points,omitempty" structs:"delta_crl_distribution_points" mapstructure:"delta_crl_distribution_points"`
 }
 
 type NotAfterBehavior int
@@ -714,6 +718,16 @@ type CAInfoBundle struct {
 	URLs                 *URLEntries
 	LeafNotAfterBehavior NotAfterBehavior
 	RevocationSigAlg     x509.SignatureAlgorithm
+
+	// Certificate policies of the issuer, used for certificates which
+	// don't request any of their own.
+	PolicyIdentifiers []string
+
+	// Format of the serial numbers of certificates signed by the issuer:
+	// the number of random bits, below an optional prefix. Zero values
+	// select the default of 159 random bits.
+	SerialNumberBits   int
+	SerialNumberPrefix []byte
 }
 
 func (b *CAInfoBundle) GetCAChain() []*CertBlock {
@@ -790,8 +804,15 @@ type CreationParameters struct {
 	ForceAppendCaChain            bool
 
 	// Only used when signing a CA cert
-	UseCSRValues        bool
-	PermittedDNSDomains []string
+	UseCSRValues            bool
+	PermittedDNSDomains     []string
+	ExcludedDNSDomains      []string
+	PermittedIPRanges       []*net.IPNet
+	ExcludedIPRanges        []*net.IPNet
+	PermittedEmailAddresses []string
+	ExcludedEmailAddresses  []string
+	PermittedURIDomains     []string
+	ExcludedURIDomains      []string
 
 	// URLs to encode into the certificate
 	URLs *URLEntries
@@ -804,12 +825,25 @@ type CreationParameters struct {
 
 	// The explicit SKID to use; especially useful for cross-signing.
 	SKID []byte
+
+	// Additional extensions to encode into the certificate; these replace
+	// any other extension with the same OID.
+	ExtraExtensions []pkix.Extension
+
+	// The explicit serial number to use; a random one is generated when
+	// nil.
+	SerialNumber *big.Int
 }
 
 type CreationBundle struct {
 	Params        *CreationParameters
 	SigningBundle *CAInfoBundle
 	CSR           *x509.CertificateRequest
+
+	// Set when possession of the CSR's private key was proven by other
+	// means, such as a CRMF proof of possession, so that the CSR carries no
+	// valid signature of its own.
+	SkipCSRSignatureCheck bool
 }
 
 // addKeyUsages adds appropriate key usages to the template given the creation
@@ -13,3 +13,7 @@ const AgentPathMetrics = "/agent/v1/metrics"
 
 // AgentPathQuit is the path that the agent will use to trigger stopping it.
 const AgentPathQuit = "/agent/v1/quit"
+
+// AgentPathReload is the path that the agent will use to trigger a reload of
+// its configuration.
+const AgentPathReload = "/agent/v1/reload"
@@ -19,6 +19,7 @@ import (
 	"github.com/hashicorp/go-secure-stdlib/plugincontainer"
 	"github.com/hashicorp/go-secure-stdlib/plugincontainer/config"
 	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/pluginruntimeutil"
 )
 
 type PluginClientConfig struct {
@@ -42,6 +43,9 @@ type runConfig struct {
 	args     []string
 	sha256   []byte
 
+	// runtimeConfig configures the container runtime of container plugins.
+	runtimeConfig *pluginruntimeutil.PluginRuntimeConfig
+
 	// Initialized with what's in PluginRunner.Env, but can be added to
 	env []string
 
@@ -129,29 +133,36 @@ func (rc runConfig) makeConfig(ctx context.Context) (*plugin.ClientConfig, error
 		clientConfig.SkipHostEnv = true
 		clientConfig.RunnerFunc = func(logger hclog.Logger, goPluginCmd *exec.Cmd, tmpDir string) (runner.Runner, error) {
 			overlayCmdSpec(goPluginCmd, cmd)
-			cfg := &config.ContainerConfig{
-				UnixSocketGroup: fmt.Sprintf("%d", os.Getgid()),
-				Image:           rc.image,
-				Tag:             rc.imageTag,
-				SHA256:          fmt.Sprintf("%x", rc.sha256),
-				Labels: map[string]string{
-					"managed-by": "hashicorp.com/vault",
-				},
-				// TODO: More configurables.
-				// Defaulting to runsc will require installing gVisor in the GitHub runner.
-				// Runtime:         "runsc",
-				// CgroupParent: "",
-				// NanoCpus: 100000000,
-				// Memory: 64 * 1024 * 1024,
-				// TODO: network
-
-			}
-			return plugincontainer.NewContainerRunner(logger, goPluginCmd, cfg, tmpDir)
+			return plugincontainer.NewContainerRunner(logger, goPluginCmd, rc.containerConfig(), tmpDir)
 		}
 	}
 	return clientConfig, nil
 }
 
+// containerConfig returns the configuration of the container for a container
+// plugin. The image is pinned to the plugin's SHA256 digest, and the limits of
+// the plugin's runtime, if any, are applied.
+func (rc runConfig) containerConfig() *config.ContainerConfig {
+	cfg := &config.ContainerConfig{
+		UnixSocketGroup: fmt.Sprintf("%d", os.Getgid()),
+		Image:           rc.image,
+		Tag:             rc.imageTag,
+		SHA256:          fmt.Sprintf("%x", rc.sha256),
+		Labels: map[string]string{
+			"managed-by": "hashicorp.com/vault",
+		},
+		// TODO: network
+	}
+	if rc.runtimeConfig != nil {
+		cfg.Runtime = rc.runtimeConfig.OCIRuntime
+		cfg.CgroupParent = rc.runtimeConfig.CgroupParent
+		cfg.NanoCpus = rc.runtimeConfig.CPU
+		cfg.Memory = rc.runtimeConfig.Memory
+	}
+
+	return cfg
+}
+
 func (rc runConfig) run(ctx context.Context) (*plugin.Client, error) {
 	clientConfig, err := rc.makeConfig(ctx)
 	if err != nil {
@@ -219,12 +230,13 @@ func (r *PluginRunner) RunConfig(ctx context.Context, opts ...RunOpt) (*plugin.C
 		imageTag = strings.TrimPrefix(r.Version, "v")
 	}
 	rc := runConfig{
-		command:  r.Command,
-		image:    image,
-		imageTag: imageTag,
-		args:     r.Args,
-		sha256:   r.Sha256,
-		env:      r.Env,
+		command:       r.Command,
+		image:         image,
+		imageTag:      imageTag,
+		args:          r.Args,
+		sha256:        r.Sha256,
+		env:           r.Env,
+		runtimeConfig: r.RuntimeConfig,
 	}
 
 	for _, opt := range opts {
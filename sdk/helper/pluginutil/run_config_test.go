@@ -12,6 +12,9 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/go-secure-stdlib/plugincontainer/config"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/pluginruntimeutil"
 	"github.com/hashicorp/vault/sdk/helper/wrapping"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -328,6 +331,57 @@ func TestMakeConfig(t *testing.T) {
 	}
 }
 
+func TestContainerConfig(t *testing.T) {
+	sha256 := []byte{0x01, 0x23, 0xab}
+	for name, tc := range map[string]struct {
+		rc       runConfig
+		expected config.ContainerConfig
+	}{
+		"no runtime": {
+			rc: runConfig{
+				image:    "hashicorp/vault-plugin-auth-jwt",
+				imageTag: "0.16.0",
+				sha256:   sha256,
+			},
+			expected: config.ContainerConfig{
+				Image:  "hashicorp/vault-plugin-auth-jwt",
+				Tag:    "0.16.0",
+				SHA256: "0123ab",
+			},
+		},
+		"runtime": {
+			rc: runConfig{
+				image:  "hashicorp/vault-plugin-auth-jwt",
+				sha256: sha256,
+				runtimeConfig: &pluginruntimeutil.PluginRuntimeConfig{
+					Name:         "gvisor",
+					Type:         consts.PluginRuntimeTypeContainer,
+					OCIRuntime:   "runsc",
+					CgroupParent: "vault-plugins",
+					CPU:          100000000,
+					Memory:       64 * 1024 * 1024,
+				},
+			},
+			expected: config.ContainerConfig{
+				Image:        "hashicorp/vault-plugin-auth-jwt",
+				SHA256:       "0123ab",
+				Runtime:      "runsc",
+				CgroupParent: "vault-plugins",
+				NanoCpus:     100000000,
+				Memory:       64 * 1024 * 1024,
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual := tc.rc.containerConfig()
+			require.Equal(t, "hashicorp.com/vault", actual.Labels["managed-by"])
+			actual.Labels = nil
+			actual.UnixSocketGroup = ""
+			require.Equal(t, tc.expected, *actual)
+		})
+	}
+}
+
 func commandWithEnv(cmd string, args []string, env []string) *exec.Cmd {
 	c := exec.Command(cmd, args...)
 	c.Env = env
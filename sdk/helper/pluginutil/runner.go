@@ -12,6 +12,7 @@ import (
 	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/pluginruntimeutil"
 	"github.com/hashicorp/vault/sdk/helper/wrapping"
 	"google.golang.org/grpc"
 )
@@ -56,11 +57,23 @@ type PluginRunner struct {
 	Version        string                      `json:"version" structs:"version"`
 	Command        string                      `json:"command" structs:"command"`
 	OCIImage       string                      `json:"oci_image" structs:"oci_image"`
+	Runtime        string                      `json:"runtime" structs:"runtime"`
 	Args           []string                    `json:"args" structs:"args"`
 	Env            []string                    `json:"env" structs:"env"`
 	Sha256         []byte                      `json:"sha256" structs:"sha256"`
 	Builtin        bool                        `json:"builtin" structs:"builtin"`
 	BuiltinFactory func() (interface{}, error) `json:"-" structs:"-"`
+
+	// ArtifactURL and SignatureURL locate the signed binary of a plugin
+	// installed through sys/plugins/install, so that nodes which don't have
+	// it in their plugin directory can fetch it.
+	ArtifactURL  string `json:"artifact_url,omitempty" structs:"artifact_url"`
+	SignatureURL string `json:"signature_url,omitempty" structs:"signature_url"`
+
+	// RuntimeConfig is the configuration of the plugin runtime named by
+	// Runtime. It is resolved from the plugin runtime catalog when the plugin
+	// is retrieved, and is not persisted with the plugin.
+	RuntimeConfig *pluginruntimeutil.PluginRuntimeConfig `json:"-" structs:"-"`
 }
 
 // BinaryReference returns either the OCI image reference if it's a container
@@ -90,9 +103,13 @@ type SetPluginInput struct {
 	Version  string
 	Command  string
 	OCIImage string
+	Runtime  string
 	Args     []string
 	Env      []string
 	Sha256   []byte
+
+	ArtifactURL  string
+	SignatureURL string
 }
 
 // Run takes a wrapper RunnerUtil instance along with the go-plugin parameters and
@@ -0,0 +1,85 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package rotation
+
+import (
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+)
+
+// AddRotationFields adds the rotation_period, rotation_schedule and
+// rotation_window fields to the schema of a path.
+func AddRotationFields(fields map[string]*framework.FieldSchema) {
+	fields["rotation_period"] = &framework.FieldSchema{
+		Type: framework.TypeDurationSecond,
+		Description: `Period for automatic credential rotation. Mutually exclusive
+with "rotation_schedule".`,
+		ConflictsWith: []string{"rotation_schedule"},
+	}
+	fields["rotation_schedule"] = &framework.FieldSchema{
+		Type: framework.TypeString,
+		Description: `Cron-style schedule for automatic credential rotation.
+Mutually exclusive with "rotation_period".`,
+	}
+	fields["rotation_window"] = &framework.FieldSchema{
+		Type: framework.TypeDurationSecond,
+		Description: `The window of time in which rotations are allowed to occur
+starting from a given "rotation_schedule". Requires "rotation_schedule".`,
+	}
+}
+
+// ParseRotationFields returns the schedule given by the fields added with
+// AddRotationFields. Fields that are not set in the request keep their value
+// from existing, which may be nil. It returns nil if no schedule is
+// configured at all.
+func ParseRotationFields(d *framework.FieldData, existing *Schedule) (*Schedule, error) {
+	s := &Schedule{}
+	if existing != nil {
+		*s = *existing
+	}
+
+	if raw, ok := d.GetOk("rotation_period"); ok {
+		s.RotationPeriod = time.Duration(raw.(int)) * time.Second
+		if s.RotationPeriod != 0 {
+			s.RotationSchedule, s.RotationWindow = "", 0
+		}
+	}
+	if raw, ok := d.GetOk("rotation_schedule"); ok {
+		s.RotationSchedule = raw.(string)
+		if s.RotationSchedule != "" {
+			s.RotationPeriod = 0
+		} else {
+			s.RotationWindow = 0
+		}
+	}
+	if raw, ok := d.GetOk("rotation_window"); ok {
+		s.RotationWindow = time.Duration(raw.(int)) * time.Second
+	}
+
+	if s.RotationPeriod == 0 && s.RotationSchedule == "" {
+		return nil, nil
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// PopulateRotationData adds the schedule's fields to the response data of a
+// read operation.
+func (s *Schedule) PopulateRotationData(data map[string]interface{}) {
+	if s == nil {
+		return
+	}
+	if s.UsesRotationPeriod() {
+		data["rotation_period"] = int64(s.RotationPeriod.Seconds())
+	}
+	if s.UsesRotationSchedule() {
+		data["rotation_schedule"] = s.RotationSchedule
+		if s.RotationWindow != 0 {
+			data["rotation_window"] = int64(s.RotationWindow.Seconds())
+		}
+	}
+}
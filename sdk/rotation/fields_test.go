@@ -0,0 +1,60 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package rotation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+)
+
+func TestParseRotationFields(t *testing.T) {
+	fields := map[string]*framework.FieldSchema{}
+	AddRotationFields(fields)
+
+	parse := func(raw map[string]interface{}, existing *Schedule) (*Schedule, error) {
+		d := &framework.FieldData{Raw: raw, Schema: fields}
+		if err := d.Validate(); err != nil {
+			return nil, err
+		}
+		return ParseRotationFields(d, existing)
+	}
+
+	s, err := parse(map[string]interface{}{}, nil)
+	if err != nil || s != nil {
+		t.Fatalf("expected no schedule, got %#v, %v", s, err)
+	}
+
+	s, err = parse(map[string]interface{}{"rotation_schedule": "0 0 * * *", "rotation_window": "2h"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.RotationSchedule != "0 0 * * *" || s.RotationWindow != 2*time.Hour {
+		t.Fatalf("unexpected schedule %#v", s)
+	}
+
+	// Switching to a period clears the schedule and its window.
+	s, err = parse(map[string]interface{}{"rotation_period": 3600}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.RotationPeriod != time.Hour || s.RotationSchedule != "" || s.RotationWindow != 0 {
+		t.Fatalf("unexpected schedule %#v", s)
+	}
+
+	data := map[string]interface{}{}
+	s.PopulateRotationData(data)
+	if !reflect.DeepEqual(data, map[string]interface{}{"rotation_period": int64(3600)}) {
+		t.Fatalf("unexpected data %#v", data)
+	}
+
+	if _, err := parse(map[string]interface{}{"rotation_period": 3600, "rotation_schedule": "0 0 * * *"}, nil); err == nil {
+		t.Fatal("expected error setting both rotation_period and rotation_schedule")
+	}
+	if _, err := parse(map[string]interface{}{"rotation_window": 7200}, s); err == nil {
+		t.Fatal("expected error setting rotation_window with rotation_period")
+	}
+}
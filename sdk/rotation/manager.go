@@ -0,0 +1,266 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package rotation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMinBackoff is the delay before retrying a rotation after its
+	// first failure.
+	DefaultMinBackoff = 10 * time.Second
+
+	// DefaultMaxBackoff caps the delay between retries of a failing rotation.
+	DefaultMaxBackoff = time.Hour
+)
+
+// ErrJobNotFound is returned when a rotation job is not registered.
+var ErrJobNotFound = errors.New("rotation job not found")
+
+// RotateFunc rotates the credential of a job.
+type RotateFunc func(ctx context.Context) error
+
+// Job is a credential that is rotated automatically.
+type Job struct {
+	// Name uniquely identifies the job within its Manager.
+	Name string
+
+	// Schedule determines when the credential is rotated.
+	Schedule *Schedule
+
+	// Rotate performs the rotation.
+	Rotate RotateFunc
+
+	// LastRotation is the time of the last successful rotation before the
+	// job was registered, if known. Period based jobs are next due
+	// RotationPeriod after it, or immediately if it is unset.
+	LastRotation time.Time
+}
+
+// Status reports the state of a rotation job.
+type Status struct {
+	LastRotation        time.Time
+	NextRotation        time.Time
+	LastError           string
+	LastErrorTime       time.Time
+	ConsecutiveFailures int
+}
+
+// Backoff computes the delay before retrying a failed rotation. The delay
+// starts at Min and doubles with every consecutive failure, up to Max.
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// Delay returns the delay after the given number of consecutive failures.
+func (b Backoff) Delay(failures int) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+	delay := b.Min
+	for i := 1; i < failures && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+type managedJob struct {
+	job     Job
+	status  Status
+	running bool
+
+	// scheduled is the time the current rotation window opened, which
+	// differs from status.NextRotation while retrying after a failure.
+	scheduled time.Time
+}
+
+// Manager runs registered rotation jobs when they are due. It is safe for
+// concurrent use.
+type Manager struct {
+	Backoff Backoff
+
+	lock sync.Mutex
+	jobs map[string]*managedJob
+	now  func() time.Time
+}
+
+// NewManager returns a Manager using the default backoff.
+func NewManager() *Manager {
+	return &Manager{
+		Backoff: Backoff{Min: DefaultMinBackoff, Max: DefaultMaxBackoff},
+		jobs:    make(map[string]*managedJob),
+		now:     time.Now,
+	}
+}
+
+// Register adds a job, replacing any job with the same name.
+func (m *Manager) Register(job Job) error {
+	if job.Name == "" {
+		return errors.New("rotation job name is required")
+	}
+	if job.Schedule == nil {
+		return fmt.Errorf("rotation job %q has no schedule", job.Name)
+	}
+	if err := job.Schedule.Validate(); err != nil {
+		return fmt.Errorf("rotation job %q: %w", job.Name, err)
+	}
+	if job.Rotate == nil {
+		return fmt.Errorf("rotation job %q has no rotate function", job.Name)
+	}
+
+	now := m.now()
+	next := job.Schedule.Next(now)
+	if job.Schedule.UsesRotationPeriod() {
+		next = job.Schedule.Next(job.LastRotation)
+		if job.LastRotation.IsZero() {
+			next = now
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.jobs[job.Name] = &managedJob{
+		job: job,
+		status: Status{
+			LastRotation: job.LastRotation,
+			NextRotation: next,
+		},
+		scheduled: next,
+	}
+	return nil
+}
+
+// Deregister removes a job. A rotation of the job that is in progress is not
+// interrupted.
+func (m *Manager) Deregister(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.jobs, name)
+}
+
+// Status returns the status of the named job.
+func (m *Manager) Status(name string) (Status, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	j, ok := m.jobs[name]
+	if !ok {
+		return Status{}, false
+	}
+	return j.status, true
+}
+
+// Jobs returns the names of the registered jobs, sorted.
+func (m *Manager) Jobs() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	names := make([]string, 0, len(m.jobs))
+	for name := range m.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RotateDue rotates every job that is due, in order of their next rotation
+// time. Scheduled jobs whose rotation window has closed are not rotated and
+// instead move on to their next scheduled time. It returns the names of the
+// jobs whose rotation failed.
+func (m *Manager) RotateDue(ctx context.Context) []string {
+	now := m.now()
+
+	m.lock.Lock()
+	var due []*managedJob
+	for _, j := range m.jobs {
+		if j.running || j.status.NextRotation.After(now) {
+			continue
+		}
+		if !j.job.Schedule.InsideWindow(j.scheduled, now) {
+			j.scheduled = j.job.Schedule.Next(now)
+			j.status.NextRotation = j.scheduled
+			continue
+		}
+		j.running = true
+		due = append(due, j)
+	}
+	sort.Slice(due, func(i, k int) bool {
+		return due[i].status.NextRotation.Before(due[k].status.NextRotation)
+	})
+	m.lock.Unlock()
+
+	var failed []string
+	for _, j := range due {
+		if ctx.Err() != nil {
+			m.finish(j, nil, true)
+			continue
+		}
+		if err := m.run(ctx, j); err != nil {
+			failed = append(failed, j.job.Name)
+		}
+	}
+	return failed
+}
+
+// Rotate immediately rotates the named job, regardless of its schedule.
+func (m *Manager) Rotate(ctx context.Context, name string) error {
+	m.lock.Lock()
+	j, ok := m.jobs[name]
+	if !ok {
+		m.lock.Unlock()
+		return ErrJobNotFound
+	}
+	if j.running {
+		m.lock.Unlock()
+		return fmt.Errorf("rotation job %q is already running", name)
+	}
+	j.running = true
+	m.lock.Unlock()
+
+	return m.run(ctx, j)
+}
+
+func (m *Manager) run(ctx context.Context, j *managedJob) error {
+	err := j.job.Rotate(ctx)
+	m.finish(j, err, false)
+	return err
+}
+
+// finish records the result of a rotation and schedules the next one. If
+// skipped is set, the rotation was not attempted and the job stays due.
+func (m *Manager) finish(j *managedJob, err error, skipped bool) {
+	now := m.now()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	j.running = false
+	switch {
+	case skipped:
+	case err != nil:
+		j.status.ConsecutiveFailures++
+		j.status.LastError = err.Error()
+		j.status.LastErrorTime = now
+		j.status.NextRotation = now.Add(m.Backoff.Delay(j.status.ConsecutiveFailures))
+	default:
+		j.status.ConsecutiveFailures = 0
+		j.status.LastError = ""
+		j.status.LastErrorTime = time.Time{}
+		j.status.LastRotation = now
+		j.scheduled = j.job.Schedule.Next(now)
+		j.status.NextRotation = j.scheduled
+	}
+}
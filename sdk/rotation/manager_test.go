@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package rotation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := Backoff{Min: 10 * time.Second, Max: time.Minute}
+
+	expected := []time.Duration{0, 10 * time.Second, 20 * time.Second, 40 * time.Second, time.Minute, time.Minute}
+	for failures, want := range expected {
+		if got := b.Delay(failures); got != want {
+			t.Fatalf("after %d failures: expected %s, got %s", failures, want, got)
+		}
+	}
+}
+
+func TestManager_RotateDue(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := NewManager()
+	m.now = func() time.Time { return now }
+
+	var rotations []string
+	var fail bool
+	rotate := func(name string) RotateFunc {
+		return func(context.Context) error {
+			rotations = append(rotations, name)
+			if fail {
+				return errors.New("boom")
+			}
+			return nil
+		}
+	}
+
+	period, _ := NewSchedule(time.Hour, "", 0)
+	if err := m.Register(Job{Name: "new", Schedule: period, Rotate: rotate("new")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Register(Job{Name: "recent", Schedule: period, Rotate: rotate("recent"), LastRotation: now.Add(-30 * time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the job that was never rotated is due.
+	if failed := m.RotateDue(context.Background()); len(failed) != 0 {
+		t.Fatalf("unexpected failures %v", failed)
+	}
+	if !reflect.DeepEqual(rotations, []string{"new"}) {
+		t.Fatalf("unexpected rotations %v", rotations)
+	}
+	status, ok := m.Status("new")
+	if !ok || !status.LastRotation.Equal(now) || !status.NextRotation.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected status %#v", status)
+	}
+
+	// Failures are retried with backoff.
+	now = now.Add(time.Hour)
+	rotations, fail = nil, true
+	if failed := m.RotateDue(context.Background()); !reflect.DeepEqual(failed, []string{"recent", "new"}) {
+		t.Fatalf("unexpected failures %v", failed)
+	}
+	status, _ = m.Status("new")
+	if status.ConsecutiveFailures != 1 || status.LastError != "boom" || !status.NextRotation.Equal(now.Add(DefaultMinBackoff)) {
+		t.Fatalf("unexpected status %#v", status)
+	}
+
+	rotations = nil
+	m.RotateDue(context.Background())
+	if len(rotations) != 0 {
+		t.Fatalf("expected no rotations during backoff, got %v", rotations)
+	}
+
+	now = now.Add(DefaultMinBackoff)
+	m.RotateDue(context.Background())
+	status, _ = m.Status("new")
+	if status.ConsecutiveFailures != 2 || !status.NextRotation.Equal(now.Add(2*DefaultMinBackoff)) {
+		t.Fatalf("unexpected status %#v", status)
+	}
+
+	// A success resets the failures.
+	now = now.Add(2 * DefaultMinBackoff)
+	fail = false
+	if failed := m.RotateDue(context.Background()); len(failed) != 0 {
+		t.Fatalf("unexpected failures %v", failed)
+	}
+	status, _ = m.Status("new")
+	if status.ConsecutiveFailures != 0 || status.LastError != "" || !status.NextRotation.Equal(now.Add(time.Hour)) {
+		t.Fatalf("unexpected status %#v", status)
+	}
+
+	m.Deregister("new")
+	if _, ok := m.Status("new"); ok {
+		t.Fatal("expected job to be removed")
+	}
+	if !reflect.DeepEqual(m.Jobs(), []string{"recent"}) {
+		t.Fatalf("unexpected jobs %v", m.Jobs())
+	}
+}
+
+func TestManager_RotateDue_Window(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	m := NewManager()
+	m.now = func() time.Time { return now }
+
+	rotated := 0
+	daily, _ := NewSchedule(0, "0 0 * * *", 2*time.Hour)
+	err := m.Register(Job{
+		Name:     "daily",
+		Schedule: daily,
+		Rotate: func(context.Context) error {
+			rotated++
+			return errors.New("boom")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	midnight := time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC)
+	if status, _ := m.Status("daily"); !status.NextRotation.Equal(midnight) {
+		t.Fatalf("unexpected next rotation %s", status.NextRotation)
+	}
+
+	// Failures are retried within the window.
+	now = midnight.Add(time.Hour)
+	m.RotateDue(context.Background())
+	now = now.Add(DefaultMinBackoff)
+	m.RotateDue(context.Background())
+	if rotated != 2 {
+		t.Fatalf("expected 2 attempts, got %d", rotated)
+	}
+
+	// Once the window closes, the job waits for the next scheduled time.
+	now = midnight.Add(3 * time.Hour)
+	m.RotateDue(context.Background())
+	if rotated != 2 {
+		t.Fatalf("expected no attempt outside the window, got %d", rotated)
+	}
+	status, _ := m.Status("daily")
+	if !status.NextRotation.Equal(midnight.Add(24*time.Hour)) || status.ConsecutiveFailures != 2 {
+		t.Fatalf("unexpected status %#v", status)
+	}
+}
+
+func TestManager_Rotate(t *testing.T) {
+	m := NewManager()
+
+	if err := m.Rotate(context.Background(), "missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("expected ErrJobNotFound, got %v", err)
+	}
+
+	rotated := false
+	period, _ := NewSchedule(time.Hour, "", 0)
+	if err := m.Register(Job{Name: "job", Schedule: period, Rotate: func(context.Context) error {
+		rotated = true
+		return nil
+	}, LastRotation: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Rotate(context.Background(), "job"); err != nil {
+		t.Fatal(err)
+	}
+	if !rotated {
+		t.Fatal("expected job to be rotated")
+	}
+}
+
+func TestManager_Register_Invalid(t *testing.T) {
+	m := NewManager()
+	noop := func(context.Context) error { return nil }
+
+	for name, job := range map[string]Job{
+		"no name":      {Schedule: &Schedule{RotationPeriod: time.Hour}, Rotate: noop},
+		"no schedule":  {Name: "job", Rotate: noop},
+		"bad schedule": {Name: "job", Schedule: &Schedule{}, Rotate: noop},
+		"no rotate":    {Name: "job", Schedule: &Schedule{RotationPeriod: time.Hour}},
+	} {
+		if err := m.Register(job); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
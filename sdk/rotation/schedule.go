@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package rotation provides Vault plugins with the shared pieces of automated
+// credential rotation: parsing of rotation schedules and periods, rotation
+// windows, and a Manager that runs registered rotation jobs when they are due,
+// tracking their status and backing off after failures. Plugins provide the
+// logic to rotate a credential and call Manager.RotateDue from their periodic
+// function.
+package rotation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	// MinRotationWindow is the shortest allowed rotation window.
+	MinRotationWindow = time.Hour
+
+	parseOptions = cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow
+)
+
+// ParseSchedule parses a standard 5-field cron-style rotation schedule.
+func ParseSchedule(rotationSchedule string) (*cron.SpecSchedule, error) {
+	parser := cron.NewParser(parseOptions)
+	schedule, err := parser.Parse(rotationSchedule)
+	if err != nil {
+		return nil, err
+	}
+	sched, ok := schedule.(*cron.SpecSchedule)
+	if !ok {
+		return nil, fmt.Errorf("invalid rotation schedule")
+	}
+	return sched, nil
+}
+
+// ValidateRotationWindow checks that the rotation window is at least
+// MinRotationWindow.
+func ValidateRotationWindow(window time.Duration) error {
+	if window < MinRotationWindow {
+		return fmt.Errorf("rotation_window must be %d seconds or more", int(MinRotationWindow.Seconds()))
+	}
+	return nil
+}
+
+// Schedule describes when a credential is rotated: either every
+// RotationPeriod, or at the times given by the cron-style RotationSchedule,
+// optionally only within RotationWindow of each scheduled time.
+type Schedule struct {
+	RotationPeriod   time.Duration `json:"rotation_period,omitempty"`
+	RotationSchedule string        `json:"rotation_schedule,omitempty"`
+	RotationWindow   time.Duration `json:"rotation_window,omitempty"`
+
+	spec *cron.SpecSchedule
+}
+
+// NewSchedule validates and returns a rotation schedule. Exactly one of
+// period and schedule must be given, and a window may only be given with a
+// schedule.
+func NewSchedule(period time.Duration, schedule string, window time.Duration) (*Schedule, error) {
+	s := &Schedule{
+		RotationPeriod:   period,
+		RotationSchedule: schedule,
+		RotationWindow:   window,
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks the schedule, parsing RotationSchedule if set.
+func (s *Schedule) Validate() error {
+	switch {
+	case s.RotationPeriod != 0 && s.RotationSchedule != "":
+		return errors.New("mutually exclusive fields rotation_period and rotation_schedule were both specified; only one of them can be provided")
+	case s.RotationPeriod == 0 && s.RotationSchedule == "":
+		return errors.New("one of rotation_schedule or rotation_period must be provided")
+	case s.RotationPeriod < 0:
+		return errors.New("rotation_period must be positive")
+	case s.RotationPeriod != 0 && s.RotationWindow != 0:
+		return errors.New("rotation_window is invalid with use of rotation_period")
+	}
+
+	if s.RotationSchedule != "" {
+		spec, err := ParseSchedule(s.RotationSchedule)
+		if err != nil {
+			return fmt.Errorf("could not parse rotation_schedule: %w", err)
+		}
+		s.spec = spec
+	}
+	if s.RotationWindow != 0 {
+		if err := ValidateRotationWindow(s.RotationWindow); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UsesRotationPeriod returns whether the credential is rotated on a period.
+func (s *Schedule) UsesRotationPeriod() bool {
+	return s.RotationPeriod != 0 && s.RotationSchedule == ""
+}
+
+// UsesRotationSchedule returns whether the credential is rotated on a
+// cron-style schedule.
+func (s *Schedule) UsesRotationSchedule() bool {
+	return s.RotationSchedule != "" && s.RotationPeriod == 0
+}
+
+// Next returns the first rotation time after t. For a period based schedule,
+// t should be the time of the last rotation.
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.UsesRotationPeriod() {
+		return t.Add(s.RotationPeriod)
+	}
+	if s.spec == nil {
+		spec, err := ParseSchedule(s.RotationSchedule)
+		if err != nil {
+			// Schedules are validated before use, so this is unreachable
+			// unless a stored schedule was modified.
+			return time.Time{}
+		}
+		s.spec = spec
+	}
+	return s.spec.Next(t)
+}
+
+// InsideWindow returns whether t is within the rotation window that opens at
+// the scheduled rotation time. Without a window, rotation is allowed at any
+// time after the scheduled time.
+func (s *Schedule) InsideWindow(scheduled, t time.Time) bool {
+	if s.UsesRotationSchedule() && s.RotationWindow != 0 {
+		return t.Before(scheduled.Add(s.RotationWindow))
+	}
+	return true
+}
@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package rotation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSchedule(t *testing.T) {
+	cases := map[string]struct {
+		period   time.Duration
+		schedule string
+		window   time.Duration
+		wantErr  bool
+	}{
+		"period":                   {period: time.Hour},
+		"schedule":                 {schedule: "0 0 * * SAT"},
+		"schedule with window":     {schedule: "0 0 * * SAT", window: 2 * time.Hour},
+		"neither":                  {wantErr: true},
+		"both":                     {period: time.Hour, schedule: "0 0 * * SAT", wantErr: true},
+		"negative period":          {period: -time.Hour, wantErr: true},
+		"period with window":       {period: time.Hour, window: 2 * time.Hour, wantErr: true},
+		"invalid schedule":         {schedule: "not a schedule", wantErr: true},
+		"schedule with seconds":    {schedule: "0 0 0 * * SAT", wantErr: true},
+		"window below the minimum": {schedule: "0 0 * * SAT", window: time.Minute, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewSchedule(tc.period, tc.schedule, tc.window)
+			if tc.wantErr != (err != nil) {
+				t.Fatalf("expected error: %t, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	last := time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	period, err := NewSchedule(time.Hour, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next := period.Next(last); !next.Equal(last.Add(time.Hour)) {
+		t.Fatalf("unexpected next rotation %s", next)
+	}
+
+	// A schedule loaded from storage has not been parsed yet.
+	daily := &Schedule{RotationSchedule: "0 0 * * *"}
+	if next := daily.Next(last); !next.Equal(time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next rotation %s", next)
+	}
+}
+
+func TestSchedule_InsideWindow(t *testing.T) {
+	scheduled := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	s, err := NewSchedule(0, "0 0 * * *", 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.InsideWindow(scheduled, scheduled.Add(time.Hour)) {
+		t.Fatal("expected to be inside the window")
+	}
+	if s.InsideWindow(scheduled, scheduled.Add(3*time.Hour)) {
+		t.Fatal("expected to be outside the window")
+	}
+
+	noWindow, err := NewSchedule(0, "0 0 * * *", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !noWindow.InsideWindow(scheduled, scheduled.Add(12*time.Hour)) {
+		t.Fatal("expected schedule without window to always be inside the window")
+	}
+}
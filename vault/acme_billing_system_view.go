@@ -41,13 +41,14 @@ type acmeBillingImpl struct {
 
 var _ logical.ACMEBillingSystemView = (*acmeBillingImpl)(nil)
 
-// Due to unfortunate layering of system view interfaces, there are three
+// Due to unfortunate layering of system view interfaces, there are four
 // possible sets of interfaces we need to layer with this ACME interface:
 //
 // 1. Everything: a managed key system view, an entropy sourcer, and an
 //    extended system view.
 // 2. Managed keys without an entropy sourcer.
 // 3. Just extended system view.
+// 4. An entropy sourcer without managed keys.
 //
 // Unfortunately, just using acmeBillingSystemViewImpl is not sufficient:
 // because of the embedded interfaces, even when these are nil, the
@@ -100,11 +101,36 @@ var (
 	_ extendedSystemView            = (*acmeBillingSystemViewImplNoManagedKeys)(nil)
 )
 
+// Scenario 4 above.
+type acmeBillingSystemViewImplNoManagedKeysSourcer struct {
+	extendedSystemView
+	entropy.Sourcer
+	acmeBillingImpl
+}
+
+var (
+	_ logical.ACMEBillingSystemView = (*acmeBillingSystemViewImplNoManagedKeysSourcer)(nil)
+	_ extendedSystemView            = (*acmeBillingSystemViewImplNoManagedKeysSourcer)(nil)
+	_ entropy.Sourcer               = (*acmeBillingSystemViewImplNoManagedKeysSourcer)(nil)
+)
+
 // NewAcmeBillingSystemView creates the appropriate implementation based on
 // the passed arguments, mapping them to the above scenarios. We further
 // restrict sysView to have a dynamicSystemView implementation, to get the
 // mount entry out of.
 func (c *Core) NewAcmeBillingSystemView(sysView interface{}) extendedSystemView {
+	if ees, ok := sysView.(entropySystemViewImpl); ok {
+		// Scenario 4.
+		return &acmeBillingSystemViewImplNoManagedKeysSourcer{
+			extendedSystemView: ees,
+			Sourcer:            ees,
+			acmeBillingImpl: acmeBillingImpl{
+				core:  c,
+				entry: ees.mountEntry,
+			},
+		}
+	}
+
 	es := sysView.(extendedSystemViewImpl)
 	des := es.dynamicSystemView
 
@@ -33,6 +33,9 @@ type AuditBroker struct {
 	logger   log.Logger
 
 	broker *eventlogger.Broker
+
+	// tails holds the live audit tail sessions, see addTailer.
+	tails auditTailers
 }
 
 // NewAuditBroker creates a new audit broker
@@ -223,6 +226,8 @@ func (a *AuditBroker) LogRequest(ctx context.Context, in *logical.LogInput, head
 		}
 	}
 
+	a.sendToTailers(ctx, in, false)
+
 	return retErr.ErrorOrNil()
 }
 
@@ -304,6 +309,8 @@ func (a *AuditBroker) LogResponse(ctx context.Context, in *logical.LogInput, hea
 		}
 	}
 
+	a.sendToTailers(ctx, in, true)
+
 	return retErr.ErrorOrNil()
 }
 
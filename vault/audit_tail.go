@@ -0,0 +1,316 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-secure-stdlib/strutil"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/vault/audit"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// auditTailBufferSize is the number of formatted entries that may be
+	// queued for a single tail session before entries start being dropped.
+	auditTailBufferSize = 512
+
+	auditTailOpEqual    = "=="
+	auditTailOpNotEqual = "!="
+	auditTailOpMatches  = "matches"
+)
+
+// auditTailFields are the entry fields which may be referenced in an audit
+// tail filter expression.
+var auditTailFields = []string{
+	"type",
+	"path",
+	"operation",
+	"mount_point",
+	"mount_type",
+	"namespace",
+	"remote_address",
+	"error",
+}
+
+// auditTailFilter is a single "<field> <operator> <value>" expression used to
+// select which audit entries are sent to a tail session.
+type auditTailFilter struct {
+	field string
+	op    string
+	value string
+}
+
+// parseAuditTailFilter parses a filter expression of the form
+// "<field> <operator> <value>", where operator is one of "==", "!=" or
+// "matches". The value may optionally be wrapped in double quotes; with the
+// "matches" operator it may contain "*" wildcards.
+func parseAuditTailFilter(expr string) (*auditTailFilter, error) {
+	parts := strings.Fields(strings.TrimSpace(expr))
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid filter %q: expected \"<field> <operator> <value>\"", expr)
+	}
+
+	field := strings.ToLower(parts[0])
+	if !strutil.StrListContains(auditTailFields, field) {
+		return nil, fmt.Errorf("invalid filter %q: unknown field %q, valid fields are: %s", expr, parts[0], strings.Join(auditTailFields, ", "))
+	}
+
+	op := strings.ToLower(parts[1])
+	switch op {
+	case auditTailOpEqual, auditTailOpNotEqual, auditTailOpMatches:
+	default:
+		return nil, fmt.Errorf("invalid filter %q: unknown operator %q", expr, parts[1])
+	}
+
+	value := strings.Join(parts[2:], " ")
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		value = value[1 : len(value)-1]
+	}
+
+	return &auditTailFilter{
+		field: field,
+		op:    op,
+		value: value,
+	}, nil
+}
+
+// matches returns true if the given entry fields satisfy the filter.
+func (f *auditTailFilter) matches(fields map[string]string) bool {
+	actual := fields[f.field]
+	switch f.op {
+	case auditTailOpEqual:
+		return actual == f.value
+	case auditTailOpNotEqual:
+		return actual != f.value
+	case auditTailOpMatches:
+		return strutil.GlobbedStringsMatch(f.value, actual)
+	}
+	return false
+}
+
+// auditTailer is a single live audit tail session. Entries that pass all of
+// its filters are formatted as JSON and queued on ch.
+type auditTailer struct {
+	filters []*auditTailFilter
+	device  string
+	ch      chan []byte
+
+	l       sync.Mutex
+	dropped int
+}
+
+// offer queues the entry for the tailer, dropping it if the tailer is not
+// keeping up.
+func (t *auditTailer) offer(entry []byte) {
+	select {
+	case t.ch <- entry:
+	default:
+		t.l.Lock()
+		t.dropped++
+		t.l.Unlock()
+	}
+}
+
+// takeDropped returns and resets the number of entries dropped since the last
+// call because the session was not consuming them fast enough.
+func (t *auditTailer) takeDropped() int {
+	t.l.Lock()
+	defer t.l.Unlock()
+	d := t.dropped
+	t.dropped = 0
+	return d
+}
+
+// auditTailers tracks the live audit tail sessions of an AuditBroker.
+type auditTailers struct {
+	l       sync.RWMutex
+	tailers map[string]*auditTailer
+}
+
+// addTailer registers a new live audit tail session. The filters are parsed
+// as described by parseAuditTailFilter and must all match for an entry to be
+// delivered. Entries are hashed using the salt of the named audit device, or
+// of the first enabled device (sorted by path) when device is empty. The
+// returned ID must be passed to removeTailer once the session ends.
+func (a *AuditBroker) addTailer(filters []string, device string) (string, *auditTailer, error) {
+	parsed := make([]*auditTailFilter, 0, len(filters))
+	for _, expr := range filters {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		f, err := parseAuditTailFilter(expr)
+		if err != nil {
+			return "", nil, err
+		}
+		parsed = append(parsed, f)
+	}
+
+	a.RLock()
+	numBackends := len(a.backends)
+	_, deviceOk := a.backends[device]
+	a.RUnlock()
+
+	switch {
+	case numBackends == 0:
+		return "", nil, fmt.Errorf("no audit devices are enabled; at least one audit device is required to tail audit entries")
+	case device != "" && !deviceOk:
+		return "", nil, fmt.Errorf("unknown audit device %q", device)
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	t := &auditTailer{
+		filters: parsed,
+		device:  device,
+		ch:      make(chan []byte, auditTailBufferSize),
+	}
+
+	a.tails.l.Lock()
+	defer a.tails.l.Unlock()
+	if a.tails.tailers == nil {
+		a.tails.tailers = make(map[string]*auditTailer)
+	}
+	a.tails.tailers[id] = t
+
+	return id, t, nil
+}
+
+// removeTailer ends the live audit tail session with the given ID.
+func (a *AuditBroker) removeTailer(id string) {
+	a.tails.l.Lock()
+	defer a.tails.l.Unlock()
+	delete(a.tails.tailers, id)
+}
+
+// hasTailers returns true if there is at least one live audit tail session.
+func (a *AuditBroker) hasTailers() bool {
+	a.tails.l.RLock()
+	defer a.tails.l.RUnlock()
+	return len(a.tails.tailers) > 0
+}
+
+// tailSalter returns the audit backend whose salt should be used to hash
+// entries for the given device. The caller must hold the broker read lock.
+func (a *AuditBroker) tailSalter(device string) audit.Salter {
+	if be, ok := a.backends[device]; ok {
+		return be.backend
+	}
+	names := make([]string, 0, len(a.backends))
+	for name := range a.backends {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return a.backends[names[0]].backend
+}
+
+// sendToTailers formats the given input and delivers it to every live tail
+// session whose filters match. Request headers are never included. The caller
+// must hold the broker read lock.
+func (a *AuditBroker) sendToTailers(ctx context.Context, in *logical.LogInput, isResponse bool) {
+	if !a.hasTailers() {
+		return
+	}
+
+	in.Request.Headers = nil
+
+	a.tails.l.RLock()
+	defer a.tails.l.RUnlock()
+
+	// Entries are formatted at most once per device salt.
+	formatted := make(map[string][]byte)
+	fields := make(map[string]map[string]string)
+
+	for _, t := range a.tails.tailers {
+		if _, ok := formatted[t.device]; !ok {
+			entry, entryFields, err := a.formatTailEntry(ctx, in, t.device, isResponse)
+			if err != nil {
+				a.logger.Error("failed to format audit entry for tail session", "error", err)
+				return
+			}
+			formatted[t.device] = entry
+			fields[t.device] = entryFields
+		}
+
+		matched := true
+		for _, f := range t.filters {
+			if !f.matches(fields[t.device]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			t.offer(formatted[t.device])
+		}
+	}
+}
+
+// formatTailEntry formats the input as a hashed JSON request or response
+// entry and extracts the fields that filters may reference.
+func (a *AuditBroker) formatTailEntry(ctx context.Context, in *logical.LogInput, device string, isResponse bool) ([]byte, map[string]string, error) {
+	salter := a.tailSalter(device)
+	if salter == nil {
+		return nil, nil, fmt.Errorf("no audit devices are enabled")
+	}
+
+	cfg, err := audit.NewFormatterConfig(audit.WithHMACAccessor(true))
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := audit.NewEntryFormatter(cfg, salter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entry interface{}
+	var req *audit.Request
+	var entryType, entryErr string
+	if isResponse {
+		e, err := f.FormatResponse(ctx, in)
+		if err != nil {
+			return nil, nil, err
+		}
+		entry, req, entryType, entryErr = e, e.Request, e.Type, e.Error
+	} else {
+		e, err := f.FormatRequest(ctx, in)
+		if err != nil {
+			return nil, nil, err
+		}
+		entry, req, entryType, entryErr = e, e.Request, e.Type, e.Error
+	}
+
+	fields := map[string]string{
+		"type":  entryType,
+		"error": entryErr,
+	}
+	if req != nil {
+		fields["path"] = req.Path
+		fields["operation"] = string(req.Operation)
+		fields["mount_point"] = req.MountPoint
+		fields["mount_type"] = req.MountType
+		fields["remote_address"] = req.RemoteAddr
+		if req.Namespace != nil {
+			fields["namespace"] = req.Namespace.Path
+		}
+	}
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return b, fields, nil
+}
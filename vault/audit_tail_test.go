@@ -0,0 +1,123 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	log "github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/helper/testhelpers/corehelpers"
+	"github.com/hashicorp/vault/sdk/helper/logging"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestParseAuditTailFilter(t *testing.T) {
+	fields := map[string]string{
+		"path":      "secret/foo",
+		"operation": "update",
+	}
+
+	cases := []struct {
+		expr    string
+		wantErr bool
+		matches bool
+	}{
+		{`path == secret/foo`, false, true},
+		{`path == "secret/foo"`, false, true},
+		{`path != secret/foo`, false, false},
+		{`path matches secret/*`, false, true},
+		{`path matches "kv/*"`, false, false},
+		{`operation == read`, false, false},
+		{`OPERATION == update`, false, true},
+		{`path ==`, true, false},
+		{`token == foo`, true, false},
+		{`path ~= foo`, true, false},
+	}
+
+	for _, tc := range cases {
+		f, err := parseAuditTailFilter(tc.expr)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("%q: expected error", tc.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tc.expr, err)
+		}
+		if got := f.matches(fields); got != tc.matches {
+			t.Fatalf("%q: expected match %t, got %t", tc.expr, tc.matches, got)
+		}
+	}
+}
+
+func TestAuditBroker_Tail(t *testing.T) {
+	l := logging.NewVaultLogger(log.Trace)
+	b, err := NewAuditBroker(l, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := b.addTailer(nil, ""); err == nil {
+		t.Fatal("expected error tailing without any audit devices")
+	}
+
+	b.Register("foo", corehelpers.TestNoopAudit(t, nil), false)
+
+	if _, _, err := b.addTailer(nil, "bar/"); err == nil {
+		t.Fatal("expected error tailing with an unknown audit device")
+	}
+
+	id, tailer, err := b.addTailer([]string{"path matches secret/*", "operation == update"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	headersConf := &AuditedHeadersConfig{
+		Headers: make(map[string]*auditedHeaderSettings),
+	}
+	ctx := namespace.RootContext(context.Background())
+
+	for _, req := range []*logical.Request{
+		{Operation: logical.ReadOperation, Path: "secret/foo"},
+		{Operation: logical.UpdateOperation, Path: "sys/mounts"},
+		{
+			Operation: logical.UpdateOperation,
+			Path:      "secret/foo",
+			Data:      map[string]interface{}{"password": "hunter2"},
+			Headers:   map[string][]string{"X-Custom": {"bar"}},
+		},
+	} {
+		if err := b.LogRequest(ctx, &logical.LogInput{Request: req}, headersConf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(tailer.ch) != 1 {
+		t.Fatalf("expected 1 tailed entry, got %d", len(tailer.ch))
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(<-tailer.ch, &entry); err != nil {
+		t.Fatal(err)
+	}
+	req := entry["request"].(map[string]interface{})
+	if req["path"] != "secret/foo" {
+		t.Fatalf("unexpected path %v", req["path"])
+	}
+	if _, ok := req["headers"]; ok {
+		t.Fatal("expected request headers to be omitted")
+	}
+	if data := req["data"].(map[string]interface{}); data["password"] == "hunter2" {
+		t.Fatal("expected request data to be hashed")
+	}
+
+	b.removeTailer(id)
+	if b.hasTailers() {
+		t.Fatal("expected no tailers after removal")
+	}
+}
@@ -9,7 +9,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	metrics "github.com/armon/go-metrics"
 	"github.com/hashicorp/go-secure-stdlib/strutil"
 	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/builtin/plugin"
@@ -768,13 +770,15 @@ func (c *Core) persistAuth(ctx context.Context, table *MountTable, local *bool)
 
 // setupCredentials is invoked after we've loaded the auth table to
 // initialize the credential backends and setup the router
-func (c *Core) setupCredentials(ctx context.Context) error {
+func (c *Core) setupCredentials(ctx context.Context) (retErr error) {
 	c.authLock.Lock()
 	defer c.authLock.Unlock()
 
-	for _, entry := range c.auth.sortEntriesByPathDepth().Entries {
-		var backend logical.Backend
+	defer metrics.MeasureSince([]string{"core", "setup_credentials"}, time.Now())
 
+	entries := c.auth.sortEntriesByPathDepth().Entries
+	pending := make([]*pendingMount, 0, len(entries))
+	for _, entry := range entries {
 		// Create a barrier view using the UUID
 		viewPath := entry.ViewPath()
 
@@ -801,10 +805,27 @@ func (c *Core) setupCredentials(ctx context.Context) error {
 			defer view.setReadOnlyErr(origViewReadOnlyErr)
 		}
 
-		// Initialize the backend
-		sysView := c.mountEntrySysView(entry)
+		pending = append(pending, &pendingMount{
+			entry:           entry,
+			view:            view,
+			sysView:         c.mountEntrySysView(entry),
+			nilMount:        nilMount,
+			origReadOnlyErr: origViewReadOnlyErr,
+		})
+	}
+
+	// Initialize the backends
+	c.createMountBackends(ctx, pending, c.newCredentialBackend, []string{"core", "credential", "setup"})
+	defer func() {
+		if retErr != nil {
+			cleanupPendingMounts(ctx, pending)
+		}
+	}()
 
-		backend, entry.RunningSha256, err = c.newCredentialBackend(ctx, entry, sysView, view)
+	for _, p := range pending {
+		entry, view, nilMount, origViewReadOnlyErr := p.entry, p.view, p.nilMount, p.origReadOnlyErr
+		viewPath := entry.ViewPath()
+		backend, err := p.backend, p.err
 		if err != nil {
 			c.logger.Error("failed to create credential entry", "path", entry.Path, "error", err)
 
@@ -839,6 +860,7 @@ func (c *Core) setupCredentials(ctx context.Context) error {
 				c.logger.Error("skipping deprecated auth entry", "name", entry.Type, "path", entry.Path, "error", err)
 				backend.Cleanup(ctx)
 				backend = nil
+				p.backend = nil
 				goto ROUTER_MOUNT
 			}
 		}
@@ -859,6 +881,7 @@ func (c *Core) setupCredentials(ctx context.Context) error {
 		if nilMount {
 			backend.Cleanup(ctx)
 			backend = nil
+			p.backend = nil
 		}
 
 	ROUTER_MOUNT:
@@ -869,6 +892,8 @@ func (c *Core) setupCredentials(ctx context.Context) error {
 			c.logger.Error("failed to mount auth entry", "path", entry.Path, "namespace", entry.Namespace(), "error", err)
 			return errLoadAuthFailed
 		}
+		// The router now owns the backend.
+		p.backend = nil
 
 		if c.logger.IsInfo() {
 			c.logger.Info("successfully mounted", "type", entry.Type, "version", entry.RunningVersion, "path", entry.Path, "namespace", entry.Namespace())
@@ -915,7 +940,12 @@ func (c *Core) setupCredentials(ctx context.Context) error {
 					view.setReadOnlyErr(origViewReadOnlyErr)
 				}
 
+				start := time.Now()
 				err := backend.Initialize(ctx, &logical.InitializationRequest{Storage: view})
+				metrics.MeasureSinceWithLabels([]string{"core", "credential", "initialize"}, start, []metrics.Label{
+					{"type", localEntry.Type},
+					{"mount_point", mountPointLabel(localEntry)},
+				})
 				if err != nil {
 					postUnsealLogger.Error("failed to initialize auth backend", "error", err)
 				}
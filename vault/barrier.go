@@ -6,6 +6,7 @@ package vault
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"time"
 
@@ -70,6 +71,43 @@ const (
 	shamirKekPath = "core/shamir-kek"
 )
 
+const (
+	// BarrierAlgorithmAESGCM is the default barrier encryption algorithm,
+	// AES-256 in GCM mode with a 96 bit nonce.
+	BarrierAlgorithmAESGCM = "aes256-gcm96"
+
+	// BarrierAlgorithmChaCha20Poly1305 is the ChaCha20-Poly1305 AEAD, which
+	// is faster than AES-GCM on platforms without AES hardware acceleration.
+	BarrierAlgorithmChaCha20Poly1305 = "chacha20-poly1305"
+)
+
+// ValidateBarrierAlgorithm checks that the barrier supports the algorithm.
+func ValidateBarrierAlgorithm(algorithm string) error {
+	switch algorithm {
+	case BarrierAlgorithmAESGCM, BarrierAlgorithmChaCha20Poly1305:
+		return nil
+	default:
+		return fmt.Errorf("unsupported barrier algorithm %q, must be %q or %q", algorithm, BarrierAlgorithmAESGCM, BarrierAlgorithmChaCha20Poly1305)
+	}
+}
+
+// barrierAlgorithm returns the algorithm of a key, which is stored empty for
+// the default algorithm.
+func barrierAlgorithm(stored string) string {
+	if stored == "" {
+		return BarrierAlgorithmAESGCM
+	}
+	return stored
+}
+
+// storedBarrierAlgorithm returns the algorithm as stored in a key.
+func storedBarrierAlgorithm(algorithm string) string {
+	if algorithm == BarrierAlgorithmAESGCM {
+		return ""
+	}
+	return algorithm
+}
+
 // SecurityBarrier is a critical component of Vault. It is used to wrap
 // an untrusted physical backend and provide a single point of encryption,
 // decryption and checksum verification. The goal is to ensure that any
@@ -87,6 +125,10 @@ type SecurityBarrier interface {
 	// is to be stored using sealKey to encrypt it.
 	Initialize(ctx context.Context, rootKey []byte, sealKey []byte, random io.Reader) error
 
+	// InitializeWithAlgorithm is like Initialize, but data is encrypted
+	// with the given algorithm rather than the default.
+	InitializeWithAlgorithm(ctx context.Context, rootKey []byte, sealKey []byte, random io.Reader, algorithm string) error
+
 	// GenerateKey is used to generate a new key
 	GenerateKey(io.Reader) ([]byte, error)
 
@@ -127,6 +169,10 @@ type SecurityBarrier interface {
 	// should use the new key, while old values should still be decryptable.
 	Rotate(ctx context.Context, reader io.Reader) (uint32, error)
 
+	// RotateWithAlgorithm is like Rotate, but the new key uses the given
+	// algorithm. This is used to convert the barrier to another algorithm.
+	RotateWithAlgorithm(ctx context.Context, reader io.Reader, algorithm string) (uint32, error)
+
 	// CreateUpgrade creates an upgrade path key to the given term from the previous term
 	CreateUpgrade(ctx context.Context, term uint32) error
 
@@ -197,4 +243,5 @@ type KeyInfo struct {
 	Term        int
 	InstallTime time.Time
 	Encryptions int64
+	Algorithm   string
 }
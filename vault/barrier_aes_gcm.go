@@ -25,6 +25,7 @@ import (
 	"github.com/hashicorp/vault/sdk/logical"
 	"github.com/hashicorp/vault/sdk/physical"
 	"go.uber.org/atomic"
+	"golang.org/x/crypto/chacha20poly1305"
 )
 
 const (
@@ -154,6 +155,16 @@ func (b *AESGCMBarrier) Initialized(ctx context.Context) (bool, error) {
 // Initialize works only if the barrier has not been initialized
 // and makes use of the given root key.
 func (b *AESGCMBarrier) Initialize(ctx context.Context, key []byte, sealKey []byte, reader io.Reader) error {
+	return b.InitializeWithAlgorithm(ctx, key, sealKey, reader, BarrierAlgorithmAESGCM)
+}
+
+// InitializeWithAlgorithm is like Initialize, but encrypts data with the
+// given algorithm.
+func (b *AESGCMBarrier) InitializeWithAlgorithm(ctx context.Context, key []byte, sealKey []byte, reader io.Reader, algorithm string) error {
+	if err := ValidateBarrierAlgorithm(algorithm); err != nil {
+		return err
+	}
+
 	// Verify the key size
 	min, max := b.KeyLength()
 	if len(key) < min || len(key) > max {
@@ -177,9 +188,10 @@ func (b *AESGCMBarrier) Initialize(ctx context.Context, key []byte, sealKey []by
 	keyring := NewKeyring()
 	keyring = keyring.SetRootKey(key)
 	keyring, err = keyring.AddKey(&Key{
-		Term:    1,
-		Version: 1,
-		Value:   encryptionKey,
+		Term:      1,
+		Version:   1,
+		Value:     encryptionKey,
+		Algorithm: storedBarrierAlgorithm(algorithm),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create keyring: %w", err)
@@ -191,7 +203,7 @@ func (b *AESGCMBarrier) Initialize(ctx context.Context, key []byte, sealKey []by
 	}
 
 	if len(sealKey) > 0 {
-		primary, err := b.aeadFromKey(encryptionKey)
+		primary, err := b.aeadFromKeyAlgorithm(encryptionKey, algorithm)
 		if err != nil {
 			return err
 		}
@@ -253,7 +265,7 @@ func (b *AESGCMBarrier) persistKeyring(ctx context.Context, keyring *Keyring) er
 
 	// Encrypt the root key
 	activeKey := keyring.ActiveKey()
-	aead, err := b.aeadFromKey(activeKey.Value)
+	aead, err := b.aeadFromKeyAlgorithm(activeKey.Value, activeKey.Algorithm)
 	if err != nil {
 		return err
 	}
@@ -548,13 +560,26 @@ func (b *AESGCMBarrier) Seal() error {
 
 // Rotate is used to create a new encryption key. All future writes
 // should use the new key, while old values should still be decryptable.
+// The new key uses the same algorithm as the active key.
 func (b *AESGCMBarrier) Rotate(ctx context.Context, randomSource io.Reader) (uint32, error) {
+	return b.RotateWithAlgorithm(ctx, randomSource, "")
+}
+
+// RotateWithAlgorithm is like Rotate, but the new key uses the given
+// algorithm, or the algorithm of the active key if it is empty.
+func (b *AESGCMBarrier) RotateWithAlgorithm(ctx context.Context, randomSource io.Reader, algorithm string) (uint32, error) {
 	b.l.Lock()
 	defer b.l.Unlock()
 	if b.sealed {
 		return 0, ErrBarrierSealed
 	}
 
+	if algorithm == "" {
+		algorithm = b.keyring.ActiveKey().Algorithm
+	} else if err := ValidateBarrierAlgorithm(algorithm); err != nil {
+		return 0, err
+	}
+
 	// Generate a new key
 	encrypt, err := b.GenerateKey(randomSource)
 	if err != nil {
@@ -567,9 +592,10 @@ func (b *AESGCMBarrier) Rotate(ctx context.Context, randomSource io.Reader) (uin
 
 	// Add a new encryption key
 	newKeyring, err := b.keyring.AddKey(&Key{
-		Term:    newTerm,
-		Version: 1,
-		Value:   encrypt,
+		Term:      newTerm,
+		Version:   1,
+		Value:     encrypt,
+		Algorithm: storedBarrierAlgorithm(algorithm),
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to add new encryption key: %w", err)
@@ -719,6 +745,7 @@ func (b *AESGCMBarrier) ActiveKeyInfo() (*KeyInfo, error) {
 		Term:        int(term),
 		InstallTime: key.InstallTime,
 		Encryptions: b.encryptions(),
+		Algorithm:   barrierAlgorithm(key.Algorithm),
 	}
 	return info, nil
 }
@@ -931,7 +958,7 @@ func (b *AESGCMBarrier) aeadForTerm(term uint32) (cipher.AEAD, error) {
 	}
 
 	// Create a new aead
-	aead, err := b.aeadFromKey(key.Value)
+	aead, err := b.aeadFromKeyAlgorithm(key.Value, key.Algorithm)
 	if err != nil {
 		return nil, err
 	}
@@ -943,6 +970,23 @@ func (b *AESGCMBarrier) aeadForTerm(term uint32) (cipher.AEAD, error) {
 	return aead, nil
 }
 
+// aeadFromKeyAlgorithm returns an AEAD of the given algorithm using the
+// given key.
+func (b *AESGCMBarrier) aeadFromKeyAlgorithm(key []byte, algorithm string) (cipher.AEAD, error) {
+	switch barrierAlgorithm(algorithm) {
+	case BarrierAlgorithmAESGCM:
+		return b.aeadFromKey(key)
+	case BarrierAlgorithmChaCha20Poly1305:
+		aead, err := chacha20poly1305.New(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cipher: %w", err)
+		}
+		return aead, nil
+	default:
+		return nil, fmt.Errorf("unsupported barrier algorithm %q", algorithm)
+	}
+}
+
 // aeadFromKey returns an AES-GCM AEAD using the given key.
 func (b *AESGCMBarrier) aeadFromKey(key []byte) (cipher.AEAD, error) {
 	// Create the AES cipher
@@ -694,3 +694,82 @@ func TestBarrier_LegacyRotate(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestAESGCMBarrier_ChaCha20Poly1305(t *testing.T) {
+	ctx := context.Background()
+	inm, err := inmem.NewInmem(nil, logger)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	b, err := NewAESGCMBarrier(inm)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	key, _ := b.GenerateKey(rand.Reader)
+	if err := b.InitializeWithAlgorithm(ctx, key, nil, rand.Reader, "rot13"); err == nil {
+		t.Fatal("expected error initializing with an unsupported algorithm")
+	}
+	if err := b.InitializeWithAlgorithm(ctx, key, nil, rand.Reader, BarrierAlgorithmChaCha20Poly1305); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := b.Unseal(ctx, key); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	info, err := b.ActiveKeyInfo()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if info.Algorithm != BarrierAlgorithmChaCha20Poly1305 {
+		t.Fatalf("unexpected algorithm %q", info.Algorithm)
+	}
+
+	entry := &logical.StorageEntry{Key: "test", Value: []byte("chacha")}
+	if err := b.Put(ctx, entry); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Rotations keep the algorithm of the active key.
+	if _, err := b.Rotate(ctx, rand.Reader); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if info, _ := b.ActiveKeyInfo(); info.Algorithm != BarrierAlgorithmChaCha20Poly1305 {
+		t.Fatalf("unexpected algorithm %q", info.Algorithm)
+	}
+
+	// Convert back to AES-GCM, keeping older entries readable.
+	if _, err := b.RotateWithAlgorithm(ctx, rand.Reader, BarrierAlgorithmAESGCM); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if info, _ := b.ActiveKeyInfo(); info.Algorithm != BarrierAlgorithmAESGCM {
+		t.Fatalf("unexpected algorithm %q", info.Algorithm)
+	}
+	if err := b.Put(ctx, &logical.StorageEntry{Key: "test2", Value: []byte("aes")}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The algorithms survive sealing and reloading the keyring.
+	if err := b.Seal(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	b2, err := NewAESGCMBarrier(inm)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := b2.Unseal(ctx, key); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for k, v := range map[string]string{"test": "chacha", "test2": "aes"} {
+		out, err := b2.Get(ctx, k)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out == nil || string(out.Value) != v {
+			t.Fatalf("unexpected entry %q: %#v", k, out)
+		}
+	}
+	if b2.keyring.TermKey(1).Algorithm != BarrierAlgorithmChaCha20Poly1305 || b2.keyring.TermKey(3).Algorithm != "" {
+		t.Fatal("unexpected key algorithms after reload")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"sync/atomic"
 
 	"github.com/hashicorp/vault/sdk/logical"
 )
@@ -23,13 +24,18 @@ type BarrierView struct {
 	readOnlyErr     error
 	readOnlyErrLock sync.RWMutex
 	iCheck          interface{}
+
+	// readCache is shared with sub-views, so that they see the cache being
+	// enabled or disabled on the mount's view.
+	readCache *atomic.Pointer[viewReadCache]
 }
 
 // NewBarrierView takes an underlying security barrier and returns
 // a view of it that can only operate with the given prefix.
 func NewBarrierView(barrier logical.Storage, prefix string) *BarrierView {
 	return &BarrierView{
-		storage: logical.NewStorageView(barrier, prefix),
+		storage:   logical.NewStorageView(barrier, prefix),
+		readCache: new(atomic.Pointer[viewReadCache]),
 	}
 }
 
@@ -49,6 +55,22 @@ func (v *BarrierView) getReadOnlyErr() error {
 	return v.readOnlyErr
 }
 
+// setReadCache replaces the read cache of the view with an empty cache of
+// the given size. A size of zero disables the cache.
+func (v *BarrierView) setReadCache(size int) error {
+	if size <= 0 {
+		v.readCache.Store(nil)
+		return nil
+	}
+
+	rc, err := newViewReadCache(size)
+	if err != nil {
+		return err
+	}
+	v.readCache.Store(rc)
+	return nil
+}
+
 func (v *BarrierView) Prefix() string {
 	return v.storage.Prefix()
 }
@@ -58,6 +80,11 @@ func (v *BarrierView) List(ctx context.Context, prefix string) ([]string, error)
 }
 
 func (v *BarrierView) Get(ctx context.Context, key string) (*logical.StorageEntry, error) {
+	if rc := v.readCache.Load(); rc != nil {
+		return rc.get(v.storage.ExpandKey(key), key, func() (*logical.StorageEntry, error) {
+			return v.storage.Get(ctx, key)
+		})
+	}
 	return v.storage.Get(ctx, key)
 }
 
@@ -76,6 +103,11 @@ func (v *BarrierView) Put(ctx context.Context, entry *logical.StorageEntry) erro
 		}
 	}
 
+	if rc := v.readCache.Load(); rc != nil {
+		return rc.write(expandedKey, func() error {
+			return v.storage.Put(ctx, entry)
+		})
+	}
 	return v.storage.Put(ctx, entry)
 }
 
@@ -90,6 +122,11 @@ func (v *BarrierView) Delete(ctx context.Context, key string) error {
 		}
 	}
 
+	if rc := v.readCache.Load(); rc != nil {
+		return rc.write(expandedKey, func() error {
+			return v.storage.Delete(ctx, key)
+		})
+	}
 	return v.storage.Delete(ctx, key)
 }
 
@@ -99,5 +136,6 @@ func (v *BarrierView) SubView(prefix string) *BarrierView {
 		storage:     v.storage.SubView(prefix),
 		readOnlyErr: v.getReadOnlyErr(),
 		iCheck:      v.iCheck,
+		readCache:   v.readCache,
 	}
 }
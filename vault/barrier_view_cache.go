@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/hashicorp/vault/sdk/helper/locksutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// viewReadCache is an in-memory cache of the decrypted entries of a mount's
+// storage view. It is enabled per mount with the read_cache_size option so
+// that hot reads of read-heavy backends, such as KV secrets or PKI CA
+// certificates, don't decrypt the same entry from storage on every request.
+//
+// Entries are keyed by their full storage path. Writes through the view
+// drop the key, and writes that bypass the view, such as raw writes and
+// writes replicated from the active node, must be reported with
+// Core.invalidateReadCache.
+type viewReadCache struct {
+	lru   *lru.TwoQueueCache
+	locks []*locksutil.LockEntry
+}
+
+func newViewReadCache(size int) (*viewReadCache, error) {
+	cache, err := lru.New2Q(size)
+	if err != nil {
+		return nil, err
+	}
+	return &viewReadCache{
+		lru:   cache,
+		locks: locksutil.CreateLocks(),
+	}, nil
+}
+
+// get returns the entry at the given storage path, using fetch to read it
+// from storage on a miss. Missing entries are cached as well.
+func (rc *viewReadCache) get(path, key string, fetch func() (*logical.StorageEntry, error)) (*logical.StorageEntry, error) {
+	lock := locksutil.LockForKey(rc.locks, path)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if raw, ok := rc.lru.Get(path); ok {
+		return copyStorageEntry(raw.(*logical.StorageEntry), key), nil
+	}
+
+	entry, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	rc.lru.Add(path, copyStorageEntry(entry, key))
+
+	return entry, nil
+}
+
+// write runs a write to the given storage path and drops the path from the
+// cache. Reads of the path wait for the write, so that they can't cache the
+// value it replaces.
+func (rc *viewReadCache) write(path string, fn func() error) error {
+	lock := locksutil.LockForKey(rc.locks, path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	defer rc.lru.Remove(path)
+	return fn()
+}
+
+// invalidate drops the given storage path from the cache.
+func (rc *viewReadCache) invalidate(path string) {
+	lock := locksutil.LockForKey(rc.locks, path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	rc.lru.Remove(path)
+}
+
+// purge drops all entries from the cache.
+func (rc *viewReadCache) purge() {
+	rc.lru.Purge()
+}
+
+// copyStorageEntry returns a copy of the entry with the given key, so that
+// callers can't modify the cached value. The key is set explicitly as the
+// cache is shared with sub-views, which see the entry under another key.
+func copyStorageEntry(entry *logical.StorageEntry, key string) *logical.StorageEntry {
+	if entry == nil {
+		return nil
+	}
+	return &logical.StorageEntry{
+		Key:      key,
+		Value:    append([]byte(nil), entry.Value...),
+		SealWrap: entry.SealWrap,
+	}
+}
+
+// configureReadCache enables, resizes or disables the read cache of the
+// storage view of a mount to match its configuration.
+func (c *Core) configureReadCache(entry *MountEntry, view *BarrierView) error {
+	size := entry.Config.ReadCacheSize
+	if c.cachingDisabled {
+		size = 0
+	}
+	return view.setReadCache(size)
+}
+
+// invalidateReadCache drops an entry, given by its full storage path, from
+// the read cache of the mount owning it. It must be called whenever a key is
+// written without going through the mount's storage view, such as by the raw
+// endpoints or by replication.
+func (c *Core) invalidateReadCache(key string) {
+	c.router.invalidateReadCache(key)
+}
+
+// raftInvalidateCallback is for the raft backend to report the keys written
+// by the logs it applies, so that writes replicated from the active node are
+// dropped from the read caches of performance standbys. The caches are
+// invalidated in the background, as the active node applies its own writes
+// while holding the cache locks of the keys being written.
+func (c *Core) raftInvalidateCallback(keys []string) {
+	go func() {
+		for _, key := range keys {
+			c.invalidateReadCache(key)
+		}
+	}()
+}
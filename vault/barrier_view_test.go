@@ -319,3 +319,64 @@ func TestBarrierView_Readonly(t *testing.T) {
 		t.Fatalf("key test missing")
 	}
 }
+
+func TestBarrierView_ReadCache(t *testing.T) {
+	ctx := context.Background()
+	_, barrier, _ := mockBarrier(t)
+	view := NewBarrierView(barrier, "foo/")
+	if err := view.setReadCache(16); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	logical.TestStorage(t, view)
+
+	entry := &logical.StorageEntry{Key: "bar/baz", Value: []byte("test")}
+	if err := view.Put(ctx, entry); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err := view.Get(ctx, "bar/baz")
+	if err != nil || out == nil || string(out.Value) != "test" {
+		t.Fatalf("bad: %v %v", out, err)
+	}
+
+	// Modifying the returned entry must not modify the cached one
+	out.Value[0] = 'T'
+
+	// Writes which bypass the view are not seen until invalidated
+	if err := barrier.Put(ctx, &logical.StorageEntry{Key: "foo/bar/baz", Value: []byte("other")}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = view.Get(ctx, "bar/baz")
+	if err != nil || out == nil || string(out.Value) != "test" {
+		t.Fatalf("bad: %v %v", out, err)
+	}
+
+	// Sub-views share the cache of their parent
+	sub := view.SubView("bar/")
+	out, err = sub.Get(ctx, "baz")
+	if err != nil || out == nil || out.Key != "baz" || string(out.Value) != "test" {
+		t.Fatalf("bad: %v %v", out, err)
+	}
+
+	view.readCache.Load().invalidate("foo/bar/baz")
+	out, err = sub.Get(ctx, "baz")
+	if err != nil || out == nil || string(out.Value) != "other" {
+		t.Fatalf("bad: %v %v", out, err)
+	}
+
+	// Writes through a sub-view drop the entry from the parent's cache
+	if err := sub.Delete(ctx, "baz"); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = view.Get(ctx, "bar/baz")
+	if err != nil || out != nil {
+		t.Fatalf("bad: %v %v", out, err)
+	}
+
+	// Once disabled, reads go to storage
+	if err := view.setReadCache(0); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if sub.readCache.Load() != nil {
+		t.Fatalf("expected the cache to be disabled on the sub-view")
+	}
+}
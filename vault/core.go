@@ -6,6 +6,7 @@ package vault
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/subtle"
 	"crypto/tls"
@@ -545,6 +546,12 @@ type Core struct {
 	// pluginFilePermissions is the permissions of the plugin files and directory
 	pluginFilePermissions int
 
+	// pluginRegistryURL is the base URL plugin artifacts are installed from
+	pluginRegistryURL string
+
+	// pluginSigningKeys are the keys trusted to sign installed plugin artifacts
+	pluginSigningKeys []ed25519.PublicKey
+
 	// pluginCatalog is used to manage plugin configurations
 	pluginCatalog *PluginCatalog
 
@@ -807,6 +814,14 @@ type CoreConfig struct {
 
 	PluginFilePermissions int
 
+	// PluginRegistryURL is the base URL plugins are installed from through
+	// sys/plugins/install when no explicit URL is given.
+	PluginRegistryURL string
+
+	// PluginSigningKeys are the keys one of which must have signed a plugin
+	// artifact for it to be installed through sys/plugins/install.
+	PluginSigningKeys []ed25519.PublicKey
+
 	DisableSealWrap bool
 
 	RawConfig *server.Config
@@ -1198,6 +1213,8 @@ func NewCore(conf *CoreConfig) (*Core, error) {
 	if conf.PluginFilePermissions != 0 {
 		c.pluginFilePermissions = conf.PluginFilePermissions
 	}
+	c.pluginRegistryURL = strings.TrimSuffix(conf.PluginRegistryURL, "/")
+	c.pluginSigningKeys = conf.PluginSigningKeys
 
 	createSecondaries(c, conf)
 
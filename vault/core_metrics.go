@@ -124,6 +124,10 @@ func (c *Core) metricsLoop(stopCh chan struct{}) {
 			// Capture the total number of in-flight requests
 			c.inFlightReqGaugeMetric()
 
+			// Capture the number of external plugin processes and the
+			// backends they serve
+			c.externalPluginGaugeMetrics(stopCh)
+
 			// Refresh gauge metrics that are looped
 			c.cachedGaugeMetricsEmitter()
 		case <-writeTimer:
@@ -572,6 +576,27 @@ func (c *Core) inFlightReqGaugeMetric() {
 	c.metricSink.SetGaugeWithLabels([]string{"core", "in_flight_requests"}, float32(totalInFlightReq), nil)
 }
 
+func (c *Core) externalPluginGaugeMetrics(stopCh chan struct{}) {
+	l := newLockGrabber(c.stateLock.RLock, c.stateLock.RUnlock, stopCh)
+	go l.grab()
+	if stopped := l.lockOrStop(); stopped {
+		return
+	}
+	pluginCatalog := c.pluginCatalog
+	c.stateLock.RUnlock()
+	if pluginCatalog == nil {
+		return
+	}
+
+	processes, connections := pluginCatalog.externalPluginGauges()
+	for _, g := range processes {
+		c.metricSink.SetGaugeWithLabels([]string{"plugin", "external", "processes"}, g.Value, g.Labels)
+	}
+	for _, g := range connections {
+		c.metricSink.SetGaugeWithLabels([]string{"plugin", "external", "backends"}, g.Value, g.Labels)
+	}
+}
+
 // configuredPoliciesGaugeCollector is used to collect gauge label values for the `vault.policy.configured.count` metric
 func (c *Core) configuredPoliciesGaugeCollector(ctx context.Context) ([]metricsutil.GaugeLabelValues, error) {
 	if c.policyStore == nil {
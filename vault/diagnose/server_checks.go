@@ -0,0 +1,167 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package diagnose
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/internalshared/configutil"
+)
+
+const (
+	raftPeersTestName       = "Check Raft Peer Connectivity"
+	TimeSkewWarningPrefix   = "Clock skew with the Vault server is "
+	TimeSkewWarnThreshold   = 5 * time.Second
+	ReachabilityDialTimeout = 5 * time.Second
+)
+
+// sealEndpointKeys lists the seal configuration keys that hold the address of
+// a remote KMS, per seal type. Seals talking to a default, cloud-wide
+// endpoint are not listed.
+var sealEndpointKeys = map[string][]string{
+	"transit": {"address"},
+	"awskms":  {"endpoint"},
+	"ocikms":  {"crypto_endpoint", "management_endpoint"},
+}
+
+// DialAddress checks that a TCP connection can be opened to addr, which is
+// either a URL or a host:port pair. URLs without a port use the default port
+// of their scheme.
+func DialAddress(ctx context.Context, addr string, timeout time.Duration) error {
+	hostPort := addr
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return fmt.Errorf("Invalid address %q: %w.", addr, err)
+		}
+		hostPort = u.Host
+		if u.Port() == "" {
+			port := "443"
+			if u.Scheme == "http" {
+				port = "80"
+			}
+			hostPort = net.JoinHostPort(u.Hostname(), port)
+		}
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// RaftPeerConnectivity checks that the cluster address of every raft peer
+// other than the local node accepts connections. It returns the addresses of
+// unreachable peers for testing purposes.
+func RaftPeerConnectivity(ctx context.Context, b RaftConfigurableStorageBackend, localNodeID string) []string {
+	conf, err := b.GetConfigurationOffline()
+	if err != nil {
+		SpotError(ctx, raftPeersTestName, fmt.Errorf("Error retrieving server configuration: %w.", err))
+		return nil
+	}
+
+	var checked int
+	var unreachable []string
+	for _, s := range conf.Servers {
+		if s.NodeID == localNodeID {
+			continue
+		}
+		checked++
+		if err := DialAddress(ctx, s.Address, ReachabilityDialTimeout); err != nil {
+			unreachable = append(unreachable, s.Address)
+			SpotWarn(ctx, raftPeersTestName, fmt.Sprintf("Raft peer %s at %s is not reachable: %s.", s.NodeID, s.Address, err))
+		}
+	}
+
+	switch {
+	case checked == 0:
+		SpotSkipped(ctx, raftPeersTestName, "No other raft peers found.")
+	case len(unreachable) == 0:
+		SpotOk(ctx, raftPeersTestName, fmt.Sprintf("All %d raft peers are reachable.", checked))
+	default:
+		Advise(ctx, "Make sure the cluster port is open between all raft peers, and that the peers are running.")
+	}
+	return unreachable
+}
+
+// SealReachabilityChecks checks that the remote endpoints configured for the
+// seals accept connections.
+func SealReachabilityChecks(ctx context.Context, seals []*configutil.KMS) error {
+	var checked int
+	for _, seal := range seals {
+		if seal.Disabled {
+			continue
+		}
+		for _, key := range sealEndpointKeys[seal.Type] {
+			addr := seal.Config[key]
+			if addr == "" {
+				continue
+			}
+			checked++
+			if err := DialAddress(ctx, addr, ReachabilityDialTimeout); err != nil {
+				Advise(ctx, "Make sure the KMS is running and that firewalls allow connections to it from this host.")
+				return fmt.Errorf("The %s endpoint %s of the %s seal is not reachable: %w.", key, addr, seal.Type, err)
+			}
+		}
+	}
+
+	if checked == 0 {
+		Skipped(ctx, "No seal with a configured remote endpoint found.")
+		return nil
+	}
+	Success(ctx, fmt.Sprintf("%d seal endpoints are reachable.", checked))
+	return nil
+}
+
+// TimeSkew estimates the difference between the local clock and serverTime,
+// which was read from a response to a request sent at start and received at
+// end. A warning is added if the skew exceeds TimeSkewWarnThreshold.
+func TimeSkew(ctx context.Context, serverTime, start, end time.Time) time.Duration {
+	// Assume the server read its clock halfway through the request
+	local := start.Add(end.Sub(start) / 2)
+	skew := serverTime.Sub(local)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	// The server only reports seconds, so allow for the truncation
+	if skew > TimeSkewWarnThreshold+time.Second {
+		Warn(ctx, fmt.Sprintf(TimeSkewWarningPrefix+"%s.", skew.Round(time.Millisecond)))
+		Advise(ctx, "Synchronize the clocks of all Vault nodes with NTP. Clock skew breaks certificate and token validation.")
+	} else {
+		Success(ctx, fmt.Sprintf("Clock skew with the Vault server is below %s.", TimeSkewWarnThreshold))
+	}
+	return skew
+}
+
+// AuditFileWritable checks that the file at path can be appended to, or
+// created if it does not exist yet.
+func AuditFileWritable(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	switch {
+	case err == nil:
+		return f.Close()
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	// The file is created when the device is enabled or reloaded, so the
+	// directory has to be writable.
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".vault-diagnose")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	return os.Remove(tmp.Name())
+}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package diagnose
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/internalshared/configutil"
+	"github.com/hashicorp/vault/physical/raft"
+)
+
+type raftConfigBackend struct {
+	servers []*raft.RaftServer
+}
+
+func (b raftConfigBackend) GetConfigurationOffline() (*raft.RaftConfigurationResponse, error) {
+	return &raft.RaftConfigurationResponse{Servers: b.servers}, nil
+}
+
+// testListenerAddrs returns the address of a listening socket, and the address
+// of a socket that was closed again.
+func testListenerAddrs(t *testing.T) (string, string) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	return ln.Addr().String(), closedAddr
+}
+
+func TestDialAddress(t *testing.T) {
+	open, closed := testListenerAddrs(t)
+	ctx := context.Background()
+
+	if err := DialAddress(ctx, open, time.Second); err != nil {
+		t.Fatalf("expected %s to be reachable: %v", open, err)
+	}
+	if err := DialAddress(ctx, "https://"+open+"/v1/", time.Second); err != nil {
+		t.Fatalf("expected URL of %s to be reachable: %v", open, err)
+	}
+	if err := DialAddress(ctx, closed, time.Second); err == nil {
+		t.Fatalf("expected %s to be unreachable", closed)
+	}
+}
+
+func TestRaftPeerConnectivity(t *testing.T) {
+	open, closed := testListenerAddrs(t)
+
+	b := raftConfigBackend{servers: []*raft.RaftServer{
+		{NodeID: "local", Address: "127.0.0.1:1"},
+		{NodeID: "up", Address: open},
+		{NodeID: "down", Address: closed},
+	}}
+	unreachable := RaftPeerConnectivity(context.Background(), b, "local")
+	if !reflect.DeepEqual(unreachable, []string{closed}) {
+		t.Fatalf("expected only %s to be unreachable, got %v", closed, unreachable)
+	}
+}
+
+func TestSealReachabilityChecks(t *testing.T) {
+	open, closed := testListenerAddrs(t)
+	ctx := context.Background()
+
+	if err := SealReachabilityChecks(ctx, []*configutil.KMS{{Type: "shamir"}}); err != nil {
+		t.Fatalf("expected seals without endpoints to be skipped: %v", err)
+	}
+
+	seals := []*configutil.KMS{{Type: "transit", Config: map[string]string{"address": "http://" + open}}}
+	if err := SealReachabilityChecks(ctx, seals); err != nil {
+		t.Fatalf("expected transit seal to be reachable: %v", err)
+	}
+
+	seals = append(seals, &configutil.KMS{Type: "awskms", Config: map[string]string{"endpoint": "https://" + closed}})
+	err := SealReachabilityChecks(ctx, seals)
+	if err == nil || !strings.Contains(err.Error(), "endpoint https://"+closed+" of the awskms seal is not reachable") {
+		t.Fatalf("expected awskms seal to be unreachable, got %v", err)
+	}
+}
+
+func TestTimeSkew(t *testing.T) {
+	start := time.Now()
+	end := start.Add(100 * time.Millisecond)
+
+	if skew := TimeSkew(context.Background(), start.Add(50*time.Millisecond), start, end); skew != 0 {
+		t.Fatalf("expected no skew, got %s", skew)
+	}
+	if skew := TimeSkew(context.Background(), start.Add(-time.Minute), start, end); skew != time.Minute+50*time.Millisecond {
+		t.Fatalf("expected skew of 1m0.05s, got %s", skew)
+	}
+}
+
+func TestAuditFileWritable(t *testing.T) {
+	dir := t.TempDir()
+
+	existing := filepath.Join(dir, "audit.log")
+	if err := os.WriteFile(existing, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := AuditFileWritable(existing); err != nil {
+		t.Fatalf("expected existing file to be writable: %v", err)
+	}
+	if err := AuditFileWritable(filepath.Join(dir, "new.log")); err != nil {
+		t.Fatalf("expected new file to be creatable: %v", err)
+	}
+	if err := AuditFileWritable(filepath.Join(dir, "missing", "audit.log")); err == nil {
+		t.Fatal("expected a missing directory to fail")
+	}
+}
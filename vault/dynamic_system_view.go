@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/go-kms-wrapping/entropy/v2"
+	"github.com/hashicorp/go-uuid"
 	"github.com/hashicorp/vault/helper/identity"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/helper/random"
@@ -43,6 +45,27 @@ type extendedSystemViewImpl struct {
 	dynamicSystemView
 }
 
+// entropySystemViewImpl is the system view of mounts with external entropy
+// access when entropy augmentation is configured. Implementing
+// entropy.Sourcer makes the backend's GetRandomReader use the core's
+// augmented entropy source for generating key material.
+type entropySystemViewImpl struct {
+	extendedSystemViewImpl
+}
+
+var _ entropy.Sourcer = entropySystemViewImpl{}
+
+func (e entropySystemViewImpl) GetRandom(bytes int) ([]byte, error) {
+	return uuid.GenerateRandomBytesWithReader(bytes, e.core.secureRandomReader)
+}
+
+// entropyAugmentationEnabled returns whether the core's secure random reader
+// mixes in an external entropy source.
+func (c *Core) entropyAugmentationEnabled() bool {
+	_, ok := c.secureRandomReader.(entropy.Sourcer)
+	return ok
+}
+
 func (e extendedSystemViewImpl) Auditor() logical.Auditor {
 	return genericAuditor{
 		mountType: e.mountEntry.Type,
@@ -275,6 +298,9 @@ func (d dynamicSystemView) LookupPluginVersion(ctx context.Context, name string,
 		}
 		return nil, fmt.Errorf("%w: %s", ErrPluginNotFound, errContext)
 	}
+	if err := d.core.installMissingPlugin(ctx, r); err != nil {
+		return nil, err
+	}
 
 	return r, nil
 }
@@ -5,6 +5,7 @@ package vault
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"reflect"
@@ -12,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-kms-wrapping/entropy/v2"
 	ldapcred "github.com/hashicorp/vault/builtin/credential/ldap"
 	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/sdk/framework"
@@ -312,3 +314,61 @@ func (b fakeBarrier) Put(context.Context, *logical.StorageEntry) error {
 func (b fakeBarrier) Delete(context.Context, string) error {
 	return fmt.Errorf("not implemented")
 }
+
+type testEntropyReader struct{}
+
+func (testEntropyReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0x42
+	}
+	return len(p), nil
+}
+
+func (r testEntropyReader) GetRandom(bytes int) ([]byte, error) {
+	buf := make([]byte, bytes)
+	_, err := r.Read(buf)
+	return buf, err
+}
+
+func TestMountEntrySysView_ExternalEntropyAccess(t *testing.T) {
+	augmented := &Core{secureRandomReader: testEntropyReader{}}
+	platform := &Core{secureRandomReader: rand.Reader}
+
+	cases := map[string]struct {
+		core       *Core
+		entry      *MountEntry
+		wantSource bool
+	}{
+		"no access":       {core: augmented, entry: &MountEntry{Type: "transit"}},
+		"no augmentation": {core: platform, entry: &MountEntry{Type: "transit", ExternalEntropyAccess: true}},
+		"access":          {core: augmented, entry: &MountEntry{Type: "transit", ExternalEntropyAccess: true}, wantSource: true},
+		"pki no access":   {core: augmented, entry: &MountEntry{Type: "pki"}},
+		"pki access":      {core: augmented, entry: &MountEntry{Type: "pki", ExternalEntropyAccess: true}, wantSource: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			sysView := tc.core.mountEntrySysView(tc.entry)
+			if tc.entry.Type == "pki" {
+				if _, ok := sysView.(logical.ACMEBillingSystemView); !ok {
+					t.Fatal("expected an ACME billing system view for pki")
+				}
+			}
+
+			sourcer, ok := sysView.(entropy.Sourcer)
+			if ok != tc.wantSource {
+				t.Fatalf("expected entropy source: %t, got: %t", tc.wantSource, ok)
+			}
+			if !ok {
+				return
+			}
+			random, err := sourcer.GetRandom(4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(random, []byte{0x42, 0x42, 0x42, 0x42}) {
+				t.Fatalf("expected bytes from the core's reader, got %x", random)
+			}
+		})
+	}
+}
@@ -183,6 +183,49 @@ func TestCore_EnableExternalPlugin_MultipleVersions(t *testing.T) {
 	}
 }
 
+// TestCore_DeregisterPinnedPluginVersion_Warns tests that deregistering a
+// plugin version which mounts are pinned to warns about those mounts.
+func TestCore_DeregisterPinnedPluginVersion_Warns(t *testing.T) {
+	for _, pluginType := range []consts.PluginType{consts.PluginTypeCredential, consts.PluginTypeSecrets} {
+		t.Run(pluginType.String(), func(t *testing.T) {
+			c, plugins := testCoreWithPlugins(t, pluginType, "")
+			for _, version := range []string{"v1.0.0", "v1.0.1"} {
+				registerPlugin(t, c.systemBackend, plugins[0].Name, pluginType.String(), version, plugins[0].Sha256, plugins[0].FileName)
+			}
+			mountPlugin(t, c.systemBackend, plugins[0].Name, pluginType, "v1.0.0", "")
+
+			deregister := func(version string) *logical.Response {
+				t.Helper()
+				req := logical.TestRequest(t, logical.DeleteOperation, fmt.Sprintf("plugins/catalog/%s/%s", pluginType, plugins[0].Name))
+				req.Data = map[string]interface{}{
+					"version": version,
+				}
+				resp, err := c.systemBackend.HandleRequest(namespace.RootContext(nil), req)
+				if err != nil || (resp != nil && resp.IsError()) {
+					t.Fatalf("err:%v resp:%#v", err, resp)
+				}
+				return resp
+			}
+
+			if resp := deregister("v1.0.1"); resp != nil && len(resp.Warnings) > 0 {
+				t.Fatalf("expected no warnings for an unused version, got %v", resp.Warnings)
+			}
+
+			resp := deregister("v1.0.0")
+			if resp == nil || len(resp.Warnings) != 1 {
+				t.Fatalf("expected a warning for a pinned version, got %#v", resp)
+			}
+			expected := mountTable(pluginType)
+			if pluginType == consts.PluginTypeSecrets {
+				expected = "foo"
+			}
+			if !strings.Contains(resp.Warnings[0], expected+"/") {
+				t.Fatalf("expected warning to name the %q mount, got %q", expected, resp.Warnings[0])
+			}
+		})
+	}
+}
+
 func TestCore_EnableExternalPlugin_Deregister_SealUnseal(t *testing.T) {
 	pluginDir, cleanup := corehelpers.MakeTestPluginDir(t)
 	t.Cleanup(func() { cleanup(t) })
@@ -26,6 +26,9 @@ type InitParams struct {
 	BarrierConfig   *SealConfig
 	RecoveryConfig  *SealConfig
 	RootTokenPGPKey string
+	// BarrierAlgorithm is the algorithm used to encrypt data in the barrier.
+	// It defaults to BarrierAlgorithmAESGCM.
+	BarrierAlgorithm string
 	// LegacyShamirSeal should only be used in test code, we don't want to
 	// give the user a way to create legacy shamir seals.
 	LegacyShamirSeal bool
@@ -165,6 +168,12 @@ func (c *Core) Initialize(ctx context.Context, initParams *InitParams) (*InitRes
 		return nil, err
 	}
 
+	if initParams.BarrierAlgorithm != "" {
+		if err := ValidateBarrierAlgorithm(initParams.BarrierAlgorithm); err != nil {
+			return nil, err
+		}
+	}
+
 	atomic.StoreUint32(&initInProgress, 1)
 	defer atomic.StoreUint32(&initInProgress, 0)
 	barrierConfig := initParams.BarrierConfig
@@ -287,12 +296,16 @@ func (c *Core) Initialize(ctx context.Context, initParams *InitParams) (*InitRes
 	}
 
 	// Initialize the barrier
-	if err := c.barrier.Initialize(ctx, barrierKey, sealKey, c.secureRandomReader); err != nil {
+	barrierAlgorithm := initParams.BarrierAlgorithm
+	if barrierAlgorithm == "" {
+		barrierAlgorithm = BarrierAlgorithmAESGCM
+	}
+	if err := c.barrier.InitializeWithAlgorithm(ctx, barrierKey, sealKey, c.secureRandomReader, barrierAlgorithm); err != nil {
 		c.logger.Error("failed to initialize barrier", "error", err)
 		return nil, fmt.Errorf("failed to initialize barrier: %w", err)
 	}
 	if c.logger.IsInfo() {
-		c.logger.Info("security barrier initialized", "stored", barrierConfig.StoredShares, "shares", barrierConfig.SecretShares, "threshold", barrierConfig.SecretThreshold)
+		c.logger.Info("security barrier initialized", "stored", barrierConfig.StoredShares, "shares", barrierConfig.SecretShares, "threshold", barrierConfig.SecretThreshold, "algorithm", barrierAlgorithm)
 	}
 
 	// Unseal the barrier
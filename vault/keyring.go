@@ -58,6 +58,11 @@ type Key struct {
 	Value       []byte
 	InstallTime time.Time
 	Encryptions uint64 `json:"encryptions,omitempty"`
+
+	// Algorithm is the AEAD used with the key. It is empty for AES-GCM, so
+	// that keyrings without other algorithms remain readable by older
+	// versions.
+	Algorithm string `json:"algorithm,omitempty"`
 }
 
 type KeyRotationConfig struct {
@@ -31,6 +31,10 @@ type RawBackend struct {
 	logger       log.Logger
 	checkRaw     func(path string) error
 	recoveryMode bool
+
+	// invalidate drops a written path from the read cache of the mount
+	// owning it, as raw writes bypass the mount's storage view.
+	invalidate func(path string)
 }
 
 func NewRawBackend(core *Core) *RawBackend {
@@ -41,6 +45,7 @@ func NewRawBackend(core *Core) *RawBackend {
 			return nil
 		},
 		recoveryMode: core.recoveryMode,
+		invalidate:   core.invalidateReadCache,
 	}
 	r.Backend = &framework.Backend{
 		Paths: rawPaths("sys/", r),
@@ -226,6 +231,7 @@ func (b *RawBackend) handleRawWrite(ctx context.Context, req *logical.Request, d
 	if err := b.barrier.Put(ctx, entry); err != nil {
 		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
 	}
+	b.invalidate(path)
 	return nil, nil
 }
 
@@ -248,6 +254,7 @@ func (b *RawBackend) handleRawDelete(ctx context.Context, req *logical.Request,
 	if err := b.barrier.Delete(ctx, path); err != nil {
 		return handleErrorNoReadOnlyForward(err)
 	}
+	b.invalidate(path)
 	return nil, nil
 }
 
@@ -102,6 +102,7 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"remount",
 				"audit",
 				"audit/*",
+				"audit-tail",
 				"raw",
 				"raw/*",
 				"replication/primary/secondary-token",
@@ -115,6 +116,7 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"config/auditing/*",
 				"config/ui/headers/*",
 				"plugins/catalog/*",
+				"plugins/install",
 				"plugins/runtimes/catalog/*",
 				"revoke-prefix/*",
 				"revoke-force/*",
@@ -124,6 +126,8 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 				"storage/raft/snapshot-auto/config/*",
 				"leases",
 				"internal/inspect/*",
+				"migration/export",
+				"migration/import",
 				// sys/seal and sys/step-down actually have their sudo requirement enforced through hardcoding
 				// PolicyCheckOpts.RootPrivsRequired in dedicated calls to Core.performPolicyChecks, but we still need
 				// to declare them here so that the generated OpenAPI spec gets their sudo status correct.
@@ -189,12 +193,14 @@ func NewSystemBackend(core *Core, logger log.Logger) *SystemBackend {
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsCatalogListPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsCatalogCRUDPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsReloadPath())
+	b.Backend.Paths = append(b.Backend.Paths, b.pluginsInstallPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsRuntimesCatalogCRUDPath())
 	b.Backend.Paths = append(b.Backend.Paths, b.pluginsRuntimesCatalogListPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.auditPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.mountPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.authPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.lockedUserPaths()...)
+	b.Backend.Paths = append(b.Backend.Paths, b.migrationPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.leasePaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.policyPaths()...)
 	b.Backend.Paths = append(b.Backend.Paths, b.wrappingPaths()...)
@@ -241,6 +247,7 @@ func (b *SystemBackend) rawPaths() []*framework.Path {
 		checkRaw: func(path string) error {
 			return checkRaw(b, path)
 		},
+		invalidate: b.Core.invalidateReadCache,
 	}
 	return rawPaths("", r)
 }
@@ -539,6 +546,11 @@ func (b *SystemBackend) handlePluginCatalogUpdate(ctx context.Context, _ *logica
 		return logical.ErrorResponse("must provide at least one of command or oci_image"), nil
 	}
 
+	pluginRuntime := d.Get("runtime").(string)
+	if pluginRuntime != "" && ociImage == "" {
+		return logical.ErrorResponse("runtime can only be specified with oci_image"), nil
+	}
+
 	if ociImage == "" {
 		if err = b.Core.CheckPluginPerms(command); err != nil {
 			return nil, err
@@ -576,12 +588,13 @@ func (b *SystemBackend) handlePluginCatalogUpdate(ctx context.Context, _ *logica
 		Version:  pluginVersion,
 		Command:  command,
 		OCIImage: ociImage,
+		Runtime:  pluginRuntime,
 		Args:     args,
 		Env:      env,
 		Sha256:   sha256Bytes,
 	})
 	if err != nil {
-		if errors.Is(err, ErrPluginNotFound) || strings.HasPrefix(err.Error(), "plugin version mismatch") {
+		if errors.Is(err, ErrPluginNotFound) || errors.Is(err, ErrPluginRuntimeNotFound) || strings.HasPrefix(err.Error(), "plugin version mismatch") {
 			return logical.ErrorResponse(err.Error()), nil
 		}
 		return nil, err
@@ -652,6 +665,10 @@ func (b *SystemBackend) handlePluginCatalogRead(ctx context.Context, _ *logical.
 		data["oci_image"] = plugin.OCIImage
 	}
 
+	if plugin.Runtime != "" {
+		data["runtime"] = plugin.Runtime
+	}
+
 	return &logical.Response{
 		Data: data,
 	}, nil
@@ -691,6 +708,16 @@ func (b *SystemBackend) handlePluginCatalogDelete(ctx context.Context, _ *logica
 		return nil, err
 	}
 
+	// Mounts pinned to the version keep running it until they are reloaded,
+	// after which they will fail to start. Warn so that they can be tuned to
+	// another version first.
+	if pinned := b.Core.mountsPinnedToPluginVersion(pluginType, pluginName, pluginVersion); len(pinned) > 0 {
+		if resp == nil {
+			resp = new(logical.Response)
+		}
+		resp.AddWarning(fmt.Sprintf("Version %q of plugin %q is still pinned by the following mounts, which will fail to start until tuned to another version with plugin_version: %s", pluginVersion, pluginName, strings.Join(pinned, ", ")))
+	}
+
 	return resp, nil
 }
 
@@ -815,6 +842,14 @@ func (b *SystemBackend) handlePluginRuntimeCatalogDelete(ctx context.Context, _
 		return logical.ErrorResponse(err.Error()), nil
 	}
 
+	plugins, err := b.Core.pluginCatalog.ListPluginsUsingRuntime(ctx, runtimeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(plugins) > 0 {
+		return logical.ErrorResponse("plugin runtime %q is in use by the following plugins and cannot be deleted: %s", runtimeName, strings.Join(plugins, ", ")), nil
+	}
+
 	err = b.Core.pluginRuntimeCatalog.Delete(ctx, runtimeName, runtimeType)
 	if err != nil {
 		return nil, err
@@ -1160,6 +1195,9 @@ func (b *SystemBackend) mountInfo(ctx context.Context, entry *MountEntry) map[st
 	if rawVal, ok := entry.synthesizedConfigCache.Load("allowed_managed_keys"); ok {
 		entryConfig["allowed_managed_keys"] = rawVal.([]string)
 	}
+	if entry.Config.ReadCacheSize > 0 {
+		entryConfig["read_cache_size"] = entry.Config.ReadCacheSize
+	}
 	if entry.Table == credentialTableType {
 		entryConfig["token_type"] = entry.Config.TokenType.String()
 	}
@@ -1377,6 +1415,10 @@ func (b *SystemBackend) handleMount(ctx context.Context, req *logical.Request, d
 	if len(apiConfig.AllowedManagedKeys) > 0 {
 		config.AllowedManagedKeys = apiConfig.AllowedManagedKeys
 	}
+	if apiConfig.ReadCacheSize < 0 {
+		return logical.ErrorResponse("read_cache_size cannot be negative"), logical.ErrInvalidRequest
+	}
+	config.ReadCacheSize = apiConfig.ReadCacheSize
 
 	// Create the mount entry
 	me := &MountEntry{
@@ -1834,6 +1876,10 @@ func (b *SystemBackend) handleTuneReadCommon(ctx context.Context, path string) (
 		resp.Data["allowed_managed_keys"] = rawVal.([]string)
 	}
 
+	if mountEntry.Config.ReadCacheSize > 0 {
+		resp.Data["read_cache_size"] = mountEntry.Config.ReadCacheSize
+	}
+
 	if mountEntry.Config.UserLockoutConfig != nil {
 		resp.Data["user_lockout_counter_reset_duration"] = int64(mountEntry.Config.UserLockoutConfig.LockoutCounterReset.Seconds())
 		resp.Data["user_lockout_threshold"] = mountEntry.Config.UserLockoutConfig.LockoutThreshold
@@ -2321,6 +2367,38 @@ func (b *SystemBackend) handleTuneWriteCommon(ctx context.Context, path string,
 		}
 	}
 
+	if rawVal, ok := data.GetOk("read_cache_size"); ok {
+		if strings.HasPrefix(path, credentialRoutePrefix) || strutil.StrListContains(singletonMounts, mountEntry.Type) {
+			return logical.ErrorResponse("'read_cache_size' can only be set on secrets engine mounts"), logical.ErrInvalidRequest
+		}
+		readCacheSize := rawVal.(int)
+		if readCacheSize < 0 {
+			return logical.ErrorResponse("read_cache_size cannot be negative"), logical.ErrInvalidRequest
+		}
+
+		view, ok := b.Core.router.MatchingStorageByAPIPath(ctx, path).(*BarrierView)
+		if !ok {
+			return handleError(fmt.Errorf("cannot fetch storage view for path %q", path))
+		}
+
+		oldVal := mountEntry.Config.ReadCacheSize
+		mountEntry.Config.ReadCacheSize = readCacheSize
+
+		// Update the mount table
+		if err := b.Core.persistMounts(ctx, b.Core.mounts, &mountEntry.Local); err != nil {
+			mountEntry.Config.ReadCacheSize = oldVal
+			return handleError(err)
+		}
+
+		if err := b.Core.configureReadCache(mountEntry, view); err != nil {
+			return handleError(err)
+		}
+
+		if b.Core.logger.IsInfo() {
+			b.Core.logger.Info("mount tuning of read_cache_size successful", "path", path)
+		}
+	}
+
 	var err error
 	var resp *logical.Response
 	var options map[string]string
@@ -3413,6 +3491,77 @@ func (b *SystemBackend) handleAuditHash(ctx context.Context, req *logical.Reques
 	}, nil
 }
 
+// handleAuditTail streams audit entries matching the requested filters until
+// the client disconnects or the core is sealed.
+func (b *SystemBackend) handleAuditTail(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	w := req.ResponseWriter
+	if w == nil {
+		return logical.ErrorResponse("streaming not supported"), nil
+	}
+
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		// http.ResponseWriter is wrapped in wrapGenericHandler, so let's
+		// access the underlying functionality
+		nw, ok := w.ResponseWriter.(logical.WrappingResponseWriter)
+		if !ok {
+			return logical.ErrorResponse("streaming not supported"), nil
+		}
+		flusher, ok = nw.Wrapped().(http.Flusher)
+		if !ok {
+			return logical.ErrorResponse("streaming not supported"), nil
+		}
+	}
+
+	filters := data.Get("filter").([]string)
+	device := data.Get("device").(string)
+	if device != "" {
+		device = sanitizePath(device)
+	}
+
+	id, tailer, err := b.Core.auditBroker.addTailer(filters, device)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	defer b.Core.auditBroker.removeTailer(id)
+
+	w.WriteHeader(http.StatusOK)
+
+	// 0 byte write is needed before the Flush call so that if we are using
+	// a gzip stream it will go ahead and write out the HTTP response header
+	_, err = w.Write([]byte(""))
+	if err != nil {
+		return nil, fmt.Errorf("error seeding flusher: %w", err)
+	}
+
+	flusher.Flush()
+
+	ticker := time.NewTicker(1 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if b.Core.Sealed() {
+				return nil, nil
+			}
+			if dropped := tailer.takeDropped(); dropped > 0 {
+				b.logger.Warn("audit tail session dropped entries", "dropped", dropped)
+			}
+		case <-ctx.Done():
+			return nil, nil
+		case entry := <-tailer.ch:
+			// Errors are ignored upstream as the response has already been
+			// started by writing the header and flushing the writer above.
+			if _, err := fmt.Fprintf(w, "%s\n", entry); err != nil {
+				return nil, fmt.Errorf("error streaming audit entries: %w", err)
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
 // handleEnableAudit is used to enable a new audit backend
 func (b *SystemBackend) handleEnableAudit(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	repState := b.Core.ReplicationState()
@@ -3586,6 +3735,7 @@ func (b *SystemBackend) handleKeyStatus(ctx context.Context, req *logical.Reques
 			"term":         info.Term,
 			"install_time": info.InstallTime.Format(time.RFC3339Nano),
 			"encryptions":  info.Encryptions,
+			"algorithm":    info.Algorithm,
 		},
 	}
 	return resp, nil
@@ -3661,13 +3811,24 @@ func (b *SystemBackend) handleKeyRotationConfigUpdate(ctx context.Context, req *
 }
 
 // handleRotate is used to trigger a key rotation
-func (b *SystemBackend) handleRotate(ctx context.Context, _ *logical.Request, _ *framework.FieldData) (*logical.Response, error) {
+func (b *SystemBackend) handleRotate(ctx context.Context, _ *logical.Request, data *framework.FieldData) (*logical.Response, error) {
 	repState := b.Core.ReplicationState()
 	if repState.HasState(consts.ReplicationPerformanceSecondary) {
 		return logical.ErrorResponse("cannot rotate on a replication secondary"), nil
 	}
 
-	if err := b.rotateBarrierKey(ctx); err != nil {
+	// Automatic rotations have no request data and keep the algorithm.
+	var algorithm string
+	if data != nil {
+		algorithm = data.Get("algorithm").(string)
+		if algorithm != "" {
+			if err := ValidateBarrierAlgorithm(algorithm); err != nil {
+				return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+			}
+		}
+	}
+
+	if err := b.rotateBarrierKey(ctx, algorithm); err != nil {
 		b.Backend.Logger().Error("error handling key rotation", "error", err)
 		return handleError(err)
 	}
@@ -4807,7 +4968,7 @@ func (b *SystemBackend) pathInternalOpenAPI(ctx context.Context, req *logical.Re
 
 				// Add tags to all of the operations if necessary
 				if tag != "" {
-					for _, op := range []*framework.OASOperation{obj.Get, obj.Post, obj.Delete} {
+					for _, op := range []*framework.OASOperation{obj.Get, obj.Post, obj.Patch, obj.Delete} {
 						// TODO: a special override for identity is used used here because the backend
 						// is currently categorized as "secret", which will likely change. Also of interest
 						// is removing all tag handling here and providing the mount information to OpenAPI.
@@ -5116,9 +5277,11 @@ func (b *SystemBackend) verifyDROperationTokenOnSecondary(f framework.OperationF
 	return f
 }
 
-func (b *SystemBackend) rotateBarrierKey(ctx context.Context) error {
+// rotateBarrierKey installs a new barrier encryption key using the given
+// algorithm, or the algorithm of the active key if it is empty.
+func (b *SystemBackend) rotateBarrierKey(ctx context.Context, algorithm string) error {
 	// Rotate to the new term
-	newTerm, err := b.Core.barrier.Rotate(ctx, b.Core.secureRandomReader)
+	newTerm, err := b.Core.barrier.RotateWithAlgorithm(ctx, b.Core.secureRandomReader, algorithm)
 	if err != nil {
 		return errwrap.Wrap(errors.New("failed to create new encryption key"), err)
 	}
@@ -5651,6 +5814,47 @@ in the plugin catalog.`,
 		`The user lockout configuration to pass into the backend. Should be a json object with string keys and values.`,
 	},
 
+	"tune_read_cache_size": {
+		`The number of decrypted storage entries of the mount to keep in memory. Set to 0 to disable the cache.`,
+	},
+
+	"migration-export": {
+		"Export the data of a mount to an encrypted archive.",
+		`
+This path responds to the following HTTP methods.
+
+    POST /
+        Export all the storage entries of the given mount, encrypted with a
+        key derived from the given passphrase. The archive can be imported
+        into a mount of the same type, on this or another cluster.
+		`,
+	},
+
+	"migration-import": {
+		"Replace the data of a mount with the content of an encrypted archive.",
+		`
+This path responds to the following HTTP methods.
+
+    POST /
+        Decrypt the given archive, replace all the storage entries of the
+        given mount with its content and reload the mount. The mount must be
+        of the same type as the exported mount. Should any write fail, the
+        previous data of the mount is restored.
+		`,
+	},
+
+	"migration_mount": {
+		`The path of the mount, such as "pki/" or "auth/approle/".`,
+	},
+
+	"migration_passphrase": {
+		`The passphrase from which the archive encryption key is derived.`,
+	},
+
+	"migration_archive": {
+		`The base64 encoded archive returned by sys/migration/export.`,
+	},
+
 	"remount": {
 		"Move the mount point of an already-mounted backend, within or across namespaces",
 		`
@@ -5898,6 +6102,26 @@ or delete a policy.
 		"",
 	},
 
+	"audit-tail": {
+		"Stream hashed audit entries from this node as they are logged.",
+		`
+This path streams request and response audit entries, formatted as JSON with
+one entry per line, as they are logged by this node. Sensitive values are
+HMAC'd using the salt of an enabled audit device, so at least one audit device
+must be enabled. Entries can be narrowed down with filter expressions.
+		`,
+	},
+
+	"audit-tail-filter": {
+		`Filter expressions of the form "<field> <operator> <value>" which must all match for an entry to be streamed. Supported fields are "type", "path", "operation", "mount_point", "mount_type", "namespace", "remote_address" and "error". Supported operators are "==", "!=" and "matches", the latter accepting "*" wildcards.`,
+		"",
+	},
+
+	"audit-tail-device": {
+		`The path of the audit device whose salt is used to hash entries. Defaults to the first enabled device.`,
+		"",
+	},
+
 	"audit-table": {
 		"List the currently enabled audit backends.",
 		`
@@ -5980,9 +6204,18 @@ Enable a new audit backend or disable an existing backend.
 		Rotate generates a new encryption key which is used to encrypt all
 		data going to the storage backend. The old encryption keys are kept so
 		that data encrypted using those keys can still be decrypted.
+
+		The new key uses the same algorithm as the active key, unless another
+		algorithm is given. Existing data is not re-encrypted: it keeps the key
+		and algorithm it was written with until it is next written.
 		`,
 	},
 
+	"rotate-algorithm": {
+		`The algorithm of the new encryption key, "aes256-gcm96" or "chacha20-poly1305". Defaults to the algorithm of the active key.`,
+		"",
+	},
+
 	"rekey_backup": {
 		"Allows fetching or deleting the backup of the rotated unseal keys.",
 		"",
@@ -6123,6 +6356,11 @@ Each entry is of the form "key=value".`,
 Must already be present on the machine.`,
 		"",
 	},
+	"plugin-catalog_runtime": {
+		`The name of the plugin runtime to run the plugin's container with.
+Only valid if oci_image is provided.`,
+		"",
+	},
 	"plugin-runtime-catalog": {
 		"Configures plugin runtimes",
 		`
@@ -6185,6 +6423,28 @@ This path responds to the following HTTP methods.
 		`The path to list leases under. Example: "aws/creds/deploy"`,
 		"",
 	},
+	"plugin-install": {
+		"Download, verify and register a plugin.",
+		`Downloads a plugin binary from the configured plugin registry or the given
+URL, verifies its detached Ed25519 signature against the configured plugin
+signing keys, writes it to the plugin directory and registers it in the
+plugin catalog.`,
+	},
+	"plugin-install_url": {
+		`The URL to download the plugin binary from. Defaults to the plugin's
+location in the configured plugin registry.`,
+		"",
+	},
+	"plugin-install_signature_url": {
+		`The URL to download the plugin's detached signature from. Defaults to
+the plugin URL with a .sig suffix.`,
+		"",
+	},
+	"plugin-install_sha256": {
+		`The expected SHA256 sum of the plugin binary. If given, the download
+is rejected when it does not match.`,
+		"",
+	},
 	"plugin-reload": {
 		"Reload mounts that use a particular backend plugin.",
 		`Reload mounts that use a particular backend plugin. Either the plugin name
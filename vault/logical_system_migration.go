@@ -0,0 +1,480 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// migrationArchiveVersion is the version of the format of mount
+	// migration archives.
+	migrationArchiveVersion = 1
+
+	// Parameters of the argon2id derivation of the archive key from the
+	// passphrase.
+	migrationKDFTime    = 3
+	migrationKDFMemory  = 64 * 1024
+	migrationKDFThreads = 4
+	migrationSaltSize   = 16
+
+	// migrationMaxPayloadSize bounds the size of the data of an archive, as
+	// archives are built and opened in memory. The encoded payload is allowed
+	// twice that, for the base64 encoding of the values.
+	migrationMaxPayloadSize = 256 * 1024 * 1024
+
+	// migrationBackupSubPath is where the data of a mount is kept while an
+	// archive is imported into it, so that it can be restored should the
+	// import fail.
+	migrationBackupSubPath = "migration-backup/"
+)
+
+// migrationArchive is the envelope of an exported mount. The payload is
+// encrypted with AES-256-GCM, with a key derived from a passphrase, which
+// also protects it from tampering.
+type migrationArchive struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// migrationPayload is the content of a mount migration archive.
+type migrationPayload struct {
+	Type       string                  `json:"type"`
+	Path       string                  `json:"path"`
+	ExportTime time.Time               `json:"export_time"`
+	Entries    []*logical.StorageEntry `json:"entries"`
+}
+
+// migrationPaths returns the paths used to export the data of a mount to an
+// encrypted archive and to import it into another mount.
+func (b *SystemBackend) migrationPaths() []*framework.Path {
+	return []*framework.Path{
+		{
+			Pattern: "migration/export$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "mount-migration",
+				OperationVerb:   "export",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"mount": {
+					Type:        framework.TypeString,
+					Required:    true,
+					Description: strings.TrimSpace(sysHelp["migration_mount"][0]),
+				},
+				"passphrase": {
+					Type:        framework.TypeString,
+					Required:    true,
+					Description: strings.TrimSpace(sysHelp["migration_passphrase"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleMigrationExport,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"archive": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"type": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"entries": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Export the data of a mount to an encrypted archive.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["migration-export"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["migration-export"][1]),
+		},
+		{
+			Pattern: "migration/import$",
+
+			DisplayAttrs: &framework.DisplayAttributes{
+				OperationPrefix: "mount-migration",
+				OperationVerb:   "import",
+			},
+
+			Fields: map[string]*framework.FieldSchema{
+				"mount": {
+					Type:        framework.TypeString,
+					Required:    true,
+					Description: strings.TrimSpace(sysHelp["migration_mount"][0]),
+				},
+				"passphrase": {
+					Type:        framework.TypeString,
+					Required:    true,
+					Description: strings.TrimSpace(sysHelp["migration_passphrase"][0]),
+				},
+				"archive": {
+					Type:        framework.TypeString,
+					Required:    true,
+					Description: strings.TrimSpace(sysHelp["migration_archive"][0]),
+				},
+			},
+
+			Operations: map[logical.Operation]framework.OperationHandler{
+				logical.UpdateOperation: &framework.PathOperation{
+					Callback: b.handleMigrationImport,
+					Responses: map[int][]framework.Response{
+						http.StatusOK: {{
+							Description: "OK",
+							Fields: map[string]*framework.FieldSchema{
+								"type": {
+									Type:     framework.TypeString,
+									Required: true,
+								},
+								"entries": {
+									Type:     framework.TypeInt,
+									Required: true,
+								},
+							},
+						}},
+					},
+					Summary: "Replace the data of a mount with the content of an encrypted archive.",
+				},
+			},
+
+			HelpSynopsis:    strings.TrimSpace(sysHelp["migration-import"][0]),
+			HelpDescription: strings.TrimSpace(sysHelp["migration-import"][1]),
+		},
+	}
+}
+
+// migrationMount returns the mount entry and storage view of the mount
+// given in the request.
+func (b *SystemBackend) migrationMount(ctx context.Context, data *framework.FieldData) (*MountEntry, *BarrierView, error) {
+	path := sanitizePath(data.Get("mount").(string))
+	if path == "/" {
+		return nil, nil, errors.New("missing mount")
+	}
+
+	entry := b.Core.router.MatchingMountEntry(ctx, path)
+	if entry == nil {
+		return nil, nil, fmt.Errorf("no mount found at %q", path)
+	}
+	if strutil.StrListContains(singletonMounts, entry.Type) {
+		return nil, nil, fmt.Errorf("mounts of type %q cannot be migrated", entry.Type)
+	}
+	if entry.Tainted {
+		return nil, nil, fmt.Errorf("mount %q is being removed", path)
+	}
+
+	view, ok := b.Core.router.MatchingStorageByAPIPath(ctx, path).(*BarrierView)
+	if !ok {
+		return nil, nil, fmt.Errorf("no storage found for mount %q", path)
+	}
+
+	return entry, view, nil
+}
+
+// handleMigrationExport exports all the storage entries of a mount to an
+// encrypted archive.
+func (b *SystemBackend) handleMigrationExport(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	passphrase := data.Get("passphrase").(string)
+	if passphrase == "" {
+		return logical.ErrorResponse("missing passphrase"), logical.ErrInvalidRequest
+	}
+
+	entry, view, err := b.migrationMount(ctx, data)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	keys, err := logical.CollectKeys(ctx, view)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mount storage: %w", err)
+	}
+
+	payload := &migrationPayload{
+		Type:       entry.Type,
+		Path:       entry.APIPathNoNamespace(),
+		ExportTime: time.Now().UTC(),
+		Entries:    make([]*logical.StorageEntry, 0, len(keys)),
+	}
+	var size int
+	for _, key := range keys {
+		se, err := view.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q: %w", key, err)
+		}
+		if se == nil {
+			continue
+		}
+		size += len(se.Key) + len(se.Value)
+		if size > migrationMaxPayloadSize {
+			return logical.ErrorResponse("mount data exceeds the maximum archive size of %d bytes", migrationMaxPayloadSize), logical.ErrInvalidRequest
+		}
+		payload.Entries = append(payload.Entries, se)
+	}
+
+	archive, err := sealMigrationArchive(b.Core.secureRandomReader, payload, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("exported mount", "path", payload.Path, "type", payload.Type, "entries", len(payload.Entries))
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"archive": archive,
+			"type":    payload.Type,
+			"entries": len(payload.Entries),
+		},
+	}, nil
+}
+
+// handleMigrationImport replaces the storage of a mount with the entries of
+// an archive, and reloads its backend so that it picks up the new data.
+func (b *SystemBackend) handleMigrationImport(ctx context.Context, req *logical.Request, data *framework.FieldData) (*logical.Response, error) {
+	passphrase := data.Get("passphrase").(string)
+	if passphrase == "" {
+		return logical.ErrorResponse("missing passphrase"), logical.ErrInvalidRequest
+	}
+
+	entry, view, err := b.migrationMount(ctx, data)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if !entry.Local && b.Core.ReplicationState().HasState(consts.ReplicationPerformanceSecondary) {
+		return nil, logical.ErrReadOnly
+	}
+
+	payload, err := openMigrationArchive(data.Get("archive").(string), passphrase)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+	if payload.Type != entry.Type {
+		return logical.ErrorResponse("archive of a %q mount cannot be imported into a %q mount", payload.Type, entry.Type), logical.ErrInvalidRequest
+	}
+	if err := validateMigrationEntries(payload.Entries); err != nil {
+		return logical.ErrorResponse(err.Error()), logical.ErrInvalidRequest
+	}
+
+	// Reject requests to the mount while its data is replaced
+	path := entry.APIPathNoNamespace()
+	if err := b.Core.router.Taint(ctx, path); err != nil {
+		return nil, err
+	}
+	defer b.Core.router.Untaint(ctx, path)
+
+	// Keep the current data of the mount aside until the archive has been
+	// fully written, and put it back should any write fail, so that the
+	// mount is never left wiped or partially imported.
+	backup := b.Core.systemBarrierView.SubView(migrationBackupSubPath + entry.UUID + "/")
+	if err := logical.ClearView(ctx, backup); err != nil {
+		return nil, fmt.Errorf("failed to clear migration backup: %w", err)
+	}
+	if err := copyMigrationView(ctx, view, backup); err != nil {
+		return nil, fmt.Errorf("failed to back up mount storage: %w", err)
+	}
+
+	if err := importMigrationEntries(ctx, view, payload.Entries); err != nil {
+		if restoreErr := restoreMigrationBackup(ctx, backup, view); restoreErr != nil {
+			b.logger.Error("failed to restore mount data after a failed import, its data was kept in system storage", "path", path, "backup_path", migrationBackupSubPath+entry.UUID+"/", "error", restoreErr)
+			return nil, fmt.Errorf("failed to import archive: %w; restoring the previous data of the mount also failed: %v", err, restoreErr)
+		}
+		return nil, fmt.Errorf("failed to import archive, the previous data of the mount was restored: %w", err)
+	}
+
+	if err := logical.ClearView(ctx, backup); err != nil {
+		b.logger.Warn("failed to remove migration backup", "path", path, "error", err)
+	}
+
+	if err := b.Core.reloadMatchingPluginMounts(ctx, []string{path}); err != nil {
+		return nil, fmt.Errorf("failed to reload mount: %w", err)
+	}
+
+	b.logger.Info("imported mount", "path", path, "source_path", payload.Path, "type", payload.Type, "entries", len(payload.Entries))
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"type":    payload.Type,
+			"entries": len(payload.Entries),
+		},
+	}, nil
+}
+
+// validateMigrationEntries checks the entries of an archive before any data
+// of the target mount is touched.
+func validateMigrationEntries(entries []*logical.StorageEntry) error {
+	seen := make(map[string]struct{}, len(entries))
+	for _, se := range entries {
+		if se == nil || se.Key == "" || strings.HasPrefix(se.Key, "/") || strings.HasSuffix(se.Key, "/") {
+			return errors.New("archive contains an invalid storage entry")
+		}
+		if _, ok := seen[se.Key]; ok {
+			return fmt.Errorf("archive contains duplicate entries for %q", se.Key)
+		}
+		seen[se.Key] = struct{}{}
+	}
+	return nil
+}
+
+// importMigrationEntries replaces the content of a view with the given
+// entries.
+func importMigrationEntries(ctx context.Context, view *BarrierView, entries []*logical.StorageEntry) error {
+	if err := logical.ClearView(ctx, view); err != nil {
+		return fmt.Errorf("failed to clear mount storage: %w", err)
+	}
+	for _, se := range entries {
+		if err := view.Put(ctx, se); err != nil {
+			return fmt.Errorf("failed to write %q: %w", se.Key, err)
+		}
+	}
+	return nil
+}
+
+// restoreMigrationBackup replaces the content of a mount's view with its
+// backup, removing the backup once restored.
+func restoreMigrationBackup(ctx context.Context, backup, view *BarrierView) error {
+	if err := logical.ClearView(ctx, view); err != nil {
+		return err
+	}
+	if err := copyMigrationView(ctx, backup, view); err != nil {
+		return err
+	}
+	return logical.ClearView(ctx, backup)
+}
+
+// copyMigrationView copies the entries of a view one at a time.
+func copyMigrationView(ctx context.Context, from, to *BarrierView) error {
+	keys, err := logical.CollectKeys(ctx, from)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		se, err := from.Get(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to read %q: %w", key, err)
+		}
+		if se == nil {
+			continue
+		}
+		if err := to.Put(ctx, se); err != nil {
+			return fmt.Errorf("failed to write %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// migrationKey derives the key of an archive from its passphrase.
+func migrationKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, migrationKDFTime, migrationKDFMemory, migrationKDFThreads, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealMigrationArchive compresses and encrypts a payload, returning the
+// base64 encoded archive.
+func sealMigrationArchive(rand io.Reader, payload *migrationPayload, passphrase string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(payload); err != nil {
+		return "", fmt.Errorf("failed to encode archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	archive := &migrationArchive{
+		Version: migrationArchiveVersion,
+		Salt:    make([]byte, migrationSaltSize),
+	}
+	if _, err := io.ReadFull(rand, archive.Salt); err != nil {
+		return "", err
+	}
+	aead, err := migrationKey(passphrase, archive.Salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand, nonce); err != nil {
+		return "", err
+	}
+	archive.Ciphertext = aead.Seal(nonce, nonce, buf.Bytes(), nil)
+
+	raw, err := json.Marshal(archive)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// openMigrationArchive decrypts and decompresses a base64 encoded archive.
+func openMigrationArchive(encoded, passphrase string) (*migrationPayload, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	var archive migrationArchive
+	if err := json.Unmarshal(raw, &archive); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	if archive.Version != migrationArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+
+	aead, err := migrationKey(passphrase, archive.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(archive.Ciphertext) < aead.NonceSize() {
+		return nil, errors.New("archive is truncated")
+	}
+	nonce, ciphertext := archive.Ciphertext[:aead.NonceSize()], archive.Ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt archive, the passphrase is invalid or the archive was modified")
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(plaintext))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	// Bound the decompressed size, as the payload is decoded in memory.
+	lr := &io.LimitedReader{R: zr, N: 2*migrationMaxPayloadSize + 1}
+	var payload migrationPayload
+	if err := json.NewDecoder(lr).Decode(&payload); err != nil {
+		if lr.N <= 0 {
+			return nil, fmt.Errorf("archive exceeds the maximum size of %d bytes", migrationMaxPayloadSize)
+		}
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	return &payload, nil
+}
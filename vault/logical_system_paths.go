@@ -1529,9 +1529,48 @@ func (b *SystemBackend) auditHashPath() *framework.Path {
 	}
 }
 
+func (b *SystemBackend) auditTailPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "audit-tail$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "auditing",
+			OperationVerb:   "tail",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"filter": {
+				Type:        framework.TypeStringSlice,
+				Description: strings.TrimSpace(sysHelp["audit-tail-filter"][0]),
+				Query:       true,
+			},
+			"device": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["audit-tail-device"][0]),
+				Query:       true,
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.ReadOperation: &framework.PathOperation{
+				Callback: b.handleAuditTail,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+					}},
+				},
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["audit-tail"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["audit-tail"][1]),
+	}
+}
+
 func (b *SystemBackend) auditPaths() []*framework.Path {
 	return []*framework.Path{
 		b.auditHashPath(),
+		b.auditTailPath(),
 
 		{
 			Pattern: "audit$",
@@ -1816,6 +1855,14 @@ func (b *SystemBackend) sealPaths() []*framework.Path {
 				OperationVerb:   "rotate",
 			},
 
+			Fields: map[string]*framework.FieldSchema{
+				"algorithm": {
+					Type:          framework.TypeString,
+					Description:   strings.TrimSpace(sysHelp["rotate-algorithm"][0]),
+					AllowedValues: []interface{}{BarrierAlgorithmAESGCM, BarrierAlgorithmChaCha20Poly1305},
+				},
+			},
+
 			Callbacks: map[logical.Operation]framework.OperationFunc{
 				logical.UpdateOperation: b.handleRotate,
 			},
@@ -1882,6 +1929,10 @@ func (b *SystemBackend) pluginsCatalogCRUDPath() *framework.Path {
 				Type:        framework.TypeString,
 				Description: strings.TrimSpace(sysHelp["plugin-catalog_oci_image"][0]),
 			},
+			"runtime": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["plugin-catalog_runtime"][0]),
+			},
 		},
 
 		Operations: map[logical.Operation]framework.OperationHandler{
@@ -1942,6 +1993,11 @@ func (b *SystemBackend) pluginsCatalogCRUDPath() *framework.Path {
 								Description: strings.TrimSpace(sysHelp["plugin-catalog_oci_image"][0]),
 								Required:    false,
 							},
+							"runtime": {
+								Type:        framework.TypeString,
+								Description: strings.TrimSpace(sysHelp["plugin-catalog_runtime"][0]),
+								Required:    false,
+							},
 							"args": {
 								Type:        framework.TypeStringSlice,
 								Description: strings.TrimSpace(sysHelp["plugin-catalog_args"][0]),
@@ -2102,6 +2158,93 @@ func (b *SystemBackend) pluginsReloadPath() *framework.Path {
 	}
 }
 
+func (b *SystemBackend) pluginsInstallPath() *framework.Path {
+	return &framework.Path{
+		Pattern: "plugins/install$",
+
+		DisplayAttrs: &framework.DisplayAttributes{
+			OperationPrefix: "plugins",
+			OperationVerb:   "install",
+			OperationSuffix: "plugin",
+		},
+
+		Fields: map[string]*framework.FieldSchema{
+			"name": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["plugin-catalog_name"][0]),
+				Required:    true,
+			},
+			"type": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["plugin-catalog_type"][0]),
+				Required:    true,
+			},
+			"version": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["plugin-catalog_version"][0]),
+				Required:    true,
+			},
+			"url": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["plugin-install_url"][0]),
+			},
+			"signature_url": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["plugin-install_signature_url"][0]),
+			},
+			"sha256": {
+				Type:        framework.TypeString,
+				Description: strings.TrimSpace(sysHelp["plugin-install_sha256"][0]),
+			},
+			"args": {
+				Type:        framework.TypeStringSlice,
+				Description: strings.TrimSpace(sysHelp["plugin-catalog_args"][0]),
+			},
+			"env": {
+				Type:        framework.TypeStringSlice,
+				Description: strings.TrimSpace(sysHelp["plugin-catalog_env"][0]),
+			},
+		},
+
+		Operations: map[logical.Operation]framework.OperationHandler{
+			logical.UpdateOperation: &framework.PathOperation{
+				Callback: b.handlePluginInstall,
+				Responses: map[int][]framework.Response{
+					http.StatusOK: {{
+						Description: "OK",
+						Fields: map[string]*framework.FieldSchema{
+							"name": {
+								Type:     framework.TypeString,
+								Required: true,
+							},
+							"type": {
+								Type:     framework.TypeString,
+								Required: true,
+							},
+							"version": {
+								Type:     framework.TypeString,
+								Required: true,
+							},
+							"command": {
+								Type:     framework.TypeString,
+								Required: true,
+							},
+							"sha256": {
+								Type:     framework.TypeString,
+								Required: true,
+							},
+						},
+					}},
+				},
+				Summary: "Download, verify and register a plugin.",
+			},
+		},
+
+		HelpSynopsis:    strings.TrimSpace(sysHelp["plugin-install"][0]),
+		HelpDescription: strings.TrimSpace(sysHelp["plugin-install"][1]),
+	}
+}
+
 func (b *SystemBackend) pluginsRuntimesCatalogCRUDPath() *framework.Path {
 	return &framework.Path{
 		Pattern: "plugins/runtimes/catalog/(?P<type>container)/" + framework.GenericNameRegex("name"),
@@ -4393,6 +4536,10 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 					Type:        framework.TypeMap,
 					Description: strings.TrimSpace(sysHelp["tune_user_lockout_config"][0]),
 				},
+				"read_cache_size": {
+					Type:        framework.TypeInt,
+					Description: strings.TrimSpace(sysHelp["tune_read_cache_size"][0]),
+				},
 			},
 
 			Operations: map[logical.Operation]framework.OperationHandler{
@@ -4435,6 +4582,11 @@ func (b *SystemBackend) mountPaths() []*framework.Path {
 									Description: strings.TrimSpace(sysHelp["tune_allowed_managed_keys"][0]),
 									Required:    false,
 								},
+								"read_cache_size": {
+									Type:        framework.TypeInt,
+									Description: strings.TrimSpace(sysHelp["tune_read_cache_size"][0]),
+									Required:    false,
+								},
 								"allowed_response_headers": {
 									Type:        framework.TypeCommaStringSlice,
 									Description: strings.TrimSpace(sysHelp["allowed_response_headers"][0]),
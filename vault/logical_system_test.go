@@ -7,6 +7,8 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -14,6 +16,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -2568,6 +2571,314 @@ func TestSystemBackend_disableAudit(t *testing.T) {
 	}
 }
 
+func TestSystemBackend_tuneReadCache(t *testing.T) {
+	c, b, root := testCoreSystemBackendRaw(t)
+	ctx := namespace.RootContext(nil)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "mounts/cached")
+	req.Data["type"] = "kv"
+	req.Data["config"] = map[string]interface{}{"read_cache_size": 16}
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v %v", err, resp)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "mounts/cached/tune")
+	resp, err = b.HandleRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+	if resp.Data["read_cache_size"] != 16 {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	view := c.router.MatchingStorageByAPIPath(ctx, "cached/").(*BarrierView)
+	if view.readCache.Load() == nil {
+		t.Fatal("expected the read cache to be enabled")
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "cached/foo")
+	req.ClientToken = root
+	req.Data["value"] = "bar"
+	if _, err := c.HandleRequest(ctx, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.ReadOperation, "cached/foo")
+	req.ClientToken = root
+	resp, err = c.HandleRequest(ctx, req)
+	if err != nil || resp.Data["value"] != "bar" {
+		t.Fatalf("bad: %v %v", resp, err)
+	}
+
+	// Raw writes bypass the view, so they must invalidate the cache
+	req = logical.TestRequest(t, logical.UpdateOperation, "raw/"+view.Prefix()+"foo")
+	req.Data["value"] = `{"value":"baz"}`
+	if _, err := b.HandleRequest(ctx, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	req = logical.TestRequest(t, logical.ReadOperation, "cached/foo")
+	req.ClientToken = root
+	resp, err = c.HandleRequest(ctx, req)
+	if err != nil || resp.Data["value"] != "baz" {
+		t.Fatalf("bad: %v %v", resp, err)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "mounts/cached/tune")
+	req.Data["read_cache_size"] = -1
+	resp, err = b.HandleRequest(ctx, req)
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected error, got %v %v", resp, err)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "mounts/auth/token/tune")
+	req.Data["read_cache_size"] = 16
+	resp, err = b.HandleRequest(ctx, req)
+	if err != logical.ErrInvalidRequest {
+		t.Fatalf("expected error, got %v %v", resp, err)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "mounts/cached/tune")
+	req.Data["read_cache_size"] = 0
+	if _, err := b.HandleRequest(ctx, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if view.readCache.Load() != nil {
+		t.Fatal("expected the read cache to be disabled")
+	}
+	if c.router.MatchingMountEntry(ctx, "cached/").Config.ReadCacheSize != 0 {
+		t.Fatal("expected the read cache size to be persisted")
+	}
+}
+
+func TestCore_readCacheRaftInvalidation(t *testing.T) {
+	c, b, root := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "mounts/cached")
+	req.Data["type"] = "kv"
+	req.Data["config"] = map[string]interface{}{"read_cache_size": 16}
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil || (resp != nil && resp.IsError()) {
+		t.Fatalf("err: %v %v", err, resp)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "cached/foo")
+	req.ClientToken = root
+	req.Data["value"] = "bar"
+	if _, err := c.HandleRequest(ctx, req); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	read := func() interface{} {
+		req := logical.TestRequest(t, logical.ReadOperation, "cached/foo")
+		req.ClientToken = root
+		resp, err := c.HandleRequest(ctx, req)
+		if err != nil || resp == nil {
+			t.Fatalf("bad: %v %v", resp, err)
+		}
+		return resp.Data["value"]
+	}
+	if v := read(); v != "bar" {
+		t.Fatalf("bad: %v", v)
+	}
+
+	// Writes replicated from the active node are applied to storage below
+	// the view, and reported by the raft backend.
+	view := c.router.MatchingStorageByAPIPath(ctx, "cached/").(*BarrierView)
+	key := view.Prefix() + "foo"
+	if err := c.barrier.Put(ctx, &logical.StorageEntry{Key: key, Value: []byte(`{"value":"baz"}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if v := read(); v != "bar" {
+		t.Fatalf("expected the cached entry, got: %v", v)
+	}
+
+	c.raftInvalidateCallback([]string{key})
+	deadline := time.Now().Add(5 * time.Second)
+	for read() != "baz" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the replicated write to invalidate the cached entry")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSystemBackend_migration(t *testing.T) {
+	c, b, root := testCoreSystemBackend(t)
+	ctx := namespace.RootContext(nil)
+
+	for _, path := range []string{"source", "target"} {
+		req := logical.TestRequest(t, logical.UpdateOperation, "mounts/"+path)
+		req.Data["type"] = "kv"
+		if resp, err := b.HandleRequest(ctx, req); err != nil || (resp != nil && resp.IsError()) {
+			t.Fatalf("err: %v %v", err, resp)
+		}
+	}
+
+	write := func(path, value string) {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, path)
+		req.ClientToken = root
+		req.Data["value"] = value
+		if _, err := c.HandleRequest(ctx, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	read := func(path string) interface{} {
+		t.Helper()
+		req := logical.TestRequest(t, logical.ReadOperation, path)
+		req.ClientToken = root
+		resp, err := c.HandleRequest(ctx, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if resp == nil {
+			return nil
+		}
+		return resp.Data["value"]
+	}
+	write("source/foo", "bar")
+	write("source/nested/baz", "qux")
+	write("target/stale", "value")
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "migration/export")
+	req.Data["mount"] = "source"
+	req.Data["passphrase"] = "correct horse battery staple"
+	resp, err := b.HandleRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	schema.ValidateResponse(
+		t,
+		schema.GetResponseSchema(t, b.(*SystemBackend).Route(req.Path), req.Operation),
+		resp,
+		true,
+	)
+	if resp.Data["type"] != "kv" || resp.Data["entries"] != 2 {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+	archive := resp.Data["archive"].(string)
+
+	// A wrong passphrase or a modified archive are rejected
+	req = logical.TestRequest(t, logical.UpdateOperation, "migration/import")
+	req.Data["mount"] = "target"
+	req.Data["passphrase"] = "wrong"
+	req.Data["archive"] = archive
+	if _, err := b.HandleRequest(ctx, req); err != logical.ErrInvalidRequest {
+		t.Fatalf("expected error, got %v", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(archive)
+	var envelope migrationArchive
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		t.Fatal(err)
+	}
+	envelope.Ciphertext[len(envelope.Ciphertext)-1] ^= 0xff
+	raw, _ = json.Marshal(envelope)
+	req.Data["passphrase"] = "correct horse battery staple"
+	req.Data["archive"] = base64.StdEncoding.EncodeToString(raw)
+	if _, err := b.HandleRequest(ctx, req); err != logical.ErrInvalidRequest {
+		t.Fatalf("expected error, got %v", err)
+	}
+
+	// The archive can't be imported into a mount of another type
+	req.Data["mount"] = "cubbyhole"
+	req.Data["archive"] = archive
+	if _, err := b.HandleRequest(ctx, req); err != logical.ErrInvalidRequest {
+		t.Fatalf("expected error, got %v", err)
+	}
+
+	req.Data["mount"] = "target"
+	resp, err = b.HandleRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Data["entries"] != 2 {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	if v := read("target/foo"); v != "bar" {
+		t.Fatalf("bad: %v", v)
+	}
+	if v := read("target/nested/baz"); v != "qux" {
+		t.Fatalf("bad: %v", v)
+	}
+	if v := read("target/stale"); v != nil {
+		t.Fatalf("expected existing data to be replaced, got %v", v)
+	}
+}
+
+// failingPutStorage fails writes of a given key.
+type failingPutStorage struct {
+	logical.Storage
+	failKey string
+}
+
+func (s *failingPutStorage) Put(ctx context.Context, entry *logical.StorageEntry) error {
+	if strings.HasSuffix(entry.Key, s.failKey) {
+		return errors.New("injected failure")
+	}
+	return s.Storage.Put(ctx, entry)
+}
+
+func TestSystemBackend_migrationImportRestore(t *testing.T) {
+	ctx := context.Background()
+	storage := &failingPutStorage{Storage: &logical.InmemStorage{}, failKey: "broken"}
+	view := NewBarrierView(storage, "logical/target/")
+	backup := NewBarrierView(storage, "sys/migration-backup/target/")
+
+	for _, key := range []string{"foo", "nested/bar"} {
+		if err := view.Put(ctx, &logical.StorageEntry{Key: key, Value: []byte("old-" + key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := copyMigrationView(ctx, view, backup); err != nil {
+		t.Fatal(err)
+	}
+	err := importMigrationEntries(ctx, view, []*logical.StorageEntry{
+		{Key: "new", Value: []byte("new")},
+		{Key: "broken", Value: []byte("broken")},
+	})
+	if err == nil {
+		t.Fatal("expected the import to fail")
+	}
+	if err := restoreMigrationBackup(ctx, backup, view); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := logical.CollectKeys(ctx, view)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"foo", "nested/bar"}) {
+		t.Fatalf("expected the previous data to be restored, got %v", keys)
+	}
+	se, err := view.Get(ctx, "nested/bar")
+	if err != nil || se == nil || string(se.Value) != "old-nested/bar" {
+		t.Fatalf("bad: %v %v", se, err)
+	}
+	if keys, _ := logical.CollectKeys(ctx, backup); len(keys) != 0 {
+		t.Fatalf("expected the backup to be removed, got %v", keys)
+	}
+
+	// Archives with invalid or duplicate entries are rejected up front.
+	for _, entries := range [][]*logical.StorageEntry{
+		{{Key: ""}},
+		{{Key: "dir/"}},
+		{{Key: "foo"}, {Key: "foo"}},
+	} {
+		if err := validateMigrationEntries(entries); err == nil {
+			t.Fatalf("expected %v to be rejected", entries)
+		}
+	}
+}
+
 func TestSystemBackend_rawRead_Compressed(t *testing.T) {
 	t.Run("basic", func(t *testing.T) {
 		b := testSystemBackendRaw(t)
@@ -3157,7 +3468,8 @@ func TestSystemBackend_keyStatus(t *testing.T) {
 	}
 
 	exp := map[string]interface{}{
-		"term": 1,
+		"term":      1,
+		"algorithm": BarrierAlgorithmAESGCM,
 	}
 	delete(resp.Data, "install_time")
 	delete(resp.Data, "encryptions")
@@ -3246,13 +3558,40 @@ func TestSystemBackend_rotate(t *testing.T) {
 	}
 
 	exp := map[string]interface{}{
-		"term": 2,
+		"term":      2,
+		"algorithm": BarrierAlgorithmAESGCM,
 	}
 	delete(resp.Data, "install_time")
 	delete(resp.Data, "encryptions")
 	if !reflect.DeepEqual(resp.Data, exp) {
 		t.Fatalf("got: %#v expect: %#v", resp.Data, exp)
 	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "rotate")
+	req.Data["algorithm"] = BarrierAlgorithmChaCha20Poly1305
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp != nil {
+		t.Fatalf("bad: %v", resp)
+	}
+
+	req = logical.TestRequest(t, logical.ReadOperation, "key-status")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Data["term"] != 3 || resp.Data["algorithm"] != BarrierAlgorithmChaCha20Poly1305 {
+		t.Fatalf("bad: %#v", resp.Data)
+	}
+
+	req = logical.TestRequest(t, logical.UpdateOperation, "rotate")
+	req.Data["algorithm"] = "rot13"
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != logical.ErrInvalidRequest || !resp.IsError() {
+		t.Fatalf("expected invalid request, got: %v, %v", resp, err)
+	}
 }
 
 func testSystemBackend(t *testing.T) logical.Backend {
@@ -3465,6 +3804,15 @@ func TestSystemBackend_PluginCatalog_ContainerCRUD(t *testing.T) {
 	}
 	c.pluginCatalog.directory = sym
 
+	err = c.pluginRuntimeCatalog.Set(namespace.RootContext(nil), &pluginruntimeutil.PluginRuntimeConfig{
+		Name:       "gvisor",
+		Type:       consts.PluginRuntimeTypeContainer,
+		OCIRuntime: "runsc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	for name, tc := range map[string]struct {
 		in, expected map[string]any
 	}{
@@ -3502,6 +3850,24 @@ func TestSystemBackend_PluginCatalog_ContainerCRUD(t *testing.T) {
 				"version":   "v1.0.0",
 			},
 		},
+		"runtime": {
+			in: map[string]any{
+				"oci_image": "foo-image",
+				"sha256":    hex.EncodeToString([]byte{'1'}),
+				"runtime":   "gvisor",
+				"version":   "v1.1.0",
+			},
+			expected: map[string]interface{}{
+				"name":      "test-plugin",
+				"oci_image": "foo-image",
+				"runtime":   "gvisor",
+				"sha256":    "31",
+				"command":   "",
+				"args":      []string{},
+				"builtin":   false,
+				"version":   "v1.1.0",
+			},
+		},
 	} {
 		t.Run(name, func(t *testing.T) {
 			// Add a container plugin.
@@ -3542,6 +3908,100 @@ func TestSystemBackend_PluginCatalog_ContainerCRUD(t *testing.T) {
 	}
 }
 
+// TestSystemBackend_PluginCatalog_ContainerRuntime tests that container
+// plugins can only reference registered runtimes, and that runtimes cannot be
+// deleted while plugins reference them.
+func TestSystemBackend_PluginCatalog_ContainerRuntime(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Containerized plugins only supported on Linux")
+	}
+
+	c, b, _ := testCoreSystemBackend(t)
+	sym, err := filepath.EvalSymlinks(os.TempDir())
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	c.pluginCatalog.directory = sym
+
+	register := func(data map[string]any) *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "plugins/catalog/database/test-plugin")
+		req.Data = data
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// A runtime requires an OCI image.
+	resp := register(map[string]any{
+		"command": "foo-command",
+		"sha256":  hex.EncodeToString([]byte{'1'}),
+		"runtime": "gvisor",
+	})
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error registering a runtime without oci_image, got %#v", resp)
+	}
+
+	// The runtime must exist.
+	resp = register(map[string]any{
+		"oci_image": "foo-image",
+		"sha256":    hex.EncodeToString([]byte{'1'}),
+		"runtime":   "gvisor",
+	})
+	if resp == nil || !resp.IsError() {
+		t.Fatalf("expected error registering with a missing runtime, got %#v", resp)
+	}
+
+	req := logical.TestRequest(t, logical.UpdateOperation, "plugins/runtimes/catalog/container/gvisor")
+	req.Data["oci_runtime"] = "runsc"
+	req.Data["memory_bytes"] = 64 * 1024 * 1024
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v %#v", err, resp)
+	}
+
+	resp = register(map[string]any{
+		"oci_image": "foo-image",
+		"sha256":    hex.EncodeToString([]byte{'1'}),
+		"runtime":   "gvisor",
+	})
+	if resp.IsError() {
+		t.Fatalf("err: %#v", resp)
+	}
+
+	plugin, err := c.pluginCatalog.Get(namespace.RootContext(nil), "test-plugin", consts.PluginTypeDatabase, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plugin.RuntimeConfig == nil || plugin.RuntimeConfig.OCIRuntime != "runsc" || plugin.RuntimeConfig.Memory != 64*1024*1024 {
+		t.Fatalf("expected the runtime config to be resolved, got %#v", plugin.RuntimeConfig)
+	}
+
+	// The runtime cannot be deleted while the plugin uses it.
+	req = logical.TestRequest(t, logical.DeleteOperation, "plugins/runtimes/catalog/container/gvisor")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp == nil || !resp.IsError() || !strings.Contains(resp.Error().Error(), "database/test-plugin") {
+		t.Fatalf("expected error deleting a runtime in use, got %#v", resp)
+	}
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "plugins/catalog/database/test-plugin")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v %#v", err, resp)
+	}
+
+	req = logical.TestRequest(t, logical.DeleteOperation, "plugins/runtimes/catalog/container/gvisor")
+	resp, err = b.HandleRequest(namespace.RootContext(nil), req)
+	if err != nil || resp.IsError() {
+		t.Fatalf("err: %v %#v", err, resp)
+	}
+}
+
 func TestSystemBackend_PluginCatalog_ListPlugins_SucceedsWithAuditLogEnabled(t *testing.T) {
 	core, b, root := testCoreSystemBackend(t)
 
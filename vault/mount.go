@@ -361,6 +361,7 @@ type MountConfig struct {
 	TokenType                 logical.TokenType     `json:"token_type,omitempty" structs:"token_type" mapstructure:"token_type"`
 	AllowedManagedKeys        []string              `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfig    `json:"user_lockout_config,omitempty" mapstructure:"user_lockout_config"`
+	ReadCacheSize             int                   `json:"read_cache_size,omitempty" structs:"read_cache_size" mapstructure:"read_cache_size"`
 
 	// PluginName is the name of the plugin registered in the catalog.
 	//
@@ -395,6 +396,7 @@ type APIMountConfig struct {
 	TokenType                 string                `json:"token_type" structs:"token_type" mapstructure:"token_type"`
 	AllowedManagedKeys        []string              `json:"allowed_managed_keys,omitempty" mapstructure:"allowed_managed_keys"`
 	UserLockoutConfig         *UserLockoutConfig    `json:"user_lockout_config,omitempty" mapstructure:"user_lockout_config"`
+	ReadCacheSize             int                   `json:"read_cache_size,omitempty" structs:"read_cache_size" mapstructure:"read_cache_size"`
 	PluginVersion             string                `json:"plugin_version,omitempty" mapstructure:"plugin_version"`
 
 	// PluginName is the name of the plugin registered in the catalog.
@@ -665,6 +667,10 @@ func (c *Core) mountInternal(ctx context.Context, entry *MountEntry, updateStora
 	}
 	addKnownPath(c, viewPath)
 
+	if err := c.configureReadCache(entry, view); err != nil {
+		return fmt.Errorf("error creating read cache: %v", err)
+	}
+
 	nilMount, err := preprocessMount(c, entry, view)
 	if err != nil {
 		return err
@@ -1477,11 +1483,15 @@ func (c *Core) persistMounts(ctx context.Context, table *MountTable, local *bool
 
 // setupMounts is invoked after we've loaded the mount table to
 // initialize the logical backends and setup the router
-func (c *Core) setupMounts(ctx context.Context) error {
+func (c *Core) setupMounts(ctx context.Context) (retErr error) {
 	c.mountsLock.Lock()
 	defer c.mountsLock.Unlock()
 
-	for _, entry := range c.mounts.sortEntriesByPathDepth().Entries {
+	defer metrics.MeasureSince([]string{"core", "setup_mounts"}, time.Now())
+
+	entries := c.mounts.sortEntriesByPathDepth().Entries
+	pending := make([]*pendingMount, 0, len(entries))
+	for _, entry := range entries {
 		// Initialize the backend, special casing for system
 		barrierPath := entry.ViewPath()
 
@@ -1503,6 +1513,10 @@ func (c *Core) setupMounts(ctx context.Context) error {
 		}
 		addKnownPath(c, barrierPath)
 
+		if err := c.configureReadCache(entry, view); err != nil {
+			return fmt.Errorf("error creating read cache: %v", err)
+		}
+
 		// Determining the replicated state of the mount
 		nilMount, err := preprocessMount(c, entry, view)
 		if err != nil {
@@ -1518,10 +1532,27 @@ func (c *Core) setupMounts(ctx context.Context) error {
 			defer view.setReadOnlyErr(origReadOnlyErr)
 		}
 
-		var backend logical.Backend
-		// Create the new backend
-		sysView := c.mountEntrySysView(entry)
-		backend, entry.RunningSha256, err = c.newLogicalBackend(ctx, entry, sysView, view)
+		pending = append(pending, &pendingMount{
+			entry:           entry,
+			view:            view,
+			sysView:         c.mountEntrySysView(entry),
+			nilMount:        nilMount,
+			origReadOnlyErr: origReadOnlyErr,
+		})
+	}
+
+	// Create the new backends
+	c.createMountBackends(ctx, pending, c.newLogicalBackend, []string{"core", "mount", "setup"})
+	defer func() {
+		if retErr != nil {
+			cleanupPendingMounts(ctx, pending)
+		}
+	}()
+
+	for _, p := range pending {
+		entry, view, nilMount, origReadOnlyErr := p.entry, p.view, p.nilMount, p.origReadOnlyErr
+		barrierPath := entry.ViewPath()
+		backend, err := p.backend, p.err
 		if err != nil {
 			c.logger.Error("failed to create mount entry", "path", entry.Path, "error", err)
 
@@ -1556,6 +1587,7 @@ func (c *Core) setupMounts(ctx context.Context) error {
 				c.logger.Error("skipping deprecated mount entry", "name", entry.Type, "path", entry.Path, "error", err)
 				backend.Cleanup(ctx)
 				backend = nil
+				p.backend = nil
 				goto ROUTER_MOUNT
 			}
 		}
@@ -1581,6 +1613,7 @@ func (c *Core) setupMounts(ctx context.Context) error {
 		if nilMount {
 			backend.Cleanup(ctx)
 			backend = nil
+			p.backend = nil
 		}
 
 	ROUTER_MOUNT:
@@ -1590,6 +1623,8 @@ func (c *Core) setupMounts(ctx context.Context) error {
 			c.logger.Error("failed to mount entry", "path", entry.Path, "error", err)
 			return errLoadMountsFailed
 		}
+		// The router now owns the backend.
+		p.backend = nil
 
 		// Initialize
 		if !nilMount {
@@ -1606,7 +1641,12 @@ func (c *Core) setupMounts(ctx context.Context) error {
 				}
 
 				nsActiveContext := namespace.ContextWithNamespace(c.activeContext, localEntry.Namespace())
+				start := time.Now()
 				err := backend.Initialize(nsActiveContext, &logical.InitializationRequest{Storage: view})
+				metrics.MeasureSinceWithLabels([]string{"core", "mount", "initialize"}, start, []metrics.Label{
+					{"type", localEntry.Type},
+					{"mount_point", mountPointLabel(localEntry)},
+				})
 				if err != nil {
 					postUnsealLogger.Error("failed to initialize mount backend", "error", err)
 				}
@@ -0,0 +1,126 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	metrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/vault/sdk/helper/strutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+// EnvVaultMountSetupConcurrency overrides the number of mount backends that
+// are created in parallel while setting up the mount and auth tables.
+const EnvVaultMountSetupConcurrency = "VAULT_MOUNT_SETUP_CONCURRENCY"
+
+// pendingMount holds the state of a mount entry while the mount table is
+// being set up.
+type pendingMount struct {
+	entry           *MountEntry
+	view            *BarrierView
+	sysView         logical.SystemView
+	nilMount        bool
+	origReadOnlyErr error
+
+	backend logical.Backend
+	err     error
+}
+
+// backendFactoryFunc creates the backend of a mount entry, returning the
+// SHA256 of the plugin running it, like newLogicalBackend.
+type backendFactoryFunc func(context.Context, *MountEntry, logical.SystemView, logical.Storage) (logical.Backend, string, error)
+
+// mountSetupConcurrency returns the number of mount backends to create in
+// parallel.
+func (c *Core) mountSetupConcurrency() int {
+	concurrency := runtime.NumCPU() * 2
+	if v := os.Getenv(EnvVaultMountSetupConcurrency); v != "" {
+		pv, err := strconv.Atoi(v)
+		if err != nil || pv < 1 {
+			c.logger.Warn("invalid value for "+EnvVaultMountSetupConcurrency+", must be a positive integer", "error", err, "value", v)
+		} else {
+			concurrency = pv
+		}
+	}
+	return concurrency
+}
+
+// createMountBackends creates the backends of the pending mounts, storing
+// the result in each of them. Singleton mounts are created first and one at
+// a time, as their factories wire them into the core. The other backends,
+// which may start plugin processes, are created in parallel. The time taken
+// to create each backend is reported under the given metric name.
+func (c *Core) createMountBackends(ctx context.Context, pending []*pendingMount, factory backendFactoryFunc, metricName []string) {
+	create := func(p *pendingMount) {
+		start := time.Now()
+		p.backend, p.entry.RunningSha256, p.err = factory(ctx, p.entry, p.sysView, p.view)
+		metrics.MeasureSinceWithLabels(metricName, start, []metrics.Label{
+			{"type", p.entry.Type},
+			{"mount_point", mountPointLabel(p.entry)},
+		})
+		c.logger.Trace("created mount backend", "type", p.entry.Type, "path", p.entry.Path, "duration", time.Since(start))
+	}
+
+	var parallel []*pendingMount
+	for _, p := range pending {
+		if strutil.StrListContains(singletonMounts, p.entry.Type) {
+			create(p)
+			continue
+		}
+		parallel = append(parallel, p)
+	}
+
+	concurrency := c.mountSetupConcurrency()
+	if concurrency <= 1 {
+		for _, p := range parallel {
+			create(p)
+		}
+		return
+	}
+
+	jobs := make(chan *pendingMount)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(parallel); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range jobs {
+				create(p)
+			}
+		}()
+	}
+	for _, p := range parallel {
+		jobs <- p
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// cleanupPendingMounts cleans up the backends of pending mounts which were
+// created but not yet handed to the router, stopping any plugin processes
+// they started. It is called when setting up a mount table fails partway.
+func cleanupPendingMounts(ctx context.Context, pending []*pendingMount) {
+	for _, p := range pending {
+		if p.backend != nil {
+			p.backend.Cleanup(ctx)
+			p.backend = nil
+		}
+	}
+}
+
+// mountPointLabel returns the full path of a mount entry, for use as a metric
+// label.
+func mountPointLabel(entry *MountEntry) string {
+	path := entry.Namespace().Path + entry.Path
+	if entry.Table == credentialTableType {
+		path = entry.Namespace().Path + credentialRoutePrefix + entry.Path
+	}
+	return path
+}
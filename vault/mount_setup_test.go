@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestCore_CreateMountBackends(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+	t.Setenv(EnvVaultMountSetupConcurrency, "4")
+
+	var pending []*pendingMount
+	for i := 0; i < 16; i++ {
+		pending = append(pending, &pendingMount{entry: &MountEntry{
+			Type:      "noop",
+			Path:      fmt.Sprintf("mount-%d/", i),
+			namespace: namespace.RootNamespace,
+		}})
+	}
+	pending = append(pending, &pendingMount{entry: &MountEntry{
+		Type:      systemMountType,
+		Path:      "sys/",
+		namespace: namespace.RootNamespace,
+	}})
+
+	var running, maxRunning int32
+	var lock sync.Mutex
+	var created []string
+	factory := func(_ context.Context, entry *MountEntry, _ logical.SystemView, _ logical.Storage) (logical.Backend, string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+
+		lock.Lock()
+		created = append(created, entry.Type)
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		if entry.Path == "mount-3/" {
+			return nil, "", errors.New("boom")
+		}
+		return &NoopBackend{}, "sha-" + strconv.Itoa(len(entry.Path)), nil
+	}
+
+	c.createMountBackends(context.Background(), pending, factory, []string{"test", "setup"})
+
+	if created[0] != systemMountType {
+		t.Fatalf("expected the singleton mount to be created first, got %v", created)
+	}
+	if maxRunning < 2 || maxRunning > 4 {
+		t.Fatalf("expected between 2 and 4 concurrent creations, got %d", maxRunning)
+	}
+	for _, p := range pending {
+		if p.entry.Path == "mount-3/" {
+			if p.err == nil || p.backend != nil {
+				t.Fatalf("expected error for %s", p.entry.Path)
+			}
+			continue
+		}
+		if p.err != nil || p.backend == nil || p.entry.RunningSha256 == "" {
+			t.Fatalf("unexpected result for %s: %v", p.entry.Path, p.err)
+		}
+	}
+}
+
+func TestCore_MountSetupConcurrency(t *testing.T) {
+	c, _, _ := TestCoreUnsealed(t)
+
+	t.Setenv(EnvVaultMountSetupConcurrency, "3")
+	if n := c.mountSetupConcurrency(); n != 3 {
+		t.Fatalf("expected 3, got %d", n)
+	}
+
+	t.Setenv(EnvVaultMountSetupConcurrency, "-1")
+	if n := c.mountSetupConcurrency(); n < 1 {
+		t.Fatalf("expected the default for an invalid value, got %d", n)
+	}
+}
+
+type cleanupCountingBackend struct {
+	NoopBackend
+	cleanups int32
+}
+
+func (b *cleanupCountingBackend) Cleanup(context.Context) {
+	atomic.AddInt32(&b.cleanups, 1)
+}
+
+func TestCore_CleanupPendingMounts(t *testing.T) {
+	mounted := &cleanupCountingBackend{}
+	unmounted := &cleanupCountingBackend{}
+	pending := []*pendingMount{
+		// Backends handed to the router are no longer tracked.
+		{entry: &MountEntry{Path: "mounted/"}},
+		{entry: &MountEntry{Path: "unmounted/"}, backend: unmounted},
+		{entry: &MountEntry{Path: "failed/"}, err: errors.New("boom")},
+	}
+
+	cleanupPendingMounts(context.Background(), pending)
+	cleanupPendingMounts(context.Background(), pending)
+
+	if n := atomic.LoadInt32(&unmounted.cleanups); n != 1 {
+		t.Fatalf("expected the unmounted backend to be cleaned up once, got %d", n)
+	}
+	if n := atomic.LoadInt32(&mounted.cleanups); n != 0 {
+		t.Fatalf("expected the mounted backend not to be cleaned up, got %d", n)
+	}
+	if pending[1].backend != nil {
+		t.Fatal("expected the cleaned up backend to be released")
+	}
+}
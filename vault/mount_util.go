@@ -65,12 +65,17 @@ func (c *Core) mountEntrySysView(entry *MountEntry) extendedSystemView {
 		},
 	}
 
+	var sysView interface{} = esi
+	if entry.ExternalEntropyAccess && c.entropyAugmentationEnabled() {
+		sysView = entropySystemViewImpl{esi}
+	}
+
 	// Due to complexity in the ACME interface, only return it when we
 	// are a PKI plugin that needs it.
 	if entry.Type != "pki" {
-		return esi
+		return sysView.(extendedSystemView)
 	}
-	return c.NewAcmeBillingSystemView(esi)
+	return c.NewAcmeBillingSystemView(sysView)
 }
 
 func (c *Core) entBuiltinPluginMetrics(ctx context.Context, entry *MountEntry, val float32) error {
@@ -11,19 +11,24 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/armon/go-metrics"
 	log "github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/go-secure-stdlib/base62"
 	semver "github.com/hashicorp/go-version"
+	"github.com/hashicorp/vault/helper/metricsutil"
 	"github.com/hashicorp/vault/helper/versions"
 	v4 "github.com/hashicorp/vault/sdk/database/dbplugin"
 	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 	"github.com/hashicorp/vault/sdk/helper/jsonutil"
+	"github.com/hashicorp/vault/sdk/helper/pluginruntimeutil"
 	"github.com/hashicorp/vault/sdk/helper/pluginutil"
 	"github.com/hashicorp/vault/sdk/logical"
 	backendplugin "github.com/hashicorp/vault/sdk/plugin"
@@ -46,6 +51,7 @@ var (
 type PluginCatalog struct {
 	builtinRegistry BuiltinRegistry
 	catalogView     *BarrierView
+	runtimeCatalog  *PluginRuntimeCatalog
 	directory       string
 	logger          log.Logger
 
@@ -61,6 +67,10 @@ type PluginCatalog struct {
 
 	lock    sync.RWMutex
 	wrapper pluginutil.RunnerUtil
+
+	// installMissing fetches the binary of a plugin installed through
+	// sys/plugins/install if it is missing from the plugin directory.
+	installMissing func(context.Context, *pluginutil.PluginRunner) error
 }
 
 // Only plugins running with identical PluginRunner config can be multiplexed,
@@ -76,6 +86,7 @@ type externalPluginsKey struct {
 	version  string
 	command  string
 	ociImage string
+	runtime  string
 	args     string
 	env      string
 	sha256   string
@@ -99,6 +110,7 @@ func makeExternalPluginsKey(p *pluginutil.PluginRunner) (externalPluginsKey, err
 		version:  p.Version,
 		command:  p.Command,
 		ociImage: p.OCIImage,
+		runtime:  p.Runtime,
 		args:     string(args),
 		env:      string(env),
 		sha256:   hex.EncodeToString(p.Sha256),
@@ -175,10 +187,12 @@ func (c *Core) setupPluginCatalog(ctx context.Context) error {
 	c.pluginCatalog = &PluginCatalog{
 		builtinRegistry: c.builtinRegistry,
 		catalogView:     NewBarrierView(c.barrier, pluginCatalogPath),
+		runtimeCatalog:  c.pluginRuntimeCatalog,
 		directory:       c.pluginDirectory,
 		logger:          c.logger,
 		mlockPlugins:    c.enableMlock,
 		wrapper:         logical.StaticSystemView{VersionString: version.GetVersion().Version},
+		installMissing:  c.installMissingPlugin,
 	}
 
 	// Run upgrade if untyped plugins exist
@@ -195,6 +209,49 @@ func (c *Core) setupPluginCatalog(ctx context.Context) error {
 	return nil
 }
 
+// mountsPinnedToPluginVersion returns the paths, across all namespaces, of the
+// secrets engines or auth methods pinned to the given version of a plugin.
+// Database plugins are not tracked by the mount tables, so none are returned
+// for them.
+func (c *Core) mountsPinnedToPluginVersion(pluginType consts.PluginType, name, version string) []string {
+	if version == "" {
+		return nil
+	}
+
+	var entries []*MountEntry
+	var prefix string
+	switch pluginType {
+	case consts.PluginTypeSecrets:
+		c.mountsLock.RLock()
+		defer c.mountsLock.RUnlock()
+		if c.mounts != nil {
+			entries = c.mounts.Entries
+		}
+	case consts.PluginTypeCredential:
+		c.authLock.RLock()
+		defer c.authLock.RUnlock()
+		if c.auth != nil {
+			entries = c.auth.Entries
+		}
+		prefix = credentialRoutePrefix
+	default:
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.Version != version {
+			continue
+		}
+		if entry.Type == name || (entry.Type == "plugin" && entry.Config.PluginName == name) {
+			paths = append(paths, entry.Namespace().Path+prefix+entry.Path)
+		}
+	}
+	sort.Strings(paths)
+
+	return paths
+}
+
 type pluginClientConn struct {
 	*grpc.ClientConn
 	id string
@@ -298,6 +355,36 @@ func (c *PluginCatalog) cleanupExternalPlugin(key externalPluginsKey, id, plugin
 	return nil
 }
 
+// externalPluginGauges returns, for each running external plugin, the number
+// of plugin processes and the number of backends they serve. Multiplexed
+// plugins serve all their backends from a single process.
+func (c *PluginCatalog) externalPluginGauges() (processes, connections []metricsutil.GaugeLabelValues) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for key, extPlugin := range c.externalPlugins {
+		if len(extPlugin.connections) == 0 {
+			continue
+		}
+
+		clients := make(map[*plugin.Client]struct{})
+		for _, pc := range extPlugin.connections {
+			clients[pc.client] = struct{}{}
+		}
+
+		labels := []metrics.Label{
+			{Name: "plugin_name", Value: key.name},
+			{Name: "plugin_type", Value: key.typ.String()},
+			{Name: "plugin_version", Value: key.version},
+			{Name: "multiplexed", Value: strconv.FormatBool(extPlugin.multiplexingSupport)},
+		}
+		processes = append(processes, metricsutil.GaugeLabelValues{Labels: labels, Value: float32(len(clients))})
+		connections = append(connections, metricsutil.GaugeLabelValues{Labels: labels, Value: float32(len(extPlugin.connections))})
+	}
+
+	return processes, connections
+}
+
 func (c *PluginCatalog) getExternalPlugin(key externalPluginsKey) *externalPlugin {
 	if extPlugin, ok := c.externalPlugins[key]; ok {
 		return extPlugin
@@ -339,6 +426,11 @@ func (c *PluginCatalog) NewPluginClient(ctx context.Context, config pluginutil.P
 	if pluginRunner == nil {
 		return nil, fmt.Errorf("no plugin found")
 	}
+	if c.installMissing != nil {
+		if err := c.installMissing(ctx, pluginRunner); err != nil {
+			return nil, err
+		}
+	}
 	pc, err := c.newPluginClient(ctx, pluginRunner, config)
 	return pc, err
 }
@@ -845,6 +937,13 @@ func (c *PluginCatalog) get(ctx context.Context, name string, pluginType consts.
 				entry.Command = filepath.Join(c.directory, entry.Command)
 			}
 
+			if entry.Runtime != "" {
+				entry.RuntimeConfig, err = c.runtimeCatalog.Get(ctx, entry.Runtime, consts.PluginRuntimeTypeContainer)
+				if err != nil {
+					return nil, fmt.Errorf("failed to retrieve runtime %q of plugin %q: %w", entry.Runtime, name, err)
+				}
+			}
+
 			return entry, nil
 		}
 	}
@@ -896,6 +995,19 @@ func (c *PluginCatalog) Set(ctx context.Context, plugin pluginutil.SetPluginInpu
 }
 
 func (c *PluginCatalog) setInternal(ctx context.Context, plugin pluginutil.SetPluginInput) (*pluginutil.PluginRunner, error) {
+	var runtimeConfig *pluginruntimeutil.PluginRuntimeConfig
+	if plugin.Runtime != "" {
+		if plugin.OCIImage == "" {
+			return nil, errors.New("a runtime can only be specified for container plugins")
+		}
+
+		var err error
+		runtimeConfig, err = c.runtimeCatalog.Get(ctx, plugin.Runtime, consts.PluginRuntimeTypeContainer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	command := plugin.Command
 	if plugin.OCIImage == "" {
 		// Best effort check to make sure the command isn't breaking out of the
@@ -919,13 +1031,15 @@ func (c *PluginCatalog) setInternal(ctx context.Context, plugin pluginutil.SetPl
 	// full command instead of the relative command because get() normally prepends
 	// the plugin directory to the command, but we can't use get() here.
 	entryTmp := &pluginutil.PluginRunner{
-		Name:     plugin.Name,
-		Command:  command,
-		OCIImage: plugin.OCIImage,
-		Args:     plugin.Args,
-		Env:      plugin.Env,
-		Sha256:   plugin.Sha256,
-		Builtin:  false,
+		Name:          plugin.Name,
+		Command:       command,
+		OCIImage:      plugin.OCIImage,
+		Runtime:       plugin.Runtime,
+		RuntimeConfig: runtimeConfig,
+		Args:          plugin.Args,
+		Env:           plugin.Env,
+		Sha256:        plugin.Sha256,
+		Builtin:       false,
 	}
 	// If the plugin type is unknown, we want to attempt to determine the type
 	if plugin.Type == consts.PluginTypeUnknown {
@@ -965,15 +1079,18 @@ func (c *PluginCatalog) setInternal(ctx context.Context, plugin pluginutil.SetPl
 	}
 
 	entry := &pluginutil.PluginRunner{
-		Name:     plugin.Name,
-		Type:     plugin.Type,
-		Version:  plugin.Version,
-		Command:  plugin.Command,
-		OCIImage: plugin.OCIImage,
-		Args:     plugin.Args,
-		Env:      plugin.Env,
-		Sha256:   plugin.Sha256,
-		Builtin:  false,
+		Name:         plugin.Name,
+		Type:         plugin.Type,
+		Version:      plugin.Version,
+		Command:      plugin.Command,
+		OCIImage:     plugin.OCIImage,
+		Runtime:      plugin.Runtime,
+		Args:         plugin.Args,
+		Env:          plugin.Env,
+		Sha256:       plugin.Sha256,
+		Builtin:      false,
+		ArtifactURL:  plugin.ArtifactURL,
+		SignatureURL: plugin.SignatureURL,
 	}
 
 	buf, err := json.Marshal(entry)
@@ -1014,6 +1131,37 @@ func (c *PluginCatalog) Delete(ctx context.Context, name string, pluginType cons
 	return c.catalogView.Delete(ctx, pluginKey)
 }
 
+// ListPluginsUsingRuntime returns the storage keys, i.e. <type>/<name> or
+// <type>/<name>/<version>, of the plugins registered with the given runtime.
+func (c *PluginCatalog) ListPluginsUsingRuntime(ctx context.Context, runtime string) ([]string, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys, err := logical.CollectKeys(ctx, c.catalogView)
+	if err != nil {
+		return nil, err
+	}
+
+	var plugins []string
+	for _, key := range keys {
+		entry, err := c.catalogView.Get(ctx, key)
+		if err != nil || entry == nil {
+			continue
+		}
+
+		plugin := new(pluginutil.PluginRunner)
+		if err := jsonutil.DecodeJSON(entry.Value, plugin); err != nil {
+			return nil, fmt.Errorf("failed to decode plugin entry: %w", err)
+		}
+		if plugin.Runtime == runtime {
+			plugins = append(plugins, key)
+		}
+	}
+	sort.Strings(plugins)
+
+	return plugins, nil
+}
+
 // List returns a list of all the known plugin names. If an external and builtin
 // plugin share the same name, only one instance of the name will be returned.
 func (c *PluginCatalog) List(ctx context.Context, pluginType consts.PluginType) ([]string, error) {
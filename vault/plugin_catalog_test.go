@@ -15,8 +15,10 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/builtin/credential/userpass"
+	"github.com/hashicorp/vault/helper/namespace"
 	"github.com/hashicorp/vault/helper/versions"
 	"github.com/hashicorp/vault/plugins/database/postgresql"
 	v5 "github.com/hashicorp/vault/sdk/database/dbplugin/v5"
@@ -648,6 +650,93 @@ func TestPluginCatalog_MakeExternalPluginsKey_Comparable(t *testing.T) {
 	}
 }
 
+func TestCore_MountsPinnedToPluginVersion(t *testing.T) {
+	core, _, _ := TestCoreUnsealed(t)
+
+	core.mountsLock.Lock()
+	core.mounts.Entries = append(core.mounts.Entries,
+		&MountEntry{Table: mountTableType, Path: "foo/", Type: "myplugin", Version: "v1.0.0", namespace: namespace.RootNamespace},
+		&MountEntry{Table: mountTableType, Path: "bar/", Type: "myplugin", Version: "v1.0.1", namespace: namespace.RootNamespace},
+		&MountEntry{Table: mountTableType, Path: "baz/", Type: "plugin", Version: "v1.0.0", Config: MountConfig{PluginName: "myplugin"}, namespace: namespace.RootNamespace},
+	)
+	core.mountsLock.Unlock()
+
+	core.authLock.Lock()
+	core.auth.Entries = append(core.auth.Entries,
+		&MountEntry{Table: credentialTableType, Path: "foo/", Type: "myplugin", Version: "v1.0.0", namespace: namespace.RootNamespace},
+	)
+	core.authLock.Unlock()
+
+	for name, tc := range map[string]struct {
+		pluginType consts.PluginType
+		version    string
+		expected   []string
+	}{
+		"secrets":       {consts.PluginTypeSecrets, "v1.0.0", []string{"baz/", "foo/"}},
+		"other version": {consts.PluginTypeSecrets, "v1.0.1", []string{"bar/"}},
+		"unused":        {consts.PluginTypeSecrets, "v2.0.0", nil},
+		"unversioned":   {consts.PluginTypeSecrets, "", nil},
+		"auth":          {consts.PluginTypeCredential, "v1.0.0", []string{"auth/foo/"}},
+		"database":      {consts.PluginTypeDatabase, "v1.0.0", nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual := core.mountsPinnedToPluginVersion(tc.pluginType, "myplugin", tc.version)
+			if !reflect.DeepEqual(tc.expected, actual) {
+				t.Fatalf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestPluginCatalog_ExternalPluginGauges(t *testing.T) {
+	core, _, _ := TestCoreUnsealed(t)
+
+	muxedClient := &plugin.Client{}
+	core.pluginCatalog.externalPlugins = map[externalPluginsKey]*externalPlugin{
+		{name: "muxed", typ: consts.PluginTypeSecrets, version: "v1.0.0"}: {
+			multiplexingSupport: true,
+			connections: map[string]*pluginClient{
+				"a": {client: muxedClient},
+				"b": {client: muxedClient},
+				"c": {client: muxedClient},
+			},
+		},
+		{name: "unmuxed", typ: consts.PluginTypeCredential}: {
+			connections: map[string]*pluginClient{
+				"a": {client: &plugin.Client{}},
+				"b": {client: &plugin.Client{}},
+			},
+		},
+		{name: "stopped", typ: consts.PluginTypeDatabase}: {
+			connections: map[string]*pluginClient{},
+		},
+	}
+
+	processes, connections := core.pluginCatalog.externalPluginGauges()
+	if len(processes) != 2 || len(connections) != 2 {
+		t.Fatalf("expected gauges for 2 running plugins, got %d and %d", len(processes), len(connections))
+	}
+
+	actual := make(map[string][2]float32)
+	for i := range processes {
+		var name string
+		for _, label := range processes[i].Labels {
+			if label.Name == "plugin_name" {
+				name = label.Value
+			}
+		}
+		actual[name] = [2]float32{processes[i].Value, connections[i].Value}
+	}
+
+	expected := map[string][2]float32{
+		"muxed":   {1, 3},
+		"unmuxed": {2, 2},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("expected %v, got %v", expected, actual)
+	}
+}
+
 func TestPluginCatalog_PluginMain_Userpass(t *testing.T) {
 	if os.Getenv(pluginutil.PluginVaultVersionEnv) == "" {
 		return
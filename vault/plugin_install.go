@@ -0,0 +1,345 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/vault/sdk/framework"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/helper/pluginutil"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+const (
+	// maxPluginArtifactSize is the largest plugin binary that will be
+	// downloaded by sys/plugins/install.
+	maxPluginArtifactSize = 512 * 1024 * 1024
+
+	// maxPluginSignatureSize is the largest detached signature that will be
+	// downloaded by sys/plugins/install.
+	maxPluginSignatureSize = 4 * 1024
+)
+
+// ParsePluginSigningKey parses a PEM encoded Ed25519 public key, as trusted to
+// sign plugin artifacts installed through sys/plugins/install.
+func ParsePluginSigningKey(pemBytes []byte) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T, only Ed25519 keys may sign plugins", key)
+	}
+
+	return edKey, nil
+}
+
+func (b *SystemBackend) handlePluginInstall(ctx context.Context, _ *logical.Request, d *framework.FieldData) (*logical.Response, error) {
+	pluginName := d.Get("name").(string)
+	if pluginName == "" {
+		return logical.ErrorResponse("missing plugin name"), nil
+	}
+	if strings.ContainsAny(pluginName, `/\`) || strings.Contains(pluginName, "..") {
+		return logical.ErrorResponse("plugin name %q must not contain path separators", pluginName), nil
+	}
+
+	pluginType, err := consts.ParsePluginType(d.Get("type").(string))
+	if err != nil || pluginType == consts.PluginTypeUnknown {
+		return logical.ErrorResponse("plugin type must be one of auth, database or secret"), nil
+	}
+
+	pluginVersion, builtin, err := getVersion(d)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+	if pluginVersion == "" {
+		return logical.ErrorResponse("missing plugin version"), nil
+	}
+	if builtin {
+		return logical.ErrorResponse("version %q is not allowed because 'builtin' is a reserved metadata identifier", pluginVersion), nil
+	}
+
+	if b.Core.pluginCatalog.directory == "" {
+		return logical.ErrorResponse(ErrDirectoryNotConfigured.Error()), nil
+	}
+	if len(b.Core.pluginSigningKeys) == 0 {
+		return logical.ErrorResponse("no plugin signing keys are configured; set plugin_signing_keys in the server configuration"), nil
+	}
+
+	artifactURL := d.Get("url").(string)
+	if artifactURL == "" {
+		if b.Core.pluginRegistryURL == "" {
+			return logical.ErrorResponse("missing url, and no plugin_registry_url is configured"), nil
+		}
+		artifactURL = pluginRegistryArtifactURL(b.Core.pluginRegistryURL, pluginName, pluginVersion)
+	}
+	signatureURL := d.Get("signature_url").(string)
+	if signatureURL == "" {
+		signatureURL = artifactURL + ".sig"
+	}
+	for _, u := range []string{artifactURL, signatureURL} {
+		if err := validatePluginDownloadURL(u); err != nil {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+	}
+
+	var expectedSha256 []byte
+	if sha256Str := d.Get("sha256").(string); sha256Str != "" {
+		expectedSha256, err = hex.DecodeString(sha256Str)
+		if err != nil {
+			return logical.ErrorResponse("could not decode SHA256 value from hex %s: %s", sha256Str, err), nil
+		}
+	}
+
+	artifact, err := b.Core.fetchSignedPlugin(ctx, artifactURL, signatureURL)
+	if err != nil {
+		return logical.ErrorResponse(err.Error()), nil
+	}
+
+	sum := sha256.Sum256(artifact)
+	if expectedSha256 != nil && !bytes.Equal(sum[:], expectedSha256) {
+		return logical.ErrorResponse("SHA256 of the downloaded plugin %x does not match %x", sum[:], expectedSha256), nil
+	}
+
+	command := fmt.Sprintf("%s_%s_%s", pluginType.String(), pluginName, pluginVersion)
+	written, err := b.Core.writePluginFile(command, artifact, sum[:])
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Core.CheckPluginPerms(command); err != nil {
+		return nil, err
+	}
+
+	err = b.Core.pluginCatalog.Set(ctx, pluginutil.SetPluginInput{
+		Name:    pluginName,
+		Type:    pluginType,
+		Version: pluginVersion,
+		Command: command,
+		Args:    d.Get("args").([]string),
+		Env:     d.Get("env").([]string),
+		Sha256:  sum[:],
+
+		ArtifactURL:  artifactURL,
+		SignatureURL: signatureURL,
+	})
+	if err != nil {
+		if written {
+			os.Remove(filepath.Join(b.Core.pluginCatalog.directory, command))
+		}
+		if errors.Is(err, ErrPluginNotFound) || strings.HasPrefix(err.Error(), "plugin version mismatch") {
+			return logical.ErrorResponse(err.Error()), nil
+		}
+		return nil, err
+	}
+
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"name":    pluginName,
+			"type":    pluginType.String(),
+			"version": pluginVersion,
+			"command": command,
+			"sha256":  hex.EncodeToString(sum[:]),
+		},
+	}, nil
+}
+
+// installMissingPlugin fetches the binary of a plugin installed through
+// sys/plugins/install into the plugin directory, if it is missing there. The
+// binary is only written to the plugin directory of the node serving the
+// install request, so other nodes fetch it when they first run the plugin.
+func (c *Core) installMissingPlugin(ctx context.Context, runner *pluginutil.PluginRunner) error {
+	if runner.Builtin || runner.OCIImage != "" || runner.ArtifactURL == "" {
+		return nil
+	}
+	if _, err := os.Stat(runner.Command); !errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	c.logger.Info("fetching missing plugin binary", "plugin", runner.Name, "version", runner.Version, "url", runner.ArtifactURL)
+	artifact, err := c.fetchSignedPlugin(ctx, runner.ArtifactURL, runner.SignatureURL)
+	if err != nil {
+		return fmt.Errorf("failed to install plugin %q: %w", runner.Name, err)
+	}
+
+	sum := sha256.Sum256(artifact)
+	if !bytes.Equal(sum[:], runner.Sha256) {
+		return fmt.Errorf("failed to install plugin %q: SHA256 of the downloaded plugin %x does not match %x", runner.Name, sum[:], runner.Sha256)
+	}
+
+	command := filepath.Base(runner.Command)
+	if _, err := c.writePluginFile(command, artifact, sum[:]); err != nil {
+		return fmt.Errorf("failed to install plugin %q: %w", runner.Name, err)
+	}
+	return c.CheckPluginPerms(command)
+}
+
+// fetchSignedPlugin downloads a plugin binary and its detached signature,
+// and verifies the signature against the configured plugin signing keys.
+func (c *Core) fetchSignedPlugin(ctx context.Context, artifactURL, signatureURL string) ([]byte, error) {
+	if len(c.pluginSigningKeys) == 0 {
+		return nil, errors.New("no plugin signing keys are configured; set plugin_signing_keys in the server configuration")
+	}
+
+	artifact, err := downloadPluginFile(ctx, artifactURL, maxPluginArtifactSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download plugin: %w", err)
+	}
+	signature, err := downloadPluginFile(ctx, signatureURL, maxPluginSignatureSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download plugin signature: %w", err)
+	}
+
+	if err := verifyPluginSignature(c.pluginSigningKeys, artifact, signature); err != nil {
+		return nil, err
+	}
+
+	return artifact, nil
+}
+
+// pluginRegistryArtifactURL returns the location of a plugin binary within a
+// plugin registry, which follows the layout of releases.hashicorp.com:
+// <registry>/<name>/<version>/<name>_<version>_<os>_<arch>.
+func pluginRegistryArtifactURL(registry, name, version string) string {
+	version = strings.TrimPrefix(version, "v")
+	return fmt.Sprintf("%s/%s/%s/%s_%s_%s_%s", registry, url.PathEscape(name), url.PathEscape(version),
+		url.PathEscape(name), url.PathEscape(version), runtime.GOOS, runtime.GOARCH)
+}
+
+func validatePluginDownloadURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("invalid url %q: scheme must be http or https", rawURL)
+	}
+	return nil
+}
+
+// downloadPluginFile fetches the file at the given URL, failing if it is
+// larger than maxSize.
+func downloadPluginFile(ctx context.Context, fileURL string, maxSize int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := cleanhttp.DefaultClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q fetching %s", resp.Status, fileURL)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%s is larger than the maximum of %d bytes", fileURL, maxSize)
+	}
+
+	return data, nil
+}
+
+// verifyPluginSignature checks that the detached Ed25519 signature, either raw
+// or base64 encoded, was made over the artifact by one of the trusted keys.
+func verifyPluginSignature(keys []ed25519.PublicKey, artifact, signature []byte) error {
+	if len(signature) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+		if err != nil || len(decoded) != ed25519.SignatureSize {
+			return errors.New("plugin signature is not a raw or base64 encoded Ed25519 signature")
+		}
+		signature = decoded
+	}
+
+	for _, key := range keys {
+		if ed25519.Verify(key, artifact, signature) {
+			return nil
+		}
+	}
+
+	return errors.New("plugin signature does not verify against any of the configured plugin signing keys")
+}
+
+// writePluginFile writes an installed plugin binary into the plugin directory
+// under the given name. It returns false without writing if an identical file
+// is already present, and refuses to replace a file with different contents.
+func (c *Core) writePluginFile(name string, contents, sum []byte) (bool, error) {
+	dir := c.pluginCatalog.directory
+	path := filepath.Join(dir, name)
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		existingSum := sha256.Sum256(existing)
+		if !bytes.Equal(existingSum[:], sum) {
+			return false, fmt.Errorf("a different plugin binary already exists at %q", name)
+		}
+		return false, nil
+	case !errors.Is(err, os.ErrNotExist):
+		return false, err
+	}
+
+	f, err := os.CreateTemp(dir, ".install-"+name+"-*")
+	if err != nil {
+		return false, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(contents); err != nil {
+		f.Close()
+		return false, err
+	}
+	if err := f.Close(); err != nil {
+		return false, err
+	}
+
+	mode := os.FileMode(0o755)
+	if c.pluginFilePermissions != 0 {
+		mode = os.FileMode(c.pluginFilePermissions)
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return false, err
+	}
+	if c.pluginFileUid != 0 {
+		if err := os.Chown(f.Name(), c.pluginFileUid, -1); err != nil {
+			return false, err
+		}
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
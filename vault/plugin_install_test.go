@@ -0,0 +1,177 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: BUSL-1.1
+
+package vault
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/hashicorp/vault/helper/namespace"
+	"github.com/hashicorp/vault/sdk/helper/consts"
+	"github.com/hashicorp/vault/sdk/logical"
+)
+
+func TestParsePluginSigningKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ParsePluginSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pub.Equal(key) {
+		t.Fatal("parsed key does not match")
+	}
+
+	if _, err := ParsePluginSigningKey([]byte("not a key")); err == nil {
+		t.Fatal("expected error parsing non-PEM data")
+	}
+}
+
+func TestSystemBackend_PluginInstall(t *testing.T) {
+	c, b, _ := testCoreSystemBackend(t)
+	c.pluginCatalog.directory = t.TempDir()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := []byte("#!/bin/sh\nexit 1\n")
+	sum := sha256.Sum256(artifact)
+	files := map[string][]byte{
+		fmt.Sprintf("/my-plugin/1.0.0/my-plugin_1.0.0_%s_%s", runtime.GOOS, runtime.GOARCH):     artifact,
+		fmt.Sprintf("/my-plugin/1.0.0/my-plugin_1.0.0_%s_%s.sig", runtime.GOOS, runtime.GOARCH): ed25519.Sign(priv, artifact),
+		"/other/plugin":     artifact,
+		"/other/plugin.sig": []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(otherPriv, artifact))),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+
+	install := func(data map[string]interface{}) *logical.Response {
+		t.Helper()
+		req := logical.TestRequest(t, logical.UpdateOperation, "plugins/install")
+		req.Data = data
+		resp, err := b.HandleRequest(namespace.RootContext(nil), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		return resp
+	}
+
+	resp := install(map[string]interface{}{"name": "my-plugin", "type": "secret", "version": "v1.0.0"})
+	if !resp.IsError() || resp.Error().Error() != "no plugin signing keys are configured; set plugin_signing_keys in the server configuration" {
+		t.Fatalf("expected error without signing keys, got %#v", resp)
+	}
+
+	c.pluginSigningKeys = []ed25519.PublicKey{pub}
+	resp = install(map[string]interface{}{"name": "my-plugin", "type": "secret", "version": "v1.0.0"})
+	if !resp.IsError() {
+		t.Fatalf("expected error without registry, got %#v", resp)
+	}
+
+	c.pluginRegistryURL = srv.URL
+	resp = install(map[string]interface{}{"name": "my-plugin", "type": "secret", "version": "v1.0.0", "sha256": hex.EncodeToString(make([]byte, 32))})
+	if !resp.IsError() {
+		t.Fatalf("expected error on SHA256 mismatch, got %#v", resp)
+	}
+
+	resp = install(map[string]interface{}{"name": "other", "type": "secret", "version": "v1.0.0", "url": srv.URL + "/other/plugin"})
+	if !resp.IsError() {
+		t.Fatalf("expected error with untrusted signature, got %#v", resp)
+	}
+	if _, err := os.Stat(filepath.Join(c.pluginCatalog.directory, "secret_other_v1.0.0")); !os.IsNotExist(err) {
+		t.Fatalf("expected unverified plugin not to be written, got %v", err)
+	}
+
+	resp = install(map[string]interface{}{"name": "my-plugin", "type": "secret", "version": "1.0.0", "sha256": hex.EncodeToString(sum[:])})
+	if resp.IsError() {
+		t.Fatalf("unexpected error: %v", resp.Error())
+	}
+	if resp.Data["command"] != "secret_my-plugin_v1.0.0" || resp.Data["sha256"] != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected response: %#v", resp.Data)
+	}
+
+	written, err := os.ReadFile(filepath.Join(c.pluginCatalog.directory, "secret_my-plugin_v1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != string(artifact) {
+		t.Fatalf("unexpected plugin contents %q", written)
+	}
+
+	plugin, err := c.pluginCatalog.Get(namespace.RootContext(nil), "my-plugin", consts.PluginTypeSecrets, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plugin == nil || hex.EncodeToString(plugin.Sha256) != hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected plugin to be registered, got %#v", plugin)
+	}
+
+	// Installing the same version again is idempotent.
+	resp = install(map[string]interface{}{"name": "my-plugin", "type": "secret", "version": "v1.0.0"})
+	if resp.IsError() {
+		t.Fatalf("unexpected error reinstalling: %v", resp.Error())
+	}
+
+	// Nodes which didn't serve the install request fetch the binary when
+	// they look the plugin up to run it.
+	path := filepath.Join(c.pluginCatalog.directory, "secret_my-plugin_v1.0.0")
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	sysView := dynamicSystemView{core: c}
+	if _, err := sysView.LookupPluginVersion(namespace.RootContext(nil), "my-plugin", consts.PluginTypeSecrets, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	written, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != string(artifact) {
+		t.Fatalf("unexpected plugin contents %q", written)
+	}
+
+	// The fetched binary is verified again.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	otherPub := otherPriv.Public().(ed25519.PublicKey)
+	c.pluginSigningKeys = []ed25519.PublicKey{otherPub}
+	if _, err := sysView.LookupPluginVersion(namespace.RootContext(nil), "my-plugin", consts.PluginTypeSecrets, "v1.0.0"); err == nil {
+		t.Fatal("expected error fetching a plugin signed by an untrusted key")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected unverified plugin not to be written, got %v", err)
+	}
+}
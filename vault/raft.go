@@ -147,6 +147,7 @@ func (c *Core) startRaftBackend(ctx context.Context) (retErr error) {
 		}
 
 		raftBackend.SetRestoreCallback(c.raftSnapshotRestoreCallback(true, true))
+		raftBackend.SetInvalidateCallback(c.raftInvalidateCallback)
 
 		if err := raftBackend.SetupCluster(ctx, raft.SetupOpts{
 			TLSKeyring:      raftTLS,
@@ -792,8 +793,9 @@ func (c *Core) raftSnapshotRestoreCallback(grabLock bool, sealNode bool) func(co
 			}()
 		}
 
-		// Purge the cache so we make sure we are operating on fresh data
+		// Purge the caches so we make sure we are operating on fresh data
 		c.physicalCache.Purge(ctx)
+		c.router.purgeReadCaches()
 
 		// Reload the keyring in case it changed. If this fails it's likely
 		// we've changed root keys.
@@ -1341,6 +1343,7 @@ func (c *Core) joinRaftSendAnswer(ctx context.Context, sealAccess seal.Access, r
 	}
 
 	raftBackend.SetRestoreCallback(c.raftSnapshotRestoreCallback(true, true))
+	raftBackend.SetInvalidateCallback(c.raftInvalidateCallback)
 	opts := raft.SetupOpts{
 		TLSKeyring:      answerResp.Data.TLSKeyring,
 		ClusterListener: c.getClusterListener(),
@@ -1410,6 +1413,7 @@ func (c *Core) RaftBootstrap(ctx context.Context, onInit bool) error {
 		}
 
 		raftBackend.SetRestoreCallback(c.raftSnapshotRestoreCallback(true, true))
+		raftBackend.SetInvalidateCallback(c.raftInvalidateCallback)
 		raftOpts.ClusterListener = c.getClusterListener()
 
 		raftOpts.TLSKeyring = raftTLS
@@ -435,6 +435,37 @@ func (r *Router) matchingStorage(ctx context.Context, path string, apiPath bool)
 	return raw.(*routeEntry).storageView
 }
 
+// invalidateReadCache drops the given full storage path from the read cache
+// of the mount owning it, if the mount has one.
+func (r *Router) invalidateReadCache(path string) {
+	r.l.RLock()
+	_, raw, ok := r.storagePrefix.LongestPrefix(path)
+	r.l.RUnlock()
+	if !ok {
+		return
+	}
+	if view, ok := raw.(*routeEntry).storageView.(*BarrierView); ok {
+		if rc := view.readCache.Load(); rc != nil {
+			rc.invalidate(path)
+		}
+	}
+}
+
+// purgeReadCaches drops the contents of the read caches of all mounts.
+func (r *Router) purgeReadCaches() {
+	r.l.RLock()
+	defer r.l.RUnlock()
+
+	r.storagePrefix.Walk(func(_ string, raw interface{}) bool {
+		if view, ok := raw.(*routeEntry).storageView.(*BarrierView); ok {
+			if rc := view.readCache.Load(); rc != nil {
+				rc.purge()
+			}
+		}
+		return false
+	})
+}
+
 // MatchingMountEntry returns the MountEntry used for a path
 func (r *Router) MatchingMountEntry(ctx context.Context, path string) *MountEntry {
 	ns, err := namespace.FromContext(ctx)